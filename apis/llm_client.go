@@ -53,9 +53,10 @@ func InitLlmClient() error {
 	return err
 }
 
-var CallLlm = func(messages []llms.MessageContent) (*string, error) {
+// CallLlm sends the messages to the LLM, giving up when the context is done. The options are applied after the ones configured in the
+// environment, so callers can override them for a specific kind of answer
+var CallLlm = func(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*string, error) {
 
-	ctx := context.Background()
 	temperatureStr := os.Getenv("LLM_TEMPERATURE")
 	if temperatureStr == "" {
 		log.Warn("LLM_TEMPERATURE environment variable is not set. Using default value of 0.6.")
@@ -93,7 +94,9 @@ var CallLlm = func(messages []llms.MessageContent) (*string, error) {
 	}
 	log.Debugf("Using LLM maximum length: %d", maxLength)
 
-	response, err := llmClient.GenerateContent(ctx, messages, llms.WithTemperature(temperature), llms.WithMinLength(minLength), llms.WithMaxLength(maxLength))
+	callOptions := append([]llms.CallOption{llms.WithTemperature(temperature), llms.WithMinLength(minLength), llms.WithMaxLength(maxLength)},
+		options...)
+	response, err := llmClient.GenerateContent(ctx, messages, callOptions...)
 
 	if err != nil {
 		log.Error(err)
@@ -16,10 +16,17 @@ import (
 // mockModel implements llms.Model for testing.
 type mockModel struct {
 	generateContentCalled bool
+	callOptions           llms.CallOptions
 }
 
 func (m *mockModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, opts ...llms.CallOption) (*llms.ContentResponse, error) {
 	m.generateContentCalled = true
+	for _, opt := range opts {
+		opt(&m.callOptions)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return &llms.ContentResponse{
 		Choices: []*llms.ContentChoice{
 			{
@@ -122,7 +129,7 @@ func TestCallLlm_UsesLlmClient(t *testing.T) {
 		},
 	}
 
-	response, _ := CallLlm(messages)
+	response, _ := CallLlm(context.Background(), messages)
 	assert.True(t, mock.generateContentCalled)
 	assert.NotEmpty(t, response)
 
@@ -153,7 +160,7 @@ func TestCallRealLlm_UsesLlmClient(t *testing.T) {
 			},
 		},
 	}
-	response, err := CallLlm(messages)
+	response, err := CallLlm(context.Background(), messages)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, response)
 }
@@ -179,7 +186,7 @@ func TestCallLlm_DefaultParameters(t *testing.T) {
 		},
 	}
 
-	resp, err := CallLlm(messages)
+	resp, err := CallLlm(context.Background(), messages)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, mock.generateContentCalled)
@@ -206,12 +213,46 @@ func TestCallLlm_CustomParameters(t *testing.T) {
 		},
 	}
 
-	resp, err := CallLlm(messages)
+	resp, err := CallLlm(context.Background(), messages)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, mock.generateContentCalled)
 }
 
+func TestCallLlm_OptionsOverrideEnvironment(t *testing.T) {
+	resetSingleton()
+	os.Setenv("OPENAI_API_KEY", "test_key")
+	defer os.Clearenv()
+
+	mock := &mockModel{}
+	llmClient = mock
+
+	os.Setenv("LLM_MAX_RESPONSE_LENGTH", "456")
+
+	messages := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Test options.")}
+
+	resp, err := CallLlm(context.Background(), messages, llms.WithMaxLength(8000), llms.WithMaxTokens(4000))
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, 8000, mock.callOptions.MaxLength)
+	assert.Equal(t, 4000, mock.callOptions.MaxTokens)
+}
+
+func TestCallLlm_ContextDone(t *testing.T) {
+	resetSingleton()
+	os.Setenv("OPENAI_API_KEY", "test_key")
+	defer os.Clearenv()
+
+	llmClient = &mockModel{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := CallLlm(ctx, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Test cancellation.")})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, resp)
+}
+
 func TestCallLlm_InvalidTemperature(t *testing.T) {
 	resetSingleton()
 	os.Setenv("OPENAI_API_KEY", "test_key")
@@ -231,7 +272,7 @@ func TestCallLlm_InvalidTemperature(t *testing.T) {
 		},
 	}
 
-	resp, err := CallLlm(messages)
+	resp, err := CallLlm(context.Background(), messages)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, mock.generateContentCalled)
@@ -256,7 +297,7 @@ func TestCallLlm_InvalidMinLength(t *testing.T) {
 		},
 	}
 
-	resp, err := CallLlm(messages)
+	resp, err := CallLlm(context.Background(), messages)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, mock.generateContentCalled)
@@ -281,7 +322,7 @@ func TestCallLlm_InvalidMaxLength(t *testing.T) {
 		},
 	}
 
-	resp, err := CallLlm(messages)
+	resp, err := CallLlm(context.Background(), messages)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.True(t, mock.generateContentCalled)
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 
+	"example.com/travel-advisor/utils"
 	log "github.com/sirupsen/logrus"
 	_ "modernc.org/sqlite"
 )
@@ -63,7 +64,9 @@ func createTables() {
 		password TEXT NOT NULL,
 		creation_date DATETIME NOT NULL,
 		update_date DATETIME NOT NULL,
-		last_login_date DATETIME
+		last_login_date DATETIME,
+		role VARCHAR(16) NOT NULL DEFAULT 'user',
+		disabled_date DATETIME
 	)
 	`
 	_, err := DB.Exec(createUsersTable)
@@ -73,6 +76,10 @@ func createTables() {
 		panic("Could not create users table!")
 	}
 
+	// Columns added after the first release of the users table
+	addColumnIfMissing("users", "role", "VARCHAR(16) NOT NULL DEFAULT 'user'")
+	addColumnIfMissing("users", "disabled_date", "DATETIME")
+
 	createItinerariesTable := `
 	CREATE TABLE IF NOT EXISTS itineraries (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -82,6 +89,7 @@ func createTables() {
 		owner_id INTEGER NOT NULL,
 		creation_date DATETIME NOT NULL,
 		update_date DATETIME NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
 		FOREIGN KEY (owner_id) REFERENCES users(id)
 	)
 	`
@@ -91,6 +99,21 @@ func createTables() {
 		panic("Could not create itineraries table!")
 	}
 
+	// Columns added after the first release of the itineraries table. The version is incremented on every change of the itinerary, so
+	// concurrent writers can detect that their copy is stale
+	addColumnIfMissing("itineraries", "version", "INTEGER NOT NULL DEFAULT 1")
+
+	// Speeds up the paginated listings of the itineraries of an owner, sorted by creation date by default
+	createItinerariesOwnerIndex := `
+	CREATE INDEX IF NOT EXISTS idx_itineraries_owner
+	ON itineraries (owner_id, creation_date)
+	`
+	_, err = DB.Exec(createItinerariesOwnerIndex)
+	if err != nil {
+		log.Errorf("Error creating itineraries owner index: %v", err)
+		panic("Could not create itineraries owner index!")
+	}
+
 	createItinerariesTravelDestinationsTable := `
 	CREATE TABLE IF NOT EXISTS itinerary_travel_destinations (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -111,6 +134,13 @@ func createTables() {
 		panic("Could not create itineraries travel destinations table!")
 	}
 
+	// Columns added when destinations started being normalized against the gazetteer. They are null for the places missing from it
+	addColumnIfMissing("itinerary_travel_destinations", "country_code", "VARCHAR(2)")
+	addColumnIfMissing("itinerary_travel_destinations", "latitude", "REAL")
+	addColumnIfMissing("itinerary_travel_destinations", "longitude", "REAL")
+	addColumnIfMissing("itinerary_travel_destinations", "time_zone", "VARCHAR(64)")
+	backfillDestinationPlaces()
+
 	createItinerariesFileJobsTable := `
 	CREATE TABLE IF NOT EXISTS itinerary_file_jobs (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -146,7 +176,9 @@ func createTables() {
 		CREATE TABLE IF NOT EXISTS audit_events (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			user_id INTEGER NOT NULL,
+			event_type VARCHAR(64),
 			event_description TEXT,
+			details TEXT,
 			event_date DATETIME,
 			FOREIGN KEY (user_id) REFERENCES users(id)
 		)
@@ -158,6 +190,24 @@ func createTables() {
 		panic("Could not create audit events table!")
 	}
 
+	// Columns added after the first release of the audit events table
+	addColumnIfMissing("audit_events", "event_type", "VARCHAR(64)")
+	addColumnIfMissing("audit_events", "details", "TEXT")
+
+	// Login events recorded before they were typed can be typed from their fixed descriptions
+	backfillAuditEventTypes := `
+		UPDATE audit_events SET event_type = CASE
+			WHEN event_description = 'Successful user login' THEN 'user.login_succeeded'
+			WHEN event_description = 'Failed user login due to invalid credentials' THEN 'user.login_failed'
+		END
+		WHERE event_type IS NULL
+	`
+	_, err = DB.Exec(backfillAuditEventTypes)
+	if err != nil {
+		log.Errorf("Error backfilling audit event types: %v", err)
+		panic("Could not backfill audit event types!")
+	}
+
 	createAuditEventsIndex := `
 		CREATE INDEX IF NOT EXISTS idx_event_details 
 		ON audit_events (event_date, event_description, user_id)
@@ -168,6 +218,557 @@ func createTables() {
 		panic("Could not create audit events index!")
 	}
 
+	createAuditEventsQueryIndex := `
+		CREATE INDEX IF NOT EXISTS idx_audit_events_user_type
+		ON audit_events (user_id, event_type, id)
+	`
+	_, err = DB.Exec(createAuditEventsQueryIndex)
+	if err != nil {
+		log.Errorf("Error creating audit events query index: %v", err)
+		panic("Could not create audit events query index!")
+	}
+
+	createLoginAttemptsTable := `
+		CREATE TABLE IF NOT EXISTS login_attempts (
+			attempt_key TEXT PRIMARY KEY,
+			failed_count INTEGER NOT NULL,
+			last_failure_date DATETIME NOT NULL,
+			locked_until DATETIME
+		)
+	`
+	_, err = DB.Exec(createLoginAttemptsTable)
+	if err != nil {
+		log.Errorf("Error creating login attempts table: %v", err)
+		panic("Could not create login attempts table!")
+	}
+
+	createApiKeysTable := `
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			key_prefix VARCHAR(16) NOT NULL,
+			key_hash VARCHAR(64) NOT NULL UNIQUE,
+			scopes TEXT NOT NULL,
+			creation_date DATETIME NOT NULL,
+			expiration_date DATETIME,
+			last_used_date DATETIME,
+			revocation_date DATETIME,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)
+	`
+	_, err = DB.Exec(createApiKeysTable)
+	if err != nil {
+		log.Errorf("Error creating API keys table: %v", err)
+		panic("Could not create API keys table!")
+	}
+
+	createDataExportJobsTable := `
+		CREATE TABLE IF NOT EXISTS data_export_jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			status_description TEXT,
+			creation_date DATETIME NOT NULL,
+			start_date DATETIME,
+			end_date DATETIME,
+			file_path TEXT,
+			file_manager VARCHAR(64) NOT NULL,
+			async_task_id VARCHAR(64),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)
+	`
+	_, err = DB.Exec(createDataExportJobsTable)
+	if err != nil {
+		log.Errorf("Error creating data export jobs table: %v", err)
+		panic("Could not create data export jobs table!")
+	}
+
+	createItinerarySharesTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_shares (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			permission VARCHAR(16) NOT NULL,
+			creation_date DATETIME NOT NULL,
+			update_date DATETIME NOT NULL,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id),
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			UNIQUE (itinerary_id, user_id)
+		)
+	`
+	_, err = DB.Exec(createItinerarySharesTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary shares table: %v", err)
+		panic("Could not create itinerary shares table!")
+	}
+
+	createShareLinksTable := `
+		CREATE TABLE IF NOT EXISTS share_links (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			itinerary_file_job_id INTEGER,
+			user_id INTEGER NOT NULL,
+			token_prefix VARCHAR(16) NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			password_hash TEXT,
+			creation_date DATETIME NOT NULL,
+			expiration_date DATETIME,
+			revocation_date DATETIME,
+			access_count INTEGER NOT NULL DEFAULT 0,
+			last_access_date DATETIME,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id),
+			FOREIGN KEY (itinerary_file_job_id) REFERENCES itinerary_file_jobs(id),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)
+	`
+	_, err = DB.Exec(createShareLinksTable)
+	if err != nil {
+		log.Errorf("Error creating share links table: %v", err)
+		panic("Could not create share links table!")
+	}
+
+	// Full-text index of the itineraries, kept up to date by the application. The rowid is the itinerary ID. It is an SQLite FTS5
+	// table, so search is only supported on SQLite
+	createItinerarySearchTable := `
+		CREATE VIRTUAL TABLE IF NOT EXISTS itinerary_search USING fts5(
+			title,
+			description,
+			notes,
+			destinations,
+			content,
+			owner_id UNINDEXED,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)
+	`
+	_, err = DB.Exec(createItinerarySearchTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary search table: %v", err)
+		panic("Could not create itinerary search table!")
+	}
+
+	// Index the itineraries created before the search was available (or whose indexing failed). Their generated documents are
+	// indexed the next time they change
+	indexMissingItineraries := `
+		INSERT INTO itinerary_search(rowid, title, description, notes, destinations, content, owner_id)
+		SELECT i.id, i.title, i.description, COALESCE(i.notes, ''),
+			COALESCE((SELECT group_concat(d.city || ', ' || d.country, '; ') FROM itinerary_travel_destinations d WHERE d.itinerary_id = i.id), ''),
+			'', i.owner_id
+		FROM itineraries i WHERE i.id NOT IN (SELECT rowid FROM itinerary_search)
+	`
+	_, err = DB.Exec(indexMissingItineraries)
+	if err != nil {
+		log.Errorf("Error indexing itineraries for search: %v", err)
+		panic("Could not index itineraries for search!")
+	}
+
+	createItineraryRevisionsTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			revision_number INTEGER NOT NULL,
+			author_id INTEGER NOT NULL,
+			creation_date DATETIME NOT NULL,
+			restored_from INTEGER,
+			title TEXT NOT NULL,
+			description TEXT,
+			notes TEXT,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id),
+			UNIQUE (itinerary_id, revision_number)
+		)
+	`
+	_, err = DB.Exec(createItineraryRevisionsTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary revisions table: %v", err)
+		panic("Could not create itinerary revisions table!")
+	}
+
+	createItineraryRevisionDestinationsTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_revision_destinations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			revision_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			country VARCHAR(128) NOT NULL,
+			city VARCHAR(128) NOT NULL,
+			arrival_date DATETIME NOT NULL,
+			departure_date DATETIME NOT NULL,
+			FOREIGN KEY (revision_id) REFERENCES itinerary_revisions(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryRevisionDestinationsTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary revision destinations table: %v", err)
+		panic("Could not create itinerary revision destinations table!")
+	}
+
+	createItineraryRevisionDestinationsIndex := `
+		CREATE INDEX IF NOT EXISTS idx_itinerary_revision_destinations_revision
+		ON itinerary_revision_destinations (revision_id, position)
+	`
+	_, err = DB.Exec(createItineraryRevisionDestinationsIndex)
+	if err != nil {
+		log.Errorf("Error creating itinerary revision destinations index: %v", err)
+		panic("Could not create itinerary revision destinations index!")
+	}
+
+	// Save the current state of the itineraries created before the revision history was available as their first revision, authored
+	// by their owners
+	createMissingFirstRevisions := `
+		INSERT INTO itinerary_revisions(itinerary_id, revision_number, author_id, creation_date, title, description, notes)
+		SELECT i.id, 1, i.owner_id, i.update_date, i.title, i.description, i.notes
+		FROM itineraries i WHERE NOT EXISTS (SELECT 1 FROM itinerary_revisions r WHERE r.itinerary_id = i.id)
+	`
+	createMissingFirstRevisionDestinations := `
+		INSERT INTO itinerary_revision_destinations(revision_id, position, country, city, arrival_date, departure_date)
+		SELECT r.id, ROW_NUMBER() OVER (PARTITION BY r.id ORDER BY d.arrival_date, d.id) - 1, d.country, d.city, d.arrival_date, d.departure_date
+		FROM itinerary_revisions r JOIN itinerary_travel_destinations d ON d.itinerary_id = r.itinerary_id
+		WHERE r.revision_number = 1 AND NOT EXISTS (SELECT 1 FROM itinerary_revision_destinations rd WHERE rd.revision_id = r.id)
+	`
+	for _, query := range []string{createMissingFirstRevisions, createMissingFirstRevisionDestinations} {
+		_, err = DB.Exec(query)
+		if err != nil {
+			log.Errorf("Error creating first itinerary revisions: %v", err)
+			panic("Could not create first itinerary revisions!")
+		}
+	}
+
+	// Revision of the itinerary each file job was generated from
+	addColumnIfMissing("itinerary_file_jobs", "itinerary_revision", "INTEGER")
+
+	// Traveller preferences of a user (user_id set) or overrides of them for an itinerary (itinerary_id set). The lists are JSON arrays
+	createTravellerPreferencesTable := `
+		CREATE TABLE IF NOT EXISTS traveller_preferences (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER UNIQUE,
+			itinerary_id INTEGER UNIQUE,
+			interests TEXT,
+			pace VARCHAR(16) NOT NULL DEFAULT '',
+			budget_level VARCHAR(16) NOT NULL DEFAULT '',
+			dietary_restrictions TEXT,
+			mobility_needs VARCHAR(256) NOT NULL DEFAULT '',
+			adults INTEGER,
+			children INTEGER,
+			seniors INTEGER,
+			language VARCHAR(35) NOT NULL DEFAULT '',
+			creation_date DATETIME NOT NULL,
+			update_date DATETIME NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id)
+		)
+	`
+	_, err = DB.Exec(createTravellerPreferencesTable)
+	if err != nil {
+		log.Errorf("Error creating traveller preferences table: %v", err)
+		panic("Could not create traveller preferences table!")
+	}
+
+	// Versions of the templates of the prompt the itineraries are generated with. Global templates have no owner
+	createPromptTemplatesTable := `
+		CREATE TABLE IF NOT EXISTS prompt_templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name VARCHAR(64) NOT NULL,
+			version INTEGER NOT NULL,
+			owner_id INTEGER,
+			author_id INTEGER NOT NULL,
+			system_message TEXT NOT NULL,
+			template TEXT NOT NULL,
+			creation_date DATETIME NOT NULL,
+			deletion_date DATETIME,
+			FOREIGN KEY (owner_id) REFERENCES users(id)
+		)
+	`
+	_, err = DB.Exec(createPromptTemplatesTable)
+	if err != nil {
+		log.Errorf("Error creating prompt templates table: %v", err)
+		panic("Could not create prompt templates table!")
+	}
+
+	// A version number identifies a single version of a template of an owner, or of a global template
+	createPromptTemplatesIndex := `
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_owner_name_version
+		ON prompt_templates (COALESCE(owner_id, 0), name, version)
+	`
+	_, err = DB.Exec(createPromptTemplatesIndex)
+	if err != nil {
+		log.Errorf("Error creating prompt templates index: %v", err)
+		panic("Could not create prompt templates index!")
+	}
+
+	// Version of the prompt template each file job was generated with. Jobs generated with the built-in prompt have none
+	addColumnIfMissing("itinerary_file_jobs", "prompt_template_id", "INTEGER")
+
+	// Language each file job is written in, so its downloads are labelled with it. Jobs without a target language have none
+	addColumnIfMissing("itinerary_file_jobs", "language", "VARCHAR(35) NOT NULL DEFAULT ''")
+
+	// Whether each file job was generated by the LLM or rendered from the plan of the itinerary
+	addColumnIfMissing("itinerary_file_jobs", "source", "VARCHAR(16) NOT NULL DEFAULT 'llm'")
+
+	// Job and days of the trip each regeneration job rewrote the file of. Jobs that generated the whole trip have none
+	addColumnIfMissing("itinerary_file_jobs", "base_job_id", "INTEGER REFERENCES itinerary_file_jobs(id)")
+	addColumnIfMissing("itinerary_file_jobs", "scope_start_date", "DATETIME")
+	addColumnIfMissing("itinerary_file_jobs", "scope_end_date", "DATETIME")
+
+	// Budget of an itinerary in the home currency of the trip
+	createItineraryBudgetsTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_budgets (
+			itinerary_id INTEGER PRIMARY KEY,
+			amount_cents INTEGER NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			creation_date DATETIME NOT NULL,
+			update_date DATETIME NOT NULL,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryBudgetsTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary budgets table: %v", err)
+		panic("Could not create itinerary budgets table!")
+	}
+
+	// Daily spending estimates of the destinations of an itinerary. They are kept by country and city rather than by destination, as
+	// the destinations are recreated when the whole itinerary is updated
+	createDestinationCostEstimatesTable := `
+		CREATE TABLE IF NOT EXISTS destination_cost_estimates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			country VARCHAR(128) NOT NULL COLLATE NOCASE,
+			city VARCHAR(128) NOT NULL COLLATE NOCASE,
+			currency VARCHAR(3) NOT NULL,
+			lodging_cents INTEGER NOT NULL,
+			food_cents INTEGER NOT NULL,
+			transport_cents INTEGER NOT NULL,
+			activities_cents INTEGER NOT NULL,
+			source VARCHAR(16) NOT NULL,
+			update_date DATETIME NOT NULL,
+			UNIQUE (itinerary_id, country, city),
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id)
+		)
+	`
+	_, err = DB.Exec(createDestinationCostEstimatesTable)
+	if err != nil {
+		log.Errorf("Error creating destination cost estimates table: %v", err)
+		panic("Could not create destination cost estimates table!")
+	}
+
+	// How the travellers get from a destination of an itinerary to the next one. Like the cost estimates, legs are kept by the countries
+	// and cities they connect, as the destinations are recreated when the whole itinerary is updated
+	createItineraryTransportLegsTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_transport_legs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			from_country VARCHAR(128) NOT NULL COLLATE NOCASE,
+			from_city VARCHAR(128) NOT NULL COLLATE NOCASE,
+			to_country VARCHAR(128) NOT NULL COLLATE NOCASE,
+			to_city VARCHAR(128) NOT NULL COLLATE NOCASE,
+			mode VARCHAR(16) NOT NULL,
+			carrier VARCHAR(128) NOT NULL DEFAULT '',
+			booking_reference VARCHAR(64) NOT NULL DEFAULT '',
+			departure_time DATETIME,
+			arrival_time DATETIME,
+			notes VARCHAR(512) NOT NULL DEFAULT '',
+			creation_date DATETIME NOT NULL,
+			update_date DATETIME NOT NULL,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryTransportLegsTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary transport legs table: %v", err)
+		panic("Could not create itinerary transport legs table!")
+	}
+
+	// Where the travellers sleep during a stay in a destination of an itinerary. Accommodations are kept by the country and city of the
+	// destination and matched to it by their check-in date, as the destinations are recreated when the whole itinerary is updated
+	createDestinationAccommodationsTable := `
+		CREATE TABLE IF NOT EXISTS destination_accommodations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			country VARCHAR(128) NOT NULL COLLATE NOCASE,
+			city VARCHAR(128) NOT NULL COLLATE NOCASE,
+			name VARCHAR(255) NOT NULL,
+			address VARCHAR(512) NOT NULL DEFAULT '',
+			check_in DATETIME NOT NULL,
+			check_out DATETIME NOT NULL,
+			confirmation_number VARCHAR(64) NOT NULL DEFAULT '',
+			cost REAL,
+			currency VARCHAR(3) NOT NULL DEFAULT '',
+			creation_date DATETIME NOT NULL,
+			update_date DATETIME NOT NULL,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id)
+		)
+	`
+	_, err = DB.Exec(createDestinationAccommodationsTable)
+	if err != nil {
+		log.Errorf("Error creating destination accommodations table: %v", err)
+		panic("Could not create destination accommodations table!")
+	}
+
+	// Day-by-day plan of an itinerary, editable by its owner and editors. The activities of a day are sorted by their position
+	createItineraryActivitiesTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_activities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			itinerary_id INTEGER NOT NULL,
+			date DATETIME NOT NULL,
+			time_slot VARCHAR(16) NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			location VARCHAR(255) NOT NULL DEFAULT '',
+			notes VARCHAR(1024) NOT NULL DEFAULT '',
+			position INTEGER NOT NULL,
+			creation_date DATETIME NOT NULL,
+			update_date DATETIME NOT NULL,
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryActivitiesTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary activities table: %v", err)
+		panic("Could not create itinerary activities table!")
+	}
+
+	// Conversations that refine the files of itinerary jobs. Each message of a user is answered by a new job with the new version of the
+	// file, which the reply of the assistant points to as well
+	createItineraryJobMessagesTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_job_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_id INTEGER NOT NULL,
+			itinerary_id INTEGER NOT NULL,
+			role VARCHAR(16) NOT NULL,
+			content TEXT NOT NULL,
+			output_job_id INTEGER,
+			creation_date DATETIME NOT NULL,
+			FOREIGN KEY (job_id) REFERENCES itinerary_file_jobs(id),
+			FOREIGN KEY (itinerary_id) REFERENCES itineraries(id),
+			FOREIGN KEY (output_job_id) REFERENCES itinerary_file_jobs(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryJobMessagesTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary job messages table: %v", err)
+		panic("Could not create itinerary job messages table!")
+	}
+
+	// Routes without dates that itineraries are created from. Shared templates can be used by every user
+	createItineraryTemplatesTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_templates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title VARCHAR(128) NOT NULL,
+			description VARCHAR(512) NOT NULL DEFAULT '',
+			notes VARCHAR(512),
+			owner_id INTEGER NOT NULL,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
+			creation_date DATETIME NOT NULL,
+			FOREIGN KEY (owner_id) REFERENCES users(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryTemplatesTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary templates table: %v", err)
+		panic("Could not create itinerary templates table!")
+	}
+
+	// Destinations of the templates, placed by the day of the trip they are arrived at
+	createItineraryTemplateDestinationsTable := `
+		CREATE TABLE IF NOT EXISTS itinerary_template_destinations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			template_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			country VARCHAR(128) NOT NULL,
+			city VARCHAR(128) NOT NULL,
+			start_day INTEGER NOT NULL,
+			days INTEGER NOT NULL,
+			FOREIGN KEY (template_id) REFERENCES itinerary_templates(id)
+		)
+	`
+	_, err = DB.Exec(createItineraryTemplateDestinationsTable)
+	if err != nil {
+		log.Errorf("Error creating itinerary template destinations table: %v", err)
+		panic("Could not create itinerary template destinations table!")
+	}
+
+	// Speeds up listing the itineraries shared with a user
+	createItinerarySharesIndex := `
+		CREATE INDEX IF NOT EXISTS idx_itinerary_shares_user
+		ON itinerary_shares (user_id)
+	`
+	_, err = DB.Exec(createItinerarySharesIndex)
+	if err != nil {
+		log.Errorf("Error creating itinerary shares index: %v", err)
+		panic("Could not create itinerary shares index!")
+	}
+
+}
+
+// backfillDestinationPlaces normalizes against the gazetteer the destinations stored before destinations were normalized, or whose
+// place was missing from an older gazetteer, the same way new destinations are normalized when saved. A destination that would end
+// up duplicating another one of the same itinerary and dates once renamed is kept as entered
+func backfillDestinationPlaces() {
+	type destinationPlace struct {
+		id      int64
+		country string
+		city    string
+	}
+
+	rows, err := DB.Query(`SELECT id, country, city FROM itinerary_travel_destinations WHERE country_code IS NULL OR time_zone IS NULL`)
+	if err != nil {
+		log.Errorf("Error retrieving destinations to normalize: %v", err)
+		panic("Could not normalize itinerary travel destinations!")
+	}
+	places := []destinationPlace{}
+	for rows.Next() {
+		var place destinationPlace
+		err = rows.Scan(&place.id, &place.country, &place.city)
+		if err != nil {
+			rows.Close()
+			log.Errorf("Error scanning destination to normalize: %v", err)
+			panic("Could not normalize itinerary travel destinations!")
+		}
+		places = append(places, place)
+	}
+	rows.Close()
+
+	normalized := 0
+	for _, place := range places {
+		country, city, gazetteerCountry, gazetteerCity := utils.NormalizePlace(place.country, place.city)
+		if gazetteerCountry == nil {
+			continue
+		}
+
+		var latitude, longitude, timeZone any
+		if gazetteerCity != nil {
+			latitude, longitude, timeZone = gazetteerCity.Latitude, gazetteerCity.Longitude, gazetteerCity.TimeZone
+		}
+		result, err := DB.Exec(`UPDATE OR IGNORE itinerary_travel_destinations
+			SET country = ?, city = ?, country_code = ?, latitude = ?, longitude = ?, time_zone = ? WHERE id = ?`,
+			country, city, gazetteerCountry.Code, latitude, longitude, timeZone, place.id)
+		if err != nil {
+			log.Errorf("Error normalizing destination %d: %v", place.id, err)
+			panic("Could not normalize itinerary travel destinations!")
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			normalized++
+		}
+	}
+
+	if normalized > 0 {
+		log.Infof("Normalized %d itinerary travel destinations against the gazetteer", normalized)
+	}
+}
+
+// addColumnIfMissing adds a column to a table created by a previous version of the application, since
+// "CREATE TABLE IF NOT EXISTS" does not alter existing tables
+func addColumnIfMissing(table string, column string, definition string) {
+	rows, err := DB.Query("SELECT " + column + " FROM " + table + " LIMIT 0")
+	if err == nil {
+		rows.Close()
+		return
+	}
+
+	log.Infof("Adding column %s to table %s", column, table)
+	_, err = DB.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	if err != nil {
+		log.Errorf("Error adding column %s to table %s: %v", column, table, err)
+		panic("Could not add column " + column + " to table " + table + "!")
+	}
 }
 
 func HandleTransaction(tx *sql.Tx, err *error) {
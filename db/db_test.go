@@ -41,7 +41,8 @@ func TestInitDB_Success(t *testing.T) {
 	}
 
 	// Check if tables exist
-	tables := []string{"users", "itineraries", "itinerary_travel_destinations", "itinerary_file_jobs", "audit_events"}
+	tables := []string{"users", "itineraries", "itinerary_travel_destinations", "itinerary_file_jobs", "audit_events", "login_attempts", "api_keys", "data_export_jobs", "itinerary_shares", "share_links", "itinerary_search", "itinerary_revisions",
+		"itinerary_revision_destinations"}
 	for _, table := range tables {
 		query := "SELECT name FROM sqlite_master WHERE type='table' AND name=?"
 		row := DB.QueryRow(query, table)
@@ -176,3 +177,90 @@ func TestHandleTransaction_Panic(t *testing.T) {
 		panic("test panic")
 	}()
 }
+
+func TestAddColumnIfMissing(t *testing.T) {
+	setTestEnv()
+	defer unsetTestEnv()
+
+	var err error
+	DB, err = sql.Open("sqlite", "file:legacy?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer DB.Close()
+
+	_, err = DB.Exec("CREATE TABLE legacy_users (id INTEGER PRIMARY KEY, email TEXT NOT NULL)")
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+	_, err = DB.Exec("INSERT INTO legacy_users (email) VALUES ('test@example.com')")
+	if err != nil {
+		t.Fatalf("Failed to insert legacy row: %v", err)
+	}
+
+	addColumnIfMissing("legacy_users", "role", "VARCHAR(16) NOT NULL DEFAULT 'user'")
+	// Adding it again must be a no-op
+	addColumnIfMissing("legacy_users", "role", "VARCHAR(16) NOT NULL DEFAULT 'user'")
+
+	var role string
+	err = DB.QueryRow("SELECT role FROM legacy_users WHERE email = 'test@example.com'").Scan(&role)
+	if err != nil {
+		t.Fatalf("Column role was not added: %v", err)
+	}
+	if role != "user" {
+		t.Errorf("Expected default role 'user', got %q", role)
+	}
+}
+
+func TestBackfillDestinationPlaces(t *testing.T) {
+	var err error
+	DB, err = sql.Open("sqlite", "file:legacy_destinations?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer DB.Close()
+
+	_, err = DB.Exec(`CREATE TABLE itinerary_travel_destinations (id INTEGER PRIMARY KEY, country TEXT NOT NULL, city TEXT NOT NULL,
+		itinerary_id INTEGER NOT NULL, arrival_date DATETIME NOT NULL, departure_date DATETIME NOT NULL,
+		country_code VARCHAR(2), latitude REAL, longitude REAL, time_zone VARCHAR(64),
+		UNIQUE (itinerary_id, arrival_date, departure_date, city, country))`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+	_, err = DB.Exec(`INSERT INTO itinerary_travel_destinations (id, country, city, itinerary_id, arrival_date, departure_date) VALUES
+		(1, 'usa', 'NYC', 1, '2025-01-01', '2025-01-05'),
+		(2, 'españa', 'Villarriba', 1, '2025-01-05', '2025-01-08'),
+		(3, 'Atlantis', 'Poseidonia', 1, '2025-01-08', '2025-01-10'),
+		(4, 'United States', 'New York', 2, '2025-01-01', '2025-01-05'),
+		(5, 'USA', 'New York', 2, '2025-01-01', '2025-01-05')`)
+	if err != nil {
+		t.Fatalf("Failed to insert legacy rows: %v", err)
+	}
+
+	backfillDestinationPlaces()
+
+	// Destination 5 is kept as entered, since renaming it would duplicate destination 4
+	expected := map[int64][4]string{
+		1: {"United States", "New York", "US", "America/New_York"},
+		2: {"Spain", "Villarriba", "ES", ""},
+		3: {"Atlantis", "Poseidonia", "", ""},
+		5: {"USA", "New York", "", ""},
+	}
+	for id, want := range expected {
+		var country, city string
+		var countryCode, timeZone sql.NullString
+		var latitude sql.NullFloat64
+		err = DB.QueryRow("SELECT country, city, country_code, latitude, time_zone FROM itinerary_travel_destinations WHERE id = ?", id).
+			Scan(&country, &city, &countryCode, &latitude, &timeZone)
+		if err != nil {
+			t.Fatalf("Failed to read destination %d: %v", id, err)
+		}
+		if latitude.Valid != timeZone.Valid {
+			t.Errorf("Destination %d: expected coordinates only along with the time zone", id)
+		}
+		got := [4]string{country, city, countryCode.String, timeZone.String}
+		if got != want {
+			t.Errorf("Destination %d: expected %v, got %v", id, want, got)
+		}
+	}
+}
@@ -19,26 +19,73 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/itineraries": {
+        "/admin/audit-events": {
             "get": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Retrieves all itineraries belonging to the authenticated user.",
+                "description": "Retrieves the audit events of all users from the newest to the oldest, optionally filtered by user, type and date range. Use the nextCursor of a page to get the next one. Only available for administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
+                ],
+                "summary": "Get the audit events of all users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "user.login_failed",
+                        "description": "Audit event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-06-01T00:00:00Z",
+                        "description": "Start of the date range (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-06-30T23:59:59Z",
+                        "description": "End of the date range (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to get",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events per page (1-200, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all itineraries of the authenticated user",
                 "responses": {
                     "200": {
-                        "description": "List of itineraries",
+                        "description": "Page of audit events",
                         "schema": {
-                            "$ref": "#/definitions/responses.GetItinerariesResponse"
+                            "$ref": "#/definitions/responses.GetAuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filters or cursor.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "401": {
@@ -47,51 +94,54 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Could not retrieve itineraries. Try again later.",
+                        "description": "Could not get audit events. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/jobs/{itineraryJobId}": {
+            "get": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Updates an existing itinerary for the authenticated user.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieves the status and details of any itinerary file job, regardless of its owner. Only available for support staff and administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Update an itinerary",
+                "summary": "Inspect any itinerary file job",
                 "parameters": [
                     {
-                        "description": "Itinerary update data",
-                        "name": "itinerary",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.UpdateItineraryRequest"
-                        }
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Itinerary updated.",
+                        "description": "Itinerary job details",
                         "schema": {
-                            "$ref": "#/definitions/responses.UpdateItineraryResponse"
+                            "$ref": "#/definitions/responses.GetItineraryJobResponse"
                         }
                     },
                     "400": {
-                        "description": "Could not parse request data or invalid destinations.",
+                        "description": "Invalid itinerary job ID.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -103,62 +153,57 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "You do not have permission to update this itinerary.",
+                        "description": "You do not have permission to access this resource.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Itinerary not found.",
+                        "description": "Itinerary job not found.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not update itinerary. Try again later.",
+                        "description": "Could not get itinerary job. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Creates a new itinerary for the authenticated user.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fully deletes an itinerary file job of any user and its generated file right away. Pending or running jobs must be stopped first. Only available for administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Create a new itinerary",
+                "summary": "Purge any itinerary file job",
                 "parameters": [
                     {
-                        "description": "Itinerary data",
-                        "name": "itinerary",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.CreateItineraryRequest"
-                        }
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Itinerary created.",
+                    "200": {
+                        "description": "Itinerary job purged.",
                         "schema": {
-                            "$ref": "#/definitions/responses.CreateItineraryResponse"
+                            "$ref": "#/definitions/responses.PurgeItineraryJobResponse"
                         }
                     },
                     "400": {
-                        "description": "Could not parse request data or invalid destinations.",
+                        "description": "Invalid itinerary job ID.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -169,8 +214,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot purge itinerary job that is still pending or running.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Could not create itinerary. Try again later.",
+                        "description": "Could not purge itinerary job. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -178,35 +241,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/itineraries/{itineraryId}": {
-            "get": {
+        "/admin/jobs/{itineraryJobId}/stop": {
+            "put": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Retrieves a specific itinerary for the authenticated user.",
+                "description": "Stops a pending or running itinerary file job of any user right away, without waiting for the async task timeout. Only available for administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Get an itinerary by ID",
+                "summary": "Force-stop any itinerary file job",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Itinerary details",
+                        "description": "Itinerary job stopped.",
                         "schema": {
-                            "$ref": "#/definitions/responses.GetItineraryResponse"
+                            "$ref": "#/definitions/responses.StopItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid itinerary job ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "401": {
@@ -222,75 +291,82 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Itinerary not found.",
+                        "description": "Itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Only pending or running itinerary jobs can be stopped.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not get itinerary. Try again later.",
+                        "description": "Could not stop itinerary job. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/prompt-templates": {
+            "post": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Deletes an itinerary for the authenticated user.",
+                "description": "Saves a new version of a global prompt template, the first one if there is no template with the name. Saving the template named itinerary changes the default prompt of the users without their own one. The template is a Go template which can only use the variables title, description, notes, ownerId, travelDestinations (with country, city, arrivalDate, departureDate, and localArrivalDate and localDepartureDate formatted for the locale of the language), interests, travellerProfile, language and languageName, and the built-in functions of Go templates. Only available for administrators.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Delete an itinerary",
+                "summary": "Save a global prompt template",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
-                        "in": "path",
-                        "required": true
+                        "description": "Prompt template",
+                        "name": "promptTemplate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.PromptTemplateRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Itinerary deleted.",
-                        "schema": {
-                            "$ref": "#/definitions/responses.DeleteItineraryResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Not authorized.",
+                    "201": {
+                        "description": "Prompt template saved.",
                         "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
+                            "$ref": "#/definitions/responses.CreatePromptTemplateResponse"
                         }
                     },
-                    "403": {
-                        "description": "You do not have permission to access this resource.",
+                    "400": {
+                        "description": "Could not parse request data or invalid template.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Itinerary not found.",
+                    "401": {
+                        "description": "Not authorized.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Itinerary has pending or running jobs. Please wait for them to complete or stop them before deleting the itinerary.",
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not delete itinerary. Try again later.",
+                        "description": "Could not save prompt template. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -298,35 +374,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/itineraries/{itineraryId}/jobs": {
+        "/admin/prompt-templates/{name}": {
             "get": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Retrieves all file jobs associated with the specified itinerary. The user must be authenticated and own the itinerary.",
+                "description": "Retrieves the versions of a global prompt template, from the newest. Only available for support staff and administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Get all file jobs for an itinerary",
+                "summary": "List the versions of a global prompt template",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
+                        "type": "string",
+                        "description": "Prompt template name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of itinerary file jobs",
+                        "description": "Versions of the prompt template",
                         "schema": {
-                            "$ref": "#/definitions/responses.GetItineraryJobsResponse"
+                            "$ref": "#/definitions/responses.GetPromptTemplatesResponse"
                         }
                     },
                     "401": {
@@ -342,47 +418,47 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Itinerary not found.",
+                        "description": "Prompt template not found.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not retrieve jobs. Try again later.",
+                        "description": "Could not get prompt template. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Starts an asynchronous job to generate a file for the specified itinerary. The user must be authenticated and the itinerary must belong to them.",
+                "description": "Deletes all the versions of a global prompt template. Deleting the template named itinerary restores the built-in default prompt. The versions stay available for the jobs generated with them. Only available for administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Start itinerary file generation job",
+                "summary": "Delete a global prompt template",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
+                        "type": "string",
+                        "description": "Prompt template name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Job started successfully.",
+                    "200": {
+                        "description": "Prompt template deleted.",
                         "schema": {
-                            "$ref": "#/definitions/responses.StartItineraryJobResponse"
+                            "$ref": "#/definitions/responses.DeletePromptTemplateResponse"
                         }
                     },
                     "401": {
@@ -398,19 +474,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Itinerary not found.",
-                        "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Too many jobs running for your user. Please wait for existing jobs to complete.",
+                        "description": "Prompt template not found.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not create job. Try again later.",
+                        "description": "Could not delete prompt template. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -418,48 +488,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/itineraries/{itineraryId}/jobs/{itineraryJobId}": {
+        "/admin/users": {
             "get": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Retrieves a specific itinerary file job for the authenticated user.",
+                "description": "Retrieves all registered users with their roles and account status. Only available for support staff and administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
-                ],
-                "summary": "Get an itinerary file job by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Itinerary Job ID",
-                        "name": "itineraryJobId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List all users",
                 "responses": {
                     "200": {
-                        "description": "Itinerary file job details",
-                        "schema": {
-                            "$ref": "#/definitions/responses.GetItineraryJobResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request.",
+                        "description": "List of users",
                         "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
+                            "$ref": "#/definitions/responses.GetUsersResponse"
                         }
                     },
                     "401": {
@@ -474,59 +522,53 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Itinerary job not found.",
-                        "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
-                        }
-                    },
                     "500": {
-                        "description": "Could not get itinerary job. Try again later.",
+                        "description": "Could not retrieve users. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/users/unlock": {
+            "post": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Soft deletes an itinerary file job for the authenticated user. The user must own the itinerary.",
+                "description": "Removes the login delay or temporary lockout applied to an account after repeated failed logins. Only available for support staff and administrators.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Delete an itinerary file job",
+                "summary": "Unlock a user account",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Itinerary Job ID",
-                        "name": "itineraryJobId",
-                        "in": "path",
-                        "required": true
+                        "description": "Account to unlock",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UnlockUserRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Itinerary job deleted.",
+                        "description": "User account unlocked.",
                         "schema": {
-                            "$ref": "#/definitions/responses.DeleteItineraryJobResponse"
+                            "$ref": "#/definitions/responses.UnlockUserResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request.",
+                        "description": "Could not parse request data.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -543,20 +585,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Itinerary job not found.",
-                        "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Cannot delete job that is still pending or running.",
-                        "schema": {
-                            "$ref": "#/definitions/responses.ErrorResponse"
-                        }
-                    },
                     "500": {
-                        "description": "Could not delete job. Try again later.",
+                        "description": "Could not unlock user account. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -564,46 +594,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/itineraries/{itineraryId}/jobs/{itineraryJobId}/file": {
-            "get": {
+        "/admin/users/{userId}/disable": {
+            "put": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Downloads the generated file for the specified itinerary job. The user must be authenticated and the itinerary must belong to them.",
+                "description": "Disables a user account. Disabled users cannot log in, and their tokens and API keys stop working right away. Administrators cannot disable their own account. Only available for administrators.",
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Download itinerary job file",
+                "summary": "Disable a user account",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Itinerary Job ID",
-                        "name": "itineraryJobId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "File downloaded successfully.",
+                        "description": "User disabled.",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/responses.UpdateUserResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request.",
+                        "description": "Invalid user ID.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -621,13 +644,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Itinerary job or file not found.",
+                        "description": "User not found.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not download file. Try again later.",
+                        "description": "Could not update user. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -635,46 +658,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/itineraries/{itineraryId}/jobs/{itineraryJobId}/stop": {
+        "/admin/users/{userId}/enable": {
             "put": {
                 "security": [
                     {
                         "Auth": []
                     }
                 ],
-                "description": "Stops an active itinerary file job for the authenticated user in case it gets stuck after the expected time. The user must own the itinerary.",
+                "description": "Re-enables a previously disabled user account. Only available for administrators.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "itineraries"
+                    "admin"
                 ],
-                "summary": "Stop an itinerary file job",
+                "summary": "Enable a user account",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Itinerary ID",
-                        "name": "itineraryId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Itinerary Job ID",
-                        "name": "itineraryJobId",
+                        "description": "User ID",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Itinerary job stopped.",
+                        "description": "User enabled.",
                         "schema": {
-                            "$ref": "#/definitions/responses.StopItineraryJobResponse"
+                            "$ref": "#/definitions/responses.UpdateUserResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request.",
+                        "description": "Invalid user ID.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -692,13 +708,13 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Itinerary job not found.",
+                        "description": "User not found.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not get itinerary job. Try again later.",
+                        "description": "Could not update user. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -706,9 +722,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Authenticates a user and returns a JWT token.",
+        "/admin/users/{userId}/role": {
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Assigns the user, support or admin role to a user. Administrators cannot change their own role. The new role is applied from the next request of the user, also with the tokens issued before the change. Only available for administrators.",
                 "consumes": [
                     "application/json"
                 ],
@@ -716,35 +737,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "User login",
+                "summary": "Change the role of a user",
                 "parameters": [
                     {
-                        "description": "User login credentials",
-                        "name": "credentials",
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.LoginRequest"
+                            "$ref": "#/definitions/requests.UpdateUserRoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful.",
+                        "description": "User role updated.",
                         "schema": {
-                            "$ref": "#/definitions/responses.LoginResponse"
+                            "$ref": "#/definitions/responses.UpdateUserResponse"
                         }
                     },
                     "400": {
-                        "description": "Could not parse request data.",
+                        "description": "Could not parse request data or invalid user ID.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Wrong user credentials.",
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update user. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
@@ -752,9 +798,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/signup": {
+        "/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves all API keys of the authenticated user, including revoked and expired ones. The keys themselves are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Get all API keys of the authenticated user",
+                "responses": {
+                    "200": {
+                        "description": "List of API keys",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetApiKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve API keys. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Creates a new user account. The password must be at least 8 characters long and contain at least 1 number, 1 upper case letter, and 1 special character.",
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Creates a named API key for machine-to-machine access with the given scopes (itineraries:read, itineraries:write, jobs:read, jobs:write) and an optional expiration date. The key is only returned once. API keys cannot be used to manage API keys.",
                 "consumes": [
                     "application/json"
                 ],
@@ -762,460 +854,8756 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "api-keys"
                 ],
-                "summary": "Register a new user",
+                "summary": "Create a personal API key",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "user",
+                        "description": "API key data",
+                        "name": "apiKey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.SignUpRequest"
+                            "$ref": "#/definitions/requests.CreateApiKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "User created.",
+                        "description": "API key created.",
                         "schema": {
-                            "$ref": "#/definitions/responses.SignUpResponse"
+                            "$ref": "#/definitions/responses.CreateApiKeyResponse"
                         }
                     },
                     "400": {
-                        "description": "Could not parse request data or user already exists.",
+                        "description": "Could not parse request data or invalid scopes/expiration date.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Could not create user. Try again later.",
+                        "description": "Could not create API key. Try again later.",
                         "schema": {
                             "$ref": "#/definitions/responses.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Itinerary": {
-            "type": "object",
-            "required": [
-                "title"
-            ],
-            "properties": {
-                "creationDate": {
-                    "type": "string",
-                    "example": "2024-06-01T00:00:00Z"
-                },
-                "description": {
-                    "type": "string",
-                    "example": "Summer vacation in Spain"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "notes": {
-                    "type": "string",
-                    "example": "I want to enjoy the nightlife"
-                },
-                "ownerId": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "title": {
-                    "type": "string",
-                    "example": "Trip to Spain"
-                },
-                "travelDestinations": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.ItineraryTravelDestination"
+        },
+        "/api-keys/{apiKeyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Revokes an API key of the authenticated user. Revoked keys can no longer be used to authenticate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API key revoked.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.RevokeApiKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid API key ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not revoke API key. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
                     }
-                },
-                "updateDate": {
-                    "type": "string",
-                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "/itineraries": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves a page of the itineraries belonging to the authenticated user, optionally filtered by destination country and city (ignoring case), travel date range and text in the title. Itineraries are sorted by creation date, update date or travel date (the arrival at their first destination), from the newest by default. The response includes the total count of matching itineraries, and a Link header with the first and next pages. Use the nextCursor of a page, with the same filters and sorting, to get the next one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the itineraries of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Destination country",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Destination city",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-07-01T00:00:00Z",
+                        "description": "Start of the travel date range (RFC 3339)",
+                        "name": "travelFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-07-31T23:59:59Z",
+                        "description": "End of the travel date range (RFC 3339)",
+                        "name": "travelTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text in the title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "creationDate",
+                            "updateDate",
+                            "travelDate"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to get",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of itineraries per page (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of itineraries",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItinerariesResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the first and next pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filters, sorting or cursor.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve itineraries. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Updates an existing itinerary. The user must own the itinerary or be one of its editors. The If-Match header must have the ETag of the itinerary as it was retrieved, so changes made by someone else in the meantime are not overwritten.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Update an itinerary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the itinerary",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Itinerary update data",
+                        "name": "itinerary",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UpdateItineraryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateItineraryResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New ETag of the itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to update this itinerary.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "The If-Match header with the ETag of the itinerary is required.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Creates a new itinerary for the authenticated user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Create a new itinerary",
+                "parameters": [
+                    {
+                        "description": "Itinerary data",
+                        "name": "itinerary",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateItineraryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Itinerary created.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateItineraryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/import": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Reads the destinations of an iCalendar file (text/calendar), a CSV file with city, country, arrival and departure columns (text/csv) or a JSON document of the interchange format (application/json), sent as the request body. The format is taken from the Content-Type header unless the format parameter is given. The destinations are validated like the ones of a new itinerary and returned as a preview, with the errors of each row and of the itinerary as a whole. The itinerary is only created when commit is true and the file has no errors.",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "text/calendar"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Import an itinerary",
+                "parameters": [
+                    {
+                        "enum": [
+                            "ics",
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Format of the file, instead of the one of the Content-Type header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Title of the itinerary, instead of the one of the file",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the itinerary if the file has no errors",
+                        "name": "commit",
+                        "in": "query"
+                    },
+                    {
+                        "description": "File to import. Any of the accepted formats, the JSON interchange format is shown",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ItineraryInterchangeDocument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preview of the import.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ImportItineraryResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Itinerary imported.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ImportItineraryResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the new itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "The file cannot be read or has errors.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ImportItineraryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "The file cannot be larger than 1 MiB.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "The file must be an iCalendar, CSV or JSON file.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not import itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/search": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Searches the words of q in the title, description, notes, destinations and latest generated document of the itineraries owned by or shared with the authenticated user. Every word of at least 2 characters must match, as a whole word or as the beginning of one, ignoring case and diacritics. Results are ranked from the best match, weighting matches in the title and destinations the most, and include the title and a snippet of the best matching text with the matched words between \u003cmark\u003e and \u003c/mark\u003e and the rest of the text HTML-escaped. Use the nextCursor of a page, with the same q, to get the next one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Search itineraries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Words to search (up to 10)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to get",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results per page (1-50, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of search results",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SearchItinerariesResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Links to the first and next pages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search text or cursor.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not search itineraries. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/shared": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the itineraries other users shared with the authenticated user, together with the granted permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the itineraries shared with the authenticated user",
+                "responses": {
+                    "200": {
+                        "description": "List of shared itineraries",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetSharedItinerariesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve shared itineraries. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves an itinerary owned by or shared with the authenticated user. The ETag header identifies the version of the itinerary, to send in the If-Match header of updates and deletions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get an itinerary by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary details",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the itinerary"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Deletes an itinerary. Only the owner can delete it. The If-Match header must have the ETag of the itinerary as it was retrieved, so an itinerary changed by someone else in the meantime is not deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Delete an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the itinerary",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteItineraryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Itinerary has pending or running jobs. Please wait for them to complete or stop them before deleting the itinerary.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "The If-Match header with the ETag of the itinerary is required.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Applies a JSON merge patch (RFC 7396) to an itinerary. Members of the patch replace the ones of the itinerary, null members remove them and omitted members are kept. The destinations are replaced as a whole when present, use the destination endpoints to change a single one. The patched itinerary is validated like a full update. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Partially update an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the itinerary",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch of the itinerary",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.PatchItineraryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateItineraryResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New ETag of the itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "The request body must be a JSON merge patch.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "The If-Match header with the ETag of the itinerary is required.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/budget": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Compares the budget of an itinerary with the estimated cost of the stays in its destinations, by category and destination. The daily cost estimates of the cities are multiplied by the days of each stay and all amounts are converted to the currency, which is the one of the budget (or EUR if there is none) by default. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the budget of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code of the currency of the amounts",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Budget summary",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetBudgetSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported currency.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get budget. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces the budget of an itinerary, in its home currency. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Set the budget of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Budget",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.BudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Budget updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateBudgetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update budget. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes the budget of an itinerary. The cost estimates of its destinations are kept. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Remove the budget of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Budget removed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteBudgetResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or budget not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove budget. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/budget/estimates": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Asks the LLM for the daily spending of the travellers in the cities of the destinations of an itinerary, broken down into lodging, food, transport and activities, for the travelling party and budget level of its traveller preferences. The generated estimates replace the previous ones of the cities. The LLM call counts towards the jobs running limit of the user and is cancelled after LLM_REQUEST_TIMEOUT_SECONDS. The currency is the one of the budget (or EUR if there is none) by default. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Generate the cost estimates of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code of the currency of the estimates",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cost estimates generated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GenerateCostEstimatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported currency.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Too many jobs running for your user.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not generate cost estimates. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Cost estimates generation timed out. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Creates a copy of an itinerary owned by the authenticated user, with its description, notes, destinations and the overrides of its traveller preferences. The dates of every destination can be moved a number of days with shiftDays, or so that the trip starts on startDate. The copy keeps the title of the itinerary unless another one is given. The body is optional. The user must own the itinerary or be one of its viewers or editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Clone an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Options of the copy",
+                        "name": "clone",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/requests.CloneItineraryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Itinerary cloned.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateItineraryResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the new itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not clone itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/destinations": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Adds a destination to an itinerary, keeping the other ones. The destinations of the itinerary are validated again with the new one. The change is saved as a new revision. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Add a destination to an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the itinerary",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Destination",
+                        "name": "destination",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.DestinationItem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Destination added.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.AddItineraryDestinationResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New ETag of the itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "The If-Match header with the ETag of the itinerary is required.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not add destination. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/destinations/{destinationId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes a destination from an itinerary, which must keep at least one destination. The change is saved as a new revision. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Delete a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the itinerary",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Destination deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteItineraryDestinationResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New ETag of the itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid destination ID or the itinerary would have no destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "The If-Match header with the ETag of the itinerary is required.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete destination. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Applies a JSON merge patch (RFC 7396) to a destination of an itinerary. Members of the patch replace the ones of the destination and omitted members are kept. The destinations of the itinerary are validated again with the patched one. The change is saved as a new revision. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Partially update a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the itinerary",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch of the destination",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.PatchDestinationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Destination updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateItineraryDestinationResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New ETag of the itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "The request body must be a JSON merge patch.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "The If-Match header with the ETag of the itinerary is required.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update destination. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/destinations/{destinationId}/accommodation": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Gets where the travellers sleep during the stay in a destination of an itinerary. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the accommodation of a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Accommodation",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetAccommodationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary, destination or accommodation not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Adds or replaces where the travellers sleep during the stay in a destination of an itinerary. The check-in and check-out must be between the arrival and departure dates of the destination, and the cost of the whole stay needs a currency. The accommodation is matched to the stay in the city its check-in falls in, so it is kept when the destinations of the itinerary are replaced. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Set the accommodation of a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Accommodation",
+                        "name": "accommodation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.AccommodationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Accommodation updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateAccommodationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid accommodation.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update accommodation. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes the accommodation of a destination of an itinerary. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Remove the accommodation of a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Accommodation removed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteAccommodationResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary, destination or accommodation not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove accommodation. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/destinations/{destinationId}/cost-estimate": {
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces the estimate of what the travellers spend a day in the city of a destination of an itinerary, by category. The estimate applies to every stay in the city and is kept when the destinations of the itinerary are replaced. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Set the cost estimate of a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Daily cost estimate",
+                        "name": "estimate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CostEstimateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cost estimate updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateCostEstimateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update cost estimate. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes the daily cost estimate of the city of a destination of an itinerary. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Remove the cost estimate of a destination of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination ID",
+                        "name": "destinationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cost estimate removed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteCostEstimateResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary, destination or cost estimate not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove cost estimate. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves all file jobs associated with the specified itinerary. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get all file jobs for an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of itinerary file jobs",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryJobsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve jobs. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Starts an asynchronous job to generate a file for the specified itinerary. The user must own the itinerary or be one of its editors. The file is generated with the latest version of the prompt template with the name, which is the own one of the user or else the global one, and the default template if no name is given. The file is written in the language, or in the one of the traveller preferences if not given, with dates and numbers formatted for its locale. With the plan source, the file is rendered from the day-by-day plan of the itinerary instead of being generated by the LLM. The job records the version, language and source used.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Start itinerary file generation job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the prompt template",
+                        "name": "promptTemplate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 tag of the language of the file, like es or pt-BR",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source of the content of the file: llm (default) or plan",
+                        "name": "source",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job started successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.StartItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid language or source, or the plan of the itinerary has no activities.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Prompt template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Too many jobs running for your user. Please wait for existing jobs to complete.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/jobs/{itineraryJobId}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves a specific itinerary file job. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get an itinerary file job by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary file job details",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Soft deletes an itinerary file job for the authenticated user. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Delete an itinerary file job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary job deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot delete job that is still pending or running.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/jobs/{itineraryJobId}/file": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Downloads the generated file for the specified itinerary job. The itinerary must be owned by or shared with the authenticated user. The Content-Language header has the language of the file, if the job has one.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Download itinerary job file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File downloaded successfully.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary job or file not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not download file. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/jobs/{itineraryJobId}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Gets the messages of the users and the replies of the assistant about the file of a job, oldest first. Each message of a user has the ID of the job with the new version of the file that answers it, which its reply has too. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the conversation about the file of an itinerary job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Conversation",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryJobMessagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid itinerary job ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get messages. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Adds a message to the conversation about the file of a completed job, like \"make day 3 more relaxed\", and starts a new job that answers it with a new version of the file. The LLM gets the latest completed version of the conversation, or the file of the job if there is none yet, with the previous messages, and its reply is added to the conversation when the new job completes. The previous message must have been answered before sending a new one. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Send a message about the file of an itinerary job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.JobMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job started successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.StartItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data, the job is not completed or the previous message is still being answered.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Too many jobs running for your user. Please wait for existing jobs to complete.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/jobs/{itineraryJobId}/regenerate": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Starts a new job that rewrites only the part of the file of a completed job about a range of dates or the stay in a destination, following the instructions, if any. The rest of the file is kept, and the base job and its file stay available for comparison. The new job has the ID of the base job and the dates of the part it rewrites. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Regenerate a part of the file of an itinerary job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the base itinerary job",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dates or destination to regenerate",
+                        "name": "scope",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.RegenerateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job started successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.StartItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data, invalid scope or the base job is not completed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary, itinerary job or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Too many jobs running for your user. Please wait for existing jobs to complete.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/jobs/{itineraryJobId}/stop": {
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Stops an active itinerary file job for the authenticated user in case it gets stuck after the expected time. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Stop an itinerary file job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itinerary Job ID",
+                        "name": "itineraryJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary job stopped.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.StopItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/plan": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Gets the days of the plan of an itinerary that have activities, in order, each with its activities in order. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the day-by-day plan of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Plan",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetPlanResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get plan. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/plan/activities": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Adds an activity at the end of its day in the plan of an itinerary. The date must be between the arrival in the first destination and the departure from the last one. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Add an activity to the plan of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Activity",
+                        "name": "activity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ActivityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Activity added.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateActivityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid activity.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not add activity. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/plan/activities/{activityId}": {
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces an activity of the plan of an itinerary. The activity keeps its place in its day, or goes at the end of the new one if its date changes. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Update an activity of the plan of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Activity ID",
+                        "name": "activityId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Activity",
+                        "name": "activity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ActivityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Activity updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateActivityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid activity.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or activity not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update activity. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes an activity from the plan of an itinerary. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Remove an activity from the plan of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Activity ID",
+                        "name": "activityId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Activity removed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteActivityResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or activity not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove activity. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/plan/days/{date}/order": {
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Sets the order of the activities of a day of the plan of an itinerary. The IDs must be the ones of all the activities of the day, each of them once. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Reorder the activities of a day of the plan of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day of the plan, like 2024-07-01",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Activity IDs in order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ReorderActivitiesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Activities reordered.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ReorderActivitiesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data, invalid date or invalid order.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or day not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not reorder activities. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/plan/generate": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Starts an asynchronous itinerary file job that replaces the plan of an itinerary with activities for every day of the trip generated by the LLM for its destinations and traveller preferences, and renders the file of the itinerary from it. The generated plan can be edited afterwards. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Generate the plan of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Job started successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.StartItineraryJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The itinerary has no destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Too many jobs running for your user. Please wait for existing jobs to complete.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create job. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the overrides of the traveller preferences set for an itinerary, together with the effective preferences it is generated with: the ones of its owner with the overrides applied. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the traveller preferences of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Traveller preferences",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryTravellerPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get traveller preferences. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces the overrides of the traveller preferences of the owner for an itinerary, e.g. to generate it for a trip with kids. Only the set attributes override the preferences of the owner. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Override the traveller preferences for an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Overrides of the traveller preferences",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.TravellerPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Traveller preferences updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateTravellerPreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update traveller preferences. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes the overrides of the traveller preferences for an itinerary, so it is generated with the preferences of its owner again. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Remove the traveller preferences of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Traveller preferences of the itinerary removed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteItineraryTravellerPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove traveller preferences. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the revisions of an itinerary from the newest, with their author and date. A revision is saved when the itinerary is created and on every update or restore. Use the revision endpoint to get the destinations of a revision. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the revision history of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of revisions",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryRevisionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve itinerary revisions. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Lists the changes made to an itinerary from a revision to another one. Each change has the JSON path of the changed field with its old and new values. Destinations are compared by their position, and added or removed destinations have no old or new value respectively. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Compare two revisions of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of the revision to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of the revision to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Changes between the revisions",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryRevisionsDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid revision numbers.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or revision not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not compare itinerary revisions. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/revisions/{revisionNumber}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the content of an itinerary as it was in one of its revisions, with its destinations. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get a revision of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "revisionNumber",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revision",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryRevisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid revision number.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or revision not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve itinerary revision. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/revisions/{revisionNumber}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Brings the title, description, notes and destinations of an itinerary back to the ones of an earlier revision. The restored content is saved as a new revision, so the history is kept. The authenticated user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Restore a revision of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of the revision to restore",
+                        "name": "revisionNumber",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary restored.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.RestoreItineraryRevisionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New ETag of the itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid revision number.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or revision not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The itinerary was changed since it was retrieved. Get it again and retry.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not restore itinerary revision. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/share-links": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves all the share links of an itinerary, including revoked and expired ones, with their access count. The tokens themselves are never returned. Only the owner can list share links.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the public share links of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of share links",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetShareLinksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve share links. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Creates a read-only link that gives access to the itinerary and its latest generated document to anyone knowing it, without an account. The link can target a specific completed job, expire at a given date and require a password. The token is only returned once. Only the owner can create share links.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Create a public share link for an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Share link data",
+                        "name": "shareLink",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateShareLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Share link created.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateShareLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data, invalid expiration date or the job is not completed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or itinerary job not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create share link. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/share-links/{shareLinkId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Revokes a share link of an itinerary. Revoked links can no longer be used to access the itinerary. Only the owner can revoke share links.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Revoke a public share link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Share link ID",
+                        "name": "shareLinkId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Share link revoked.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.RevokeShareLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid share link ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or share link not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not revoke share link. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/shares": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the users an itinerary is shared with and their permissions. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the users an itinerary is shared with",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of itinerary shares",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItinerarySharesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve itinerary shares. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Grants a registered user the viewer or editor permission on an itinerary. Viewers can read the itinerary and download its files, while editors can also update it and manage its file jobs. Sharing again with the same user changes their permission. Only the owner can share the itinerary.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Share an itinerary with another user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User and permission",
+                        "name": "share",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ShareItineraryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary shared.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ShareItineraryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or the itinerary cannot be shared with its owner.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or user not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not share itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/shares/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Revokes the access of a user to an itinerary. The owner can revoke the access of anyone, while the other users can only leave an itinerary shared with them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Stop sharing an itinerary with a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary unshared.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UnshareItineraryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or share not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not unshare itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/template": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Saves the route of an itinerary as a template of the authenticated user, without dates: every destination is placed by the whole days since the arrival at the first one. The template takes the title, description and notes of the itinerary, unless another title is given. Shared templates can be used by every user. The user must own the itinerary or be one of its viewers or editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itinerary-templates"
+                ],
+                "summary": "Save an itinerary as a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template options",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateTemplateFromItineraryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Itinerary template created.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateItineraryTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create itinerary template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/transport-legs": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Gets how the travellers get from each destination of an itinerary to the next one, in travel order. The legs whose cities are no longer consecutive destinations go last, without fromDestinationId and toDestinationId. The itinerary must be owned by or shared with the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Get the transport legs of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transport legs",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetTransportLegsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Adds how the travellers get from a destination of an itinerary to the next one. The leg must depart between the arrival in and the departure from the destination it goes from, and arrive at the latest on the departure date from the next one. The leg belongs to the cities it connects, so it is kept when the destinations of the itinerary are replaced. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Add a transport leg to an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transport leg",
+                        "name": "transportLeg",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.TransportLegRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Transport leg added.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateTransportLegResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid transport leg.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not add transport leg. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itineraries/{itineraryId}/transport-legs/{transportLegId}": {
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces a transport leg of an itinerary, which is validated like a new one. The user must own the itinerary or be one of its editors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Update a transport leg of an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transport leg ID",
+                        "name": "transportLegId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transport leg",
+                        "name": "transportLeg",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.TransportLegRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transport leg updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateTransportLegResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid transport leg.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary, transport leg or destination not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update transport leg. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Removes a transport leg of an itinerary. The user must own the itinerary or be one of its editors.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itineraries"
+                ],
+                "summary": "Remove a transport leg from an itinerary",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary ID",
+                        "name": "itineraryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transport leg ID",
+                        "name": "transportLegId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transport leg removed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteTransportLegResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary or transport leg not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove transport leg. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itinerary-templates": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the itinerary templates of the authenticated user and the ones shared by other users, sorted by title.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itinerary-templates"
+                ],
+                "summary": "List the itinerary templates",
+                "responses": {
+                    "200": {
+                        "description": "Itinerary templates",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary templates. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Creates an itinerary template of the authenticated user. Templates have no dates: every destination is arrived at on the startDay day of the trip (0 being its start date) and departed from days days later. The destinations follow the same rules as the ones of an itinerary once placed on the calendar. Shared templates can be used by every user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itinerary-templates"
+                ],
+                "summary": "Create an itinerary template",
+                "parameters": [
+                    {
+                        "description": "Itinerary template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateItineraryTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Itinerary template created.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateItineraryTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create itinerary template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itinerary-templates/{templateId}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves an itinerary template of the authenticated user or shared by another user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itinerary-templates"
+                ],
+                "summary": "Get an itinerary template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary template ID",
+                        "name": "templateId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary template",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetItineraryTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid itinerary template ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get itinerary template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Deletes an itinerary template of the authenticated user. The itineraries created from it are kept. Shared templates can only be deleted by their owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itinerary-templates"
+                ],
+                "summary": "Delete an itinerary template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary template ID",
+                        "name": "templateId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary template deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteItineraryTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid itinerary template ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete itinerary template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/itinerary-templates/{templateId}/instantiate": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Creates an itinerary of the authenticated user from an itinerary template of theirs or shared by another user, placing its destinations on the calendar from the start date. The itinerary takes the title, description and notes of the template, unless another title is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "itinerary-templates"
+                ],
+                "summary": "Create an itinerary from a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itinerary template ID",
+                        "name": "templateId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Start date and title of the itinerary",
+                        "name": "instantiation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.InstantiateItineraryTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Itinerary created.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreateItineraryResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the new itinerary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid destinations.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Itinerary template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticates a user and returns a JWT token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "User login credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Wrong user credentials.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "This account is disabled.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Unexpected error. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the profile of the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the authenticated user",
+                "responses": {
+                    "200": {
+                        "description": "User profile",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetMeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get user. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Permanently deletes the account of the authenticated user together with all their itineraries, destinations and API keys. Generated itinerary files are removed shortly after by the dead jobs cleanup. The password is required to confirm the deletion.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete the authenticated user",
+                "parameters": [
+                    {
+                        "description": "Password confirmation",
+                        "name": "confirmation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.DeleteMeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteMeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized or wrong password.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete account. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Updates the profile of the authenticated user. Only the attributes present in the request are changed. The email must be valid and not used by another account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the authenticated user",
+                "parameters": [
+                    {
+                        "description": "Profile attributes to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UpdateMeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateMeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid email.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The email is already in use.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update user. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the audit events of the authenticated user from the newest to the oldest, optionally filtered by type and date range. Use the nextCursor of a page to get the next one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the audit events of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "itinerary.created",
+                        "description": "Audit event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-06-01T00:00:00Z",
+                        "description": "Start of the date range (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-06-30T23:59:59Z",
+                        "description": "End of the date range (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to get",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events per page (1-200, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of audit events",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetAuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filters or cursor.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get audit events. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/export": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Starts a background job that collects the profile, itineraries, destinations, itinerary file job metadata, audit events and generated itinerary files of the authenticated user into a single ZIP file. Only one data export can be in progress at a time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Request an export of all the user data",
+                "responses": {
+                    "202": {
+                        "description": "Data export started successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.StartDataExportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A data export is already in progress.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create data export. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/exports": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the data export jobs of the authenticated user with their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all data exports of the authenticated user",
+                "responses": {
+                    "200": {
+                        "description": "List of data exports",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetDataExportsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get data exports. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/exports/{exportJobId}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the status of a data export job of the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a data export by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Data export job ID",
+                        "name": "exportJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data export job details",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetDataExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data export ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Data export not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get data export. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Deletes a data export job of the authenticated user. Its ZIP file is removed shortly after by the dead jobs cleanup.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a data export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Data export job ID",
+                        "name": "exportJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data export deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeleteDataExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data export ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Data export not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot delete a data export that is still pending or running.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete data export. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/exports/{exportJobId}/file": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Downloads the ZIP file generated by a completed data export job of the authenticated user.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Download a data export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Data export job ID",
+                        "name": "exportJobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File downloaded successfully.",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid data export ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Data export or file not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not download file. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces the password of the authenticated user. The current password is required to confirm the change, and wrong attempts count towards the login brute-force protection. The new password must follow the same rules as on sign up.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change the password of the authenticated user",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ChangePasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or the new password is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized or wrong current password.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not change password. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the interests, pace, budget level, dietary restrictions, mobility needs, travelling party and preferred language the itineraries of the authenticated user are generated for. Unset preferences are omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the traveller preferences of the authenticated user",
+                "responses": {
+                    "200": {
+                        "description": "Traveller preferences",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetTravellerPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get traveller preferences. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Replaces the traveller preferences of the authenticated user, which personalise the itineraries generated from then on. Omitted attributes are unset. The language is a BCP 47 tag like es or pt-BR.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the traveller preferences of the authenticated user",
+                "parameters": [
+                    {
+                        "description": "Traveller preferences",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.TravellerPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Traveller preferences updated.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.UpdateTravellerPreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update traveller preferences. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/prompt-templates": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the latest version of every prompt template of the authenticated user. A template of the user is used instead of the global one with the same name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prompt-templates"
+                ],
+                "summary": "List the prompt templates of the authenticated user",
+                "responses": {
+                    "200": {
+                        "description": "Prompt templates of the user",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetPromptTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get prompt templates. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Saves a new version of a prompt template of the authenticated user, the first one if there is no template with the name. The template is a Go template which can only use the variables title, description, notes, ownerId, travelDestinations (with country, city, arrivalDate, departureDate, and localArrivalDate and localDepartureDate formatted for the locale of the language), interests, travellerProfile, language and languageName, and the built-in functions of Go templates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prompt-templates"
+                ],
+                "summary": "Save a prompt template of the authenticated user",
+                "parameters": [
+                    {
+                        "description": "Prompt template",
+                        "name": "promptTemplate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.PromptTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Prompt template saved.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.CreatePromptTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or invalid template.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not save prompt template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/prompt-templates/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the versions of a prompt template of the authenticated user, from the newest.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prompt-templates"
+                ],
+                "summary": "List the versions of a prompt template of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prompt template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Versions of the prompt template",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetPromptTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Prompt template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get prompt template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Deletes all the versions of a prompt template of the authenticated user, so the global template with the same name, if any, is used again. The versions stay available for the jobs generated with them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prompt-templates"
+                ],
+                "summary": "Delete a prompt template of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Prompt template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Prompt template deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.DeletePromptTemplateResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Prompt template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not delete prompt template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/places/suggest": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Suggests the cities of the offline gazetteer destinations are normalized against, to autocomplete the city and country of a destination. Cities match when their name, an alternate name or a word of them starts with q, ignoring case and diacritics. Cities named as q go first, then the most populous ones. Each place has the normalized city and country names, the ISO 3166-1 alpha-2 code of the country, the coordinates and the IANA time zone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "places"
+                ],
+                "summary": "Suggest places",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Beginning of the name of the city",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code or name of the country of the cities",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of places (1-50, default 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Suggested places",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SuggestPlacesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query or unknown country.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not suggest places. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prompt-templates": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves the latest version of every global prompt template, managed by the administrators. Itineraries are generated with the template named itinerary unless another one is asked for, and with the built-in one if there is no such template.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prompt-templates"
+                ],
+                "summary": "List the global prompt templates",
+                "responses": {
+                    "200": {
+                        "description": "Global prompt templates",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetPromptTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get prompt templates. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/prompt-templates/{promptTemplateId}": {
+            "get": {
+                "security": [
+                    {
+                        "Auth": []
+                    }
+                ],
+                "description": "Retrieves a version of a prompt template, like the one an itinerary file job was generated with, even if the template was deleted since. The template must be global or owned by the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prompt-templates"
+                ],
+                "summary": "Get a version of a prompt template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Prompt template version ID",
+                        "name": "promptTemplateId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Prompt template version",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetPromptTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid prompt template ID.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not authorized.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "You do not have permission to access this resource.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Prompt template not found.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get prompt template. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/share-links/{token}": {
+            "get": {
+                "description": "Retrieves the shared itinerary, with its destinations, and the content of its latest generated document (or of the job targeted by the link). No account is needed. Password-protected links require the password in the X-Share-Password header, and repeated wrong passwords from the same IP are delayed and then locked out like failed logins, and too many wrong passwords from any IP lock out the link. Every access is counted and audited.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Get an itinerary through a public share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password of the share link",
+                        "name": "X-Share-Password",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Shared itinerary and document",
+                        "schema": {
+                            "$ref": "#/definitions/responses.GetPublicItineraryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Password required or invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Share link not found, revoked or expired.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong share link passwords. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not retrieve shared itinerary. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/share-links/{token}/file": {
+            "get": {
+                "description": "Downloads the latest generated document of the shared itinerary, or the document of the job targeted by the link. No account is needed. Password-protected links require the password in the X-Share-Password header, and repeated wrong passwords from the same IP are delayed and then locked out like failed logins, and too many wrong passwords from any IP lock out the link. Every access is counted and audited.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Download the document of an itinerary through a public share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password of the share link",
+                        "name": "X-Share-Password",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Itinerary document",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Password required or invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Share link not found, revoked or expired, or no document available.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong share link passwords. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not download shared document. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Creates a new user account. The password must be at least 8 characters long and contain at least 1 number, 1 upper case letter, and 1 special character.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SignUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.SignUpResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Could not parse request data or user already exists.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create user. Try again later.",
+                        "schema": {
+                            "$ref": "#/definitions/responses.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.ApiKey": {
+            "type": "object",
+            "properties": {
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "expirationDate": {
+                    "type": "string",
+                    "example": "2025-06-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "keyPrefix": {
+                    "type": "string",
+                    "example": "tak_1a2b3c4d"
+                },
+                "lastUsedDate": {
+                    "type": "string",
+                    "example": "2024-06-02T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "CI pipeline"
+                },
+                "revocationDate": {
+                    "type": "string",
+                    "example": "2024-06-03T00:00:00Z"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "itineraries:read",
+                        "jobs:write"
+                    ]
+                }
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "eventDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "eventDescription": {
+                    "type": "string",
+                    "example": "Successful user login"
+                },
+                "eventType": {
+                    "type": "string",
+                    "example": "user.login_succeeded"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.BudgetSummary": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/models.ItineraryBudget"
+                },
+                "budgetAmount": {
+                    "type": "number",
+                    "example": 3000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DestinationBudget"
+                    }
+                },
+                "destinationsWithoutEstimate": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "estimatedCost": {
+                    "$ref": "#/definitions/models.CostBreakdown"
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "overBudget": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "remaining": {
+                    "type": "number",
+                    "example": 2060
+                }
+            }
+        },
+        "models.CostBreakdown": {
+            "type": "object",
+            "properties": {
+                "activities": {
+                    "type": "number",
+                    "example": 160
+                },
+                "food": {
+                    "type": "number",
+                    "example": 240
+                },
+                "lodging": {
+                    "type": "number",
+                    "example": 480
+                },
+                "total": {
+                    "type": "number",
+                    "example": 940
+                },
+                "transport": {
+                    "type": "number",
+                    "example": 60
+                }
+            }
+        },
+        "models.DataExportJob": {
+            "type": "object",
+            "properties": {
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "endDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:01:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "startDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "statusDescription": {
+                    "type": "string",
+                    "example": "Data export completed successfully"
+                }
+            }
+        },
+        "models.DestinationAccommodation": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "Gran Vía 25, 28013 Madrid"
+                },
+                "checkIn": {
+                    "type": "string",
+                    "example": "2024-07-01T15:00:00+02:00"
+                },
+                "checkOut": {
+                    "type": "string",
+                    "example": "2024-07-05T11:00:00+02:00"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "confirmationNumber": {
+                    "type": "string",
+                    "example": "HX-48213"
+                },
+                "cost": {
+                    "type": "number",
+                    "example": 480
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "destinationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Hotel Gran Vía"
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "models.DestinationBudget": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "days": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "destinationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "estimate": {
+                    "$ref": "#/definitions/models.DestinationCostEstimate"
+                },
+                "estimatedCost": {
+                    "$ref": "#/definitions/models.CostBreakdown"
+                }
+            }
+        },
+        "models.DestinationCostEstimate": {
+            "type": "object",
+            "properties": {
+                "activities": {
+                    "type": "number",
+                    "example": 40
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "food": {
+                    "type": "number",
+                    "example": 60
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lodging": {
+                    "type": "number",
+                    "example": 120
+                },
+                "source": {
+                    "type": "string",
+                    "example": "generated"
+                },
+                "transport": {
+                    "type": "number",
+                    "example": 15
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "models.Itinerary": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "accommodations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DestinationAccommodation"
+                    }
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Summer vacation in Spain"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "I want to enjoy the nightlife"
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Trip to Spain"
+                },
+                "transportLegs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryTransportLeg"
+                    }
+                },
+                "travelDestinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryTravelDestination"
+                    }
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ItineraryActivity": {
+            "type": "object",
+            "properties": {
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Calle de Ruiz de Alarcón 23, Madrid"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Free entry from 6 pm"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "timeSlot": {
+                    "type": "string",
+                    "example": "morning"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Visit the Prado Museum"
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "models.ItineraryBudget": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 3000
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "models.ItineraryDay": {
+            "type": "object",
+            "properties": {
+                "activities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryActivity"
+                    }
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
                 }
             }
         },
         "models.ItineraryFileJob": {
             "type": "object",
             "properties": {
-                "asyncTaskId": {
-                    "description": "Optional, async task ID from task manager",
+                "asyncTaskId": {
+                    "description": "Optional, async task ID from task manager",
+                    "type": "string",
+                    "example": "e2467dd0-db8a-49db-a5cb-9474f8e63933"
+                },
+                "baseJobId": {
+                    "description": "BaseJobID is the ID of the job whose file was regenerated for a part of the trip, which is kept for comparison. Jobs that\ngenerated the whole trip have none",
+                    "type": "integer",
+                    "example": 4
+                },
+                "creationDate": {
+                    "description": "Status can be \"running\", \"completed\", \"failed\", or \"stopped\"",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "endDate": {
+                    "description": "EndDate is set when the job ends",
+                    "type": "string",
+                    "example": "2024-06-01T00:01:00Z"
+                },
+                "fileManager": {
+                    "description": "Optional, used for file management",
+                    "type": "string",
+                    "example": "local"
+                },
+                "filepath": {
+                    "description": "Optional, used for file storage",
+                    "type": "string",
+                    "example": "/path/to/file.txt"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "description": "ItineraryID is the ID of the itinerary associated with this job",
+                    "type": "integer",
+                    "example": 123
+                },
+                "itineraryRevision": {
+                    "description": "ItineraryRevision is the number of the itinerary revision the job was generated from. Jobs created before the revision history have none",
+                    "type": "integer",
+                    "example": 3
+                },
+                "language": {
+                    "description": "Language is the BCP 47 tag of the language the file is written in. Jobs without a target language have none",
+                    "type": "string",
+                    "example": "es"
+                },
+                "promptTemplateId": {
+                    "description": "PromptTemplateID is the ID of the version of the prompt template the job was generated with. Jobs generated with the built-in prompt have none",
+                    "type": "integer",
+                    "example": 2
+                },
+                "scopeEndDate": {
+                    "type": "string",
+                    "example": "2024-07-04T00:00:00Z"
+                },
+                "scopeStartDate": {
+                    "description": "ScopeStartDate and ScopeEndDate are the first and last days of the trip regenerated from the file of the base job, at midnight UTC",
+                    "type": "string",
+                    "example": "2024-07-03T00:00:00Z"
+                },
+                "source": {
+                    "description": "Source is where the content of the file comes from: \"llm\" if it was generated by the LLM, \"plan\" if it was rendered from the\nday-by-day plan of the itinerary, or \"llm_plan\" if it was rendered from the plan the job generated with the LLM",
+                    "type": "string",
+                    "example": "llm"
+                },
+                "startDate": {
+                    "description": "CreationDate is set when the job is created",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "statusDescription": {
+                    "type": "string",
+                    "example": "Job completed successfully"
+                }
+            }
+        },
+        "models.ItineraryImport": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Summer vacation in Spain"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "type": "string",
+                    "example": "csv"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "I want to enjoy the nightlife"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryImportRow"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Trip to Spain"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ItineraryImportRow": {
+            "type": "object",
+            "properties": {
+                "arrivalDate": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "departureDate": {
+                    "type": "string",
+                    "example": "2024-07-05T00:00:00Z"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ItineraryInterchangeDestination": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-07-05"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                }
+            }
+        },
+        "models.ItineraryInterchangeDocument": {
+            "type": "object",
+            "properties": {
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryInterchangeDestination"
+                    }
+                },
+                "trip": {
+                    "$ref": "#/definitions/models.ItineraryInterchangeTrip"
+                }
+            }
+        },
+        "models.ItineraryInterchangeTrip": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Summer vacation in Spain"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Trip to Spain"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "I want to enjoy the nightlife"
+                }
+            }
+        },
+        "models.ItineraryJobMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Make the third day more relaxed"
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "jobId": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "outputJobId": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                }
+            }
+        },
+        "models.ItineraryRevision": {
+            "type": "object",
+            "properties": {
+                "authorId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Summer vacation in Spain"
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "I want to enjoy the nightlife"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "restoredFrom": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Trip to Spain"
+                },
+                "travelDestinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryRevisionDestination"
+                    }
+                }
+            }
+        },
+        "models.ItineraryRevisionChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "travelDestinations[1].city"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "Seville"
+                }
+            }
+        },
+        "models.ItineraryRevisionDestination": {
+            "type": "object",
+            "properties": {
+                "arrivalDate": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "departureDate": {
+                    "type": "string",
+                    "example": "2024-07-05T00:00:00Z"
+                }
+            }
+        },
+        "models.ItinerarySearchResult": {
+            "type": "object",
+            "properties": {
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "score": {
+                    "type": "number",
+                    "example": 7.25
+                },
+                "snippet": {
+                    "type": "string",
+                    "example": "...a \u003cmark\u003eKyoto\u003c/mark\u003e \u003cmark\u003efood\u003c/mark\u003e tour in Nishiki market..."
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Trip to Japan"
+                },
+                "titleHighlight": {
+                    "type": "string",
+                    "example": "Trip to \u003cmark\u003eJapan\u003c/mark\u003e \u0026amp; Korea"
+                }
+            }
+        },
+        "models.ItineraryShare": {
+            "type": "object",
+            "properties": {
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itinerary": {
+                    "$ref": "#/definitions/models.Itinerary"
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "permission": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "userEmail": {
+                    "type": "string",
+                    "example": "friend@example.com"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ItineraryTemplate": {
+            "type": "object",
+            "properties": {
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Yearly team offsite in Portugal"
+                },
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryTemplateDestination"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Book the team dinner in advance"
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "shared": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Corporate offsite"
+                }
+            }
+        },
+        "models.ItineraryTemplateDestination": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Lisbon"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Portugal"
+                },
+                "days": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "startDay": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "models.ItineraryTransportLeg": {
+            "type": "object",
+            "properties": {
+                "arrivalTime": {
+                    "type": "string",
+                    "example": "2024-07-05T12:15:00+02:00"
+                },
+                "bookingReference": {
+                    "type": "string",
+                    "example": "X7K2P9"
+                },
+                "carrier": {
+                    "type": "string",
+                    "example": "Renfe"
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "departureTime": {
+                    "type": "string",
+                    "example": "2024-07-05T09:30:00+02:00"
+                },
+                "fromCity": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "fromCountry": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "fromDestinationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "train"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Seats 4A and 4B"
+                },
+                "toCity": {
+                    "type": "string",
+                    "example": "Barcelona"
+                },
+                "toCountry": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "toDestinationId": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "models.ItineraryTravelDestination": {
+            "type": "object",
+            "required": [
+                "arrivalDate",
+                "city",
+                "country",
+                "departureDate"
+            ],
+            "properties": {
+                "arrivalDate": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "countryCode": {
+                    "type": "string",
+                    "example": "ES"
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "departureDate": {
+                    "type": "string",
+                    "example": "2024-07-05T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "latitude": {
+                    "type": "number",
+                    "example": 40.4165
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": -3.70256
+                },
+                "timeZone": {
+                    "type": "string",
+                    "example": "Europe/Madrid"
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "models.Place": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "countryCode": {
+                    "type": "string",
+                    "example": "ES"
+                },
+                "latitude": {
+                    "type": "number",
+                    "example": 40.4165
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": -3.70256
+                },
+                "population": {
+                    "type": "integer",
+                    "example": 3255944
+                },
+                "timeZone": {
+                    "type": "string",
+                    "example": "Europe/Madrid"
+                }
+            }
+        },
+        "models.PromptTemplate": {
+            "type": "object",
+            "properties": {
+                "authorId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "itinerary"
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "systemMessage": {
+                    "type": "string",
+                    "example": "You are a helpful expert and guide of international travel."
+                },
+                "template": {
+                    "type": "string",
+                    "example": "Create a relaxed travel itinerary titled {{.title}}."
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ShareLink": {
+            "type": "object",
+            "properties": {
+                "accessCount": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "expirationDate": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "hasPassword": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "itineraryJobId": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "lastAccessDate": {
+                    "type": "string",
+                    "example": "2024-06-02T00:00:00Z"
+                },
+                "revocationDate": {
+                    "type": "string",
+                    "example": "2024-06-03T00:00:00Z"
+                },
+                "tokenPrefix": {
+                    "type": "string",
+                    "example": "tas_1a2b3c4d"
+                }
+            }
+        },
+        "models.TravellerPreferences": {
+            "type": "object",
+            "properties": {
+                "adults": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "budgetLevel": {
+                    "type": "string",
+                    "example": "moderate"
+                },
+                "children": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "dietaryRestrictions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vegetarian"
+                    ]
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "museums",
+                        "street food",
+                        "hiking"
+                    ]
+                },
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "language": {
+                    "type": "string",
+                    "example": "es"
+                },
+                "mobilityNeeds": {
+                    "type": "string",
+                    "example": "Wheelchair user, no long walks"
+                },
+                "pace": {
+                    "type": "string",
+                    "example": "relaxed"
+                },
+                "seniors": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "creationDate": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "disabledDate": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "test@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lastLoginDate": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                },
+                "updateDate": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "requests.AccommodationRequest": {
+            "type": "object",
+            "required": [
+                "checkIn",
+                "checkOut",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "Gran Vía 25, 28013 Madrid"
+                },
+                "checkIn": {
+                    "type": "string",
+                    "example": "2024-07-01T15:00:00+02:00"
+                },
+                "checkOut": {
+                    "type": "string",
+                    "example": "2024-07-05T11:00:00+02:00"
+                },
+                "confirmationNumber": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "HX-48213"
+                },
+                "cost": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 480
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Hotel Gran Vía"
+                }
+            }
+        },
+        "requests.ActivityRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "timeSlot",
+                "title"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "location": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Calle de Ruiz de Alarcón 23, Madrid"
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "Free entry from 6 pm"
+                },
+                "timeSlot": {
+                    "type": "string",
+                    "enum": [
+                        "morning",
+                        "afternoon",
+                        "evening"
+                    ],
+                    "example": "morning"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Visit the Prado Museum"
+                }
+            }
+        },
+        "requests.BudgetRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "currency"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 3000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                }
+            }
+        },
+        "requests.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "currentPassword",
+                "newPassword"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Password123-"
+                },
+                "newPassword": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "NewPassword123-"
+                }
+            }
+        },
+        "requests.CloneItineraryRequest": {
+            "type": "object",
+            "properties": {
+                "shiftDays": {
+                    "type": "integer",
+                    "maximum": 3660,
+                    "minimum": -3660,
+                    "example": 364
+                },
+                "startDate": {
+                    "type": "string",
+                    "example": "2025-06-02T00:00:00Z"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Corporate offsite 2025"
+                }
+            }
+        },
+        "requests.CostEstimateRequest": {
+            "type": "object",
+            "required": [
+                "currency"
+            ],
+            "properties": {
+                "activities": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 40
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "food": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 60
+                },
+                "lodging": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 120
+                },
+                "transport": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 15
+                }
+            }
+        },
+        "requests.CreateApiKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expirationDate": {
+                    "type": "string",
+                    "example": "2025-06-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "CI pipeline"
+                },
+                "scopes": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "itineraries:read",
+                        "jobs:write"
+                    ]
+                }
+            }
+        },
+        "requests.CreateItineraryRequest": {
+            "type": "object",
+            "required": [
+                "destinations",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "Summer vacation in Spain"
+                },
+                "destinations": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/requests.DestinationItem"
+                    }
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "I want to enjoy the nightlife"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Trip to Spain"
+                }
+            }
+        },
+        "requests.CreateItineraryTemplateRequest": {
+            "type": "object",
+            "required": [
+                "destinations",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "Yearly team offsite in Portugal"
+                },
+                "destinations": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/requests.TemplateDestinationItem"
+                    }
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "Book the team dinner in advance"
+                },
+                "shared": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Corporate offsite"
+                }
+            }
+        },
+        "requests.CreateShareLinkRequest": {
+            "type": "object",
+            "properties": {
+                "expirationDate": {
+                    "type": "string",
+                    "example": "2025-06-01T00:00:00Z"
+                },
+                "itineraryJobId": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "example": "s3cr3t"
+                }
+            }
+        },
+        "requests.CreateTemplateFromItineraryRequest": {
+            "type": "object",
+            "properties": {
+                "shared": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Corporate offsite"
+                }
+            }
+        },
+        "requests.DeleteMeRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Password123-"
+                }
+            }
+        },
+        "requests.DestinationItem": {
+            "type": "object",
+            "required": [
+                "arrivalDate",
+                "city",
+                "country",
+                "departureDate"
+            ],
+            "properties": {
+                "arrivalDate": {
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "city": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Madrid"
+                },
+                "country": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Spain"
+                },
+                "departureDate": {
+                    "type": "string",
+                    "example": "2024-07-05T00:00:00Z"
+                }
+            }
+        },
+        "requests.InstantiateItineraryTemplateRequest": {
+            "type": "object",
+            "required": [
+                "startDate"
+            ],
+            "properties": {
+                "startDate": {
+                    "type": "string",
+                    "example": "2025-06-02T00:00:00Z"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Corporate offsite 2025"
+                }
+            }
+        },
+        "requests.JobMessageRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "Make the third day more relaxed"
+                }
+            }
+        },
+        "requests.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "test@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Password123-"
+                }
+            }
+        },
+        "requests.PatchDestinationRequest": {
+            "type": "object",
+            "properties": {
+                "arrivalDate": {
+                    "type": "string",
+                    "example": "2024-07-05T00:00:00Z"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Seville"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Spain"
+                },
+                "departureDate": {
+                    "type": "string",
+                    "example": "2024-07-08T00:00:00Z"
+                }
+            }
+        },
+        "requests.PatchItineraryRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Summer vacation in Spain"
+                },
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/requests.DestinationItem"
+                    }
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "I want to enjoy the nightlife"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Trip to Spain and Portugal"
+                }
+            }
+        },
+        "requests.PromptTemplateRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "systemMessage",
+                "template"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "kids"
+                },
+                "systemMessage": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "You are a helpful expert in family travel."
+                },
+                "template": {
+                    "type": "string",
+                    "maxLength": 8192,
+                    "example": "Create a travel itinerary for a family with kids titled {{.title}}."
+                }
+            }
+        },
+        "requests.RegenerateJobRequest": {
+            "type": "object",
+            "properties": {
+                "destinationId": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 11
+                },
+                "endDate": {
+                    "type": "string",
+                    "example": "2024-07-03"
+                },
+                "instructions": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "Less museums and more time outdoors"
+                },
+                "startDate": {
+                    "type": "string",
+                    "example": "2024-07-02"
+                }
+            }
+        },
+        "requests.ReorderActivitiesRequest": {
+            "type": "object",
+            "required": [
+                "activityIds"
+            ],
+            "properties": {
+                "activityIds": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "requests.ShareItineraryRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "permission"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "friend@example.com"
+                },
+                "permission": {
+                    "type": "string",
+                    "enum": [
+                        "viewer",
+                        "editor"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "requests.SignUpRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "test@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Password123-"
+                }
+            }
+        },
+        "requests.TemplateDestinationItem": {
+            "type": "object",
+            "required": [
+                "city",
+                "country"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Lisbon"
+                },
+                "country": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Portugal"
+                },
+                "days": {
+                    "type": "integer",
+                    "maximum": 30,
+                    "minimum": 0,
+                    "example": 3
+                },
+                "startDay": {
+                    "type": "integer",
+                    "maximum": 30,
+                    "minimum": 0,
+                    "example": 0
+                }
+            }
+        },
+        "requests.TransportLegRequest": {
+            "type": "object",
+            "required": [
+                "fromDestinationId",
+                "mode"
+            ],
+            "properties": {
+                "arrivalTime": {
+                    "type": "string",
+                    "example": "2024-07-05T12:15:00+02:00"
+                },
+                "bookingReference": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "X7K2P9"
+                },
+                "carrier": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Renfe"
+                },
+                "departureTime": {
+                    "type": "string",
+                    "example": "2024-07-05T09:30:00+02:00"
+                },
+                "fromDestinationId": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "flight",
+                        "train",
+                        "bus",
+                        "car",
+                        "ferry",
+                        "other"
+                    ],
+                    "example": "train"
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "Seats 4A and 4B"
+                }
+            }
+        },
+        "requests.TravellerPreferencesRequest": {
+            "type": "object",
+            "required": [
+                "dietaryRestrictions",
+                "interests"
+            ],
+            "properties": {
+                "adults": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 2
+                },
+                "budgetLevel": {
                     "type": "string",
-                    "example": "e2467dd0-db8a-49db-a5cb-9474f8e63933"
+                    "enum": [
+                        "budget",
+                        "moderate",
+                        "luxury"
+                    ],
+                    "example": "moderate"
                 },
-                "creationDate": {
-                    "description": "Status can be \"running\", \"completed\", \"failed\", or \"stopped\"",
+                "children": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 1
+                },
+                "dietaryRestrictions": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vegetarian"
+                    ]
+                },
+                "interests": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "museums",
+                        "street food",
+                        "hiking"
+                    ]
+                },
+                "language": {
                     "type": "string",
-                    "example": "2024-06-01T00:00:00Z"
+                    "maxLength": 35,
+                    "example": "es"
                 },
-                "endDate": {
-                    "description": "EndDate is set when the job ends",
+                "mobilityNeeds": {
                     "type": "string",
-                    "example": "2024-06-01T00:01:00Z"
+                    "maxLength": 256,
+                    "example": "Wheelchair user, no long walks"
                 },
-                "fileManager": {
-                    "description": "Optional, used for file management",
+                "pace": {
                     "type": "string",
-                    "example": "local"
+                    "enum": [
+                        "relaxed",
+                        "moderate",
+                        "intense"
+                    ],
+                    "example": "relaxed"
                 },
-                "filepath": {
-                    "description": "Optional, used for file storage",
+                "seniors": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 0
+                }
+            }
+        },
+        "requests.UnlockUserRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
                     "type": "string",
-                    "example": "/path/to/file.txt"
+                    "maxLength": 128,
+                    "example": "test@example.com"
+                }
+            }
+        },
+        "requests.UpdateItineraryRequest": {
+            "type": "object",
+            "required": [
+                "destinations",
+                "id",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Summer vacation in Spain"
+                },
+                "destinations": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/requests.DestinationItem"
+                    }
                 },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "I want to enjoy the nightlife"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Trip to Spain"
+                }
+            }
+        },
+        "requests.UpdateMeRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "new@example.com"
+                }
+            }
+        },
+        "requests.UpdateUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "support",
+                        "admin"
+                    ],
+                    "example": "support"
+                }
+            }
+        },
+        "responses.AddItineraryDestinationResponse": {
+            "type": "object",
+            "properties": {
+                "destination": {
+                    "$ref": "#/definitions/models.ItineraryTravelDestination"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Destination added."
+                }
+            }
+        },
+        "responses.ChangePasswordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Password changed."
+                }
+            }
+        },
+        "responses.CreateActivityResponse": {
+            "type": "object",
+            "properties": {
+                "activity": {
+                    "$ref": "#/definitions/models.ItineraryActivity"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Activity added."
+                }
+            }
+        },
+        "responses.CreateApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "$ref": "#/definitions/models.ApiKey"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "tak_1a2b3c4d5e6f..."
+                },
+                "message": {
+                    "type": "string",
+                    "example": "API key created. Store it safely, it will not be shown again."
+                }
+            }
+        },
+        "responses.CreateItineraryResponse": {
+            "type": "object",
+            "properties": {
                 "itineraryId": {
-                    "description": "ItineraryID is the ID of the itinerary associated with this job",
                     "type": "integer",
                     "example": 123
                 },
-                "startDate": {
-                    "description": "CreationDate is set when the job is created",
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary created."
+                }
+            }
+        },
+        "responses.CreateItineraryTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary template created."
+                },
+                "template": {
+                    "$ref": "#/definitions/models.ItineraryTemplate"
+                }
+            }
+        },
+        "responses.CreatePromptTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Prompt template saved."
+                },
+                "promptTemplate": {
+                    "$ref": "#/definitions/models.PromptTemplate"
+                }
+            }
+        },
+        "responses.CreateShareLinkResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Share link created. Store it safely, it will not be shown again."
+                },
+                "shareLink": {
+                    "$ref": "#/definitions/models.ShareLink"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "tas_1a2b3c4d5e6f..."
+                }
+            }
+        },
+        "responses.CreateTransportLegResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Transport leg added."
+                },
+                "transportLeg": {
+                    "$ref": "#/definitions/models.ItineraryTransportLeg"
+                }
+            }
+        },
+        "responses.DeleteAccommodationResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Accommodation removed."
+                }
+            }
+        },
+        "responses.DeleteActivityResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Activity removed."
+                }
+            }
+        },
+        "responses.DeleteBudgetResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Budget removed."
+                }
+            }
+        },
+        "responses.DeleteCostEstimateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Cost estimate removed."
+                }
+            }
+        },
+        "responses.DeleteDataExportResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Data export deleted."
+                }
+            }
+        },
+        "responses.DeleteItineraryDestinationResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Destination deleted."
+                }
+            }
+        },
+        "responses.DeleteItineraryJobResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary job deleted."
+                }
+            }
+        },
+        "responses.DeleteItineraryResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary deleted."
+                }
+            }
+        },
+        "responses.DeleteItineraryTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary template deleted."
+                }
+            }
+        },
+        "responses.DeleteItineraryTravellerPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "example": "2024-06-01T00:00:00Z"
-                },
-                "status": {
+                    "example": "Traveller preferences of the itinerary removed."
+                }
+            }
+        },
+        "responses.DeleteMeResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "example": "completed"
-                },
-                "statusDescription": {
+                    "example": "Account deleted."
+                }
+            }
+        },
+        "responses.DeletePromptTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "example": "Job completed successfully"
+                    "example": "Prompt template deleted."
                 }
             }
         },
-        "models.ItineraryTravelDestination": {
+        "responses.DeleteTransportLegResponse": {
             "type": "object",
-            "required": [
-                "arrivalDate",
-                "city",
-                "country",
-                "departureDate"
-            ],
             "properties": {
-                "arrivalDate": {
+                "message": {
                     "type": "string",
-                    "example": "2024-07-01T00:00:00Z"
-                },
-                "city": {
+                    "example": "Transport leg removed."
+                }
+            }
+        },
+        "responses.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "example": "Madrid"
+                    "example": "An error occurred."
+                }
+            }
+        },
+        "responses.GenerateCostEstimatesResponse": {
+            "type": "object",
+            "properties": {
+                "estimates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DestinationCostEstimate"
+                    }
                 },
-                "country": {
+                "message": {
                     "type": "string",
-                    "example": "Spain"
+                    "example": "Cost estimates generated."
+                }
+            }
+        },
+        "responses.GetAccommodationResponse": {
+            "type": "object",
+            "properties": {
+                "accommodation": {
+                    "$ref": "#/definitions/models.DestinationAccommodation"
+                }
+            }
+        },
+        "responses.GetApiKeysResponse": {
+            "type": "object",
+            "properties": {
+                "apiKeys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApiKey"
+                    }
+                }
+            }
+        },
+        "responses.GetAuditEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
                 },
-                "creationDate": {
+                "nextCursor": {
                     "type": "string",
-                    "example": "2024-06-01T00:00:00Z"
+                    "example": "eyJpZCI6NDJ9"
+                }
+            }
+        },
+        "responses.GetBudgetSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "summary": {
+                    "$ref": "#/definitions/models.BudgetSummary"
+                }
+            }
+        },
+        "responses.GetDataExportResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/models.DataExportJob"
+                }
+            }
+        },
+        "responses.GetDataExportsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DataExportJob"
+                    }
+                }
+            }
+        },
+        "responses.GetItinerariesResponse": {
+            "type": "object",
+            "properties": {
+                "itineraries": {
+                    "description": "Example JSON representation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Itinerary"
+                    }
                 },
-                "departureDate": {
+                "nextCursor": {
                     "type": "string",
-                    "example": "2024-07-05T00:00:00Z"
+                    "example": "eyJpZCI6NDJ9"
                 },
-                "id": {
+                "totalCount": {
                     "type": "integer",
-                    "example": 1
+                    "example": 42
+                }
+            }
+        },
+        "responses.GetItineraryJobMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryJobMessage"
+                    }
+                }
+            }
+        },
+        "responses.GetItineraryJobResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "description": "Example JSON representation",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ItineraryFileJob"
+                        }
+                    ]
+                }
+            }
+        },
+        "responses.GetItineraryJobsResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryFileJob"
+                    }
+                }
+            }
+        },
+        "responses.GetItineraryResponse": {
+            "type": "object",
+            "properties": {
+                "itinerary": {
+                    "description": "Example JSON representation",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Itinerary"
+                        }
+                    ]
+                }
+            }
+        },
+        "responses.GetItineraryRevisionResponse": {
+            "type": "object",
+            "properties": {
+                "revision": {
+                    "$ref": "#/definitions/models.ItineraryRevision"
+                }
+            }
+        },
+        "responses.GetItineraryRevisionsDiffResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryRevisionChange"
+                    }
                 },
-                "itineraryId": {
+                "from": {
                     "type": "integer",
                     "example": 1
                 },
-                "updateDate": {
+                "to": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "responses.GetItineraryRevisionsResponse": {
+            "type": "object",
+            "properties": {
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryRevision"
+                    }
+                }
+            }
+        },
+        "responses.GetItinerarySharesResponse": {
+            "type": "object",
+            "properties": {
+                "shares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryShare"
+                    }
+                }
+            }
+        },
+        "responses.GetItineraryTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "template": {
+                    "$ref": "#/definitions/models.ItineraryTemplate"
+                }
+            }
+        },
+        "responses.GetItineraryTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryTemplate"
+                    }
+                }
+            }
+        },
+        "responses.GetItineraryTravellerPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "effective": {
+                    "$ref": "#/definitions/models.TravellerPreferences"
+                },
+                "overrides": {
+                    "$ref": "#/definitions/models.TravellerPreferences"
+                }
+            }
+        },
+        "responses.GetMeResponse": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "responses.GetPlanResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryDay"
+                    }
+                }
+            }
+        },
+        "responses.GetPromptTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "promptTemplate": {
+                    "$ref": "#/definitions/models.PromptTemplate"
+                }
+            }
+        },
+        "responses.GetPromptTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "promptTemplates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PromptTemplate"
+                    }
+                }
+            }
+        },
+        "responses.GetPublicItineraryResponse": {
+            "type": "object",
+            "properties": {
+                "document": {
                     "type": "string",
-                    "example": "2024-06-01T00:00:00Z"
+                    "example": "Day 1: Arrival in Madrid..."
+                },
+                "itinerary": {
+                    "$ref": "#/definitions/models.Itinerary"
+                },
+                "itineraryJob": {
+                    "$ref": "#/definitions/models.ItineraryFileJob"
+                }
+            }
+        },
+        "responses.GetShareLinksResponse": {
+            "type": "object",
+            "properties": {
+                "shareLinks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ShareLink"
+                    }
+                }
+            }
+        },
+        "responses.GetSharedItinerariesResponse": {
+            "type": "object",
+            "properties": {
+                "shares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItineraryShare"
+                    }
                 }
             }
         },
-        "requests.CreateItineraryRequest": {
+        "responses.GetTransportLegsResponse": {
             "type": "object",
-            "required": [
-                "destinations",
-                "title"
-            ],
             "properties": {
-                "description": {
-                    "type": "string",
-                    "maxLength": 512,
-                    "example": "Summer vacation in Spain"
-                },
-                "destinations": {
+                "transportLegs": {
                     "type": "array",
-                    "maxItems": 20,
-                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/requests.DestinationItem"
+                        "$ref": "#/definitions/models.ItineraryTransportLeg"
                     }
+                }
+            }
+        },
+        "responses.GetTravellerPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "preferences": {
+                    "$ref": "#/definitions/models.TravellerPreferences"
+                }
+            }
+        },
+        "responses.GetUsersResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                }
+            }
+        },
+        "responses.ImportItineraryResponse": {
+            "type": "object",
+            "properties": {
+                "import": {
+                    "$ref": "#/definitions/models.ItineraryImport"
                 },
-                "notes": {
-                    "type": "string",
-                    "maxLength": 512,
-                    "example": "I want to enjoy the nightlife"
+                "itineraryId": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "title": {
+                "message": {
                     "type": "string",
-                    "maxLength": 128,
-                    "example": "Trip to Spain"
+                    "example": "Itinerary imported."
                 }
             }
         },
-        "requests.DestinationItem": {
+        "responses.LoginResponse": {
             "type": "object",
-            "required": [
-                "arrivalDate",
-                "city",
-                "country",
-                "departureDate"
-            ],
             "properties": {
-                "arrivalDate": {
-                    "type": "string",
-                    "example": "2024-07-01T00:00:00Z"
-                },
-                "city": {
+                "message": {
                     "type": "string",
-                    "maxLength": 128,
-                    "example": "Madrid"
+                    "example": "Login successful!"
                 },
-                "country": {
+                "token": {
                     "type": "string",
-                    "maxLength": 128,
-                    "example": "Spain"
-                },
-                "departureDate": {
+                    "example": "token123"
+                }
+            }
+        },
+        "responses.PurgeItineraryJobResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "example": "2024-07-05T00:00:00Z"
+                    "example": "Itinerary job purged."
                 }
             }
         },
-        "requests.LoginRequest": {
+        "responses.ReorderActivitiesResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "message": {
                     "type": "string",
-                    "maxLength": 100,
-                    "example": "test@example.com"
-                },
-                "password": {
+                    "example": "Activities reordered."
+                }
+            }
+        },
+        "responses.RestoreItineraryRevisionResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "maxLength": 256,
-                    "example": "Password123-"
+                    "example": "Itinerary restored to revision 1."
                 }
             }
         },
-        "requests.SignUpRequest": {
+        "responses.RevokeApiKeyResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "message": {
                     "type": "string",
-                    "maxLength": 128,
-                    "example": "test@example.com"
-                },
-                "password": {
+                    "example": "API key revoked."
+                }
+            }
+        },
+        "responses.RevokeShareLinkResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "maxLength": 256,
-                    "example": "Password123-"
+                    "example": "Share link revoked."
                 }
             }
         },
-        "requests.UpdateItineraryRequest": {
+        "responses.SearchItinerariesResponse": {
             "type": "object",
-            "required": [
-                "destinations",
-                "id",
-                "title"
-            ],
             "properties": {
-                "description": {
+                "nextCursor": {
                     "type": "string",
-                    "maxLength": 256,
-                    "example": "Summer vacation in Spain"
+                    "example": "eyJvZmZzZXQiOjIwfQ"
                 },
-                "destinations": {
+                "results": {
                     "type": "array",
-                    "maxItems": 20,
-                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/requests.DestinationItem"
+                        "$ref": "#/definitions/models.ItinerarySearchResult"
                     }
+                }
+            }
+        },
+        "responses.ShareItineraryResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary shared."
                 },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "notes": {
+                "share": {
+                    "$ref": "#/definitions/models.ItineraryShare"
+                }
+            }
+        },
+        "responses.SignUpResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "maxLength": 512,
-                    "example": "I want to enjoy the nightlife"
+                    "example": "User created."
                 },
-                "title": {
+                "user": {
                     "type": "string",
-                    "maxLength": 128,
-                    "example": "Trip to Spain"
+                    "example": "test@example.com"
                 }
             }
         },
-        "responses.CreateItineraryResponse": {
+        "responses.StartDataExportResponse": {
             "type": "object",
             "properties": {
-                "itineraryId": {
+                "jobId": {
                     "type": "integer",
                     "example": 123
                 },
                 "message": {
                     "type": "string",
-                    "example": "Itinerary created."
+                    "example": "Data export started successfully."
                 }
             }
         },
-        "responses.DeleteItineraryJobResponse": {
+        "responses.StartItineraryJobResponse": {
             "type": "object",
             "properties": {
+                "jobId": {
+                    "type": "integer",
+                    "example": 123
+                },
                 "message": {
                     "type": "string",
-                    "example": "Itinerary job deleted."
+                    "example": "Job started successfully."
                 }
             }
         },
-        "responses.DeleteItineraryResponse": {
+        "responses.StopItineraryJobResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "Itinerary deleted."
+                    "example": "Itinerary job stopped."
                 }
             }
         },
-        "responses.ErrorResponse": {
+        "responses.SuggestPlacesResponse": {
+            "type": "object",
+            "properties": {
+                "places": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Place"
+                    }
+                }
+            }
+        },
+        "responses.UnlockUserResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "An error occurred."
+                    "example": "User account unlocked."
                 }
             }
         },
-        "responses.GetItinerariesResponse": {
+        "responses.UnshareItineraryResponse": {
             "type": "object",
             "properties": {
-                "itineraries": {
-                    "description": "Example JSON representation",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Itinerary"
-                    }
+                "message": {
+                    "type": "string",
+                    "example": "Itinerary unshared."
                 }
             }
         },
-        "responses.GetItineraryJobResponse": {
+        "responses.UpdateAccommodationResponse": {
             "type": "object",
             "properties": {
-                "job": {
-                    "description": "Example JSON representation",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.ItineraryFileJob"
-                        }
-                    ]
+                "accommodation": {
+                    "$ref": "#/definitions/models.DestinationAccommodation"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Accommodation updated."
                 }
             }
         },
-        "responses.GetItineraryJobsResponse": {
+        "responses.UpdateActivityResponse": {
             "type": "object",
             "properties": {
-                "job": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.ItineraryFileJob"
-                    }
+                "activity": {
+                    "$ref": "#/definitions/models.ItineraryActivity"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Activity updated."
                 }
             }
         },
-        "responses.GetItineraryResponse": {
+        "responses.UpdateBudgetResponse": {
             "type": "object",
             "properties": {
-                "itinerary": {
-                    "description": "Example JSON representation",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.Itinerary"
-                        }
-                    ]
+                "budget": {
+                    "$ref": "#/definitions/models.ItineraryBudget"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Budget updated."
                 }
             }
         },
-        "responses.LoginResponse": {
+        "responses.UpdateCostEstimateResponse": {
             "type": "object",
             "properties": {
+                "estimate": {
+                    "$ref": "#/definitions/models.DestinationCostEstimate"
+                },
                 "message": {
                     "type": "string",
-                    "example": "Login successful!"
+                    "example": "Cost estimate updated."
+                }
+            }
+        },
+        "responses.UpdateItineraryDestinationResponse": {
+            "type": "object",
+            "properties": {
+                "destination": {
+                    "$ref": "#/definitions/models.ItineraryTravelDestination"
                 },
-                "token": {
+                "message": {
                     "type": "string",
-                    "example": "token123"
+                    "example": "Destination updated."
                 }
             }
         },
-        "responses.SignUpResponse": {
+        "responses.UpdateItineraryResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "User created."
+                    "example": "Itinerary updated."
+                }
+            }
+        },
+        "responses.UpdateMeResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Profile updated."
                 },
                 "user": {
-                    "type": "string",
-                    "example": "test@example.com"
+                    "$ref": "#/definitions/models.User"
                 }
             }
         },
-        "responses.StartItineraryJobResponse": {
+        "responses.UpdateTransportLegResponse": {
             "type": "object",
             "properties": {
-                "jobId": {
-                    "type": "integer",
-                    "example": 123
-                },
                 "message": {
                     "type": "string",
-                    "example": "Job started successfully."
+                    "example": "Transport leg updated."
+                },
+                "transportLeg": {
+                    "$ref": "#/definitions/models.ItineraryTransportLeg"
                 }
             }
         },
-        "responses.StopItineraryJobResponse": {
+        "responses.UpdateTravellerPreferencesResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "Itinerary job stopped."
+                    "example": "Traveller preferences updated."
+                },
+                "preferences": {
+                    "$ref": "#/definitions/models.TravellerPreferences"
                 }
             }
         },
-        "responses.UpdateItineraryResponse": {
+        "responses.UpdateUserResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "Itinerary updated."
+                    "example": "User updated."
                 }
             }
         }
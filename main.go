@@ -60,6 +60,14 @@ func main() {
 	defer db.DB.Close()
 	log.Info("Database connection established")
 
+	initialAdminEmail := os.Getenv("INITIAL_ADMIN_EMAIL")
+	if initialAdminEmail != "" {
+		err = services.GetUserService().EnsureAdmin(initialAdminEmail)
+		if err != nil {
+			log.Errorf("Error granting the admin role to the initial administrator: %v", err)
+		}
+	}
+
 	// Initialize Asyncq Server
 	redisClientAddr := os.Getenv("REDIS_ADDR")
 	redisPasswr := os.Getenv("REDIS_PASSWORD")
@@ -81,6 +89,7 @@ func main() {
 	// mux maps a type to a handler
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(services.TypeItineraryFileGeneration, services.HandleItineraryFileJob)
+	mux.HandleFunc(services.TypeDataExport, services.HandleDataExportJob)
 
 	go func() {
 		if err := asyncqSrv.Run(mux); err != nil {
@@ -90,6 +99,10 @@ func main() {
 
 	// Initialize Gin server
 	server := gin.Default()
+	err = routes.SetTrustedProxies(server)
+	if err != nil {
+		log.Fatalf("The format of TRUSTED_PROXIES environment property is incorrect: %v", err)
+	}
 
 	routes.RegisterRoutes(server)
 
@@ -100,7 +113,7 @@ func main() {
 
 }
 
-// This function triggers the full delection of "dead" itinerary file jobs (those marked in status 'deleted') through a periodic timer
+// This function triggers the full delection of "dead" itinerary file jobs and data export jobs (those marked in status 'deleted') through a periodic timer
 func startDeadItineraryFileJobsCleanup() {
 	// Initialize a ticker to run every 'n' minutes according to the value defined in the environment variables (10 minutes if absent there)
 	intervalInMinutesStr := os.Getenv("DEAD_ITINERARY_FILE_JOBS_TIMER_MINUTES_INTERVAL")
@@ -137,6 +150,18 @@ func startDeadItineraryFileJobsCleanup() {
 			}
 			log.Info("Periodic cleanup of deleted itinerary files was successful")
 
+			log.Info("Running periodic cleanup of deleted data exports")
+			err = services.GetDataExportJobService().DeleteDeadJobs(fetchLimit)
+			if err != nil {
+				log.Errorf("Error during periodic cleanup of data exports: %v", err)
+			}
+
+			log.Info("Running periodic cleanup of expired login attempts")
+			err = services.GetLoginThrottleService().DeleteExpiredAttempts()
+			if err != nil {
+				log.Errorf("Error during periodic cleanup of login attempts: %v", err)
+			}
+
 		}
 	}()
 }
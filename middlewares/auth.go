@@ -2,8 +2,11 @@ package middlewares
 
 import (
 	"net/http"
+	"slices"
 
+	"example.com/travel-advisor/models"
 	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
 	"example.com/travel-advisor/utils"
 	"github.com/gin-gonic/gin"
 
@@ -20,15 +23,102 @@ func Authenticate(context *gin.Context) {
 		return
 	}
 
-	userId, err := utils.VerifyToken(token)
+	var userId int64
+	var role string
 
+	if utils.IsApiKey(token) {
+		apiKey, err := services.GetApiKeyService().Authenticate(token)
+		if err != nil {
+			log.Errorf("Error verifying API key: %v", err)
+			context.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{Message: "Not authorized."})
+			return
+		}
+
+		userId = apiKey.UserID
+		// API keys are limited to the scoped user endpoints, so they never act with a privileged role
+		role = models.RoleUser
+		context.Set("apiKeyScopes", apiKey.Scopes)
+	} else {
+		var err error
+		// The role claim of the token is not trusted, since the role may have changed since the login. It is read from the user below
+		userId, _, err = utils.VerifyToken(token)
+
+		if err != nil {
+			log.Errorf("Error verifying token: %v", err)
+			context.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{Message: "Not authorized."})
+			return
+		}
+	}
+
+	// Tokens and API keys outlive the disabling of an account and the changes of its role, so both are checked on every request
+	user, err := services.GetUserService().FindById(userId)
 	if err != nil {
-		log.Errorf("Error verifying token: %v", err)
+		log.Errorf("Error retrieving authenticated user %d: %v", userId, err)
+		context.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{Message: "Not authorized."})
+		return
+	}
+	if user.DisabledDate != nil {
+		log.Warnf("User %d is disabled", userId)
 		context.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{Message: "Not authorized."})
 		return
 	}
 
+	if role == "" {
+		role = user.Role
+	}
+	if role == "" {
+		role = models.RoleUser
+	}
+
 	context.Set("userId", userId)
+	context.Set("userRole", role)
 
 	context.Next()
 }
+
+// RequireScope must be chained after Authenticate. Requests authenticated with an API key are only let through if the key was
+// granted the given scope, while requests authenticated with a JWT have access to every scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		apiKeyScopes, exists := context.Get("apiKeyScopes")
+		if !exists || slices.Contains(apiKeyScopes.([]string), scope) {
+			context.Next()
+			return
+		}
+
+		log.Errorf("API key does not have the %s scope", scope)
+		context.AbortWithStatusJSON(http.StatusForbidden, responses.ErrorResponse{Message: "The API key does not have the required scope: " + scope + "."})
+	}
+}
+
+// RequireLoginSession must be chained after Authenticate. It rejects requests authenticated with an API key, so that
+// endpoints like the API key management ones can only be used after logging in with a password.
+func RequireLoginSession(context *gin.Context) {
+	if _, exists := context.Get("apiKeyScopes"); exists {
+		log.Error("API keys cannot be used to access this resource")
+		context.AbortWithStatusJSON(http.StatusForbidden, responses.ErrorResponse{Message: "You do not have permission to access this resource."})
+		return
+	}
+
+	context.Next()
+}
+
+// RequireRole must be chained after Authenticate. It only lets through the users with one of the given roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		role, exists := context.Get("userRole")
+		if !exists {
+			log.Error("User role not found in context")
+			context.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{Message: "Not authorized."})
+			return
+		}
+
+		if !slices.Contains(roles, role.(string)) {
+			log.Errorf("Role %s is not allowed to access this resource", role)
+			context.AbortWithStatusJSON(http.StatusForbidden, responses.ErrorResponse{Message: "You do not have permission to access this resource."})
+			return
+		}
+
+		context.Next()
+	}
+}
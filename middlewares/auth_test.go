@@ -1,10 +1,15 @@
 package middlewares
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
 	"example.com/travel-advisor/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -33,8 +38,8 @@ func TestAuthenticate_NoToken(t *testing.T) {
 
 func TestAuthenticate_InvalidToken(t *testing.T) {
 	// Mock utils.VerifyToken to return an error
-	utils.VerifyToken = func(token string) (int64, error) {
-		return 0, assert.AnError
+	utils.VerifyToken = func(token string) (int64, string, error) {
+		return 0, "", assert.AnError
 	}
 
 	// Set up Gin context
@@ -60,9 +65,11 @@ func TestAuthenticate_InvalidToken(t *testing.T) {
 
 func TestAuthenticate_ValidToken(t *testing.T) {
 	// Mock utils.VerifyToken to return a valid userId
-	utils.VerifyToken = func(token string) (int64, error) {
-		return 12345, nil
+	utils.VerifyToken = func(token string) (int64, string, error) {
+		return 12345, "", nil
 	}
+	restore := setMockUserService(&models.User{ID: 12345}, nil)
+	defer restore()
 
 	// Set up Gin context
 	gin.SetMode(gin.TestMode)
@@ -70,7 +77,8 @@ func TestAuthenticate_ValidToken(t *testing.T) {
 	router.Use(Authenticate)
 	router.GET("/test", func(c *gin.Context) {
 		userId, _ := c.Get("userId")
-		c.JSON(http.StatusOK, gin.H{"message": "success", "userId": userId})
+		role, _ := c.Get("userRole")
+		c.JSON(http.StatusOK, gin.H{"message": "success", "userId": userId, "role": role})
 	})
 
 	// Create a request with a valid Authorization header
@@ -83,5 +91,300 @@ func TestAuthenticate_ValidToken(t *testing.T) {
 
 	// Assert the response
 	assert.Equal(t, http.StatusOK, resp.Code)
-	assert.JSONEq(t, `{"message": "success", "userId": 12345}`, resp.Body.String())
+	assert.JSONEq(t, `{"message": "success", "userId": 12345, "role": "user"}`, resp.Body.String())
+}
+
+func TestAuthenticate_ValidTokenWithRole(t *testing.T) {
+	utils.VerifyToken = func(token string) (int64, string, error) {
+		return 1, models.RoleAdmin, nil
+	}
+	restore := setMockUserService(&models.User{ID: 1, Role: models.RoleAdmin}, nil)
+	defer restore()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate)
+	router.GET("/test", func(c *gin.Context) {
+		role, _ := c.Get("userRole")
+		c.JSON(http.StatusOK, gin.H{"role": role})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "valid-token")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"role": "admin"}`, resp.Body.String())
+}
+
+func TestAuthenticate_DemotedUser(t *testing.T) {
+	// The token was issued while the user was an administrator
+	utils.VerifyToken = func(token string) (int64, string, error) {
+		return 1, models.RoleAdmin, nil
+	}
+	restore := setMockUserService(&models.User{ID: 1, Role: models.RoleUser}, nil)
+	defer restore()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate, RequireRole(models.RoleAdmin))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "valid-token")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestAuthenticate_DisabledUser(t *testing.T) {
+	utils.VerifyToken = func(token string) (int64, string, error) {
+		return 12345, models.RoleUser, nil
+	}
+	disabledDate := time.Now()
+	restore := setMockUserService(&models.User{ID: 12345, DisabledDate: &disabledDate}, nil)
+	defer restore()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate)
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "valid-token")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestAuthenticate_UnknownUser(t *testing.T) {
+	utils.VerifyToken = func(token string) (int64, string, error) {
+		return 12345, models.RoleUser, nil
+	}
+	restore := setMockUserService(nil, sql.ErrNoRows)
+	defer restore()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate)
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "valid-token")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func newRoleRouter(role *string, handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if role != nil {
+			c.Set("userRole", *role)
+		}
+		c.Next()
+	})
+	handlers = append(handlers, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+	router.GET("/test", handlers...)
+	return router
+}
+
+func TestRequireRole_Allowed(t *testing.T) {
+	role := models.RoleSupport
+	router := newRoleRouter(&role, RequireRole(models.RoleSupport, models.RoleAdmin))
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRequireRole_Forbidden(t *testing.T) {
+	role := models.RoleSupport
+	router := newRoleRouter(&role, RequireRole(models.RoleAdmin))
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.JSONEq(t, `{"message": "You do not have permission to access this resource."}`, resp.Body.String())
+}
+
+func TestRequireRole_NoRole(t *testing.T) {
+	router := newRoleRouter(nil, RequireRole(models.RoleAdmin))
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+// mockUserService only implements FindById, the only method used by the middlewares
+type mockUserService struct {
+	services.UserServiceInterface
+	user *models.User
+	err  error
+}
+
+func (m *mockUserService) FindById(id int64) (*models.User, error) {
+	return m.user, m.err
+}
+
+func setMockUserService(user *models.User, err error) func() {
+	orig := services.GetUserService
+	services.GetUserService = func() services.UserServiceInterface {
+		return &mockUserService{user: user, err: err}
+	}
+	return func() { services.GetUserService = orig }
+}
+
+// mockApiKeyService only implements Authenticate, the only method used by the middlewares
+type mockApiKeyService struct {
+	services.ApiKeyServiceInterface
+	authenticateFunc func(rawApiKey string) (*models.ApiKey, error)
+}
+
+func (m *mockApiKeyService) Authenticate(rawApiKey string) (*models.ApiKey, error) {
+	return m.authenticateFunc(rawApiKey)
+}
+
+func TestAuthenticate_ValidApiKey(t *testing.T) {
+	origGetApiKeyService := services.GetApiKeyService
+	defer func() { services.GetApiKeyService = origGetApiKeyService }()
+	services.GetApiKeyService = func() services.ApiKeyServiceInterface {
+		return &mockApiKeyService{authenticateFunc: func(rawApiKey string) (*models.ApiKey, error) {
+			assert.Equal(t, "tak_valid", rawApiKey)
+			return &models.ApiKey{ID: 1, UserID: 12345, Scopes: []string{models.ApiKeyScopeItinerariesRead}}, nil
+		}}
+	}
+	restore := setMockUserService(&models.User{ID: 12345, Role: models.RoleAdmin}, nil)
+	defer restore()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate)
+	router.GET("/test", func(c *gin.Context) {
+		userId, _ := c.Get("userId")
+		scopes, _ := c.Get("apiKeyScopes")
+		role, _ := c.Get("userRole")
+		c.JSON(http.StatusOK, gin.H{"userId": userId, "scopes": scopes, "role": role})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "tak_valid")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	// API keys never act with a privileged role, even for administrators
+	assert.JSONEq(t, `{"userId": 12345, "scopes": ["itineraries:read"], "role": "user"}`, resp.Body.String())
+}
+
+func TestAuthenticate_InvalidApiKey(t *testing.T) {
+	origGetApiKeyService := services.GetApiKeyService
+	defer func() { services.GetApiKeyService = origGetApiKeyService }()
+	services.GetApiKeyService = func() services.ApiKeyServiceInterface {
+		return &mockApiKeyService{authenticateFunc: func(rawApiKey string) (*models.ApiKey, error) {
+			return nil, errors.New("API key is revoked or expired")
+		}}
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate)
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "tak_revoked")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.JSONEq(t, `{"message": "Not authorized."}`, resp.Body.String())
+}
+
+func newScopedRouter(apiKeyScopes []string, handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userId", int64(1))
+		if apiKeyScopes != nil {
+			c.Set("apiKeyScopes", apiKeyScopes)
+		}
+		c.Next()
+	})
+	handlers = append(handlers, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+	router.GET("/test", handlers...)
+	return router
+}
+
+func TestRequireScope_JwtSession(t *testing.T) {
+	router := newScopedRouter(nil, RequireScope(models.ApiKeyScopeJobsWrite))
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRequireScope_ApiKeyWithScope(t *testing.T) {
+	router := newScopedRouter([]string{models.ApiKeyScopeItinerariesRead, models.ApiKeyScopeJobsWrite}, RequireScope(models.ApiKeyScopeJobsWrite))
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRequireScope_ApiKeyWithoutScope(t *testing.T) {
+	router := newScopedRouter([]string{models.ApiKeyScopeItinerariesRead}, RequireScope(models.ApiKeyScopeJobsWrite))
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.JSONEq(t, `{"message": "The API key does not have the required scope: jobs:write."}`, resp.Body.String())
+}
+
+func TestRequireLoginSession(t *testing.T) {
+	router := newScopedRouter(nil, RequireLoginSession)
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	router = newScopedRouter([]string{models.ApiKeyScopeItinerariesRead}, RequireLoginSession)
+	req, _ = http.NewRequest(http.MethodGet, "/test", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
 }
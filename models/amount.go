@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Amount is an amount of money in hundredths of the unit of its currency (the cents of most currencies), so amounts are stored and
+// added up exactly. It is written in JSON as a decimal number of units, like 120.5
+type Amount int64
+
+// AmountOf returns the amount of the number of units, rounded to hundredths
+func AmountOf(units float64) Amount {
+	return Amount(math.Round(units * 100))
+}
+
+// Units returns the amount as a number of units, for the calculations that cannot be exact like currency conversions
+func (a Amount) Units() float64 {
+	return float64(a) / 100
+}
+
+// Convert returns the amount multiplied by an exchange rate, rounded to hundredths
+func (a Amount) Convert(rate float64) Amount {
+	return Amount(math.Round(float64(a) * rate))
+}
+
+// String returns the amount as a decimal number of units without trailing zeros, like 120.5
+func (a Amount) String() string {
+	sign := ""
+	value := int64(a)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	units, hundredths := value/100, value%100
+	switch {
+	case hundredths == 0:
+		return fmt.Sprintf("%s%d", sign, units)
+	case hundredths%10 == 0:
+		return fmt.Sprintf("%s%d.%d", sign, units, hundredths/10)
+	default:
+		return fmt.Sprintf("%s%d.%02d", sign, units, hundredths)
+	}
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number of units without going through a float, rounding it half away from zero to hundredths
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	units, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return fmt.Errorf("invalid amount %s", text)
+	}
+	hundredths := new(big.Rat).Mul(units, big.NewRat(100, 1))
+
+	// Round half away from zero: add or subtract a half and truncate
+	half := big.NewRat(1, 2)
+	if hundredths.Sign() < 0 {
+		half.Neg(half)
+	}
+	hundredths.Add(hundredths, half)
+	rounded := new(big.Int).Quo(hundredths.Num(), hundredths.Denom())
+	if !rounded.IsInt64() {
+		return errors.New("amount out of range")
+	}
+
+	*a = Amount(rounded.Int64())
+	return nil
+}
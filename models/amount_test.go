@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount_MarshalJSON(t *testing.T) {
+	for amount, expected := range map[Amount]string{0: "0", 12000: "120", 12050: "120.5", 12005: "120.05", -1999: "-19.99", -5: "-0.05"} {
+		data, err := json.Marshal(amount)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+}
+
+func TestAmount_UnmarshalJSON(t *testing.T) {
+	for text, expected := range map[string]Amount{"120": 12000, "120.5": 12050, "0.1": 10, "100.125": 10013, "-100.125": -10013,
+		"1e2": 10000} {
+		var amount Amount
+		assert.NoError(t, json.Unmarshal([]byte(text), &amount), text)
+		assert.Equal(t, expected, amount, text)
+	}
+
+	var amount Amount
+	assert.Error(t, json.Unmarshal([]byte(`"120"`), &amount))
+	assert.Error(t, json.Unmarshal([]byte("1e30"), &amount))
+}
+
+func TestAmount_Sum(t *testing.T) {
+	assert.Equal(t, AmountOf(0.3), AmountOf(0.1)+AmountOf(0.2))
+	assert.Equal(t, Amount(1502), Amount(1001).Convert(1.5))
+	assert.Equal(t, 0.3, (AmountOf(0.1) + AmountOf(0.2)).Units())
+}
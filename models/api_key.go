@@ -0,0 +1,284 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+const (
+	ApiKeyScopeItinerariesRead  = "itineraries:read"
+	ApiKeyScopeItinerariesWrite = "itineraries:write"
+	ApiKeyScopeJobsRead         = "jobs:read"
+	ApiKeyScopeJobsWrite        = "jobs:write"
+)
+
+// ApiKeyScopes lists every scope that can be granted to an API key
+var ApiKeyScopes = []string{ApiKeyScopeItinerariesRead, ApiKeyScopeItinerariesWrite, ApiKeyScopeJobsRead, ApiKeyScopeJobsWrite}
+
+// ApiKey is a personal, revocable credential used for machine-to-machine access. Only the hash of the key is stored; the
+// clear prefix just helps users tell their keys apart.
+type ApiKey struct {
+	ID             int64      `json:"id" example:"1"`
+	UserID         int64      `json:"-"`
+	Name           string     `json:"name" example:"CI pipeline"`
+	KeyPrefix      string     `json:"keyPrefix" example:"tak_1a2b3c4d"`
+	KeyHash        string     `json:"-"`
+	Scopes         []string   `json:"scopes" example:"itineraries:read,jobs:write"`
+	CreationDate   time.Time  `json:"creationDate" example:"2024-06-01T00:00:00Z"`
+	ExpirationDate *time.Time `json:"expirationDate,omitempty" example:"2025-06-01T00:00:00Z"`
+	LastUsedDate   *time.Time `json:"lastUsedDate,omitempty" example:"2024-06-02T00:00:00Z"`
+	RevocationDate *time.Time `json:"revocationDate,omitempty" example:"2024-06-03T00:00:00Z"`
+
+	FindById           func(id int64) (*ApiKey, error)       `json:"-"`
+	FindByHash         func(keyHash string) (*ApiKey, error) `json:"-"`
+	FindByUserId       func(userId int64) ([]*ApiKey, error) `json:"-"`
+	Create             func() error                          `json:"-"`
+	Revoke             func() error                          `json:"-"`
+	UpdateLastUsedDate func(lastUsedDate time.Time) error    `json:"-"`
+	DeleteByUserIdTx   func(userId int64, tx *sql.Tx) error  `json:"-"`
+}
+
+var InitApiKey = func() *ApiKey {
+	return InitApiKeyFunctions(&ApiKey{})
+}
+
+var InitApiKeyFunctions = func(apiKey *ApiKey) *ApiKey {
+	// Set default SQL implementations for FindById, FindByHash, FindByUserId, Create, Revoke, UpdateLastUsedDate and DeleteByUserIdTx. In the future
+	// there could be implementations for other NoSQL DB systems like MongoDB
+	apiKey.FindById = apiKey.defaultFindById
+	apiKey.FindByHash = apiKey.defaultFindByHash
+	apiKey.FindByUserId = apiKey.defaultFindByUserId
+	apiKey.Create = apiKey.defaultCreate
+	apiKey.Revoke = apiKey.defaultRevoke
+	apiKey.UpdateLastUsedDate = apiKey.defaultUpdateLastUsedDate
+	apiKey.DeleteByUserIdTx = apiKey.defaultDeleteByUserIdTx
+
+	return apiKey
+}
+
+var NewApiKey = func(userId int64, name string, scopes []string, expirationDate *time.Time) *ApiKey {
+	apiKey := &ApiKey{
+		UserID:         userId,
+		Name:           name,
+		Scopes:         scopes,
+		ExpirationDate: expirationDate,
+	}
+
+	return InitApiKeyFunctions(apiKey)
+}
+
+// IsActive tells whether the key has not been revoked and has not expired at the given time
+func (ak *ApiKey) IsActive(now time.Time) bool {
+	if ak.RevocationDate != nil {
+		return false
+	}
+	return ak.ExpirationDate == nil || now.Before(*ak.ExpirationDate)
+}
+
+const apiKeyColumns = `id, user_id, name, key_prefix, key_hash, scopes, creation_date, expiration_date, last_used_date, revocation_date`
+
+type apiKeyScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApiKey(scanner apiKeyScanner) (*ApiKey, error) {
+	apiKey := &ApiKey{}
+
+	var scopes string
+	var expirationDate sql.NullTime
+	var lastUsedDate sql.NullTime
+	var revocationDate sql.NullTime
+	err := scanner.Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name, &apiKey.KeyPrefix, &apiKey.KeyHash, &scopes, &apiKey.CreationDate,
+		&expirationDate, &lastUsedDate, &revocationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if scopes != "" {
+		apiKey.Scopes = strings.Split(scopes, ",")
+	} else {
+		apiKey.Scopes = []string{}
+	}
+	if expirationDate.Valid {
+		apiKey.ExpirationDate = &expirationDate.Time
+	}
+	if lastUsedDate.Valid {
+		apiKey.LastUsedDate = &lastUsedDate.Time
+	}
+	if revocationDate.Valid {
+		apiKey.RevocationDate = &revocationDate.Time
+	}
+
+	return apiKey, nil
+}
+
+func (ak *ApiKey) defaultFindById(id int64) (*ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+	row := db.DB.QueryRow(query, id)
+
+	return scanApiKey(row)
+}
+
+func (ak *ApiKey) defaultFindByHash(keyHash string) (*ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+	row := db.DB.QueryRow(query, keyHash)
+
+	return scanApiKey(row)
+}
+
+func (ak *ApiKey) defaultFindByUserId(userId int64) ([]*ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = ? ORDER BY creation_date DESC`
+	rows, err := db.DB.Query(query, userId)
+	if err != nil {
+		log.Errorf("Error querying API keys of user %d: %v", userId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []*ApiKey{}
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			log.Errorf("Error scanning API key row: %v", err)
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating API key rows: %v", err)
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (ak *ApiKey) defaultCreate() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for API key creation: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `INSERT INTO api_keys(user_id, name, key_prefix, key_hash, scopes, creation_date, expiration_date)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for API key: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	ak.CreationDate = time.Now()
+
+	result, err := stmt.Exec(ak.UserID, ak.Name, ak.KeyPrefix, ak.KeyHash, strings.Join(ak.Scopes, ","), ak.CreationDate, ak.ExpirationDate)
+	if err != nil {
+		log.Errorf("Error executing insert for API key: %v", err)
+		return err
+	}
+
+	apiKeyId, err := result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for API key: %v", err)
+		return err
+	}
+
+	ak.ID = apiKeyId
+
+	auditEvent := NewAuditEvent(ak.UserID, AuditEventApiKeyCreated, fmt.Sprintf("API key %d created.", ak.ID),
+		map[string]any{"apiKeyId": ak.ID, "name": ak.Name, "scopes": ak.Scopes})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for API key creation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ak *ApiKey) defaultRevoke() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for API key revocation: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `UPDATE api_keys SET revocation_date = ? WHERE id = ? AND revocation_date IS NULL`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for API key revocation: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+
+	_, err = stmt.Exec(now, ak.ID)
+	if err != nil {
+		log.Errorf("Error executing update for API key revocation: %v", err)
+		return err
+	}
+
+	ak.RevocationDate = &now
+
+	auditEvent := NewAuditEvent(ak.UserID, AuditEventApiKeyRevoked, fmt.Sprintf("API key %d revoked.", ak.ID), map[string]any{"apiKeyId": ak.ID})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for API key revocation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ak *ApiKey) defaultUpdateLastUsedDate(lastUsedDate time.Time) error {
+	query := `UPDATE api_keys SET last_used_date = ? WHERE id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for API key last used date: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(lastUsedDate, ak.ID)
+	if err != nil {
+		log.Errorf("Error executing update for API key last used date: %v", err)
+		return err
+	}
+
+	ak.LastUsedDate = &lastUsedDate
+
+	return nil
+}
+
+func (ak *ApiKey) defaultDeleteByUserIdTx(userId int64, tx *sql.Tx) error {
+	query := `DELETE FROM api_keys WHERE user_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for API keys of user %d: %v", userId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(userId)
+	if err != nil {
+		log.Errorf("Error executing delete for API keys of user %d: %v", userId, err)
+		return err
+	}
+
+	return nil
+}
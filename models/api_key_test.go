@@ -0,0 +1,195 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyTestColumns = []string{"id", "user_id", "name", "key_prefix", "key_hash", "scopes", "creation_date", "expiration_date", "last_used_date", "revocation_date"}
+
+func TestApiKey_FindByHash_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	expiration := now.Add(24 * time.Hour)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash = \\?").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(apiKeyTestColumns).
+			AddRow(1, 2, "CI", "tak_01234567", "hash", "itineraries:read,jobs:write", now, expiration, nil, nil))
+
+	apiKey, err := InitApiKey().FindByHash("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), apiKey.ID)
+	assert.Equal(t, int64(2), apiKey.UserID)
+	assert.Equal(t, []string{ApiKeyScopeItinerariesRead, ApiKeyScopeJobsWrite}, apiKey.Scopes)
+	assert.True(t, expiration.Equal(*apiKey.ExpirationDate))
+	assert.Nil(t, apiKey.LastUsedDate)
+	assert.Nil(t, apiKey.RevocationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_FindById_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE id = \\?").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(apiKeyTestColumns))
+
+	apiKey, err := InitApiKey().FindById(5)
+	assert.Nil(t, apiKey)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_FindByUserId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE user_id = \\? ORDER BY creation_date DESC").
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows(apiKeyTestColumns).
+			AddRow(2, 2, "Backup", "tak_89abcdef", "hash2", "jobs:read", now, nil, now, now).
+			AddRow(1, 2, "CI", "tak_01234567", "hash1", "itineraries:read", now, nil, nil, nil))
+
+	apiKeys, err := InitApiKey().FindByUserId(2)
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 2)
+	assert.NotNil(t, apiKeys[0].RevocationDate)
+	assert.NotNil(t, apiKeys[0].LastUsedDate)
+	assert.Nil(t, apiKeys[1].RevocationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_FindByUserId_QueryError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE user_id = \\?").
+		WillReturnError(errors.New("query error"))
+
+	apiKeys, err := InitApiKey().FindByUserId(2)
+	assert.Error(t, err)
+	assert.Nil(t, apiKeys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	apiKey := NewApiKey(2, "CI", []string{ApiKeyScopeItinerariesRead, ApiKeyScopeJobsWrite}, nil)
+	apiKey.KeyPrefix = "tak_01234567"
+	apiKey.KeyHash = "hash"
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO api_keys").
+		ExpectExec().
+		WithArgs(int64(2), "CI", "tak_01234567", "hash", "itineraries:read,jobs:write", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(2), AuditEventApiKeyCreated, "API key 7 created.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = apiKey.Create()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), apiKey.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_Create_ExecError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	apiKey := NewApiKey(2, "CI", []string{ApiKeyScopeItinerariesRead}, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO api_keys").
+		ExpectExec().
+		WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
+
+	err = apiKey.Create()
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_Revoke_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	apiKey := InitApiKeyFunctions(&ApiKey{ID: 7, UserID: 2})
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE api_keys SET revocation_date = \\? WHERE id = \\? AND revocation_date IS NULL").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(2), AuditEventApiKeyRevoked, "API key 7 revoked.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = apiKey.Revoke()
+	assert.NoError(t, err)
+	assert.NotNil(t, apiKey.RevocationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_UpdateLastUsedDate_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	apiKey := InitApiKeyFunctions(&ApiKey{ID: 7})
+	now := time.Now()
+
+	mock.ExpectPrepare("UPDATE api_keys SET last_used_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WithArgs(now, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = apiKey.UpdateLastUsedDate(now)
+	assert.NoError(t, err)
+	assert.Equal(t, &now, apiKey.LastUsedDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApiKey_IsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.True(t, (&ApiKey{}).IsActive(now))
+	assert.True(t, (&ApiKey{ExpirationDate: &future}).IsActive(now))
+	assert.False(t, (&ApiKey{ExpirationDate: &past}).IsActive(now))
+	assert.False(t, (&ApiKey{RevocationDate: &past}).IsActive(now))
+}
@@ -2,32 +2,109 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Audit event types, named "<resource>.<action>"
+const (
+	AuditEventLoginSucceeded           = "user.login_succeeded"
+	AuditEventLoginFailed              = "user.login_failed"
+	AuditEventEmailChanged             = "user.email_changed"
+	AuditEventPasswordChanged          = "user.password_changed"
+	AuditEventRoleChanged              = "user.role_changed"
+	AuditEventUserDisabled             = "user.disabled"
+	AuditEventUserEnabled              = "user.enabled"
+	AuditEventUserDeleted              = "user.deleted"
+	AuditEventApiKeyCreated            = "api_key.created"
+	AuditEventApiKeyRevoked            = "api_key.revoked"
+	AuditEventItineraryCreated         = "itinerary.created"
+	AuditEventItineraryUpdated         = "itinerary.updated"
+	AuditEventItineraryDeleted         = "itinerary.deleted"
+	AuditEventItineraryRestored        = "itinerary.restored"
+	AuditEventItineraryShared          = "itinerary.shared"
+	AuditEventItineraryUnshared        = "itinerary.unshared"
+	AuditEventShareLinkCreated         = "share_link.created"
+	AuditEventShareLinkRevoked         = "share_link.revoked"
+	AuditEventShareLinkAccessed        = "share_link.accessed"
+	AuditEventJobStarted               = "itinerary_file_job.started"
+	AuditEventJobStopped               = "itinerary_file_job.stopped"
+	AuditEventJobForceStopped          = "itinerary_file_job.force_stopped"
+	AuditEventJobDeleted               = "itinerary_file_job.deleted"
+	AuditEventJobPurged                = "itinerary_file_job.purged"
+	AuditEventJobFileDownloaded        = "itinerary_file_job.downloaded"
+	AuditEventDataExportRequested      = "data_export.requested"
+	AuditEventDataExportDownloaded     = "data_export.downloaded"
+	AuditEventPromptTemplateSaved      = "prompt_template.saved"
+	AuditEventPromptTemplateDeleted    = "prompt_template.deleted"
+	AuditEventItineraryTemplateCreated = "itinerary_template.created"
+	AuditEventItineraryTemplateDeleted = "itinerary_template.deleted"
 )
 
+// AuditEventTypes lists every type of audit event that can be recorded
+var AuditEventTypes = []string{AuditEventLoginSucceeded, AuditEventLoginFailed, AuditEventEmailChanged, AuditEventPasswordChanged,
+	AuditEventRoleChanged, AuditEventUserDisabled, AuditEventUserEnabled, AuditEventUserDeleted, AuditEventApiKeyCreated,
+	AuditEventApiKeyRevoked, AuditEventItineraryCreated, AuditEventItineraryUpdated, AuditEventItineraryDeleted,
+	AuditEventItineraryRestored, AuditEventItineraryShared, AuditEventItineraryUnshared, AuditEventShareLinkCreated,
+	AuditEventShareLinkRevoked, AuditEventShareLinkAccessed, AuditEventJobStarted, AuditEventJobStopped, AuditEventJobForceStopped,
+	AuditEventJobDeleted, AuditEventJobPurged, AuditEventJobFileDownloaded, AuditEventDataExportRequested, AuditEventDataExportDownloaded,
+	AuditEventPromptTemplateSaved, AuditEventPromptTemplateDeleted, AuditEventItineraryTemplateCreated, AuditEventItineraryTemplateDeleted}
+
+// IsValidAuditEventType checks whether the type is one of AuditEventTypes
+func IsValidAuditEventType(eventType string) bool {
+	return slices.Contains(AuditEventTypes, eventType)
+}
+
 type AuditEvent struct {
-	ID               int64
-	UserID           int64
-	EventDescription string
-	EventDate        *time.Time
+	ID               int64          `json:"id" example:"1"`
+	UserID           int64          `json:"userId" example:"1"`
+	EventType        string         `json:"eventType" example:"user.login_succeeded"`
+	EventDescription string         `json:"eventDescription" example:"Successful user login"`
+	Details          map[string]any `json:"details,omitempty"`
+	EventDate        *time.Time     `json:"eventDate" example:"2024-06-01T00:00:00Z"`
+
+	CreateAuditEvent func(*sql.Tx) error                                  `json:"-"`
+	FindByUserId     func(userId int64) ([]*AuditEvent, error)            `json:"-"`
+	Find             func(filter AuditEventFilter) ([]*AuditEvent, error) `json:"-"`
+}
+
+// AuditEventFilter restricts the audit events returned by Find. Zero values do not filter. Events are returned from the newest
+// to the oldest, so BeforeID is the cursor to continue from a previous page
+type AuditEventFilter struct {
+	UserID    int64
+	EventType string
+	From      *time.Time
+	To        *time.Time
+	BeforeID  int64
+	Limit     int
+}
 
-	CreateAuditEvent func(*sql.Tx) error
+var InitAuditEvent = func() *AuditEvent {
+	return InitAuditEventFunctions(&AuditEvent{})
 }
 
 var InitAuditEventFunctions = func(auditEvent *AuditEvent) *AuditEvent {
-	// Set default SQL implementation for CreateAuditEvent. In the future there could be implementations for
+	// Set default SQL implementations for CreateAuditEvent, FindByUserId and Find. In the future there could be implementations for
 	// other NoSQL DB systems like MongoDB
 	auditEvent.CreateAuditEvent = auditEvent.defaultCreateAuditEvent
+	auditEvent.FindByUserId = auditEvent.defaultFindByUserId
+	auditEvent.Find = auditEvent.defaultFind
 
 	return auditEvent
 }
 
-var NewAuditEvent = func(userId int64, eventDescription string) *AuditEvent {
+var NewAuditEvent = func(userId int64, eventType string, eventDescription string, details map[string]any) *AuditEvent {
 	auditEvent := &AuditEvent{
 		UserID:           userId,
+		EventType:        eventType,
 		EventDescription: eventDescription,
+		Details:          details,
 	}
 
 	return InitAuditEventFunctions(auditEvent)
@@ -35,8 +112,18 @@ var NewAuditEvent = func(userId int64, eventDescription string) *AuditEvent {
 }
 
 func (ae *AuditEvent) defaultCreateAuditEvent(tx *sql.Tx) error {
-	query := `INSERT INTO audit_events(user_id, event_description, event_date) 
-	VALUES (?, ?, ?)`
+	query := `INSERT INTO audit_events(user_id, event_type, event_description, details, event_date)
+	VALUES (?, ?, ?, ?, ?)`
+
+	var details sql.NullString
+	if len(ae.Details) > 0 {
+		detailsJson, err := json.Marshal(ae.Details)
+		if err != nil {
+			log.Errorf("Error marshalling audit event details: %v", err)
+			return err
+		}
+		details = sql.NullString{String: string(detailsJson), Valid: true}
+	}
 
 	stmt, err := tx.Prepare(query)
 	if err != nil {
@@ -48,7 +135,7 @@ func (ae *AuditEvent) defaultCreateAuditEvent(tx *sql.Tx) error {
 	now := time.Now()
 	ae.EventDate = &now
 
-	result, err := stmt.Exec(ae.UserID, ae.EventDescription, ae.EventDate)
+	result, err := stmt.Exec(ae.UserID, ae.EventType, ae.EventDescription, details, ae.EventDate)
 	if err != nil {
 		log.Errorf("Error executing statement for user creation: %v", err)
 		return err
@@ -63,3 +150,90 @@ func (ae *AuditEvent) defaultCreateAuditEvent(tx *sql.Tx) error {
 	ae.ID = int64(auditId)
 	return err
 }
+
+const auditEventColumns = `id, user_id, event_type, event_description, details, event_date`
+
+func (ae *AuditEvent) defaultFindByUserId(userId int64) ([]*AuditEvent, error) {
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events WHERE user_id = ? ORDER BY event_date ASC, id ASC`
+	return ae.findAuditEvents(query, userId)
+}
+
+func (ae *AuditEvent) defaultFind(filter AuditEventFilter) ([]*AuditEvent, error) {
+	conditions := []string{}
+	args := []any{}
+	if filter.UserID > 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.EventType != "" {
+		conditions = append(conditions, "event_type = ?")
+		args = append(args, filter.EventType)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "event_date >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "event_date <= ?")
+		args = append(args, *filter.To)
+	}
+	if filter.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filter.BeforeID)
+	}
+
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	return ae.findAuditEvents(query, args...)
+}
+
+func (ae *AuditEvent) findAuditEvents(query string, args ...any) ([]*AuditEvent, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Errorf("Error querying audit events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	auditEvents := []*AuditEvent{}
+	for rows.Next() {
+		auditEvent := &AuditEvent{}
+		var eventType sql.NullString
+		var eventDescription sql.NullString
+		var details sql.NullString
+		var eventDate sql.NullTime
+		err := rows.Scan(&auditEvent.ID, &auditEvent.UserID, &eventType, &eventDescription, &details, &eventDate)
+		if err != nil {
+			log.Errorf("Error scanning audit event row: %v", err)
+			return nil, err
+		}
+		auditEvent.EventType = eventType.String
+		auditEvent.EventDescription = eventDescription.String
+		if details.Valid && details.String != "" {
+			err = json.Unmarshal([]byte(details.String), &auditEvent.Details)
+			if err != nil {
+				// The event is still meaningful without its details
+				log.Warnf("Error unmarshalling details of audit event %d: %v", auditEvent.ID, err)
+			}
+		}
+		if eventDate.Valid {
+			auditEvent.EventDate = &eventDate.Time
+		}
+		auditEvents = append(auditEvents, auditEvent)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating audit event rows: %v", err)
+		return nil, err
+	}
+
+	return auditEvents, nil
+}
@@ -3,6 +3,9 @@ package models
 import (
 	"errors"
 	"testing"
+	"time"
+
+	appdb "example.com/travel-advisor/db"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -35,11 +38,11 @@ func TestDefaultCreateAuditEvent_Success(t *testing.T) {
 		}
 	}()
 
-	auditEvent := NewAuditEvent(1, "Created something")
+	auditEvent := NewAuditEvent(1, AuditEventLoginSucceeded, "Created something", nil)
 
 	mock.ExpectPrepare("INSERT INTO audit_events").
 		ExpectExec().
-		WithArgs(auditEvent.UserID, auditEvent.EventDescription, sqlmock.AnyArg()).
+		WithArgs(auditEvent.UserID, auditEvent.EventType, auditEvent.EventDescription, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(42, 1))
 
 	err = auditEvent.defaultCreateAuditEvent(tx)
@@ -77,7 +80,7 @@ func TestDefaultCreateAuditEvent_PrepareError(t *testing.T) {
 		}
 	}()
 
-	auditEvent := NewAuditEvent(1, "Prepare error")
+	auditEvent := NewAuditEvent(1, AuditEventLoginSucceeded, "Prepare error", nil)
 
 	mock.ExpectPrepare("INSERT INTO audit_events").
 		WillReturnError(errors.New("prepare failed"))
@@ -115,11 +118,11 @@ func TestDefaultCreateAuditEvent_ExecError(t *testing.T) {
 		}
 	}()
 
-	auditEvent := NewAuditEvent(1, "Exec error")
+	auditEvent := NewAuditEvent(1, AuditEventLoginSucceeded, "Exec error", nil)
 
 	mock.ExpectPrepare("INSERT INTO audit_events").
 		ExpectExec().
-		WithArgs(auditEvent.UserID, auditEvent.EventDescription, sqlmock.AnyArg()).
+		WithArgs(auditEvent.UserID, auditEvent.EventType, auditEvent.EventDescription, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(errors.New("exec failed"))
 
 	err = auditEvent.defaultCreateAuditEvent(tx)
@@ -156,11 +159,11 @@ func TestDefaultCreateAuditEvent_LastInsertIdError(t *testing.T) {
 		}
 	}()
 
-	auditEvent := NewAuditEvent(1, "LastInsertId error")
+	auditEvent := NewAuditEvent(1, AuditEventLoginSucceeded, "LastInsertId error", nil)
 
 	mock.ExpectPrepare("INSERT INTO audit_events").
 		ExpectExec().
-		WithArgs(auditEvent.UserID, auditEvent.EventDescription, sqlmock.AnyArg()).
+		WithArgs(auditEvent.UserID, auditEvent.EventType, auditEvent.EventDescription, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewErrorResult(errors.New("last insert id failed")))
 
 	err = auditEvent.defaultCreateAuditEvent(tx)
@@ -169,3 +172,108 @@ func TestDefaultCreateAuditEvent_LastInsertIdError(t *testing.T) {
 	mock.ExpectRollback()
 
 }
+
+func TestAuditEvent_FindByUserId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	appdb.DB = dbMock
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "user_id", "event_type", "event_description", "details", "event_date"}).
+		AddRow(1, 2, AuditEventLoginSucceeded, "Successful user login", nil, now).
+		AddRow(2, 2, nil, nil, nil, nil)
+	mock.ExpectQuery("SELECT id, user_id, event_type, event_description, details, event_date FROM audit_events WHERE user_id = \\?").
+		WithArgs(int64(2)).
+		WillReturnRows(rows)
+
+	auditEvents, err := InitAuditEvent().FindByUserId(2)
+	assert.NoError(t, err)
+	assert.Len(t, auditEvents, 2)
+	assert.Equal(t, "Successful user login", auditEvents[0].EventDescription)
+	assert.Equal(t, AuditEventLoginSucceeded, auditEvents[0].EventType)
+	assert.Nil(t, auditEvents[1].EventDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDefaultCreateAuditEvent_WithDetails(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	tx, err := dbMock.Begin()
+	assert.NoError(t, err)
+
+	auditEvent := NewAuditEvent(1, AuditEventItineraryCreated, "Itinerary 3 created.", map[string]any{"itineraryId": int64(3)})
+
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(1), AuditEventItineraryCreated, "Itinerary 3 created.", `{"itineraryId":3}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+
+	err = auditEvent.CreateAuditEvent(tx)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditEvent_Find_AllFilters(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	appdb.DB = dbMock
+
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "user_id", "event_type", "event_description", "details", "event_date"}).
+		AddRow(9, 2, AuditEventItineraryCreated, "Itinerary 3 created.", `{"itineraryId":3,"title":"Trip"}`, from)
+	mock.ExpectQuery("SELECT (.+) FROM audit_events WHERE user_id = \\? AND event_type = \\? AND event_date >= \\? AND event_date <= \\? AND id < \\? ORDER BY id DESC LIMIT \\?").
+		WithArgs(int64(2), AuditEventItineraryCreated, from, to, int64(10), 5).
+		WillReturnRows(rows)
+
+	auditEvents, err := InitAuditEvent().Find(AuditEventFilter{UserID: 2, EventType: AuditEventItineraryCreated, From: &from, To: &to, BeforeID: 10, Limit: 5})
+	assert.NoError(t, err)
+	assert.Len(t, auditEvents, 1)
+	assert.Equal(t, map[string]any{"itineraryId": float64(3), "title": "Trip"}, auditEvents[0].Details)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditEvent_Find_NoFilters(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	appdb.DB = dbMock
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "event_type", "event_description", "details", "event_date"}).
+		AddRow(1, 2, AuditEventLoginFailed, "Failed user login due to invalid credentials", "not json", nil)
+	mock.ExpectQuery("SELECT (.+) FROM audit_events ORDER BY id DESC$").
+		WillReturnRows(rows)
+
+	auditEvents, err := InitAuditEvent().Find(AuditEventFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, auditEvents, 1)
+	assert.Nil(t, auditEvents[0].Details, "Invalid details should be ignored")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditEvent_Find_QueryError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	appdb.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM audit_events").WillReturnError(errors.New("query failed"))
+
+	auditEvents, err := InitAuditEvent().Find(AuditEventFilter{UserID: 2})
+	assert.Error(t, err)
+	assert.Nil(t, auditEvents)
+}
+
+func TestIsValidAuditEventType(t *testing.T) {
+	assert.True(t, IsValidAuditEventType(AuditEventJobFileDownloaded))
+	assert.False(t, IsValidAuditEventType("user.unknown"))
+}
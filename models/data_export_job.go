@@ -0,0 +1,260 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// DataExportJob tracks the generation of the ZIP file with all the data of a user. It follows the same status model as
+// ItineraryFileJob: "pending", "running", "completed", "failed", "stopped" or "deleted"
+type DataExportJob struct {
+	ID                int64     `json:"id" example:"1"`
+	UserID            int64     `json:"-"`
+	Status            string    `json:"status" example:"completed"`
+	StatusDescription string    `json:"statusDescription,omitempty" example:"Data export completed successfully"`
+	CreationDate      time.Time `json:"creationDate" example:"2024-06-01T00:00:00Z"`
+	StartDate         time.Time `json:"startDate" example:"2024-06-01T00:00:00Z"`
+	EndDate           time.Time `json:"endDate,omitempty" example:"2024-06-01T00:01:00Z"`
+	Filepath          string    `json:"-"`
+	FileManager       string    `json:"-"`
+	AsyncTaskID       string    `json:"-"`
+
+	FindAliveById                func(id int64) (*DataExportJob, error)         `json:"-"`
+	FindAliveByUserId            func(userId int64) ([]*DataExportJob, error)   `json:"-"`
+	FindDead                     func(fetchLimit int) ([]*DataExportJob, error) `json:"-"`
+	GetInProgressJobsOfUserCount func(userId int64) (int, error)                `json:"-"`
+	PrepareJob                   func() error                                   `json:"-"`
+	AddAsyncTaskId               func(asyncTaskId string) error                 `json:"-"`
+	StartJob                     func() error                                   `json:"-"`
+	FailJob                      func(errorDescription string) error            `json:"-"`
+	CompleteJob                  func() error                                   `json:"-"`
+	DeleteJob                    func() error                                   `json:"-"`
+	SoftDeleteJob                func() error                                   `json:"-"`
+	SoftDeleteJobsByUserIdTx     func(userId int64, tx *sql.Tx) error           `json:"-"`
+}
+
+var InitDataExportJob = func() *DataExportJob {
+	return InitDataExportJobFunctions(&DataExportJob{})
+}
+
+var InitDataExportJobFunctions = func(job *DataExportJob) *DataExportJob {
+	// Set default SQL implementations for the job queries and status changes. In the future there could be implementations for
+	// other NoSQL DB systems like MongoDB
+	job.FindAliveById = job.defaultFindAliveById
+	job.FindAliveByUserId = job.defaultFindAliveByUserId
+	job.FindDead = job.defaultFindDead
+	job.GetInProgressJobsOfUserCount = job.defaultGetInProgressJobsOfUserCount
+	job.PrepareJob = job.defaultPrepareJob
+	job.AddAsyncTaskId = job.defaultAddAsyncTaskId
+	job.StartJob = job.defaultStartJob
+	job.FailJob = job.defaultFailJob
+	job.CompleteJob = job.defaultCompleteJob
+	job.DeleteJob = job.defaultDeleteJob
+	job.SoftDeleteJob = job.defaultSoftDeleteJob
+	job.SoftDeleteJobsByUserIdTx = job.defaultSoftDeleteJobsByUserIdTx
+	return job
+}
+
+var NewDataExportJob = func(userId int64) *DataExportJob {
+	job := &DataExportJob{
+		UserID: userId,
+	}
+
+	return InitDataExportJobFunctions(job)
+}
+
+const dataExportJobColumns = `id, user_id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, async_task_id`
+
+type dataExportJobScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDataExportJob(scanner dataExportJobScanner) (*DataExportJob, error) {
+	job := &DataExportJob{}
+
+	var statusDescription sql.NullString
+	var startDate sql.NullTime
+	var endDate sql.NullTime
+	var filePath sql.NullString
+	var asyncTaskId sql.NullString
+	err := scanner.Scan(&job.ID, &job.UserID, &job.Status, &statusDescription, &job.CreationDate, &startDate, &endDate, &filePath,
+		&job.FileManager, &asyncTaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	job.StatusDescription = statusDescription.String
+	job.StartDate = startDate.Time
+	job.EndDate = endDate.Time
+	job.Filepath = filePath.String
+	job.AsyncTaskID = asyncTaskId.String
+
+	return job, nil
+}
+
+func (dej *DataExportJob) defaultFindAliveById(id int64) (*DataExportJob, error) {
+	query := `SELECT ` + dataExportJobColumns + ` FROM data_export_jobs WHERE id = ? AND status != 'deleted'`
+	row := db.DB.QueryRow(query, id)
+
+	return scanDataExportJob(row)
+}
+
+func (dej *DataExportJob) findJobs(query string, args ...any) ([]*DataExportJob, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Errorf("Error querying data export jobs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*DataExportJob{}
+	for rows.Next() {
+		job, err := scanDataExportJob(rows)
+		if err != nil {
+			log.Errorf("Error scanning data export job row: %v", err)
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating data export job rows: %v", err)
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (dej *DataExportJob) defaultFindAliveByUserId(userId int64) ([]*DataExportJob, error) {
+	query := `SELECT ` + dataExportJobColumns + ` FROM data_export_jobs WHERE user_id = ? AND status != 'deleted' ORDER BY creation_date DESC`
+	return dej.findJobs(query, userId)
+}
+
+func (dej *DataExportJob) defaultFindDead(fetchLimit int) ([]*DataExportJob, error) {
+	query := `SELECT ` + dataExportJobColumns + ` FROM data_export_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT ?`
+	return dej.findJobs(query, fetchLimit)
+}
+
+func (dej *DataExportJob) defaultGetInProgressJobsOfUserCount(userId int64) (int, error) {
+	query := `SELECT COUNT(id) FROM data_export_jobs WHERE status IN ('pending','running') AND user_id = ?`
+	row := db.DB.QueryRow(query, userId)
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (dej *DataExportJob) defaultPrepareJob() error {
+	dej.Status = "pending"
+	dej.CreationDate = time.Now()
+
+	filemanager := os.Getenv("FILE_MANAGER")
+	if filemanager == "" {
+		filemanager = "local" // Local file manager if not set
+	}
+
+	dej.FileManager = filemanager
+
+	query := `INSERT INTO data_export_jobs (user_id, status, creation_date, file_manager) VALUES (?, ?, ?, ?)`
+	res, err := db.DB.Exec(query, dej.UserID, dej.Status, dej.CreationDate, dej.FileManager)
+	if err != nil {
+		return fmt.Errorf("could not insert data export job into database: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("could not get the ID of the data export job: %w", err)
+	}
+	dej.ID = id
+
+	return nil
+}
+
+func (dej *DataExportJob) defaultAddAsyncTaskId(asyncTaskId string) error {
+	dej.AsyncTaskID = asyncTaskId
+	query := `UPDATE data_export_jobs SET async_task_id = ? WHERE id = ?`
+	_, err := db.DB.Exec(query, dej.AsyncTaskID, dej.ID)
+	if err != nil {
+		log.Errorf("Error updating async task ID of data export job in database: %v", err)
+		return fmt.Errorf("failed to update async task ID in database: %w", err)
+	}
+	return nil
+}
+
+func (dej *DataExportJob) defaultStartJob() error {
+	dej.Status = "running"
+	dej.StartDate = time.Now()
+	query := `UPDATE data_export_jobs SET status = ?, start_date = ? WHERE id = ?`
+	_, err := db.DB.Exec(query, dej.Status, dej.StartDate, dej.ID)
+	if err != nil {
+		log.Errorf("Error updating data export job status to 'running' in database: %v", err)
+		return fmt.Errorf("failed to update job status to 'running' in database: %w", err)
+	}
+	return nil
+}
+
+func (dej *DataExportJob) defaultFailJob(errorDescription string) error {
+	dej.Status = "failed"
+	dej.StatusDescription = errorDescription
+	dej.EndDate = time.Now()
+
+	query := `UPDATE data_export_jobs SET status = ?, status_description = ?, end_date = ? WHERE id = ?`
+	_, err := db.DB.Exec(query, dej.Status, dej.StatusDescription, dej.EndDate, dej.ID)
+	if err != nil {
+		log.Warnf("Error updating data export job status to 'failed' in database: %v", err)
+		return fmt.Errorf("failed to update job status to 'failed' in database: %w", err)
+	}
+	return nil
+}
+
+func (dej *DataExportJob) defaultCompleteJob() error {
+	dej.Status = "completed"
+	dej.StatusDescription = "Data export completed successfully"
+	dej.EndDate = time.Now()
+
+	query := `UPDATE data_export_jobs SET status = ?, status_description = ?, file_path = ?, end_date = ? WHERE id = ?`
+	_, err := db.DB.Exec(query, dej.Status, dej.StatusDescription, dej.Filepath, dej.EndDate, dej.ID)
+	if err != nil {
+		log.Errorf("Error updating data export job status to 'completed' in database: %v", err)
+		return fmt.Errorf("failed to update job status to 'completed' in database: %w", err)
+	}
+	return nil
+}
+
+func (dej *DataExportJob) defaultDeleteJob() error {
+	query := `DELETE FROM data_export_jobs WHERE id = ?`
+	_, err := db.DB.Exec(query, dej.ID)
+	if err != nil {
+		log.Errorf("Error deleting data export job from database: %v", err)
+		return fmt.Errorf("failed to delete data export job from database: %w", err)
+	}
+	return nil
+}
+
+func (dej *DataExportJob) defaultSoftDeleteJob() error {
+	query := `UPDATE data_export_jobs SET status = 'deleted' WHERE id = ?`
+	_, err := db.DB.Exec(query, dej.ID)
+	if err != nil {
+		log.Errorf("Error soft deleting data export job: %v", err)
+		return fmt.Errorf("failed to soft delete data export job: %w", err)
+	}
+	return nil
+}
+
+func (dej *DataExportJob) defaultSoftDeleteJobsByUserIdTx(userId int64, tx *sql.Tx) error {
+	query := `UPDATE data_export_jobs SET status = 'deleted' WHERE user_id = ?`
+	_, err := tx.Exec(query, userId)
+	if err != nil {
+		log.Errorf("Error soft deleting data export jobs by user ID: %v", err)
+		return fmt.Errorf("failed to soft delete data export jobs by user ID: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var dataExportJobTestColumns = []string{"id", "user_id", "status", "status_description", "creation_date", "start_date", "end_date",
+	"file_path", "file_manager", "async_task_id"}
+
+func TestDataExportJob_PrepareJob_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+	t.Setenv("FILE_MANAGER", "")
+
+	mock.ExpectExec("INSERT INTO data_export_jobs").
+		WithArgs(int64(2), "pending", sqlmock.AnyArg(), "local").
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	job := NewDataExportJob(2)
+	err = job.PrepareJob()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), job.ID)
+	assert.Equal(t, "pending", job.Status)
+	assert.Equal(t, "local", job.FileManager)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataExportJob_PrepareJob_Error(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectExec("INSERT INTO data_export_jobs").WillReturnError(errors.New("db error"))
+
+	err = NewDataExportJob(2).PrepareJob()
+	assert.Error(t, err)
+}
+
+func TestDataExportJob_FindAliveById_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	now := time.Now()
+	rows := sqlmock.NewRows(dataExportJobTestColumns).
+		AddRow(7, 2, "completed", "Data export completed successfully", now, now, now, "files/users/2/exports/a.zip", "local", "task")
+	mock.ExpectQuery("SELECT (.+) FROM data_export_jobs WHERE id = \\? AND status != 'deleted'").
+		WithArgs(int64(7)).
+		WillReturnRows(rows)
+
+	job, err := InitDataExportJob().FindAliveById(7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), job.UserID)
+	assert.Equal(t, "files/users/2/exports/a.zip", job.Filepath)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataExportJob_FindAliveByUserId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	rows := sqlmock.NewRows(dataExportJobTestColumns).
+		AddRow(8, 2, "pending", nil, time.Now(), nil, nil, nil, "local", nil)
+	mock.ExpectQuery("SELECT (.+) FROM data_export_jobs WHERE user_id = \\? AND status != 'deleted'").
+		WithArgs(int64(2)).
+		WillReturnRows(rows)
+
+	jobs, err := InitDataExportJob().FindAliveByUserId(2)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "", jobs[0].Filepath)
+	assert.True(t, jobs[0].StartDate.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataExportJob_GetInProgressJobsOfUserCount(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT COUNT\\(id\\) FROM data_export_jobs").
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	count, err := InitDataExportJob().GetInProgressJobsOfUserCount(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestDataExportJob_CompleteJob_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectExec("UPDATE data_export_jobs SET status = \\?, status_description = \\?, file_path = \\?, end_date = \\? WHERE id = \\?").
+		WithArgs("completed", "Data export completed successfully", "files/a.zip", sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	job := InitDataExportJobFunctions(&DataExportJob{ID: 7, Filepath: "files/a.zip"})
+	err = job.CompleteJob()
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", job.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataExportJob_SoftDeleteJob_Error(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectExec("UPDATE data_export_jobs SET status = 'deleted' WHERE id = \\?").
+		WithArgs(int64(7)).
+		WillReturnError(errors.New("db error"))
+
+	err = InitDataExportJobFunctions(&DataExportJob{ID: 7}).SoftDeleteJob()
+	assert.Error(t, err)
+}
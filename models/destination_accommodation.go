@@ -0,0 +1,248 @@
+package models
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+	"example.com/travel-advisor/utils"
+)
+
+// DestinationAccommodation is where the travellers sleep during a stay in a destination of an itinerary. Accommodations belong to the
+// country and city of the destination and are matched to the stay their check-in date falls in, so they are kept when the destinations
+// of the itinerary are replaced. DestinationID is not stored: it is set when the itinerary is retrieved with its destinations. The cost
+// of the whole stay is in its own currency (an ISO 4217 code)
+type DestinationAccommodation struct {
+	ID                 int64      `json:"id" example:"1"`
+	ItineraryID        int64      `json:"itineraryId" example:"1"`
+	DestinationID      *int64     `json:"destinationId,omitempty" example:"1"`
+	Country            string     `json:"country" example:"Spain"`
+	City               string     `json:"city" example:"Madrid"`
+	Name               string     `json:"name" example:"Hotel Gran Vía"`
+	Address            string     `json:"address,omitempty" example:"Gran Vía 25, 28013 Madrid"`
+	CheckIn            time.Time  `json:"checkIn" example:"2024-07-01T15:00:00+02:00"`
+	CheckOut           time.Time  `json:"checkOut" example:"2024-07-05T11:00:00+02:00"`
+	ConfirmationNumber string     `json:"confirmationNumber,omitempty" example:"HX-48213"`
+	Cost               *float64   `json:"cost,omitempty" example:"480"`
+	Currency           string     `json:"currency,omitempty" example:"EUR"`
+	CreationDate       *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	UpdateDate         *time.Time `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindByItineraryId     func(itineraryId int64) ([]*DestinationAccommodation, error) `json:"-"`
+	Create                func() error                                                 `json:"-"`
+	Update                func() error                                                 `json:"-"`
+	Delete                func() error                                                 `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                    `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                        `json:"-"`
+}
+
+var InitDestinationAccommodation = func() *DestinationAccommodation {
+	return InitDestinationAccommodationFunctions(&DestinationAccommodation{})
+}
+
+var InitDestinationAccommodationFunctions = func(accommodation *DestinationAccommodation) *DestinationAccommodation {
+	// Set default SQL implementations for FindByItineraryId, Create, Update, Delete, DeleteByItineraryIdTx and DeleteByOwnerIdTx. In
+	// the future there could be implementations for other NoSQL DB systems like MongoDB
+	accommodation.FindByItineraryId = accommodation.defaultFindByItineraryId
+	accommodation.Create = accommodation.defaultCreate
+	accommodation.Update = accommodation.defaultUpdate
+	accommodation.Delete = accommodation.defaultDelete
+	accommodation.DeleteByItineraryIdTx = accommodation.defaultDeleteByItineraryIdTx
+	accommodation.DeleteByOwnerIdTx = accommodation.defaultDeleteByOwnerIdTx
+
+	return accommodation
+}
+
+// BelongsTo returns whether the accommodation is in the city of the destination, by any of its names in the gazetteer, and its
+// check-in date, in its own time zone, is during the stay
+func (a *DestinationAccommodation) BelongsTo(destination *ItineraryTravelDestination) bool {
+	checkIn := a.CheckIn.Format(time.DateOnly)
+	return utils.SamePlace(a.Country, a.City, destination.Country, destination.City) &&
+		checkIn >= destination.ArrivalDate.UTC().Format(time.DateOnly) && checkIn <= destination.DepartureDate.UTC().Format(time.DateOnly)
+}
+
+// ConnectAccommodations sets the destinations the accommodations belong to, at most one per destination, and sorts the accommodations
+// by check-in. The accommodations of no destination are left without one
+func ConnectAccommodations(destinations []*ItineraryTravelDestination, accommodations []*DestinationAccommodation) {
+	sort.SliceStable(accommodations, func(a int, b int) bool {
+		return accommodations[a].CheckIn.Before(accommodations[b].CheckIn)
+	})
+
+	connected := map[int64]bool{}
+	for _, accommodation := range accommodations {
+		accommodation.DestinationID = nil
+		for _, destination := range destinations {
+			if !connected[destination.ID] && accommodation.BelongsTo(destination) {
+				accommodation.DestinationID = &destination.ID
+				connected[destination.ID] = true
+				break
+			}
+		}
+	}
+}
+
+func (a *DestinationAccommodation) defaultFindByItineraryId(itineraryId int64) ([]*DestinationAccommodation, error) {
+	query := `SELECT id, itinerary_id, country, city, name, address, check_in, check_out, confirmation_number, cost, currency,
+	creation_date, update_date
+	FROM destination_accommodations WHERE itinerary_id = ? ORDER BY id`
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error fetching accommodations of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	accommodations := []*DestinationAccommodation{}
+	for rows.Next() {
+		accommodation := &DestinationAccommodation{}
+		err = rows.Scan(&accommodation.ID, &accommodation.ItineraryID, &accommodation.Country, &accommodation.City, &accommodation.Name,
+			&accommodation.Address, &accommodation.CheckIn, &accommodation.CheckOut, &accommodation.ConfirmationNumber, &accommodation.Cost,
+			&accommodation.Currency, &accommodation.CreationDate, &accommodation.UpdateDate)
+		if err != nil {
+			log.Errorf("Error scanning accommodation of itinerary %d: %v", itineraryId, err)
+			return nil, err
+		}
+		accommodations = append(accommodations, accommodation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accommodations, nil
+}
+
+func (a *DestinationAccommodation) defaultCreate() error {
+	query := `INSERT INTO destination_accommodations(itinerary_id, country, city, name, address, check_in, check_out, confirmation_number,
+	cost, currency, creation_date, update_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for destination accommodation: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(a.ItineraryID, a.Country, a.City, a.Name, a.Address, a.CheckIn, a.CheckOut, a.ConfirmationNumber, a.Cost,
+		a.Currency, now, now)
+	if err != nil {
+		log.Errorf("Error executing insert for accommodation of itinerary %d: %v", a.ItineraryID, err)
+		return err
+	}
+
+	a.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for destination accommodation: %v", err)
+		return err
+	}
+	a.CreationDate = &now
+	a.UpdateDate = &now
+
+	return nil
+}
+
+// defaultUpdate saves the accommodation, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has no
+// such accommodation
+func (a *DestinationAccommodation) defaultUpdate() error {
+	query := `UPDATE destination_accommodations SET country = ?, city = ?, name = ?, address = ?, check_in = ?, check_out = ?,
+	confirmation_number = ?, cost = ?, currency = ?, update_date = ? WHERE id = ? AND itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for destination accommodation: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(a.Country, a.City, a.Name, a.Address, a.CheckIn, a.CheckOut, a.ConfirmationNumber, a.Cost, a.Currency, now,
+		a.ID, a.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing update for accommodation %d of itinerary %d: %v", a.ID, a.ItineraryID, err)
+		return err
+	}
+
+	err = checkAccommodationFound(result, a)
+	if err != nil {
+		return err
+	}
+	a.UpdateDate = &now
+
+	return nil
+}
+
+// defaultDelete deletes the accommodation, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has
+// no such accommodation
+func (a *DestinationAccommodation) defaultDelete() error {
+	query := `DELETE FROM destination_accommodations WHERE id = ? AND itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for destination accommodation: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(a.ID, a.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing delete for accommodation %d of itinerary %d: %v", a.ID, a.ItineraryID, err)
+		return err
+	}
+
+	return checkAccommodationFound(result, a)
+}
+
+func checkAccommodationFound(result sql.Result, a *DestinationAccommodation) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting affected rows for destination accommodation: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Errorf("Accommodation %d not found in itinerary %d", a.ID, a.ItineraryID)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (a *DestinationAccommodation) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM destination_accommodations WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for accommodations of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for accommodations of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the accommodations of all the itineraries of an owner
+func (a *DestinationAccommodation) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM destination_accommodations WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for accommodations of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for accommodations of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
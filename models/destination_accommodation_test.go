@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestinationAccommodation_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	checkIn := time.Date(2024, time.July, 1, 15, 0, 0, 0, time.UTC)
+	checkOut := time.Date(2024, time.July, 5, 11, 0, 0, 0, time.UTC)
+	cost := 480.0
+	mock.ExpectPrepare("INSERT INTO destination_accommodations").
+		ExpectExec().
+		WithArgs(int64(3), "Spain", "Madrid", "Hotel Gran Vía", "", checkIn, checkOut, "HX-48213", &cost, "EUR", sqlmock.AnyArg(),
+			sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+
+	accommodation := InitDestinationAccommodation()
+	accommodation.ItineraryID = 3
+	accommodation.Country, accommodation.City = "Spain", "Madrid"
+	accommodation.Name = "Hotel Gran Vía"
+	accommodation.CheckIn, accommodation.CheckOut = checkIn, checkOut
+	accommodation.ConfirmationNumber = "HX-48213"
+	accommodation.Cost, accommodation.Currency = &cost, "EUR"
+	assert.NoError(t, accommodation.Create())
+	assert.Equal(t, int64(4), accommodation.ID)
+	assert.NotNil(t, accommodation.CreationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestinationAccommodation_Update_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("UPDATE destination_accommodations SET (.+) WHERE id = \\? AND itinerary_id = \\?").
+		ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	accommodation := InitDestinationAccommodation()
+	accommodation.ID = 4
+	accommodation.ItineraryID = 3
+	assert.ErrorIs(t, accommodation.Update(), sql.ErrNoRows)
+	assert.Nil(t, accommodation.UpdateDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnectAccommodations(t *testing.T) {
+	day := func(d int, hour int) time.Time { return time.Date(2024, time.July, d, hour, 0, 0, 0, time.UTC) }
+	destinations := []*ItineraryTravelDestination{
+		{ID: 1, Country: "Spain", City: "Madrid", ArrivalDate: day(1, 0), DepartureDate: day(4, 0)},
+		{ID: 2, Country: "Spain", City: "Seville", ArrivalDate: day(4, 0), DepartureDate: day(6, 0)},
+		{ID: 3, Country: "Spain", City: "Madrid", ArrivalDate: day(6, 0), DepartureDate: day(8, 0)},
+	}
+	accommodations := []*DestinationAccommodation{
+		{ID: 1, Country: "ES", City: "madrid", CheckIn: day(6, 15), CheckOut: day(8, 11)},
+		{ID: 2, Country: "Spain", City: "Seville", CheckIn: day(10, 15), CheckOut: day(11, 11)},
+		{ID: 3, Country: "Spain", City: "Madrid", CheckIn: day(1, 15), CheckOut: day(4, 11)},
+		{ID: 4, Country: "Spain", City: "Madrid", CheckIn: day(2, 15), CheckOut: day(4, 11)},
+	}
+
+	ConnectAccommodations(destinations, accommodations)
+
+	ids := []int64{}
+	for _, accommodation := range accommodations {
+		ids = append(ids, accommodation.ID)
+	}
+	assert.Equal(t, []int64{3, 4, 1, 2}, ids)
+	assert.Equal(t, int64(1), *accommodations[0].DestinationID)
+	assert.Nil(t, accommodations[1].DestinationID)
+	assert.Equal(t, int64(3), *accommodations[2].DestinationID)
+	assert.Nil(t, accommodations[3].DestinationID)
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Sources of the cost estimates of the destinations
+const (
+	CostEstimateSourceManual    = "manual"
+	CostEstimateSourceGenerated = "generated"
+)
+
+// DestinationCostEstimate is the estimate of what the travellers spend a day in a destination of an itinerary, broken down by category
+// and in its own currency (an ISO 4217 code). Estimates belong to the country and city of a destination, so they are kept when the
+// destinations of the itinerary are replaced and apply to every stay in the same city
+type DestinationCostEstimate struct {
+	ID          int64     `json:"-"`
+	ItineraryID int64     `json:"itineraryId" example:"1"`
+	Country     string    `json:"country" example:"Spain"`
+	City        string    `json:"city" example:"Madrid"`
+	Currency    string    `json:"currency" example:"EUR"`
+	Lodging     Amount    `json:"lodging" swaggertype:"number" example:"120"`
+	Food        Amount    `json:"food" swaggertype:"number" example:"60"`
+	Transport   Amount    `json:"transport" swaggertype:"number" example:"15"`
+	Activities  Amount    `json:"activities" swaggertype:"number" example:"40"`
+	Source      string    `json:"source" example:"generated"`
+	UpdateDate  time.Time `json:"updateDate" example:"2024-06-01T00:00:00Z"`
+
+	FindByItineraryId     func(itineraryId int64) ([]*DestinationCostEstimate, error) `json:"-"`
+	Save                  func() error                                                `json:"-"`
+	Delete                func() error                                                `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                   `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                       `json:"-"`
+}
+
+var InitDestinationCostEstimate = func() *DestinationCostEstimate {
+	return InitDestinationCostEstimateFunctions(&DestinationCostEstimate{})
+}
+
+var InitDestinationCostEstimateFunctions = func(estimate *DestinationCostEstimate) *DestinationCostEstimate {
+	// Set default SQL implementations for FindByItineraryId, Save, Delete, DeleteByItineraryIdTx and DeleteByOwnerIdTx. In the future
+	// there could be implementations for other NoSQL DB systems like MongoDB
+	estimate.FindByItineraryId = estimate.defaultFindByItineraryId
+	estimate.Save = estimate.defaultSave
+	estimate.Delete = estimate.defaultDelete
+	estimate.DeleteByItineraryIdTx = estimate.defaultDeleteByItineraryIdTx
+	estimate.DeleteByOwnerIdTx = estimate.defaultDeleteByOwnerIdTx
+
+	return estimate
+}
+
+// Total returns the estimated spending of a day in all the categories
+func (e *DestinationCostEstimate) Total() Amount {
+	return e.Lodging + e.Food + e.Transport + e.Activities
+}
+
+func (e *DestinationCostEstimate) defaultFindByItineraryId(itineraryId int64) ([]*DestinationCostEstimate, error) {
+	query := `SELECT id, itinerary_id, country, city, currency, lodging_cents, food_cents, transport_cents, activities_cents, source,
+	update_date FROM destination_cost_estimates WHERE itinerary_id = ? ORDER BY id`
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error fetching cost estimates of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	estimates := []*DestinationCostEstimate{}
+	for rows.Next() {
+		estimate := &DestinationCostEstimate{}
+		err = rows.Scan(&estimate.ID, &estimate.ItineraryID, &estimate.Country, &estimate.City, &estimate.Currency, &estimate.Lodging,
+			&estimate.Food, &estimate.Transport, &estimate.Activities, &estimate.Source, &estimate.UpdateDate)
+		if err != nil {
+			log.Errorf("Error scanning cost estimate of itinerary %d: %v", itineraryId, err)
+			return nil, err
+		}
+		estimates = append(estimates, estimate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return estimates, nil
+}
+
+// defaultSave saves the estimate of the country and city of the itinerary, replacing the previous one
+func (e *DestinationCostEstimate) defaultSave() error {
+	query := `INSERT INTO destination_cost_estimates(itinerary_id, country, city, currency, lodging_cents, food_cents, transport_cents,
+	activities_cents, source, update_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (itinerary_id, country, city) DO UPDATE SET currency = excluded.currency, lodging_cents = excluded.lodging_cents,
+	food_cents = excluded.food_cents, transport_cents = excluded.transport_cents, activities_cents = excluded.activities_cents,
+	source = excluded.source, update_date = excluded.update_date`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing upsert for destination cost estimate: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(e.ItineraryID, e.Country, e.City, e.Currency, e.Lodging, e.Food, e.Transport, e.Activities, e.Source, now)
+	if err != nil {
+		log.Errorf("Error executing upsert for cost estimate of %s (%s) in itinerary %d: %v", e.City, e.Country, e.ItineraryID, err)
+		return err
+	}
+
+	e.UpdateDate = now
+
+	return nil
+}
+
+// defaultDelete deletes the estimate of the country and city of the itinerary. Returns sql.ErrNoRows if there is none
+func (e *DestinationCostEstimate) defaultDelete() error {
+	query := `DELETE FROM destination_cost_estimates WHERE itinerary_id = ? AND country = ? AND city = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for destination cost estimate: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(e.ItineraryID, e.Country, e.City)
+	if err != nil {
+		log.Errorf("Error executing delete for cost estimate of %s (%s) in itinerary %d: %v", e.City, e.Country, e.ItineraryID, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (e *DestinationCostEstimate) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM destination_cost_estimates WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for cost estimates of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for cost estimates of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the cost estimates of all the itineraries of an owner
+func (e *DestinationCostEstimate) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM destination_cost_estimates WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for cost estimates of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for cost estimates of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestinationCostEstimate_FindByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, itinerary_id, country, city, currency, lodging_cents, food_cents, transport_cents, activities_cents, source, update_date\\s+" +
+		"FROM destination_cost_estimates WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "country", "city", "currency", "lodging_cents", "food_cents", "transport_cents", "activities_cents",
+			"source", "update_date"}).
+			AddRow(1, 3, "Spain", "Madrid", "EUR", 12000, 6000, 1500, 4000, CostEstimateSourceGenerated, now).
+			AddRow(2, 3, "Japan", "Kyoto", "JPY", 1800000, 600000, 150000, 400000, CostEstimateSourceManual, now))
+
+	estimates, err := InitDestinationCostEstimate().FindByItineraryId(3)
+	assert.NoError(t, err)
+	assert.Len(t, estimates, 2)
+	assert.Equal(t, "Madrid", estimates[0].City)
+	assert.Equal(t, AmountOf(235), estimates[0].Total())
+	assert.Equal(t, "JPY", estimates[1].Currency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestinationCostEstimate_Save_Error(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("INSERT INTO destination_cost_estimates").
+		ExpectExec().
+		WithArgs(int64(3), "Spain", "Madrid", "EUR", int64(12000), int64(6000), int64(1500), int64(4000), CostEstimateSourceManual, sqlmock.AnyArg()).
+		WillReturnError(errors.New("constraint failed"))
+
+	estimate := InitDestinationCostEstimate()
+	estimate.ItineraryID = 3
+	estimate.Country = "Spain"
+	estimate.City = "Madrid"
+	estimate.Currency = "EUR"
+	estimate.Lodging, estimate.Food, estimate.Transport, estimate.Activities = AmountOf(120), AmountOf(60), AmountOf(15), AmountOf(40)
+	estimate.Source = CostEstimateSourceManual
+	assert.EqualError(t, estimate.Save(), "constraint failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestinationCostEstimate_Delete_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("DELETE FROM destination_cost_estimates WHERE itinerary_id = \\? AND country = \\? AND city = \\?").
+		ExpectExec().
+		WithArgs(int64(3), "Spain", "Madrid").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	estimate := InitDestinationCostEstimate()
+	estimate.ItineraryID = 3
+	estimate.Country = "Spain"
+	estimate.City = "Madrid"
+	assert.ErrorIs(t, estimate.Delete(), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
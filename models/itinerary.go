@@ -1,9 +1,13 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
 	"example.com/travel-advisor/db"
+	"example.com/travel-advisor/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,15 +18,63 @@ type Itinerary struct {
 	CreationDate       *time.Time                    `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
 	UpdateDate         *time.Time                    `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
 	TravelDestinations []*ItineraryTravelDestination `json:"travelDestinations,omitempty"`
+	TransportLegs      []*ItineraryTransportLeg      `json:"transportLegs,omitempty"`
+	Accommodations     []*DestinationAccommodation   `json:"accommodations,omitempty"`
 	OwnerID            int64                         `json:"ownerId" example:"1"`
 	Notes              *string                       `json:"notes,omitempty" example:"I want to enjoy the nightlife"`
+	Version            int64                         `json:"version" example:"1"`
+
+	FindById            func(id int64, includeDestinations bool) (*Itinerary, error)        `json:"-"`
+	FindLightweightById func(id int64) (*Itinerary, error)                                  `json:"-"`
+	FindByOwnerId       func(ownerId int64) ([]*Itinerary, error)                           `json:"-"`
+	Find                func(filter ItineraryFilter) ([]*Itinerary, error)                  `json:"-"`
+	Count               func(filter ItineraryFilter) (int64, error)                         `json:"-"`
+	Create              func() error                                                        `json:"-"`
+	CreateClone         func(sourceId int64) error                                          `json:"-"`
+	Update              func(authorId int64) error                                          `json:"-"`
+	Restore             func(revision *ItineraryRevision, authorId int64) error             `json:"-"`
+	AddDestination      func(destination *ItineraryTravelDestination, authorId int64) error `json:"-"`
+	UpdateDestination   func(destination *ItineraryTravelDestination, authorId int64) error `json:"-"`
+	DeleteDestination   func(destination *ItineraryTravelDestination, authorId int64) error `json:"-"`
+	Delete              func() error                                                        `json:"-"`
+	DeleteByOwnerIdTx   func(ownerId int64, tx *sql.Tx) error                               `json:"-"`
+}
+
+// ErrItineraryVersionConflict is returned when an itinerary is updated or deleted from a stale version, because it changed since it
+// was read
+var ErrItineraryVersionConflict = errors.New("itinerary version conflict")
+
+// Sort fields of the itinerary listings. The travel date of an itinerary is the arrival date of its first destination
+const (
+	ItinerarySortCreationDate = "creationDate"
+	ItinerarySortUpdateDate   = "updateDate"
+	ItinerarySortTravelDate   = "travelDate"
+)
+
+var ItinerarySortFields = []string{ItinerarySortCreationDate, ItinerarySortUpdateDate, ItinerarySortTravelDate}
 
-	FindById            func(id int64, includeDestinations bool) (*Itinerary, error) `json:"-"`
-	FindLightweightById func(id int64) (*Itinerary, error)                           `json:"-"`
-	FindByOwnerId       func(ownerId int64) ([]*Itinerary, error)                    `json:"-"`
-	Create              func() error                                                 `json:"-"`
-	Update              func() error                                                 `json:"-"`
-	Delete              func() error                                                 `json:"-"`
+// itinerarySortExpressions maps the sort fields to the SQL expressions they sort by. Expressions refer to the itineraries table as i
+var itinerarySortExpressions = map[string]string{
+	ItinerarySortCreationDate: "i.creation_date",
+	ItinerarySortUpdateDate:   "i.update_date",
+	ItinerarySortTravelDate:   "(SELECT MIN(d.arrival_date) FROM itinerary_travel_destinations d WHERE d.itinerary_id = i.id)",
+}
+
+// ItineraryFilter restricts the itineraries returned by Find and Count. Zero values do not filter. Country and City match a destination
+// ignoring case, or by their code and names in the gazetteer, TravelFrom and TravelTo keep the itineraries with a destination visited
+// in that range and Title matches part of the title. Itineraries are sorted by SortBy (creation date by default) and then by ID, and
+// AfterID is the cursor to continue from the last itinerary of a previous page. Count ignores AfterID and Limit
+type ItineraryFilter struct {
+	OwnerID    int64
+	Country    string
+	City       string
+	TravelFrom *time.Time
+	TravelTo   *time.Time
+	Title      string
+	SortBy     string
+	Ascending  bool
+	AfterID    int64
+	Limit      int
 }
 
 var InitItinerary = func() *Itinerary {
@@ -30,14 +82,23 @@ var InitItinerary = func() *Itinerary {
 }
 
 var InitItineraryFunctions = func(itinerary *Itinerary) *Itinerary {
-	// Set default SQL implementations for FindById, FindByOwnerId, Create, Update and Delete. In the future there could be implementations for
+	// Set default SQL implementations for FindById, FindByOwnerId, Find, Count, Create, CreateClone, Update, Restore, AddDestination,
+	// UpdateDestination, DeleteDestination, Delete and DeleteByOwnerIdTx. In the future there could be implementations for
 	// other NoSQL DB systems like MongoDB
 	itinerary.FindById = itinerary.defaultFindById
 	itinerary.FindLightweightById = itinerary.defaultFindLightweightById
 	itinerary.FindByOwnerId = itinerary.defaultFindByOwnerId
+	itinerary.Find = itinerary.defaultFind
+	itinerary.Count = itinerary.defaultCount
 	itinerary.Create = itinerary.defaultCreate
+	itinerary.CreateClone = itinerary.defaultCreateClone
 	itinerary.Update = itinerary.defaultUpdate
+	itinerary.Restore = itinerary.defaultRestore
+	itinerary.AddDestination = itinerary.defaultAddDestination
+	itinerary.UpdateDestination = itinerary.defaultUpdateDestination
+	itinerary.DeleteDestination = itinerary.defaultDeleteDestination
 	itinerary.Delete = itinerary.defaultDelete
+	itinerary.DeleteByOwnerIdTx = itinerary.defaultDeleteByOwnerIdTx
 
 	return itinerary
 }
@@ -55,12 +116,13 @@ var NewItinerary = func(title string, description string, notes *string, travelD
 }
 
 func (i *Itinerary) defaultFindById(id int64, includeDestinations bool) (*Itinerary, error) {
-	query := `SELECT id, title, description, notes, owner_id, creation_date, update_date
+	query := `SELECT id, title, description, notes, owner_id, creation_date, update_date, version
 	FROM itineraries WHERE id = ?`
 	row := db.DB.QueryRow(query, id)
 
 	itinerary := &Itinerary{}
-	err := row.Scan(&itinerary.ID, &itinerary.Title, &itinerary.Description, &itinerary.Notes, &itinerary.OwnerID, &itinerary.CreationDate, &itinerary.UpdateDate)
+	err := row.Scan(&itinerary.ID, &itinerary.Title, &itinerary.Description, &itinerary.Notes, &itinerary.OwnerID, &itinerary.CreationDate, &itinerary.UpdateDate,
+		&itinerary.Version)
 	if err != nil {
 		log.Errorf("Error fetching itinerary by ID %d: %v", id, err)
 		return nil, err
@@ -77,20 +139,56 @@ func (i *Itinerary) defaultFindById(id int64, includeDestinations bool) (*Itiner
 		}
 
 		itinerary.TravelDestinations = travelDestinations
+
+		err = itinerary.findTransportLegs()
+		if err != nil {
+			return nil, err
+		}
+
+		err = itinerary.findAccommodations()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return itinerary, nil
 
 }
 
+// findTransportLegs fetches the transport legs of the itinerary, connecting them to its destinations
+func (i *Itinerary) findTransportLegs() error {
+	legs, err := InitItineraryTransportLeg().FindByItineraryId(i.ID)
+	if err != nil {
+		log.Errorf("Error fetching transport legs for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	ConnectTransportLegs(i.TravelDestinations, legs)
+	i.TransportLegs = legs
+	return nil
+}
+
+// findAccommodations fetches the accommodations of the itinerary, connecting them to its destinations
+func (i *Itinerary) findAccommodations() error {
+	accommodations, err := InitDestinationAccommodation().FindByItineraryId(i.ID)
+	if err != nil {
+		log.Errorf("Error fetching accommodations for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	ConnectAccommodations(i.TravelDestinations, accommodations)
+	i.Accommodations = accommodations
+	return nil
+}
+
 func (i *Itinerary) defaultFindLightweightById(id int64) (*Itinerary, error) {
-	query := `SELECT id, owner_id
+	query := `SELECT id, owner_id, version
 	FROM itineraries WHERE id = ?`
 	row := db.DB.QueryRow(query, id)
 
 	itinerary := &Itinerary{}
 
-	err := row.Scan(&itinerary.ID, &itinerary.OwnerID)
+	err := row.Scan(&itinerary.ID, &itinerary.OwnerID, &itinerary.Version)
 	if err != nil {
 		log.Errorf("Error fetching lightweight itinerary by ID %d: %v", id, err)
 		return nil, err
@@ -101,7 +199,7 @@ func (i *Itinerary) defaultFindLightweightById(id int64) (*Itinerary, error) {
 }
 
 func (i *Itinerary) defaultFindByOwnerId(ownerId int64) ([]*Itinerary, error) {
-	query := `SELECT id, title, description, notes, owner_id, creation_date, update_date
+	query := `SELECT id, title, description, notes, owner_id, creation_date, update_date, version
 	FROM itineraries WHERE owner_id = ?`
 
 	rows, err := db.DB.Query(query, ownerId)
@@ -114,7 +212,8 @@ func (i *Itinerary) defaultFindByOwnerId(ownerId int64) ([]*Itinerary, error) {
 
 	for rows.Next() {
 		var itinerary Itinerary
-		err := rows.Scan(&itinerary.ID, &itinerary.Title, &itinerary.Description, &itinerary.Notes, &itinerary.OwnerID, &itinerary.CreationDate, &itinerary.UpdateDate)
+		err := rows.Scan(&itinerary.ID, &itinerary.Title, &itinerary.Description, &itinerary.Notes, &itinerary.OwnerID, &itinerary.CreationDate, &itinerary.UpdateDate,
+			&itinerary.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -130,13 +229,172 @@ func (i *Itinerary) defaultFindByOwnerId(ownerId int64) ([]*Itinerary, error) {
 
 		itinerary.TravelDestinations = travelDestinations
 
+		err = itinerary.findTransportLegs()
+		if err != nil {
+			return nil, err
+		}
+
+		err = itinerary.findAccommodations()
+		if err != nil {
+			return nil, err
+		}
+
 		itineraries = append(itineraries, &itinerary)
 	}
 
 	return itineraries, nil
 }
 
+// itineraryFilterConditions builds the WHERE conditions of the filter, except the cursor
+func itineraryFilterConditions(filter ItineraryFilter) ([]string, []any) {
+	conditions := []string{}
+	args := []any{}
+	if filter.OwnerID > 0 {
+		conditions = append(conditions, "i.owner_id = ?")
+		args = append(args, filter.OwnerID)
+	}
+
+	destinationConditions := []string{}
+	// Countries of the gazetteer, and their cities, also match by their normalized names, so "USA" finds the destinations in United
+	// States
+	var city *utils.GazetteerCity
+	country := utils.FindCountry(filter.Country)
+	if country != nil {
+		city = utils.FindCity(country.Code, filter.City)
+		destinationConditions = append(destinationConditions, "(d.country_code = ? OR d.country = ? COLLATE NOCASE)")
+		args = append(args, country.Code, filter.Country)
+	} else if filter.Country != "" {
+		destinationConditions = append(destinationConditions, "d.country = ? COLLATE NOCASE")
+		args = append(args, filter.Country)
+	}
+	if filter.City != "" {
+		if city != nil && !strings.EqualFold(city.Name, filter.City) {
+			destinationConditions = append(destinationConditions, "(d.city = ? COLLATE NOCASE OR d.city = ? COLLATE NOCASE)")
+			args = append(args, city.Name, filter.City)
+		} else {
+			destinationConditions = append(destinationConditions, "d.city = ? COLLATE NOCASE")
+			args = append(args, filter.City)
+		}
+	}
+	if filter.TravelFrom != nil {
+		destinationConditions = append(destinationConditions, "d.departure_date >= ?")
+		args = append(args, *filter.TravelFrom)
+	}
+	if filter.TravelTo != nil {
+		destinationConditions = append(destinationConditions, "d.arrival_date <= ?")
+		args = append(args, *filter.TravelTo)
+	}
+	if len(destinationConditions) > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM itinerary_travel_destinations d WHERE d.itinerary_id = i.id AND "+
+			strings.Join(destinationConditions, " AND ")+")")
+	}
+
+	if filter.Title != "" {
+		conditions = append(conditions, `i.title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLikePattern(filter.Title)+"%")
+	}
+
+	return conditions, args
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (i *Itinerary) defaultFind(filter ItineraryFilter) ([]*Itinerary, error) {
+	sortExpression, ok := itinerarySortExpressions[filter.SortBy]
+	if !ok {
+		sortExpression = itinerarySortExpressions[ItinerarySortCreationDate]
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	conditions, args := itineraryFilterConditions(filter)
+	if filter.AfterID > 0 {
+		// Keyset pagination: continue after the sort value and ID of the last itinerary of the previous page
+		conditions = append(conditions, "("+sortExpression+", i.id) "+comparison+" ((SELECT "+sortExpression+" FROM itineraries i WHERE i.id = ?), ?)")
+		args = append(args, filter.AfterID, filter.AfterID)
+	}
+
+	query := `SELECT i.id, i.title, i.description, i.notes, i.owner_id, i.creation_date, i.update_date, i.version FROM itineraries i`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY ` + sortExpression + ` ` + direction + `, i.id ` + direction
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Errorf("Error querying itineraries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	itineraries := []*Itinerary{}
+	for rows.Next() {
+		itinerary := &Itinerary{}
+		err := rows.Scan(&itinerary.ID, &itinerary.Title, &itinerary.Description, &itinerary.Notes, &itinerary.OwnerID, &itinerary.CreationDate, &itinerary.UpdateDate,
+			&itinerary.Version)
+		if err != nil {
+			log.Errorf("Error scanning itinerary row: %v", err)
+			return nil, err
+		}
+		itineraries = append(itineraries, itinerary)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary rows: %v", err)
+		return nil, err
+	}
+
+	// Destinations are fetched once the rows are closed, so the page does not hold two connections
+	rows.Close()
+	for _, itinerary := range itineraries {
+		travelDestinations, err := InitItineraryTravelDestination().FindByItineraryId(itinerary.ID)
+		if err != nil {
+			log.Errorf("Error fetching travel destinations for itinerary ID %d: %v", itinerary.ID, err)
+			return nil, err
+		}
+		itinerary.TravelDestinations = travelDestinations
+	}
+
+	return itineraries, nil
+}
+
+func (i *Itinerary) defaultCount(filter ItineraryFilter) (int64, error) {
+	conditions, args := itineraryFilterConditions(filter)
+
+	query := `SELECT COUNT(*) FROM itineraries i`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	var count int64
+	err := db.DB.QueryRow(query, args...).Scan(&count)
+	if err != nil {
+		log.Errorf("Error counting itineraries: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (i *Itinerary) defaultCreate() error {
+	return i.create(nil)
+}
+
+// defaultCreateClone creates the itinerary as a copy of another one, which already has its content, copying as well the overrides of
+// the traveller preferences of the source itinerary
+func (i *Itinerary) defaultCreateClone(sourceId int64) error {
+	return i.create(&sourceId)
+}
+
+func (i *Itinerary) create(sourceId *int64) error {
 	tx, err := db.DB.Begin()
 	if err != nil {
 		log.Errorf("Error starting transaction for itinerary creation: %v", err)
@@ -172,6 +430,7 @@ func (i *Itinerary) defaultCreate() error {
 	}
 
 	i.ID = itineraryId
+	i.Version = 1
 
 	for idx := range i.TravelDestinations {
 		i.TravelDestinations[idx].ItineraryID = itineraryId
@@ -182,10 +441,49 @@ func (i *Itinerary) defaultCreate() error {
 		}
 	}
 
+	if sourceId != nil {
+		preferences := InitTravellerPreferences()
+		preferences.ItineraryID = sourceId
+		err = preferences.CopyToItineraryTx(itineraryId, tx)
+		if err != nil {
+			log.Errorf("Error copying traveller preferences of itinerary ID %d to itinerary ID %d: %v", *sourceId, itineraryId, err)
+			return err
+		}
+	}
+
+	// The first revision is authored by the owner
+	err = NewItineraryRevision(i, i.OwnerID, nil).CreateTx(tx)
+	if err != nil {
+		log.Errorf("Error creating first revision for itinerary ID %d: %v", itineraryId, err)
+		return err
+	}
+
 	return nil
 }
 
-func (i *Itinerary) defaultUpdate() error {
+// defaultUpdate saves the itinerary and a new revision with its content, authored by the given user. The update only applies if the
+// itinerary is still in its version, returning ErrItineraryVersionConflict otherwise, and increments the version
+func (i *Itinerary) defaultUpdate(authorId int64) error {
+	return i.update(authorId, nil)
+}
+
+// defaultRestore replaces the content of the itinerary with the one of an earlier revision, saving it as a new revision. The itinerary
+// needs its ID, owner and version
+func (i *Itinerary) defaultRestore(revision *ItineraryRevision, authorId int64) error {
+	i.Title = revision.Title
+	i.Description = revision.Description
+	i.Notes = revision.Notes
+
+	i.TravelDestinations = []*ItineraryTravelDestination{}
+	for _, destination := range revision.TravelDestinations {
+		i.TravelDestinations = append(i.TravelDestinations,
+			NewItineraryTravelDestination(destination.Country, destination.City, destination.ArrivalDate, destination.DepartureDate))
+	}
+
+	return i.update(authorId, &revision.Number)
+}
+
+func (i *Itinerary) update(authorId int64, restoredFrom *int64) error {
 	tx, err := db.DB.Begin()
 	if err != nil {
 		log.Errorf("Error starting transaction for itinerary update: %v", err)
@@ -198,7 +496,8 @@ func (i *Itinerary) defaultUpdate() error {
 		return err
 	}
 
-	query := `UPDATE itineraries SET title = ?, description = ?, notes = ?, update_date = ? WHERE id = ?`
+	// The version is checked and incremented in the same statement, so only one of two concurrent writers of a version succeeds
+	query := `UPDATE itineraries SET title = ?, description = ?, notes = ?, update_date = ?, version = version + 1 WHERE id = ? AND version = ?`
 	stmt, err := tx.Prepare(query)
 	if err != nil {
 		log.Errorf("Error preparing update for itinerary: %v", err)
@@ -206,12 +505,17 @@ func (i *Itinerary) defaultUpdate() error {
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(i.Title, i.Description, i.Notes, time.Now(), i.ID)
+	result, err := stmt.Exec(i.Title, i.Description, i.Notes, time.Now(), i.ID, i.Version)
 	if err != nil {
 		log.Errorf("Error executing update for itinerary ID %d: %v", i.ID, err)
 		return err
 	}
 
+	err = checkItineraryVersion(result, i.ID, i.Version)
+	if err != nil {
+		return err
+	}
+
 	destination := InitItineraryTravelDestination()
 
 	// Clear existing travel destinations for this itinerary
@@ -231,9 +535,97 @@ func (i *Itinerary) defaultUpdate() error {
 		}
 	}
 
+	err = NewItineraryRevision(i, authorId, restoredFrom).CreateTx(tx)
+	if err != nil {
+		log.Errorf("Error creating revision for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	i.Version++
+	return nil
+}
+
+// defaultAddDestination adds a destination to the itinerary. Like the other changes of a single destination, it only applies if the
+// itinerary is still in its version, increments it and saves a revision with the destinations of the itinerary, which must already
+// include the change
+func (i *Itinerary) defaultAddDestination(destination *ItineraryTravelDestination, authorId int64) error {
+	destination.ItineraryID = i.ID
+	return i.changeDestination(authorId, destination.Create)
+}
+
+// defaultUpdateDestination saves a destination of the itinerary. Returns sql.ErrNoRows if the itinerary has no such destination
+func (i *Itinerary) defaultUpdateDestination(destination *ItineraryTravelDestination, authorId int64) error {
+	destination.ItineraryID = i.ID
+	return i.changeDestination(authorId, destination.Update)
+}
+
+// defaultDeleteDestination deletes a destination of the itinerary. Returns sql.ErrNoRows if the itinerary has no such destination
+func (i *Itinerary) defaultDeleteDestination(destination *ItineraryTravelDestination, authorId int64) error {
+	destination.ItineraryID = i.ID
+	return i.changeDestination(authorId, destination.Delete)
+}
+
+func (i *Itinerary) changeDestination(authorId int64, change func(tx *sql.Tx) error) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for itinerary destination change: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `UPDATE itineraries SET update_date = ?, version = version + 1 WHERE id = ? AND version = ?`
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing version update for itinerary: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(time.Now(), i.ID, i.Version)
+	if err != nil {
+		log.Errorf("Error executing version update for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	err = checkItineraryVersion(result, i.ID, i.Version)
+	if err != nil {
+		return err
+	}
+
+	err = change(tx)
+	if err != nil {
+		log.Errorf("Error changing a travel destination of itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	err = NewItineraryRevision(i, authorId, nil).CreateTx(tx)
+	if err != nil {
+		log.Errorf("Error creating revision for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	i.Version++
 	return nil
 }
 
+// checkItineraryVersion returns ErrItineraryVersionConflict if a statement conditioned on the version of an itinerary changed no row
+func checkItineraryVersion(result sql.Result, id int64, version int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting affected rows for itinerary ID %d: %v", id, err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Warnf("Itinerary ID %d is no longer in version %d", id, version)
+		return ErrItineraryVersionConflict
+	}
+	return nil
+}
+
+// defaultDelete deletes the itinerary with its destinations, shares, share links, search document, revisions, traveller preferences,
+// budget, cost estimates, transport legs, accommodations and plan, marking its jobs for full future deletion. The itinerary needs its
+// ID and version
 func (i *Itinerary) defaultDelete() error {
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -263,8 +655,79 @@ func (i *Itinerary) defaultDelete() error {
 		return err
 	}
 
-	// Delete itinerary
-	query := `DELETE FROM itineraries WHERE id = ?`
+	// Revoke the access of the users the itinerary was shared with
+	share := InitItineraryShare()
+	err = share.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting shares for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	shareLink := InitShareLink()
+	err = shareLink.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting share links for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	searchDocument := InitItinerarySearchDocument()
+	err = searchDocument.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting search document for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	revision := InitItineraryRevision()
+	err = revision.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting revisions for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	preferences := InitTravellerPreferences()
+	err = preferences.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting traveller preferences for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	budget := InitItineraryBudget()
+	err = budget.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting budget for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	costEstimate := InitDestinationCostEstimate()
+	err = costEstimate.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting cost estimates for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	transportLeg := InitItineraryTransportLeg()
+	err = transportLeg.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting transport legs for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	accommodation := InitDestinationAccommodation()
+	err = accommodation.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting accommodations for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	activity := InitItineraryActivity()
+	err = activity.DeleteByItineraryIdTx(i.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting activities for itinerary ID %d: %v", i.ID, err)
+		return err
+	}
+
+	// Delete itinerary, unless it changed since its version was read. The whole deletion is rolled back then
+	query := `DELETE FROM itineraries WHERE id = ? AND version = ?`
 	stmt, err := tx.Prepare(query)
 	if err != nil {
 		log.Errorf("Error preparing delete for itinerary ID %d: %v", i.ID, err)
@@ -272,11 +735,117 @@ func (i *Itinerary) defaultDelete() error {
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(i.ID)
+	result, err := stmt.Exec(i.ID, i.Version)
 	if err != nil {
 		log.Errorf("Error executing delete for itinerary ID %d: %v", i.ID, err)
 		return err
 	}
 
+	err = checkItineraryVersion(result, i.ID, i.Version)
+	return err
+}
+
+// defaultDeleteByOwnerIdTx deletes all the itineraries of a user with their destinations, shares, share links, search documents,
+// revisions, traveller preferences, budgets, cost estimates, transport legs, accommodations and plans, marking their jobs for full
+// future deletion
+func (i *Itinerary) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	job := InitItineraryFileJob()
+	err := job.SoftDeleteJobsByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error marking jobs for deletion for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	destination := InitItineraryTravelDestination()
+	err = destination.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting travel destinations for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	share := InitItineraryShare()
+	err = share.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary shares for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	shareLink := InitShareLink()
+	err = shareLink.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting share links for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	searchDocument := InitItinerarySearchDocument()
+	err = searchDocument.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary search documents for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	revision := InitItineraryRevision()
+	err = revision.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary revisions for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	preferences := InitTravellerPreferences()
+	err = preferences.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary traveller preferences for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	budget := InitItineraryBudget()
+	err = budget.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary budgets for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	costEstimate := InitDestinationCostEstimate()
+	err = costEstimate.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting destination cost estimates for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	transportLeg := InitItineraryTransportLeg()
+	err = transportLeg.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary transport legs for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	accommodation := InitDestinationAccommodation()
+	err = accommodation.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting destination accommodations for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	activity := InitItineraryActivity()
+	err = activity.DeleteByOwnerIdTx(ownerId, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary activities for owner ID %d: %v", ownerId, err)
+		return err
+	}
+
+	query := `DELETE FROM itineraries WHERE owner_id = ?`
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itineraries of owner ID %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for itineraries of owner ID %d: %v", ownerId, err)
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,316 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Time slots of the activities of a day
+const (
+	TimeSlotMorning   = "morning"
+	TimeSlotAfternoon = "afternoon"
+	TimeSlotEvening   = "evening"
+)
+
+var TimeSlots = []string{TimeSlotMorning, TimeSlotAfternoon, TimeSlotEvening}
+
+// ItineraryActivity is an activity of the day-by-day plan of an itinerary. Date is the day of the activity, at midnight UTC, and
+// Position its order among the activities of the day
+type ItineraryActivity struct {
+	ID           int64      `json:"id" example:"1"`
+	ItineraryID  int64      `json:"itineraryId" example:"1"`
+	Date         time.Time  `json:"date" example:"2024-07-01T00:00:00Z"`
+	TimeSlot     string     `json:"timeSlot" example:"morning"`
+	Title        string     `json:"title" example:"Visit the Prado Museum"`
+	Location     string     `json:"location,omitempty" example:"Calle de Ruiz de Alarcón 23, Madrid"`
+	Notes        string     `json:"notes,omitempty" example:"Free entry from 6 pm"`
+	Position     int        `json:"position" example:"1"`
+	CreationDate *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	UpdateDate   *time.Time `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindByItineraryId     func(itineraryId int64) ([]*ItineraryActivity, error)          `json:"-"`
+	Create                func() error                                                   `json:"-"`
+	Update                func() error                                                   `json:"-"`
+	Delete                func() error                                                   `json:"-"`
+	UpdatePositions       func(itineraryId int64, activityIds []int64) error             `json:"-"`
+	ReplaceByItineraryId  func(itineraryId int64, activities []*ItineraryActivity) error `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                      `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                          `json:"-"`
+}
+
+// ItineraryDay is a day of the plan of an itinerary with its activities in order
+type ItineraryDay struct {
+	Date       time.Time            `json:"date" example:"2024-07-01T00:00:00Z"`
+	Activities []*ItineraryActivity `json:"activities"`
+}
+
+var InitItineraryActivity = func() *ItineraryActivity {
+	return InitItineraryActivityFunctions(&ItineraryActivity{})
+}
+
+var InitItineraryActivityFunctions = func(activity *ItineraryActivity) *ItineraryActivity {
+	// Set default SQL implementations for FindByItineraryId, Create, Update, Delete, UpdatePositions, ReplaceByItineraryId,
+	// DeleteByItineraryIdTx and DeleteByOwnerIdTx. In the future there could be implementations for other NoSQL DB systems like MongoDB
+	activity.FindByItineraryId = activity.defaultFindByItineraryId
+	activity.Create = activity.defaultCreate
+	activity.Update = activity.defaultUpdate
+	activity.Delete = activity.defaultDelete
+	activity.UpdatePositions = activity.defaultUpdatePositions
+	activity.ReplaceByItineraryId = activity.defaultReplaceByItineraryId
+	activity.DeleteByItineraryIdTx = activity.defaultDeleteByItineraryIdTx
+	activity.DeleteByOwnerIdTx = activity.defaultDeleteByOwnerIdTx
+
+	return activity
+}
+
+// GroupActivitiesByDay groups the activities, sorted by date and position, into the days of the plan
+func GroupActivitiesByDay(activities []*ItineraryActivity) []*ItineraryDay {
+	days := []*ItineraryDay{}
+	for _, activity := range activities {
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(activity.Date) {
+			days = append(days, &ItineraryDay{Date: activity.Date, Activities: []*ItineraryActivity{}})
+		}
+		days[len(days)-1].Activities = append(days[len(days)-1].Activities, activity)
+	}
+	return days
+}
+
+func (a *ItineraryActivity) defaultFindByItineraryId(itineraryId int64) ([]*ItineraryActivity, error) {
+	query := `SELECT id, itinerary_id, date, time_slot, title, location, notes, position, creation_date, update_date
+	FROM itinerary_activities WHERE itinerary_id = ? ORDER BY date, position, id`
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error fetching activities of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []*ItineraryActivity{}
+	for rows.Next() {
+		activity := &ItineraryActivity{}
+		err = rows.Scan(&activity.ID, &activity.ItineraryID, &activity.Date, &activity.TimeSlot, &activity.Title, &activity.Location,
+			&activity.Notes, &activity.Position, &activity.CreationDate, &activity.UpdateDate)
+		if err != nil {
+			log.Errorf("Error scanning activity of itinerary %d: %v", itineraryId, err)
+			return nil, err
+		}
+		activity.Date = activity.Date.UTC()
+		activities = append(activities, activity)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+func (a *ItineraryActivity) defaultCreate() error {
+	query := `INSERT INTO itinerary_activities(itinerary_id, date, time_slot, title, location, notes, position, creation_date, update_date)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for itinerary activity: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(a.ItineraryID, a.Date, a.TimeSlot, a.Title, a.Location, a.Notes, a.Position, now, now)
+	if err != nil {
+		log.Errorf("Error executing insert for activity of itinerary %d: %v", a.ItineraryID, err)
+		return err
+	}
+
+	a.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for itinerary activity: %v", err)
+		return err
+	}
+	a.CreationDate = &now
+	a.UpdateDate = &now
+
+	return nil
+}
+
+// defaultUpdate saves the activity, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has no such
+// activity
+func (a *ItineraryActivity) defaultUpdate() error {
+	query := `UPDATE itinerary_activities SET date = ?, time_slot = ?, title = ?, location = ?, notes = ?, position = ?, update_date = ?
+	WHERE id = ? AND itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for itinerary activity: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(a.Date, a.TimeSlot, a.Title, a.Location, a.Notes, a.Position, now, a.ID, a.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing update for activity %d of itinerary %d: %v", a.ID, a.ItineraryID, err)
+		return err
+	}
+
+	err = checkActivityFound(result, a.ID, a.ItineraryID)
+	if err != nil {
+		return err
+	}
+	a.UpdateDate = &now
+
+	return nil
+}
+
+// defaultDelete deletes the activity, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has no
+// such activity
+func (a *ItineraryActivity) defaultDelete() error {
+	query := `DELETE FROM itinerary_activities WHERE id = ? AND itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary activity: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(a.ID, a.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing delete for activity %d of itinerary %d: %v", a.ID, a.ItineraryID, err)
+		return err
+	}
+
+	return checkActivityFound(result, a.ID, a.ItineraryID)
+}
+
+func checkActivityFound(result sql.Result, activityId int64, itineraryId int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting affected rows for itinerary activity: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Errorf("Activity %d not found in itinerary %d", activityId, itineraryId)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// defaultUpdatePositions sets the positions of the activities of the itinerary in the order of their IDs, starting from 1, in a
+// transaction. Returns sql.ErrNoRows if the itinerary has no such activity
+func (a *ItineraryActivity) defaultUpdatePositions(itineraryId int64, activityIds []int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction to reorder activities of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE itinerary_activities SET position = ?, update_date = ? WHERE id = ? AND itinerary_id = ?`)
+	if err != nil {
+		log.Errorf("Error preparing reorder of activities of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for idx, activityId := range activityIds {
+		result, err := stmt.Exec(idx+1, now, activityId, itineraryId)
+		if err != nil {
+			log.Errorf("Error executing reorder of activity %d of itinerary %d: %v", activityId, itineraryId, err)
+			return err
+		}
+		err = checkActivityFound(result, activityId, itineraryId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// defaultReplaceByItineraryId replaces the whole plan of the itinerary with the activities in a transaction, setting their IDs
+func (a *ItineraryActivity) defaultReplaceByItineraryId(itineraryId int64, activities []*ItineraryActivity) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction to replace the plan of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = a.defaultDeleteByItineraryIdTx(itineraryId, tx)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO itinerary_activities(itinerary_id, date, time_slot, title, location, notes, position,
+	creation_date, update_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		log.Errorf("Error preparing insert for the plan of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, activity := range activities {
+		result, err := stmt.Exec(itineraryId, activity.Date, activity.TimeSlot, activity.Title, activity.Location, activity.Notes,
+			activity.Position, now, now)
+		if err != nil {
+			log.Errorf("Error executing insert for the plan of itinerary %d: %v", itineraryId, err)
+			return err
+		}
+		activity.ID, err = result.LastInsertId()
+		if err != nil {
+			log.Errorf("Error getting last insert ID for itinerary activity: %v", err)
+			return err
+		}
+		activity.ItineraryID = itineraryId
+		activity.CreationDate = &now
+		activity.UpdateDate = &now
+	}
+
+	return tx.Commit()
+}
+
+func (a *ItineraryActivity) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_activities WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for activities of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for activities of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the activities of all the itineraries of an owner
+func (a *ItineraryActivity) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_activities WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for activities of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for activities of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItineraryActivity_FindByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	rows := sqlmock.NewRows([]string{"id", "itinerary_id", "date", "time_slot", "title", "location", "notes", "position", "creation_date", "update_date"}).
+		AddRow(1, 3, time.Date(2024, time.July, 1, 2, 0, 0, 0, madrid), "morning", "Visit the Prado Museum", "", "", 1, time.Now(), time.Now())
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_activities WHERE itinerary_id = \\? ORDER BY date, position, id").
+		WithArgs(int64(3)).
+		WillReturnRows(rows)
+
+	activities, err := InitItineraryActivity().FindByItineraryId(3)
+	assert.NoError(t, err)
+	assert.Len(t, activities, 1)
+	assert.Equal(t, time.UTC, activities[0].Date.Location())
+	assert.Equal(t, "Visit the Prado Museum", activities[0].Title)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryActivity_UpdatePositions_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE itinerary_activities SET position = \\?, update_date = \\? WHERE id = \\? AND itinerary_id = \\?")
+	mock.ExpectExec("UPDATE itinerary_activities").
+		WithArgs(1, sqlmock.AnyArg(), int64(2), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE itinerary_activities").
+		WithArgs(2, sqlmock.AnyArg(), int64(9), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = InitItineraryActivity().UpdatePositions(3, []int64{2, 9})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryActivity_ReplaceByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	date := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM itinerary_activities WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectPrepare("INSERT INTO itinerary_activities")
+	mock.ExpectExec("INSERT INTO itinerary_activities").
+		WithArgs(int64(3), date, "morning", "Visit the Prado Museum", "", "", 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	activity := &ItineraryActivity{Date: date, TimeSlot: TimeSlotMorning, Title: "Visit the Prado Museum", Position: 1}
+	err = InitItineraryActivity().ReplaceByItineraryId(3, []*ItineraryActivity{activity})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), activity.ID)
+	assert.Equal(t, int64(3), activity.ItineraryID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupActivitiesByDay(t *testing.T) {
+	first := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 1)
+	activities := []*ItineraryActivity{{ID: 1, Date: first}, {ID: 2, Date: first}, {ID: 3, Date: second}}
+
+	days := GroupActivitiesByDay(activities)
+	assert.Len(t, days, 2)
+	assert.Equal(t, first, days[0].Date)
+	assert.Equal(t, activities[:2], days[0].Activities)
+	assert.Equal(t, []*ItineraryActivity{activities[2]}, days[1].Activities)
+	assert.Empty(t, GroupActivitiesByDay([]*ItineraryActivity{}))
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// ItineraryBudget is the money the travellers plan to spend on a trip, in its home currency (an ISO 4217 code)
+type ItineraryBudget struct {
+	ItineraryID  int64      `json:"itineraryId" example:"1"`
+	Amount       Amount     `json:"amount" swaggertype:"number" example:"3000"`
+	Currency     string     `json:"currency" example:"EUR"`
+	CreationDate *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	UpdateDate   *time.Time `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindByItineraryId     func(itineraryId int64) (*ItineraryBudget, error) `json:"-"`
+	Save                  func() error                                      `json:"-"`
+	Delete                func() error                                      `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error         `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error             `json:"-"`
+}
+
+// CostBreakdown is an amount of money split by category
+type CostBreakdown struct {
+	Lodging    Amount `json:"lodging" swaggertype:"number" example:"480"`
+	Food       Amount `json:"food" swaggertype:"number" example:"240"`
+	Transport  Amount `json:"transport" swaggertype:"number" example:"60"`
+	Activities Amount `json:"activities" swaggertype:"number" example:"160"`
+	Total      Amount `json:"total" swaggertype:"number" example:"940"`
+}
+
+// DestinationBudget is the estimated cost of the stay in a destination, in the currency of the summary
+type DestinationBudget struct {
+	DestinationID int64                    `json:"destinationId" example:"1"`
+	Country       string                   `json:"country" example:"Spain"`
+	City          string                   `json:"city" example:"Madrid"`
+	Days          int                      `json:"days" example:"4"`
+	Estimate      *DestinationCostEstimate `json:"estimate,omitempty"`
+	EstimatedCost *CostBreakdown           `json:"estimatedCost,omitempty"`
+}
+
+// BudgetSummary compares the budget of an itinerary with the estimated cost of its destinations, all converted to the currency of the
+// summary
+type BudgetSummary struct {
+	ItineraryID                 int64                `json:"itineraryId" example:"1"`
+	Currency                    string               `json:"currency" example:"EUR"`
+	Budget                      *ItineraryBudget     `json:"budget,omitempty"`
+	BudgetAmount                *Amount              `json:"budgetAmount,omitempty" swaggertype:"number" example:"3000"`
+	EstimatedCost               CostBreakdown        `json:"estimatedCost"`
+	Remaining                   *Amount              `json:"remaining,omitempty" swaggertype:"number" example:"2060"`
+	OverBudget                  bool                 `json:"overBudget" example:"false"`
+	DestinationsWithoutEstimate int                  `json:"destinationsWithoutEstimate" example:"0"`
+	Destinations                []*DestinationBudget `json:"destinations"`
+}
+
+var InitItineraryBudget = func() *ItineraryBudget {
+	return InitItineraryBudgetFunctions(&ItineraryBudget{})
+}
+
+var InitItineraryBudgetFunctions = func(budget *ItineraryBudget) *ItineraryBudget {
+	// Set default SQL implementations for FindByItineraryId, Save, Delete, DeleteByItineraryIdTx and DeleteByOwnerIdTx. In the future
+	// there could be implementations for other NoSQL DB systems like MongoDB
+	budget.FindByItineraryId = budget.defaultFindByItineraryId
+	budget.Save = budget.defaultSave
+	budget.Delete = budget.defaultDelete
+	budget.DeleteByItineraryIdTx = budget.defaultDeleteByItineraryIdTx
+	budget.DeleteByOwnerIdTx = budget.defaultDeleteByOwnerIdTx
+
+	return budget
+}
+
+func (b *ItineraryBudget) defaultFindByItineraryId(itineraryId int64) (*ItineraryBudget, error) {
+	query := `SELECT itinerary_id, amount_cents, currency, creation_date, update_date FROM itinerary_budgets WHERE itinerary_id = ?`
+
+	budget := &ItineraryBudget{}
+	err := db.DB.QueryRow(query, itineraryId).Scan(&budget.ItineraryID, &budget.Amount, &budget.Currency, &budget.CreationDate,
+		&budget.UpdateDate)
+	if err != nil {
+		log.Errorf("Error fetching budget of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+// defaultSave saves the budget of the itinerary, replacing the previous one
+func (b *ItineraryBudget) defaultSave() error {
+	query := `INSERT INTO itinerary_budgets(itinerary_id, amount_cents, currency, creation_date, update_date) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (itinerary_id) DO UPDATE SET amount_cents = excluded.amount_cents, currency = excluded.currency, update_date = excluded.update_date`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing upsert for itinerary budget: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(b.ItineraryID, b.Amount, b.Currency, now, now)
+	if err != nil {
+		log.Errorf("Error executing upsert for budget of itinerary %d: %v", b.ItineraryID, err)
+		return err
+	}
+
+	b.UpdateDate = &now
+
+	return nil
+}
+
+// defaultDelete deletes the budget of the itinerary. Returns sql.ErrNoRows if the itinerary has none
+func (b *ItineraryBudget) defaultDelete() error {
+	query := `DELETE FROM itinerary_budgets WHERE itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary budget: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(b.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing delete for budget of itinerary %d: %v", b.ItineraryID, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (b *ItineraryBudget) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_budgets WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for budget of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for budget of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the budgets of all the itineraries of an owner
+func (b *ItineraryBudget) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_budgets WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for budgets of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for budgets of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItineraryBudget_FindByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT itinerary_id, amount_cents, currency, creation_date, update_date FROM itinerary_budgets WHERE itinerary_id = \\?").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"itinerary_id", "amount_cents", "currency", "creation_date", "update_date"}).
+			AddRow(3, 250050, "USD", now, now))
+
+	budget, err := InitItineraryBudget().FindByItineraryId(3)
+	assert.NoError(t, err)
+	assert.Equal(t, AmountOf(2500.5), budget.Amount)
+	assert.Equal(t, "USD", budget.Currency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryBudget_Save_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("INSERT INTO itinerary_budgets\\(itinerary_id, amount_cents, currency, creation_date, update_date\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)\\s+"+
+		"ON CONFLICT \\(itinerary_id\\) DO UPDATE").
+		ExpectExec().
+		WithArgs(int64(3), int64(250000), "EUR", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	budget := InitItineraryBudget()
+	budget.ItineraryID = 3
+	budget.Amount = AmountOf(2500)
+	budget.Currency = "EUR"
+	assert.NoError(t, budget.Save())
+	assert.NotNil(t, budget.UpdateDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryBudget_Delete_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("DELETE FROM itinerary_budgets WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	budget := InitItineraryBudget()
+	budget.ItineraryID = 3
+	assert.ErrorIs(t, budget.Delete(), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -11,6 +11,17 @@ import (
 	"example.com/travel-advisor/db"
 )
 
+// Sources of the content of an itinerary file. The llm_plan source is the one of the jobs that generate the plan of the itinerary with
+// the LLM before rendering the file from it, which are only started by the plan generation
+const (
+	ItineraryFileJobSourceLlm     = "llm"
+	ItineraryFileJobSourcePlan    = "plan"
+	ItineraryFileJobSourceLlmPlan = "llm_plan"
+)
+
+// ItineraryFileJobSources are the sources the itinerary file jobs can be started with
+var ItineraryFileJobSources = []string{ItineraryFileJobSourceLlm, ItineraryFileJobSourcePlan}
+
 type ItineraryFileJob struct {
 	ID                int64  `json:"id" example:"1"`
 	Status            string `json:"status" example:"completed"`
@@ -24,6 +35,21 @@ type ItineraryFileJob struct {
 	FileManager string    `json:"fileManager,omitempty" example:"local"`                                // Optional, used for file management
 	ItineraryID int64     `json:"itineraryId" example:"123"`                                            // ItineraryID is the ID of the itinerary associated with this job
 	AsyncTaskID string    `json:"asyncTaskId,omitempty" example:"e2467dd0-db8a-49db-a5cb-9474f8e63933"` // Optional, async task ID from task manager
+	// ItineraryRevision is the number of the itinerary revision the job was generated from. Jobs created before the revision history have none
+	ItineraryRevision *int64 `json:"itineraryRevision,omitempty" example:"3"`
+	// PromptTemplateID is the ID of the version of the prompt template the job was generated with. Jobs generated with the built-in prompt have none
+	PromptTemplateID *int64 `json:"promptTemplateId,omitempty" example:"2"`
+	// Language is the BCP 47 tag of the language the file is written in. Jobs without a target language have none
+	Language string `json:"language,omitempty" example:"es"`
+	// Source is where the content of the file comes from: "llm" if it was generated by the LLM, "plan" if it was rendered from the
+	// day-by-day plan of the itinerary, or "llm_plan" if it was rendered from the plan the job generated with the LLM
+	Source string `json:"source" example:"llm"`
+	// BaseJobID is the ID of the job whose file was regenerated for a part of the trip, which is kept for comparison. Jobs that
+	// generated the whole trip have none
+	BaseJobID *int64 `json:"baseJobId,omitempty" example:"4"`
+	// ScopeStartDate and ScopeEndDate are the first and last days of the trip regenerated from the file of the base job, at midnight UTC
+	ScopeStartDate *time.Time `json:"scopeStartDate,omitempty" example:"2024-07-03T00:00:00Z"`
+	ScopeEndDate   *time.Time `json:"scopeEndDate,omitempty" example:"2024-07-04T00:00:00Z"`
 
 	FindAliveById                     func(id int64) (*ItineraryFileJob, error)            `json:"-"`
 	FindAliveLightweightById          func(id int64) (*ItineraryFileJob, error)            `json:"-"`
@@ -40,6 +66,7 @@ type ItineraryFileJob struct {
 	DeleteJob                         func() error                                         `json:"-"`
 	SoftDeleteJob                     func() error                                         `json:"-"`
 	SoftDeleteJobsByItineraryIdTx     func(itineraryId int64, tx *sql.Tx) error            `json:"-"`
+	SoftDeleteJobsByOwnerIdTx         func(ownerId int64, tx *sql.Tx) error                `json:"-"`
 }
 
 var InitItineraryFileJob = func() *ItineraryFileJob {
@@ -64,6 +91,7 @@ var InitItineraryFileJobFunctions = func(job *ItineraryFileJob) *ItineraryFileJo
 	job.DeleteJob = job.defaultDeleteJob
 	job.SoftDeleteJob = job.defaultSoftDeleteJob
 	job.SoftDeleteJobsByItineraryIdTx = job.defaultSoftDeleteJobsByItineraryId
+	job.SoftDeleteJobsByOwnerIdTx = job.defaultSoftDeleteJobsByOwnerId
 	return job
 }
 
@@ -77,7 +105,7 @@ var NewItineraryFileJob = func(itineraryId int64) *ItineraryFileJob {
 }
 
 func (ifj *ItineraryFileJob) defaultFindAliveById(id int64) (*ItineraryFileJob, error) {
-	query := `SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	query := `SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE id = ? AND status != 'deleted'`
 	row := db.DB.QueryRow(query, id)
 
@@ -89,7 +117,9 @@ func (ifj *ItineraryFileJob) defaultFindAliveById(id int64) (*ItineraryFileJob,
 	var filePath sql.NullString
 	var fileManager sql.NullString
 	var asyncTaskId sql.NullString
-	err := row.Scan(&itineraryFileJob.ID, &itineraryFileJob.Status, &statusDescription, &itineraryFileJob.CreationDate, &startDate, &endDate, &filePath, &fileManager, &itineraryFileJob.ItineraryID, &asyncTaskId)
+	var itineraryRevision sql.NullInt64
+	var promptTemplateId sql.NullInt64
+	err := row.Scan(&itineraryFileJob.ID, &itineraryFileJob.Status, &statusDescription, &itineraryFileJob.CreationDate, &startDate, &endDate, &filePath, &fileManager, &itineraryFileJob.ItineraryID, &asyncTaskId, &itineraryRevision, &promptTemplateId, &itineraryFileJob.Language, &itineraryFileJob.Source, &itineraryFileJob.BaseJobID, &itineraryFileJob.ScopeStartDate, &itineraryFileJob.ScopeEndDate)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +154,12 @@ func (ifj *ItineraryFileJob) defaultFindAliveById(id int64) (*ItineraryFileJob,
 	} else {
 		itineraryFileJob.AsyncTaskID = ""
 	}
+	if itineraryRevision.Valid {
+		itineraryFileJob.ItineraryRevision = &itineraryRevision.Int64
+	}
+	if promptTemplateId.Valid {
+		itineraryFileJob.PromptTemplateID = &promptTemplateId.Int64
+	}
 
 	return itineraryFileJob, nil
 }
@@ -144,7 +180,7 @@ func (ifj *ItineraryFileJob) defaultFindAliveLightweightById(id int64) (*Itinera
 }
 
 func (ifj *ItineraryFileJob) defaultFindAliveByItineraryId(itineraryId int64) ([]*ItineraryFileJob, error) {
-	query := `SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	query := `SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE itinerary_id = ? AND status != 'deleted'`
 	rows, err := db.DB.Query(query, itineraryId)
 	if err != nil {
@@ -160,7 +196,9 @@ func (ifj *ItineraryFileJob) defaultFindAliveByItineraryId(itineraryId int64) ([
 		var filePath sql.NullString
 		var fileManager sql.NullString
 		var asyncTaskId sql.NullString
-		err := rows.Scan(&job.ID, &job.Status, &statusDescription, &job.CreationDate, &startDate, &endDate, &filePath, &fileManager, &job.ItineraryID, &asyncTaskId)
+		var itineraryRevision sql.NullInt64
+		var promptTemplateId sql.NullInt64
+		err := rows.Scan(&job.ID, &job.Status, &statusDescription, &job.CreationDate, &startDate, &endDate, &filePath, &fileManager, &job.ItineraryID, &asyncTaskId, &itineraryRevision, &promptTemplateId, &job.Language, &job.Source, &job.BaseJobID, &job.ScopeStartDate, &job.ScopeEndDate)
 
 		if err != nil {
 			return nil, err
@@ -195,6 +233,12 @@ func (ifj *ItineraryFileJob) defaultFindAliveByItineraryId(itineraryId int64) ([
 		} else {
 			job.AsyncTaskID = ""
 		}
+		if itineraryRevision.Valid {
+			job.ItineraryRevision = &itineraryRevision.Int64
+		}
+		if promptTemplateId.Valid {
+			job.PromptTemplateID = &promptTemplateId.Int64
+		}
 
 		jobs = append(jobs, &job)
 	}
@@ -205,7 +249,7 @@ func (ifj *ItineraryFileJob) defaultFindAliveByItineraryId(itineraryId int64) ([
 }
 
 func (ifj *ItineraryFileJob) defaultFindDead(fetchLimit int) ([]*ItineraryFileJob, error) {
-	query := `SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	query := `SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT ?`
 	rows, err := db.DB.Query(query, fetchLimit)
 	if err != nil {
@@ -221,7 +265,9 @@ func (ifj *ItineraryFileJob) defaultFindDead(fetchLimit int) ([]*ItineraryFileJo
 		var filePath sql.NullString
 		var fileManager sql.NullString
 		var asyncTaskId sql.NullString
-		err := rows.Scan(&job.ID, &job.Status, &statusDescription, &job.CreationDate, &startDate, &endDate, &filePath, &fileManager, &job.ItineraryID, &asyncTaskId)
+		var itineraryRevision sql.NullInt64
+		var promptTemplateId sql.NullInt64
+		err := rows.Scan(&job.ID, &job.Status, &statusDescription, &job.CreationDate, &startDate, &endDate, &filePath, &fileManager, &job.ItineraryID, &asyncTaskId, &itineraryRevision, &promptTemplateId, &job.Language, &job.Source, &job.BaseJobID, &job.ScopeStartDate, &job.ScopeEndDate)
 
 		if err != nil {
 			return nil, err
@@ -256,6 +302,12 @@ func (ifj *ItineraryFileJob) defaultFindDead(fetchLimit int) ([]*ItineraryFileJo
 		} else {
 			job.AsyncTaskID = ""
 		}
+		if itineraryRevision.Valid {
+			job.ItineraryRevision = &itineraryRevision.Int64
+		}
+		if promptTemplateId.Valid {
+			job.PromptTemplateID = &promptTemplateId.Int64
+		}
 
 		jobs = append(jobs, &job)
 	}
@@ -298,9 +350,17 @@ func (ifj *ItineraryFileJob) defaultPrepareJob(itinerary *Itinerary) error {
 
 	ifj.FileManager = filemanager
 
-	// Insert the job into the database
-	query := `INSERT INTO itinerary_file_jobs (status, creation_date, file_manager, itinerary_id) VALUES (?, ?, ?, ?)`
-	res, err := db.DB.Exec(query, ifj.Status, time.Now(), ifj.FileManager, itinerary.ID)
+	if ifj.Source == "" {
+		ifj.Source = ItineraryFileJobSourceLlm
+	}
+
+	// Insert the job into the database, generated from the latest revision of the itinerary with the prompt template, language, source
+	// and regeneration scope set in the job
+	query := `INSERT INTO itinerary_file_jobs (status, creation_date, file_manager, itinerary_id, itinerary_revision, prompt_template_id, language, source,
+	base_job_id, scope_start_date, scope_end_date)
+	VALUES (?, ?, ?, ?, (SELECT MAX(revision_number) FROM itinerary_revisions WHERE itinerary_id = ?), ?, ?, ?, ?, ?, ?)`
+	res, err := db.DB.Exec(query, ifj.Status, time.Now(), ifj.FileManager, itinerary.ID, itinerary.ID, ifj.PromptTemplateID, ifj.Language,
+		ifj.Source, ifj.BaseJobID, ifj.ScopeStartDate, ifj.ScopeEndDate)
 	if err == nil {
 		id, err := res.LastInsertId()
 		if err == nil {
@@ -386,14 +446,28 @@ func (ifj *ItineraryFileJob) defaultCompleteJob() error {
 	return nil
 }
 
-func (ifj *ItineraryFileJob) defaultDeleteJob() error {
+// defaultDeleteJob deletes the job with the conversation about its file
+func (ifj *ItineraryFileJob) defaultDeleteJob() (err error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction to delete job: %v", err)
+		return fmt.Errorf("failed to delete job from database: %w", err)
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	err = InitItineraryJobMessage().DeleteByJobIdTx(ifj.ID, tx)
+	if err != nil {
+		return fmt.Errorf("failed to delete job messages from database: %w", err)
+	}
+
 	query := `DELETE FROM itinerary_file_jobs WHERE id = ?`
-	_, err := db.DB.Exec(query, ifj.ID)
+	_, err = tx.Exec(query, ifj.ID)
 	if err != nil {
 		log.Errorf("Error deleting job from database: %v", err)
 		return fmt.Errorf("failed to delete job from database: %w", err)
 	}
-	return err
+	return nil
 }
 
 func (ifj *ItineraryFileJob) defaultSoftDeleteJob() error {
@@ -415,3 +489,13 @@ func (ifj *ItineraryFileJob) defaultSoftDeleteJobsByItineraryId(itineraryId int6
 	}
 	return nil
 }
+
+func (ifj *ItineraryFileJob) defaultSoftDeleteJobsByOwnerId(ownerId int64, tx *sql.Tx) error {
+	query := `UPDATE itinerary_file_jobs SET status = 'deleted' WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+	_, err := tx.Exec(query, ownerId)
+	if err != nil {
+		log.Errorf("Error soft deleting jobs by owner ID: %v", err)
+		return fmt.Errorf("failed to soft delete jobs by owner ID: %w", err)
+	}
+	return nil
+}
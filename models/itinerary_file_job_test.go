@@ -18,11 +18,11 @@ func TestFileJobDefaultFindAliveByItineraryId_Success(t *testing.T) {
 	itineraryID := int64(1)
 	asyncTaskId1 := "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
 	asyncTaskId2 := "952057c1-ac50-4014-972e-28ab65242ed6"
-	rows := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id"}).
-		AddRow(1, "completed", "Job OK", time.Now(), time.Now().Add(1*time.Minute), time.Now().Add(24*time.Hour), "/path/to/file1", "local", itineraryID, asyncTaskId1).
-		AddRow(2, "running", "Job running", time.Now().Add(48*time.Hour), time.Now().Add(49*time.Hour), time.Now().Add(72*time.Hour), "/path/to/file2", "local", itineraryID, asyncTaskId2)
+	rows := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date"}).
+		AddRow(1, "completed", "Job OK", time.Now(), time.Now().Add(1*time.Minute), time.Now().Add(24*time.Hour), "/path/to/file1", "local", itineraryID, asyncTaskId1, nil, nil, "", "llm", nil, nil, nil).
+		AddRow(2, "running", "Job running", time.Now().Add(48*time.Hour), time.Now().Add(49*time.Hour), time.Now().Add(72*time.Hour), "/path/to/file2", "local", itineraryID, asyncTaskId2, nil, nil, "", "llm", nil, nil, nil)
 
-	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id FROM itinerary_file_jobs WHERE itinerary_id = \\? AND status != 'deleted'").
+	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date FROM itinerary_file_jobs WHERE itinerary_id = \\? AND status != 'deleted'").
 		WithArgs(itineraryID).
 		WillReturnRows(rows)
 
@@ -64,12 +64,12 @@ func TestFileJobDefaultFindAliveByItineraryId_NilStartAndEndDateSuccess(t *testi
 	asyncTaskId1 := "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
 	asyncTaskId2 := "952057c1-ac50-4014-972e-28ab65242ed6"
 	asyncTaskId3 := "12345678-1234-5678-1234-567812345678"
-	rows := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id"}).
-		AddRow(1, "completed", "Job OK", time.Now(), time.Now().Add(1*time.Minute), time.Now().Add(24*time.Hour), "/path/to/file1", "local", itineraryID, asyncTaskId1).
-		AddRow(2, "running", "Job running", time.Now().Add(48*time.Hour), time.Now().Add(49*time.Hour), time.Now().Add(72*time.Hour), "/path/to/file2", "local", itineraryID, asyncTaskId2).
-		AddRow(3, "pending", "Job pending", time.Now().Add(72*time.Hour), nil, nil, "/path/to/file3", "local", itineraryID, asyncTaskId3)
+	rows := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date"}).
+		AddRow(1, "completed", "Job OK", time.Now(), time.Now().Add(1*time.Minute), time.Now().Add(24*time.Hour), "/path/to/file1", "local", itineraryID, asyncTaskId1, nil, nil, "", "llm", nil, nil, nil).
+		AddRow(2, "running", "Job running", time.Now().Add(48*time.Hour), time.Now().Add(49*time.Hour), time.Now().Add(72*time.Hour), "/path/to/file2", "local", itineraryID, asyncTaskId2, nil, nil, "", "llm", nil, nil, nil).
+		AddRow(3, "pending", "Job pending", time.Now().Add(72*time.Hour), nil, nil, "/path/to/file3", "local", itineraryID, asyncTaskId3, nil, nil, "", "llm", nil, nil, nil)
 
-	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id FROM itinerary_file_jobs WHERE itinerary_id = \\? AND status != 'deleted'").
+	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date FROM itinerary_file_jobs WHERE itinerary_id = \\? AND status != 'deleted'").
 		WithArgs(itineraryID).
 		WillReturnRows(rows)
 
@@ -117,7 +117,7 @@ func TestFileJobDefaultFindAliveByItineraryId_Error(t *testing.T) {
 
 	itineraryID := int64(1)
 
-	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id FROM itinerary_file_jobs WHERE itinerary_id = \\? AND status != 'deleted'").
+	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date FROM itinerary_file_jobs WHERE itinerary_id = \\? AND status != 'deleted'").
 		WithArgs(itineraryID).
 		WillReturnError(sqlmock.ErrCancelled)
 
@@ -140,10 +140,10 @@ func TestFileJobDefaultFindAliveById_Success(t *testing.T) {
 
 	jobID := int64(1)
 	asyncTaskId := "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
-	row := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id"}).
-		AddRow(jobID, "completed", "Job OK", time.Now(), time.Now().Add(1*time.Minute), time.Now().Add(24*time.Hour), "/path/to/file", "local", 1, asyncTaskId)
+	row := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date"}).
+		AddRow(jobID, "completed", "Job OK", time.Now(), time.Now().Add(1*time.Minute), time.Now().Add(24*time.Hour), "/path/to/file", "local", 1, asyncTaskId, 3, 2, "es", "plan", nil, nil, nil)
 
-	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id FROM itinerary_file_jobs WHERE id = \\? AND status != 'deleted'").
+	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date FROM itinerary_file_jobs WHERE id = \\? AND status != 'deleted'").
 		WithArgs(jobID).
 		WillReturnRows(row)
 
@@ -153,6 +153,10 @@ func TestFileJobDefaultFindAliveById_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, jobID, j.ID)
 	assert.Equal(t, "completed", j.Status)
+	assert.Equal(t, int64(3), *j.ItineraryRevision)
+	assert.Equal(t, int64(2), *j.PromptTemplateID)
+	assert.Equal(t, "es", j.Language)
+	assert.Equal(t, "plan", j.Source)
 	assert.Equal(t, "/path/to/file", j.Filepath)
 	assert.Equal(t, "local", j.FileManager)
 	assert.Equal(t, "Job OK", j.StatusDescription)
@@ -172,10 +176,10 @@ func TestFileJobDefaultFindAliveById_NullStartAndEndDateSuccess(t *testing.T) {
 
 	jobID := int64(1)
 	asyncTaskId := "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
-	row := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id"}).
-		AddRow(jobID, "pending", "Job OK", time.Now(), nil, nil, "/path/to/file", "local", 1, asyncTaskId)
+	row := sqlmock.NewRows([]string{"id", "status", "status_description", "creation_date", "start_date", "end_date", "file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date"}).
+		AddRow(jobID, "pending", "Job OK", time.Now(), nil, nil, "/path/to/file", "local", 1, asyncTaskId, nil, nil, "", "llm", nil, nil, nil)
 
-	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id FROM itinerary_file_jobs WHERE id = \\? AND status != 'deleted'").
+	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date FROM itinerary_file_jobs WHERE id = \\? AND status != 'deleted'").
 		WithArgs(jobID).
 		WillReturnRows(row)
 
@@ -203,7 +207,7 @@ func TestFileJobDefaultFindAliveById_Error(t *testing.T) {
 	db.DB = dbMock
 
 	itineraryID := int64(1)
-	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id FROM itinerary_file_jobs WHERE id = \\? AND status != 'deleted'").
+	mock.ExpectQuery("SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date FROM itinerary_file_jobs WHERE id = \\? AND status != 'deleted'").
 		WithArgs(itineraryID).
 		WillReturnError(sqlmock.ErrCancelled)
 
@@ -233,12 +237,12 @@ func TestDefaultFindDead_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "status", "status_description", "creation_date", "start_date", "end_date",
-		"file_path", "file_manager", "itinerary_id", "async_task_id",
+		"file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date",
 	}).
-		AddRow(job1ID, "deleted", "desc1", now, now.Add(1*time.Minute), now.Add(2*time.Minute), "/dead/file1", "local", itineraryID, asyncTaskId1).
-		AddRow(job2ID, "deleted", "desc2", now.Add(1*time.Hour), now.Add(2*time.Hour), now.Add(3*time.Hour), "/dead/file2", "s3", itineraryID, asyncTaskId2)
+		AddRow(job1ID, "deleted", "desc1", now, now.Add(1*time.Minute), now.Add(2*time.Minute), "/dead/file1", "local", itineraryID, asyncTaskId1, nil, nil, "", "llm", nil, nil, nil).
+		AddRow(job2ID, "deleted", "desc2", now.Add(1*time.Hour), now.Add(2*time.Hour), now.Add(3*time.Hour), "/dead/file2", "s3", itineraryID, asyncTaskId2, nil, nil, "", "llm", nil, nil, nil)
 
-	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT \?`).
 		WithArgs(2).
 		WillReturnRows(rows)
@@ -286,12 +290,12 @@ func TestDefaultFindDead_NilStartAndEndDateSuccess(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "status", "status_description", "creation_date", "start_date", "end_date",
-		"file_path", "file_manager", "itinerary_id", "async_task_id",
+		"file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date",
 	}).
-		AddRow(job1ID, "deleted", "desc1", now, now.Add(1*time.Minute), now.Add(2*time.Minute), "/dead/file1", "local", itineraryID, asyncTaskId1).
-		AddRow(job2ID, "deleted", "desc2", now.Add(1*time.Hour), nil, nil, "/dead/file2", "s3", itineraryID, asyncTaskId2)
+		AddRow(job1ID, "deleted", "desc1", now, now.Add(1*time.Minute), now.Add(2*time.Minute), "/dead/file1", "local", itineraryID, asyncTaskId1, nil, nil, "", "llm", nil, nil, nil).
+		AddRow(job2ID, "deleted", "desc2", now.Add(1*time.Hour), nil, nil, "/dead/file2", "s3", itineraryID, asyncTaskId2, nil, nil, "", "llm", nil, nil, nil)
 
-	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT \?`).
 		WithArgs(2).
 		WillReturnRows(rows)
@@ -330,7 +334,7 @@ func TestDefaultFindDead_QueryError(t *testing.T) {
 	defer dbMock.Close()
 	db.DB = dbMock
 
-	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT \?`).
 		WithArgs(5).
 		WillReturnError(sqlmock.ErrCancelled)
@@ -355,11 +359,11 @@ func TestDefaultFindDead_ScanError(t *testing.T) {
 	// Return a row with a wrong type to cause scan error
 	rows := sqlmock.NewRows([]string{
 		"id", "status", "status_description", "creation_date", "start_date", "end_date",
-		"file_path", "file_manager", "itinerary_id", "async_task_id",
+		"file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date",
 	}).
-		AddRow("not-an-int", "deleted", "desc", time.Now(), time.Now(), time.Now(), "/file", "local", 1, "async-task")
+		AddRow("not-an-int", "deleted", "desc", time.Now(), time.Now(), time.Now(), "/file", "local", 1, "async-task", nil, nil, "", "llm", nil, nil, nil)
 
-	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT \?`).
 		WithArgs(1).
 		WillReturnRows(rows)
@@ -383,12 +387,12 @@ func TestDefaultFindDead_RowsErr(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "status", "status_description", "creation_date", "start_date", "end_date",
-		"file_path", "file_manager", "itinerary_id", "async_task_id",
+		"file_path", "file_manager", "itinerary_id", "async_task_id", "itinerary_revision", "prompt_template_id", "language", "source", "base_job_id", "scope_start_date", "scope_end_date",
 	}).
-		AddRow(1, "deleted", "desc", time.Now(), time.Now(), time.Now(), "/file", "local", 1, "async-task").
+		AddRow(1, "deleted", "desc", time.Now(), time.Now(), time.Now(), "/file", "local", 1, "async-task", nil, nil, "", "llm", nil, nil, nil).
 		RowError(0, sqlmock.ErrCancelled)
 
-	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id
+	mock.ExpectQuery(`SELECT id, status, status_description, creation_date, start_date, end_date, file_path, file_manager, itinerary_id, async_task_id, itinerary_revision, prompt_template_id, language, source, base_job_id, scope_start_date, scope_end_date
 	FROM itinerary_file_jobs WHERE status = 'deleted' ORDER BY creation_date ASC LIMIT \?`).
 		WithArgs(1).
 		WillReturnRows(rows)
@@ -629,9 +633,14 @@ func TestDefaultDeleteJob_Success(t *testing.T) {
 		ID: 1,
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`DELETE FROM itinerary_job_messages WHERE job_id = \?`).ExpectExec().
+		WithArgs(job.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
 	mock.ExpectExec(`DELETE FROM itinerary_file_jobs WHERE id = \?`).
 		WithArgs(job.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	err = job.defaultDeleteJob()
 
@@ -652,9 +661,14 @@ func TestDefaultDeleteJob_Error(t *testing.T) {
 		ID: 1,
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`DELETE FROM itinerary_job_messages WHERE job_id = \?`).ExpectExec().
+		WithArgs(job.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec(`DELETE FROM itinerary_file_jobs WHERE id = \?`).
 		WithArgs(job.ID).
 		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
 
 	err = job.defaultDeleteJob()
 
@@ -678,8 +692,8 @@ func TestDefaultPrepareJob_SuccessDefaultFileManager(t *testing.T) {
 	}
 	job := &ItineraryFileJob{}
 
-	mock.ExpectExec(`INSERT INTO itinerary_file_jobs \(status, creation_date, file_manager, itinerary_id\) VALUES \(\?, \?, \?, \?\)`).
-		WithArgs("pending", sqlmock.AnyArg(), "local", itinerary.ID).
+	mock.ExpectExec(`INSERT INTO itinerary_file_jobs \(status, creation_date, file_manager, itinerary_id, itinerary_revision, prompt_template_id, language, source,\s+base_job_id, scope_start_date, scope_end_date\)\s+VALUES \(\?, \?, \?, \?, \(SELECT MAX\(revision_number\) FROM itinerary_revisions WHERE itinerary_id = \?\), \?, \?, \?, \?, \?, \?\)`).
+		WithArgs("pending", sqlmock.AnyArg(), "local", itinerary.ID, itinerary.ID, nil, "", "llm", nil, nil, nil).
 		WillReturnResult(sqlmock.NewResult(123, 1))
 
 	err = job.defaultPrepareJob(itinerary)
@@ -705,13 +719,17 @@ func TestDefaultPrepareJob_SuccessEnvironmentVariableFileManager(t *testing.T) {
 		Description: "A test trip",
 		OwnerID:     7,
 	}
-	job := &ItineraryFileJob{}
+	promptTemplateId := int64(5)
+	baseJobId := int64(4)
+	scopeDate := time.Date(2024, time.July, 3, 0, 0, 0, 0, time.UTC)
+	job := &ItineraryFileJob{PromptTemplateID: &promptTemplateId, Language: "fr", Source: ItineraryFileJobSourcePlan, BaseJobID: &baseJobId,
+		ScopeStartDate: &scopeDate, ScopeEndDate: &scopeDate}
 
 	// Set the environment variable for file manager
 	t.Setenv("FILE_MANAGER", "s3")
 
-	mock.ExpectExec(`INSERT INTO itinerary_file_jobs \(status, creation_date, file_manager, itinerary_id\) VALUES \(\?, \?, \?, \?\)`).
-		WithArgs("pending", sqlmock.AnyArg(), "s3", itinerary.ID).
+	mock.ExpectExec(`INSERT INTO itinerary_file_jobs \(status, creation_date, file_manager, itinerary_id, itinerary_revision, prompt_template_id, language, source,\s+base_job_id, scope_start_date, scope_end_date\)\s+VALUES \(\?, \?, \?, \?, \(SELECT MAX\(revision_number\) FROM itinerary_revisions WHERE itinerary_id = \?\), \?, \?, \?, \?, \?, \?\)`).
+		WithArgs("pending", sqlmock.AnyArg(), "s3", itinerary.ID, itinerary.ID, promptTemplateId, "fr", "plan", &baseJobId, &scopeDate, &scopeDate).
 		WillReturnResult(sqlmock.NewResult(123, 1))
 
 	err = job.defaultPrepareJob(itinerary)
@@ -739,8 +757,8 @@ func TestDefaultPrepareJob_InsertError(t *testing.T) {
 	}
 	job := &ItineraryFileJob{}
 
-	mock.ExpectExec(`INSERT INTO itinerary_file_jobs \(status, creation_date, file_manager, itinerary_id\) VALUES \(\?, \?, \?. \?\)`).
-		WithArgs("pending", sqlmock.AnyArg(), "local", itinerary.ID).
+	mock.ExpectExec(`INSERT INTO itinerary_file_jobs \(status, creation_date, file_manager, itinerary_id, itinerary_revision, prompt_template_id, language, source,\s+base_job_id, scope_start_date, scope_end_date\)`).
+		WithArgs("pending", sqlmock.AnyArg(), "local", itinerary.ID, itinerary.ID, nil, "", "llm", nil, nil, nil).
 		WillReturnError(sqlmock.ErrCancelled)
 
 	err = job.defaultPrepareJob(itinerary)
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+const (
+	ItineraryImportFormatICS  = "ics"
+	ItineraryImportFormatCSV  = "csv"
+	ItineraryImportFormatJSON = "json"
+)
+
+// ItineraryImportFormats lists the formats itineraries can be imported from
+var ItineraryImportFormats = []string{ItineraryImportFormatICS, ItineraryImportFormatCSV, ItineraryImportFormatJSON}
+
+// ItineraryImport is the preview of an itinerary read from an imported file, before it is created. Each destination found in the file
+// is a row with the errors that prevent it from being imported, and Errors has the ones of the itinerary as a whole, like a trip
+// spanning too many days. The itinerary can only be created when it is valid
+type ItineraryImport struct {
+	Format      string                `json:"format" example:"csv"`
+	Title       string                `json:"title" example:"Trip to Spain"`
+	Description string                `json:"description" example:"Summer vacation in Spain"`
+	Notes       *string               `json:"notes,omitempty" example:"I want to enjoy the nightlife"`
+	Rows        []*ItineraryImportRow `json:"rows"`
+	Errors      []string              `json:"errors"`
+	Valid       bool                  `json:"valid" example:"true"`
+}
+
+// ItineraryImportRow is a destination of an imported file. Row is the line of a CSV file, counting the header, or the position of the
+// destination in an iCalendar or JSON file, starting at 1. The dates are missing if they could not be read
+type ItineraryImportRow struct {
+	Row           int        `json:"row" example:"2"`
+	Country       string     `json:"country" example:"Spain"`
+	City          string     `json:"city" example:"Madrid"`
+	ArrivalDate   *time.Time `json:"arrivalDate,omitempty" example:"2024-07-01T00:00:00Z"`
+	DepartureDate *time.Time `json:"departureDate,omitempty" example:"2024-07-05T00:00:00Z"`
+	Errors        []string   `json:"errors"`
+}
+
+// ItineraryInterchangeDocument is the JSON interchange format of imported itineraries, modelled on the trip exports of TripIt. The
+// dates are kept as text, so a destination with a wrong date is reported in the preview instead of failing the whole import
+type ItineraryInterchangeDocument struct {
+	Trip         ItineraryInterchangeTrip           `json:"trip"`
+	Destinations []*ItineraryInterchangeDestination `json:"destinations"`
+}
+
+type ItineraryInterchangeTrip struct {
+	DisplayName string  `json:"display_name" example:"Trip to Spain"`
+	Description string  `json:"description" example:"Summer vacation in Spain"`
+	Notes       *string `json:"notes" example:"I want to enjoy the nightlife"`
+}
+
+type ItineraryInterchangeDestination struct {
+	City      string `json:"city" example:"Madrid"`
+	Country   string `json:"country" example:"Spain"`
+	StartDate string `json:"start_date" example:"2024-07-01"`
+	EndDate   string `json:"end_date" example:"2024-07-05"`
+}
+
+// TravelDestinations returns the destinations of the rows, in the order of the file. Rows without dates are left out
+func (i *ItineraryImport) TravelDestinations() []*ItineraryTravelDestination {
+	destinations := []*ItineraryTravelDestination{}
+	for _, row := range i.Rows {
+		if row.ArrivalDate == nil || row.DepartureDate == nil {
+			continue
+		}
+		destinations = append(destinations, NewItineraryTravelDestination(row.Country, row.City, *row.ArrivalDate, *row.DepartureDate))
+	}
+	return destinations
+}
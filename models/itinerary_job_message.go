@@ -0,0 +1,136 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Roles of the messages of the conversation about the file of a job
+const (
+	JobMessageRoleUser      = "user"
+	JobMessageRoleAssistant = "assistant"
+)
+
+// ItineraryJobMessage is a message of the conversation that refines the file of an itinerary job. JobID is the job the conversation is
+// about, and OutputJobID the job with the new version of the file that answers the message of the user, which the reply of the
+// assistant has too
+type ItineraryJobMessage struct {
+	ID           int64      `json:"id" example:"1"`
+	JobID        int64      `json:"jobId" example:"4"`
+	ItineraryID  int64      `json:"itineraryId" example:"1"`
+	Role         string     `json:"role" example:"user"`
+	Content      string     `json:"content" example:"Make the third day more relaxed"`
+	OutputJobID  *int64     `json:"outputJobId,omitempty" example:"6"`
+	CreationDate *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindByJobId       func(jobId int64) ([]*ItineraryJobMessage, error)       `json:"-"`
+	FindByItineraryId func(itineraryId int64) ([]*ItineraryJobMessage, error) `json:"-"`
+	Create            func() error                                            `json:"-"`
+	DeleteByJobIdTx   func(jobId int64, tx *sql.Tx) error                     `json:"-"`
+}
+
+var InitItineraryJobMessage = func() *ItineraryJobMessage {
+	return InitItineraryJobMessageFunctions(&ItineraryJobMessage{})
+}
+
+var InitItineraryJobMessageFunctions = func(message *ItineraryJobMessage) *ItineraryJobMessage {
+	// Set default SQL implementations for FindByJobId, FindByItineraryId, Create and DeleteByJobIdTx. In the future there could be
+	// implementations for other NoSQL DB systems like MongoDB
+	message.FindByJobId = message.defaultFindByJobId
+	message.FindByItineraryId = message.defaultFindByItineraryId
+	message.Create = message.defaultCreate
+	message.DeleteByJobIdTx = message.defaultDeleteByJobIdTx
+
+	return message
+}
+
+// defaultFindByJobId retrieves the conversation about the file of a job, oldest message first
+func (m *ItineraryJobMessage) defaultFindByJobId(jobId int64) ([]*ItineraryJobMessage, error) {
+	query := `SELECT id, job_id, itinerary_id, role, content, output_job_id, creation_date
+	FROM itinerary_job_messages WHERE job_id = ? ORDER BY creation_date, id`
+	return findItineraryJobMessages(query, jobId)
+}
+
+// defaultFindByItineraryId retrieves the conversations about the files of all the jobs of an itinerary
+func (m *ItineraryJobMessage) defaultFindByItineraryId(itineraryId int64) ([]*ItineraryJobMessage, error) {
+	query := `SELECT id, job_id, itinerary_id, role, content, output_job_id, creation_date
+	FROM itinerary_job_messages WHERE itinerary_id = ? ORDER BY job_id, creation_date, id`
+	return findItineraryJobMessages(query, itineraryId)
+}
+
+func findItineraryJobMessages(query string, id int64) ([]*ItineraryJobMessage, error) {
+	rows, err := db.DB.Query(query, id)
+	if err != nil {
+		log.Errorf("Error fetching itinerary job messages: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*ItineraryJobMessage{}
+	for rows.Next() {
+		message := &ItineraryJobMessage{}
+		err = rows.Scan(&message.ID, &message.JobID, &message.ItineraryID, &message.Role, &message.Content, &message.OutputJobID,
+			&message.CreationDate)
+		if err != nil {
+			log.Errorf("Error scanning itinerary job message: %v", err)
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (m *ItineraryJobMessage) defaultCreate() error {
+	query := `INSERT INTO itinerary_job_messages(job_id, itinerary_id, role, content, output_job_id, creation_date)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for itinerary job message: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(m.JobID, m.ItineraryID, m.Role, m.Content, m.OutputJobID, now)
+	if err != nil {
+		log.Errorf("Error executing insert for message of itinerary job %d: %v", m.JobID, err)
+		return err
+	}
+
+	m.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for itinerary job message: %v", err)
+		return err
+	}
+	m.CreationDate = &now
+
+	return nil
+}
+
+func (m *ItineraryJobMessage) defaultDeleteByJobIdTx(jobId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_job_messages WHERE job_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for messages of itinerary job %d: %v", jobId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(jobId)
+	if err != nil {
+		log.Errorf("Error executing delete for messages of itinerary job %d: %v", jobId, err)
+		return err
+	}
+
+	return nil
+}
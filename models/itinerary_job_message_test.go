@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItineraryJobMessage_FindByJobId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	rows := sqlmock.NewRows([]string{"id", "job_id", "itinerary_id", "role", "content", "output_job_id", "creation_date"}).
+		AddRow(1, 4, 3, "user", "Make the third day more relaxed", 6, time.Now()).
+		AddRow(2, 4, 3, "assistant", "The third day now starts later.", 6, time.Now())
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_job_messages WHERE job_id = \\? ORDER BY creation_date, id").
+		WithArgs(int64(4)).
+		WillReturnRows(rows)
+
+	messages, err := InitItineraryJobMessage().FindByJobId(4)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, JobMessageRoleAssistant, messages[1].Role)
+	assert.Equal(t, int64(6), *messages[1].OutputJobID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryJobMessage_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	outputJobId := int64(6)
+	mock.ExpectPrepare("INSERT INTO itinerary_job_messages")
+	mock.ExpectExec("INSERT INTO itinerary_job_messages").
+		WithArgs(int64(4), int64(3), "user", "Add vegetarian restaurants", &outputJobId, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	message := InitItineraryJobMessage()
+	message.JobID = 4
+	message.ItineraryID = 3
+	message.Role = JobMessageRoleUser
+	message.Content = "Add vegetarian restaurants"
+	message.OutputJobID = &outputJobId
+	err = message.Create()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), message.ID)
+	assert.NotNil(t, message.CreationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
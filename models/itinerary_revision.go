@@ -0,0 +1,320 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// ItineraryRevision is an immutable snapshot of an itinerary, saved when it is created and on every update. Revisions are numbered
+// from 1 for each itinerary. Restoring a revision saves a new one with its content, so the history is never rewritten
+type ItineraryRevision struct {
+	ID                 int64                           `json:"-"`
+	ItineraryID        int64                           `json:"itineraryId" example:"1"`
+	Number             int64                           `json:"number" example:"3"`
+	AuthorID           int64                           `json:"authorId" example:"1"`
+	CreationDate       time.Time                       `json:"creationDate" example:"2024-06-01T00:00:00Z"`
+	RestoredFrom       *int64                          `json:"restoredFrom,omitempty" example:"1"`
+	Title              string                          `json:"title" example:"Trip to Spain"`
+	Description        string                          `json:"description" example:"Summer vacation in Spain"`
+	Notes              *string                         `json:"notes,omitempty" example:"I want to enjoy the nightlife"`
+	TravelDestinations []*ItineraryRevisionDestination `json:"travelDestinations,omitempty"`
+
+	CreateTx              func(tx *sql.Tx) error                                            `json:"-"`
+	FindByItineraryId     func(itineraryId int64) ([]*ItineraryRevision, error)             `json:"-"`
+	FindByNumber          func(itineraryId int64, number int64) (*ItineraryRevision, error) `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                         `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                             `json:"-"`
+}
+
+// ItineraryRevisionDestination is a travel destination as it was in a revision
+type ItineraryRevisionDestination struct {
+	Country       string    `json:"country" example:"Spain"`
+	City          string    `json:"city" example:"Madrid"`
+	ArrivalDate   time.Time `json:"arrivalDate" example:"2024-07-01T00:00:00Z"`
+	DepartureDate time.Time `json:"departureDate" example:"2024-07-05T00:00:00Z"`
+}
+
+// ItineraryRevisionChange is a difference between two revisions. Field is the JSON path of the changed value, and From or To are
+// missing when a destination was added or removed
+type ItineraryRevisionChange struct {
+	Field string `json:"field" example:"travelDestinations[1].city"`
+	From  any    `json:"from,omitempty" swaggertype:"string" example:"Madrid"`
+	To    any    `json:"to,omitempty" swaggertype:"string" example:"Seville"`
+}
+
+var InitItineraryRevision = func() *ItineraryRevision {
+	return InitItineraryRevisionFunctions(&ItineraryRevision{})
+}
+
+var InitItineraryRevisionFunctions = func(revision *ItineraryRevision) *ItineraryRevision {
+	// Set default SQL implementations for CreateTx, FindByItineraryId, FindByNumber, DeleteByItineraryIdTx and DeleteByOwnerIdTx. In the
+	// future there could be implementations for other NoSQL DB systems like MongoDB
+	revision.CreateTx = revision.defaultCreateTx
+	revision.FindByItineraryId = revision.defaultFindByItineraryId
+	revision.FindByNumber = revision.defaultFindByNumber
+	revision.DeleteByItineraryIdTx = revision.defaultDeleteByItineraryIdTx
+	revision.DeleteByOwnerIdTx = revision.defaultDeleteByOwnerIdTx
+
+	return revision
+}
+
+// NewItineraryRevision takes a snapshot of the current content of the itinerary. restoredFrom is the number of the revision being
+// restored, if any
+var NewItineraryRevision = func(itinerary *Itinerary, authorId int64, restoredFrom *int64) *ItineraryRevision {
+	revision := &ItineraryRevision{
+		ItineraryID:  itinerary.ID,
+		AuthorID:     authorId,
+		RestoredFrom: restoredFrom,
+		Title:        itinerary.Title,
+		Description:  itinerary.Description,
+		Notes:        itinerary.Notes,
+	}
+
+	for _, destination := range itinerary.TravelDestinations {
+		revision.TravelDestinations = append(revision.TravelDestinations, &ItineraryRevisionDestination{
+			Country:       destination.Country,
+			City:          destination.City,
+			ArrivalDate:   destination.ArrivalDate,
+			DepartureDate: destination.DepartureDate,
+		})
+	}
+
+	return InitItineraryRevisionFunctions(revision)
+}
+
+// Changes lists the differences from this revision to another one. Destinations are compared by their position
+func (r *ItineraryRevision) Changes(to *ItineraryRevision) []*ItineraryRevisionChange {
+	changes := []*ItineraryRevisionChange{}
+	addChange := func(field string, from any, to any) {
+		changes = append(changes, &ItineraryRevisionChange{Field: field, From: from, To: to})
+	}
+
+	if r.Title != to.Title {
+		addChange("title", r.Title, to.Title)
+	}
+	if r.Description != to.Description {
+		addChange("description", r.Description, to.Description)
+	}
+	if !equalOptionalStrings(r.Notes, to.Notes) {
+		addChange("notes", optionalString(r.Notes), optionalString(to.Notes))
+	}
+
+	for position := 0; position < max(len(r.TravelDestinations), len(to.TravelDestinations)); position++ {
+		field := fmt.Sprintf("travelDestinations[%d]", position)
+		if position >= len(to.TravelDestinations) {
+			addChange(field, r.TravelDestinations[position], nil)
+			continue
+		}
+		if position >= len(r.TravelDestinations) {
+			addChange(field, nil, to.TravelDestinations[position])
+			continue
+		}
+
+		fromDestination, toDestination := r.TravelDestinations[position], to.TravelDestinations[position]
+		if fromDestination.Country != toDestination.Country {
+			addChange(field+".country", fromDestination.Country, toDestination.Country)
+		}
+		if fromDestination.City != toDestination.City {
+			addChange(field+".city", fromDestination.City, toDestination.City)
+		}
+		if !fromDestination.ArrivalDate.Equal(toDestination.ArrivalDate) {
+			addChange(field+".arrivalDate", fromDestination.ArrivalDate, toDestination.ArrivalDate)
+		}
+		if !fromDestination.DepartureDate.Equal(toDestination.DepartureDate) {
+			addChange(field+".departureDate", fromDestination.DepartureDate, toDestination.DepartureDate)
+		}
+	}
+
+	return changes
+}
+
+func equalOptionalStrings(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// optionalString returns the string or an untyped nil, so missing notes are omitted from the changes
+func optionalString(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// defaultCreateTx saves the revision with the next number of its itinerary. It runs in the transaction of the change it records
+func (r *ItineraryRevision) defaultCreateTx(tx *sql.Tx) error {
+	r.CreationDate = time.Now()
+
+	query := `INSERT INTO itinerary_revisions(itinerary_id, revision_number, author_id, creation_date, restored_from, title, description, notes)
+	VALUES (?, (SELECT COALESCE(MAX(revision_number), 0) + 1 FROM itinerary_revisions WHERE itinerary_id = ?), ?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(query, r.ItineraryID, r.ItineraryID, r.AuthorID, r.CreationDate, r.RestoredFrom, r.Title, r.Description, r.Notes)
+	if err != nil {
+		log.Errorf("Error executing insert for revision of itinerary %d: %v", r.ItineraryID, err)
+		return err
+	}
+
+	r.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for revision of itinerary %d: %v", r.ItineraryID, err)
+		return err
+	}
+
+	err = tx.QueryRow(`SELECT revision_number FROM itinerary_revisions WHERE id = ?`, r.ID).Scan(&r.Number)
+	if err != nil {
+		log.Errorf("Error fetching number of revision %d: %v", r.ID, err)
+		return err
+	}
+
+	queryDestination := `INSERT INTO itinerary_revision_destinations(revision_id, position, country, city, arrival_date, departure_date)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	stmt, err := tx.Prepare(queryDestination)
+	if err != nil {
+		log.Errorf("Error preparing insert for revision destinations: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	for position, destination := range r.TravelDestinations {
+		_, err = stmt.Exec(r.ID, position, destination.Country, destination.City, destination.ArrivalDate, destination.DepartureDate)
+		if err != nil {
+			log.Errorf("Error executing insert for destination of revision %d: %v", r.ID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// defaultFindByItineraryId retrieves the revisions of an itinerary from the newest, without their destinations
+func (r *ItineraryRevision) defaultFindByItineraryId(itineraryId int64) ([]*ItineraryRevision, error) {
+	query := `SELECT id, itinerary_id, revision_number, author_id, creation_date, restored_from, title, description, notes
+	FROM itinerary_revisions WHERE itinerary_id = ? ORDER BY revision_number DESC`
+
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error querying revisions of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*ItineraryRevision{}
+	for rows.Next() {
+		revision, err := scanItineraryRevision(rows)
+		if err != nil {
+			log.Errorf("Error scanning itinerary revision row: %v", err)
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary revision rows: %v", err)
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// defaultFindByNumber retrieves a revision of an itinerary with its destinations
+func (r *ItineraryRevision) defaultFindByNumber(itineraryId int64, number int64) (*ItineraryRevision, error) {
+	query := `SELECT id, itinerary_id, revision_number, author_id, creation_date, restored_from, title, description, notes
+	FROM itinerary_revisions WHERE itinerary_id = ? AND revision_number = ?`
+
+	revision, err := scanItineraryRevision(db.DB.QueryRow(query, itineraryId, number))
+	if err != nil {
+		log.Errorf("Error fetching revision %d of itinerary %d: %v", number, itineraryId, err)
+		return nil, err
+	}
+
+	queryDestinations := `SELECT country, city, arrival_date, departure_date
+	FROM itinerary_revision_destinations WHERE revision_id = ? ORDER BY position ASC`
+
+	rows, err := db.DB.Query(queryDestinations, revision.ID)
+	if err != nil {
+		log.Errorf("Error querying destinations of revision %d: %v", revision.ID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		destination := &ItineraryRevisionDestination{}
+		err := rows.Scan(&destination.Country, &destination.City, &destination.ArrivalDate, &destination.DepartureDate)
+		if err != nil {
+			log.Errorf("Error scanning revision destination row: %v", err)
+			return nil, err
+		}
+		revision.TravelDestinations = append(revision.TravelDestinations, destination)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating revision destination rows: %v", err)
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+type itineraryRevisionScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItineraryRevision(scanner itineraryRevisionScanner) (*ItineraryRevision, error) {
+	revision := &ItineraryRevision{}
+
+	var restoredFrom sql.NullInt64
+	err := scanner.Scan(&revision.ID, &revision.ItineraryID, &revision.Number, &revision.AuthorID, &revision.CreationDate, &restoredFrom,
+		&revision.Title, &revision.Description, &revision.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	if restoredFrom.Valid {
+		revision.RestoredFrom = &restoredFrom.Int64
+	}
+
+	return revision, nil
+}
+
+func (r *ItineraryRevision) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	_, err := tx.Exec(`DELETE FROM itinerary_revision_destinations
+	WHERE revision_id IN (SELECT id FROM itinerary_revisions WHERE itinerary_id = ?)`, itineraryId)
+	if err != nil {
+		log.Errorf("Error deleting revision destinations of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM itinerary_revisions WHERE itinerary_id = ?`, itineraryId)
+	if err != nil {
+		log.Errorf("Error deleting revisions of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the revisions of all the itineraries of an owner, whoever their authors are
+func (r *ItineraryRevision) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	_, err := tx.Exec(`DELETE FROM itinerary_revision_destinations WHERE revision_id IN (SELECT id FROM itinerary_revisions
+	WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?))`, ownerId)
+	if err != nil {
+		log.Errorf("Error deleting revision destinations of itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM itinerary_revisions WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`, ownerId)
+	if err != nil {
+		log.Errorf("Error deleting revisions of itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var itineraryRevisionTestColumns = []string{"id", "itinerary_id", "revision_number", "author_id", "creation_date", "restored_from", "title",
+	"description", "notes"}
+
+// expectCreateItineraryRevision expects the revision saved with a change of an itinerary
+func expectCreateItineraryRevision(mock sqlmock.Sqlmock, itineraryId int64, authorId int64, restoredFrom *int64, destinations int) {
+	mock.ExpectExec("INSERT INTO itinerary_revisions").
+		WithArgs(itineraryId, itineraryId, authorId, sqlmock.AnyArg(), restoredFrom, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectQuery("SELECT revision_number FROM itinerary_revisions WHERE id = \\?").
+		WithArgs(int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"revision_number"}).AddRow(4))
+	prepare := mock.ExpectPrepare("INSERT INTO itinerary_revision_destinations")
+	for position := 0; position < destinations; position++ {
+		prepare.ExpectExec().
+			WithArgs(int64(10), position, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(int64(position+1), 1))
+	}
+}
+
+func TestNewItineraryRevision(t *testing.T) {
+	notes := "Tapas"
+	arrival := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	itinerary := &Itinerary{ID: 1, Title: "Spain", Description: "Summer", Notes: &notes, TravelDestinations: []*ItineraryTravelDestination{
+		{ID: 7, Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(48 * time.Hour)},
+	}}
+	restoredFrom := int64(2)
+
+	revision := NewItineraryRevision(itinerary, 3, &restoredFrom)
+	assert.Equal(t, int64(1), revision.ItineraryID)
+	assert.Equal(t, int64(3), revision.AuthorID)
+	assert.Equal(t, &restoredFrom, revision.RestoredFrom)
+	assert.Equal(t, "Spain", revision.Title)
+	assert.Equal(t, &notes, revision.Notes)
+	assert.Equal(t, []*ItineraryRevisionDestination{{Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(48 * time.Hour)}},
+		revision.TravelDestinations)
+	assert.NotNil(t, revision.CreateTx)
+}
+
+func TestItineraryRevision_CreateTx_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	expectCreateItineraryRevision(mock, 1, 3, nil, 2)
+	mock.ExpectCommit()
+
+	revision := NewItineraryRevision(&Itinerary{ID: 1, Title: "Spain", TravelDestinations: []*ItineraryTravelDestination{
+		{Country: "Spain", City: "Madrid"}, {Country: "Spain", City: "Seville"},
+	}}, 3, nil)
+
+	tx, err := db.DB.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, revision.CreateTx(tx))
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, int64(10), revision.ID)
+	assert.Equal(t, int64(4), revision.Number)
+	assert.False(t, revision.CreationDate.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryRevision_CreateTx_InsertError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO itinerary_revisions").WillReturnError(errors.New("constraint failed"))
+	mock.ExpectRollback()
+
+	tx, err := db.DB.Begin()
+	assert.NoError(t, err)
+	assert.EqualError(t, NewItineraryRevision(&Itinerary{ID: 1}, 3, nil).CreateTx(tx), "constraint failed")
+	assert.NoError(t, tx.Rollback())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryRevision_FindByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_revisions WHERE itinerary_id = \\? ORDER BY revision_number DESC").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(itineraryRevisionTestColumns).
+			AddRow(12, 1, 3, 2, now, 1, "Spain", "Summer", nil).
+			AddRow(11, 1, 2, 1, now, nil, "Spain trip", "Summer", "Tapas"))
+
+	revisions, err := InitItineraryRevision().FindByItineraryId(1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, int64(3), revisions[0].Number)
+	assert.Equal(t, int64(2), revisions[0].AuthorID)
+	assert.Equal(t, int64(1), *revisions[0].RestoredFrom)
+	assert.Nil(t, revisions[0].Notes)
+	assert.Nil(t, revisions[1].RestoredFrom)
+	assert.Equal(t, "Tapas", *revisions[1].Notes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryRevision_FindByNumber_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	arrival := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_revisions WHERE itinerary_id = \\? AND revision_number = \\?").
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows(itineraryRevisionTestColumns).AddRow(11, 1, 2, 1, time.Now(), nil, "Spain", "Summer", nil))
+	mock.ExpectQuery("SELECT country, city, arrival_date, departure_date FROM itinerary_revision_destinations WHERE revision_id = \\? ORDER BY position ASC").
+		WithArgs(int64(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"country", "city", "arrival_date", "departure_date"}).
+			AddRow("Spain", "Madrid", arrival, arrival.Add(24*time.Hour)).
+			AddRow("Spain", "Seville", arrival.Add(24*time.Hour), arrival.Add(72*time.Hour)))
+
+	revision, err := InitItineraryRevision().FindByNumber(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), revision.Number)
+	assert.Len(t, revision.TravelDestinations, 2)
+	assert.Equal(t, "Seville", revision.TravelDestinations[1].City)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryRevision_FindByNumber_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_revisions WHERE itinerary_id = \\? AND revision_number = \\?").
+		WithArgs(int64(1), int64(9)).
+		WillReturnRows(sqlmock.NewRows(itineraryRevisionTestColumns))
+
+	revision, err := InitItineraryRevision().FindByNumber(1, 9)
+	assert.Nil(t, revision)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItinerary_Restore_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	arrival := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	revision := &ItineraryRevision{ItineraryID: 1, Number: 2, Title: "Old title", Description: "Old description",
+		TravelDestinations: []*ItineraryRevisionDestination{{Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(24 * time.Hour)}}}
+	restoredFrom := int64(2)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
+		ExpectExec().
+		WithArgs("Old title", "Old description", nil, sqlmock.AnyArg(), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`DELETE FROM itinerary_travel_destinations WHERE itinerary_id = \?`).ExpectExec().
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
+		WithArgs("Spain", "Madrid", "ES", 40.4165, -3.70256, "Europe/Madrid", int64(1), arrival, arrival.Add(24*time.Hour), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	expectCreateItineraryRevision(mock, 1, 3, &restoredFrom, 1)
+	mock.ExpectCommit()
+
+	itinerary := InitItinerary()
+	itinerary.ID = 1
+	itinerary.OwnerID = 1
+	itinerary.Version = 2
+	itinerary.Title = "New title"
+
+	err = itinerary.Restore(revision, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Old title", itinerary.Title)
+	assert.Equal(t, int64(3), itinerary.Version)
+	assert.Len(t, itinerary.TravelDestinations, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryRevision_Changes(t *testing.T) {
+	notes := "Tapas"
+	arrival := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	madrid := &ItineraryRevisionDestination{Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(24 * time.Hour)}
+	seville := &ItineraryRevisionDestination{Country: "Spain", City: "Seville", ArrivalDate: arrival.Add(24 * time.Hour), DepartureDate: arrival.Add(72 * time.Hour)}
+	movedMadrid := &ItineraryRevisionDestination{Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(48 * time.Hour)}
+
+	from := &ItineraryRevision{Title: "Spain", Description: "Summer", TravelDestinations: []*ItineraryRevisionDestination{madrid}}
+	to := &ItineraryRevision{Title: "Spain", Description: "Summer", Notes: &notes, TravelDestinations: []*ItineraryRevisionDestination{movedMadrid, seville}}
+
+	assert.Equal(t, []*ItineraryRevisionChange{
+		{Field: "notes", To: "Tapas"},
+		{Field: "travelDestinations[0].departureDate", From: arrival.Add(24 * time.Hour), To: arrival.Add(48 * time.Hour)},
+		{Field: "travelDestinations[1]", To: seville},
+	}, from.Changes(to))
+
+	assert.Equal(t, []*ItineraryRevisionChange{
+		{Field: "notes", From: "Tapas"},
+		{Field: "travelDestinations[0].departureDate", From: arrival.Add(48 * time.Hour), To: arrival.Add(24 * time.Hour)},
+		{Field: "travelDestinations[1]", From: seville},
+	}, to.Changes(from))
+
+	assert.Empty(t, from.Changes(from))
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"database/sql"
+	"html"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Markers around the matched terms in the highlighted titles and snippets of the search results
+const (
+	SearchHighlightStart = "<mark>"
+	SearchHighlightEnd   = "</mark>"
+)
+
+// Control characters asked to FTS5 to surround the matched terms, replaced with SearchHighlightStart and SearchHighlightEnd once the
+// rest of the text is HTML-escaped. They are removed from the indexed texts, so only FTS5 can add them
+const (
+	ftsHighlightStart = "\x02"
+	ftsHighlightEnd   = "\x03"
+)
+
+var ftsHighlightRemover = strings.NewReplacer(ftsHighlightStart, "", ftsHighlightEnd, "")
+
+// ItinerarySearchDocument is the full-text indexed content of an itinerary: its texts, its destinations and the text of its latest
+// generated document. It is stored in the itinerary_search FTS5 table, whose rowid is the itinerary ID
+type ItinerarySearchDocument struct {
+	ItineraryID  int64
+	OwnerID      int64
+	Title        string
+	Description  string
+	Notes        string
+	Destinations string
+	Content      string
+
+	Save                  func() error                                                         `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                            `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                                `json:"-"`
+	Search                func(filter ItinerarySearchFilter) ([]*ItinerarySearchResult, error) `json:"-"`
+}
+
+// ItinerarySearchFilter holds the terms of a search among the itineraries owned by or shared with a user. Every term must match,
+// either as a word or as a word prefix
+type ItinerarySearchFilter struct {
+	UserID int64
+	Terms  []string
+	Offset int
+	Limit  int
+}
+
+// ItinerarySearchResult is an itinerary matching a search. Higher scores are better matches. The title highlight and snippet are
+// HTML-escaped, with the matched terms surrounded by SearchHighlightStart and SearchHighlightEnd, so they can be rendered as HTML
+type ItinerarySearchResult struct {
+	ItineraryID    int64   `json:"itineraryId" example:"1"`
+	OwnerID        int64   `json:"ownerId" example:"1"`
+	Title          string  `json:"title" example:"Trip to Japan"`
+	TitleHighlight string  `json:"titleHighlight" example:"Trip to <mark>Japan</mark> &amp; Korea"`
+	Snippet        string  `json:"snippet" example:"...a <mark>Kyoto</mark> <mark>food</mark> tour in Nishiki market..."`
+	Score          float64 `json:"score" example:"7.25"`
+}
+
+var InitItinerarySearchDocument = func() *ItinerarySearchDocument {
+	return InitItinerarySearchDocumentFunctions(&ItinerarySearchDocument{})
+}
+
+var InitItinerarySearchDocumentFunctions = func(document *ItinerarySearchDocument) *ItinerarySearchDocument {
+	// Set default SQLite FTS5 implementations for Save, DeleteByItineraryIdTx, DeleteByOwnerIdTx and Search. Search is only supported on
+	// SQLite: other DB systems, like PostgreSQL with tsvector columns, would need their own implementations and are out of scope for now
+	document.Save = document.defaultSave
+	document.DeleteByItineraryIdTx = document.defaultDeleteByItineraryIdTx
+	document.DeleteByOwnerIdTx = document.defaultDeleteByOwnerIdTx
+	document.Search = document.defaultSearch
+
+	return document
+}
+
+// defaultSave replaces the indexed content of the itinerary
+func (sd *ItinerarySearchDocument) defaultSave() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for itinerary search document: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	err = sd.defaultDeleteByItineraryIdTx(sd.ItineraryID, tx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO itinerary_search(rowid, title, description, notes, destinations, content, owner_id) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for itinerary search document: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(sd.ItineraryID, ftsHighlightRemover.Replace(sd.Title), ftsHighlightRemover.Replace(sd.Description),
+		ftsHighlightRemover.Replace(sd.Notes), ftsHighlightRemover.Replace(sd.Destinations), ftsHighlightRemover.Replace(sd.Content),
+		sd.OwnerID)
+	if err != nil {
+		log.Errorf("Error executing insert for search document of itinerary %d: %v", sd.ItineraryID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (sd *ItinerarySearchDocument) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_search WHERE rowid = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for search document of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for search document of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the search documents of all the itineraries of an owner
+func (sd *ItinerarySearchDocument) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_search WHERE owner_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary search documents of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for itinerary search documents of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultSearch ranks the matching itineraries with BM25, weighting the title and destinations above the description and notes, and
+// those above the generated document
+func (sd *ItinerarySearchDocument) defaultSearch(filter ItinerarySearchFilter) ([]*ItinerarySearchResult, error) {
+	query := `SELECT rowid, owner_id, title,
+	highlight(itinerary_search, 0, char(2), char(3)),
+	snippet(itinerary_search, -1, char(2), char(3), '...', 16),
+	-bm25(itinerary_search, 10.0, 4.0, 2.0, 8.0, 1.0) AS score
+	FROM itinerary_search
+	WHERE itinerary_search MATCH ? AND (owner_id = ? OR rowid IN (SELECT itinerary_id FROM itinerary_shares WHERE user_id = ?))
+	ORDER BY score DESC, rowid DESC LIMIT ? OFFSET ?`
+
+	rows, err := db.DB.Query(query, ftsMatchExpression(filter.Terms), filter.UserID, filter.UserID, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Errorf("Error searching itineraries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*ItinerarySearchResult{}
+	for rows.Next() {
+		result := &ItinerarySearchResult{}
+		err := rows.Scan(&result.ItineraryID, &result.OwnerID, &result.Title, &result.TitleHighlight, &result.Snippet, &result.Score)
+		if err != nil {
+			log.Errorf("Error scanning itinerary search row: %v", err)
+			return nil, err
+		}
+		result.TitleHighlight = escapeSearchHighlight(result.TitleHighlight)
+		result.Snippet = escapeSearchHighlight(result.Snippet)
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary search rows: %v", err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// escapeSearchHighlight HTML-escapes a text highlighted by FTS5, so the indexed texts cannot inject markup, and then replaces the
+// FTS5 markers with SearchHighlightStart and SearchHighlightEnd
+func escapeSearchHighlight(text string) string {
+	return strings.NewReplacer(ftsHighlightStart, SearchHighlightStart, ftsHighlightEnd, SearchHighlightEnd).Replace(html.EscapeString(text))
+}
+
+// ftsMatchExpression quotes every term as an FTS5 string followed by the prefix operator, so user input cannot inject FTS5 query
+// syntax. Terms are implicitly combined with AND
+func ftsMatchExpression(terms []string) string {
+	quotedTerms := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quotedTerms = append(quotedTerms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	return strings.Join(quotedTerms, " ")
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItinerarySearchDocument_Save_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE rowid = \\?").
+		ExpectExec().WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO itinerary_search").
+		ExpectExec().WithArgs(int64(2), "Japan", "Spring trip", "Street food", "Kyoto, Japan", "Day 1", int64(1)).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	document := InitItinerarySearchDocument()
+	document.ItineraryID = 2
+	document.OwnerID = 1
+	document.Title = "Japan"
+	document.Description = "Spring trip"
+	document.Notes = "Street food"
+	document.Destinations = "Kyoto, Japan"
+	document.Content = "Day\x02 1\x03"
+
+	assert.NoError(t, document.Save())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItinerarySearchDocument_Save_InsertError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE rowid = \\?").
+		ExpectExec().WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("INSERT INTO itinerary_search").
+		ExpectExec().WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
+
+	document := InitItinerarySearchDocument()
+	document.ItineraryID = 2
+
+	assert.EqualError(t, document.Save(), "insert error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItinerarySearchDocument_Search_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_search WHERE itinerary_search MATCH \\? (.+) LIMIT \\? OFFSET \\?").
+		WithArgs(`"kyoto"* "food"*`, int64(1), int64(1), 21, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"rowid", "owner_id", "title", "highlight", "snippet", "score"}).
+			AddRow(2, 1, "Kyoto <b>", "\x02Kyoto\x03 <b>", "a \x02food\x03 tour <script>alert('x')</script>", 7.5))
+
+	results, err := InitItinerarySearchDocument().Search(ItinerarySearchFilter{UserID: 1, Terms: []string{"kyoto", "food"}, Offset: 20, Limit: 21})
+	assert.NoError(t, err)
+	assert.Equal(t, []*ItinerarySearchResult{{ItineraryID: 2, OwnerID: 1, Title: "Kyoto <b>", TitleHighlight: "<mark>Kyoto</mark> &lt;b&gt;",
+		Snippet: "a <mark>food</mark> tour &lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;", Score: 7.5}}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFtsMatchExpression(t *testing.T) {
+	assert.Equal(t, `"kyoto"*`, ftsMatchExpression([]string{"kyoto"}))
+	assert.Equal(t, `"say ""hi"""* "OR"* "NEAR(a"*`, ftsMatchExpression([]string{`say "hi"`, "OR", "NEAR(a"}))
+}
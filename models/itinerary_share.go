@@ -0,0 +1,269 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Permission levels of a user on an itinerary. The owner is implicit (itineraries.owner_id), while viewers and editors are
+// granted through itinerary shares
+const (
+	ItineraryPermissionViewer = "viewer"
+	ItineraryPermissionEditor = "editor"
+	ItineraryPermissionOwner  = "owner"
+)
+
+// ItineraryShareRoles lists the permissions that can be granted to other users
+var ItineraryShareRoles = []string{ItineraryPermissionViewer, ItineraryPermissionEditor}
+
+// ItineraryPermissionLevel ranks the permissions, so a higher level includes all the lower ones. Unknown permissions rank 0
+func ItineraryPermissionLevel(permission string) int {
+	switch permission {
+	case ItineraryPermissionViewer:
+		return 1
+	case ItineraryPermissionEditor:
+		return 2
+	case ItineraryPermissionOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ItineraryShare grants a registered user other than the owner access to an itinerary
+type ItineraryShare struct {
+	ID           int64      `json:"id" example:"1"`
+	ItineraryID  int64      `json:"itineraryId" example:"1"`
+	UserID       int64      `json:"userId" example:"2"`
+	UserEmail    string     `json:"userEmail,omitempty" example:"friend@example.com"`
+	Permission   string     `json:"permission" example:"editor"`
+	CreationDate *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	UpdateDate   *time.Time `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	Itinerary    *Itinerary `json:"itinerary,omitempty"`
+
+	FindByItineraryAndUserId func(itineraryId int64, userId int64) (*ItineraryShare, error) `json:"-"`
+	FindByItineraryId        func(itineraryId int64) ([]*ItineraryShare, error)             `json:"-"`
+	FindByUserId             func(userId int64) ([]*ItineraryShare, error)                  `json:"-"`
+	Save                     func() error                                                   `json:"-"`
+	Delete                   func() error                                                   `json:"-"`
+	DeleteByItineraryIdTx    func(itineraryId int64, tx *sql.Tx) error                      `json:"-"`
+	DeleteByOwnerIdTx        func(ownerId int64, tx *sql.Tx) error                          `json:"-"`
+	DeleteByUserIdTx         func(userId int64, tx *sql.Tx) error                           `json:"-"`
+}
+
+var InitItineraryShare = func() *ItineraryShare {
+	return InitItineraryShareFunctions(&ItineraryShare{})
+}
+
+var InitItineraryShareFunctions = func(share *ItineraryShare) *ItineraryShare {
+	// Set default SQL implementations for FindByItineraryAndUserId, FindByItineraryId, FindByUserId, Save, Delete, DeleteByItineraryIdTx,
+	// DeleteByOwnerIdTx and DeleteByUserIdTx. In the future there could be implementations for other NoSQL DB systems like MongoDB
+	share.FindByItineraryAndUserId = share.defaultFindByItineraryAndUserId
+	share.FindByItineraryId = share.defaultFindByItineraryId
+	share.FindByUserId = share.defaultFindByUserId
+	share.Save = share.defaultSave
+	share.Delete = share.defaultDelete
+	share.DeleteByItineraryIdTx = share.defaultDeleteByItineraryIdTx
+	share.DeleteByOwnerIdTx = share.defaultDeleteByOwnerIdTx
+	share.DeleteByUserIdTx = share.defaultDeleteByUserIdTx
+
+	return share
+}
+
+var NewItineraryShare = func(itineraryId int64, userId int64, permission string) *ItineraryShare {
+	share := &ItineraryShare{
+		ItineraryID: itineraryId,
+		UserID:      userId,
+		Permission:  permission,
+	}
+
+	return InitItineraryShareFunctions(share)
+}
+
+func (s *ItineraryShare) defaultFindByItineraryAndUserId(itineraryId int64, userId int64) (*ItineraryShare, error) {
+	query := `SELECT s.id, s.itinerary_id, s.user_id, u.email, s.permission, s.creation_date, s.update_date
+	FROM itinerary_shares s JOIN users u ON u.id = s.user_id
+	WHERE s.itinerary_id = ? AND s.user_id = ?`
+	row := db.DB.QueryRow(query, itineraryId, userId)
+
+	share := &ItineraryShare{}
+	err := row.Scan(&share.ID, &share.ItineraryID, &share.UserID, &share.UserEmail, &share.Permission, &share.CreationDate, &share.UpdateDate)
+	if err != nil {
+		log.Errorf("Error fetching share of itinerary %d with user %d: %v", itineraryId, userId, err)
+		return nil, err
+	}
+
+	return share, nil
+}
+
+func (s *ItineraryShare) defaultFindByItineraryId(itineraryId int64) ([]*ItineraryShare, error) {
+	query := `SELECT s.id, s.itinerary_id, s.user_id, u.email, s.permission, s.creation_date, s.update_date
+	FROM itinerary_shares s JOIN users u ON u.id = s.user_id
+	WHERE s.itinerary_id = ? ORDER BY s.creation_date ASC, s.id ASC`
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error querying shares of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []*ItineraryShare{}
+	for rows.Next() {
+		share := &ItineraryShare{}
+		err := rows.Scan(&share.ID, &share.ItineraryID, &share.UserID, &share.UserEmail, &share.Permission, &share.CreationDate, &share.UpdateDate)
+		if err != nil {
+			log.Errorf("Error scanning itinerary share row: %v", err)
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary share rows: %v", err)
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// defaultFindByUserId retrieves the shares granted to a user, together with the itineraries they give access to (without destinations)
+func (s *ItineraryShare) defaultFindByUserId(userId int64) ([]*ItineraryShare, error) {
+	query := `SELECT s.id, s.itinerary_id, s.user_id, s.permission, s.creation_date, s.update_date,
+	i.title, i.description, i.notes, i.owner_id, i.creation_date, i.update_date, i.version
+	FROM itinerary_shares s JOIN itineraries i ON i.id = s.itinerary_id
+	WHERE s.user_id = ? ORDER BY s.creation_date DESC, s.id DESC`
+	rows, err := db.DB.Query(query, userId)
+	if err != nil {
+		log.Errorf("Error querying shares of user %d: %v", userId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []*ItineraryShare{}
+	for rows.Next() {
+		share := &ItineraryShare{Itinerary: &Itinerary{}}
+		err := rows.Scan(&share.ID, &share.ItineraryID, &share.UserID, &share.Permission, &share.CreationDate, &share.UpdateDate,
+			&share.Itinerary.Title, &share.Itinerary.Description, &share.Itinerary.Notes, &share.Itinerary.OwnerID,
+			&share.Itinerary.CreationDate, &share.Itinerary.UpdateDate, &share.Itinerary.Version)
+		if err != nil {
+			log.Errorf("Error scanning itinerary share row: %v", err)
+			return nil, err
+		}
+		share.Itinerary.ID = share.ItineraryID
+		shares = append(shares, share)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary share rows: %v", err)
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// defaultSave grants the permission to the user, replacing the previous one if the itinerary was already shared with them
+func (s *ItineraryShare) defaultSave() error {
+	query := `INSERT INTO itinerary_shares(itinerary_id, user_id, permission, creation_date, update_date)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (itinerary_id, user_id) DO UPDATE SET permission = excluded.permission, update_date = excluded.update_date`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing upsert for itinerary share: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(s.ItineraryID, s.UserID, s.Permission, now, now)
+	if err != nil {
+		log.Errorf("Error executing upsert for share of itinerary %d with user %d: %v", s.ItineraryID, s.UserID, err)
+		return err
+	}
+
+	s.UpdateDate = &now
+
+	return nil
+}
+
+func (s *ItineraryShare) defaultDelete() error {
+	query := `DELETE FROM itinerary_shares WHERE itinerary_id = ? AND user_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary share: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(s.ItineraryID, s.UserID)
+	if err != nil {
+		log.Errorf("Error executing delete for share of itinerary %d with user %d: %v", s.ItineraryID, s.UserID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *ItineraryShare) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_shares WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for shares of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for shares of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the shares of all the itineraries of an owner
+func (s *ItineraryShare) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_shares WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary shares of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for itinerary shares of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByUserIdTx deletes the shares granted to a user
+func (s *ItineraryShare) defaultDeleteByUserIdTx(userId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_shares WHERE user_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary shares of user %d: %v", userId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(userId)
+	if err != nil {
+		log.Errorf("Error executing delete for itinerary shares of user %d: %v", userId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var itineraryShareTestColumns = []string{"id", "itinerary_id", "user_id", "email", "permission", "creation_date", "update_date"}
+
+func TestItineraryPermissionLevel(t *testing.T) {
+	assert.Less(t, ItineraryPermissionLevel(""), ItineraryPermissionLevel(ItineraryPermissionViewer))
+	assert.Less(t, ItineraryPermissionLevel(ItineraryPermissionViewer), ItineraryPermissionLevel(ItineraryPermissionEditor))
+	assert.Less(t, ItineraryPermissionLevel(ItineraryPermissionEditor), ItineraryPermissionLevel(ItineraryPermissionOwner))
+	assert.Equal(t, 0, ItineraryPermissionLevel("admin"))
+}
+
+func TestItineraryShare_FindByItineraryAndUserId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_shares s JOIN users u ON u.id = s.user_id WHERE s.itinerary_id = \\? AND s.user_id = \\?").
+		WithArgs(int64(1), int64(3)).
+		WillReturnRows(sqlmock.NewRows(itineraryShareTestColumns).AddRow(7, 1, 3, "friend@example.com", "editor", now, now))
+
+	share, err := InitItineraryShare().FindByItineraryAndUserId(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), share.ID)
+	assert.Equal(t, "friend@example.com", share.UserEmail)
+	assert.Equal(t, ItineraryPermissionEditor, share.Permission)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_FindByItineraryAndUserId_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_shares").
+		WithArgs(int64(1), int64(3)).
+		WillReturnRows(sqlmock.NewRows(itineraryShareTestColumns))
+
+	share, err := InitItineraryShare().FindByItineraryAndUserId(1, 3)
+	assert.Nil(t, share)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_FindByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_shares s JOIN users u ON u.id = s.user_id WHERE s.itinerary_id = \\?").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(itineraryShareTestColumns).
+			AddRow(7, 1, 3, "friend@example.com", "editor", now, now).
+			AddRow(8, 1, 4, "family@example.com", "viewer", now, now))
+
+	shares, err := InitItineraryShare().FindByItineraryId(1)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 2)
+	assert.Equal(t, "family@example.com", shares[1].UserEmail)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_FindByItineraryId_QueryError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_shares").WillReturnError(errors.New("db error"))
+
+	shares, err := InitItineraryShare().FindByItineraryId(1)
+	assert.Nil(t, shares)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_FindByUserId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_shares s JOIN itineraries i ON i.id = s.itinerary_id WHERE s.user_id = \\?").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "user_id", "permission", "creation_date", "update_date",
+			"title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+			AddRow(7, 1, 3, "viewer", now, now, "Trip to Spain", "Summer", nil, 2, now, now, 4))
+
+	shares, err := InitItineraryShare().FindByUserId(3)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 1)
+	assert.Equal(t, int64(1), shares[0].Itinerary.ID)
+	assert.Equal(t, "Trip to Spain", shares[0].Itinerary.Title)
+	assert.Equal(t, int64(2), shares[0].Itinerary.OwnerID)
+	assert.Equal(t, int64(4), shares[0].Itinerary.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_Save_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("INSERT INTO itinerary_shares(.+) ON CONFLICT \\(itinerary_id, user_id\\) DO UPDATE").
+		ExpectExec().
+		WithArgs(int64(1), int64(3), "editor", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	share := NewItineraryShare(1, 3, ItineraryPermissionEditor)
+	err = share.Save()
+	assert.NoError(t, err)
+	assert.NotNil(t, share.UpdateDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_Save_ExecError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("INSERT INTO itinerary_shares").
+		ExpectExec().
+		WillReturnError(errors.New("db error"))
+
+	err = NewItineraryShare(1, 3, ItineraryPermissionViewer).Save()
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_Delete_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id = \\? AND user_id = \\?").
+		ExpectExec().
+		WithArgs(int64(1), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewItineraryShare(1, 3, ItineraryPermissionViewer).Delete()
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryShare_DeleteByItineraryIdTx_PrepareError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id = \\?").WillReturnError(errors.New("prepare error"))
+
+	tx, err := db.DB.Begin()
+	assert.NoError(t, err)
+
+	err = InitItineraryShare().DeleteByItineraryIdTx(1, tx)
+	assert.EqualError(t, err, "prepare error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
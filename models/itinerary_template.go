@@ -0,0 +1,314 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// ItineraryTemplate is a reusable route without dates, like a yearly offsite, that itineraries are created from for a start date. Its
+// destinations are placed by the day of the trip they are arrived at. Shared templates can be used by every user, but only their
+// owner can delete them
+type ItineraryTemplate struct {
+	ID           int64                           `json:"id" example:"1"`
+	Title        string                          `json:"title" example:"Corporate offsite"`
+	Description  string                          `json:"description" example:"Yearly team offsite in Portugal"`
+	Notes        *string                         `json:"notes,omitempty" example:"Book the team dinner in advance"`
+	OwnerID      int64                           `json:"ownerId" example:"1"`
+	Shared       bool                            `json:"shared" example:"true"`
+	Destinations []*ItineraryTemplateDestination `json:"destinations"`
+	CreationDate *time.Time                      `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindById          func(id int64) (*ItineraryTemplate, error)        `json:"-"`
+	FindAvailable     func(userId int64) ([]*ItineraryTemplate, error)  `json:"-"`
+	FindByOwnerId     func(ownerId int64) ([]*ItineraryTemplate, error) `json:"-"`
+	Create            func() error                                      `json:"-"`
+	Delete            func() error                                      `json:"-"`
+	DeleteByOwnerIdTx func(ownerId int64, tx *sql.Tx) error             `json:"-"`
+}
+
+// ItineraryTemplateDestination is a destination of a template. StartDay is the day of the trip it is arrived at, 0 being the start
+// date, and Days the number of days until it is departed from
+type ItineraryTemplateDestination struct {
+	Country  string `json:"country" example:"Portugal"`
+	City     string `json:"city" example:"Lisbon"`
+	StartDay int    `json:"startDay" example:"0"`
+	Days     int    `json:"days" example:"3"`
+}
+
+var InitItineraryTemplate = func() *ItineraryTemplate {
+	return InitItineraryTemplateFunctions(&ItineraryTemplate{})
+}
+
+var InitItineraryTemplateFunctions = func(template *ItineraryTemplate) *ItineraryTemplate {
+	// Set default SQL implementations for FindById, FindAvailable, FindByOwnerId, Create, Delete and DeleteByOwnerIdTx. In the future
+	// there could be implementations for other NoSQL DB systems like MongoDB
+	template.FindById = template.defaultFindById
+	template.FindAvailable = template.defaultFindAvailable
+	template.FindByOwnerId = template.defaultFindByOwnerId
+	template.Create = template.defaultCreate
+	template.Delete = template.defaultDelete
+	template.DeleteByOwnerIdTx = template.defaultDeleteByOwnerIdTx
+
+	return template
+}
+
+// NewItineraryTemplateFromItinerary takes the route of the itinerary as a template, placing its destinations by the whole days since
+// the arrival at the first one
+var NewItineraryTemplateFromItinerary = func(itinerary *Itinerary) *ItineraryTemplate {
+	template := &ItineraryTemplate{
+		Title:        itinerary.Title,
+		Description:  itinerary.Description,
+		Notes:        itinerary.Notes,
+		Destinations: []*ItineraryTemplateDestination{},
+	}
+
+	var start time.Time
+	for idx, destination := range itinerary.TravelDestinations {
+		if idx == 0 || destination.ArrivalDate.Before(start) {
+			start = destination.ArrivalDate
+		}
+	}
+
+	for _, destination := range itinerary.TravelDestinations {
+		template.Destinations = append(template.Destinations, &ItineraryTemplateDestination{
+			Country:  destination.Country,
+			City:     destination.City,
+			StartDay: wholeDays(destination.ArrivalDate.Sub(start)),
+			Days:     wholeDays(destination.DepartureDate.Sub(destination.ArrivalDate)),
+		})
+	}
+
+	return InitItineraryTemplateFunctions(template)
+}
+
+// wholeDays rounds the duration to the nearest number of days
+func wholeDays(duration time.Duration) int {
+	return int((duration + 12*time.Hour) / (24 * time.Hour))
+}
+
+// TravelDestinations places the destinations of the template on the calendar, starting on the start date
+func (t *ItineraryTemplate) TravelDestinations(startDate time.Time) []*ItineraryTravelDestination {
+	destinations := []*ItineraryTravelDestination{}
+	for _, destination := range t.Destinations {
+		arrivalDate := startDate.AddDate(0, 0, destination.StartDay)
+		destinations = append(destinations, NewItineraryTravelDestination(destination.Country, destination.City, arrivalDate,
+			arrivalDate.AddDate(0, 0, destination.Days)))
+	}
+	return destinations
+}
+
+const itineraryTemplateColumns = `id, title, description, notes, owner_id, shared, creation_date`
+
+// defaultFindById retrieves a template with its destinations
+func (t *ItineraryTemplate) defaultFindById(id int64) (*ItineraryTemplate, error) {
+	query := `SELECT ` + itineraryTemplateColumns + ` FROM itinerary_templates WHERE id = ?`
+
+	template, err := scanItineraryTemplate(db.DB.QueryRow(query, id))
+	if err != nil {
+		log.Errorf("Error fetching itinerary template %d: %v", id, err)
+		return nil, err
+	}
+
+	err = template.findDestinations()
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// defaultFindAvailable retrieves the templates of the user and the ones shared by other users, with their destinations, sorted by title
+func (t *ItineraryTemplate) defaultFindAvailable(userId int64) ([]*ItineraryTemplate, error) {
+	query := `SELECT ` + itineraryTemplateColumns + ` FROM itinerary_templates WHERE owner_id = ? OR shared = TRUE ORDER BY title, id`
+	return queryItineraryTemplates(query, userId)
+}
+
+// defaultFindByOwnerId retrieves the templates of the owner, with their destinations, sorted by title
+func (t *ItineraryTemplate) defaultFindByOwnerId(ownerId int64) ([]*ItineraryTemplate, error) {
+	query := `SELECT ` + itineraryTemplateColumns + ` FROM itinerary_templates WHERE owner_id = ? ORDER BY title, id`
+	return queryItineraryTemplates(query, ownerId)
+}
+
+func queryItineraryTemplates(query string, args ...any) ([]*ItineraryTemplate, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Errorf("Error querying itinerary templates: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*ItineraryTemplate{}
+	for rows.Next() {
+		template, err := scanItineraryTemplate(rows)
+		if err != nil {
+			log.Errorf("Error scanning itinerary template row: %v", err)
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary template rows: %v", err)
+		return nil, err
+	}
+
+	// Destinations are fetched once the rows are closed, so the listing does not hold two connections
+	rows.Close()
+	for _, template := range templates {
+		err = template.findDestinations()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return templates, nil
+}
+
+type itineraryTemplateScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItineraryTemplate(scanner itineraryTemplateScanner) (*ItineraryTemplate, error) {
+	template := &ItineraryTemplate{}
+	err := scanner.Scan(&template.ID, &template.Title, &template.Description, &template.Notes, &template.OwnerID, &template.Shared,
+		&template.CreationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (t *ItineraryTemplate) findDestinations() error {
+	query := `SELECT country, city, start_day, days FROM itinerary_template_destinations WHERE template_id = ? ORDER BY position ASC`
+
+	rows, err := db.DB.Query(query, t.ID)
+	if err != nil {
+		log.Errorf("Error querying destinations of itinerary template %d: %v", t.ID, err)
+		return err
+	}
+	defer rows.Close()
+
+	t.Destinations = []*ItineraryTemplateDestination{}
+	for rows.Next() {
+		destination := &ItineraryTemplateDestination{}
+		err := rows.Scan(&destination.Country, &destination.City, &destination.StartDay, &destination.Days)
+		if err != nil {
+			log.Errorf("Error scanning itinerary template destination row: %v", err)
+			return err
+		}
+		t.Destinations = append(t.Destinations, destination)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating itinerary template destination rows: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultCreate saves the template with its destinations, in the order they have
+func (t *ItineraryTemplate) defaultCreate() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for itinerary template creation: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	now := time.Now()
+	query := `INSERT INTO itinerary_templates(title, description, notes, owner_id, shared, creation_date) VALUES (?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(query, t.Title, t.Description, t.Notes, t.OwnerID, t.Shared, now)
+	if err != nil {
+		log.Errorf("Error executing insert for itinerary template: %v", err)
+		return err
+	}
+
+	t.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for itinerary template: %v", err)
+		return err
+	}
+
+	queryDestination := `INSERT INTO itinerary_template_destinations(template_id, position, country, city, start_day, days)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	stmt, err := tx.Prepare(queryDestination)
+	if err != nil {
+		log.Errorf("Error preparing insert for itinerary template destinations: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	for position, destination := range t.Destinations {
+		_, err = stmt.Exec(t.ID, position, destination.Country, destination.City, destination.StartDay, destination.Days)
+		if err != nil {
+			log.Errorf("Error executing insert for destination of itinerary template %d: %v", t.ID, err)
+			return err
+		}
+	}
+
+	t.CreationDate = &now
+	return nil
+}
+
+// defaultDelete deletes the template with its destinations. Returns sql.ErrNoRows if there is no such template. The itineraries
+// created from it are kept
+func (t *ItineraryTemplate) defaultDelete() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for itinerary template deletion: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	_, err = tx.Exec(`DELETE FROM itinerary_template_destinations WHERE template_id = ?`, t.ID)
+	if err != nil {
+		log.Errorf("Error deleting destinations of itinerary template %d: %v", t.ID, err)
+		return err
+	}
+
+	result, err := tx.Exec(`DELETE FROM itinerary_templates WHERE id = ?`, t.ID)
+	if err != nil {
+		log.Errorf("Error deleting itinerary template %d: %v", t.ID, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting rows affected for itinerary template %d: %v", t.ID, err)
+		return err
+	}
+	if rowsAffected == 0 {
+		err = sql.ErrNoRows
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the templates of an owner with their destinations, including the shared ones
+func (t *ItineraryTemplate) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	_, err := tx.Exec(`DELETE FROM itinerary_template_destinations
+	WHERE template_id IN (SELECT id FROM itinerary_templates WHERE owner_id = ?)`, ownerId)
+	if err != nil {
+		log.Errorf("Error deleting itinerary template destinations of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM itinerary_templates WHERE owner_id = ?`, ownerId)
+	if err != nil {
+		log.Errorf("Error deleting itinerary templates of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
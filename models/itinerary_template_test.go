@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var itineraryTemplateTestColumns = []string{"id", "title", "description", "notes", "owner_id", "shared", "creation_date"}
+
+func TestNewItineraryTemplateFromItinerary(t *testing.T) {
+	start := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	itinerary := &Itinerary{Title: "Offsite", Description: "Team offsite", TravelDestinations: []*ItineraryTravelDestination{
+		NewItineraryTravelDestination("Portugal", "Porto", start.AddDate(0, 0, 3), start.AddDate(0, 0, 5).Add(2*time.Hour)),
+		NewItineraryTravelDestination("Portugal", "Lisbon", start, start.AddDate(0, 0, 3)),
+	}}
+
+	template := NewItineraryTemplateFromItinerary(itinerary)
+	assert.Equal(t, "Offsite", template.Title)
+	assert.Equal(t, "Team offsite", template.Description)
+	assert.Equal(t, []*ItineraryTemplateDestination{
+		{Country: "Portugal", City: "Porto", StartDay: 3, Days: 2},
+		{Country: "Portugal", City: "Lisbon", StartDay: 0, Days: 3},
+	}, template.Destinations)
+
+	destinations := template.TravelDestinations(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, destinations, 2)
+	assert.Equal(t, time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC), destinations[0].ArrivalDate)
+	assert.Equal(t, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), destinations[0].DepartureDate)
+	assert.Equal(t, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), destinations[1].ArrivalDate)
+}
+
+func TestItineraryTemplate_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO itinerary_templates\(title, description, notes, owner_id, shared, creation_date\)`).
+		WithArgs("Offsite", "Team offsite", nil, int64(3), true, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	prepare := mock.ExpectPrepare("INSERT INTO itinerary_template_destinations")
+	prepare.ExpectExec().WithArgs(int64(5), 0, "Portugal", "Lisbon", 0, 3).WillReturnResult(sqlmock.NewResult(1, 1))
+	prepare.ExpectExec().WithArgs(int64(5), 1, "Portugal", "Porto", 3, 2).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	template := InitItineraryTemplate()
+	template.Title = "Offsite"
+	template.Description = "Team offsite"
+	template.OwnerID = 3
+	template.Shared = true
+	template.Destinations = []*ItineraryTemplateDestination{
+		{Country: "Portugal", City: "Lisbon", StartDay: 0, Days: 3},
+		{Country: "Portugal", City: "Porto", StartDay: 3, Days: 2},
+	}
+
+	assert.NoError(t, template.Create())
+	assert.Equal(t, int64(5), template.ID)
+	assert.NotNil(t, template.CreationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryTemplate_FindById_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_templates WHERE id = \\?").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(itineraryTemplateTestColumns).AddRow(5, "Offsite", "Team offsite", nil, 3, true, now))
+	mock.ExpectQuery("SELECT country, city, start_day, days FROM itinerary_template_destinations WHERE template_id = \\? ORDER BY position").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"country", "city", "start_day", "days"}).
+			AddRow("Portugal", "Lisbon", 0, 3).
+			AddRow("Portugal", "Porto", 3, 2))
+
+	template, err := InitItineraryTemplate().FindById(5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Offsite", template.Title)
+	assert.True(t, template.Shared)
+	assert.Len(t, template.Destinations, 2)
+	assert.Equal(t, "Porto", template.Destinations[1].City)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryTemplate_FindAvailable_IncludesShared(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_templates WHERE owner_id = \\? OR shared = TRUE ORDER BY title, id").
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(itineraryTemplateTestColumns).AddRow(5, "Offsite", "Team offsite", nil, 3, true, time.Now()))
+	mock.ExpectQuery("SELECT country, city, start_day, days FROM itinerary_template_destinations").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"country", "city", "start_day", "days"}).AddRow("Portugal", "Lisbon", 0, 3))
+
+	templates, err := InitItineraryTemplate().FindAvailable(4)
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1)
+	assert.Equal(t, int64(3), templates[0].OwnerID)
+	assert.Len(t, templates[0].Destinations, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryTemplate_Delete_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM itinerary_template_destinations WHERE template_id = \\?").
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM itinerary_templates WHERE id = \\?").
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	template := InitItineraryTemplateFunctions(&ItineraryTemplate{ID: 5})
+	assert.ErrorIs(t, template.Delete(), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
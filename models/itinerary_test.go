@@ -21,22 +21,35 @@ func TestItineraryDefaultFindById_WithDestinationsSuccess(t *testing.T) {
 	itinerary := &Itinerary{}
 
 	// Mock main itinerary row
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE id = \\?").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-				AddRow(1, "Test Title", "Test Description", "A test trip", 2, time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+				AddRow(1, "Test Title", "Test Description", "A test trip", 2, time.Now(), time.Now().Add(2*time.Hour), 1),
 		)
 
 	// Mock travel destinations rows
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
-				AddRow(10, "Country1", "City1", 1, now, now.Add(12*time.Hour), time.Now(), time.Now().Add(2*time.Hour)).
-				AddRow(11, "Country2", "City2", 1, now.Add(12*time.Hour), now.Add(24*time.Hour), time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
+				AddRow(10, "Country1", "City1", nil, nil, nil, nil, 1, now, now.Add(12*time.Hour), time.Now(), time.Now().Add(2*time.Hour)).
+				AddRow(11, "Country2", "City2", nil, nil, nil, nil, 1, now.Add(12*time.Hour), now.Add(24*time.Hour), time.Now(), time.Now().Add(2*time.Hour)),
 		)
 
+	// Mock transport legs rows, the first one no longer connecting consecutive destinations
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_transport_legs WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "from_country", "from_city", "to_country", "to_city", "mode", "carrier", "booking_reference", "departure_time", "arrival_time", "notes", "creation_date", "update_date"}).
+			AddRow(5, 1, "country2", "city2", "Country1", "City1", "train", "", "", nil, nil, "", now, now).
+			AddRow(6, 1, "country1", "city1", "Country2", "City2", "flight", "Iberia", "X7K2P9", now.Add(11*time.Hour), now.Add(12*time.Hour), "", now, now))
+
+	// Mock accommodations rows, in the first destination
+	mock.ExpectQuery("SELECT (.+) FROM destination_accommodations WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "country", "city", "name", "address", "check_in", "check_out", "confirmation_number", "cost", "currency", "creation_date", "update_date"}).
+			AddRow(3, 1, "country1", "city1", "Hotel", "", now.UTC(), now.Add(12*time.Hour), "", nil, "", now, now))
+
 	it, err := itinerary.defaultFindById(1, true)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), it.ID)
@@ -48,6 +61,13 @@ func TestItineraryDefaultFindById_WithDestinationsSuccess(t *testing.T) {
 	assert.Equal(t, int64(10), (it.TravelDestinations)[0].ID)
 	assert.Equal(t, "Country1", (it.TravelDestinations)[0].Country)
 	assert.Equal(t, "City1", (it.TravelDestinations)[0].City)
+	assert.Len(t, it.TransportLegs, 2)
+	assert.Equal(t, int64(6), it.TransportLegs[0].ID)
+	assert.Equal(t, int64(10), *it.TransportLegs[0].FromDestinationID)
+	assert.Equal(t, int64(11), *it.TransportLegs[0].ToDestinationID)
+	assert.Nil(t, it.TransportLegs[1].FromDestinationID)
+	assert.Len(t, it.Accommodations, 1)
+	assert.Equal(t, int64(10), *it.Accommodations[0].DestinationID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -60,11 +80,11 @@ func TestItineraryDefaultFindById_WithoutDestinationsSuccess(t *testing.T) {
 	itinerary := &Itinerary{}
 
 	// Mock main itinerary row
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE id = \\?").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-				AddRow(1, "Test Title", "Test Description", "A test trip", 2, time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+				AddRow(1, "Test Title", "Test Description", "A test trip", 2, time.Now(), time.Now().Add(2*time.Hour), 1),
 		)
 
 	it, err := itinerary.defaultFindById(1, false)
@@ -88,22 +108,30 @@ func TestItineraryDefaultFindById_WithDestinationsSuccessNullNotes(t *testing.T)
 	itinerary := &Itinerary{}
 
 	// Mock main itinerary row
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE id = \\?").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-				AddRow(1, "Test Title", "Test Description", nil, 2, time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+				AddRow(1, "Test Title", "Test Description", nil, 2, time.Now(), time.Now().Add(2*time.Hour), 1),
 		)
 
 	// Mock travel destinations rows
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
-				AddRow(10, "Country1", "City1", 1, now, now.Add(12*time.Hour), time.Now(), time.Now().Add(2*time.Hour)).
-				AddRow(11, "Country2", "City2", 1, now.Add(12*time.Hour), now.Add(24*time.Hour), time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
+				AddRow(10, "Country1", "City1", nil, nil, nil, nil, 1, now, now.Add(12*time.Hour), time.Now(), time.Now().Add(2*time.Hour)).
+				AddRow(11, "Country2", "City2", nil, nil, nil, nil, 1, now.Add(12*time.Hour), now.Add(24*time.Hour), time.Now(), time.Now().Add(2*time.Hour)),
 		)
 
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_transport_legs WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "from_country", "from_city", "to_country", "to_city", "mode", "carrier", "booking_reference", "departure_time", "arrival_time", "notes", "creation_date", "update_date"}))
+
+	mock.ExpectQuery("SELECT (.+) FROM destination_accommodations WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
 	it, err := itinerary.defaultFindById(1, true)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), it.ID)
@@ -126,7 +154,7 @@ func TestItineraryDefaultFindById_RowScanError(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE id = \\?").
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
@@ -144,14 +172,14 @@ func TestItineraryDefaultFindById_DestinationsQueryError(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE id = \\?").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-				AddRow(1, "Test Title", "Test Description", nil, 2, time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+				AddRow(1, "Test Title", "Test Description", nil, 2, time.Now(), time.Now().Add(2*time.Hour), 1),
 		)
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnError(sql.ErrConnDone)
 
@@ -170,20 +198,20 @@ func TestItineraryDefaultFindById_DestinationScanError(t *testing.T) {
 	now := time.Now()
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE id = \\?").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-				AddRow(1, "Test Title", "Test Description", nil, 2, time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+				AddRow(1, "Test Title", "Test Description", nil, 2, time.Now(), time.Now().Add(2*time.Hour), 1),
 		)
 
 	// Return a row with a wrong type to force scan error
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
-				AddRow(10, "Country1", "City1", 1, now, now.Add(12*time.Hour), time.Now(), time.Now().Add(2*time.Hour)).
-				AddRow("not-an-int", "Country2", "City2", 1, now.Add(12*time.Hour), now.Add(24*time.Hour), time.Now(), time.Now().Add(2*time.Hour)),
+			sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
+				AddRow(10, "Country1", "City1", nil, nil, nil, nil, 1, now, now.Add(12*time.Hour), time.Now(), time.Now().Add(2*time.Hour)).
+				AddRow("not-an-int", "Country2", "City2", nil, nil, nil, nil, 1, now.Add(12*time.Hour), now.Add(24*time.Hour), time.Now(), time.Now().Add(2*time.Hour)),
 		)
 
 	_, err = itinerary.defaultFindById(1, true)
@@ -199,15 +227,16 @@ func TestItineraryDefaultFindLightweightById_Success(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, owner_id FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, owner_id, version FROM itineraries WHERE id = \\?").
 		WithArgs(42).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id"}).
-			AddRow(42, 99))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "version"}).
+			AddRow(42, 99, 3))
 
 	it, err := itinerary.defaultFindLightweightById(42)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(42), it.ID)
 	assert.Equal(t, int64(99), it.OwnerID)
+	assert.Equal(t, int64(3), it.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -219,7 +248,7 @@ func TestItineraryDefaultFindLightweightById_NoRows(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, owner_id FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, owner_id, version FROM itineraries WHERE id = \\?").
 		WithArgs(100).
 		WillReturnError(sql.ErrNoRows)
 
@@ -237,10 +266,10 @@ func TestItineraryDefaultFindLightweightById_ScanError(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, owner_id FROM itineraries WHERE id = \\?").
+	mock.ExpectQuery("SELECT id, owner_id, version FROM itineraries WHERE id = \\?").
 		WithArgs(123).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id"}).
-			AddRow("not-an-int", 99))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "version"}).
+			AddRow("not-an-int", 99, 1))
 
 	_, err = itinerary.defaultFindLightweightById(123)
 	assert.Error(t, err)
@@ -257,14 +286,22 @@ func TestItineraryFindByOwnerId_Success(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE owner_id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE owner_id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+			AddRow(1, "Test Title", "Test Description", "A test trip", 1, time.Now(), time.Now().Add(2*time.Hour), 1))
+
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-			AddRow(1, "Test Title", "Test Description", "A test trip", 1, time.Now(), time.Now().Add(2*time.Hour)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}))
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_transport_legs WHERE itinerary_id = \\? ORDER BY id").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "from_country", "from_city", "to_country", "to_city", "mode", "carrier", "booking_reference", "departure_time", "arrival_time", "notes", "creation_date", "update_date"}))
+
+	mock.ExpectQuery("SELECT (.+) FROM destination_accommodations WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
 	// Act
 	itineraries, err := itinerary.defaultFindByOwnerId(1)
@@ -291,14 +328,22 @@ func TestItineraryFindByOwnerId_SuccessNullNotes(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE owner_id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE owner_id = \\?").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-			AddRow(1, "Test Title", "Test Description", nil, 1, time.Now(), time.Now().Add(2*time.Hour)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+			AddRow(1, "Test Title", "Test Description", nil, 1, time.Now(), time.Now().Add(2*time.Hour), 1))
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}))
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_transport_legs WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_id", "from_country", "from_city", "to_country", "to_city", "mode", "carrier", "booking_reference", "departure_time", "arrival_time", "notes", "creation_date", "update_date"}))
+
+	mock.ExpectQuery("SELECT (.+) FROM destination_accommodations WHERE itinerary_id = \\? ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
 	// Act
 	itineraries, err := itinerary.defaultFindByOwnerId(1)
@@ -323,7 +368,7 @@ func TestItineraryFindByOwnerId_NoRows(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE owner_id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE owner_id = \\?").
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
@@ -346,7 +391,7 @@ func TestItineraryFindByOwnerId_QueryError(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE owner_id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE owner_id = \\?").
 		WithArgs(1).
 		WillReturnError(assert.AnError)
 
@@ -369,12 +414,12 @@ func TestItineraryDefaultFindByOwnerId_DestinationQueryError(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE owner_id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE owner_id = \\?").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-			AddRow(1, "Test Title", "Test Description", nil, 1, time.Now(), time.Now().Add(2*time.Hour)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+			AddRow(1, "Test Title", "Test Description", nil, 1, time.Now(), time.Now().Add(2*time.Hour), 1))
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnError(sql.ErrConnDone)
 
@@ -398,15 +443,15 @@ func TestItineraryDefaultFindByOwnerId_DestinationScanError(t *testing.T) {
 
 	itinerary := &Itinerary{}
 
-	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date FROM itineraries WHERE owner_id = \\?").
+	mock.ExpectQuery("SELECT id, title, description, notes, owner_id, creation_date, update_date, version FROM itineraries WHERE owner_id = \\?").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date"}).
-			AddRow(1, "Test Title", "Test Description", nil, 1, time.Now(), time.Now().Add(2*time.Hour)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+			AddRow(1, "Test Title", "Test Description", nil, 1, time.Now(), time.Now().Add(2*time.Hour), 1))
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date"}).
-			AddRow("not-an-int", "Country1", "City1", 1, time.Now(), time.Now().Add(12*time.Hour)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date"}).
+			AddRow("not-an-int", "Country1", "City1", nil, nil, nil, nil, 1, time.Now(), time.Now().Add(12*time.Hour)))
 
 	// Act
 	_, err = itinerary.defaultFindByOwnerId(1)
@@ -417,6 +462,91 @@ func TestItineraryDefaultFindByOwnerId_DestinationScanError(t *testing.T) {
 
 }
 
+func TestItineraryDefaultFind_FiltersAndCursor(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT i.id, i.title, i.description, i.notes, i.owner_id, i.creation_date, i.update_date, i.version FROM itineraries i "+
+		"WHERE i.owner_id = \\? AND EXISTS \\(SELECT 1 FROM itinerary_travel_destinations d WHERE d.itinerary_id = i.id AND "+
+		"\\(d.country_code = \\? OR d.country = \\? COLLATE NOCASE\\) AND d.city = \\? COLLATE NOCASE AND d.departure_date >= \\? AND d.arrival_date <= \\?\\) "+
+		"AND i.title LIKE \\? ESCAPE '\\\\' "+
+		"AND \\(\\(SELECT MIN\\(d.arrival_date\\) FROM itinerary_travel_destinations d WHERE d.itinerary_id = i.id\\), i.id\\) > "+
+		"\\(\\(SELECT (.+) FROM itineraries i WHERE i.id = \\?\\), \\?\\) "+
+		"ORDER BY (.+) ASC, i.id ASC LIMIT \\?").
+		WithArgs(int64(1), "ES", "spain", "madrid", from, to, "%50\\%%", int64(4), int64(4), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}).
+			AddRow(5, "Spain 50% off", "Summer", nil, 1, time.Now(), time.Now(), 1))
+
+	mock.ExpectQuery("SELECT (.+) FROM itinerary_travel_destinations WHERE itinerary_id = \\?").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
+			AddRow(1, "Spain", "Madrid", nil, nil, nil, nil, 5, from, to, time.Now(), time.Now()))
+
+	itineraries, err := InitItinerary().Find(ItineraryFilter{OwnerID: 1, Country: "spain", City: "madrid", TravelFrom: &from, TravelTo: &to,
+		Title: "50%", SortBy: ItinerarySortTravelDate, Ascending: true, AfterID: 4, Limit: 3})
+
+	assert.NoError(t, err)
+	assert.Len(t, itineraries, 1)
+	assert.Equal(t, int64(5), itineraries[0].ID)
+	assert.Len(t, itineraries[0].TravelDestinations, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryDefaultFind_DefaultSort(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itineraries i WHERE i.owner_id = \\? ORDER BY i.creation_date DESC, i.id DESC$").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "notes", "owner_id", "creation_date", "update_date", "version"}))
+
+	itineraries, err := InitItinerary().Find(ItineraryFilter{OwnerID: 1, SortBy: "unknown"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, itineraries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryDefaultFind_QueryError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM itineraries i").
+		WillReturnError(errors.New("query error"))
+
+	itineraries, err := InitItinerary().Find(ItineraryFilter{OwnerID: 1})
+
+	assert.Error(t, err)
+	assert.Nil(t, itineraries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryDefaultCount_IgnoresCursor(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM itineraries i WHERE i.owner_id = \\? AND i.title LIKE \\? ESCAPE '\\\\'$").
+		WithArgs(int64(1), "%trip%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	count, err := InitItinerary().Count(ItineraryFilter{OwnerID: 1, Title: "trip", AfterID: 4, Limit: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItineraryItinerary_Create_Success(t *testing.T) {
 	dbMock, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -445,10 +575,11 @@ func TestItineraryItinerary_Create_Success(t *testing.T) {
 
 	for _, destination := range itinerary.TravelDestinations {
 		mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
-			WithArgs(destination.Country, destination.City, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(destination.Country, destination.City, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 	}
 
+	expectCreateItineraryRevision(mock, 1, 1, nil, 2)
 	mock.ExpectCommit()
 
 	err = itinerary.defaultCreate()
@@ -458,6 +589,7 @@ func TestItineraryItinerary_Create_Success(t *testing.T) {
 	assert.Equal(t, itinerary.Description, "Test Description")
 	assert.Equal(t, &testNotes, itinerary.Notes)
 	assert.Equal(t, int64(1), itinerary.OwnerID)
+	assert.Equal(t, int64(1), itinerary.Version)
 	assert.Len(t, itinerary.TravelDestinations, 2)
 	assert.Equal(t, itinerary.ID, itinerary.TravelDestinations[0].ItineraryID)
 	assert.Equal(t, "Country 1", itinerary.TravelDestinations[0].Country)
@@ -468,6 +600,44 @@ func TestItineraryItinerary_Create_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestItineraryItinerary_CreateClone_CopiesPreferences(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	itinerary := &Itinerary{
+		Title:       "Copy of Test Title",
+		Description: "Test Description",
+		OwnerID:     1,
+		TravelDestinations: []*ItineraryTravelDestination{
+			NewItineraryTravelDestination("Country 1", "City 1", time.Now(), time.Now().Add(24*time.Hour)),
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO itineraries").
+		ExpectExec().
+		WithArgs("Copy of Test Title", "Test Description", nil, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(8, 1))
+	mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
+		WithArgs("Country 1", "City 1", nil, nil, nil, nil, int64(8), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`INSERT INTO traveller_preferences\(.+\)\s+SELECT NULL, \?, .+ FROM traveller_preferences WHERE itinerary_id = \?`).
+		ExpectExec().
+		WithArgs(int64(8), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectCreateItineraryRevision(mock, 8, 1, nil, 1)
+	mock.ExpectCommit()
+
+	err = itinerary.defaultCreateClone(3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), itinerary.ID)
+	assert.Equal(t, int64(1), itinerary.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItineraryItinerary_Create_SuccessNullNotes(t *testing.T) {
 	dbMock, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -495,10 +665,11 @@ func TestItineraryItinerary_Create_SuccessNullNotes(t *testing.T) {
 
 	for _, destination := range itinerary.TravelDestinations {
 		mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
-			WithArgs(destination.Country, destination.City, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(destination.Country, destination.City, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 	}
 
+	expectCreateItineraryRevision(mock, 1, 1, nil, 2)
 	mock.ExpectCommit()
 
 	err = itinerary.defaultCreate()
@@ -620,7 +791,7 @@ func TestItineraryItinerary_Create_TravelDestinationError(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(errors.New("insert destinations error"))
 
 	mock.ExpectRollback()
@@ -646,6 +817,7 @@ func TestItineraryUpdate_Success(t *testing.T) {
 		Description: "Updated Description",
 		OwnerID:     1,
 		Notes:       &testNotes,
+		Version:     4,
 		TravelDestinations: []*ItineraryTravelDestination{
 			NewItineraryTravelDestination("Country 1", "City 1", time.Now(), time.Now().Add(24*time.Hour)),
 			NewItineraryTravelDestination("Country 2", "City 2", time.Now(), time.Now().Add(24*time.Hour)),
@@ -654,9 +826,9 @@ func TestItineraryUpdate_Success(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \? WHERE id = \?`).
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
 		ExpectExec().
-		WithArgs(itinerary.Title, itinerary.Description, itinerary.Notes, sqlmock.AnyArg(), itinerary.ID).
+		WithArgs(itinerary.Title, itinerary.Description, itinerary.Notes, sqlmock.AnyArg(), itinerary.ID, itinerary.Version).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectPrepare(`DELETE FROM itinerary_travel_destinations WHERE itinerary_id = \?`).ExpectExec().
@@ -665,14 +837,15 @@ func TestItineraryUpdate_Success(t *testing.T) {
 
 	for _, destination := range itinerary.TravelDestinations {
 		mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
-			WithArgs(destination.Country, destination.City, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(destination.Country, destination.City, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 	}
 
+	expectCreateItineraryRevision(mock, 1, 2, nil, 2)
 	mock.ExpectCommit()
 
 	// Act
-	err = itinerary.defaultUpdate()
+	err = itinerary.defaultUpdate(2)
 
 	// Assert
 	assert.NoError(t, err)
@@ -681,6 +854,7 @@ func TestItineraryUpdate_Success(t *testing.T) {
 	assert.Equal(t, itinerary.Description, "Updated Description")
 	assert.Equal(t, &testNotes, itinerary.Notes)
 	assert.Equal(t, int64(1), itinerary.OwnerID)
+	assert.Equal(t, int64(5), itinerary.Version)
 	assert.Len(t, itinerary.TravelDestinations, 2)
 	assert.Equal(t, itinerary.ID, itinerary.TravelDestinations[0].ItineraryID)
 	assert.Equal(t, "Country 1", itinerary.TravelDestinations[0].Country)
@@ -713,9 +887,9 @@ func TestItineraryUpdate_SuccessNullNotes(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \? WHERE id = \?`).
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
 		ExpectExec().
-		WithArgs(itinerary.Title, itinerary.Description, itinerary.Notes, sqlmock.AnyArg(), itinerary.ID).
+		WithArgs(itinerary.Title, itinerary.Description, itinerary.Notes, sqlmock.AnyArg(), itinerary.ID, itinerary.Version).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectPrepare(`DELETE FROM itinerary_travel_destinations WHERE itinerary_id = \?`).ExpectExec().
@@ -724,14 +898,15 @@ func TestItineraryUpdate_SuccessNullNotes(t *testing.T) {
 
 	for _, destination := range itinerary.TravelDestinations {
 		mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
-			WithArgs(destination.Country, destination.City, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(destination.Country, destination.City, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 	}
 
+	expectCreateItineraryRevision(mock, 1, 2, nil, 2)
 	mock.ExpectCommit()
 
 	// Act
-	err = itinerary.defaultUpdate()
+	err = itinerary.defaultUpdate(2)
 
 	// Assert
 	assert.NoError(t, err)
@@ -764,13 +939,13 @@ func TestItineraryUpdate_PrepareStatementError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \? WHERE id = \?`).
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
 		WillReturnError(errors.New("prepare statement error"))
 
 	mock.ExpectRollback()
 
 	// Act
-	err = itinerary.defaultUpdate()
+	err = itinerary.defaultUpdate(2)
 
 	// Assert
 	assert.Error(t, err)
@@ -778,6 +953,34 @@ func TestItineraryUpdate_PrepareStatementError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestItineraryUpdate_VersionConflict(t *testing.T) {
+	// Arrange
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	itinerary := &Itinerary{ID: 1, Title: "Updated Title", Version: 2}
+
+	mock.ExpectBegin()
+
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
+		ExpectExec().
+		WithArgs(itinerary.Title, itinerary.Description, sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectRollback()
+
+	// Act
+	err = itinerary.defaultUpdate(2)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrItineraryVersionConflict)
+	assert.Equal(t, int64(2), itinerary.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItineraryUpdate_DeleteDestinationsError(t *testing.T) {
 	// Arrange
 	dbMock, mock, err := sqlmock.New()
@@ -792,9 +995,9 @@ func TestItineraryUpdate_DeleteDestinationsError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \? WHERE id = \?`).
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
 		ExpectExec().
-		WithArgs(itinerary.Title, itinerary.Description, sqlmock.AnyArg(), sqlmock.AnyArg(), itinerary.ID).
+		WithArgs(itinerary.Title, itinerary.Description, sqlmock.AnyArg(), sqlmock.AnyArg(), itinerary.ID, itinerary.Version).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectPrepare(`DELETE FROM itinerary_travel_destinations WHERE itinerary_id = \?`).ExpectExec().
@@ -804,7 +1007,7 @@ func TestItineraryUpdate_DeleteDestinationsError(t *testing.T) {
 	mock.ExpectRollback()
 
 	// Act
-	err = itinerary.defaultUpdate()
+	err = itinerary.defaultUpdate(2)
 
 	// Assert
 	assert.Error(t, err)
@@ -832,9 +1035,9 @@ func TestItineraryUpdate_InsertDestinationsError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \? WHERE id = \?`).
+	mock.ExpectPrepare(`UPDATE itineraries SET title = \?, description = \?, notes = \?, update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).
 		ExpectExec().
-		WithArgs(itinerary.Title, itinerary.Description, sqlmock.AnyArg(), sqlmock.AnyArg(), itinerary.ID).
+		WithArgs(itinerary.Title, itinerary.Description, sqlmock.AnyArg(), sqlmock.AnyArg(), itinerary.ID, itinerary.Version).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectPrepare(`DELETE FROM itinerary_travel_destinations WHERE itinerary_id = \?`).ExpectExec().
@@ -842,13 +1045,13 @@ func TestItineraryUpdate_InsertDestinationsError(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(errors.New("insert destinations error"))
 
 	mock.ExpectRollback()
 
 	// Act
-	err = itinerary.defaultUpdate()
+	err = itinerary.defaultUpdate(2)
 
 	// Assert
 	assert.Error(t, err)
@@ -889,10 +1092,53 @@ func TestItineraryDefaultDelete_Success_WithJobs(t *testing.T) {
 		return nil
 	}
 
-	// Mock DELETE FROM itineraries
-	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\?").
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM share_links WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE rowid = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM itinerary_revision_destinations WHERE revision_id IN").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM itinerary_revisions WHERE itinerary_id = \\?").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_budgets WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_cost_estimates WHERE itinerary_id = \\?").
 		ExpectExec().
 		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_transport_legs WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_accommodations WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_activities WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Mock DELETE FROM itineraries
+	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\? AND version = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID, itinerary.Version).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	mock.ExpectCommit()
@@ -1015,7 +1261,50 @@ func TestItineraryDefaultDelete_PrepareDeleteItineraryError(t *testing.T) {
 		return nil
 	}
 
-	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\?").
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM share_links WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE rowid = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM itinerary_revision_destinations WHERE revision_id IN").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM itinerary_revisions WHERE itinerary_id = \\?").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_budgets WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_cost_estimates WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_transport_legs WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_accommodations WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_activities WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\? AND version = \\?").
 		WillReturnError(errors.New("prepare delete itinerary error"))
 
 	mock.ExpectRollback()
@@ -1058,9 +1347,52 @@ func TestItineraryDefaultDelete_ExecDeleteItineraryError(t *testing.T) {
 		return nil
 	}
 
-	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\?").
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM share_links WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE rowid = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM itinerary_revision_destinations WHERE revision_id IN").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM itinerary_revisions WHERE itinerary_id = \\?").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_budgets WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_cost_estimates WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_transport_legs WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_accommodations WHERE itinerary_id = \\?").
 		ExpectExec().
 		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_activities WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\? AND version = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID, itinerary.Version).
 		WillReturnError(errors.New("exec delete itinerary error"))
 
 	mock.ExpectRollback()
@@ -1070,3 +1402,173 @@ func TestItineraryDefaultDelete_ExecDeleteItineraryError(t *testing.T) {
 	assert.Equal(t, "exec delete itinerary error", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestItineraryDefaultDelete_VersionConflict(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	itinerary := &Itinerary{ID: 1, Version: 3}
+
+	mock.ExpectBegin()
+
+	// Mock SoftDeleteJobsByItineraryIdTx
+	originalInitItineraryFileJob := InitItineraryFileJob
+	defer func() { InitItineraryFileJob = originalInitItineraryFileJob }()
+	mockJob := &ItineraryFileJob{}
+	InitItineraryFileJob = func() *ItineraryFileJob {
+		return mockJob
+	}
+	mockJob.SoftDeleteJobsByItineraryIdTx = func(itineraryId int64, tx *sql.Tx) error {
+		return nil
+	}
+
+	// Mock DeleteByItineraryIdTx
+	originalInitItineraryTravelDestination := InitItineraryTravelDestination
+	defer func() { InitItineraryTravelDestination = originalInitItineraryTravelDestination }()
+	mockDest := &ItineraryTravelDestination{}
+	InitItineraryTravelDestination = func() *ItineraryTravelDestination {
+		return mockDest
+	}
+	mockDest.DeleteByItineraryIdTx = func(itineraryId int64, tx *sql.Tx) error {
+		return nil
+	}
+
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM share_links WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE rowid = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM itinerary_revision_destinations WHERE revision_id IN").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM itinerary_revisions WHERE itinerary_id = \\?").
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_budgets WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_cost_estimates WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_transport_legs WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_accommodations WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_activities WHERE itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectPrepare("DELETE FROM itineraries WHERE id = \\? AND version = \\?").
+		ExpectExec().
+		WithArgs(itinerary.ID, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectRollback()
+
+	err = itinerary.defaultDelete()
+	assert.ErrorIs(t, err, ErrItineraryVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryAddDestination_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	arrival := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	destination := NewItineraryTravelDestination("Spain", "Seville", arrival.Add(48*time.Hour), arrival.Add(72*time.Hour))
+	itinerary := InitItinerary()
+	itinerary.ID = 1
+	itinerary.Version = 3
+	itinerary.TravelDestinations = []*ItineraryTravelDestination{
+		{ID: 4, ItineraryID: 1, Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(48 * time.Hour)}, destination,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`UPDATE itineraries SET update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).ExpectExec().
+		WithArgs(sqlmock.AnyArg(), int64(1), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`INSERT INTO itinerary_travel_destinations`).ExpectExec().
+		WithArgs("Spain", "Seville", "ES", 37.38283, -5.97317, "Europe/Madrid", int64(1), arrival.Add(48*time.Hour), arrival.Add(72*time.Hour), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	expectCreateItineraryRevision(mock, 1, 2, nil, 2)
+	mock.ExpectCommit()
+
+	err = itinerary.AddDestination(destination, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), destination.ID)
+	assert.Equal(t, int64(1), destination.ItineraryID)
+	assert.Equal(t, int64(4), itinerary.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryUpdateDestination_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	destination := NewItineraryTravelDestination("Spain", "Seville", time.Now(), time.Now().Add(24*time.Hour))
+	destination.ID = 9
+	itinerary := InitItinerary()
+	itinerary.ID = 1
+	itinerary.Version = 3
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`UPDATE itineraries SET update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).ExpectExec().
+		WithArgs(sqlmock.AnyArg(), int64(1), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`UPDATE itinerary_travel_destinations SET`).ExpectExec().
+		WithArgs("Spain", "Seville", "ES", 37.38283, -5.97317, "Europe/Madrid", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(9), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = itinerary.UpdateDestination(destination, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, int64(3), itinerary.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryDeleteDestination_VersionConflict(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	destination := InitItineraryTravelDestination()
+	destination.ID = 4
+	itinerary := InitItinerary()
+	itinerary.ID = 1
+	itinerary.Version = 3
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`UPDATE itineraries SET update_date = \?, version = version \+ 1 WHERE id = \? AND version = \?`).ExpectExec().
+		WithArgs(sqlmock.AnyArg(), int64(1), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = itinerary.DeleteDestination(destination, 2)
+	assert.ErrorIs(t, err, ErrItineraryVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
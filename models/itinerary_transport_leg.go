@@ -0,0 +1,266 @@
+package models
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+	"example.com/travel-advisor/utils"
+)
+
+// Modes of transport of the legs between destinations
+const (
+	TransportModeFlight = "flight"
+	TransportModeTrain  = "train"
+	TransportModeBus    = "bus"
+	TransportModeCar    = "car"
+	TransportModeFerry  = "ferry"
+	TransportModeOther  = "other"
+)
+
+var TransportModes = []string{TransportModeFlight, TransportModeTrain, TransportModeBus, TransportModeCar, TransportModeFerry, TransportModeOther}
+
+// ItineraryTransportLeg is how the travellers get from a destination of an itinerary to the next one. Legs belong to the countries
+// and cities they connect, so they are kept when the destinations of the itinerary are replaced. FromDestinationID and ToDestinationID
+// are not stored: they are set when the itinerary is retrieved with its destinations, as long as the cities of the leg are still
+// consecutive destinations
+type ItineraryTransportLeg struct {
+	ID                int64      `json:"id" example:"1"`
+	ItineraryID       int64      `json:"itineraryId" example:"1"`
+	FromDestinationID *int64     `json:"fromDestinationId,omitempty" example:"1"`
+	ToDestinationID   *int64     `json:"toDestinationId,omitempty" example:"2"`
+	FromCountry       string     `json:"fromCountry" example:"Spain"`
+	FromCity          string     `json:"fromCity" example:"Madrid"`
+	ToCountry         string     `json:"toCountry" example:"Spain"`
+	ToCity            string     `json:"toCity" example:"Barcelona"`
+	Mode              string     `json:"mode" example:"train"`
+	Carrier           string     `json:"carrier,omitempty" example:"Renfe"`
+	BookingReference  string     `json:"bookingReference,omitempty" example:"X7K2P9"`
+	DepartureTime     *time.Time `json:"departureTime,omitempty" example:"2024-07-05T09:30:00+02:00"`
+	ArrivalTime       *time.Time `json:"arrivalTime,omitempty" example:"2024-07-05T12:15:00+02:00"`
+	Notes             string     `json:"notes,omitempty" example:"Seats 4A and 4B"`
+	CreationDate      *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	UpdateDate        *time.Time `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindByItineraryId     func(itineraryId int64) ([]*ItineraryTransportLeg, error) `json:"-"`
+	Create                func() error                                              `json:"-"`
+	Update                func() error                                              `json:"-"`
+	Delete                func() error                                              `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                 `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                     `json:"-"`
+}
+
+var InitItineraryTransportLeg = func() *ItineraryTransportLeg {
+	return InitItineraryTransportLegFunctions(&ItineraryTransportLeg{})
+}
+
+var InitItineraryTransportLegFunctions = func(leg *ItineraryTransportLeg) *ItineraryTransportLeg {
+	// Set default SQL implementations for FindByItineraryId, Create, Update, Delete, DeleteByItineraryIdTx and DeleteByOwnerIdTx. In
+	// the future there could be implementations for other NoSQL DB systems like MongoDB
+	leg.FindByItineraryId = leg.defaultFindByItineraryId
+	leg.Create = leg.defaultCreate
+	leg.Update = leg.defaultUpdate
+	leg.Delete = leg.defaultDelete
+	leg.DeleteByItineraryIdTx = leg.defaultDeleteByItineraryIdTx
+	leg.DeleteByOwnerIdTx = leg.defaultDeleteByOwnerIdTx
+
+	return leg
+}
+
+// Connects returns whether the leg goes from the city of a destination to the city of another one, by any of their names in the
+// gazetteer
+func (l *ItineraryTransportLeg) Connects(from *ItineraryTravelDestination, to *ItineraryTravelDestination) bool {
+	return utils.SamePlace(l.FromCountry, l.FromCity, from.Country, from.City) && utils.SamePlace(l.ToCountry, l.ToCity, to.Country, to.City)
+}
+
+// ConnectTransportLegs sets the destinations the legs go from and to, among the consecutive destinations sorted by arrival date, and
+// sorts the legs in travel order. The legs whose cities are no longer consecutive destinations go last, without destinations
+func ConnectTransportLegs(destinations []*ItineraryTravelDestination, legs []*ItineraryTransportLeg) {
+	positions := map[*ItineraryTransportLeg]int{}
+	for _, leg := range legs {
+		leg.FromDestinationID = nil
+		leg.ToDestinationID = nil
+		positions[leg] = len(destinations)
+		for idx := 0; idx+1 < len(destinations); idx++ {
+			if leg.Connects(destinations[idx], destinations[idx+1]) {
+				leg.FromDestinationID = &destinations[idx].ID
+				leg.ToDestinationID = &destinations[idx+1].ID
+				positions[leg] = idx
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(legs, func(a int, b int) bool {
+		if positions[legs[a]] != positions[legs[b]] {
+			return positions[legs[a]] < positions[legs[b]]
+		}
+		if legs[a].DepartureTime != nil && legs[b].DepartureTime != nil {
+			return legs[a].DepartureTime.Before(*legs[b].DepartureTime)
+		}
+		return legs[a].DepartureTime != nil && legs[b].DepartureTime == nil
+	})
+}
+
+func (l *ItineraryTransportLeg) defaultFindByItineraryId(itineraryId int64) ([]*ItineraryTransportLeg, error) {
+	query := `SELECT id, itinerary_id, from_country, from_city, to_country, to_city, mode, carrier, booking_reference, departure_time,
+	arrival_time, notes, creation_date, update_date
+	FROM itinerary_transport_legs WHERE itinerary_id = ? ORDER BY id`
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error fetching transport legs of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	legs := []*ItineraryTransportLeg{}
+	for rows.Next() {
+		leg := &ItineraryTransportLeg{}
+		err = rows.Scan(&leg.ID, &leg.ItineraryID, &leg.FromCountry, &leg.FromCity, &leg.ToCountry, &leg.ToCity, &leg.Mode, &leg.Carrier,
+			&leg.BookingReference, &leg.DepartureTime, &leg.ArrivalTime, &leg.Notes, &leg.CreationDate, &leg.UpdateDate)
+		if err != nil {
+			log.Errorf("Error scanning transport leg of itinerary %d: %v", itineraryId, err)
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return legs, nil
+}
+
+func (l *ItineraryTransportLeg) defaultCreate() error {
+	query := `INSERT INTO itinerary_transport_legs(itinerary_id, from_country, from_city, to_country, to_city, mode, carrier,
+	booking_reference, departure_time, arrival_time, notes, creation_date, update_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for itinerary transport leg: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(l.ItineraryID, l.FromCountry, l.FromCity, l.ToCountry, l.ToCity, l.Mode, l.Carrier, l.BookingReference,
+		l.DepartureTime, l.ArrivalTime, l.Notes, now, now)
+	if err != nil {
+		log.Errorf("Error executing insert for transport leg of itinerary %d: %v", l.ItineraryID, err)
+		return err
+	}
+
+	l.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for itinerary transport leg: %v", err)
+		return err
+	}
+	l.CreationDate = &now
+	l.UpdateDate = &now
+
+	return nil
+}
+
+// defaultUpdate saves the leg, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has no such leg
+func (l *ItineraryTransportLeg) defaultUpdate() error {
+	query := `UPDATE itinerary_transport_legs SET from_country = ?, from_city = ?, to_country = ?, to_city = ?, mode = ?, carrier = ?,
+	booking_reference = ?, departure_time = ?, arrival_time = ?, notes = ?, update_date = ? WHERE id = ? AND itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for itinerary transport leg: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	result, err := stmt.Exec(l.FromCountry, l.FromCity, l.ToCountry, l.ToCity, l.Mode, l.Carrier, l.BookingReference, l.DepartureTime,
+		l.ArrivalTime, l.Notes, now, l.ID, l.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing update for transport leg %d of itinerary %d: %v", l.ID, l.ItineraryID, err)
+		return err
+	}
+
+	err = checkTransportLegFound(result, l)
+	if err != nil {
+		return err
+	}
+	l.UpdateDate = &now
+
+	return nil
+}
+
+// defaultDelete deletes the leg, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has no such leg
+func (l *ItineraryTransportLeg) defaultDelete() error {
+	query := `DELETE FROM itinerary_transport_legs WHERE id = ? AND itinerary_id = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary transport leg: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(l.ID, l.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing delete for transport leg %d of itinerary %d: %v", l.ID, l.ItineraryID, err)
+		return err
+	}
+
+	return checkTransportLegFound(result, l)
+}
+
+func checkTransportLegFound(result sql.Result, l *ItineraryTransportLeg) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting affected rows for itinerary transport leg: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Errorf("Transport leg %d not found in itinerary %d", l.ID, l.ItineraryID)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (l *ItineraryTransportLeg) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_transport_legs WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for transport legs of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for transport legs of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the transport legs of all the itineraries of an owner
+func (l *ItineraryTransportLeg) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_transport_legs WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for transport legs of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for transport legs of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItineraryTransportLeg_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	departure := time.Date(2024, time.July, 5, 9, 30, 0, 0, time.UTC)
+	mock.ExpectPrepare("INSERT INTO itinerary_transport_legs").
+		ExpectExec().
+		WithArgs(int64(3), "Spain", "Madrid", "Spain", "Barcelona", TransportModeTrain, "Renfe", "X7K2P9", &departure, nil, "",
+			sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	leg := InitItineraryTransportLeg()
+	leg.ItineraryID = 3
+	leg.FromCountry, leg.FromCity, leg.ToCountry, leg.ToCity = "Spain", "Madrid", "Spain", "Barcelona"
+	leg.Mode = TransportModeTrain
+	leg.Carrier = "Renfe"
+	leg.BookingReference = "X7K2P9"
+	leg.DepartureTime = &departure
+	assert.NoError(t, leg.Create())
+	assert.Equal(t, int64(7), leg.ID)
+	assert.NotNil(t, leg.CreationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryTransportLeg_Update_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("UPDATE itinerary_transport_legs SET (.+) WHERE id = \\? AND itinerary_id = \\?").
+		ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	leg := InitItineraryTransportLeg()
+	leg.ID = 7
+	leg.ItineraryID = 3
+	assert.ErrorIs(t, leg.Update(), sql.ErrNoRows)
+	assert.Nil(t, leg.UpdateDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItineraryTransportLeg_Delete_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("DELETE FROM itinerary_transport_legs WHERE id = \\? AND itinerary_id = \\?").
+		ExpectExec().
+		WithArgs(int64(7), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	leg := InitItineraryTransportLeg()
+	leg.ID = 7
+	leg.ItineraryID = 3
+	assert.ErrorIs(t, leg.Delete(), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnectTransportLegs(t *testing.T) {
+	destinations := []*ItineraryTravelDestination{
+		{ID: 1, Country: "Spain", City: "Madrid"},
+		{ID: 2, Country: "Spain", City: "Barcelona"},
+		{ID: 3, Country: "France", City: "Paris"},
+	}
+	early := time.Date(2024, time.July, 9, 7, 0, 0, 0, time.UTC)
+	late := early.Add(3 * time.Hour)
+	legs := []*ItineraryTransportLeg{
+		{ID: 1, FromCountry: "ESP", FromCity: "barcelona", ToCountry: "france", ToCity: "Paris", DepartureTime: &late},
+		{ID: 2, FromCountry: "France", FromCity: "Paris", ToCountry: "Spain", ToCity: "Madrid"},
+		{ID: 3, FromCountry: "Spain", FromCity: "Barcelona", ToCountry: "France", ToCity: "Paris", DepartureTime: &early},
+		{ID: 4, FromCountry: "Spain", FromCity: "Madrid", ToCountry: "Spain", ToCity: "Barcelona"},
+	}
+
+	ConnectTransportLegs(destinations, legs)
+
+	ids := []int64{}
+	for _, leg := range legs {
+		ids = append(ids, leg.ID)
+	}
+	assert.Equal(t, []int64{4, 3, 1, 2}, ids)
+	assert.Equal(t, int64(1), *legs[0].FromDestinationID)
+	assert.Equal(t, int64(2), *legs[0].ToDestinationID)
+	assert.Equal(t, int64(2), *legs[2].FromDestinationID)
+	assert.Equal(t, int64(3), *legs[2].ToDestinationID)
+	assert.Nil(t, legs[3].FromDestinationID)
+	assert.Nil(t, legs[3].ToDestinationID)
+}
@@ -7,12 +7,19 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"example.com/travel-advisor/db"
+	"example.com/travel-advisor/utils"
 )
 
+// ItineraryTravelDestination is a stay in a city of an itinerary. The country and city of the destinations found in the gazetteer are
+// normalized to their names in it, with the ISO 3166-1 alpha-2 code of the country and the coordinates and IANA time zone of the city
 type ItineraryTravelDestination struct {
 	ID            int64      `json:"id" example:"1"`
 	Country       string     `json:"country" binding:"required" example:"Spain"`
 	City          string     `json:"city" binding:"required" example:"Madrid"`
+	CountryCode   *string    `json:"countryCode,omitempty" example:"ES"`
+	Latitude      *float64   `json:"latitude,omitempty" example:"40.4165"`
+	Longitude     *float64   `json:"longitude,omitempty" example:"-3.70256"`
+	TimeZone      *string    `json:"timeZone,omitempty" example:"Europe/Madrid"`
 	ItineraryID   int64      `json:"itineraryId" example:"1"`
 	ArrivalDate   time.Time  `json:"arrivalDate" binding:"required" example:"2024-07-01T00:00:00Z"`
 	DepartureDate time.Time  `json:"departureDate" binding:"required" example:"2024-07-05T00:00:00Z"`
@@ -21,9 +28,10 @@ type ItineraryTravelDestination struct {
 
 	FindByItineraryId     func(itineraryId int64) ([]*ItineraryTravelDestination, error) `json:"-"`
 	Create                func(*sql.Tx) error                                            `json:"-"`
-	Update                func() error                                                   `json:"-"`
-	Delete                func() error                                                   `json:"-"`
+	Update                func(*sql.Tx) error                                            `json:"-"`
+	Delete                func(*sql.Tx) error                                            `json:"-"`
 	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error                      `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                          `json:"-"`
 }
 
 var InitItineraryTravelDestination = func() *ItineraryTravelDestination {
@@ -38,6 +46,7 @@ var initItineraryTravelDestinationFunctions = func(destination *ItineraryTravelD
 	destination.Update = destination.defaultUpdate
 	destination.Delete = destination.defaultDelete
 	destination.DeleteByItineraryIdTx = destination.defaultDeleteByItineraryIdTx
+	destination.DeleteByOwnerIdTx = destination.defaultDeleteByOwnerIdTx
 
 	return destination
 }
@@ -49,14 +58,37 @@ var NewItineraryTravelDestination = func(country string, city string, arrivalDat
 		ArrivalDate:   arrivalDate,
 		DepartureDate: departureDate,
 	}
+	destination.normalize()
 
 	return initItineraryTravelDestinationFunctions(destination)
 }
 
+// normalize replaces the country and city with their names in the gazetteer, so "USA" and "us" are both stored as United States, and
+// sets the country code, coordinates and time zone. Places missing from the gazetteer are kept as entered, with the country code if
+// only the city is missing
+func (d *ItineraryTravelDestination) normalize() {
+	var country *utils.GazetteerCountry
+	var city *utils.GazetteerCity
+	d.Country, d.City, country, city = utils.NormalizePlace(d.Country, d.City)
+	if country == nil {
+		return
+	}
+	code := country.Code
+	d.CountryCode = &code
+
+	if city == nil {
+		return
+	}
+	latitude, longitude, timeZone := city.Latitude, city.Longitude, city.TimeZone
+	d.Latitude = &latitude
+	d.Longitude = &longitude
+	d.TimeZone = &timeZone
+}
+
 func (d *ItineraryTravelDestination) defaultFindByItineraryId(itineraryId int64) ([]*ItineraryTravelDestination, error) {
 
-	query := `SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date
-	FROM itinerary_travel_destinations WHERE itinerary_id = ? ORDER BY arrival_date ASC`
+	query := `SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date,
+	creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = ? ORDER BY arrival_date ASC`
 	destRows, err := db.DB.Query(query, itineraryId)
 	if err != nil {
 		log.Errorf("Error querying itinerary travel destinations: %v", err)
@@ -67,7 +99,9 @@ func (d *ItineraryTravelDestination) defaultFindByItineraryId(itineraryId int64)
 
 	for destRows.Next() {
 		var destination ItineraryTravelDestination
-		err := destRows.Scan(&destination.ID, &destination.Country, &destination.City, &destination.ItineraryID, &destination.ArrivalDate, &destination.DepartureDate, &destination.CreationDate, &destination.UpdateDate)
+		err := destRows.Scan(&destination.ID, &destination.Country, &destination.City, &destination.CountryCode,
+			&destination.Latitude, &destination.Longitude, &destination.TimeZone, &destination.ItineraryID, &destination.ArrivalDate,
+			&destination.DepartureDate, &destination.CreationDate, &destination.UpdateDate)
 		if err != nil {
 			log.Errorf("Error scanning itinerary travel destination: %v", err)
 			destRows.Close()
@@ -81,8 +115,9 @@ func (d *ItineraryTravelDestination) defaultFindByItineraryId(itineraryId int64)
 }
 
 func (d *ItineraryTravelDestination) defaultCreate(tx *sql.Tx) error {
-	query := `INSERT INTO itinerary_travel_destinations (country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date)
-	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO itinerary_travel_destinations (country, city, country_code, latitude, longitude, time_zone, itinerary_id,
+	arrival_date, departure_date, creation_date, update_date)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	stmt, err := tx.Prepare(query)
 	if err != nil {
@@ -92,7 +127,7 @@ func (d *ItineraryTravelDestination) defaultCreate(tx *sql.Tx) error {
 
 	defer stmt.Close()
 
-	result, err := stmt.Exec(d.Country, d.City, d.ItineraryID, d.ArrivalDate, d.DepartureDate, time.Now(), time.Now())
+	result, err := stmt.Exec(d.Country, d.City, d.CountryCode, d.Latitude, d.Longitude, d.TimeZone, d.ItineraryID, d.ArrivalDate, d.DepartureDate, time.Now(), time.Now())
 	if err != nil {
 		log.Errorf("Error executing insert for itinerary travel destination: %v", err)
 		return err
@@ -109,10 +144,13 @@ func (d *ItineraryTravelDestination) defaultCreate(tx *sql.Tx) error {
 	return nil
 }
 
-func (d *ItineraryTravelDestination) defaultUpdate() error {
-	query := `UPDATE itinerary_travel_destinations SET country = ?, city = ?, arrival_date = ?, departure_date = ?, update_date = ? WHERE id = ?`
+// defaultUpdate saves the destination, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has no
+// such destination
+func (d *ItineraryTravelDestination) defaultUpdate(tx *sql.Tx) error {
+	query := `UPDATE itinerary_travel_destinations SET country = ?, city = ?, country_code = ?, latitude = ?, longitude = ?, time_zone = ?,
+	arrival_date = ?, departure_date = ?, update_date = ? WHERE id = ? AND itinerary_id = ?`
 
-	stmt, err := db.DB.Prepare(query)
+	stmt, err := tx.Prepare(query)
 	if err != nil {
 		log.Errorf("Error preparing update for itinerary travel destination: %v", err)
 		return err
@@ -120,19 +158,21 @@ func (d *ItineraryTravelDestination) defaultUpdate() error {
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(d.Country, d.City, d.ArrivalDate, d.DepartureDate, time.Now(), d.ID)
+	result, err := stmt.Exec(d.Country, d.City, d.CountryCode, d.Latitude, d.Longitude, d.TimeZone, d.ArrivalDate, d.DepartureDate, time.Now(), d.ID, d.ItineraryID)
 	if err != nil {
 		log.Errorf("Error executing update for itinerary travel destination: %v", err)
 		return err
 	}
 
-	return nil
+	return checkDestinationFound(result, d)
 }
 
-func (d *ItineraryTravelDestination) defaultDelete() error {
-	query := `DELETE FROM itinerary_travel_destinations WHERE id = ?`
+// defaultDelete deletes the destination, which needs its ID and the ID of its itinerary. Returns sql.ErrNoRows if the itinerary has
+// no such destination
+func (d *ItineraryTravelDestination) defaultDelete(tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_travel_destinations WHERE id = ? AND itinerary_id = ?`
 
-	stmt, err := db.DB.Prepare(query)
+	stmt, err := tx.Prepare(query)
 	if err != nil {
 		log.Errorf("Error preparing delete for itinerary travel destination: %v", err)
 		return err
@@ -140,12 +180,25 @@ func (d *ItineraryTravelDestination) defaultDelete() error {
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(d.ID)
+	result, err := stmt.Exec(d.ID, d.ItineraryID)
 	if err != nil {
 		log.Errorf("Error executing delete for itinerary travel destination: %v", err)
 		return err
 	}
 
+	return checkDestinationFound(result, d)
+}
+
+func checkDestinationFound(result sql.Result, d *ItineraryTravelDestination) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting affected rows for itinerary travel destination: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		log.Errorf("Travel destination %d not found in itinerary %d", d.ID, d.ItineraryID)
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
@@ -168,3 +221,23 @@ func (d *ItineraryTravelDestination) defaultDeleteByItineraryIdTx(itineraryId in
 
 	return nil
 }
+
+func (d *ItineraryTravelDestination) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM itinerary_travel_destinations WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for itinerary travel destinations by owner ID: %v", err)
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for itinerary travel destinations by owner ID: %v", err)
+		return err
+	}
+
+	return nil
+}
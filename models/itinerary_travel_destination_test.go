@@ -11,6 +11,32 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestNewItineraryTravelDestination_Normalizes(t *testing.T) {
+	arrival := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, country := range []string{"USA", "United States", " us "} {
+		destination := NewItineraryTravelDestination(country, "nyc", arrival, arrival.Add(24*time.Hour))
+		assert.Equal(t, "United States", destination.Country)
+		assert.Equal(t, "New York", destination.City)
+		assert.Equal(t, "US", *destination.CountryCode)
+		assert.InDelta(t, 40.71427, *destination.Latitude, 0.00001)
+		assert.InDelta(t, -74.00597, *destination.Longitude, 0.00001)
+		assert.Equal(t, "America/New_York", *destination.TimeZone)
+	}
+
+	destination := NewItineraryTravelDestination("spain", "Villarriba", arrival, arrival.Add(24*time.Hour))
+	assert.Equal(t, "Spain", destination.Country)
+	assert.Equal(t, "Villarriba", destination.City)
+	assert.Equal(t, "ES", *destination.CountryCode)
+	assert.Nil(t, destination.Latitude)
+	assert.Nil(t, destination.TimeZone)
+
+	destination = NewItineraryTravelDestination("Atlantis", "Poseidonia", arrival, arrival.Add(24*time.Hour))
+	assert.Equal(t, "Atlantis", destination.Country)
+	assert.Equal(t, "Poseidonia", destination.City)
+	assert.Nil(t, destination.CountryCode)
+}
+
 func TestDestinationTravelDestination_Find_Success(t *testing.T) {
 	// Arrange
 	dbMock, mock, err := sqlmock.New()
@@ -21,10 +47,10 @@ func TestDestinationTravelDestination_Find_Success(t *testing.T) {
 
 	destination := &ItineraryTravelDestination{}
 
-	rows := sqlmock.NewRows([]string{"id", "country", "city", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
-		AddRow(1, "Test Country", "Test City", 1, time.Now(), time.Now().Add(48*time.Hour), time.Now(), time.Now().Add(2*time.Hour))
+	rows := sqlmock.NewRows([]string{"id", "country", "city", "country_code", "latitude", "longitude", "time_zone", "itinerary_id", "arrival_date", "departure_date", "creation_date", "update_date"}).
+		AddRow(1, "Test Country", "Test City", nil, nil, nil, nil, 1, time.Now(), time.Now().Add(48*time.Hour), time.Now(), time.Now().Add(2*time.Hour))
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -50,7 +76,7 @@ func TestDestinationTravelDestination_Find_NoRows(t *testing.T) {
 
 	destination := &ItineraryTravelDestination{}
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
@@ -74,7 +100,7 @@ func TestDestinationTravelDestination_Find_QueryError(t *testing.T) {
 
 	destination := &ItineraryTravelDestination{}
 
-	mock.ExpectQuery("SELECT id, country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
+	mock.ExpectQuery("SELECT id, country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date FROM itinerary_travel_destinations WHERE itinerary_id = \\? ORDER BY arrival_date ASC").
 		WithArgs(1).
 		WillReturnError(errors.New("query error"))
 
@@ -124,9 +150,9 @@ func TestDestinationCreate_Success(t *testing.T) {
 		DepartureDate: time.Now().Add(24 * time.Hour),
 	}
 
-	query := `INSERT INTO itinerary_travel_destinations \(country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`
+	query := `INSERT INTO itinerary_travel_destinations \(country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?, \?, \?\)`
 	mock.ExpectPrepare(query).ExpectExec().
-		WithArgs(destination.Country, destination.City, destination.ItineraryID, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(destination.Country, destination.City, nil, nil, nil, nil, destination.ItineraryID, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Act
@@ -175,7 +201,7 @@ func TestDestinationCreate_PrepareError(t *testing.T) {
 		DepartureDate: time.Now().Add(24 * time.Hour),
 	}
 
-	query := `INSERT INTO itinerary_travel_destinations \(country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`
+	query := `INSERT INTO itinerary_travel_destinations \(country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?, \?, \?\)`
 	mock.ExpectPrepare(query).WillReturnError(sql.ErrConnDone)
 
 	// Act
@@ -224,10 +250,10 @@ func TestDestinationCreate_ExecError(t *testing.T) {
 		DepartureDate: time.Now().Add(24 * time.Hour),
 	}
 
-	query := `INSERT INTO itinerary_travel_destinations \(country, city, itinerary_id, arrival_date, departure_date, creation_date, update_date\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`
+	query := `INSERT INTO itinerary_travel_destinations \(country, city, country_code, latitude, longitude, time_zone, itinerary_id, arrival_date, departure_date, creation_date, update_date\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?, \?, \?\)`
 	mock.ExpectPrepare(query)
 	mock.ExpectExec(query).
-		WithArgs(destination.Country, destination.City, destination.ItineraryID, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(destination.Country, destination.City, nil, nil, nil, nil, destination.ItineraryID, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(sql.ErrNoRows)
 
 	// Act
@@ -240,165 +266,153 @@ func TestDestinationCreate_ExecError(t *testing.T) {
 	mock.ExpectRollback()
 }
 
-func TestDestinationUpdate_Success(t *testing.T) {
-	// Arrange
+const (
+	updateDestinationQuery = `UPDATE itinerary_travel_destinations SET country = \?, city = \?, country_code = \?, latitude = \?, longitude = \?, time_zone = \?, arrival_date = \?, departure_date = \?, update_date = \? WHERE id = \? AND itinerary_id = \?`
+	deleteDestinationQuery = `DELETE FROM itinerary_travel_destinations WHERE id = \? AND itinerary_id = \?`
+)
+
+// beginDestinationTx starts the transaction destinations are updated and deleted in
+func beginDestinationTx(t *testing.T) (*sql.Tx, sqlmock.Sqlmock) {
 	dbMock, mock, err := sqlmock.New()
 	assert.NoError(t, err)
-	defer dbMock.Close()
-
+	t.Cleanup(func() { dbMock.Close() })
 	db.DB = dbMock
 
-	destination := &ItineraryTravelDestination{
+	mock.ExpectBegin()
+	tx, err := db.DB.Begin()
+	assert.NoError(t, err)
+	return tx, mock
+}
+
+func newTestDestination() *ItineraryTravelDestination {
+	return &ItineraryTravelDestination{
 		ID:            1,
+		ItineraryID:   2,
 		Country:       "USA",
 		City:          "New York",
 		ArrivalDate:   time.Now(),
 		DepartureDate: time.Now().Add(24 * time.Hour),
 	}
+}
 
-	query := `UPDATE itinerary_travel_destinations SET country = \?, city = \?, arrival_date = \?, departure_date = \?, update_date = \? WHERE id = \?`
-	mock.ExpectPrepare(query).ExpectExec().
-		WithArgs(destination.Country, destination.City, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), destination.ID).
+func TestDestinationUpdate_Success(t *testing.T) {
+	// Arrange
+	tx, mock := beginDestinationTx(t)
+	destination := newTestDestination()
+
+	mock.ExpectPrepare(updateDestinationQuery).ExpectExec().
+		WithArgs(destination.Country, destination.City, nil, nil, nil, nil, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), int64(1), int64(2)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	// Act
-	err = destination.defaultUpdate()
+	err := destination.defaultUpdate(tx)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestDestinationUpdate_PrepareError(t *testing.T) {
+func TestDestinationUpdate_NotFound(t *testing.T) {
 	// Arrange
-	dbMock, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer dbMock.Close()
+	tx, mock := beginDestinationTx(t)
+	destination := newTestDestination()
 
-	db.DB = dbMock
+	mock.ExpectPrepare(updateDestinationQuery).ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 0))
 
-	destination := &ItineraryTravelDestination{
-		ID:            1,
-		Country:       "USA",
-		City:          "New York",
-		ArrivalDate:   time.Now(),
-		DepartureDate: time.Now().Add(24 * time.Hour),
-	}
+	// Act
+	err := destination.defaultUpdate(tx)
 
-	query := `UPDATE itinerary_travel_destinations SET country = \?, city = \?, arrival_date = \?, departure_date = \?, update_date = \? WHERE id = \?`
-	mock.ExpectPrepare(query).WillReturnError(sql.ErrConnDone)
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestinationUpdate_PrepareError(t *testing.T) {
+	// Arrange
+	tx, mock := beginDestinationTx(t)
+
+	mock.ExpectPrepare(updateDestinationQuery).WillReturnError(sql.ErrConnDone)
 
 	// Act
-	err = destination.defaultUpdate()
+	err := newTestDestination().defaultUpdate(tx)
 
 	// Assert
-	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDestinationUpdate_ExecError(t *testing.T) {
 	// Arrange
-	dbMock, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer dbMock.Close()
-
-	db.DB = dbMock
-
-	destination := &ItineraryTravelDestination{
-		ID:            1,
-		Country:       "USA",
-		City:          "New York",
-		ArrivalDate:   time.Now(),
-		DepartureDate: time.Now().Add(24 * time.Hour),
-	}
+	tx, mock := beginDestinationTx(t)
 
-	query := `UPDATE itinerary_travel_destinations SET country = \?, city = \?, arrival_date = \?, departure_date = \?, update_date = \? WHERE id = \?`
-	mock.ExpectPrepare(query)
-	mock.ExpectExec(query).
-		WithArgs(destination.Country, destination.City, destination.ArrivalDate, destination.DepartureDate, sqlmock.AnyArg(), destination.ID).
-		WillReturnError(sql.ErrNoRows)
+	mock.ExpectPrepare(updateDestinationQuery).ExpectExec().WillReturnError(sql.ErrConnDone)
 
 	// Act
-	err = destination.defaultUpdate()
+	err := newTestDestination().defaultUpdate(tx)
 
 	// Assert
-	assert.Error(t, err)
-	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDestinationDelete_Success(t *testing.T) {
 	// Arrange
-	dbMock, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer dbMock.Close()
-
-	db.DB = dbMock
-
-	destination := &ItineraryTravelDestination{
-		ID: 1,
-	}
+	tx, mock := beginDestinationTx(t)
 
-	query := `DELETE FROM itinerary_travel_destinations WHERE id = \?`
-	mock.ExpectPrepare(query).ExpectExec().
+	mock.ExpectPrepare(deleteDestinationQuery).ExpectExec().
+		WithArgs(int64(1), int64(2)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	// Act
-	err = destination.defaultDelete()
+	err := newTestDestination().defaultDelete(tx)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestDestinationDelete_PrepareError(t *testing.T) {
+func TestDestinationDelete_NotFound(t *testing.T) {
 	// Arrange
-	dbMock, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer dbMock.Close()
+	tx, mock := beginDestinationTx(t)
 
-	db.DB = dbMock
+	mock.ExpectPrepare(deleteDestinationQuery).ExpectExec().
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 
-	destination := &ItineraryTravelDestination{
-		ID: 1,
-	}
+	// Act
+	err := newTestDestination().defaultDelete(tx)
 
-	query := `DELETE FROM itinerary_travel_destinations WHERE id = \?`
-	mock.ExpectPrepare(query).WillReturnError(sql.ErrConnDone)
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestinationDelete_PrepareError(t *testing.T) {
+	// Arrange
+	tx, mock := beginDestinationTx(t)
+
+	mock.ExpectPrepare(deleteDestinationQuery).WillReturnError(sql.ErrConnDone)
 
 	// Act
-	err = destination.defaultDelete()
+	err := newTestDestination().defaultDelete(tx)
 
 	// Assert
-	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDestinationDelete_ExecError(t *testing.T) {
 	// Arrange
-	dbMock, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer dbMock.Close()
-
-	db.DB = dbMock
-
-	destination := &ItineraryTravelDestination{
-		ID: 1,
-	}
+	tx, mock := beginDestinationTx(t)
 
-	query := `DELETE FROM itinerary_travel_destinations WHERE id = \?`
-	mock.ExpectPrepare(query).ExpectExec().
-		WithArgs(destination.ID).
-		WillReturnError(sql.ErrNoRows)
+	mock.ExpectPrepare(deleteDestinationQuery).ExpectExec().WillReturnError(sql.ErrConnDone)
 
 	// Act
-	err = destination.defaultDelete()
+	err := newTestDestination().defaultDelete(tx)
 
 	// Assert
-	assert.Error(t, err)
-	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
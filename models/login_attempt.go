@@ -0,0 +1,158 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// LoginAttempt keeps the failed login counter of a single throttling key. A key identifies either an account (by its email)
+// or a source IP, so the same table is used for both kinds of brute-force protection.
+type LoginAttempt struct {
+	AttemptKey      string
+	FailedCount     int
+	LastFailureDate *time.Time
+	LockedUntil     *time.Time
+
+	FindByKey       func(attemptKey string) (*LoginAttempt, error)                                     `json:"-"`
+	RegisterFailure func(attemptKey string, failureDate time.Time, resetBefore time.Time) (int, error) `json:"-"`
+	SetLockedUntil  func(attemptKey string, failedCount int, lockedUntil *time.Time) error             `json:"-"`
+	DeleteByKey     func(attemptKey string) error                                                      `json:"-"`
+	DeleteExpired   func(before time.Time) (int64, error)                                              `json:"-"`
+}
+
+var InitLoginAttempt = func() *LoginAttempt {
+	return InitLoginAttemptFunctions(&LoginAttempt{})
+}
+
+var InitLoginAttemptFunctions = func(loginAttempt *LoginAttempt) *LoginAttempt {
+	// Set default SQL implementations for FindByKey, RegisterFailure, SetLockedUntil, DeleteByKey and DeleteExpired. In the future
+	// there could be implementations for other NoSQL DB systems like MongoDB
+	loginAttempt.FindByKey = loginAttempt.defaultFindByKey
+	loginAttempt.RegisterFailure = loginAttempt.defaultRegisterFailure
+	loginAttempt.SetLockedUntil = loginAttempt.defaultSetLockedUntil
+	loginAttempt.DeleteByKey = loginAttempt.defaultDeleteByKey
+	loginAttempt.DeleteExpired = loginAttempt.defaultDeleteExpired
+
+	return loginAttempt
+}
+
+var NewLoginAttempt = func(attemptKey string) *LoginAttempt {
+	loginAttempt := &LoginAttempt{
+		AttemptKey: attemptKey,
+	}
+
+	return InitLoginAttemptFunctions(loginAttempt)
+}
+
+func (la *LoginAttempt) defaultFindByKey(attemptKey string) (*LoginAttempt, error) {
+	query := `SELECT attempt_key, failed_count, last_failure_date, locked_until
+	FROM login_attempts WHERE attempt_key = ?`
+	row := db.DB.QueryRow(query, attemptKey)
+
+	loginAttempt := &LoginAttempt{}
+
+	var lockedUntil sql.NullTime
+	err := row.Scan(&loginAttempt.AttemptKey, &loginAttempt.FailedCount, &loginAttempt.LastFailureDate, &lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		loginAttempt.LockedUntil = &lockedUntil.Time
+	}
+
+	return loginAttempt, nil
+}
+
+// defaultRegisterFailure increases the failed counter of the key in a single statement and returns the new count, so concurrent
+// failed logins can never overwrite each other's increments. The counter starts again from one when the previous failure happened
+// before resetBefore
+func (la *LoginAttempt) defaultRegisterFailure(attemptKey string, failureDate time.Time, resetBefore time.Time) (int, error) {
+	query := `INSERT INTO login_attempts (attempt_key, failed_count, last_failure_date, locked_until)
+	VALUES (?, 1, ?, NULL)
+	ON CONFLICT (attempt_key) DO UPDATE SET
+		failed_count = CASE WHEN login_attempts.last_failure_date < ? THEN 1 ELSE login_attempts.failed_count + 1 END,
+		last_failure_date = excluded.last_failure_date
+	RETURNING failed_count`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for registering failed login attempt: %v", err)
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var failedCount int
+	err = stmt.QueryRow(attemptKey, failureDate, resetBefore).Scan(&failedCount)
+	if err != nil {
+		log.Errorf("Error executing statement for registering failed login attempt: %v", err)
+		return 0, err
+	}
+
+	return failedCount, nil
+}
+
+// defaultSetLockedUntil sets the delay or lockout of the key only while its counter is still failedCount, so the lock computed for
+// an older failure never replaces the one of a newer failure
+func (la *LoginAttempt) defaultSetLockedUntil(attemptKey string, failedCount int, lockedUntil *time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = ? WHERE attempt_key = ? AND failed_count = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for locking login attempt: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(lockedUntil, attemptKey, failedCount)
+	if err != nil {
+		log.Errorf("Error executing statement for locking login attempt: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (la *LoginAttempt) defaultDeleteByKey(attemptKey string) error {
+	query := `DELETE FROM login_attempts WHERE attempt_key = ?`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for deleting login attempt: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(attemptKey)
+	if err != nil {
+		log.Errorf("Error executing statement for deleting login attempt: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteExpired deletes the counters whose last failure happened before the given date and which are no longer locked.
+// Returns the number of deleted counters
+func (la *LoginAttempt) defaultDeleteExpired(before time.Time) (int64, error) {
+	query := `DELETE FROM login_attempts WHERE last_failure_date < ? AND (locked_until IS NULL OR locked_until < ?)`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for deleting expired login attempts: %v", err)
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(before, before)
+	if err != nil {
+		log.Errorf("Error executing statement for deleting expired login attempts: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttempt_FindByKey_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	lockedUntil := now.Add(15 * time.Minute)
+
+	mock.ExpectQuery("SELECT attempt_key, failed_count, last_failure_date, locked_until FROM login_attempts WHERE attempt_key = \\?").
+		WithArgs("account:test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"attempt_key", "failed_count", "last_failure_date", "locked_until"}).
+			AddRow("account:test@example.com", 10, now, lockedUntil))
+
+	loginAttempt, err := InitLoginAttempt().FindByKey("account:test@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "account:test@example.com", loginAttempt.AttemptKey)
+	assert.Equal(t, 10, loginAttempt.FailedCount)
+	assert.NotNil(t, loginAttempt.LockedUntil)
+	assert.True(t, lockedUntil.Equal(*loginAttempt.LockedUntil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_FindByKey_NullLockedUntil(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT attempt_key, failed_count, last_failure_date, locked_until FROM login_attempts WHERE attempt_key = \\?").
+		WithArgs("ip:127.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"attempt_key", "failed_count", "last_failure_date", "locked_until"}).
+			AddRow("ip:127.0.0.1", 1, time.Now(), nil))
+
+	loginAttempt, err := InitLoginAttempt().FindByKey("ip:127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loginAttempt.FailedCount)
+	assert.Nil(t, loginAttempt.LockedUntil)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_FindByKey_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT attempt_key, failed_count, last_failure_date, locked_until FROM login_attempts WHERE attempt_key = \\?").
+		WithArgs("account:unknown@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"attempt_key", "failed_count", "last_failure_date", "locked_until"}))
+
+	loginAttempt, err := InitLoginAttempt().FindByKey("account:unknown@example.com")
+	assert.Nil(t, loginAttempt)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_RegisterFailure_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	resetBefore := now.Add(-15 * time.Minute)
+
+	mock.ExpectPrepare("INSERT INTO login_attempts .* ON CONFLICT \\(attempt_key\\) DO UPDATE SET .*failed_count \\+ 1.* RETURNING failed_count").
+		ExpectQuery().
+		WithArgs("account:test@example.com", now, resetBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"failed_count"}).AddRow(4))
+
+	failedCount, err := InitLoginAttempt().RegisterFailure("account:test@example.com", now, resetBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, failedCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_RegisterFailure_Error(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("INSERT INTO login_attempts").
+		ExpectQuery().
+		WillReturnError(errors.New("upsert error"))
+
+	_, err = InitLoginAttempt().RegisterFailure("account:test@example.com", time.Now(), time.Now())
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_SetLockedUntil_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	lockedUntil := time.Now().Add(15 * time.Minute)
+
+	mock.ExpectPrepare("UPDATE login_attempts SET locked_until = \\? WHERE attempt_key = \\? AND failed_count = \\?").
+		ExpectExec().
+		WithArgs(&lockedUntil, "account:test@example.com", 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = InitLoginAttempt().SetLockedUntil("account:test@example.com", 10, &lockedUntil)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_DeleteExpired_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	before := time.Now().Add(-15 * time.Minute)
+
+	mock.ExpectPrepare("DELETE FROM login_attempts WHERE last_failure_date < \\?").
+		ExpectExec().
+		WithArgs(before, before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := InitLoginAttempt().DeleteExpired(before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_DeleteByKey_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("DELETE FROM login_attempts WHERE attempt_key = \\?").
+		ExpectExec().
+		WithArgs("account:test@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = InitLoginAttempt().DeleteByKey("account:test@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttempt_DeleteByKey_PrepareError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectPrepare("DELETE FROM login_attempts WHERE attempt_key = \\?").
+		WillReturnError(errors.New("prepare error"))
+
+	err = InitLoginAttempt().DeleteByKey("account:test@example.com")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,13 @@
+package models
+
+// Place is a city of the gazetteer destinations are normalized against, suggested while typing a destination. Country is the
+// normalized name of the country and CountryCode its ISO 3166-1 alpha-2 code
+type Place struct {
+	City        string  `json:"city" example:"Madrid"`
+	Country     string  `json:"country" example:"Spain"`
+	CountryCode string  `json:"countryCode" example:"ES"`
+	Latitude    float64 `json:"latitude" example:"40.4165"`
+	Longitude   float64 `json:"longitude" example:"-3.70256"`
+	TimeZone    string  `json:"timeZone" example:"Europe/Madrid"`
+	Population  int64   `json:"population" example:"3255944"`
+}
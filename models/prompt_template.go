@@ -0,0 +1,218 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// PromptTemplate is a version of a named template of the prompt the itineraries are generated with. Global templates (no OwnerID) are
+// managed by the administrators and used by everyone, and users can have their own variants with the same or other names. Versions are
+// numbered from 1 for each name and owner and never change, so the jobs generated with a version can be reproduced. Deleting a template
+// hides all its versions, which are kept for the jobs generated with them
+type PromptTemplate struct {
+	ID            int64     `json:"id" example:"1"`
+	Name          string    `json:"name" example:"itinerary"`
+	Version       int64     `json:"version" example:"2"`
+	OwnerID       *int64    `json:"ownerId,omitempty" example:"1"`
+	AuthorID      int64     `json:"authorId" example:"1"`
+	SystemMessage string    `json:"systemMessage" example:"You are a helpful expert and guide of international travel."`
+	Template      string    `json:"template" example:"Create a relaxed travel itinerary titled {{.title}}."`
+	CreationDate  time.Time `json:"creationDate" example:"2024-06-01T00:00:00Z"`
+
+	FindById          func(id int64) (*PromptTemplate, error)                      `json:"-"`
+	FindLatest        func(ownerId *int64, name string) (*PromptTemplate, error)   `json:"-"`
+	FindAllLatest     func(ownerId *int64) ([]*PromptTemplate, error)              `json:"-"`
+	FindVersions      func(ownerId *int64, name string) ([]*PromptTemplate, error) `json:"-"`
+	Create            func() error                                                 `json:"-"`
+	SoftDelete        func() error                                                 `json:"-"`
+	DeleteByOwnerIdTx func(ownerId int64, tx *sql.Tx) error                        `json:"-"`
+}
+
+var InitPromptTemplate = func() *PromptTemplate {
+	return InitPromptTemplateFunctions(&PromptTemplate{})
+}
+
+var InitPromptTemplateFunctions = func(promptTemplate *PromptTemplate) *PromptTemplate {
+	// Set default SQL implementations for FindById, FindLatest, FindAllLatest, FindVersions, Create, SoftDelete and DeleteByOwnerIdTx. In
+	// the future there could be implementations for other NoSQL DB systems like MongoDB
+	promptTemplate.FindById = promptTemplate.defaultFindById
+	promptTemplate.FindLatest = promptTemplate.defaultFindLatest
+	promptTemplate.FindAllLatest = promptTemplate.defaultFindAllLatest
+	promptTemplate.FindVersions = promptTemplate.defaultFindVersions
+	promptTemplate.Create = promptTemplate.defaultCreate
+	promptTemplate.SoftDelete = promptTemplate.defaultSoftDelete
+	promptTemplate.DeleteByOwnerIdTx = promptTemplate.defaultDeleteByOwnerIdTx
+
+	return promptTemplate
+}
+
+const promptTemplateColumns = `id, name, version, owner_id, author_id, system_message, template, creation_date`
+
+// defaultFindById retrieves a version of a template even if the template was deleted, so the jobs generated with it can be reproduced
+func (pt *PromptTemplate) defaultFindById(id int64) (*PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + ` FROM prompt_templates WHERE id = ?`
+	promptTemplate, err := scanPromptTemplate(db.DB.QueryRow(query, id))
+	if err != nil {
+		log.Errorf("Error fetching prompt template %d: %v", id, err)
+		return nil, err
+	}
+
+	return promptTemplate, nil
+}
+
+// defaultFindLatest retrieves the latest version of a template of the owner, or of a global template if the owner is nil
+func (pt *PromptTemplate) defaultFindLatest(ownerId *int64, name string) (*PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + ` FROM prompt_templates
+	WHERE owner_id IS ? AND name = ? AND deletion_date IS NULL ORDER BY version DESC LIMIT 1`
+	promptTemplate, err := scanPromptTemplate(db.DB.QueryRow(query, ownerId, name))
+	if err != nil {
+		log.Errorf("Error fetching latest version of prompt template %s: %v", name, err)
+		return nil, err
+	}
+
+	return promptTemplate, nil
+}
+
+// defaultFindAllLatest retrieves the latest version of every template of the owner, or of every global template if the owner is nil,
+// sorted by name
+func (pt *PromptTemplate) defaultFindAllLatest(ownerId *int64) ([]*PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + ` FROM prompt_templates p
+	WHERE owner_id IS ? AND deletion_date IS NULL
+	AND version = (SELECT MAX(version) FROM prompt_templates WHERE owner_id IS p.owner_id AND name = p.name)
+	ORDER BY name`
+	return queryPromptTemplates(query, ownerId)
+}
+
+// defaultFindVersions retrieves the versions of a template of the owner, or of a global template if the owner is nil, from the newest
+func (pt *PromptTemplate) defaultFindVersions(ownerId *int64, name string) ([]*PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + ` FROM prompt_templates
+	WHERE owner_id IS ? AND name = ? AND deletion_date IS NULL ORDER BY version DESC`
+	return queryPromptTemplates(query, ownerId, name)
+}
+
+func queryPromptTemplates(query string, args ...any) ([]*PromptTemplate, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		log.Errorf("Error fetching prompt templates: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	promptTemplates := []*PromptTemplate{}
+	for rows.Next() {
+		promptTemplate, err := scanPromptTemplate(rows)
+		if err != nil {
+			log.Errorf("Error scanning prompt template: %v", err)
+			return nil, err
+		}
+		promptTemplates = append(promptTemplates, promptTemplate)
+	}
+
+	return promptTemplates, rows.Err()
+}
+
+type promptTemplateScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromptTemplate(row promptTemplateScanner) (*PromptTemplate, error) {
+	promptTemplate := &PromptTemplate{}
+	err := row.Scan(&promptTemplate.ID, &promptTemplate.Name, &promptTemplate.Version, &promptTemplate.OwnerID, &promptTemplate.AuthorID,
+		&promptTemplate.SystemMessage, &promptTemplate.Template, &promptTemplate.CreationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return promptTemplate, nil
+}
+
+// defaultCreate saves the template as the next version of its name and owner. The versions of a deleted template keep being counted, so
+// a version number always identifies the same content
+func (pt *PromptTemplate) defaultCreate() error {
+	pt.CreationDate = time.Now()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for prompt template creation: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `INSERT INTO prompt_templates(name, version, owner_id, author_id, system_message, template, creation_date)
+	VALUES (?, (SELECT COALESCE(MAX(version), 0) + 1 FROM prompt_templates WHERE owner_id IS ? AND name = ?), ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(query, pt.Name, pt.OwnerID, pt.Name, pt.OwnerID, pt.AuthorID, pt.SystemMessage, pt.Template, pt.CreationDate)
+	if err != nil {
+		log.Errorf("Error executing insert for prompt template %s: %v", pt.Name, err)
+		return err
+	}
+
+	pt.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for prompt template %s: %v", pt.Name, err)
+		return err
+	}
+
+	err = tx.QueryRow(`SELECT version FROM prompt_templates WHERE id = ?`, pt.ID).Scan(&pt.Version)
+	if err != nil {
+		log.Errorf("Error fetching version of prompt template %d: %v", pt.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultSoftDelete hides all the versions of the template with the name and owner of this one. Returns sql.ErrNoRows if there is no
+// such template
+func (pt *PromptTemplate) defaultSoftDelete() error {
+	query := `UPDATE prompt_templates SET deletion_date = ? WHERE owner_id IS ? AND name = ? AND deletion_date IS NULL`
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing soft delete for prompt template %s: %v", pt.Name, err)
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(time.Now(), pt.OwnerID, pt.Name)
+	if err != nil {
+		log.Errorf("Error executing soft delete for prompt template %s: %v", pt.Name, err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Errorf("Error getting rows affected for prompt template %s: %v", pt.Name, err)
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes every version of the templates of an owner
+func (pt *PromptTemplate) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM prompt_templates WHERE owner_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for prompt templates of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for prompt templates of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var promptTemplateTestColumns = []string{"id", "name", "version", "owner_id", "author_id", "system_message", "template", "creation_date"}
+
+func TestPromptTemplate_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	ownerId := int64(3)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO prompt_templates\(name, version, owner_id, author_id, system_message, template, creation_date\)\s+`+
+		`VALUES \(\?, \(SELECT COALESCE\(MAX\(version\), 0\) \+ 1 FROM prompt_templates WHERE owner_id IS \? AND name = \?\)`).
+		WithArgs("kids", &ownerId, "kids", &ownerId, int64(3), "You are a guide.", "Plan {{.title}}", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectQuery("SELECT version FROM prompt_templates WHERE id = \\?").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectCommit()
+
+	promptTemplate := InitPromptTemplate()
+	promptTemplate.Name = "kids"
+	promptTemplate.OwnerID = &ownerId
+	promptTemplate.AuthorID = 3
+	promptTemplate.SystemMessage = "You are a guide."
+	promptTemplate.Template = "Plan {{.title}}"
+
+	assert.NoError(t, promptTemplate.Create())
+	assert.Equal(t, int64(7), promptTemplate.ID)
+	assert.Equal(t, int64(2), promptTemplate.Version)
+	assert.False(t, promptTemplate.CreationDate.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromptTemplate_Create_InsertError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO prompt_templates").WillReturnError(errors.New("constraint failed"))
+	mock.ExpectRollback()
+
+	promptTemplate := InitPromptTemplate()
+	promptTemplate.Name = "itinerary"
+	assert.EqualError(t, promptTemplate.Create(), "constraint failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromptTemplate_FindLatest_Global(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM prompt_templates\\s+WHERE owner_id IS \\? AND name = \\? AND deletion_date IS NULL ORDER BY version DESC LIMIT 1").
+		WithArgs(nil, "itinerary").
+		WillReturnRows(sqlmock.NewRows(promptTemplateTestColumns).AddRow(4, "itinerary", 3, nil, 1, "You are a guide.", "Plan {{.title}}", now))
+
+	promptTemplate, err := InitPromptTemplate().FindLatest(nil, "itinerary")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), promptTemplate.ID)
+	assert.Equal(t, int64(3), promptTemplate.Version)
+	assert.Nil(t, promptTemplate.OwnerID)
+	assert.Equal(t, "Plan {{.title}}", promptTemplate.Template)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromptTemplate_FindVersions_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	ownerId := int64(3)
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM prompt_templates\\s+WHERE owner_id IS \\? AND name = \\? AND deletion_date IS NULL ORDER BY version DESC").
+		WithArgs(&ownerId, "kids").
+		WillReturnRows(sqlmock.NewRows(promptTemplateTestColumns).
+			AddRow(8, "kids", 2, 3, 3, "You are a guide.", "Plan {{.title}} for kids", now).
+			AddRow(7, "kids", 1, 3, 3, "You are a guide.", "Plan {{.title}}", now))
+
+	promptTemplates, err := InitPromptTemplate().FindVersions(&ownerId, "kids")
+	assert.NoError(t, err)
+	assert.Len(t, promptTemplates, 2)
+	assert.Equal(t, int64(2), promptTemplates[0].Version)
+	assert.Equal(t, ownerId, *promptTemplates[1].OwnerID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromptTemplate_SoftDelete_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	ownerId := int64(3)
+	mock.ExpectPrepare("UPDATE prompt_templates SET deletion_date = \\? WHERE owner_id IS \\? AND name = \\? AND deletion_date IS NULL").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), &ownerId, "kids").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	promptTemplate := InitPromptTemplate()
+	promptTemplate.Name = "kids"
+	promptTemplate.OwnerID = &ownerId
+	assert.ErrorIs(t, promptTemplate.SoftDelete(), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,330 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Resources that can be accessed through a share link
+const (
+	ShareLinkResourceItinerary = "itinerary"
+	ShareLinkResourceFile      = "file"
+)
+
+// ShareLink gives read-only access to an itinerary, and optionally to a specific job file, to anyone knowing its token, without an
+// account. Only the hash of the token and of the optional password are stored.
+type ShareLink struct {
+	ID                 int64      `json:"id" example:"1"`
+	ItineraryID        int64      `json:"itineraryId" example:"1"`
+	ItineraryFileJobID *int64     `json:"itineraryJobId,omitempty" example:"3"`
+	UserID             int64      `json:"-"`
+	TokenPrefix        string     `json:"tokenPrefix" example:"tas_1a2b3c4d"`
+	TokenHash          string     `json:"-"`
+	PasswordHash       *string    `json:"-"`
+	HasPassword        bool       `json:"hasPassword" example:"true"`
+	CreationDate       time.Time  `json:"creationDate" example:"2024-06-01T00:00:00Z"`
+	ExpirationDate     *time.Time `json:"expirationDate,omitempty" example:"2024-07-01T00:00:00Z"`
+	RevocationDate     *time.Time `json:"revocationDate,omitempty" example:"2024-06-03T00:00:00Z"`
+	AccessCount        int64      `json:"accessCount" example:"12"`
+	LastAccessDate     *time.Time `json:"lastAccessDate,omitempty" example:"2024-06-02T00:00:00Z"`
+
+	FindById              func(id int64) (*ShareLink, error)            `json:"-"`
+	FindByTokenHash       func(tokenHash string) (*ShareLink, error)    `json:"-"`
+	FindByItineraryId     func(itineraryId int64) ([]*ShareLink, error) `json:"-"`
+	Create                func() error                                  `json:"-"`
+	Revoke                func() error                                  `json:"-"`
+	RegisterAccess        func(resource string, clientIp string) error  `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error     `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error         `json:"-"`
+}
+
+var InitShareLink = func() *ShareLink {
+	return InitShareLinkFunctions(&ShareLink{})
+}
+
+var InitShareLinkFunctions = func(shareLink *ShareLink) *ShareLink {
+	// Set default SQL implementations for FindById, FindByTokenHash, FindByItineraryId, Create, Revoke, RegisterAccess, DeleteByItineraryIdTx
+	// and DeleteByOwnerIdTx. In the future there could be implementations for other NoSQL DB systems like MongoDB
+	shareLink.FindById = shareLink.defaultFindById
+	shareLink.FindByTokenHash = shareLink.defaultFindByTokenHash
+	shareLink.FindByItineraryId = shareLink.defaultFindByItineraryId
+	shareLink.Create = shareLink.defaultCreate
+	shareLink.Revoke = shareLink.defaultRevoke
+	shareLink.RegisterAccess = shareLink.defaultRegisterAccess
+	shareLink.DeleteByItineraryIdTx = shareLink.defaultDeleteByItineraryIdTx
+	shareLink.DeleteByOwnerIdTx = shareLink.defaultDeleteByOwnerIdTx
+
+	return shareLink
+}
+
+var NewShareLink = func(userId int64, itineraryId int64, itineraryFileJobId *int64, expirationDate *time.Time) *ShareLink {
+	shareLink := &ShareLink{
+		UserID:             userId,
+		ItineraryID:        itineraryId,
+		ItineraryFileJobID: itineraryFileJobId,
+		ExpirationDate:     expirationDate,
+	}
+
+	return InitShareLinkFunctions(shareLink)
+}
+
+// IsActive tells whether the link has not been revoked and has not expired at the given time
+func (sl *ShareLink) IsActive(now time.Time) bool {
+	if sl.RevocationDate != nil {
+		return false
+	}
+	return sl.ExpirationDate == nil || now.Before(*sl.ExpirationDate)
+}
+
+const shareLinkColumns = `id, itinerary_id, itinerary_file_job_id, user_id, token_prefix, token_hash, password_hash, creation_date, expiration_date,
+	revocation_date, access_count, last_access_date`
+
+type shareLinkScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanShareLink(scanner shareLinkScanner) (*ShareLink, error) {
+	shareLink := &ShareLink{}
+
+	var itineraryFileJobId sql.NullInt64
+	var passwordHash sql.NullString
+	var expirationDate sql.NullTime
+	var revocationDate sql.NullTime
+	var lastAccessDate sql.NullTime
+	err := scanner.Scan(&shareLink.ID, &shareLink.ItineraryID, &itineraryFileJobId, &shareLink.UserID, &shareLink.TokenPrefix, &shareLink.TokenHash,
+		&passwordHash, &shareLink.CreationDate, &expirationDate, &revocationDate, &shareLink.AccessCount, &lastAccessDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if itineraryFileJobId.Valid {
+		shareLink.ItineraryFileJobID = &itineraryFileJobId.Int64
+	}
+	if passwordHash.Valid {
+		shareLink.PasswordHash = &passwordHash.String
+		shareLink.HasPassword = true
+	}
+	if expirationDate.Valid {
+		shareLink.ExpirationDate = &expirationDate.Time
+	}
+	if revocationDate.Valid {
+		shareLink.RevocationDate = &revocationDate.Time
+	}
+	if lastAccessDate.Valid {
+		shareLink.LastAccessDate = &lastAccessDate.Time
+	}
+
+	return shareLink, nil
+}
+
+func (sl *ShareLink) defaultFindById(id int64) (*ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE id = ?`
+	row := db.DB.QueryRow(query, id)
+
+	return scanShareLink(row)
+}
+
+func (sl *ShareLink) defaultFindByTokenHash(tokenHash string) (*ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE token_hash = ?`
+	row := db.DB.QueryRow(query, tokenHash)
+
+	return scanShareLink(row)
+}
+
+func (sl *ShareLink) defaultFindByItineraryId(itineraryId int64) ([]*ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE itinerary_id = ? ORDER BY creation_date DESC, id DESC`
+	rows, err := db.DB.Query(query, itineraryId)
+	if err != nil {
+		log.Errorf("Error querying share links of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shareLinks := []*ShareLink{}
+	for rows.Next() {
+		shareLink, err := scanShareLink(rows)
+		if err != nil {
+			log.Errorf("Error scanning share link row: %v", err)
+			return nil, err
+		}
+		shareLinks = append(shareLinks, shareLink)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating share link rows: %v", err)
+		return nil, err
+	}
+
+	return shareLinks, nil
+}
+
+func (sl *ShareLink) defaultCreate() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for share link creation: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `INSERT INTO share_links(itinerary_id, itinerary_file_job_id, user_id, token_prefix, token_hash, password_hash, creation_date, expiration_date)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing insert for share link: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	sl.CreationDate = time.Now()
+
+	result, err := stmt.Exec(sl.ItineraryID, sl.ItineraryFileJobID, sl.UserID, sl.TokenPrefix, sl.TokenHash, sl.PasswordHash, sl.CreationDate, sl.ExpirationDate)
+	if err != nil {
+		log.Errorf("Error executing insert for share link: %v", err)
+		return err
+	}
+
+	shareLinkId, err := result.LastInsertId()
+	if err != nil {
+		log.Errorf("Error getting last insert ID for share link: %v", err)
+		return err
+	}
+
+	sl.ID = shareLinkId
+	sl.HasPassword = sl.PasswordHash != nil
+
+	auditEvent := NewAuditEvent(sl.UserID, AuditEventShareLinkCreated, fmt.Sprintf("Share link %d of itinerary %d created.", sl.ID, sl.ItineraryID),
+		map[string]any{"shareLinkId": sl.ID, "itineraryId": sl.ItineraryID, "itineraryJobId": sl.ItineraryFileJobID, "hasPassword": sl.HasPassword})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for share link creation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (sl *ShareLink) defaultRevoke() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for share link revocation: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `UPDATE share_links SET revocation_date = ? WHERE id = ? AND revocation_date IS NULL`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for share link revocation: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+
+	_, err = stmt.Exec(now, sl.ID)
+	if err != nil {
+		log.Errorf("Error executing update for share link revocation: %v", err)
+		return err
+	}
+
+	sl.RevocationDate = &now
+
+	auditEvent := NewAuditEvent(sl.UserID, AuditEventShareLinkRevoked, fmt.Sprintf("Share link %d of itinerary %d revoked.", sl.ID, sl.ItineraryID),
+		map[string]any{"shareLinkId": sl.ID, "itineraryId": sl.ItineraryID})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for share link revocation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultRegisterAccess counts an access to the link and records it in the audit log of the user who created the link
+func (sl *ShareLink) defaultRegisterAccess(resource string, clientIp string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for share link access: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := `UPDATE share_links SET access_count = access_count + 1, last_access_date = ? WHERE id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing update for share link access: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+
+	_, err = stmt.Exec(now, sl.ID)
+	if err != nil {
+		log.Errorf("Error executing update for share link access: %v", err)
+		return err
+	}
+
+	sl.AccessCount++
+	sl.LastAccessDate = &now
+
+	auditEvent := NewAuditEvent(sl.UserID, AuditEventShareLinkAccessed, fmt.Sprintf("Share link %d of itinerary %d accessed.", sl.ID, sl.ItineraryID),
+		map[string]any{"shareLinkId": sl.ID, "itineraryId": sl.ItineraryID, "resource": resource, "clientIp": clientIp})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for share link access: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (sl *ShareLink) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM share_links WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for share links of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for share links of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the share links of all the itineraries of an owner
+func (sl *ShareLink) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM share_links WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for share links of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for share links of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var shareLinkTestColumns = []string{"id", "itinerary_id", "itinerary_file_job_id", "user_id", "token_prefix", "token_hash", "password_hash",
+	"creation_date", "expiration_date", "revocation_date", "access_count", "last_access_date"}
+
+func TestShareLink_FindByTokenHash_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	expiration := now.Add(24 * time.Hour)
+
+	mock.ExpectQuery("SELECT (.+) FROM share_links WHERE token_hash = \\?").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(shareLinkTestColumns).
+			AddRow(1, 2, 3, 4, "tas_01234567", "hash", "bcrypt", now, expiration, nil, 5, now))
+
+	shareLink, err := InitShareLink().FindByTokenHash("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), shareLink.ID)
+	assert.Equal(t, int64(2), shareLink.ItineraryID)
+	assert.Equal(t, int64(3), *shareLink.ItineraryFileJobID)
+	assert.Equal(t, int64(4), shareLink.UserID)
+	assert.True(t, shareLink.HasPassword)
+	assert.True(t, expiration.Equal(*shareLink.ExpirationDate))
+	assert.Nil(t, shareLink.RevocationDate)
+	assert.Equal(t, int64(5), shareLink.AccessCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_FindById_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM share_links WHERE id = \\?").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(shareLinkTestColumns))
+
+	shareLink, err := InitShareLink().FindById(5)
+	assert.Nil(t, shareLink)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_FindByItineraryId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM share_links WHERE itinerary_id = \\? ORDER BY creation_date DESC").
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows(shareLinkTestColumns).
+			AddRow(2, 2, nil, 1, "tas_89abcdef", "hash2", nil, now, nil, now, 0, nil).
+			AddRow(1, 2, 3, 1, "tas_01234567", "hash1", "bcrypt", now, nil, nil, 4, now))
+
+	shareLinks, err := InitShareLink().FindByItineraryId(2)
+	assert.NoError(t, err)
+	assert.Len(t, shareLinks, 2)
+	assert.Nil(t, shareLinks[0].ItineraryFileJobID)
+	assert.False(t, shareLinks[0].HasPassword)
+	assert.NotNil(t, shareLinks[0].RevocationDate)
+	assert.True(t, shareLinks[1].HasPassword)
+	assert.NotNil(t, shareLinks[1].LastAccessDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_FindByItineraryId_QueryError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM share_links WHERE itinerary_id = \\?").
+		WillReturnError(errors.New("query error"))
+
+	shareLinks, err := InitShareLink().FindByItineraryId(2)
+	assert.Error(t, err)
+	assert.Nil(t, shareLinks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_Create_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	passwordHash := "bcrypt"
+	shareLink := NewShareLink(1, 2, nil, nil)
+	shareLink.TokenPrefix = "tas_01234567"
+	shareLink.TokenHash = "hash"
+	shareLink.PasswordHash = &passwordHash
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO share_links").
+		ExpectExec().
+		WithArgs(int64(2), nil, int64(1), "tas_01234567", "hash", &passwordHash, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(1), AuditEventShareLinkCreated, "Share link 7 of itinerary 2 created.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = shareLink.Create()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), shareLink.ID)
+	assert.True(t, shareLink.HasPassword)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_Create_ExecError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	shareLink := NewShareLink(1, 2, nil, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO share_links").
+		ExpectExec().
+		WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
+
+	err = shareLink.Create()
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_Revoke_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	shareLink := NewShareLink(1, 2, nil, nil)
+	shareLink.ID = 7
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE share_links SET revocation_date = \\? WHERE id = \\? AND revocation_date IS NULL").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(1), AuditEventShareLinkRevoked, "Share link 7 of itinerary 2 revoked.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = shareLink.Revoke()
+	assert.NoError(t, err)
+	assert.NotNil(t, shareLink.RevocationDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_RegisterAccess_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	shareLink := NewShareLink(1, 2, nil, nil)
+	shareLink.ID = 7
+	shareLink.AccessCount = 3
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE share_links SET access_count = access_count \\+ 1, last_access_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(1), AuditEventShareLinkAccessed, "Share link 7 of itinerary 2 accessed.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = shareLink.RegisterAccess(ShareLinkResourceFile, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), shareLink.AccessCount)
+	assert.NotNil(t, shareLink.LastAccessDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_RegisterAccess_AuditError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	shareLink := NewShareLink(1, 2, nil, nil)
+	shareLink.ID = 7
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE share_links SET access_count").
+		ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
+
+	err = shareLink.RegisterAccess(ShareLinkResourceItinerary, "10.0.0.1")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareLink_IsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.True(t, (&ShareLink{}).IsActive(now))
+	assert.True(t, (&ShareLink{ExpirationDate: &future}).IsActive(now))
+	assert.False(t, (&ShareLink{ExpirationDate: &past}).IsActive(now))
+	assert.False(t, (&ShareLink{RevocationDate: &past}).IsActive(now))
+}
@@ -0,0 +1,350 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/db"
+)
+
+// Paces a traveller can prefer, from few activities a day with plenty of free time to packed days
+const (
+	TravellerPaceRelaxed  = "relaxed"
+	TravellerPaceModerate = "moderate"
+	TravellerPaceIntense  = "intense"
+)
+
+// Budget levels a traveller can prefer for accommodation, food and activities
+const (
+	TravellerBudgetLow      = "budget"
+	TravellerBudgetModerate = "moderate"
+	TravellerBudgetLuxury   = "luxury"
+)
+
+// TravellerPreferences personalise the generated itineraries. A user has at most one profile of preferences (UserID set) and an
+// itinerary at most one set of overrides of the profile of its owner (ItineraryID set). Unset attributes (nil or empty) are not
+// taken into account, so the overrides only need the attributes that differ from the profile
+type TravellerPreferences struct {
+	ID                  int64      `json:"-"`
+	UserID              *int64     `json:"userId,omitempty" example:"1"`
+	ItineraryID         *int64     `json:"itineraryId,omitempty" example:"1"`
+	Interests           []string   `json:"interests,omitempty" example:"museums,street food,hiking"`
+	Pace                string     `json:"pace,omitempty" example:"relaxed"`
+	BudgetLevel         string     `json:"budgetLevel,omitempty" example:"moderate"`
+	DietaryRestrictions []string   `json:"dietaryRestrictions,omitempty" example:"vegetarian"`
+	MobilityNeeds       string     `json:"mobilityNeeds,omitempty" example:"Wheelchair user, no long walks"`
+	Adults              *int       `json:"adults,omitempty" example:"2"`
+	Children            *int       `json:"children,omitempty" example:"1"`
+	Seniors             *int       `json:"seniors,omitempty" example:"0"`
+	Language            string     `json:"language,omitempty" example:"es"`
+	CreationDate        *time.Time `json:"creationDate,omitempty" example:"2024-06-01T00:00:00Z"`
+	UpdateDate          *time.Time `json:"updateDate,omitempty" example:"2024-06-01T00:00:00Z"`
+
+	FindByUserId          func(userId int64) (*TravellerPreferences, error)      `json:"-"`
+	FindByItineraryId     func(itineraryId int64) (*TravellerPreferences, error) `json:"-"`
+	Save                  func() error                                           `json:"-"`
+	Delete                func() error                                           `json:"-"`
+	CopyToItineraryTx     func(itineraryId int64, tx *sql.Tx) error              `json:"-"`
+	DeleteByItineraryIdTx func(itineraryId int64, tx *sql.Tx) error              `json:"-"`
+	DeleteByOwnerIdTx     func(ownerId int64, tx *sql.Tx) error                  `json:"-"`
+	DeleteByUserIdTx      func(userId int64, tx *sql.Tx) error                   `json:"-"`
+}
+
+var InitTravellerPreferences = func() *TravellerPreferences {
+	return InitTravellerPreferencesFunctions(&TravellerPreferences{})
+}
+
+var InitTravellerPreferencesFunctions = func(preferences *TravellerPreferences) *TravellerPreferences {
+	// Set default SQL implementations for FindByUserId, FindByItineraryId, Save, Delete, CopyToItineraryTx, DeleteByItineraryIdTx,
+	// DeleteByOwnerIdTx and DeleteByUserIdTx. In the future there could be implementations for other NoSQL DB systems like MongoDB
+	preferences.FindByUserId = preferences.defaultFindByUserId
+	preferences.FindByItineraryId = preferences.defaultFindByItineraryId
+	preferences.Save = preferences.defaultSave
+	preferences.Delete = preferences.defaultDelete
+	preferences.CopyToItineraryTx = preferences.defaultCopyToItineraryTx
+	preferences.DeleteByItineraryIdTx = preferences.defaultDeleteByItineraryIdTx
+	preferences.DeleteByOwnerIdTx = preferences.defaultDeleteByOwnerIdTx
+	preferences.DeleteByUserIdTx = preferences.defaultDeleteByUserIdTx
+
+	return preferences
+}
+
+// IsEmpty checks whether none of the preferences is set
+func (p *TravellerPreferences) IsEmpty() bool {
+	return p.Interests == nil && p.Pace == "" && p.BudgetLevel == "" && p.DietaryRestrictions == nil && p.MobilityNeeds == "" &&
+		p.Adults == nil && p.Children == nil && p.Seniors == nil && p.Language == ""
+}
+
+// Merge returns the preferences with the ones set in the overrides replacing them. Neither the preferences nor the overrides change.
+// The overrides can be nil
+func (p *TravellerPreferences) Merge(overrides *TravellerPreferences) *TravellerPreferences {
+	merged := &TravellerPreferences{Interests: p.Interests, Pace: p.Pace, BudgetLevel: p.BudgetLevel, DietaryRestrictions: p.DietaryRestrictions,
+		MobilityNeeds: p.MobilityNeeds, Adults: p.Adults, Children: p.Children, Seniors: p.Seniors, Language: p.Language}
+	if overrides == nil {
+		return merged
+	}
+
+	if overrides.Interests != nil {
+		merged.Interests = overrides.Interests
+	}
+	if overrides.Pace != "" {
+		merged.Pace = overrides.Pace
+	}
+	if overrides.BudgetLevel != "" {
+		merged.BudgetLevel = overrides.BudgetLevel
+	}
+	if overrides.DietaryRestrictions != nil {
+		merged.DietaryRestrictions = overrides.DietaryRestrictions
+	}
+	if overrides.MobilityNeeds != "" {
+		merged.MobilityNeeds = overrides.MobilityNeeds
+	}
+	if overrides.Adults != nil {
+		merged.Adults = overrides.Adults
+	}
+	if overrides.Children != nil {
+		merged.Children = overrides.Children
+	}
+	if overrides.Seniors != nil {
+		merged.Seniors = overrides.Seniors
+	}
+	if overrides.Language != "" {
+		merged.Language = overrides.Language
+	}
+
+	return merged
+}
+
+const travellerPreferencesColumns = `id, user_id, itinerary_id, interests, pace, budget_level, dietary_restrictions, mobility_needs, adults,
+	children, seniors, language, creation_date, update_date`
+
+func (p *TravellerPreferences) defaultFindByUserId(userId int64) (*TravellerPreferences, error) {
+	query := `SELECT ` + travellerPreferencesColumns + ` FROM traveller_preferences WHERE user_id = ?`
+	preferences, err := scanTravellerPreferences(db.DB.QueryRow(query, userId))
+	if err != nil {
+		log.Errorf("Error fetching traveller preferences of user %d: %v", userId, err)
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (p *TravellerPreferences) defaultFindByItineraryId(itineraryId int64) (*TravellerPreferences, error) {
+	query := `SELECT ` + travellerPreferencesColumns + ` FROM traveller_preferences WHERE itinerary_id = ?`
+	preferences, err := scanTravellerPreferences(db.DB.QueryRow(query, itineraryId))
+	if err != nil {
+		log.Errorf("Error fetching traveller preferences of itinerary %d: %v", itineraryId, err)
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func scanTravellerPreferences(row *sql.Row) (*TravellerPreferences, error) {
+	preferences := &TravellerPreferences{}
+	var interests, dietaryRestrictions sql.NullString
+	var adults, children, seniors sql.NullInt64
+	err := row.Scan(&preferences.ID, &preferences.UserID, &preferences.ItineraryID, &interests, &preferences.Pace, &preferences.BudgetLevel,
+		&dietaryRestrictions, &preferences.MobilityNeeds, &adults, &children, &seniors, &preferences.Language, &preferences.CreationDate,
+		&preferences.UpdateDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lists are stored as JSON arrays, keeping apart the unset (NULL) and empty ones
+	if interests.Valid {
+		err = json.Unmarshal([]byte(interests.String), &preferences.Interests)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dietaryRestrictions.Valid {
+		err = json.Unmarshal([]byte(dietaryRestrictions.String), &preferences.DietaryRestrictions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	preferences.Adults = nullIntToPointer(adults)
+	preferences.Children = nullIntToPointer(children)
+	preferences.Seniors = nullIntToPointer(seniors)
+
+	return preferences, nil
+}
+
+func nullIntToPointer(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	number := int(value.Int64)
+	return &number
+}
+
+// defaultSave saves the preferences of the user or the overrides of the itinerary, whichever is set, replacing the previous ones
+func (p *TravellerPreferences) defaultSave() error {
+	conflictColumn := "user_id"
+	if p.ItineraryID != nil {
+		conflictColumn = "itinerary_id"
+	}
+
+	query := `INSERT INTO traveller_preferences(user_id, itinerary_id, interests, pace, budget_level, dietary_restrictions, mobility_needs,
+	adults, children, seniors, language, creation_date, update_date)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (` + conflictColumn + `) DO UPDATE SET interests = excluded.interests, pace = excluded.pace,
+	budget_level = excluded.budget_level, dietary_restrictions = excluded.dietary_restrictions, mobility_needs = excluded.mobility_needs,
+	adults = excluded.adults, children = excluded.children, seniors = excluded.seniors, language = excluded.language,
+	update_date = excluded.update_date`
+
+	interests, err := marshalNullableList(p.Interests)
+	if err != nil {
+		log.Errorf("Error marshalling traveller interests: %v", err)
+		return err
+	}
+	dietaryRestrictions, err := marshalNullableList(p.DietaryRestrictions)
+	if err != nil {
+		log.Errorf("Error marshalling traveller dietary restrictions: %v", err)
+		return err
+	}
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing upsert for traveller preferences: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(p.UserID, p.ItineraryID, interests, p.Pace, p.BudgetLevel, dietaryRestrictions, p.MobilityNeeds, p.Adults, p.Children,
+		p.Seniors, p.Language, now, now)
+	if err != nil {
+		log.Errorf("Error executing upsert for traveller preferences: %v", err)
+		return err
+	}
+
+	p.UpdateDate = &now
+
+	return nil
+}
+
+func marshalNullableList(list []string) (*string, error) {
+	if list == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+	return &text, nil
+}
+
+// defaultDelete deletes the overrides of the itinerary, or the preferences of the user if no itinerary is set
+func (p *TravellerPreferences) defaultDelete() error {
+	query := `DELETE FROM traveller_preferences WHERE user_id = ?`
+	id := p.UserID
+	if p.ItineraryID != nil {
+		query = `DELETE FROM traveller_preferences WHERE itinerary_id = ?`
+		id = p.ItineraryID
+	}
+
+	stmt, err := db.DB.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for traveller preferences: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(id)
+	if err != nil {
+		log.Errorf("Error executing delete for traveller preferences: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultCopyToItineraryTx copies the overrides of the itinerary of these preferences, if it has any, to another itinerary
+func (p *TravellerPreferences) defaultCopyToItineraryTx(itineraryId int64, tx *sql.Tx) error {
+	query := `INSERT INTO traveller_preferences(user_id, itinerary_id, interests, pace, budget_level, dietary_restrictions, mobility_needs,
+	adults, children, seniors, language, creation_date, update_date)
+	SELECT NULL, ?, interests, pace, budget_level, dietary_restrictions, mobility_needs, adults, children, seniors, language, ?, ?
+	FROM traveller_preferences WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing copy of traveller preferences to itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(itineraryId, now, now, p.ItineraryID)
+	if err != nil {
+		log.Errorf("Error executing copy of traveller preferences to itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+func (p *TravellerPreferences) defaultDeleteByItineraryIdTx(itineraryId int64, tx *sql.Tx) error {
+	query := `DELETE FROM traveller_preferences WHERE itinerary_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for traveller preferences of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(itineraryId)
+	if err != nil {
+		log.Errorf("Error executing delete for traveller preferences of itinerary %d: %v", itineraryId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByOwnerIdTx deletes the overrides of all the itineraries of an owner
+func (p *TravellerPreferences) defaultDeleteByOwnerIdTx(ownerId int64, tx *sql.Tx) error {
+	query := `DELETE FROM traveller_preferences WHERE itinerary_id IN (SELECT id FROM itineraries WHERE owner_id = ?)`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for traveller preferences of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ownerId)
+	if err != nil {
+		log.Errorf("Error executing delete for traveller preferences of the itineraries of owner %d: %v", ownerId, err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDeleteByUserIdTx deletes the preferences of a user
+func (p *TravellerPreferences) defaultDeleteByUserIdTx(userId int64, tx *sql.Tx) error {
+	query := `DELETE FROM traveller_preferences WHERE user_id = ?`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing delete for traveller preferences of user %d: %v", userId, err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(userId)
+	if err != nil {
+		log.Errorf("Error executing delete for traveller preferences of user %d: %v", userId, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var travellerPreferencesTestColumns = []string{"id", "user_id", "itinerary_id", "interests", "pace", "budget_level", "dietary_restrictions",
+	"mobility_needs", "adults", "children", "seniors", "language", "creation_date", "update_date"}
+
+func TestTravellerPreferences_Merge(t *testing.T) {
+	two, zero := 2, 0
+	profile := &TravellerPreferences{Interests: []string{"museums"}, Pace: TravellerPaceIntense, DietaryRestrictions: []string{"vegetarian"},
+		Adults: &two, Language: "es"}
+
+	merged := profile.Merge(&TravellerPreferences{Pace: TravellerPaceRelaxed, DietaryRestrictions: []string{}, Children: &zero})
+	assert.Equal(t, []string{"museums"}, merged.Interests)
+	assert.Equal(t, TravellerPaceRelaxed, merged.Pace)
+	assert.Equal(t, []string{}, merged.DietaryRestrictions)
+	assert.Equal(t, 2, *merged.Adults)
+	assert.Equal(t, 0, *merged.Children)
+	assert.Equal(t, "es", merged.Language)
+	assert.Equal(t, TravellerPaceIntense, profile.Pace)
+
+	assert.Equal(t, profile.Interests, profile.Merge(nil).Interests)
+	assert.True(t, (&TravellerPreferences{}).IsEmpty())
+	assert.False(t, merged.IsEmpty())
+}
+
+func TestTravellerPreferences_FindByUserId_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM traveller_preferences WHERE user_id = \\?").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows(travellerPreferencesTestColumns).
+			AddRow(1, 3, nil, `["museums","street food"]`, "relaxed", "luxury", nil, "", 2, nil, 1, "es", now, now))
+
+	preferences, err := InitTravellerPreferences().FindByUserId(3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), *preferences.UserID)
+	assert.Nil(t, preferences.ItineraryID)
+	assert.Equal(t, []string{"museums", "street food"}, preferences.Interests)
+	assert.Nil(t, preferences.DietaryRestrictions)
+	assert.Equal(t, 2, *preferences.Adults)
+	assert.Nil(t, preferences.Children)
+	assert.Equal(t, 1, *preferences.Seniors)
+	assert.Equal(t, "es", preferences.Language)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTravellerPreferences_FindByItineraryId_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM traveller_preferences WHERE itinerary_id = \\?").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(travellerPreferencesTestColumns))
+
+	preferences, err := InitTravellerPreferences().FindByItineraryId(1)
+	assert.Nil(t, preferences)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTravellerPreferences_Save_Itinerary(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	itineraryId := int64(1)
+	children := 2
+	preferences := InitTravellerPreferences()
+	preferences.ItineraryID = &itineraryId
+	preferences.DietaryRestrictions = []string{}
+	preferences.Children = &children
+
+	mock.ExpectPrepare("INSERT INTO traveller_preferences(.+) ON CONFLICT \\(itinerary_id\\) DO UPDATE").
+		ExpectExec().
+		WithArgs(nil, &itineraryId, nil, "", "", "[]", "", nil, &children, nil, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, preferences.Save())
+	assert.NotNil(t, preferences.UpdateDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTravellerPreferences_Delete_User(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+	db.DB = dbMock
+
+	userId := int64(3)
+	preferences := InitTravellerPreferences()
+	preferences.UserID = &userId
+
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE user_id = \\?").
+		ExpectExec().
+		WithArgs(&userId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, preferences.Delete())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"example.com/travel-advisor/db"
@@ -10,31 +11,59 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// Roles lists every role that can be assigned to a user
+var Roles = []string{RoleUser, RoleSupport, RoleAdmin}
+
 type User struct {
 	ID            int64      `json:"id" example:"1"`
 	Email         string     `json:"email" binding:"required" example:"test@example.com"`
-	Password      string     `json:"password" example:"password123"`
+	Password      string     `json:"-"`
+	Role          string     `json:"role" example:"user"`
 	CreationDate  *time.Time `json:"creationDate" example:"2024-01-01T00:00:00Z"`
 	UpdateDate    *time.Time `json:"updateDate" example:"2024-01-01T00:00:00Z"`
 	LastLoginDate *time.Time `json:"lastLoginDate" example:"2024-01-01T00:00:00Z"`
+	DisabledDate  *time.Time `json:"disabledDate,omitempty" example:"2024-01-01T00:00:00Z"`
 
-	FindByEmail         func(email string) (*User, error) `json:"-"`
-	Create              func() error                      `json:"-"`
-	ValidateCredentials func(password string) error       `json:"-"`
-	UpdateLastLoginDate func(*sql.Tx) error               `json:"-"`
+	FindById            func(id int64) (*User, error)            `json:"-"`
+	FindByEmail         func(email string) (*User, error)        `json:"-"`
+	FindAll             func() ([]*User, error)                  `json:"-"`
+	Create              func() error                             `json:"-"`
+	ValidateCredentials func(password string) error              `json:"-"`
+	UpdateLastLoginDate func(*sql.Tx) error                      `json:"-"`
+	UpdateRole          func(role string, actorId int64) error   `json:"-"`
+	UpdateDisabled      func(disabled bool, actorId int64) error `json:"-"`
+	UpdateEmail         func(email string) error                 `json:"-"`
+	UpdatePassword      func(password string) error              `json:"-"`
+	Delete              func() error                             `json:"-"`
 }
 
+// bcrypt hash (same cost as utils.HashPassword) used to equalize the response time of logins with unknown emails
+const dummyPasswordHash = "$2a$14$VPhlpymXkhNa2.wxyVpideV3kAtEjxFX/13mHYHRPqZ7woa3C1IDm"
+
 var InitUser = func() *User {
 	return InitUserFunctions(&User{})
 }
 
 var InitUserFunctions = func(user *User) *User {
-	// Set default SQL implementations for FindByEmail, Create, ValidateCredentials and UpdateLastLoginDate. In the future there could be implementations for
-	// other NoSQL DB systems like MongoDB
+	// Set default SQL implementations for FindById, FindByEmail, FindAll, Create, ValidateCredentials, UpdateLastLoginDate, UpdateRole,
+	// UpdateDisabled, UpdateEmail, UpdatePassword and Delete. In the future there could be implementations for other NoSQL DB systems like MongoDB
+	user.FindById = user.defaultFindById
 	user.FindByEmail = user.defaultFindUser
+	user.FindAll = user.defaultFindAll
 	user.Create = user.defaultCreate
 	user.ValidateCredentials = user.defaultValidateCredentials
 	user.UpdateLastLoginDate = user.defaultUpdateLastLoginDate
+	user.UpdateRole = user.defaultUpdateRole
+	user.UpdateDisabled = user.defaultUpdateDisabled
+	user.UpdateEmail = user.defaultUpdateEmail
+	user.UpdatePassword = user.defaultUpdatePassword
+	user.Delete = user.defaultDelete
 
 	return user
 }
@@ -43,6 +72,7 @@ var NewUser = func(email, password string) *User {
 	user := &User{
 		Email:    email,
 		Password: password,
+		Role:     RoleUser,
 	}
 
 	return InitUserFunctions(user)
@@ -50,8 +80,8 @@ var NewUser = func(email, password string) *User {
 
 func (u *User) defaultCreate() error {
 
-	query := `INSERT INTO users(email, password, creation_date, update_date) 
-	VALUES (?, ?, ?, ?)`
+	query := `INSERT INTO users(email, password, role, creation_date, update_date) 
+	VALUES (?, ?, ?, ?, ?)`
 
 	stmt, err := db.DB.Prepare(query)
 	if err != nil {
@@ -67,7 +97,11 @@ func (u *User) defaultCreate() error {
 		return err
 	}
 
-	result, err := stmt.Exec(u.Email, hashedPassword, time.Now(), time.Now())
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+
+	result, err := stmt.Exec(u.Email, hashedPassword, u.Role, time.Now(), time.Now())
 	if err != nil {
 		log.Errorf("Error executing statement for user creation: %v", err)
 		return err
@@ -102,13 +136,18 @@ func (u *User) defaultFindUser(email string) (*User, error) {
 
 func (u *User) defaultValidateCredentials(password string) error {
 
-	query := "SELECT id,password FROM users WHERE email=?"
+	query := "SELECT id,password,role,disabled_date FROM users WHERE email=?"
 	row := db.DB.QueryRow(query, u.Email)
 
 	var retrievedPassword string
-	err := row.Scan(&u.ID, &retrievedPassword)
+	var disabledDate sql.NullTime
+	err := row.Scan(&u.ID, &retrievedPassword, &u.Role, &disabledDate)
 	if err != nil {
 		log.Errorf("Error retrieving user credentials: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Compare against a dummy hash so unknown emails take as long as wrong passwords and do not reveal which accounts exist
+			utils.CheckPasswordHash(dummyPasswordHash, password)
+		}
 		return err
 	}
 
@@ -118,6 +157,10 @@ func (u *User) defaultValidateCredentials(password string) error {
 		return errors.New("credentials invalid")
 	}
 
+	if disabledDate.Valid {
+		u.DisabledDate = &disabledDate.Time
+	}
+
 	return nil
 }
 
@@ -141,3 +184,325 @@ func (u *User) defaultUpdateLastLoginDate(tx *sql.Tx) error {
 
 	return nil
 }
+
+const userColumns = `id, email, role, creation_date, update_date, last_login_date, disabled_date`
+
+type userScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(scanner userScanner) (*User, error) {
+	user := &User{}
+
+	var creationDate sql.NullTime
+	var updateDate sql.NullTime
+	var lastLoginDate sql.NullTime
+	var disabledDate sql.NullTime
+	err := scanner.Scan(&user.ID, &user.Email, &user.Role, &creationDate, &updateDate, &lastLoginDate, &disabledDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if creationDate.Valid {
+		user.CreationDate = &creationDate.Time
+	}
+	if updateDate.Valid {
+		user.UpdateDate = &updateDate.Time
+	}
+	if lastLoginDate.Valid {
+		user.LastLoginDate = &lastLoginDate.Time
+	}
+	if disabledDate.Valid {
+		user.DisabledDate = &disabledDate.Time
+	}
+
+	return user, nil
+}
+
+func (u *User) defaultFindById(id int64) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	row := db.DB.QueryRow(query, id)
+
+	user, err := scanUser(row)
+	if err != nil {
+		log.Errorf("Error finding user by ID: %v", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *User) defaultFindAll() ([]*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY id`
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		log.Errorf("Error querying users: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			log.Errorf("Error scanning user row: %v", err)
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Errorf("Error iterating user rows: %v", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (u *User) defaultUpdateRole(role string, actorId int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for user role update: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := "UPDATE users SET role = ?, update_date = ? WHERE id = ?"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for updating user role: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(role, now, u.ID)
+	if err != nil {
+		log.Errorf("Error executing statement for updating user role: %v", err)
+		return err
+	}
+
+	u.Role = role
+	u.UpdateDate = &now
+
+	auditEvent := NewAuditEvent(actorId, AuditEventRoleChanged, fmt.Sprintf("Role of user %d changed to %s.", u.ID, role),
+		map[string]any{"userId": u.ID, "role": role})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for user role update: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *User) defaultUpdateDisabled(disabled bool, actorId int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for user disabled status update: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := "UPDATE users SET disabled_date = ?, update_date = ? WHERE id = ?"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for updating user disabled status: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	var disabledDate *time.Time
+	eventType := AuditEventUserEnabled
+	eventDescription := fmt.Sprintf("User %d enabled.", u.ID)
+	if disabled {
+		disabledDate = &now
+		eventType = AuditEventUserDisabled
+		eventDescription = fmt.Sprintf("User %d disabled.", u.ID)
+	}
+
+	_, err = stmt.Exec(disabledDate, now, u.ID)
+	if err != nil {
+		log.Errorf("Error executing statement for updating user disabled status: %v", err)
+		return err
+	}
+
+	u.DisabledDate = disabledDate
+	u.UpdateDate = &now
+
+	auditEvent := NewAuditEvent(actorId, eventType, eventDescription, map[string]any{"userId": u.ID})
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for user disabled status update: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *User) defaultUpdateEmail(email string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for user email update: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := "UPDATE users SET email = ?, update_date = ? WHERE id = ?"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for updating user email: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(email, now, u.ID)
+	if err != nil {
+		log.Errorf("Error executing statement for updating user email: %v", err)
+		return err
+	}
+
+	u.Email = email
+	u.UpdateDate = &now
+
+	auditEvent := NewAuditEvent(u.ID, AuditEventEmailChanged, "User email changed.", nil)
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for user email update: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *User) defaultUpdatePassword(password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Errorf("Error hashing password for user password update: %v", err)
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for user password update: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	query := "UPDATE users SET password = ?, update_date = ? WHERE id = ?"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for updating user password: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	_, err = stmt.Exec(hashedPassword, now, u.ID)
+	if err != nil {
+		log.Errorf("Error executing statement for updating user password: %v", err)
+		return err
+	}
+
+	u.UpdateDate = &now
+
+	auditEvent := NewAuditEvent(u.ID, AuditEventPasswordChanged, "User password changed.", nil)
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for user password update: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// defaultDelete removes the user together with their itineraries (including their shares and share links), destinations, API keys, traveller
+// preferences, prompt templates, itinerary templates and the itinerary shares granted to them. The file and data export jobs are
+// only marked as deleted, so the dead jobs cleanup removes their files later on. The audit events are kept, including a final one for the deletion
+func (u *User) defaultDelete() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for user deletion: %v", err)
+		return err
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	itinerary := InitItinerary()
+	err = itinerary.DeleteByOwnerIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting itineraries of user %d: %v", u.ID, err)
+		return err
+	}
+
+	apiKey := InitApiKey()
+	err = apiKey.DeleteByUserIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting API keys of user %d: %v", u.ID, err)
+		return err
+	}
+
+	share := InitItineraryShare()
+	err = share.DeleteByUserIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary shares granted to user %d: %v", u.ID, err)
+		return err
+	}
+
+	preferences := InitTravellerPreferences()
+	err = preferences.DeleteByUserIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting traveller preferences of user %d: %v", u.ID, err)
+		return err
+	}
+
+	promptTemplate := InitPromptTemplate()
+	err = promptTemplate.DeleteByOwnerIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting prompt templates of user %d: %v", u.ID, err)
+		return err
+	}
+
+	itineraryTemplate := InitItineraryTemplate()
+	err = itineraryTemplate.DeleteByOwnerIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error deleting itinerary templates of user %d: %v", u.ID, err)
+		return err
+	}
+
+	dataExportJob := InitDataExportJob()
+	err = dataExportJob.SoftDeleteJobsByUserIdTx(u.ID, tx)
+	if err != nil {
+		log.Errorf("Error marking data export jobs for deletion for user %d: %v", u.ID, err)
+		return err
+	}
+
+	query := "DELETE FROM users WHERE id = ?"
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		log.Errorf("Error preparing statement for user deletion: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(u.ID)
+	if err != nil {
+		log.Errorf("Error executing statement for user deletion: %v", err)
+		return err
+	}
+
+	auditEvent := NewAuditEvent(u.ID, AuditEventUserDeleted, fmt.Sprintf("User %d deleted their account.", u.ID), nil)
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error creating audit event for user deletion: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"example.com/travel-advisor/db"
 	"example.com/travel-advisor/utils"
@@ -19,7 +21,7 @@ func TestUser_Create(t *testing.T) {
 
 	mock.ExpectPrepare("INSERT INTO users").
 		ExpectExec().
-		WithArgs("test@example.com", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs("test@example.com", sqlmock.AnyArg(), RoleUser, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	user := NewUser("test@example.com", "password123")
@@ -39,7 +41,7 @@ func TestUser_Create_Error(t *testing.T) {
 
 	mock.ExpectPrepare("INSERT INTO users").
 		ExpectExec().
-		WithArgs("test@example.com", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs("test@example.com", sqlmock.AnyArg(), RoleUser, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(errors.New("insert error"))
 
 	user := NewUser("test@example.com", "password123")
@@ -120,9 +122,9 @@ func TestUser_ValidateCredentials(t *testing.T) {
 		t.Fatalf("Failed to hash password: %v", err)
 	}
 
-	mock.ExpectQuery("SELECT id,password FROM users WHERE email=?").
+	mock.ExpectQuery("SELECT id,password,role,disabled_date FROM users WHERE email=?").
 		WithArgs("test@example.com").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "password"}).AddRow(1, hashedPassword))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "role", "disabled_date"}).AddRow(1, hashedPassword, RoleUser, nil))
 
 	user := InitUser()
 	user.Email = "test@example.com"
@@ -140,9 +142,9 @@ func TestUser_ValidateCredentials_Invalid(t *testing.T) {
 
 	db.DB = dbMock
 
-	mock.ExpectQuery("SELECT id,password FROM users WHERE email=?").
+	mock.ExpectQuery("SELECT id,password,role,disabled_date FROM users WHERE email=?").
 		WithArgs("test@example.com").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "password"}).AddRow(1, "hashedPassword"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "role", "disabled_date"}).AddRow(1, "hashedPassword", RoleUser, nil))
 
 	user := InitUser()
 	user.Email = "test@example.com"
@@ -152,3 +154,326 @@ func TestUser_ValidateCredentials_Invalid(t *testing.T) {
 	assert.Equal(t, "credentials invalid", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUser_ValidateCredentials_UnknownEmail(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT id,password,role,disabled_date FROM users WHERE email=?").
+		WithArgs("unknown@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "role", "disabled_date"}))
+
+	user := InitUser()
+	user.Email = "unknown@example.com"
+
+	err = user.ValidateCredentials("password123")
+	assert.Error(t, err)
+	assert.Equal(t, "sql: no rows in result set", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var userTestColumns = []string{"id", "email", "role", "creation_date", "update_date", "last_login_date", "disabled_date"}
+
+func TestUser_FindById_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = \\?").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(userTestColumns).AddRow(1, "test@example.com", RoleSupport, now, now, nil, now))
+
+	user, err := InitUser().FindById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", user.Email)
+	assert.Equal(t, RoleSupport, user.Role)
+	assert.Nil(t, user.LastLoginDate)
+	assert.NotNil(t, user.DisabledDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_FindById_NotFound(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = \\?").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(userTestColumns))
+
+	user, err := InitUser().FindById(1)
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_FindAll_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM users ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(userTestColumns).
+			AddRow(1, "admin@example.com", RoleAdmin, now, now, now, nil).
+			AddRow(2, "test@example.com", RoleUser, now, now, nil, nil))
+
+	users, err := InitUser().FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, RoleAdmin, users[0].Role)
+	assert.Empty(t, users[1].Password)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_UpdateRole_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE users SET role = \\?, update_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WithArgs(RoleSupport, sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(1), AuditEventRoleChanged, "Role of user 2 changed to support.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := InitUserFunctions(&User{ID: 2, Role: RoleUser})
+	err = user.UpdateRole(RoleSupport, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleSupport, user.Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_UpdateDisabled_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE users SET disabled_date = \\?, update_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(1), AuditEventUserDisabled, "User 2 disabled.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := InitUserFunctions(&User{ID: 2})
+	err = user.UpdateDisabled(true, 1)
+	assert.NoError(t, err)
+	assert.NotNil(t, user.DisabledDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_UpdateDisabled_ExecError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE users SET disabled_date = \\?, update_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WillReturnError(errors.New("update error"))
+	mock.ExpectRollback()
+
+	disabledDate := time.Now()
+	user := InitUserFunctions(&User{ID: 2, DisabledDate: &disabledDate})
+	err = user.UpdateDisabled(false, 1)
+	assert.Error(t, err)
+	assert.NotNil(t, user.DisabledDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_UpdateEmail_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE users SET email = \\?, update_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WithArgs("new@example.com", sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(2), AuditEventEmailChanged, "User email changed.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := InitUserFunctions(&User{ID: 2, Email: "old@example.com"})
+	err = user.UpdateEmail("new@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_UpdatePassword_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("UPDATE users SET password = \\?, update_date = \\? WHERE id = \\?").
+		ExpectExec().
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(2), AuditEventPasswordChanged, "User password changed.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := InitUserFunctions(&User{ID: 2})
+	err = user.UpdatePassword("NewPassword123-")
+	assert.NoError(t, err)
+	assert.NotNil(t, user.UpdateDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_Delete_Success(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE itinerary_file_jobs SET status = 'deleted' WHERE itinerary_id IN").
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare("DELETE FROM itinerary_travel_destinations WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM share_links WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM itinerary_search WHERE owner_id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM itinerary_revision_destinations WHERE revision_id IN").
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM itinerary_revisions WHERE itinerary_id IN").
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_budgets WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_cost_estimates WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_transport_legs WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM destination_accommodations WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itinerary_activities WHERE itinerary_id IN").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("DELETE FROM itineraries WHERE owner_id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM api_keys WHERE user_id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM itinerary_shares WHERE user_id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare("DELETE FROM traveller_preferences WHERE user_id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM prompt_templates WHERE owner_id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM itinerary_template_destinations").
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM itinerary_templates WHERE owner_id = \\?").
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE data_export_jobs SET status = 'deleted' WHERE user_id = \\?").
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("DELETE FROM users WHERE id = \\?").
+		ExpectExec().
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO audit_events").
+		ExpectExec().
+		WithArgs(int64(2), AuditEventUserDeleted, "User 2 deleted their account.", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := InitUserFunctions(&User{ID: 2})
+	err = user.Delete()
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_Delete_RollbackOnError(t *testing.T) {
+	dbMock, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer dbMock.Close()
+
+	db.DB = dbMock
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE itinerary_file_jobs SET status = 'deleted' WHERE itinerary_id IN").
+		WithArgs(int64(2)).
+		WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
+
+	user := InitUserFunctions(&User{ID: 2})
+	err = user.Delete()
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,9 @@
+package requests
+
+import "time"
+
+type CreateApiKeyRequest struct {
+	Name           string     `json:"name" binding:"required,max=128" example:"CI pipeline"`
+	Scopes         []string   `json:"scopes" binding:"required,min=1,max=10,dive,required,max=64" example:"itineraries:read,jobs:write"`
+	ExpirationDate *time.Time `json:"expirationDate" binding:"omitnil" example:"2025-06-01T00:00:00Z"`
+}
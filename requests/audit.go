@@ -0,0 +1,16 @@
+package requests
+
+import "time"
+
+type GetAuditEventsRequest struct {
+	Type   string     `form:"type" binding:"omitempty,max=64" example:"itinerary.created"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-06-01T00:00:00Z"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-06-30T23:59:59Z"`
+	Cursor string     `form:"cursor" binding:"omitempty,max=256" example:"eyJpZCI6NDJ9"`
+	Limit  int        `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+}
+
+type GetAllAuditEventsRequest struct {
+	GetAuditEventsRequest
+	UserID int64 `form:"userId" binding:"omitempty,min=1" example:"1"`
+}
@@ -0,0 +1,16 @@
+package requests
+
+// BudgetRequest replaces the budget of an itinerary
+type BudgetRequest struct {
+	Amount   float64 `json:"amount" binding:"required,gt=0" example:"3000"`
+	Currency string  `json:"currency" binding:"required,iso4217" example:"EUR"`
+}
+
+// CostEstimateRequest replaces the estimate of what the travellers spend a day in the city of a destination of an itinerary
+type CostEstimateRequest struct {
+	Currency   string  `json:"currency" binding:"required,iso4217" example:"EUR"`
+	Lodging    float64 `json:"lodging" binding:"min=0" example:"120"`
+	Food       float64 `json:"food" binding:"min=0" example:"60"`
+	Transport  float64 `json:"transport" binding:"min=0" example:"15"`
+	Activities float64 `json:"activities" binding:"min=0" example:"40"`
+}
@@ -0,0 +1,14 @@
+package requests
+
+import "time"
+
+// AccommodationRequest adds or replaces where the travellers sleep during the stay in a destination of an itinerary
+type AccommodationRequest struct {
+	Name               string    `json:"name" binding:"required,max=255" example:"Hotel Gran Vía"`
+	Address            string    `json:"address" binding:"max=512" example:"Gran Vía 25, 28013 Madrid"`
+	CheckIn            time.Time `json:"checkIn" binding:"required" example:"2024-07-01T15:00:00+02:00"`
+	CheckOut           time.Time `json:"checkOut" binding:"required" example:"2024-07-05T11:00:00+02:00"`
+	ConfirmationNumber string    `json:"confirmationNumber" binding:"max=64" example:"HX-48213"`
+	Cost               *float64  `json:"cost" binding:"omitempty,min=0" example:"480"`
+	Currency           string    `json:"currency" binding:"required_with=Cost,omitempty,iso4217" example:"EUR"`
+}
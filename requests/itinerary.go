@@ -23,3 +23,68 @@ type DestinationItem struct {
 	ArrivalDate   time.Time `json:"arrivalDate" binding:"required" example:"2024-07-01T00:00:00Z"`
 	DepartureDate time.Time `json:"departureDate" binding:"required" example:"2024-07-05T00:00:00Z"`
 }
+
+type GetItinerariesRequest struct {
+	Country    string     `form:"country" binding:"omitempty,max=128" example:"Spain"`
+	City       string     `form:"city" binding:"omitempty,max=128" example:"Madrid"`
+	TravelFrom *time.Time `form:"travelFrom" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-07-01T00:00:00Z"`
+	TravelTo   *time.Time `form:"travelTo" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-07-31T23:59:59Z"`
+	Title      string     `form:"title" binding:"omitempty,max=128" example:"Spain"`
+	Sort       string     `form:"sort" binding:"omitempty,max=32" example:"travelDate"`
+	Order      string     `form:"order" binding:"omitempty,max=4" example:"asc"`
+	Cursor     string     `form:"cursor" binding:"omitempty,max=256" example:"eyJpZCI6NDJ9"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+}
+
+type SearchItinerariesRequest struct {
+	Q      string `form:"q" binding:"required,max=256" example:"kyoto food"`
+	Cursor string `form:"cursor" binding:"omitempty,max=256" example:"eyJvZmZzZXQiOjIwfQ"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50" example:"20"`
+}
+
+type GetItineraryRevisionsDiffRequest struct {
+	From int64 `form:"from" binding:"required,min=1" example:"1"`
+	To   int64 `form:"to" binding:"required,min=1" example:"3"`
+}
+
+type ShareItineraryRequest struct {
+	Email      string `json:"email" binding:"required,max=128" example:"friend@example.com"`
+	Permission string `json:"permission" binding:"required,oneof=viewer editor" example:"editor"`
+}
+
+type CreateShareLinkRequest struct {
+	ItineraryJobID *int64     `json:"itineraryJobId" binding:"omitnil,min=1" example:"3"`
+	ExpirationDate *time.Time `json:"expirationDate" binding:"omitnil" example:"2025-06-01T00:00:00Z"`
+	Password       string     `json:"password" binding:"omitempty,max=72" example:"s3cr3t"`
+}
+
+// PatchItineraryRequest documents the JSON merge patch of an itinerary. Omitted members are kept, null members are removed and
+// destinations are replaced as a whole
+type PatchItineraryRequest struct {
+	Title        *string            `json:"title" example:"Trip to Spain and Portugal"`
+	Description  *string            `json:"description" example:"Summer vacation in Spain"`
+	Notes        *string            `json:"notes" example:"I want to enjoy the nightlife"`
+	Destinations []*DestinationItem `json:"destinations"`
+}
+
+// PatchDestinationRequest documents the JSON merge patch of a destination of an itinerary. Omitted members are kept
+type PatchDestinationRequest struct {
+	Country       *string    `json:"country" example:"Spain"`
+	City          *string    `json:"city" example:"Seville"`
+	ArrivalDate   *time.Time `json:"arrivalDate" example:"2024-07-05T00:00:00Z"`
+	DepartureDate *time.Time `json:"departureDate" example:"2024-07-08T00:00:00Z"`
+}
+
+// RegenerateJobRequest rewrites the part of the file of a job about the days from StartDate to EndDate, or about the stay in the
+// destination with DestinationID
+type RegenerateJobRequest struct {
+	StartDate     string `json:"startDate" binding:"omitempty,datetime=2006-01-02" example:"2024-07-02"`
+	EndDate       string `json:"endDate" binding:"omitempty,datetime=2006-01-02" example:"2024-07-03"`
+	DestinationID *int64 `json:"destinationId" binding:"omitnil,min=1" example:"11"`
+	Instructions  string `json:"instructions" binding:"max=1024" example:"Less museums and more time outdoors"`
+}
+
+// JobMessageRequest is a message of the conversation that refines the file of a job
+type JobMessageRequest struct {
+	Content string `json:"content" binding:"required,max=2048" example:"Make the third day more relaxed"`
+}
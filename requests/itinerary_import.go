@@ -0,0 +1,7 @@
+package requests
+
+type ImportItineraryRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=ics csv json" example:"csv"`
+	Title  string `form:"title" binding:"omitempty,max=128" example:"Trip to Spain"`
+	Commit bool   `form:"commit" example:"false"`
+}
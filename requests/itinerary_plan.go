@@ -0,0 +1,15 @@
+package requests
+
+// ActivityRequest adds or replaces an activity of the day-by-day plan of an itinerary
+type ActivityRequest struct {
+	Date     string `json:"date" binding:"required,datetime=2006-01-02" example:"2024-07-01"`
+	TimeSlot string `json:"timeSlot" binding:"required,oneof=morning afternoon evening" example:"morning"`
+	Title    string `json:"title" binding:"required,max=255" example:"Visit the Prado Museum"`
+	Location string `json:"location" binding:"max=255" example:"Calle de Ruiz de Alarcón 23, Madrid"`
+	Notes    string `json:"notes" binding:"max=1024" example:"Free entry from 6 pm"`
+}
+
+// ReorderActivitiesRequest sets the order of all the activities of a day of the plan of an itinerary
+type ReorderActivitiesRequest struct {
+	ActivityIds []int64 `json:"activityIds" binding:"required,min=1" example:"3,1,2"`
+}
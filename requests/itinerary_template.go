@@ -0,0 +1,40 @@
+package requests
+
+import "time"
+
+// CloneItineraryRequest copies an itinerary, optionally moving its dates ShiftDays days (negative to move them earlier) or to start on
+// StartDate. Without a title, the copy keeps the one of the itinerary
+type CloneItineraryRequest struct {
+	Title     string     `json:"title" binding:"omitempty,max=128" example:"Corporate offsite 2025"`
+	ShiftDays int        `json:"shiftDays" binding:"min=-3660,max=3660" example:"364"`
+	StartDate *time.Time `json:"startDate" binding:"omitnil" example:"2025-06-02T00:00:00Z"`
+}
+
+type CreateItineraryTemplateRequest struct {
+	Title        string                     `json:"title" binding:"required,max=128" example:"Corporate offsite"`
+	Description  string                     `json:"description" binding:"omitempty,max=512" example:"Yearly team offsite in Portugal"`
+	Notes        *string                    `json:"notes" binding:"omitnil,omitempty,max=512" example:"Book the team dinner in advance"`
+	Shared       bool                       `json:"shared" example:"true"`
+	Destinations []*TemplateDestinationItem `json:"destinations" binding:"required,min=1,max=20,dive"`
+}
+
+// TemplateDestinationItem is a destination of a template, arrived at on the StartDay day of the trip (0 being the start date) and
+// departed from Days days later
+type TemplateDestinationItem struct {
+	Country  string `json:"country" binding:"required,max=128" example:"Portugal"`
+	City     string `json:"city" binding:"required,max=128" example:"Lisbon"`
+	StartDay int    `json:"startDay" binding:"min=0,max=30" example:"0"`
+	Days     int    `json:"days" binding:"min=0,max=30" example:"3"`
+}
+
+// CreateTemplateFromItineraryRequest saves the route of an itinerary as a template. Without a title, the template takes the one of the
+// itinerary
+type CreateTemplateFromItineraryRequest struct {
+	Title  string `json:"title" binding:"omitempty,max=128" example:"Corporate offsite"`
+	Shared bool   `json:"shared" example:"true"`
+}
+
+type InstantiateItineraryTemplateRequest struct {
+	StartDate time.Time `json:"startDate" binding:"required" example:"2025-06-02T00:00:00Z"`
+	Title     string    `json:"title" binding:"omitempty,max=128" example:"Corporate offsite 2025"`
+}
@@ -0,0 +1,14 @@
+package requests
+
+import "time"
+
+// TransportLegRequest adds or replaces the transport leg from a destination of an itinerary to the next one
+type TransportLegRequest struct {
+	FromDestinationID int64      `json:"fromDestinationId" binding:"required,min=1" example:"1"`
+	Mode              string     `json:"mode" binding:"required,oneof=flight train bus car ferry other" example:"train"`
+	Carrier           string     `json:"carrier" binding:"max=128" example:"Renfe"`
+	BookingReference  string     `json:"bookingReference" binding:"max=64" example:"X7K2P9"`
+	DepartureTime     *time.Time `json:"departureTime" example:"2024-07-05T09:30:00+02:00"`
+	ArrivalTime       *time.Time `json:"arrivalTime" example:"2024-07-05T12:15:00+02:00"`
+	Notes             string     `json:"notes" binding:"max=512" example:"Seats 4A and 4B"`
+}
@@ -0,0 +1,7 @@
+package requests
+
+type SuggestPlacesRequest struct {
+	Q       string `form:"q" binding:"required,max=128" example:"mad"`
+	Country string `form:"country" binding:"omitempty,max=128" example:"Spain"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=50" example:"10"`
+}
@@ -0,0 +1,10 @@
+package requests
+
+// PromptTemplateRequest saves a new version of a prompt template. The template is a Go template which can use the variables title,
+// description, notes, ownerId, travelDestinations (with country, city, arrivalDate and departureDate), interests, travellerProfile and
+// language
+type PromptTemplateRequest struct {
+	Name          string `json:"name" binding:"required,max=64,excludesall=/" example:"kids"`
+	SystemMessage string `json:"systemMessage" binding:"required,max=1024" example:"You are a helpful expert in family travel."`
+	Template      string `json:"template" binding:"required,max=8192" example:"Create a travel itinerary for a family with kids titled {{.title}}."`
+}
@@ -0,0 +1,15 @@
+package requests
+
+// TravellerPreferencesRequest replaces the traveller preferences of a user, or their overrides for an itinerary. Omitted or null
+// attributes are unset, so the ones of the profile of the owner apply to the itineraries
+type TravellerPreferencesRequest struct {
+	Interests           []string `json:"interests" binding:"omitempty,max=20,dive,required,max=64" example:"museums,street food,hiking"`
+	Pace                string   `json:"pace" binding:"omitempty,oneof=relaxed moderate intense" example:"relaxed"`
+	BudgetLevel         string   `json:"budgetLevel" binding:"omitempty,oneof=budget moderate luxury" example:"moderate"`
+	DietaryRestrictions []string `json:"dietaryRestrictions" binding:"omitempty,max=10,dive,required,max=64" example:"vegetarian"`
+	MobilityNeeds       string   `json:"mobilityNeeds" binding:"omitempty,max=256" example:"Wheelchair user, no long walks"`
+	Adults              *int     `json:"adults" binding:"omitnil,min=0,max=50" example:"2"`
+	Children            *int     `json:"children" binding:"omitnil,min=0,max=50" example:"1"`
+	Seniors             *int     `json:"seniors" binding:"omitnil,min=0,max=50" example:"0"`
+	Language            string   `json:"language" binding:"omitempty,max=35,bcp47_language_tag" example:"es"`
+}
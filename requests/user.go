@@ -9,3 +9,24 @@ type LoginRequest struct {
 	Email    string `json:"email" binding:"required,max=100" example:"test@example.com"`
 	Password string `json:"password" binding:"required,max=256" example:"Password123-"`
 }
+
+type UnlockUserRequest struct {
+	Email string `json:"email" binding:"required,max=128" example:"test@example.com"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user support admin" example:"support"`
+}
+
+type UpdateMeRequest struct {
+	Email *string `json:"email" binding:"omitempty,max=128" example:"new@example.com"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required,max=256" example:"Password123-"`
+	NewPassword     string `json:"newPassword" binding:"required,max=256" example:"NewPassword123-"`
+}
+
+type DeleteMeRequest struct {
+	Password string `json:"password" binding:"required,max=256" example:"Password123-"`
+}
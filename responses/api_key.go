@@ -0,0 +1,17 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type CreateApiKeyResponse struct {
+	Message string         `json:"message" example:"API key created. Store it safely, it will not be shown again."`
+	Key     string         `json:"key" example:"tak_1a2b3c4d5e6f..."`
+	ApiKey  *models.ApiKey `json:"apiKey"`
+}
+
+type GetApiKeysResponse struct {
+	ApiKeys []*models.ApiKey `json:"apiKeys"`
+}
+
+type RevokeApiKeyResponse struct {
+	Message string `json:"message" example:"API key revoked."`
+}
@@ -0,0 +1,8 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type GetAuditEventsResponse struct {
+	Events     []*models.AuditEvent `json:"events"`
+	NextCursor string               `json:"nextCursor,omitempty" example:"eyJpZCI6NDJ9"`
+}
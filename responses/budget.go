@@ -0,0 +1,32 @@
+package responses
+
+import (
+	"example.com/travel-advisor/models"
+)
+
+type GetBudgetSummaryResponse struct {
+	Summary *models.BudgetSummary `json:"summary"`
+}
+
+type UpdateBudgetResponse struct {
+	Message string                  `json:"message" example:"Budget updated."`
+	Budget  *models.ItineraryBudget `json:"budget"`
+}
+
+type DeleteBudgetResponse struct {
+	Message string `json:"message" example:"Budget removed."`
+}
+
+type UpdateCostEstimateResponse struct {
+	Message  string                          `json:"message" example:"Cost estimate updated."`
+	Estimate *models.DestinationCostEstimate `json:"estimate"`
+}
+
+type DeleteCostEstimateResponse struct {
+	Message string `json:"message" example:"Cost estimate removed."`
+}
+
+type GenerateCostEstimatesResponse struct {
+	Message   string                            `json:"message" example:"Cost estimates generated."`
+	Estimates []*models.DestinationCostEstimate `json:"estimates"`
+}
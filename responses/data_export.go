@@ -0,0 +1,20 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type StartDataExportResponse struct {
+	Message string `json:"message" example:"Data export started successfully."`
+	JobId   int64  `json:"jobId" example:"123"`
+}
+
+type GetDataExportResponse struct {
+	Job *models.DataExportJob `json:"job"`
+}
+
+type GetDataExportsResponse struct {
+	Jobs []*models.DataExportJob `json:"jobs"`
+}
+
+type DeleteDataExportResponse struct {
+	Message string `json:"message" example:"Data export deleted."`
+}
@@ -0,0 +1,18 @@
+package responses
+
+import (
+	"example.com/travel-advisor/models"
+)
+
+type GetAccommodationResponse struct {
+	Accommodation *models.DestinationAccommodation `json:"accommodation"`
+}
+
+type UpdateAccommodationResponse struct {
+	Message       string                           `json:"message" example:"Accommodation updated."`
+	Accommodation *models.DestinationAccommodation `json:"accommodation"`
+}
+
+type DeleteAccommodationResponse struct {
+	Message string `json:"message" example:"Accommodation removed."`
+}
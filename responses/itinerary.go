@@ -13,6 +13,13 @@ type GetItineraryResponse struct {
 
 type GetItinerariesResponse struct {
 	Itineraries []*models.Itinerary `json:"itineraries"` // Example JSON representation
+	TotalCount  int64               `json:"totalCount" example:"42"`
+	NextCursor  string              `json:"nextCursor,omitempty" example:"eyJpZCI6NDJ9"`
+}
+
+type SearchItinerariesResponse struct {
+	Results    []*models.ItinerarySearchResult `json:"results"`
+	NextCursor string                          `json:"nextCursor,omitempty" example:"eyJvZmZzZXQiOjIwfQ"`
 }
 
 type StartItineraryJobResponse struct {
@@ -28,6 +35,11 @@ type GetItineraryJobResponse struct {
 	Job *models.ItineraryFileJob `json:"job"` // Example JSON representation
 }
 
+// GetItineraryJobMessagesResponse has the conversation about the file of a job, oldest message first
+type GetItineraryJobMessagesResponse struct {
+	Messages []*models.ItineraryJobMessage `json:"messages"`
+}
+
 type GetItineraryJobsResponse struct {
 	Jobs []*models.ItineraryFileJob `json:"job"`
 }
@@ -43,3 +55,76 @@ type DeleteItineraryResponse struct {
 type DeleteItineraryJobResponse struct {
 	Message string `json:"message" example:"Itinerary job deleted."`
 }
+
+type PurgeItineraryJobResponse struct {
+	Message string `json:"message" example:"Itinerary job purged."`
+}
+
+type ShareItineraryResponse struct {
+	Message string                 `json:"message" example:"Itinerary shared."`
+	Share   *models.ItineraryShare `json:"share"`
+}
+
+type GetItinerarySharesResponse struct {
+	Shares []*models.ItineraryShare `json:"shares"`
+}
+
+type GetSharedItinerariesResponse struct {
+	Shares []*models.ItineraryShare `json:"shares"`
+}
+
+type UnshareItineraryResponse struct {
+	Message string `json:"message" example:"Itinerary unshared."`
+}
+
+type CreateShareLinkResponse struct {
+	Message   string            `json:"message" example:"Share link created. Store it safely, it will not be shown again."`
+	Token     string            `json:"token" example:"tas_1a2b3c4d5e6f..."`
+	ShareLink *models.ShareLink `json:"shareLink"`
+}
+
+type GetShareLinksResponse struct {
+	ShareLinks []*models.ShareLink `json:"shareLinks"`
+}
+
+type RevokeShareLinkResponse struct {
+	Message string `json:"message" example:"Share link revoked."`
+}
+
+type GetPublicItineraryResponse struct {
+	Itinerary *models.Itinerary        `json:"itinerary"`
+	Job       *models.ItineraryFileJob `json:"itineraryJob,omitempty"`
+	Document  *string                  `json:"document,omitempty" example:"Day 1: Arrival in Madrid..."`
+}
+
+type GetItineraryRevisionsResponse struct {
+	Revisions []*models.ItineraryRevision `json:"revisions"`
+}
+
+type GetItineraryRevisionResponse struct {
+	Revision *models.ItineraryRevision `json:"revision"`
+}
+
+type GetItineraryRevisionsDiffResponse struct {
+	From    int64                             `json:"from" example:"1"`
+	To      int64                             `json:"to" example:"3"`
+	Changes []*models.ItineraryRevisionChange `json:"changes"`
+}
+
+type RestoreItineraryRevisionResponse struct {
+	Message string `json:"message" example:"Itinerary restored to revision 1."`
+}
+
+type AddItineraryDestinationResponse struct {
+	Message     string                             `json:"message" example:"Destination added."`
+	Destination *models.ItineraryTravelDestination `json:"destination"`
+}
+
+type UpdateItineraryDestinationResponse struct {
+	Message     string                             `json:"message" example:"Destination updated."`
+	Destination *models.ItineraryTravelDestination `json:"destination"`
+}
+
+type DeleteItineraryDestinationResponse struct {
+	Message string `json:"message" example:"Destination deleted."`
+}
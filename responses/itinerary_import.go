@@ -0,0 +1,9 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type ImportItineraryResponse struct {
+	Message     string                  `json:"message" example:"Itinerary imported."`
+	ItineraryID int64                   `json:"itineraryId,omitempty" example:"1"`
+	Import      *models.ItineraryImport `json:"import"`
+}
@@ -0,0 +1,27 @@
+package responses
+
+import (
+	"example.com/travel-advisor/models"
+)
+
+type GetPlanResponse struct {
+	Days []*models.ItineraryDay `json:"days"`
+}
+
+type CreateActivityResponse struct {
+	Message  string                    `json:"message" example:"Activity added."`
+	Activity *models.ItineraryActivity `json:"activity"`
+}
+
+type UpdateActivityResponse struct {
+	Message  string                    `json:"message" example:"Activity updated."`
+	Activity *models.ItineraryActivity `json:"activity"`
+}
+
+type DeleteActivityResponse struct {
+	Message string `json:"message" example:"Activity removed."`
+}
+
+type ReorderActivitiesResponse struct {
+	Message string `json:"message" example:"Activities reordered."`
+}
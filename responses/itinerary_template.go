@@ -0,0 +1,20 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type GetItineraryTemplatesResponse struct {
+	Templates []*models.ItineraryTemplate `json:"templates"`
+}
+
+type GetItineraryTemplateResponse struct {
+	Template *models.ItineraryTemplate `json:"template"`
+}
+
+type CreateItineraryTemplateResponse struct {
+	Message  string                    `json:"message" example:"Itinerary template created."`
+	Template *models.ItineraryTemplate `json:"template"`
+}
+
+type DeleteItineraryTemplateResponse struct {
+	Message string `json:"message" example:"Itinerary template deleted."`
+}
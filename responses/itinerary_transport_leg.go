@@ -0,0 +1,23 @@
+package responses
+
+import (
+	"example.com/travel-advisor/models"
+)
+
+type GetTransportLegsResponse struct {
+	TransportLegs []*models.ItineraryTransportLeg `json:"transportLegs"`
+}
+
+type CreateTransportLegResponse struct {
+	Message      string                        `json:"message" example:"Transport leg added."`
+	TransportLeg *models.ItineraryTransportLeg `json:"transportLeg"`
+}
+
+type UpdateTransportLegResponse struct {
+	Message      string                        `json:"message" example:"Transport leg updated."`
+	TransportLeg *models.ItineraryTransportLeg `json:"transportLeg"`
+}
+
+type DeleteTransportLegResponse struct {
+	Message string `json:"message" example:"Transport leg removed."`
+}
@@ -0,0 +1,7 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type SuggestPlacesResponse struct {
+	Places []*models.Place `json:"places"`
+}
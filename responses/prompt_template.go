@@ -0,0 +1,20 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type GetPromptTemplatesResponse struct {
+	PromptTemplates []*models.PromptTemplate `json:"promptTemplates"`
+}
+
+type GetPromptTemplateResponse struct {
+	PromptTemplate *models.PromptTemplate `json:"promptTemplate"`
+}
+
+type CreatePromptTemplateResponse struct {
+	Message        string                 `json:"message" example:"Prompt template saved."`
+	PromptTemplate *models.PromptTemplate `json:"promptTemplate"`
+}
+
+type DeletePromptTemplateResponse struct {
+	Message string `json:"message" example:"Prompt template deleted."`
+}
@@ -0,0 +1,21 @@
+package responses
+
+import "example.com/travel-advisor/models"
+
+type GetTravellerPreferencesResponse struct {
+	Preferences *models.TravellerPreferences `json:"preferences"`
+}
+
+type UpdateTravellerPreferencesResponse struct {
+	Message     string                       `json:"message" example:"Traveller preferences updated."`
+	Preferences *models.TravellerPreferences `json:"preferences"`
+}
+
+type GetItineraryTravellerPreferencesResponse struct {
+	Overrides *models.TravellerPreferences `json:"overrides"`
+	Effective *models.TravellerPreferences `json:"effective"`
+}
+
+type DeleteItineraryTravellerPreferencesResponse struct {
+	Message string `json:"message" example:"Traveller preferences of the itinerary removed."`
+}
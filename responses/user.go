@@ -1,5 +1,7 @@
 package responses
 
+import "example.com/travel-advisor/models"
+
 type SignUpResponse struct {
 	Message string `json:"message" example:"User created."`
 	User    string `json:"user" example:"test@example.com"`
@@ -9,3 +11,32 @@ type LoginResponse struct {
 	Message string `json:"message" example:"Login successful!"`
 	Token   string `json:"token" example:"token123"`
 }
+
+type UnlockUserResponse struct {
+	Message string `json:"message" example:"User account unlocked."`
+}
+
+type GetUsersResponse struct {
+	Users []*models.User `json:"users"`
+}
+
+type UpdateUserResponse struct {
+	Message string `json:"message" example:"User updated."`
+}
+
+type GetMeResponse struct {
+	User *models.User `json:"user"`
+}
+
+type UpdateMeResponse struct {
+	Message string       `json:"message" example:"Profile updated."`
+	User    *models.User `json:"user"`
+}
+
+type ChangePasswordResponse struct {
+	Message string `json:"message" example:"Password changed."`
+}
+
+type DeleteMeResponse struct {
+	Message string `json:"message" example:"Account deleted."`
+}
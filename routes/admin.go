@@ -0,0 +1,322 @@
+package routes
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+)
+
+// getUsers godoc
+// @Summary      List all users
+// @Description  Retrieves all registered users with their roles and account status. Only available for support staff and administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetUsersResponse  "List of users"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve users. Try again later."
+// @Router       /admin/users [get]
+func getUsers(context *gin.Context) {
+	log.Debug("Retrieving all users")
+
+	users, err := services.GetUserService().FindAll()
+	if err != nil {
+		log.Errorf("Error retrieving users: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve users. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetUsersResponse{Users: users})
+}
+
+// updateUserRole godoc
+// @Summary      Change the role of a user
+// @Description  Assigns the user, support or admin role to a user. Administrators cannot change their own role. The new role is applied from the next request of the user, also with the tokens issued before the change. Only available for administrators.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        userId  path  int  true  "User ID"
+// @Param        role  body  requests.UpdateUserRoleRequest  true  "New role"
+// @Success      200  {object}  responses.UpdateUserResponse  "User role updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid user ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "User not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update user. Try again later."
+// @Router       /admin/users/{userId}/role [put]
+func updateUserRole(context *gin.Context) {
+	log.Debug("Updating user role")
+
+	actorId := validateAuthenticatedUser(context)
+	if actorId == nil {
+		return
+	}
+
+	userId := getPathId(context, "userId", "user")
+	if userId == nil {
+		return
+	}
+
+	var input requests.UpdateUserRoleRequest
+
+	// Bind JSON input to the input struct
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The role must be one of: user, support, admin."})
+		return
+	}
+
+	if *userId == *actorId {
+		log.Errorf("User %d tried to change their own role", *actorId)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "You cannot change your own role."})
+		return
+	}
+
+	err := services.GetUserService().UpdateRole(*userId, input.Role, *actorId)
+	if err != nil {
+		handleAdminUserUpdateError(context, *userId, err)
+		return
+	}
+
+	log.Infof("Role of user %d changed to %s by user %d", *userId, input.Role, *actorId)
+	context.JSON(http.StatusOK, &responses.UpdateUserResponse{Message: "User role updated."})
+}
+
+// disableUser godoc
+// @Summary      Disable a user account
+// @Description  Disables a user account. Disabled users cannot log in, and their tokens and API keys stop working right away. Administrators cannot disable their own account. Only available for administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        userId  path  int  true  "User ID"
+// @Success      200  {object}  responses.UpdateUserResponse  "User disabled."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid user ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "User not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update user. Try again later."
+// @Router       /admin/users/{userId}/disable [put]
+func disableUser(context *gin.Context) {
+	log.Debug("Disabling user")
+	updateUserDisabled(context, true)
+}
+
+// enableUser godoc
+// @Summary      Enable a user account
+// @Description  Re-enables a previously disabled user account. Only available for administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        userId  path  int  true  "User ID"
+// @Success      200  {object}  responses.UpdateUserResponse  "User enabled."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid user ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "User not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update user. Try again later."
+// @Router       /admin/users/{userId}/enable [put]
+func enableUser(context *gin.Context) {
+	log.Debug("Enabling user")
+	updateUserDisabled(context, false)
+}
+
+func updateUserDisabled(context *gin.Context, disabled bool) {
+	actorId := validateAuthenticatedUser(context)
+	if actorId == nil {
+		return
+	}
+
+	userId := getPathId(context, "userId", "user")
+	if userId == nil {
+		return
+	}
+
+	if disabled && *userId == *actorId {
+		log.Errorf("User %d tried to disable their own account", *actorId)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "You cannot disable your own account."})
+		return
+	}
+
+	err := services.GetUserService().UpdateDisabled(*userId, disabled, *actorId)
+	if err != nil {
+		handleAdminUserUpdateError(context, *userId, err)
+		return
+	}
+
+	message := "User enabled."
+	if disabled {
+		message = "User disabled."
+	}
+
+	log.Infof("User %d disabled status set to %t by user %d", *userId, disabled, *actorId)
+	context.JSON(http.StatusOK, &responses.UpdateUserResponse{Message: message})
+}
+
+func handleAdminUserUpdateError(context *gin.Context, userId int64, err error) {
+	if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+		log.Warnf("User %d not found", userId)
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "User not found."})
+		return
+	}
+
+	log.Errorf("Error updating user %d: %v", userId, err)
+	context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not update user. Try again later."})
+}
+
+// getAnyItineraryJob godoc
+// @Summary      Inspect any itinerary file job
+// @Description  Retrieves the status and details of any itinerary file job, regardless of its owner. Only available for support staff and administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryJobId  path  int  true  "Itinerary Job ID"
+// @Success      200  {object}  responses.GetItineraryJobResponse  "Itinerary job details"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid itinerary job ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary job not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get itinerary job. Try again later."
+// @Router       /admin/jobs/{itineraryJobId} [get]
+func getAnyItineraryJob(context *gin.Context) {
+	log.Debug("Inspecting itinerary job")
+
+	itineraryJob := getAdminItineraryJob(context)
+	if itineraryJob == nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryJobResponse{Job: itineraryJob})
+}
+
+// forceStopItineraryJob godoc
+// @Summary      Force-stop any itinerary file job
+// @Description  Stops a pending or running itinerary file job of any user right away, without waiting for the async task timeout. Only available for administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryJobId  path  int  true  "Itinerary Job ID"
+// @Success      200  {object}  responses.StopItineraryJobResponse  "Itinerary job stopped."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid itinerary job ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary job not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Only pending or running itinerary jobs can be stopped."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not stop itinerary job. Try again later."
+// @Router       /admin/jobs/{itineraryJobId}/stop [put]
+func forceStopItineraryJob(context *gin.Context) {
+	log.Debug("Force-stopping itinerary job")
+
+	actorId := validateAuthenticatedUser(context)
+	if actorId == nil {
+		return
+	}
+
+	itineraryJob := getAdminItineraryJob(context)
+	if itineraryJob == nil {
+		return
+	}
+
+	if itineraryJob.Status != "pending" && itineraryJob.Status != "running" {
+		log.Errorf("Itinerary job %d is %s and cannot be stopped", itineraryJob.ID, itineraryJob.Status)
+		context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: "Only pending or running itinerary jobs can be stopped."})
+		return
+	}
+
+	err := services.GetItineraryFileJobService().ForceStopJob(itineraryJob, *actorId)
+	if err != nil {
+		log.Errorf("Error force-stopping itinerary job %d: %v", itineraryJob.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not stop itinerary job. Try again later."})
+		return
+	}
+
+	log.Infof("Itinerary job %d force-stopped by user %d", itineraryJob.ID, *actorId)
+	context.JSON(http.StatusOK, &responses.StopItineraryJobResponse{Message: "Itinerary job stopped."})
+}
+
+// purgeItineraryJob godoc
+// @Summary      Purge any itinerary file job
+// @Description  Fully deletes an itinerary file job of any user and its generated file right away. Pending or running jobs must be stopped first. Only available for administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryJobId  path  int  true  "Itinerary Job ID"
+// @Success      200  {object}  responses.PurgeItineraryJobResponse  "Itinerary job purged."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid itinerary job ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary job not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Cannot purge itinerary job that is still pending or running."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not purge itinerary job. Try again later."
+// @Router       /admin/jobs/{itineraryJobId} [delete]
+func purgeItineraryJob(context *gin.Context) {
+	log.Debug("Purging itinerary job")
+
+	actorId := validateAuthenticatedUser(context)
+	if actorId == nil {
+		return
+	}
+
+	itineraryJob := getAdminItineraryJob(context)
+	if itineraryJob == nil {
+		return
+	}
+
+	if itineraryJob.Status == "pending" || itineraryJob.Status == "running" {
+		log.Errorf("Itinerary job %d is still %s and cannot be purged", itineraryJob.ID, itineraryJob.Status)
+		context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: "Cannot purge itinerary job that is still pending or running. Stop it first."})
+		return
+	}
+
+	err := services.GetItineraryFileJobService().PurgeJob(itineraryJob, *actorId)
+	if err != nil {
+		log.Errorf("Error purging itinerary job %d: %v", itineraryJob.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not purge itinerary job. Try again later."})
+		return
+	}
+
+	log.Infof("Itinerary job %d purged by user %d", itineraryJob.ID, *actorId)
+	context.JSON(http.StatusOK, &responses.PurgeItineraryJobResponse{Message: "Itinerary job purged."})
+}
+
+func getAdminItineraryJob(context *gin.Context) *models.ItineraryFileJob {
+	itineraryJobId := getPathId(context, "itineraryJobId", "itinerary job")
+	if itineraryJobId == nil {
+		return nil
+	}
+
+	itineraryJob, err := services.GetItineraryFileJobService().FindAliveById(*itineraryJobId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			log.Errorf("Itinerary job %d not found", *itineraryJobId)
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Itinerary job not found."})
+		} else {
+			log.Errorf("Error retrieving itinerary job %d: %v", *itineraryJobId, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get itinerary job. Try again later."})
+		}
+		return nil
+	}
+
+	return models.InitItineraryFileJobFunctions(itineraryJob)
+}
+
+func getPathId(context *gin.Context, paramName string, resourceName string) *int64 {
+	var id int64
+	_, err := fmt.Sscan(context.Param(paramName), &id)
+	if err != nil || id <= 0 {
+		log.Errorf("Invalid %s ID format: %v", resourceName, err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: fmt.Sprintf("Invalid %s ID.", resourceName)})
+		return nil
+	}
+	return &id
+}
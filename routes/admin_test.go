@@ -0,0 +1,246 @@
+package routes
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setMockJobsService(mock *mockJobsService) func() {
+	orig := services.GetItineraryFileJobService
+	services.GetItineraryFileJobService = func() services.ItineraryFileJobServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryFileJobService = orig }
+}
+
+func newAuthenticatedContext(method string, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	setUserId(c, 1)
+	return c, w
+}
+
+func TestGetUsers_Success(t *testing.T) {
+	restore := setMockUserService(&mockUserService{
+		findAllFunc: func() ([]*models.User, error) {
+			return []*models.User{{ID: 1, Email: "admin@example.com", Role: models.RoleAdmin}}, nil
+		},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "admin@example.com")
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestGetUsers_Error(t *testing.T) {
+	restore := setMockUserService(&mockUserService{
+		findAllFunc: func() ([]*models.User, error) { return nil, errors.New("db error") },
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getUsers(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestUpdateUserRole_Success(t *testing.T) {
+	var updatedRole string
+	restore := setMockUserService(&mockUserService{
+		updateRoleFunc: func(userId int64, role string, actorId int64) error {
+			updatedRole = role
+			return nil
+		},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"role":"support"}`, gin.Params{{Key: "userId", Value: "2"}})
+	updateUserRole(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.RoleSupport, updatedRole)
+}
+
+func TestUpdateUserRole_InvalidRole(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodPut, `{"role":"superuser"}`, gin.Params{{Key: "userId", Value: "2"}})
+	updateUserRole(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateUserRole_InvalidUserId(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodPut, `{"role":"support"}`, gin.Params{{Key: "userId", Value: "abc"}})
+	updateUserRole(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateUserRole_OwnRole(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodPut, `{"role":"user"}`, gin.Params{{Key: "userId", Value: "1"}})
+	updateUserRole(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateUserRole_NotFound(t *testing.T) {
+	restore := setMockUserService(&mockUserService{
+		updateRoleFunc: func(userId int64, role string, actorId int64) error { return sql.ErrNoRows },
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"role":"admin"}`, gin.Params{{Key: "userId", Value: "2"}})
+	updateUserRole(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDisableUser_Success(t *testing.T) {
+	var disabledValue bool
+	restore := setMockUserService(&mockUserService{
+		updateDisabledFunc: func(userId int64, disabled bool, actorId int64) error {
+			disabledValue = disabled
+			return nil
+		},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "userId", Value: "2"}})
+	disableUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, disabledValue)
+	assert.Contains(t, w.Body.String(), "User disabled.")
+}
+
+func TestDisableUser_OwnAccount(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "userId", Value: "1"}})
+	disableUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestEnableUser_Success(t *testing.T) {
+	disabledValue := true
+	restore := setMockUserService(&mockUserService{
+		updateDisabledFunc: func(userId int64, disabled bool, actorId int64) error {
+			disabledValue = disabled
+			return nil
+		},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "userId", Value: "2"}})
+	enableUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, disabledValue)
+}
+
+func TestEnableUser_Error(t *testing.T) {
+	restore := setMockUserService(&mockUserService{
+		updateDisabledFunc: func(userId int64, disabled bool, actorId int64) error { return errors.New("db error") },
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "userId", Value: "2"}})
+	enableUser(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetAnyItineraryJob_Success(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 5, Status: "running"}})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	getAnyItineraryJob(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetAnyItineraryJob_NotFound(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{FindAliveByIdErr: sql.ErrNoRows})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	getAnyItineraryJob(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestForceStopItineraryJob_Success(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 5, Status: "running"}})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	forceStopItineraryJob(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestForceStopItineraryJob_NotInProgress(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 5, Status: "completed"}})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	forceStopItineraryJob(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestForceStopItineraryJob_Error(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{
+		FindAliveByIdResult: &models.ItineraryFileJob{ID: 5, Status: "pending"},
+		ForceStopJobErr:     errors.New("fail"),
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPut, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	forceStopItineraryJob(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestPurgeItineraryJob_Success(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 5, Status: "failed"}})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	purgeItineraryJob(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPurgeItineraryJob_InProgress(t *testing.T) {
+	restore := setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 5, Status: "running"}})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryJobId", Value: "5"}})
+	purgeItineraryJob(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPurgeItineraryJob_InvalidId(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryJobId", Value: "0"}})
+	purgeItineraryJob(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
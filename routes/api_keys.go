@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+)
+
+// createApiKey godoc
+// @Summary      Create a personal API key
+// @Description  Creates a named API key for machine-to-machine access with the given scopes (itineraries:read, itineraries:write, jobs:read, jobs:write) and an optional expiration date. The key is only returned once. API keys cannot be used to manage API keys.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        apiKey  body  requests.CreateApiKeyRequest  true  "API key data"
+// @Success      201  {object}  responses.CreateApiKeyResponse  "API key created."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid scopes/expiration date."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create API key. Try again later."
+// @Router       /api-keys [post]
+func createApiKey(context *gin.Context) {
+	log.Debug("Creating API key")
+
+	var input requests.CreateApiKeyRequest
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	// Bind JSON input to the input struct
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. One or more mandatory attributes are null/empty or at least one of the expected attributes is too large."})
+		return
+	}
+
+	apiKey := models.NewApiKey(*userId, input.Name, input.Scopes, input.ExpirationDate)
+
+	apiKeyService := services.GetApiKeyService()
+
+	err := apiKeyService.Validate(apiKey)
+	if err != nil {
+		log.Errorf("Error validating API key: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	rawApiKey, err := apiKeyService.Create(apiKey)
+	if err != nil {
+		log.Errorf("Error creating API key: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create API key. Try again later."})
+		return
+	}
+
+	log.Debugf("API key %d created successfully for user %d", apiKey.ID, *userId)
+	context.JSON(http.StatusCreated, &responses.CreateApiKeyResponse{Message: "API key created. Store it safely, it will not be shown again.", Key: rawApiKey, ApiKey: apiKey})
+}
+
+// getApiKeys godoc
+// @Summary      Get all API keys of the authenticated user
+// @Description  Retrieves all API keys of the authenticated user, including revoked and expired ones. The keys themselves are never returned.
+// @Tags         api-keys
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetApiKeysResponse  "List of API keys"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve API keys. Try again later."
+// @Router       /api-keys [get]
+func getApiKeys(context *gin.Context) {
+	log.Debug("Retrieving API keys")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	apiKeys, err := services.GetApiKeyService().FindByUserId(*userId)
+	if err != nil {
+		log.Errorf("Error retrieving API keys for user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve API keys. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetApiKeysResponse{ApiKeys: apiKeys})
+}
+
+// revokeApiKey godoc
+// @Summary      Revoke an API key
+// @Description  Revokes an API key of the authenticated user. Revoked keys can no longer be used to authenticate.
+// @Tags         api-keys
+// @Produce      json
+// @Security     Auth
+// @Param        apiKeyId  path  int  true  "API key ID"
+// @Success      200  {object}  responses.RevokeApiKeyResponse  "API key revoked."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid API key ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "API key not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not revoke API key. Try again later."
+// @Router       /api-keys/{apiKeyId} [delete]
+func revokeApiKey(context *gin.Context) {
+	log.Debug("Revoking API key")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	apiKeyId := getPathId(context, "apiKeyId", "API key")
+	if apiKeyId == nil {
+		return
+	}
+
+	err := services.GetApiKeyService().Revoke(*userId, *apiKeyId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			log.Warnf("API key %d not found for user %d", *apiKeyId, *userId)
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "API key not found."})
+		} else {
+			log.Errorf("Error revoking API key %d: %v", *apiKeyId, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not revoke API key. Try again later."})
+		}
+		return
+	}
+
+	log.Debugf("API key %d revoked for user %d", *apiKeyId, *userId)
+	context.JSON(http.StatusOK, &responses.RevokeApiKeyResponse{Message: "API key revoked."})
+}
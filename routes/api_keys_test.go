@@ -0,0 +1,205 @@
+package routes
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockApiKeyService struct {
+	validateFunc     func(apiKey *models.ApiKey) error
+	createFunc       func(apiKey *models.ApiKey) (string, error)
+	findByUserIdFunc func(userId int64) ([]*models.ApiKey, error)
+	revokeFunc       func(userId int64, apiKeyId int64) error
+	authenticateFunc func(rawApiKey string) (*models.ApiKey, error)
+}
+
+func (m *mockApiKeyService) Validate(apiKey *models.ApiKey) error {
+	if m.validateFunc == nil {
+		return nil
+	}
+	return m.validateFunc(apiKey)
+}
+func (m *mockApiKeyService) Create(apiKey *models.ApiKey) (string, error) {
+	return m.createFunc(apiKey)
+}
+func (m *mockApiKeyService) FindByUserId(userId int64) ([]*models.ApiKey, error) {
+	return m.findByUserIdFunc(userId)
+}
+func (m *mockApiKeyService) Revoke(userId int64, apiKeyId int64) error {
+	return m.revokeFunc(userId, apiKeyId)
+}
+func (m *mockApiKeyService) Authenticate(rawApiKey string) (*models.ApiKey, error) {
+	return m.authenticateFunc(rawApiKey)
+}
+
+func setMockApiKeyService(mock services.ApiKeyServiceInterface) func() {
+	orig := services.GetApiKeyService
+	services.GetApiKeyService = func() services.ApiKeyServiceInterface { return mock }
+	return func() { services.GetApiKeyService = orig }
+}
+
+func newApiKeyTestContext(method string, url string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	return c, w
+}
+
+// --- Tests ---
+
+func TestCreateApiKey_Success(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		createFunc: func(apiKey *models.ApiKey) (string, error) {
+			assert.Equal(t, int64(1), apiKey.UserID)
+			assert.Equal(t, "CI", apiKey.Name)
+			apiKey.ID = 5
+			return "tak_secret", nil
+		},
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("POST", "/api-keys", `{"name":"CI","scopes":["itineraries:read","jobs:write"]}`)
+	setUserId(c, 1)
+
+	createApiKey(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":"tak_secret"`)
+	assert.NotContains(t, w.Body.String(), "keyHash")
+}
+
+func TestCreateApiKey_Unauthorized(t *testing.T) {
+	c, w := newApiKeyTestContext("POST", "/api-keys", `{"name":"CI","scopes":["jobs:read"]}`)
+
+	createApiKey(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCreateApiKey_BadRequest(t *testing.T) {
+	c, w := newApiKeyTestContext("POST", "/api-keys", `{"name":"CI","scopes":[]}`)
+	setUserId(c, 1)
+
+	createApiKey(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateApiKey_ValidationError(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		validateFunc: func(apiKey *models.ApiKey) error { return errors.New("unknown API key scope: users:write") },
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("POST", "/api-keys", `{"name":"CI","scopes":["users:write"]}`)
+	setUserId(c, 1)
+
+	createApiKey(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown API key scope: users:write")
+}
+
+func TestCreateApiKey_CreateError(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		createFunc: func(apiKey *models.ApiKey) (string, error) { return "", errors.New("db error") },
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("POST", "/api-keys", `{"name":"CI","scopes":["jobs:read"]}`)
+	setUserId(c, 1)
+
+	createApiKey(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetApiKeys_Success(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		findByUserIdFunc: func(userId int64) ([]*models.ApiKey, error) {
+			return []*models.ApiKey{{ID: 1, Name: "CI", KeyPrefix: "tak_01234567", KeyHash: "hash"}}, nil
+		},
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("GET", "/api-keys", "")
+	setUserId(c, 1)
+
+	getApiKeys(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "tak_01234567")
+	assert.NotContains(t, w.Body.String(), "hash")
+}
+
+func TestGetApiKeys_Error(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		findByUserIdFunc: func(userId int64) ([]*models.ApiKey, error) { return nil, errors.New("db error") },
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("GET", "/api-keys", "")
+	setUserId(c, 1)
+
+	getApiKeys(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRevokeApiKey_Success(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		revokeFunc: func(userId int64, apiKeyId int64) error {
+			assert.Equal(t, int64(1), userId)
+			assert.Equal(t, int64(5), apiKeyId)
+			return nil
+		},
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("DELETE", "/api-keys/5", "")
+	c.Params = gin.Params{{Key: "apiKeyId", Value: "5"}}
+	setUserId(c, 1)
+
+	revokeApiKey(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRevokeApiKey_InvalidId(t *testing.T) {
+	c, w := newApiKeyTestContext("DELETE", "/api-keys/abc", "")
+	c.Params = gin.Params{{Key: "apiKeyId", Value: "abc"}}
+	setUserId(c, 1)
+
+	revokeApiKey(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRevokeApiKey_NotFound(t *testing.T) {
+	restore := setMockApiKeyService(&mockApiKeyService{
+		revokeFunc: func(userId int64, apiKeyId int64) error { return sql.ErrNoRows },
+	})
+	defer restore()
+
+	c, w := newApiKeyTestContext("DELETE", "/api-keys/5", "")
+	c.Params = gin.Params{{Key: "apiKeyId", Value: "5"}}
+	setUserId(c, 1)
+
+	revokeApiKey(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getMyAuditEvents godoc
+// @Summary      Get the audit events of the authenticated user
+// @Description  Retrieves the audit events of the authenticated user from the newest to the oldest, optionally filtered by type and date range. Use the nextCursor of a page to get the next one.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Param        type    query  string  false  "Audit event type"  example(itinerary.created)
+// @Param        from    query  string  false  "Start of the date range (RFC 3339)"  example(2024-06-01T00:00:00Z)
+// @Param        to      query  string  false  "End of the date range (RFC 3339)"  example(2024-06-30T23:59:59Z)
+// @Param        cursor  query  string  false  "Cursor of the page to get"
+// @Param        limit   query  int     false  "Maximum number of events per page (1-200, default 50)"
+// @Success      200  {object}  responses.GetAuditEventsResponse  "Page of audit events"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid filters or cursor."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get audit events. Try again later."
+// @Router       /me/audit-events [get]
+func getMyAuditEvents(context *gin.Context) {
+	log.Debug("Retrieving audit events of the authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	var input requests.GetAuditEventsRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing audit events query: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data."})
+		return
+	}
+
+	findAuditEvents(context, services.AuditEventsQuery{
+		UserID:    *userId,
+		EventType: input.Type,
+		From:      input.From,
+		To:        input.To,
+		Cursor:    input.Cursor,
+		Limit:     input.Limit,
+	})
+}
+
+// getAllAuditEvents godoc
+// @Summary      Get the audit events of all users
+// @Description  Retrieves the audit events of all users from the newest to the oldest, optionally filtered by user, type and date range. Use the nextCursor of a page to get the next one. Only available for administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        userId  query  int     false  "User ID"
+// @Param        type    query  string  false  "Audit event type"  example(user.login_failed)
+// @Param        from    query  string  false  "Start of the date range (RFC 3339)"  example(2024-06-01T00:00:00Z)
+// @Param        to      query  string  false  "End of the date range (RFC 3339)"  example(2024-06-30T23:59:59Z)
+// @Param        cursor  query  string  false  "Cursor of the page to get"
+// @Param        limit   query  int     false  "Maximum number of events per page (1-200, default 50)"
+// @Success      200  {object}  responses.GetAuditEventsResponse  "Page of audit events"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid filters or cursor."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get audit events. Try again later."
+// @Router       /admin/audit-events [get]
+func getAllAuditEvents(context *gin.Context) {
+	log.Debug("Retrieving audit events of all users")
+
+	var input requests.GetAllAuditEventsRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing audit events query: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data."})
+		return
+	}
+
+	findAuditEvents(context, services.AuditEventsQuery{
+		UserID:    input.UserID,
+		EventType: input.Type,
+		From:      input.From,
+		To:        input.To,
+		Cursor:    input.Cursor,
+		Limit:     input.Limit,
+	})
+}
+
+func findAuditEvents(context *gin.Context, query services.AuditEventsQuery) {
+	page, err := services.GetAuditService().FindEvents(query)
+	if err != nil {
+		log.Errorf("Error retrieving audit events: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "invalid event type"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid audit event type."})
+		case strings.Contains(err.Error(), "invalid date range"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The start of the date range must be before its end."})
+		case strings.Contains(err.Error(), "invalid cursor"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid cursor."})
+		default:
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get audit events. Try again later."})
+		}
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetAuditEventsResponse{Events: page.Events, NextCursor: page.NextCursor})
+}
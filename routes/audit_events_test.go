@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuditService struct {
+	Query  services.AuditEventsQuery
+	Result *services.AuditEventsPage
+	Err    error
+}
+
+func (m *mockAuditService) FindEvents(query services.AuditEventsQuery) (*services.AuditEventsPage, error) {
+	m.Query = query
+	return m.Result, m.Err
+}
+
+func setMockAuditService(mock *mockAuditService) func() {
+	orig := services.GetAuditService
+	services.GetAuditService = func() services.AuditServiceInterface {
+		return mock
+	}
+	return func() { services.GetAuditService = orig }
+}
+
+func TestGetMyAuditEvents_Success(t *testing.T) {
+	mock := &mockAuditService{Result: &services.AuditEventsPage{
+		Events:     []*models.AuditEvent{{ID: 5, UserID: 1, EventType: models.AuditEventItineraryCreated}},
+		NextCursor: "next",
+	}}
+	defer setMockAuditService(mock)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?type=itinerary.created&from=2024-06-01T00:00:00Z&limit=10", nil)
+	getMyAuditEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"eventType":"itinerary.created"`)
+	assert.Contains(t, w.Body.String(), `"nextCursor":"next"`)
+	assert.Equal(t, int64(1), mock.Query.UserID)
+	assert.Equal(t, models.AuditEventItineraryCreated, mock.Query.EventType)
+	assert.NotNil(t, mock.Query.From)
+	assert.Nil(t, mock.Query.To)
+	assert.Equal(t, 10, mock.Query.Limit)
+}
+
+func TestGetMyAuditEvents_InvalidQuery(t *testing.T) {
+	defer setMockAuditService(&mockAuditService{})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?from=yesterday", nil)
+	getMyAuditEvents(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetMyAuditEvents_InvalidCursor(t *testing.T) {
+	defer setMockAuditService(&mockAuditService{Err: errors.New("invalid cursor")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?cursor=abc", nil)
+	getMyAuditEvents(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid cursor.")
+}
+
+func TestGetMyAuditEvents_Error(t *testing.T) {
+	defer setMockAuditService(&mockAuditService{Err: errors.New("failed to find audit events")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getMyAuditEvents(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetAllAuditEvents_Success(t *testing.T) {
+	mock := &mockAuditService{Result: &services.AuditEventsPage{Events: []*models.AuditEvent{}}}
+	defer setMockAuditService(mock)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?userId=7&type=user.login_failed", nil)
+	getAllAuditEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "nextCursor")
+	assert.Equal(t, int64(7), mock.Query.UserID)
+	assert.Equal(t, models.AuditEventLoginFailed, mock.Query.EventType)
+}
+
+func TestGetAllAuditEvents_AllUsers(t *testing.T) {
+	mock := &mockAuditService{Result: &services.AuditEventsPage{Events: []*models.AuditEvent{}}}
+	defer setMockAuditService(mock)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getAllAuditEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(0), mock.Query.UserID)
+}
+
+func TestGetAllAuditEvents_InvalidType(t *testing.T) {
+	defer setMockAuditService(&mockAuditService{Err: errors.New("invalid event type")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?type=unknown", nil)
+	getAllAuditEvents(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid audit event type.")
+}
@@ -0,0 +1,276 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getItineraryBudget godoc
+// @Summary      Get the budget of an itinerary
+// @Description  Compares the budget of an itinerary with the estimated cost of the stays in its destinations, by category and destination. The daily cost estimates of the cities are multiplied by the days of each stay and all amounts are converted to the currency, which is the one of the budget (or EUR if there is none) by default. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path   int     true   "Itinerary ID"
+// @Param        currency     query  string  false  "ISO 4217 code of the currency of the amounts"
+// @Success      200  {object}  responses.GetBudgetSummaryResponse  "Budget summary"
+// @Failure      400  {object}  responses.ErrorResponse  "Unsupported currency."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get budget. Try again later."
+// @Router       /itineraries/{itineraryId}/budget [get]
+func getItineraryBudget(context *gin.Context) {
+	log.Debug("Retrieving itinerary budget")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	summary, err := services.GetBudgetService().GetSummary(itinerary, context.Query("currency"))
+	if err != nil {
+		log.Errorf("Error retrieving budget of itinerary %d: %v", itinerary.ID, err)
+		handleBudgetError(context, err, "Could not get budget. Try again later.")
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetBudgetSummaryResponse{Summary: summary})
+}
+
+// updateItineraryBudget godoc
+// @Summary      Set the budget of an itinerary
+// @Description  Replaces the budget of an itinerary, in its home currency. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int                     true  "Itinerary ID"
+// @Param        budget       body  requests.BudgetRequest  true  "Budget"
+// @Success      200  {object}  responses.UpdateBudgetResponse  "Budget updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update budget. Try again later."
+// @Router       /itineraries/{itineraryId}/budget [put]
+func updateItineraryBudget(context *gin.Context) {
+	log.Debug("Updating itinerary budget")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.BudgetRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The amount must be positive and the currency an ISO 4217 code like EUR."})
+		return
+	}
+
+	budget := &models.ItineraryBudget{Amount: models.AmountOf(input.Amount), Currency: input.Currency}
+	err := services.GetBudgetService().SaveBudget(itinerary, budget, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating budget of itinerary %d: %v", itinerary.ID, err)
+		handleBudgetError(context, err, "Could not update budget. Try again later.")
+		return
+	}
+
+	log.Debugf("Budget of itinerary %d updated", itinerary.ID)
+	context.JSON(http.StatusOK, &responses.UpdateBudgetResponse{Message: "Budget updated.", Budget: budget})
+}
+
+// deleteItineraryBudget godoc
+// @Summary      Remove the budget of an itinerary
+// @Description  Removes the budget of an itinerary. The cost estimates of its destinations are kept. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.DeleteBudgetResponse  "Budget removed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or budget not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not remove budget. Try again later."
+// @Router       /itineraries/{itineraryId}/budget [delete]
+func deleteItineraryBudget(context *gin.Context) {
+	log.Debug("Deleting itinerary budget")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	err := services.GetBudgetService().DeleteBudget(itinerary, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting budget of itinerary %d: %v", itinerary.ID, err)
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Budget not found."})
+			return
+		}
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not remove budget. Try again later."})
+		return
+	}
+
+	log.Debugf("Budget of itinerary %d removed", itinerary.ID)
+	context.JSON(http.StatusOK, &responses.DeleteBudgetResponse{Message: "Budget removed."})
+}
+
+// updateDestinationCostEstimate godoc
+// @Summary      Set the cost estimate of a destination of an itinerary
+// @Description  Replaces the estimate of what the travellers spend a day in the city of a destination of an itinerary, by category. The estimate applies to every stay in the city and is kept when the destinations of the itinerary are replaced. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path  int                           true  "Itinerary ID"
+// @Param        destinationId  path  int                           true  "Destination ID"
+// @Param        estimate       body  requests.CostEstimateRequest  true  "Daily cost estimate"
+// @Success      200  {object}  responses.UpdateCostEstimateResponse  "Cost estimate updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or destination not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update cost estimate. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId}/cost-estimate [put]
+func updateDestinationCostEstimate(context *gin.Context) {
+	log.Debug("Updating destination cost estimate")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	var input requests.CostEstimateRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The currency must be an ISO 4217 code like EUR and the amounts cannot be negative."})
+		return
+	}
+
+	estimate := &models.DestinationCostEstimate{Currency: input.Currency, Lodging: models.AmountOf(input.Lodging),
+		Food: models.AmountOf(input.Food), Transport: models.AmountOf(input.Transport), Activities: models.AmountOf(input.Activities)}
+	err := services.GetBudgetService().SaveEstimate(itinerary, *destinationId, estimate, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating cost estimate of destination %d of itinerary %d: %v", *destinationId, itinerary.ID, err)
+		handleBudgetError(context, err, "Could not update cost estimate. Try again later.")
+		return
+	}
+
+	log.Debugf("Cost estimate of destination %d of itinerary %d updated", *destinationId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.UpdateCostEstimateResponse{Message: "Cost estimate updated.", Estimate: estimate})
+}
+
+// deleteDestinationCostEstimate godoc
+// @Summary      Remove the cost estimate of a destination of an itinerary
+// @Description  Removes the daily cost estimate of the city of a destination of an itinerary. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path  int  true  "Itinerary ID"
+// @Param        destinationId  path  int  true  "Destination ID"
+// @Success      200  {object}  responses.DeleteCostEstimateResponse  "Cost estimate removed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary, destination or cost estimate not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not remove cost estimate. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId}/cost-estimate [delete]
+func deleteDestinationCostEstimate(context *gin.Context) {
+	log.Debug("Deleting destination cost estimate")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	err := services.GetBudgetService().DeleteEstimate(itinerary, *destinationId, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting cost estimate of destination %d of itinerary %d: %v", *destinationId, itinerary.ID, err)
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Destination or cost estimate not found."})
+			return
+		}
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not remove cost estimate. Try again later."})
+		return
+	}
+
+	log.Debugf("Cost estimate of destination %d of itinerary %d removed", *destinationId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.DeleteCostEstimateResponse{Message: "Cost estimate removed."})
+}
+
+// generateCostEstimates godoc
+// @Summary      Generate the cost estimates of an itinerary
+// @Description  Asks the LLM for the daily spending of the travellers in the cities of the destinations of an itinerary, broken down into lodging, food, transport and activities, for the travelling party and budget level of its traveller preferences. The generated estimates replace the previous ones of the cities. The LLM call counts towards the jobs running limit of the user and is cancelled after LLM_REQUEST_TIMEOUT_SECONDS. The currency is the one of the budget (or EUR if there is none) by default. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path   int     true   "Itinerary ID"
+// @Param        currency     query  string  false  "ISO 4217 code of the currency of the estimates"
+// @Success      200  {object}  responses.GenerateCostEstimatesResponse  "Cost estimates generated."
+// @Failure      400  {object}  responses.ErrorResponse  "Unsupported currency."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Too many jobs running for your user."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not generate cost estimates. Try again later."
+// @Failure      504  {object}  responses.ErrorResponse  "Cost estimates generation timed out. Try again later."
+// @Router       /itineraries/{itineraryId}/budget/estimates [post]
+func generateCostEstimates(context *gin.Context) {
+	log.Debug("Generating itinerary cost estimates")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if !checkJobsRunningLimit(context, services.GetItineraryFileJobService(), userId) {
+		return
+	}
+
+	estimates, err := services.GetBudgetService().GenerateEstimates(context.Request.Context(), itinerary, context.Query("currency"), userId)
+	if err != nil {
+		log.Errorf("Error generating cost estimates of itinerary %d: %v", itinerary.ID, err)
+		if strings.Contains(err.Error(), "timed out") {
+			context.JSON(http.StatusGatewayTimeout, &responses.ErrorResponse{Message: "Cost estimates generation timed out. Try again later."})
+			return
+		}
+		handleBudgetError(context, err, "Could not generate cost estimates. Try again later.")
+		return
+	}
+
+	log.Debugf("Cost estimates of itinerary %d generated", itinerary.ID)
+	context.JSON(http.StatusOK, &responses.GenerateCostEstimatesResponse{Message: "Cost estimates generated.", Estimates: estimates})
+}
+
+func handleBudgetError(context *gin.Context, err error, internalErrorMessage string) {
+	switch {
+	case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Destination not found."})
+	case strings.HasPrefix(err.Error(), "invalid currency: "):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Unsupported currency."})
+	case strings.HasPrefix(err.Error(), "invalid "):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+	default:
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: internalErrorMessage})
+	}
+}
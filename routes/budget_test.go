@@ -0,0 +1,245 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockBudgetService struct {
+	Summary          *models.BudgetSummary
+	Estimates        []*models.DestinationCostEstimate
+	Err              error
+	Currency         string
+	SavedBudget      *models.ItineraryBudget
+	SavedEstimate    *models.DestinationCostEstimate
+	DestinationId    int64
+	Deleted          bool
+	GeneratedInCalls int
+}
+
+func (m *mockBudgetService) GetSummary(_ *models.Itinerary, currency string) (*models.BudgetSummary, error) {
+	m.Currency = currency
+	return m.Summary, m.Err
+}
+func (m *mockBudgetService) SaveBudget(_ *models.Itinerary, budget *models.ItineraryBudget, _ int64) error {
+	m.SavedBudget = budget
+	return m.Err
+}
+func (m *mockBudgetService) DeleteBudget(_ *models.Itinerary, _ int64) error {
+	m.Deleted = true
+	return m.Err
+}
+func (m *mockBudgetService) SaveEstimate(_ *models.Itinerary, destinationId int64, estimate *models.DestinationCostEstimate, _ int64) error {
+	m.DestinationId = destinationId
+	m.SavedEstimate = estimate
+	return m.Err
+}
+func (m *mockBudgetService) DeleteEstimate(_ *models.Itinerary, destinationId int64, _ int64) error {
+	m.DestinationId = destinationId
+	m.Deleted = true
+	return m.Err
+}
+func (m *mockBudgetService) GenerateEstimates(_ context.Context, _ *models.Itinerary, currency string, _ int64) ([]*models.DestinationCostEstimate, error) {
+	m.Currency = currency
+	m.GeneratedInCalls++
+	return m.Estimates, m.Err
+}
+
+func setMockBudgetService(mock *mockBudgetService) func() {
+	orig := services.GetBudgetService
+	services.GetBudgetService = func() services.BudgetServiceInterface {
+		return mock
+	}
+	return func() { services.GetBudgetService = orig }
+}
+
+// --- Tests ---
+
+func TestGetItineraryBudget_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	remaining := models.AmountOf(60)
+	budgetService := &mockBudgetService{Summary: &models.BudgetSummary{ItineraryID: 1, Currency: "USD", Remaining: &remaining}}
+	defer setMockBudgetService(budgetService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	c.Request.URL.RawQuery = "currency=USD"
+	getItineraryBudget(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "USD", budgetService.Currency)
+	assert.Contains(t, w.Body.String(), `"remaining":60`)
+}
+
+func TestGetItineraryBudget_UnsupportedCurrency(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockBudgetService(&mockBudgetService{Err: errors.New("invalid currency: unsupported currency: XXX")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryBudget(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Unsupported currency.")
+}
+
+func TestUpdateItineraryBudget_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	budgetService := &mockBudgetService{}
+	defer setMockBudgetService(budgetService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"amount":2500.5,"currency":"GBP"}`, itineraryIdParams)
+	updateItineraryBudget(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.Amount(250050), budgetService.SavedBudget.Amount)
+	assert.Equal(t, "GBP", budgetService.SavedBudget.Currency)
+}
+
+func TestUpdateItineraryBudget_Invalid(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	for _, body := range []string{`{"amount":0,"currency":"EUR"}`, `{"amount":100,"currency":"EURO"}`, `{"amount":100}`} {
+		budgetService := &mockBudgetService{}
+		restore := setMockBudgetService(budgetService)
+
+		c, w := newAuthenticatedContext(http.MethodPut, body, itineraryIdParams)
+		updateItineraryBudget(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, budgetService.SavedBudget, body)
+	}
+}
+
+func TestUpdateItineraryBudget_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	budgetService := &mockBudgetService{}
+	defer setMockBudgetService(budgetService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"amount":100,"currency":"EUR"}`, itineraryIdParams)
+	updateItineraryBudget(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, budgetService.SavedBudget)
+}
+
+func TestDeleteItineraryBudget_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockBudgetService(&mockBudgetService{Err: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryIdParams)
+	deleteItineraryBudget(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateDestinationCostEstimate_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	budgetService := &mockBudgetService{}
+	defer setMockBudgetService(budgetService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"currency":"JPY","lodging":12000,"food":5000}`, itineraryDestinationParams)
+	updateDestinationCostEstimate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(10), budgetService.DestinationId)
+	assert.Equal(t, "JPY", budgetService.SavedEstimate.Currency)
+	assert.Equal(t, models.AmountOf(12000), budgetService.SavedEstimate.Lodging)
+}
+
+func TestUpdateDestinationCostEstimate_Negative(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	budgetService := &mockBudgetService{}
+	defer setMockBudgetService(budgetService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"currency":"EUR","food":-1}`, itineraryDestinationParams)
+	updateDestinationCostEstimate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, budgetService.SavedEstimate)
+}
+
+func TestUpdateDestinationCostEstimate_DestinationNotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockBudgetService(&mockBudgetService{Err: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"currency":"EUR","food":30}`, itineraryDestinationParams)
+	updateDestinationCostEstimate(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteDestinationCostEstimate_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	budgetService := &mockBudgetService{}
+	defer setMockBudgetService(budgetService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryDestinationParams)
+	deleteDestinationCostEstimate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, budgetService.Deleted)
+}
+
+func TestGenerateCostEstimates_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	budgetService := &mockBudgetService{Estimates: []*models.DestinationCostEstimate{
+		{ItineraryID: 1, Country: "Spain", City: "Madrid", Currency: "EUR", Lodging: models.AmountOf(100), Source: models.CostEstimateSourceGenerated},
+	}}
+	defer setMockBudgetService(budgetService)()
+	defer setMockJobsService(&mockJobsService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateCostEstimates(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, budgetService.GeneratedInCalls)
+	assert.Contains(t, w.Body.String(), `"source":"generated"`)
+	assert.Contains(t, w.Body.String(), `"lodging":100`)
+}
+
+func TestGenerateCostEstimates_TooManyJobsRunning(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	budgetService := &mockBudgetService{}
+	defer setMockBudgetService(budgetService)()
+	defer setMockJobsService(&mockJobsService{GetInProgressJobsOfUserCountVal: 5})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateCostEstimates(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 0, budgetService.GeneratedInCalls)
+}
+
+func TestGenerateCostEstimates_TimedOut(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockBudgetService(&mockBudgetService{Err: errors.New("cost estimates generation timed out")})()
+	defer setMockJobsService(&mockJobsService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateCostEstimates(c)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestGenerateCostEstimates_Error(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockBudgetService(&mockBudgetService{Err: errors.New("failed to generate cost estimates")})()
+	defer setMockJobsService(&mockJobsService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateCostEstimates(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Could not generate cost estimates. Try again later.")
+}
@@ -0,0 +1,253 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// requestDataExport godoc
+// @Summary      Request an export of all the user data
+// @Description  Starts a background job that collects the profile, itineraries, destinations, itinerary file job metadata, audit events and generated itinerary files of the authenticated user into a single ZIP file. Only one data export can be in progress at a time.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Success      202  {object}  responses.StartDataExportResponse  "Data export started successfully."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      409  {object}  responses.ErrorResponse  "A data export is already in progress."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create data export. Try again later."
+// @Router       /me/export [post]
+func requestDataExport(context *gin.Context) {
+	log.Debug("Requesting data export")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	dataExportJobService := services.GetDataExportJobService()
+
+	inProgressCount, err := dataExportJobService.GetInProgressJobsOfUserCount(*userId)
+	if err != nil {
+		log.Errorf("Error checking data exports in progress for user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not check data export status. Try again later."})
+		return
+	}
+
+	if inProgressCount > 0 {
+		log.Errorf("User %d already has a data export in progress", *userId)
+		context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: "A data export is already in progress. Please wait for it to complete."})
+		return
+	}
+
+	dataExportTask, err := dataExportJobService.PrepareJob(*userId)
+	if err != nil {
+		log.Errorf("Error preparing data export job: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create data export. Try again later."})
+		return
+	}
+
+	job := dataExportTask.DataExportJob
+
+	asyncTaskQueue, err := services.NewAsyncqTaskQueue()
+	if err != nil {
+		log.Errorf("Error initializing async task queue: %v", err)
+		dataExportJobService.FailJob("Could not enqueue job", job)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create data export. Try again later."})
+		return
+	}
+	defer asyncTaskQueue.Close()
+
+	asyncTaskId, err := asyncTaskQueue.EnqueueDataExportJob(*dataExportTask)
+	if err != nil {
+		log.Error("Error enqueuing data export job: ", err)
+		dataExportJobService.FailJob("Could not enqueue job", job)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not enqueue data export. Try again later."})
+		return
+	}
+
+	err = dataExportJobService.AddAsyncTaskId(*asyncTaskId, job)
+	if err != nil {
+		log.Error("Error adding async task ID to data export job: ", err)
+		dataExportJobService.FailJob("Could not add async task ID to job", job)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not add async task ID to data export. Try again later."})
+		return
+	}
+
+	log.Debugf("Data export job started successfully with ID %d for user %d", job.ID, *userId)
+	context.JSON(http.StatusAccepted, &responses.StartDataExportResponse{Message: "Data export started successfully.", JobId: job.ID})
+}
+
+// getDataExports godoc
+// @Summary      Get all data exports of the authenticated user
+// @Description  Retrieves the data export jobs of the authenticated user with their status.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetDataExportsResponse  "List of data exports"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get data exports. Try again later."
+// @Router       /me/exports [get]
+func getDataExports(context *gin.Context) {
+	log.Debug("Retrieving data exports")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	jobs, err := services.GetDataExportJobService().FindAliveByUserId(*userId)
+	if err != nil {
+		log.Errorf("Error retrieving data exports of user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get data exports. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetDataExportsResponse{Jobs: jobs})
+}
+
+// getDataExport godoc
+// @Summary      Get a data export by ID
+// @Description  Retrieves the status of a data export job of the authenticated user.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Param        exportJobId  path  int  true  "Data export job ID"
+// @Success      200  {object}  responses.GetDataExportResponse  "Data export job details"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid data export ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Data export not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get data export. Try again later."
+// @Router       /me/exports/{exportJobId} [get]
+func getDataExport(context *gin.Context) {
+	log.Debug("Retrieving data export")
+
+	job := getAndValidateDataExportJob(context)
+	if job == nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetDataExportResponse{Job: job})
+}
+
+// downloadDataExportFile godoc
+// @Summary      Download a data export
+// @Description  Downloads the ZIP file generated by a completed data export job of the authenticated user.
+// @Tags         users
+// @Produce      application/octet-stream
+// @Security     Auth
+// @Param        exportJobId  path  int  true  "Data export job ID"
+// @Success      200  {file}  file  "File downloaded successfully."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid data export ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Data export or file not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not download file. Try again later."
+// @Router       /me/exports/{exportJobId}/file [get]
+func downloadDataExportFile(context *gin.Context) {
+	log.Debug("Downloading data export file")
+
+	job := getAndValidateDataExportJob(context)
+	if job == nil {
+		return
+	}
+
+	if job.Filepath == "" {
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Data export file not found."})
+		return
+	}
+
+	file, err := services.GetDataExportJobService().OpenDataExportJobFile(job)
+	if err != nil {
+		log.Error("Error opening data export file: ", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not open data export file. Try again later."})
+		return
+	}
+	defer file.Close()
+
+	fileName := serveFile(context, file, "")
+	if fileName == "" {
+		return
+	}
+
+	log.Debugf("File %s served successfully for data export job ID %d", fileName, job.ID)
+}
+
+// deleteDataExport godoc
+// @Summary      Delete a data export
+// @Description  Deletes a data export job of the authenticated user. Its ZIP file is removed shortly after by the dead jobs cleanup.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Param        exportJobId  path  int  true  "Data export job ID"
+// @Success      200  {object}  responses.DeleteDataExportResponse  "Data export deleted."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid data export ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Data export not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Cannot delete a data export that is still pending or running."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not delete data export. Try again later."
+// @Router       /me/exports/{exportJobId} [delete]
+func deleteDataExport(context *gin.Context) {
+	log.Debug("Deleting data export")
+
+	job := getAndValidateDataExportJob(context)
+	if job == nil {
+		return
+	}
+
+	if job.Status == "pending" || job.Status == "running" {
+		log.Errorf("Data export job %d is still %s and cannot be deleted", job.ID, job.Status)
+		context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: "Cannot delete a data export that is still pending or running."})
+		return
+	}
+
+	err := services.GetDataExportJobService().SoftDeleteJob(job)
+	if err != nil {
+		log.Errorf("Error deleting data export job %d: %v", job.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete data export. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.DeleteDataExportResponse{Message: "Data export deleted."})
+}
+
+// getAndValidateDataExportJob retrieves the data export job of the path, which must belong to the authenticated user. Jobs of other
+// users are reported as not found
+func getAndValidateDataExportJob(context *gin.Context) *models.DataExportJob {
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return nil
+	}
+
+	jobId := getPathId(context, "exportJobId", "data export")
+	if jobId == nil {
+		return nil
+	}
+
+	job, err := services.GetDataExportJobService().FindAliveById(*jobId)
+	if err == nil && job.UserID != *userId {
+		log.Errorf("Data export job %d does not belong to user %d", *jobId, *userId)
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Data export not found."})
+		} else {
+			log.Errorf("Error retrieving data export job %d: %v", *jobId, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get data export. Try again later."})
+		}
+		return nil
+	}
+
+	return models.InitDataExportJobFunctions(job)
+}
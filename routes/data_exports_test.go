@@ -0,0 +1,332 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockDataExportJobService struct {
+	FindAliveByIdResult        *models.DataExportJob
+	FindAliveByIdErr           error
+	FindAliveByUserIdResult    []*models.DataExportJob
+	FindAliveByUserIdErr       error
+	InProgressCount            int
+	InProgressCountErr         error
+	PrepareJobErr              error
+	AddAsyncTaskIdErr          error
+	OpenDataExportJobFileValue io.ReadSeekCloser
+	OpenDataExportJobFileErr   error
+	SoftDeleteErr              error
+	FailJobCalled              bool
+	SoftDeleteCalled           bool
+}
+
+func (m *mockDataExportJobService) FindAliveById(_ int64) (*models.DataExportJob, error) {
+	return m.FindAliveByIdResult, m.FindAliveByIdErr
+}
+func (m *mockDataExportJobService) FindAliveByUserId(_ int64) ([]*models.DataExportJob, error) {
+	return m.FindAliveByUserIdResult, m.FindAliveByUserIdErr
+}
+func (m *mockDataExportJobService) GetInProgressJobsOfUserCount(_ int64) (int, error) {
+	return m.InProgressCount, m.InProgressCountErr
+}
+func (m *mockDataExportJobService) PrepareJob(userId int64) (*services.DataExportAsyncTaskPayload, error) {
+	if m.PrepareJobErr != nil {
+		return nil, m.PrepareJobErr
+	}
+	return &services.DataExportAsyncTaskPayload{DataExportJob: &models.DataExportJob{ID: 7, UserID: userId}, UserID: userId}, nil
+}
+func (m *mockDataExportJobService) AddAsyncTaskId(_ string, _ *models.DataExportJob) error {
+	return m.AddAsyncTaskIdErr
+}
+func (m *mockDataExportJobService) FailJob(_ string, _ *models.DataExportJob) error {
+	m.FailJobCalled = true
+	return nil
+}
+func (m *mockDataExportJobService) OpenDataExportJobFile(_ *models.DataExportJob) (io.ReadSeekCloser, error) {
+	return m.OpenDataExportJobFileValue, m.OpenDataExportJobFileErr
+}
+func (m *mockDataExportJobService) SoftDeleteJob(_ *models.DataExportJob) error {
+	m.SoftDeleteCalled = true
+	return m.SoftDeleteErr
+}
+func (m *mockDataExportJobService) DeleteDeadJobs(_ int) error { return nil }
+
+func setMockDataExportJobService(mock *mockDataExportJobService) func() {
+	orig := services.GetDataExportJobService
+	services.GetDataExportJobService = func() services.DataExportJobServiceInterface {
+		return mock
+	}
+	return func() { services.GetDataExportJobService = orig }
+}
+
+func setMockAsyncqTaskQueue(queue services.AsyncTaskQueueInterface, err error) func() {
+	orig := services.NewAsyncqTaskQueue
+	services.NewAsyncqTaskQueue = func() (services.AsyncTaskQueueInterface, error) {
+		return queue, err
+	}
+	return func() { services.NewAsyncqTaskQueue = orig }
+}
+
+var exportJobParams = gin.Params{{Key: "exportJobId", Value: "7"}}
+
+// --- Tests ---
+
+func TestRequestDataExport_Success(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{})
+	defer restore()
+	restoreQueue := setMockAsyncqTaskQueue(&mockAsyncqTaskQueue{EnqueueId: "taskid"}, nil)
+	defer restoreQueue()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"jobId":7`)
+}
+
+func TestRequestDataExport_AlreadyInProgress(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{InProgressCount: 1})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRequestDataExport_InProgressCountError(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{InProgressCountErr: errors.New("db error")})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRequestDataExport_PrepareJobError(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{PrepareJobErr: errors.New("prepare error")})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRequestDataExport_EnqueueError(t *testing.T) {
+	mockSvc := &mockDataExportJobService{}
+	restore := setMockDataExportJobService(mockSvc)
+	defer restore()
+	restoreQueue := setMockAsyncqTaskQueue(&mockAsyncqTaskQueue{EnqueueErr: errors.New("enqueue error")}, nil)
+	defer restoreQueue()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.True(t, mockSvc.FailJobCalled)
+}
+
+func TestRequestDataExport_QueueInitError(t *testing.T) {
+	mockSvc := &mockDataExportJobService{}
+	restore := setMockDataExportJobService(mockSvc)
+	defer restore()
+	restoreQueue := setMockAsyncqTaskQueue(nil, errors.New("REDIS_PASSWORD environment variable is not set"))
+	defer restoreQueue()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.True(t, mockSvc.FailJobCalled)
+}
+
+func TestRequestDataExport_AddAsyncTaskIdError(t *testing.T) {
+	mockSvc := &mockDataExportJobService{AddAsyncTaskIdErr: errors.New("update error")}
+	restore := setMockDataExportJobService(mockSvc)
+	defer restore()
+	restoreQueue := setMockAsyncqTaskQueue(&mockAsyncqTaskQueue{EnqueueId: "taskid"}, nil)
+	defer restoreQueue()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", nil)
+	requestDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.True(t, mockSvc.FailJobCalled)
+}
+
+func TestGetDataExports_Success(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByUserIdResult: []*models.DataExportJob{{ID: 7, UserID: 1, Status: "completed", Filepath: "secret/path.zip"}},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getDataExports(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"completed"`)
+	assert.NotContains(t, w.Body.String(), "secret/path.zip")
+}
+
+func TestGetDataExports_Error(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{FindAliveByUserIdErr: errors.New("db error")})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getDataExports(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetDataExport_Success(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByIdResult: &models.DataExportJob{ID: 7, UserID: 1, Status: "running"},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	getDataExport(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"running"`)
+}
+
+func TestGetDataExport_InvalidId(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "exportJobId", Value: "abc"}})
+	getDataExport(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetDataExport_NotFound(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{FindAliveByIdErr: sql.ErrNoRows})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	getDataExport(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetDataExport_OtherUser(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByIdResult: &models.DataExportJob{ID: 7, UserID: 2, Status: "completed"},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	getDataExport(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetDataExport_UnexpectedError(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{FindAliveByIdErr: errors.New("db error")})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	getDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestDownloadDataExportFile_NoFile(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByIdResult: &models.DataExportJob{ID: 7, UserID: 1, Status: "running"},
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	downloadDataExportFile(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDownloadDataExportFile_OpenError(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByIdResult:      &models.DataExportJob{ID: 7, UserID: 1, Status: "completed", Filepath: "export.zip"},
+		OpenDataExportJobFileErr: errors.New("open error"),
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	downloadDataExportFile(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestDownloadDataExportFile_Success(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "export-*.zip")
+	assert.NoError(t, err)
+	_, err = file.WriteString("zip content")
+	assert.NoError(t, err)
+
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByIdResult:        &models.DataExportJob{ID: 7, UserID: 1, Status: "completed", Filepath: file.Name()},
+		OpenDataExportJobFileValue: file,
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", exportJobParams)
+	downloadDataExportFile(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "zip content", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	assert.Empty(t, w.Header().Get("Content-Language"))
+}
+
+func TestDeleteDataExport_Success(t *testing.T) {
+	mockSvc := &mockDataExportJobService{
+		FindAliveByIdResult: &models.DataExportJob{ID: 7, UserID: 1, Status: "completed"},
+	}
+	restore := setMockDataExportJobService(mockSvc)
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", exportJobParams)
+	deleteDataExport(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, mockSvc.SoftDeleteCalled)
+}
+
+func TestDeleteDataExport_InProgress(t *testing.T) {
+	mockSvc := &mockDataExportJobService{
+		FindAliveByIdResult: &models.DataExportJob{ID: 7, UserID: 1, Status: "running"},
+	}
+	restore := setMockDataExportJobService(mockSvc)
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", exportJobParams)
+	deleteDataExport(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.False(t, mockSvc.SoftDeleteCalled)
+}
+
+func TestDeleteDataExport_Error(t *testing.T) {
+	restore := setMockDataExportJobService(&mockDataExportJobService{
+		FindAliveByIdResult: &models.DataExportJob{ID: 7, UserID: 1, Status: "failed"},
+		SoftDeleteErr:       errors.New("db error"),
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", exportJobParams)
+	deleteDataExport(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
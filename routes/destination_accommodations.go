@@ -0,0 +1,150 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getDestinationAccommodation godoc
+// @Summary      Get the accommodation of a destination of an itinerary
+// @Description  Gets where the travellers sleep during the stay in a destination of an itinerary. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path  int  true  "Itinerary ID"
+// @Param        destinationId  path  int  true  "Destination ID"
+// @Success      200  {object}  responses.GetAccommodationResponse  "Accommodation"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary, destination or accommodation not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get itinerary. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId}/accommodation [get]
+func getDestinationAccommodation(context *gin.Context) {
+	log.Debug("Retrieving destination accommodation")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	for _, accommodation := range itinerary.Accommodations {
+		if accommodation.DestinationID != nil && *accommodation.DestinationID == *destinationId {
+			context.JSON(http.StatusOK, &responses.GetAccommodationResponse{Accommodation: accommodation})
+			return
+		}
+	}
+
+	context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Destination or accommodation not found."})
+}
+
+// updateDestinationAccommodation godoc
+// @Summary      Set the accommodation of a destination of an itinerary
+// @Description  Adds or replaces where the travellers sleep during the stay in a destination of an itinerary. The check-in and check-out must be between the arrival and departure dates of the destination, and the cost of the whole stay needs a currency. The accommodation is matched to the stay in the city its check-in falls in, so it is kept when the destinations of the itinerary are replaced. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path  int                            true  "Itinerary ID"
+// @Param        destinationId  path  int                            true  "Destination ID"
+// @Param        accommodation  body  requests.AccommodationRequest  true  "Accommodation"
+// @Success      200  {object}  responses.UpdateAccommodationResponse  "Accommodation updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid accommodation."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or destination not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update accommodation. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId}/accommodation [put]
+func updateDestinationAccommodation(context *gin.Context) {
+	log.Debug("Updating destination accommodation")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	var input requests.AccommodationRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The name, check-in and check-out are required, and a cost cannot be negative and needs an ISO 4217 currency like EUR."})
+		return
+	}
+
+	accommodation := &models.DestinationAccommodation{Name: input.Name, Address: input.Address, CheckIn: input.CheckIn,
+		CheckOut: input.CheckOut, ConfirmationNumber: input.ConfirmationNumber, Cost: input.Cost, Currency: input.Currency}
+	err := services.GetDestinationAccommodationService().Save(itinerary, *destinationId, accommodation, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating accommodation of destination %d of itinerary %d: %v", *destinationId, itinerary.ID, err)
+		handleAccommodationError(context, err, "Destination not found.", "Could not update accommodation. Try again later.")
+		return
+	}
+
+	log.Debugf("Accommodation of destination %d of itinerary %d updated", *destinationId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.UpdateAccommodationResponse{Message: "Accommodation updated.", Accommodation: accommodation})
+}
+
+// deleteDestinationAccommodation godoc
+// @Summary      Remove the accommodation of a destination of an itinerary
+// @Description  Removes the accommodation of a destination of an itinerary. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path  int  true  "Itinerary ID"
+// @Param        destinationId  path  int  true  "Destination ID"
+// @Success      200  {object}  responses.DeleteAccommodationResponse  "Accommodation removed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary, destination or accommodation not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not remove accommodation. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId}/accommodation [delete]
+func deleteDestinationAccommodation(context *gin.Context) {
+	log.Debug("Deleting destination accommodation")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	err := services.GetDestinationAccommodationService().Delete(itinerary, *destinationId, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting accommodation of destination %d of itinerary %d: %v", *destinationId, itinerary.ID, err)
+		handleAccommodationError(context, err, "Destination or accommodation not found.", "Could not remove accommodation. Try again later.")
+		return
+	}
+
+	log.Debugf("Accommodation of destination %d of itinerary %d removed", *destinationId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.DeleteAccommodationResponse{Message: "Accommodation removed."})
+}
+
+func handleAccommodationError(context *gin.Context, err error, notFoundMessage string, internalErrorMessage string) {
+	switch {
+	case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: notFoundMessage})
+	case strings.HasPrefix(err.Error(), "invalid accommodation: "):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+	default:
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: internalErrorMessage})
+	}
+}
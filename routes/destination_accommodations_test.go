@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockDestinationAccommodationService struct {
+	Err           error
+	Accommodation *models.DestinationAccommodation
+	DestinationId int64
+	Deleted       bool
+}
+
+func (m *mockDestinationAccommodationService) Save(_ *models.Itinerary, destinationId int64, accommodation *models.DestinationAccommodation, _ int64) error {
+	m.DestinationId = destinationId
+	m.Accommodation = accommodation
+	return m.Err
+}
+func (m *mockDestinationAccommodationService) Delete(_ *models.Itinerary, destinationId int64, _ int64) error {
+	m.DestinationId = destinationId
+	m.Deleted = true
+	return m.Err
+}
+
+func setMockDestinationAccommodationService(mock *mockDestinationAccommodationService) func() {
+	orig := services.GetDestinationAccommodationService
+	services.GetDestinationAccommodationService = func() services.DestinationAccommodationServiceInterface {
+		return mock
+	}
+	return func() { services.GetDestinationAccommodationService = orig }
+}
+
+// --- Tests ---
+
+func TestGetDestinationAccommodation_Success(t *testing.T) {
+	destinationId := int64(10)
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2,
+		Accommodations: []*models.DestinationAccommodation{{ID: 4, DestinationID: &destinationId, Name: "Hotel Gran Vía"}}}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryDestinationParams)
+	getDestinationAccommodation(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Hotel Gran Vía"`)
+}
+
+func TestGetDestinationAccommodation_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryDestinationParams)
+	getDestinationAccommodation(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateDestinationAccommodation_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	accommodationService := &mockDestinationAccommodationService{}
+	defer setMockDestinationAccommodationService(accommodationService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"name":"Hotel Gran Vía","checkIn":"2024-07-01T15:00:00+02:00",
+		"checkOut":"2024-07-05T11:00:00+02:00","cost":480,"currency":"EUR"}`, itineraryDestinationParams)
+	updateDestinationAccommodation(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(10), accommodationService.DestinationId)
+	assert.Equal(t, "Hotel Gran Vía", accommodationService.Accommodation.Name)
+	assert.Equal(t, 480.0, *accommodationService.Accommodation.Cost)
+}
+
+func TestUpdateDestinationAccommodation_InvalidBody(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	for _, body := range []string{
+		`{"checkIn":"2024-07-01T15:00:00Z","checkOut":"2024-07-05T11:00:00Z"}`,
+		`{"name":"Hotel","checkIn":"2024-07-01T15:00:00Z"}`,
+		`{"name":"Hotel","checkIn":"2024-07-01T15:00:00Z","checkOut":"2024-07-05T11:00:00Z","cost":480}`,
+		`{"name":"Hotel","checkIn":"2024-07-01T15:00:00Z","checkOut":"2024-07-05T11:00:00Z","cost":-1,"currency":"EUR"}`,
+	} {
+		accommodationService := &mockDestinationAccommodationService{}
+		restore := setMockDestinationAccommodationService(accommodationService)
+
+		c, w := newAuthenticatedContext(http.MethodPut, body, itineraryDestinationParams)
+		updateDestinationAccommodation(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, accommodationService.Accommodation, body)
+	}
+}
+
+func TestUpdateDestinationAccommodation_InvalidDates(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockDestinationAccommodationService(&mockDestinationAccommodationService{
+		Err: errors.New("invalid accommodation: the check-in must be before the check-out")})()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"name":"Hotel","checkIn":"2024-07-05T15:00:00Z","checkOut":"2024-07-01T11:00:00Z"}`,
+		itineraryDestinationParams)
+	updateDestinationAccommodation(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the check-in must be before the check-out")
+}
+
+func TestDeleteDestinationAccommodation_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	accommodationService := &mockDestinationAccommodationService{Err: sql.ErrNoRows}
+	defer setMockDestinationAccommodationService(accommodationService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryDestinationParams)
+	deleteDestinationAccommodation(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.True(t, accommodationService.Deleted)
+}
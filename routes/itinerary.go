@@ -2,9 +2,12 @@ package routes
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,7 +17,9 @@ import (
 	"example.com/travel-advisor/requests"
 	"example.com/travel-advisor/responses"
 	"example.com/travel-advisor/services"
+	"example.com/travel-advisor/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // createItinerary godoc
@@ -76,22 +81,27 @@ func createItinerary(context *gin.Context) {
 	}
 
 	log.Debugf("Itinerary created successfully for user %d", userId)
+	setItineraryETag(context, itinerary)
 	context.JSON(http.StatusCreated, &responses.CreateItineraryResponse{Message: "Itinerary created.", ItineraryID: itinerary.ID})
 }
 
 // updateItinerary godoc
 // @Summary      Update an itinerary
-// @Description  Updates an existing itinerary for the authenticated user.
+// @Description  Updates an existing itinerary. The user must own the itinerary or be one of its editors. The If-Match header must have the ETag of the itinerary as it was retrieved, so changes made by someone else in the meantime are not overwritten.
 // @Tags         itineraries
 // @Accept       json
 // @Produce      json
 // @Security     Auth
+// @Param        If-Match   header  string  true  "ETag of the itinerary"
 // @Param        itinerary  body  requests.UpdateItineraryRequest  true  "Itinerary update data"
 // @Success      200  {object}  responses.UpdateItineraryResponse       "Itinerary updated."
+// @Header       200  {string}  ETag  "New ETag of the itinerary"
 // @Failure      400  {object}  responses.ErrorResponse       "Could not parse request data or invalid destinations."
 // @Failure      401  {object}  responses.ErrorResponse       "Not authorized."
 // @Failure      403  {object}  responses.ErrorResponse       "You do not have permission to update this itinerary."
 // @Failure      404  {object}  responses.ErrorResponse       "Itinerary not found."
+// @Failure      412  {object}  responses.ErrorResponse       "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      428  {object}  responses.ErrorResponse       "The If-Match header with the ETag of the itinerary is required."
 // @Failure      500  {object}  responses.ErrorResponse       "Could not update itinerary. Try again later."
 // @Router       /itineraries [put]
 func updateItinerary(context *gin.Context) {
@@ -130,12 +140,85 @@ func updateItinerary(context *gin.Context) {
 
 	itinerary = models.InitItineraryFunctions(itinerary)
 
-	if itinerary.OwnerID != userId.(int64) {
-		log.Errorf("User %d does not have permission to update itinerary %d", userId, input.ID)
-		context.JSON(http.StatusForbidden, &responses.ErrorResponse{Message: "You do not have permission to update this itinerary."})
+	if !checkItineraryPermission(context, itinerary, userId.(int64), models.ItineraryPermissionEditor) {
 		return
 	}
 
+	if !checkIfMatch(context, itinerary) {
+		return
+	}
+
+	if !saveItineraryUpdate(context, itinerary, &input, userId.(int64)) {
+		return
+	}
+
+	log.Debugf("Itinerary %d updated successfully for user %d", input.ID, userId)
+	context.JSON(http.StatusOK, &responses.UpdateItineraryResponse{Message: "Itinerary updated."})
+}
+
+// patchItinerary godoc
+// @Summary      Partially update an itinerary
+// @Description  Applies a JSON merge patch (RFC 7396) to an itinerary. Members of the patch replace the ones of the itinerary, null members remove them and omitted members are kept. The destinations are replaced as a whole when present, use the destination endpoints to change a single one. The patched itinerary is validated like a full update. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.
+// @Tags         itineraries
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path    int     true  "Itinerary ID"
+// @Param        If-Match     header  string  true  "ETag of the itinerary"
+// @Param        patch        body    requests.PatchItineraryRequest  true  "Merge patch of the itinerary"
+// @Success      200  {object}  responses.UpdateItineraryResponse  "Itinerary updated."
+// @Header       200  {string}  ETag  "New ETag of the itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      412  {object}  responses.ErrorResponse  "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      415  {object}  responses.ErrorResponse  "The request body must be a JSON merge patch."
+// @Failure      428  {object}  responses.ErrorResponse  "The If-Match header with the ETag of the itinerary is required."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update itinerary. Try again later."
+// @Router       /itineraries/{itineraryId} [patch]
+func patchItinerary(context *gin.Context) {
+	log.Debug("Patching itinerary")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	if !checkIfMatch(context, itinerary) {
+		return
+	}
+
+	document := &requests.UpdateItineraryRequest{ID: itinerary.ID, Title: itinerary.Title, Description: itinerary.Description, Notes: itinerary.Notes}
+	for _, destination := range itinerary.TravelDestinations {
+		document.Destinations = append(document.Destinations, newDestinationItem(destination))
+	}
+
+	var input requests.UpdateItineraryRequest
+	if !bindMergePatch(context, document, &input) {
+		return
+	}
+
+	if input.ID != itinerary.ID {
+		log.Errorf("Merge patch changes the ID of itinerary %d to %d", itinerary.ID, input.ID)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The itinerary ID cannot be changed."})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if !saveItineraryUpdate(context, models.InitItineraryFunctions(itinerary), &input, userId) {
+		return
+	}
+
+	log.Debugf("Itinerary %d patched successfully for user %d", itinerary.ID, userId)
+	context.JSON(http.StatusOK, &responses.UpdateItineraryResponse{Message: "Itinerary updated."})
+}
+
+// saveItineraryUpdate replaces the content of the itinerary with the one of the update request and saves it, setting its new ETag.
+// Sends an error response and returns false if the destinations are not valid or the itinerary cannot be saved
+func saveItineraryUpdate(context *gin.Context, itinerary *models.Itinerary, input *requests.UpdateItineraryRequest, userId int64) bool {
+	itineraryService := services.GetItineraryService()
+
 	itinerary.Title = input.Title
 	itinerary.Description = input.Description
 	itinerary.Notes = input.Notes
@@ -148,46 +231,57 @@ func updateItinerary(context *gin.Context) {
 
 	itinerary.TravelDestinations = itineraryTravelDestinations
 
-	err = itineraryService.ValidateItineraryDestinationsDates(itinerary.TravelDestinations)
+	err := itineraryService.ValidateItineraryDestinationsDates(itinerary.TravelDestinations)
 	if err != nil {
 		log.Errorf("Error validating itinerary destinations dates: %v", err)
 		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
-		return
+		return false
 	}
 
-	err = itineraryService.Update(itinerary)
+	err = itineraryService.Update(itinerary, userId)
 	if err != nil {
 		log.Errorf("Error updating itinerary %v", err)
-		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not update itinerary. Try again later."})
-		return
+		if strings.Contains(err.Error(), models.ErrItineraryVersionConflict.Error()) {
+			context.JSON(http.StatusPreconditionFailed, &responses.ErrorResponse{Message: itineraryChangedMessage})
+		} else {
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not update itinerary. Try again later."})
+		}
+		return false
 	}
 
-	log.Debugf("Itinerary %d updated successfully for user %d", input.ID, userId)
-	context.JSON(http.StatusOK, &responses.UpdateItineraryResponse{Message: "Itinerary updated."})
+	setItineraryETag(context, itinerary)
+	return true
 }
 
 // deleteItinerary godoc
 // @Summary      Delete an itinerary
-// @Description  Deletes an itinerary for the authenticated user.
+// @Description  Deletes an itinerary. Only the owner can delete it. The If-Match header must have the ETag of the itinerary as it was retrieved, so an itinerary changed by someone else in the meantime is not deleted.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
 // @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        If-Match  header  string  true  "ETag of the itinerary"
 // @Success      200  {object}  responses.DeleteItineraryResponse  "Itinerary deleted."
 // @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
 // @Failure      403  {object}  responses.ErrorResponse "You do not have permission to access this resource."
 // @Failure      404  {object}  responses.ErrorResponse "Itinerary not found."
 // @Failure      409  {object}  responses.ErrorResponse  "Itinerary has pending or running jobs. Please wait for them to complete or stop them before deleting the itinerary."
+// @Failure      412  {object}  responses.ErrorResponse  "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      428  {object}  responses.ErrorResponse  "The If-Match header with the ETag of the itinerary is required."
 // @Failure      500  {object}  responses.ErrorResponse  "Could not delete itinerary. Try again later."
 // @Router       /itineraries/{itineraryId} [delete]
 func deleteItinerary(context *gin.Context) {
 	log.Debug("Deleting itinerary")
 
-	itinerary := getAndValidateItinerary(context, false)
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionOwner)
 	if itinerary == nil {
 		return
 	}
 
+	if !checkIfMatch(context, itinerary) {
+		return
+	}
+
 	jobsService := services.GetItineraryFileJobService()
 
 	jobsRunningCount, err := jobsService.GetInProgressJobsOfItineraryCount(itinerary.ID)
@@ -205,10 +299,14 @@ func deleteItinerary(context *gin.Context) {
 
 	itineraryService := services.GetItineraryService()
 
-	err = itineraryService.Delete(itinerary.ID)
+	err = itineraryService.Delete(itinerary.ID, itinerary.Version, context.GetInt64("userId"))
 	if err != nil {
 		log.Errorf("Error deleting itinerary %d: %v", itinerary.ID, err)
-		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete itinerary. Try again later."})
+		if strings.Contains(err.Error(), models.ErrItineraryVersionConflict.Error()) {
+			context.JSON(http.StatusPreconditionFailed, &responses.ErrorResponse{Message: itineraryChangedMessage})
+		} else {
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete itinerary. Try again later."})
+		}
 		return
 	}
 
@@ -217,46 +315,106 @@ func deleteItinerary(context *gin.Context) {
 }
 
 // getOwnersItineraries godoc
-// @Summary      Get all itineraries of the authenticated user
-// @Description  Retrieves all itineraries belonging to the authenticated user.
+// @Summary      Get the itineraries of the authenticated user
+// @Description  Retrieves a page of the itineraries belonging to the authenticated user, optionally filtered by destination country and city (ignoring case), travel date range and text in the title. Itineraries are sorted by creation date, update date or travel date (the arrival at their first destination), from the newest by default. The response includes the total count of matching itineraries, and a Link header with the first and next pages. Use the nextCursor of a page, with the same filters and sorting, to get the next one.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
-// @Success      200  {object}  responses.GetItinerariesResponse  "List of itineraries"
+// @Param        country     query  string  false  "Destination country"
+// @Param        city        query  string  false  "Destination city"
+// @Param        travelFrom  query  string  false  "Start of the travel date range (RFC 3339)"  example(2024-07-01T00:00:00Z)
+// @Param        travelTo    query  string  false  "End of the travel date range (RFC 3339)"  example(2024-07-31T23:59:59Z)
+// @Param        title       query  string  false  "Text in the title"
+// @Param        sort        query  string  false  "Sort field"  Enums(creationDate, updateDate, travelDate)
+// @Param        order       query  string  false  "Sort order"  Enums(asc, desc)
+// @Param        cursor      query  string  false  "Cursor of the page to get"
+// @Param        limit       query  int     false  "Maximum number of itineraries per page (1-100, default 20)"
+// @Success      200  {object}  responses.GetItinerariesResponse  "Page of itineraries"
+// @Header       200  {string}  Link  "Links to the first and next pages"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid filters, sorting or cursor."
 // @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
 // @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve itineraries. Try again later."
 // @Router       /itineraries [get]
 func getOwnersItineraries(context *gin.Context) {
 	log.Debug("Retrieving owner's itineraries")
 
-	userId, exists := context.Get("userId")
-	if !exists {
-		log.Error("User ID not found in context")
-		context.JSON(http.StatusUnauthorized, &responses.ErrorResponse{Message: "Not authorized."})
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	var input requests.GetItinerariesRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing itineraries query: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data."})
 		return
 	}
 
 	itineraryService := services.GetItineraryService()
 
-	itineraries, err := itineraryService.FindByOwnerId(userId.(int64))
+	page, err := itineraryService.FindItineraries(services.ItinerariesQuery{
+		OwnerID:    *userId,
+		Country:    input.Country,
+		City:       input.City,
+		TravelFrom: input.TravelFrom,
+		TravelTo:   input.TravelTo,
+		Title:      input.Title,
+		Sort:       input.Sort,
+		Order:      input.Order,
+		Cursor:     input.Cursor,
+		Limit:      input.Limit,
+	})
 	if err != nil {
-		log.Errorf("Error retrieving itineraries for user %d: %v", userId, err)
-		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve itineraries. Try again later."})
+		log.Errorf("Error retrieving itineraries for user %d: %v", *userId, err)
+		switch {
+		case strings.Contains(err.Error(), "invalid sort field"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The sort field must be creationDate, updateDate or travelDate."})
+		case strings.Contains(err.Error(), "invalid sort order"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The sort order must be asc or desc."})
+		case strings.Contains(err.Error(), "invalid date range"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The start of the travel date range must be before its end."})
+		case strings.Contains(err.Error(), "invalid cursor"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid cursor."})
+		default:
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve itineraries. Try again later."})
+		}
 		return
 	}
 
-	log.Debugf("Retrieved itineraries for user %d: %d itineraries found", userId, len(itineraries))
-	context.JSON(http.StatusOK, &responses.GetItinerariesResponse{Itineraries: itineraries})
+	setPaginationLinkHeader(context, page.NextCursor)
+
+	log.Debugf("Retrieved itineraries for user %d: %d of %d itineraries", *userId, len(page.Itineraries), page.TotalCount)
+	context.JSON(http.StatusOK, &responses.GetItinerariesResponse{Itineraries: page.Itineraries, TotalCount: page.TotalCount, NextCursor: page.NextCursor})
+}
+
+// setPaginationLinkHeader sets the Link header (RFC 8288) of a cursor-paginated listing with the first page and, unless it is the
+// last page, the next one. The links keep the query parameters of the request
+func setPaginationLinkHeader(context *gin.Context, nextCursor string) {
+	pageUrl := *context.Request.URL
+	query := pageUrl.Query()
+
+	query.Del("cursor")
+	pageUrl.RawQuery = query.Encode()
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageUrl.String())}
+
+	if nextCursor != "" {
+		query.Set("cursor", nextCursor)
+		pageUrl.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageUrl.String()))
+	}
+
+	context.Header("Link", strings.Join(links, ", "))
 }
 
 // getItinerary godoc
 // @Summary      Get an itinerary by ID
-// @Description  Retrieves a specific itinerary for the authenticated user.
+// @Description  Retrieves an itinerary owned by or shared with the authenticated user. The ETag header identifies the version of the itinerary, to send in the If-Match header of updates and deletions.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
 // @Param        itineraryId  path  int  true  "Itinerary ID"
 // @Success      200  {object}  responses.GetItineraryResponse  "Itinerary details"
+// @Header       200  {string}  ETag  "Version of the itinerary"
 // @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
 // @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
 // @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
@@ -265,47 +423,92 @@ func getOwnersItineraries(context *gin.Context) {
 func getItinerary(context *gin.Context) {
 	log.Debug("Retrieving itinerary")
 
-	itinerary := getAndValidateItinerary(context, true)
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionViewer)
 	if itinerary == nil {
 		return
 	}
 
 	log.Debugf("Retrieved itinerary for user %d: %+v", itinerary.OwnerID, itinerary)
+	setItineraryETag(context, itinerary)
 	context.JSON(http.StatusOK, &responses.GetItineraryResponse{Itinerary: itinerary})
 }
 
 // runItineraryFileJob godoc
 // @Summary      Start itinerary file generation job
-// @Description  Starts an asynchronous job to generate a file for the specified itinerary. The user must be authenticated and the itinerary must belong to them.
+// @Description  Starts an asynchronous job to generate a file for the specified itinerary. The user must own the itinerary or be one of its editors. The file is generated with the latest version of the prompt template with the name, which is the own one of the user or else the global one, and the default template if no name is given. The file is written in the language, or in the one of the traveller preferences if not given, with dates and numbers formatted for its locale. With the plan source, the file is rendered from the day-by-day plan of the itinerary instead of being generated by the LLM. The job records the version, language and source used.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
-// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        itineraryId     path   int     true   "Itinerary ID"
+// @Param        promptTemplate  query  string  false  "Name of the prompt template"
+// @Param        language        query  string  false  "BCP 47 tag of the language of the file, like es or pt-BR"
+// @Param        source          query  string  false  "Source of the content of the file: llm (default) or plan"
 // @Success      202  {object}  responses.StartItineraryJobResponse  "Job started successfully."
+// @Failure      400  {object}  responses.ErrorResponse       "Invalid language or source, or the plan of the itinerary has no activities."
 // @Failure      401  {object}  responses.ErrorResponse       "Not authorized."
 // @Failure      403  {object}  responses.ErrorResponse       "You do not have permission to access this resource."
 // @Failure      404  {object}  responses.ErrorResponse       "Itinerary not found."
+// @Failure      404  {object}  responses.ErrorResponse       "Prompt template not found."
 // @Failure      409  {object}  responses.ErrorResponse       "Too many jobs running for your user. Please wait for existing jobs to complete."
 // @Failure      500  {object}  responses.ErrorResponse       "Could not create job. Try again later."
 // @Router       /itineraries/{itineraryId}/jobs [post]
 func runItineraryFileJob(context *gin.Context) {
 	log.Debug("Running itinerary file job")
 
-	itinerary := getAndValidateItinerary(context, true)
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
 	if itinerary == nil {
 		return
 	}
 
+	language := context.Query("language")
+	if language != "" {
+		if err := utils.ValidateLanguageTag(language); err != nil {
+			log.Errorf("Invalid itinerary file job language %s: %v", language, err)
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid language. It must be a BCP 47 tag like es."})
+			return
+		}
+	}
+
+	source := context.Query("source")
+	if source != "" && !slices.Contains(models.ItineraryFileJobSources, source) {
+		log.Errorf("Invalid itinerary file job source %s", source)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid source. It must be llm or plan."})
+		return
+	}
+
 	jobsService := services.GetItineraryFileJobService()
+	userId := context.GetInt64("userId")
+	if !checkJobsRunningLimit(context, jobsService, userId) {
+		return
+	}
 
-	// Check if there is already a job running for this user
-	userId, _ := context.Get("userId")
+	// Prepare and run the job
+	itineraryFileJobTask, err := jobsService.PrepareJob(itinerary, context.Query("promptTemplate"), language, source, userId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Prompt template not found."})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid plan: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+			return
+		}
+		log.Errorf("Error preparing itinerary file job: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create job. Try again later."})
+		return
+	}
 
-	jobsRunningCount, err := jobsService.GetInProgressJobsOfUserCount(userId.(int64))
+	enqueueItineraryFileJob(context, jobsService, itineraryFileJobTask, userId)
+}
+
+// checkJobsRunningLimit answers with an error if the user already has as many jobs in progress as the JOBS_RUNNING_PER_USER_LIMIT
+// environment variable allows
+func checkJobsRunningLimit(context *gin.Context, jobsService services.ItineraryFileJobServiceInterface, userId int64) bool {
+	jobsRunningCount, err := jobsService.GetInProgressJobsOfUserCount(userId)
 	if err != nil {
 		log.Errorf("Error checking running jobs for user %d: %v", userId, err)
 		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not check job status. Try again later."})
-		return
+		return false
 	}
 
 	jobsRunningLimitStr := os.Getenv("JOBS_RUNNING_PER_USER_LIMIT")
@@ -316,24 +519,21 @@ func runItineraryFileJob(context *gin.Context) {
 		if convErr != nil {
 			log.Errorf("Invalid JOBS_RUNNING_PER_USER_LIMIT environment variable: %v", convErr)
 			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Invalid jobs running limit configuration."})
-			return
+			return false
 		}
 	}
 
 	if jobsRunningCount >= jobsRunningLimit {
 		log.Errorf("User %d has too many jobs running: %d", userId, jobsRunningCount)
 		context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: "Too many jobs running for your user. Please wait for existing jobs to complete."})
-		return
+		return false
 	}
 
-	// Prepare and run the job
-	itineraryFileJobTask, err := jobsService.PrepareJob(itinerary)
-	if err != nil {
-		log.Errorf("Error preparing itinerary file job: %v", err)
-		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create job. Try again later."})
-		return
-	}
+	return true
+}
 
+// enqueueItineraryFileJob runs the prepared job in the async task queue, answering with its ID
+func enqueueItineraryFileJob(context *gin.Context, jobsService services.ItineraryFileJobServiceInterface, itineraryFileJobTask *services.ItineraryFileAsyncTaskPayload, userId int64) {
 	job := itineraryFileJobTask.ItineraryFileJob
 
 	asyncTaskQueue, err := services.NewAsyncqTaskQueue()
@@ -372,7 +572,7 @@ func runItineraryFileJob(context *gin.Context) {
 
 // getItineraryJob godoc
 // @Summary      Get an itinerary file job by ID
-// @Description  Retrieves a specific itinerary file job for the authenticated user.
+// @Description  Retrieves a specific itinerary file job. The itinerary must be owned by or shared with the authenticated user.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
@@ -388,7 +588,7 @@ func runItineraryFileJob(context *gin.Context) {
 func getItineraryJob(context *gin.Context) {
 	log.Debug("Retrieving itinerary job")
 
-	itinerary := getAndValidateItinerary(context, false)
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
 	if itinerary == nil {
 		return
 	}
@@ -435,7 +635,7 @@ func getItineraryJob(context *gin.Context) {
 
 // downloadItineraryJobFile godoc
 // @Summary      Download itinerary job file
-// @Description  Downloads the generated file for the specified itinerary job. The user must be authenticated and the itinerary must belong to them.
+// @Description  Downloads the generated file for the specified itinerary job. The itinerary must be owned by or shared with the authenticated user. The Content-Language header has the language of the file, if the job has one.
 // @Tags         itineraries
 // @Produce      application/octet-stream
 // @Security     Auth
@@ -451,7 +651,7 @@ func getItineraryJob(context *gin.Context) {
 func downloadItineraryJobFile(context *gin.Context) {
 	log.Debug("Downloading itinerary job file")
 
-	itinerary := getAndValidateItinerary(context, false)
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
 	if itinerary == nil {
 		return
 	}
@@ -497,7 +697,7 @@ func downloadItineraryJobFile(context *gin.Context) {
 		return
 	}
 
-	file, err := jobsService.OpenItineraryJobFile(itineraryJob)
+	file, err := jobsService.OpenItineraryJobFile(itineraryJob, context.GetInt64("userId"))
 	if err != nil {
 		log.Error("Error opening itinerary job file: ", err)
 		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not open itinerary job file. Try again later."})
@@ -505,34 +705,48 @@ func downloadItineraryJobFile(context *gin.Context) {
 	}
 	defer file.Close()
 
+	fileName := serveFile(context, file, itineraryJob.Language)
+	if fileName == "" {
+		return
+	}
+
+	log.Debugf("File %s served successfully for itinerary job ID %d", fileName, itineraryJobId)
+	// Note: The file will be served directly to the client, so no further action is needed here.
+
+}
+
+// serveFile streams a job file as an attachment labelled with its language, if any, and returns its name, or an empty string if an
+// error response was sent instead
+func serveFile(context *gin.Context, file io.ReadSeekCloser, language string) string {
 	// Assert file to *os.File to access Stat()
 	osFile, ok := file.(*os.File)
 	if !ok {
 		log.Error("File is not an *os.File, cannot get file info")
 		// TODO in the future, instead of returning an error, we could handle different file types (like a S3 file)
 		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Internal server error. Try again later."})
-		return
+		return ""
 	}
 
 	fileInfo, err := osFile.Stat()
 	if err != nil {
 		log.Error("Error getting file info: ", err)
 		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get file info. Try again later."})
-		return
+		return ""
 	}
 	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileInfo.Name()))
 	context.Header("Content-Type", "application/octet-stream")
+	if language != "" {
+		context.Header("Content-Language", language)
+	}
 	context.Header("Content-Length", strconv.FormatInt(fileInfo.Size(), 10))
 	http.ServeContent(context.Writer, context.Request, fileInfo.Name(), fileInfo.ModTime(), file)
 
-	log.Debugf("File %s served successfully for itinerary job ID %d", fileInfo.Name(), itineraryJobId)
-	// Note: The file will be served directly to the client, so no further action is needed here.
-
+	return fileInfo.Name()
 }
 
 // stopItineraryJob godoc
 // @Summary      Stop an itinerary file job
-// @Description  Stops an active itinerary file job for the authenticated user in case it gets stuck after the expected time. The user must own the itinerary.
+// @Description  Stops an active itinerary file job for the authenticated user in case it gets stuck after the expected time. The user must own the itinerary or be one of its editors.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
@@ -547,7 +761,7 @@ func downloadItineraryJobFile(context *gin.Context) {
 // @Router       /itineraries/{itineraryId}/jobs/{itineraryJobId}/stop [put]
 func stopItineraryJob(context *gin.Context) {
 	log.Debug("Stopping itinerary job")
-	itinerary := getAndValidateItinerary(context, false)
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
 	if itinerary == nil {
 		return
 	}
@@ -587,7 +801,7 @@ func stopItineraryJob(context *gin.Context) {
 		return
 	}
 
-	err = jobsService.StopJob(itineraryJob)
+	err = jobsService.StopJob(itineraryJob, context.GetInt64("userId"))
 	if err != nil {
 		log.Error("Error stopping itinerary job: ", err)
 		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: fmt.Sprintf("Could not stop job: %v", err)})
@@ -600,7 +814,7 @@ func stopItineraryJob(context *gin.Context) {
 
 // deleteItineraryJob godoc
 // @Summary      Delete an itinerary file job
-// @Description  Soft deletes an itinerary file job for the authenticated user. The user must own the itinerary.
+// @Description  Soft deletes an itinerary file job for the authenticated user. The user must own the itinerary or be one of its editors.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
@@ -617,7 +831,7 @@ func stopItineraryJob(context *gin.Context) {
 func deleteItineraryJob(context *gin.Context) {
 	log.Debug("Deleting itinerary job")
 
-	itinerary := getAndValidateItinerary(context, false)
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
 	if itinerary == nil {
 		return
 	}
@@ -666,7 +880,7 @@ func deleteItineraryJob(context *gin.Context) {
 	}
 
 	// We soft delete the job instead of hard deleting it to safely delete files later in a background task
-	err = jobsService.SoftDeleteJob(itineraryJob)
+	err = jobsService.SoftDeleteJob(itineraryJob, context.GetInt64("userId"))
 	if err != nil {
 		log.Error("Error deleting itinerary job: ", err)
 		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete job. Try again later."})
@@ -680,7 +894,7 @@ func deleteItineraryJob(context *gin.Context) {
 
 // getAllItineraryFileJobs godoc
 // @Summary      Get all file jobs for an itinerary
-// @Description  Retrieves all file jobs associated with the specified itinerary. The user must be authenticated and own the itinerary.
+// @Description  Retrieves all file jobs associated with the specified itinerary. The itinerary must be owned by or shared with the authenticated user.
 // @Tags         itineraries
 // @Produce      json
 // @Security     Auth
@@ -694,7 +908,7 @@ func deleteItineraryJob(context *gin.Context) {
 func getAllItineraryFileJobs(context *gin.Context) {
 	log.Debug("Retrieving all itinerary file jobs for an itinerary")
 
-	itinerary := getAndValidateItinerary(context, false)
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
 	if itinerary == nil {
 		return
 	}
@@ -723,7 +937,9 @@ func validateAuthenticatedUser(context *gin.Context) *int64 {
 	return &uid
 }
 
-func getAndValidateItinerary(context *gin.Context, fullItinerary bool) *models.Itinerary {
+// getAndValidateItinerary retrieves the itinerary of the path, sending an error response and returning nil unless the authenticated
+// user has at least the required permission on it
+func getAndValidateItinerary(context *gin.Context, fullItinerary bool, requiredPermission string) *models.Itinerary {
 	userId := validateAuthenticatedUser(context)
 	if userId == nil {
 		return nil
@@ -766,15 +982,126 @@ func getAndValidateItinerary(context *gin.Context, fullItinerary bool) *models.I
 		return nil
 	}
 
-	if itinerary.OwnerID != *userId {
-		log.Errorf("User %d does not have permission to access itinerary %d", *userId, itineraryId)
-		context.JSON(http.StatusForbidden, &responses.ErrorResponse{Message: "You do not have permission to access this resource."})
+	if !checkItineraryPermission(context, itinerary, *userId, requiredPermission) {
 		return nil
 	}
 
 	return itinerary
 }
 
+const itineraryChangedMessage = "The itinerary was changed since it was retrieved. Get it again and retry."
+
+// setItineraryETag sets the ETag header to the version of the itinerary, which clients send back in the If-Match header to change it
+func setItineraryETag(context *gin.Context, itinerary *models.Itinerary) {
+	context.Header("ETag", fmt.Sprintf(`"%d"`, itinerary.Version))
+}
+
+// checkIfMatch sends an error response and returns false unless the If-Match header has the ETag of the current version of the
+// itinerary, or * for any version. The version is checked again when the itinerary is written, in case it changes meanwhile
+func checkIfMatch(context *gin.Context, itinerary *models.Itinerary) bool {
+	ifMatch := strings.TrimSpace(context.GetHeader("If-Match"))
+	if ifMatch == "" {
+		log.Errorf("If-Match header missing for itinerary %d", itinerary.ID)
+		context.JSON(http.StatusPreconditionRequired, &responses.ErrorResponse{Message: "The If-Match header with the ETag of the itinerary is required."})
+		return false
+	}
+	if ifMatch == "*" {
+		return true
+	}
+
+	// Weak ETags never match, since If-Match uses the strong comparison
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == fmt.Sprintf(`"%d"`, itinerary.Version) {
+			return true
+		}
+	}
+
+	log.Errorf("If-Match %s does not match version %d of itinerary %d", ifMatch, itinerary.Version, itinerary.ID)
+	context.JSON(http.StatusPreconditionFailed, &responses.ErrorResponse{Message: itineraryChangedMessage})
+	return false
+}
+
+// bindMergePatch applies the JSON merge patch (RFC 7396) of the request body to the document and binds the result to the input,
+// validating it like a JSON body. Sends an error response and returns false if the body is not a valid merge patch or the result is
+// not valid
+func bindMergePatch(context *gin.Context, document any, input any) bool {
+	contentType := context.ContentType()
+	if contentType != "application/merge-patch+json" && contentType != binding.MIMEJSON {
+		log.Errorf("Unsupported merge patch content type %s", contentType)
+		context.JSON(http.StatusUnsupportedMediaType, &responses.ErrorResponse{Message: "The request body must be a JSON merge patch."})
+		return false
+	}
+
+	patch, err := context.GetRawData()
+	if err != nil {
+		log.Errorf("Error reading merge patch: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not read request data."})
+		return false
+	}
+
+	documentJson, err := json.Marshal(document)
+	if err != nil {
+		log.Errorf("Error marshalling merge patch document: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not apply the merge patch. Try again later."})
+		return false
+	}
+
+	patched, err := utils.ApplyMergePatch(documentJson, patch)
+	if err == nil {
+		err = json.Unmarshal(patched, input)
+	}
+	if err == nil {
+		err = binding.Validator.ValidateStruct(input)
+	}
+	if err != nil {
+		log.Errorf("Error applying merge patch: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The patch is not valid JSON, or one or more mandatory attributes are null/empty or at least one of the attributes is too large."})
+		return false
+	}
+
+	return true
+}
+
+// checkItineraryPermission sends an error response and returns false unless the user has at least the required permission on the itinerary
+func checkItineraryPermission(context *gin.Context, itinerary *models.Itinerary, userId int64, requiredPermission string) bool {
+	err := services.GetPermissionService().CheckItineraryPermission(itinerary, userId, requiredPermission)
+	if err != nil {
+		if strings.Contains(err.Error(), "permission denied") {
+			log.Errorf("User %d does not have permission to access itinerary %d", userId, itinerary.ID)
+			context.JSON(http.StatusForbidden, &responses.ErrorResponse{Message: "You do not have permission to access this resource."})
+		} else {
+			log.Errorf("Error checking permission of user %d on itinerary %d: %v", userId, itinerary.ID, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not check permissions. Try again later."})
+		}
+		return false
+	}
+
+	return true
+}
+
+// getAndValidateItineraryJob retrieves the job of the itineraryJobId path parameter, answering with an error if it is not found or is
+// not a job of the itinerary
+func getAndValidateItineraryJob(context *gin.Context, jobsService services.ItineraryFileJobServiceInterface, itinerary *models.Itinerary) *models.ItineraryFileJob {
+	itineraryJobId := getPathId(context, "itineraryJobId", "itinerary job")
+	if itineraryJobId == nil {
+		return nil
+	}
+
+	itineraryJob, err := jobsService.FindAliveById(*itineraryJobId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			log.Error("Itinerary job not found: ", err)
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Itinerary job not found."})
+		} else {
+			log.Error("Error retrieving itinerary job: ", err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get itinerary job. Try again later."})
+		}
+		return nil
+	}
+
+	return validateItineraryJobOwnership(itinerary.ID, itineraryJob, context)
+}
+
 func validateItineraryJobOwnership(itineraryId int64, itineraryFileJob *models.ItineraryFileJob, context *gin.Context) *models.ItineraryFileJob {
 	if itineraryId != itineraryFileJob.ItineraryID {
 		log.Errorf("Itinerary ID %d does not match job's itinerary ID %d", itineraryId, itineraryFileJob.ItineraryID)
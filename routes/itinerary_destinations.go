@@ -0,0 +1,203 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// addItineraryDestination godoc
+// @Summary      Add a destination to an itinerary
+// @Description  Adds a destination to an itinerary, keeping the other ones. The destinations of the itinerary are validated again with the new one. The change is saved as a new revision. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path    int     true  "Itinerary ID"
+// @Param        If-Match     header  string  true  "ETag of the itinerary"
+// @Param        destination  body    requests.DestinationItem  true  "Destination"
+// @Success      201  {object}  responses.AddItineraryDestinationResponse  "Destination added."
+// @Header       201  {string}  ETag  "New ETag of the itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      412  {object}  responses.ErrorResponse  "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      428  {object}  responses.ErrorResponse  "The If-Match header with the ETag of the itinerary is required."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not add destination. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations [post]
+func addItineraryDestination(context *gin.Context) {
+	log.Debug("Adding itinerary destination")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	if !checkIfMatch(context, itinerary) {
+		return
+	}
+
+	var input requests.DestinationItem
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. One or more mandatory attributes are null/empty or at least one of the expected attributes is too large."})
+		return
+	}
+
+	destination := models.NewItineraryTravelDestination(input.Country, input.City, input.ArrivalDate, input.DepartureDate)
+	err := services.GetItineraryDestinationService().Add(itinerary, destination, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error adding destination to itinerary %d: %v", itinerary.ID, err)
+		handleItineraryDestinationError(context, err, "Could not add destination. Try again later.")
+		return
+	}
+
+	log.Debugf("Destination %d added to itinerary %d", destination.ID, itinerary.ID)
+	setItineraryETag(context, itinerary)
+	context.JSON(http.StatusCreated, &responses.AddItineraryDestinationResponse{Message: "Destination added.", Destination: destination})
+}
+
+// patchItineraryDestination godoc
+// @Summary      Partially update a destination of an itinerary
+// @Description  Applies a JSON merge patch (RFC 7396) to a destination of an itinerary. Members of the patch replace the ones of the destination and omitted members are kept. The destinations of the itinerary are validated again with the patched one. The change is saved as a new revision. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.
+// @Tags         itineraries
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path    int     true  "Itinerary ID"
+// @Param        destinationId  path    int     true  "Destination ID"
+// @Param        If-Match       header  string  true  "ETag of the itinerary"
+// @Param        patch          body    requests.PatchDestinationRequest  true  "Merge patch of the destination"
+// @Success      200  {object}  responses.UpdateItineraryDestinationResponse  "Destination updated."
+// @Header       200  {string}  ETag  "New ETag of the itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or destination not found."
+// @Failure      412  {object}  responses.ErrorResponse  "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      415  {object}  responses.ErrorResponse  "The request body must be a JSON merge patch."
+// @Failure      428  {object}  responses.ErrorResponse  "The If-Match header with the ETag of the itinerary is required."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update destination. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId} [patch]
+func patchItineraryDestination(context *gin.Context) {
+	log.Debug("Patching itinerary destination")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	var current *models.ItineraryTravelDestination
+	for _, destination := range itinerary.TravelDestinations {
+		if destination.ID == *destinationId {
+			current = destination
+		}
+	}
+	if current == nil {
+		log.Errorf("Destination %d not found in itinerary %d", *destinationId, itinerary.ID)
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Destination not found."})
+		return
+	}
+
+	if !checkIfMatch(context, itinerary) {
+		return
+	}
+
+	var input requests.DestinationItem
+	if !bindMergePatch(context, newDestinationItem(current), &input) {
+		return
+	}
+
+	destination := models.NewItineraryTravelDestination(input.Country, input.City, input.ArrivalDate, input.DepartureDate)
+	destination.ID = current.ID
+	destination.CreationDate = current.CreationDate
+	err := services.GetItineraryDestinationService().Update(itinerary, destination, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating destination %d of itinerary %d: %v", *destinationId, itinerary.ID, err)
+		handleItineraryDestinationError(context, err, "Could not update destination. Try again later.")
+		return
+	}
+
+	log.Debugf("Destination %d of itinerary %d updated", *destinationId, itinerary.ID)
+	setItineraryETag(context, itinerary)
+	context.JSON(http.StatusOK, &responses.UpdateItineraryDestinationResponse{Message: "Destination updated.", Destination: destination})
+}
+
+// deleteItineraryDestination godoc
+// @Summary      Delete a destination of an itinerary
+// @Description  Removes a destination from an itinerary, which must keep at least one destination. The change is saved as a new revision. The user must own the itinerary or be one of its editors, and the If-Match header must have the ETag of the itinerary as it was retrieved.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId    path    int     true  "Itinerary ID"
+// @Param        destinationId  path    int     true  "Destination ID"
+// @Param        If-Match       header  string  true  "ETag of the itinerary"
+// @Success      200  {object}  responses.DeleteItineraryDestinationResponse  "Destination deleted."
+// @Header       200  {string}  ETag  "New ETag of the itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid destination ID or the itinerary would have no destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or destination not found."
+// @Failure      412  {object}  responses.ErrorResponse  "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      428  {object}  responses.ErrorResponse  "The If-Match header with the ETag of the itinerary is required."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not delete destination. Try again later."
+// @Router       /itineraries/{itineraryId}/destinations/{destinationId} [delete]
+func deleteItineraryDestination(context *gin.Context) {
+	log.Debug("Deleting itinerary destination")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	destinationId := getPathId(context, "destinationId", "destination")
+	if destinationId == nil {
+		return
+	}
+
+	if !checkIfMatch(context, itinerary) {
+		return
+	}
+
+	err := services.GetItineraryDestinationService().Delete(itinerary, *destinationId, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting destination %d of itinerary %d: %v", *destinationId, itinerary.ID, err)
+		handleItineraryDestinationError(context, err, "Could not delete destination. Try again later.")
+		return
+	}
+
+	log.Debugf("Destination %d of itinerary %d deleted", *destinationId, itinerary.ID)
+	setItineraryETag(context, itinerary)
+	context.JSON(http.StatusOK, &responses.DeleteItineraryDestinationResponse{Message: "Destination deleted."})
+}
+
+func newDestinationItem(destination *models.ItineraryTravelDestination) *requests.DestinationItem {
+	return &requests.DestinationItem{Country: destination.Country, City: destination.City, ArrivalDate: destination.ArrivalDate,
+		DepartureDate: destination.DepartureDate}
+}
+
+func handleItineraryDestinationError(context *gin.Context, err error, internalErrorMessage string) {
+	switch {
+	case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Destination not found."})
+	case strings.HasPrefix(err.Error(), "invalid destinations: "):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: strings.TrimPrefix(err.Error(), "invalid destinations: ")})
+	case strings.Contains(err.Error(), models.ErrItineraryVersionConflict.Error()):
+		context.JSON(http.StatusPreconditionFailed, &responses.ErrorResponse{Message: itineraryChangedMessage})
+	default:
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: internalErrorMessage})
+	}
+}
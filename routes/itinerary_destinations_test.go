@@ -0,0 +1,193 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockItineraryDestinationService struct {
+	Err           error
+	Destination   *models.ItineraryTravelDestination
+	DestinationId int64
+	ActorId       int64
+}
+
+func (m *mockItineraryDestinationService) Add(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error {
+	return m.change(itinerary, destination, actorId)
+}
+func (m *mockItineraryDestinationService) Update(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error {
+	return m.change(itinerary, destination, actorId)
+}
+func (m *mockItineraryDestinationService) Delete(itinerary *models.Itinerary, destinationId int64, actorId int64) error {
+	m.DestinationId = destinationId
+	return m.change(itinerary, nil, actorId)
+}
+func (m *mockItineraryDestinationService) change(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error {
+	m.Destination = destination
+	m.ActorId = actorId
+	if m.Err == nil {
+		itinerary.Version++
+	}
+	return m.Err
+}
+
+func setMockItineraryDestinationService(mock *mockItineraryDestinationService) func() {
+	orig := services.GetItineraryDestinationService
+	services.GetItineraryDestinationService = func() services.ItineraryDestinationServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryDestinationService = orig }
+}
+
+var itineraryDestinationParams = gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "destinationId", Value: "10"}}
+
+// --- Tests ---
+
+func TestAddItineraryDestination_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	destinationService := &mockItineraryDestinationService{}
+	defer setMockItineraryDestinationService(destinationService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost,
+		`{"country":"Spain","city":"Seville","arrivalDate":"2024-07-05T00:00:00Z","departureDate":"2024-07-08T00:00:00Z"}`, itineraryIdParams)
+	c.Request.Header.Set("If-Match", `"2"`)
+	addItineraryDestination(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Equal(t, "Seville", destinationService.Destination.City)
+	assert.Equal(t, int64(1), destinationService.ActorId)
+}
+
+func TestAddItineraryDestination_InvalidDestinations(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	defer setMockItineraryDestinationService(&mockItineraryDestinationService{
+		Err: errInvalidDestinations("the itinerary cannot span more than 30 days")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost,
+		`{"country":"Spain","city":"Seville","arrivalDate":"2024-09-05T00:00:00Z","departureDate":"2024-09-08T00:00:00Z"}`, itineraryIdParams)
+	c.Request.Header.Set("If-Match", `"2"`)
+	addItineraryDestination(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"the itinerary cannot span more than 30 days"`)
+}
+
+func TestAddItineraryDestination_MissingIfMatch(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	destinationService := &mockItineraryDestinationService{}
+	defer setMockItineraryDestinationService(destinationService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{}`, itineraryIdParams)
+	addItineraryDestination(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Nil(t, destinationService.Destination)
+}
+
+func TestPatchItineraryDestination_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	destinationService := &mockItineraryDestinationService{}
+	defer setMockItineraryDestinationService(destinationService)()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"city":"Toledo"}`, itineraryDestinationParams)
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Request.Header.Set("If-Match", `"2"`)
+	patchItineraryDestination(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(10), destinationService.Destination.ID)
+	assert.Equal(t, "Spain", destinationService.Destination.Country)
+	assert.Equal(t, "Toledo", destinationService.Destination.City)
+	assert.Equal(t, 1, destinationService.Destination.ArrivalDate.Day())
+}
+
+func TestPatchItineraryDestination_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	defer setMockItineraryDestinationService(&mockItineraryDestinationService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"city":"Toledo"}`, gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "destinationId", Value: "99"}})
+	c.Request.Header.Set("If-Match", `"2"`)
+	patchItineraryDestination(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPatchItineraryDestination_RemovedMandatoryMember(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	defer setMockItineraryDestinationService(&mockItineraryDestinationService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"city":null}`, itineraryDestinationParams)
+	c.Request.Header.Set("If-Match", `"2"`)
+	patchItineraryDestination(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteItineraryDestination_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+	destinationService := &mockItineraryDestinationService{}
+	defer setMockItineraryDestinationService(destinationService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryDestinationParams)
+	c.Request.Header.Set("If-Match", `"2"`)
+	deleteItineraryDestination(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Equal(t, int64(10), destinationService.DestinationId)
+}
+
+func TestDeleteItineraryDestination_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{sql.ErrNoRows, http.StatusNotFound},
+		{errInvalidDestinations("at least one destination is required"), http.StatusBadRequest},
+		{models.ErrItineraryVersionConflict, http.StatusPreconditionFailed},
+	}
+
+	for _, test := range tests {
+		restoreItineraryService := setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})
+		restoreDestinationService := setMockItineraryDestinationService(&mockItineraryDestinationService{Err: test.err})
+
+		c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryDestinationParams)
+		c.Request.Header.Set("If-Match", `"2"`)
+		deleteItineraryDestination(c)
+		restoreDestinationService()
+		restoreItineraryService()
+
+		assert.Equal(t, test.status, w.Code, test.err.Error())
+	}
+}
+
+func TestDeleteItineraryDestination_Viewer(t *testing.T) {
+	itinerary := newPatchItinerary()
+	itinerary.OwnerID = 2
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: itinerary})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	destinationService := &mockItineraryDestinationService{}
+	defer setMockItineraryDestinationService(destinationService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryDestinationParams)
+	c.Request.Header.Set("If-Match", `"2"`)
+	deleteItineraryDestination(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Zero(t, destinationService.DestinationId)
+}
+
+func errInvalidDestinations(message string) error {
+	return fmt.Errorf("invalid destinations: %w", errors.New(message))
+}
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// regenerateItineraryJob godoc
+// @Summary      Regenerate a part of the file of an itinerary job
+// @Description  Starts a new job that rewrites only the part of the file of a completed job about a range of dates or the stay in a destination, following the instructions, if any. The rest of the file is kept, and the base job and its file stay available for comparison. The new job has the ID of the base job and the dates of the part it rewrites. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int                            true  "Itinerary ID"
+// @Param        itineraryJobId  path  int                            true  "ID of the base itinerary job"
+// @Param        scope           body  requests.RegenerateJobRequest  true  "Dates or destination to regenerate"
+// @Success      202  {object}  responses.StartItineraryJobResponse  "Job started successfully."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data, invalid scope or the base job is not completed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary, itinerary job or destination not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Too many jobs running for your user. Please wait for existing jobs to complete."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create job. Try again later."
+// @Router       /itineraries/{itineraryId}/jobs/{itineraryJobId}/regenerate [post]
+func regenerateItineraryJob(context *gin.Context) {
+	log.Debug("Regenerating itinerary job")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.RegenerateJobRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. Either the start and end dates as YYYY-MM-DD or a destination ID are required."})
+		return
+	}
+
+	// The format of the dates was checked by the binding
+	scope := &services.RegenerationScope{DestinationID: input.DestinationID}
+	if input.StartDate != "" {
+		startDate, _ := time.Parse(time.DateOnly, input.StartDate)
+		scope.StartDate = &startDate
+	}
+	if input.EndDate != "" {
+		endDate, _ := time.Parse(time.DateOnly, input.EndDate)
+		scope.EndDate = &endDate
+	}
+
+	jobsService := services.GetItineraryFileJobService()
+	baseJob := getAndValidateItineraryJob(context, jobsService, itinerary)
+	if baseJob == nil {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if !checkJobsRunningLimit(context, jobsService, userId) {
+		return
+	}
+
+	itineraryFileJobTask, err := jobsService.RegenerateJob(itinerary, baseJob, scope, input.Instructions, userId)
+	if err != nil {
+		log.Errorf("Error preparing regeneration of itinerary job %d: %v", baseJob.ID, err)
+		switch {
+		case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Destination not found."})
+		case strings.HasPrefix(err.Error(), "invalid scope: "), strings.HasPrefix(err.Error(), "invalid base job: "):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+		default:
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create job. Try again later."})
+		}
+		return
+	}
+
+	enqueueItineraryFileJob(context, jobsService, itineraryFileJobTask, userId)
+}
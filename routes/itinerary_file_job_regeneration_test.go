@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var itineraryJobParams = gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "itineraryJobId", Value: "4"}}
+
+func TestRegenerateItineraryJob_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	baseJob := &models.ItineraryFileJob{ID: 4, ItineraryID: 1, Status: "completed"}
+	jobsService := &mockJobsService{
+		FindAliveByIdResult: baseJob,
+		PrepareJobTask: &services.ItineraryFileAsyncTaskPayload{
+			Itinerary:        &models.Itinerary{ID: 1, OwnerID: 2},
+			ItineraryFileJob: &models.ItineraryFileJob{ID: 5},
+		},
+	}
+	defer setMockJobsService(jobsService)()
+	defer setMockAsyncqTaskQueue(&mockAsyncqTaskQueue{EnqueueId: "taskid"}, nil)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"startDate":"2024-07-02","endDate":"2024-07-03","instructions":"Less museums"}`,
+		itineraryJobParams)
+	regenerateItineraryJob(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"jobId":5`)
+	assert.Equal(t, baseJob, jobsService.RegeneratedBaseJob)
+	assert.Equal(t, time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC), *jobsService.RegeneratedScope.StartDate)
+	assert.Equal(t, time.Date(2024, 7, 3, 0, 0, 0, 0, time.UTC), *jobsService.RegeneratedScope.EndDate)
+	assert.Nil(t, jobsService.RegeneratedScope.DestinationID)
+	assert.Equal(t, "Less museums", jobsService.RegeneratedInstructions)
+}
+
+func TestRegenerateItineraryJob_InvalidBody(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	for _, body := range []string{`{"startDate":"02/07/2024","endDate":"2024-07-03"}`, `{"destinationId":0}`} {
+		jobsService := &mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1}}
+		restore := setMockJobsService(jobsService)
+
+		c, w := newAuthenticatedContext(http.MethodPost, body, itineraryJobParams)
+		regenerateItineraryJob(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, jobsService.RegeneratedScope, body)
+	}
+}
+
+func TestRegenerateItineraryJob_JobOfAnotherItinerary(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	jobsService := &mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 2}}
+	defer setMockJobsService(jobsService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"destinationId":10}`, itineraryJobParams)
+	regenerateItineraryJob(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, jobsService.RegeneratedScope)
+}
+
+func TestRegenerateItineraryJob_InvalidScope(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1},
+		PrepareJobErr: errors.New("invalid scope: the dates must be between 2024-07-01 and 2024-07-08")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"startDate":"2024-08-01","endDate":"2024-08-02"}`, itineraryJobParams)
+	regenerateItineraryJob(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the dates must be between 2024-07-01 and 2024-07-08")
+}
+
+func TestRegenerateItineraryJob_DestinationNotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1},
+		PrepareJobErr: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"destinationId":99}`, itineraryJobParams)
+	regenerateItineraryJob(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Destination not found.")
+}
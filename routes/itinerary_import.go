@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize is the largest file that can be imported, far more than the 20 destinations of an itinerary need
+const maxImportSize = 1 << 20
+
+// importFormatsByContentType maps the media types of the imported files to their format
+var importFormatsByContentType = map[string]string{
+	"text/calendar":    models.ItineraryImportFormatICS,
+	"text/csv":         models.ItineraryImportFormatCSV,
+	"application/json": models.ItineraryImportFormatJSON,
+}
+
+// importItinerary godoc
+// @Summary      Import an itinerary
+// @Description  Reads the destinations of an iCalendar file (text/calendar), a CSV file with city, country, arrival and departure columns (text/csv) or a JSON document of the interchange format (application/json), sent as the request body. The format is taken from the Content-Type header unless the format parameter is given. The destinations are validated like the ones of a new itinerary and returned as a preview, with the errors of each row and of the itinerary as a whole. The itinerary is only created when commit is true and the file has no errors.
+// @Tags         itineraries
+// @Accept       json,text/csv,text/calendar
+// @Produce      json
+// @Security     Auth
+// @Param        format  query  string  false  "Format of the file, instead of the one of the Content-Type header"  Enums(ics, csv, json)
+// @Param        title   query  string  false  "Title of the itinerary, instead of the one of the file"
+// @Param        commit  query  bool    false  "Create the itinerary if the file has no errors"
+// @Param        file    body   models.ItineraryInterchangeDocument  true  "File to import. Any of the accepted formats, the JSON interchange format is shown"
+// @Success      200  {object}  responses.ImportItineraryResponse  "Preview of the import."
+// @Success      201  {object}  responses.ImportItineraryResponse  "Itinerary imported."
+// @Header       201  {string}  ETag  "ETag of the new itinerary"
+// @Failure      400  {object}  responses.ImportItineraryResponse  "The file cannot be read or has errors."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      413  {object}  responses.ErrorResponse  "The file cannot be larger than 1 MiB."
+// @Failure      415  {object}  responses.ErrorResponse  "The file must be an iCalendar, CSV or JSON file."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not import itinerary. Try again later."
+// @Router       /itineraries/import [post]
+func importItinerary(context *gin.Context) {
+	log.Debug("Importing itinerary")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	var input requests.ImportItineraryRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing query parameters %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The format must be ics, csv or json and the title cannot be too large."})
+		return
+	}
+
+	format := input.Format
+	if format == "" {
+		format = importFormatsByContentType[context.ContentType()]
+	}
+	if format == "" {
+		log.Errorf("Unsupported import content type %s", context.ContentType())
+		context.JSON(http.StatusUnsupportedMediaType, &responses.ErrorResponse{Message: "The file must be an iCalendar, CSV or JSON file."})
+		return
+	}
+
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, maxImportSize)
+	data, err := context.GetRawData()
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			context.JSON(http.StatusRequestEntityTooLarge, &responses.ErrorResponse{Message: "The file cannot be larger than 1 MiB."})
+			return
+		}
+		log.Errorf("Error reading imported file: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not read request data."})
+		return
+	}
+
+	importService := services.GetItineraryImportService()
+	itineraryImport, err := importService.Preview(format, data, input.Title)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid import: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: strings.TrimPrefix(err.Error(), "invalid import: ")})
+			return
+		}
+		log.Errorf("Error previewing %s import: %v", format, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not import itinerary. Try again later."})
+		return
+	}
+
+	if !input.Commit {
+		message := "The file can be imported."
+		if !itineraryImport.Valid {
+			message = "The file has errors."
+		}
+		context.JSON(http.StatusOK, &responses.ImportItineraryResponse{Message: message, Import: itineraryImport})
+		return
+	}
+
+	if !itineraryImport.Valid {
+		context.JSON(http.StatusBadRequest, &responses.ImportItineraryResponse{Message: "The file has errors. Fix them and import it again.", Import: itineraryImport})
+		return
+	}
+
+	itinerary, err := importService.Create(itineraryImport, *userId)
+	if err != nil {
+		log.Errorf("Error creating itinerary from %s import: %v", format, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not import itinerary. Try again later."})
+		return
+	}
+
+	log.Debugf("Itinerary %d imported from a %s file for user %d", itinerary.ID, format, *userId)
+	setItineraryETag(context, itinerary)
+	context.JSON(http.StatusCreated, &responses.ImportItineraryResponse{Message: "Itinerary imported.", ItineraryID: itinerary.ID, Import: itineraryImport})
+}
@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockItineraryImportService struct {
+	Import     *models.ItineraryImport
+	PreviewErr error
+	CreateErr  error
+	Itinerary  *models.Itinerary
+	Format     string
+	Data       string
+	Title      string
+	Created    bool
+}
+
+func (m *mockItineraryImportService) Preview(format string, data []byte, title string) (*models.ItineraryImport, error) {
+	m.Format = format
+	m.Data = string(data)
+	m.Title = title
+	return m.Import, m.PreviewErr
+}
+func (m *mockItineraryImportService) Create(_ *models.ItineraryImport, _ int64) (*models.Itinerary, error) {
+	m.Created = true
+	return m.Itinerary, m.CreateErr
+}
+
+func setMockItineraryImportService(mock *mockItineraryImportService) func() {
+	orig := services.GetItineraryImportService
+	services.GetItineraryImportService = func() services.ItineraryImportServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryImportService = orig }
+}
+
+func newImportContext(contentType string, query string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := newAuthenticatedContext(http.MethodPost, body, nil)
+	c.Request.Header.Set("Content-Type", contentType)
+	c.Request.URL.RawQuery = query
+	return c, w
+}
+
+// --- Tests ---
+
+func TestImportItinerary_Preview(t *testing.T) {
+	importService := &mockItineraryImportService{Import: &models.ItineraryImport{Format: "csv", Title: "Spain", Valid: false,
+		Rows: []*models.ItineraryImportRow{{Row: 2, Country: "Spain", Errors: []string{"the city is required"}}}}}
+	defer setMockItineraryImportService(importService)()
+
+	c, w := newImportContext("text/csv; charset=utf-8", "title=Spain", "city,country,arrival,departure\n,Spain,2024-07-01,2024-07-05\n")
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ItineraryImportFormatCSV, importService.Format)
+	assert.Equal(t, "Spain", importService.Title)
+	assert.True(t, strings.HasPrefix(importService.Data, "city,country"))
+	assert.False(t, importService.Created)
+	assert.Contains(t, w.Body.String(), `"message":"The file has errors."`)
+	assert.Contains(t, w.Body.String(), "the city is required")
+}
+
+func TestImportItinerary_FormatParameter(t *testing.T) {
+	importService := &mockItineraryImportService{Import: &models.ItineraryImport{Format: "ics", Valid: true}}
+	defer setMockItineraryImportService(importService)()
+
+	c, w := newImportContext("application/octet-stream", "format=ics", "BEGIN:VCALENDAR")
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ItineraryImportFormatICS, importService.Format)
+	assert.Contains(t, w.Body.String(), "The file can be imported.")
+}
+
+func TestImportItinerary_UnsupportedContentType(t *testing.T) {
+	importService := &mockItineraryImportService{}
+	defer setMockItineraryImportService(importService)()
+
+	c, w := newImportContext("application/pdf", "", "%PDF")
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Empty(t, importService.Format)
+}
+
+func TestImportItinerary_TooLarge(t *testing.T) {
+	importService := &mockItineraryImportService{}
+	defer setMockItineraryImportService(importService)()
+
+	c, w := newImportContext("text/csv", "", strings.Repeat("a", maxImportSize+1))
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Empty(t, importService.Format)
+}
+
+func TestImportItinerary_Unreadable(t *testing.T) {
+	defer setMockItineraryImportService(&mockItineraryImportService{PreviewErr: errors.New("invalid import: the file is not an iCalendar file")})()
+
+	c, w := newImportContext("text/calendar", "", "not a calendar")
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"the file is not an iCalendar file"`)
+}
+
+func TestImportItinerary_CommitWithErrors(t *testing.T) {
+	importService := &mockItineraryImportService{Import: &models.ItineraryImport{Format: "json", Valid: false,
+		Errors: []string{"the itinerary cannot span more than 30 days"}}}
+	defer setMockItineraryImportService(importService)()
+
+	c, w := newImportContext("application/json", "commit=true", `{"destinations":[]}`)
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, importService.Created)
+	assert.Contains(t, w.Body.String(), "the itinerary cannot span more than 30 days")
+}
+
+func TestImportItinerary_Commit(t *testing.T) {
+	importService := &mockItineraryImportService{Import: &models.ItineraryImport{Format: "json", Valid: true},
+		Itinerary: &models.Itinerary{ID: 9, OwnerID: 1, Version: 1}}
+	defer setMockItineraryImportService(importService)()
+
+	c, w := newImportContext("application/json", "commit=true", `{"destinations":[]}`)
+	importItinerary(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.True(t, importService.Created)
+	assert.Contains(t, w.Body.String(), `"itineraryId":9`)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+}
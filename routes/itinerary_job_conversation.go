@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getItineraryJobMessages godoc
+// @Summary      Get the conversation about the file of an itinerary job
+// @Description  Gets the messages of the users and the replies of the assistant about the file of a job, oldest first. Each message of a user has the ID of the job with the new version of the file that answers it, which its reply has too. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int  true  "Itinerary ID"
+// @Param        itineraryJobId  path  int  true  "Itinerary Job ID"
+// @Success      200  {object}  responses.GetItineraryJobMessagesResponse  "Conversation"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid itinerary job ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or itinerary job not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get messages. Try again later."
+// @Router       /itineraries/{itineraryId}/jobs/{itineraryJobId}/messages [get]
+func getItineraryJobMessages(context *gin.Context) {
+	log.Debug("Retrieving itinerary job messages")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	jobsService := services.GetItineraryFileJobService()
+	itineraryJob := getAndValidateItineraryJob(context, jobsService, itinerary)
+	if itineraryJob == nil {
+		return
+	}
+
+	messages, err := jobsService.FindMessages(itineraryJob)
+	if err != nil {
+		log.Errorf("Error retrieving messages of itinerary job %d: %v", itineraryJob.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get messages. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryJobMessagesResponse{Messages: messages})
+}
+
+// sendItineraryJobMessage godoc
+// @Summary      Send a message about the file of an itinerary job
+// @Description  Adds a message to the conversation about the file of a completed job, like "make day 3 more relaxed", and starts a new job that answers it with a new version of the file. The LLM gets the latest completed version of the conversation, or the file of the job if there is none yet, with the previous messages, and its reply is added to the conversation when the new job completes. The previous message must have been answered before sending a new one. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int                         true  "Itinerary ID"
+// @Param        itineraryJobId  path  int                         true  "Itinerary Job ID"
+// @Param        message         body  requests.JobMessageRequest  true  "Message"
+// @Success      202  {object}  responses.StartItineraryJobResponse  "Job started successfully."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data, the job is not completed or the previous message is still being answered."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or itinerary job not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Too many jobs running for your user. Please wait for existing jobs to complete."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create job. Try again later."
+// @Router       /itineraries/{itineraryId}/jobs/{itineraryJobId}/messages [post]
+func sendItineraryJobMessage(context *gin.Context) {
+	log.Debug("Sending itinerary job message")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.JobMessageRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The content of the message is required, up to 2048 characters."})
+		return
+	}
+
+	jobsService := services.GetItineraryFileJobService()
+	itineraryJob := getAndValidateItineraryJob(context, jobsService, itinerary)
+	if itineraryJob == nil {
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	if !checkJobsRunningLimit(context, jobsService, userId) {
+		return
+	}
+
+	itineraryFileJobTask, err := jobsService.SendMessage(itinerary, itineraryJob, input.Content, userId)
+	if err != nil {
+		log.Errorf("Error sending message about itinerary job %d: %v", itineraryJob.ID, err)
+		if strings.HasPrefix(err.Error(), "invalid message: ") || strings.HasPrefix(err.Error(), "invalid base job: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+			return
+		}
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create job. Try again later."})
+		return
+	}
+
+	enqueueItineraryFileJob(context, jobsService, itineraryFileJobTask, userId)
+}
@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetItineraryJobMessages_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	outputJobId := int64(6)
+	defer setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1},
+		FindMessagesResult: []*models.ItineraryJobMessage{
+			{ID: 1, JobID: 4, ItineraryID: 1, Role: models.JobMessageRoleUser, Content: "Make the third day more relaxed", OutputJobID: &outputJobId},
+		}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryJobParams)
+	getItineraryJobMessages(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"Make the third day more relaxed"`)
+	assert.Contains(t, w.Body.String(), `"outputJobId":6`)
+}
+
+func TestGetItineraryJobMessages_JobNotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockJobsService(&mockJobsService{FindAliveByIdErr: errors.New("sql: no rows in result set")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryJobParams)
+	getItineraryJobMessages(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSendItineraryJobMessage_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	jobsService := &mockJobsService{
+		FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1, Status: "completed"},
+		PrepareJobTask: &services.ItineraryFileAsyncTaskPayload{
+			Itinerary:        &models.Itinerary{ID: 1, OwnerID: 2},
+			ItineraryFileJob: &models.ItineraryFileJob{ID: 6},
+		},
+	}
+	defer setMockJobsService(jobsService)()
+	defer setMockAsyncqTaskQueue(&mockAsyncqTaskQueue{EnqueueId: "taskid"}, nil)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"content":"Add vegetarian restaurants"}`, itineraryJobParams)
+	sendItineraryJobMessage(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"jobId":6`)
+	assert.Equal(t, "Add vegetarian restaurants", jobsService.SentMessage)
+}
+
+func TestSendItineraryJobMessage_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	jobsService := &mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1, Status: "completed"}}
+	defer setMockJobsService(jobsService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"content":"Add vegetarian restaurants"}`, itineraryJobParams)
+	sendItineraryJobMessage(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, jobsService.SentMessage)
+}
+
+func TestSendItineraryJobMessage_PreviousMessageUnanswered(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockJobsService(&mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1, Status: "completed"},
+		PrepareJobErr: errors.New("invalid message: the previous message is still being answered")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"content":"Add a museum"}`, itineraryJobParams)
+	sendItineraryJobMessage(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the previous message is still being answered")
+}
+
+func TestSendItineraryJobMessage_EmptyContent(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	jobsService := &mockJobsService{FindAliveByIdResult: &models.ItineraryFileJob{ID: 4, ItineraryID: 1, Status: "completed"}}
+	defer setMockJobsService(jobsService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"content":""}`, itineraryJobParams)
+	sendItineraryJobMessage(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, jobsService.SentMessage)
+}
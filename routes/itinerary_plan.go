@@ -0,0 +1,285 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getItineraryPlan godoc
+// @Summary      Get the day-by-day plan of an itinerary
+// @Description  Gets the days of the plan of an itinerary that have activities, in order, each with its activities in order. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.GetPlanResponse  "Plan"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get plan. Try again later."
+// @Router       /itineraries/{itineraryId}/plan [get]
+func getItineraryPlan(context *gin.Context) {
+	log.Debug("Retrieving itinerary plan")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	days, err := services.GetItineraryPlanService().FindDays(itinerary)
+	if err != nil {
+		log.Errorf("Error retrieving plan of itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get plan. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetPlanResponse{Days: days})
+}
+
+// addItineraryActivity godoc
+// @Summary      Add an activity to the plan of an itinerary
+// @Description  Adds an activity at the end of its day in the plan of an itinerary. The date must be between the arrival in the first destination and the departure from the last one. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int                       true  "Itinerary ID"
+// @Param        activity     body  requests.ActivityRequest  true  "Activity"
+// @Success      201  {object}  responses.CreateActivityResponse  "Activity added."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid activity."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not add activity. Try again later."
+// @Router       /itineraries/{itineraryId}/plan/activities [post]
+func addItineraryActivity(context *gin.Context) {
+	log.Debug("Adding itinerary activity")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	activity := bindActivity(context)
+	if activity == nil {
+		return
+	}
+
+	err := services.GetItineraryPlanService().AddActivity(itinerary, activity, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error adding activity to itinerary %d: %v", itinerary.ID, err)
+		handlePlanError(context, err, "Itinerary not found.", "Could not add activity. Try again later.")
+		return
+	}
+
+	log.Debugf("Activity %d added to itinerary %d", activity.ID, itinerary.ID)
+	context.JSON(http.StatusCreated, &responses.CreateActivityResponse{Message: "Activity added.", Activity: activity})
+}
+
+// updateItineraryActivity godoc
+// @Summary      Update an activity of the plan of an itinerary
+// @Description  Replaces an activity of the plan of an itinerary. The activity keeps its place in its day, or goes at the end of the new one if its date changes. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int                       true  "Itinerary ID"
+// @Param        activityId   path  int                       true  "Activity ID"
+// @Param        activity     body  requests.ActivityRequest  true  "Activity"
+// @Success      200  {object}  responses.UpdateActivityResponse  "Activity updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid activity."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or activity not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update activity. Try again later."
+// @Router       /itineraries/{itineraryId}/plan/activities/{activityId} [put]
+func updateItineraryActivity(context *gin.Context) {
+	log.Debug("Updating itinerary activity")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	activityId := getPathId(context, "activityId", "activity")
+	if activityId == nil {
+		return
+	}
+
+	activity := bindActivity(context)
+	if activity == nil {
+		return
+	}
+	activity.ID = *activityId
+
+	err := services.GetItineraryPlanService().UpdateActivity(itinerary, activity, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating activity %d of itinerary %d: %v", *activityId, itinerary.ID, err)
+		handlePlanError(context, err, "Activity not found.", "Could not update activity. Try again later.")
+		return
+	}
+
+	log.Debugf("Activity %d of itinerary %d updated", *activityId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.UpdateActivityResponse{Message: "Activity updated.", Activity: activity})
+}
+
+// deleteItineraryActivity godoc
+// @Summary      Remove an activity from the plan of an itinerary
+// @Description  Removes an activity from the plan of an itinerary. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        activityId   path  int  true  "Activity ID"
+// @Success      200  {object}  responses.DeleteActivityResponse  "Activity removed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or activity not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not remove activity. Try again later."
+// @Router       /itineraries/{itineraryId}/plan/activities/{activityId} [delete]
+func deleteItineraryActivity(context *gin.Context) {
+	log.Debug("Deleting itinerary activity")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	activityId := getPathId(context, "activityId", "activity")
+	if activityId == nil {
+		return
+	}
+
+	err := services.GetItineraryPlanService().DeleteActivity(itinerary, *activityId, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting activity %d of itinerary %d: %v", *activityId, itinerary.ID, err)
+		handlePlanError(context, err, "Activity not found.", "Could not remove activity. Try again later.")
+		return
+	}
+
+	log.Debugf("Activity %d of itinerary %d removed", *activityId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.DeleteActivityResponse{Message: "Activity removed."})
+}
+
+// reorderItineraryDay godoc
+// @Summary      Reorder the activities of a day of the plan of an itinerary
+// @Description  Sets the order of the activities of a day of the plan of an itinerary. The IDs must be the ones of all the activities of the day, each of them once. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int                                true  "Itinerary ID"
+// @Param        date         path  string                             true  "Day of the plan, like 2024-07-01"
+// @Param        order        body  requests.ReorderActivitiesRequest  true  "Activity IDs in order"
+// @Success      200  {object}  responses.ReorderActivitiesResponse  "Activities reordered."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data, invalid date or invalid order."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or day not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not reorder activities. Try again later."
+// @Router       /itineraries/{itineraryId}/plan/days/{date}/order [put]
+func reorderItineraryDay(context *gin.Context) {
+	log.Debug("Reordering itinerary day")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, context.Param("date"))
+	if err != nil {
+		log.Errorf("Invalid plan date %s: %v", context.Param("date"), err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid date. It must be like 2024-07-01."})
+		return
+	}
+
+	var input requests.ReorderActivitiesRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The IDs of the activities of the day are required."})
+		return
+	}
+
+	err = services.GetItineraryPlanService().ReorderDay(itinerary, date, input.ActivityIds, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error reordering activities of %s in itinerary %d: %v", context.Param("date"), itinerary.ID, err)
+		handlePlanError(context, err, "Day not found.", "Could not reorder activities. Try again later.")
+		return
+	}
+
+	log.Debugf("Activities of %s in itinerary %d reordered", context.Param("date"), itinerary.ID)
+	context.JSON(http.StatusOK, &responses.ReorderActivitiesResponse{Message: "Activities reordered."})
+}
+
+// generateItineraryPlan godoc
+// @Summary      Generate the plan of an itinerary
+// @Description  Starts an asynchronous itinerary file job that replaces the plan of an itinerary with activities for every day of the trip generated by the LLM for its destinations and traveller preferences, and renders the file of the itinerary from it. The generated plan can be edited afterwards. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      202  {object}  responses.StartItineraryJobResponse  "Job started successfully."
+// @Failure      400  {object}  responses.ErrorResponse  "The itinerary has no destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      409  {object}  responses.ErrorResponse  "Too many jobs running for your user. Please wait for existing jobs to complete."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create job. Try again later."
+// @Router       /itineraries/{itineraryId}/plan/generate [post]
+func generateItineraryPlan(context *gin.Context) {
+	log.Debug("Generating itinerary plan")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	jobsService := services.GetItineraryFileJobService()
+	userId := context.GetInt64("userId")
+	if !checkJobsRunningLimit(context, jobsService, userId) {
+		return
+	}
+
+	itineraryFileJobTask, err := jobsService.PrepareJob(itinerary, "", "", models.ItineraryFileJobSourceLlmPlan, userId)
+	if err != nil {
+		log.Errorf("Error preparing plan generation job of itinerary %d: %v", itinerary.ID, err)
+		handlePlanError(context, err, "Itinerary not found.", "Could not create job. Try again later.")
+		return
+	}
+
+	enqueueItineraryFileJob(context, jobsService, itineraryFileJobTask, userId)
+}
+
+// bindActivity parses the activity of the request body, answering with an error if it is not valid
+func bindActivity(context *gin.Context) *models.ItineraryActivity {
+	var input requests.ActivityRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The date as YYYY-MM-DD, a time slot among morning, afternoon and evening, and the title are required."})
+		return nil
+	}
+
+	// The format of the date was checked by the binding
+	date, _ := time.Parse(time.DateOnly, input.Date)
+	return &models.ItineraryActivity{Date: date, TimeSlot: input.TimeSlot, Title: input.Title, Location: input.Location, Notes: input.Notes}
+}
+
+func handlePlanError(context *gin.Context, err error, notFoundMessage string, internalErrorMessage string) {
+	switch {
+	case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: notFoundMessage})
+	case strings.HasPrefix(err.Error(), "invalid activity: "), strings.HasPrefix(err.Error(), "invalid plan: "):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+	default:
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: internalErrorMessage})
+	}
+}
@@ -0,0 +1,219 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockItineraryPlanService struct {
+	Err         error
+	Days        []*models.ItineraryDay
+	Activity    *models.ItineraryActivity
+	DeletedId   int64
+	Date        time.Time
+	ActivityIds []int64
+}
+
+func (m *mockItineraryPlanService) FindDays(_ *models.Itinerary) ([]*models.ItineraryDay, error) {
+	return m.Days, m.Err
+}
+func (m *mockItineraryPlanService) AddActivity(_ *models.Itinerary, activity *models.ItineraryActivity, _ int64) error {
+	m.Activity = activity
+	return m.Err
+}
+func (m *mockItineraryPlanService) UpdateActivity(_ *models.Itinerary, activity *models.ItineraryActivity, _ int64) error {
+	m.Activity = activity
+	return m.Err
+}
+func (m *mockItineraryPlanService) DeleteActivity(_ *models.Itinerary, activityId int64, _ int64) error {
+	m.DeletedId = activityId
+	return m.Err
+}
+func (m *mockItineraryPlanService) ReorderDay(_ *models.Itinerary, date time.Time, activityIds []int64, _ int64) error {
+	m.Date = date
+	m.ActivityIds = activityIds
+	return m.Err
+}
+func (m *mockItineraryPlanService) Generate(_ context.Context, _ *models.Itinerary, _ int64) ([]*models.ItineraryDay, error) {
+	return m.Days, m.Err
+}
+
+func setMockItineraryPlanService(mock *mockItineraryPlanService) func() {
+	orig := services.GetItineraryPlanService
+	services.GetItineraryPlanService = func() services.ItineraryPlanServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryPlanService = orig }
+}
+
+var itineraryActivityParams = gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "activityId", Value: "5"}}
+
+// --- Tests ---
+
+func TestGetItineraryPlan_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	defer setMockItineraryPlanService(&mockItineraryPlanService{Days: []*models.ItineraryDay{{Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		Activities: []*models.ItineraryActivity{{ID: 5, TimeSlot: models.TimeSlotMorning, Title: "Visit the Prado Museum", Position: 1}}}}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryPlan(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"date":"2024-07-01T00:00:00Z"`)
+	assert.Contains(t, w.Body.String(), `"title":"Visit the Prado Museum"`)
+}
+
+func TestAddItineraryActivity_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	planService := &mockItineraryPlanService{}
+	defer setMockItineraryPlanService(planService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"date":"2024-07-01","timeSlot":"morning","title":"Visit the Prado Museum",
+		"notes":"Free entry from 6 pm"}`, itineraryIdParams)
+	addItineraryActivity(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), planService.Activity.Date)
+	assert.Equal(t, models.TimeSlotMorning, planService.Activity.TimeSlot)
+	assert.Equal(t, "Free entry from 6 pm", planService.Activity.Notes)
+}
+
+func TestAddItineraryActivity_InvalidBody(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	for _, body := range []string{
+		`{"timeSlot":"morning","title":"Walk"}`,
+		`{"date":"01/07/2024","timeSlot":"morning","title":"Walk"}`,
+		`{"date":"2024-07-01","timeSlot":"night","title":"Walk"}`,
+		`{"date":"2024-07-01","timeSlot":"morning"}`,
+	} {
+		planService := &mockItineraryPlanService{}
+		restore := setMockItineraryPlanService(planService)
+
+		c, w := newAuthenticatedContext(http.MethodPost, body, itineraryIdParams)
+		addItineraryActivity(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, planService.Activity, body)
+	}
+}
+
+func TestAddItineraryActivity_OutsideTrip(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryPlanService(&mockItineraryPlanService{
+		Err: errors.New("invalid activity: the date must be between 2024-07-01 and 2024-07-08")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"date":"2024-08-01","timeSlot":"morning","title":"Walk"}`, itineraryIdParams)
+	addItineraryActivity(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the date must be between 2024-07-01 and 2024-07-08")
+}
+
+func TestUpdateItineraryActivity_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	planService := &mockItineraryPlanService{Err: sql.ErrNoRows}
+	defer setMockItineraryPlanService(planService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"date":"2024-07-02","timeSlot":"evening","title":"Flamenco show"}`,
+		itineraryActivityParams)
+	updateItineraryActivity(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, int64(5), planService.Activity.ID)
+}
+
+func TestDeleteItineraryActivity_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	planService := &mockItineraryPlanService{}
+	defer setMockItineraryPlanService(planService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryActivityParams)
+	deleteItineraryActivity(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Zero(t, planService.DeletedId)
+}
+
+func TestReorderItineraryDay_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	planService := &mockItineraryPlanService{}
+	defer setMockItineraryPlanService(planService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"activityIds":[3,1,2]}`,
+		gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "date", Value: "2024-07-01"}})
+	reorderItineraryDay(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), planService.Date)
+	assert.Equal(t, []int64{3, 1, 2}, planService.ActivityIds)
+}
+
+func TestReorderItineraryDay_InvalidDate(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	planService := &mockItineraryPlanService{}
+	defer setMockItineraryPlanService(planService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"activityIds":[1]}`,
+		gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "date", Value: "July 1"}})
+	reorderItineraryDay(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, planService.ActivityIds)
+}
+
+func TestGenerateItineraryPlan_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	jobsService := &mockJobsService{PrepareJobTask: &services.ItineraryFileAsyncTaskPayload{Itinerary: &models.Itinerary{ID: 1, OwnerID: 1},
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 7}}}
+	defer setMockJobsService(jobsService)()
+	origQueue := services.NewAsyncqTaskQueue
+	defer func() { services.NewAsyncqTaskQueue = origQueue }()
+	services.NewAsyncqTaskQueue = func() (services.AsyncTaskQueueInterface, error) {
+		return &mockAsyncqTaskQueue{EnqueueId: "task-7"}, nil
+	}
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateItineraryPlan(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, models.ItineraryFileJobSourceLlmPlan, jobsService.PreparedSource)
+	assert.Contains(t, w.Body.String(), `"jobId":7`)
+}
+
+func TestGenerateItineraryPlan_TooManyJobsRunning(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	jobsService := &mockJobsService{GetInProgressJobsOfUserCountVal: 5}
+	defer setMockJobsService(jobsService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateItineraryPlan(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Empty(t, jobsService.PreparedSource)
+}
+
+func TestGenerateItineraryPlan_NoDestinations(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockJobsService(&mockJobsService{PrepareJobErr: errors.New("invalid plan: the itinerary has no destinations")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	generateItineraryPlan(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the itinerary has no destinations")
+}
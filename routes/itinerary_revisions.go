@@ -0,0 +1,180 @@
+package routes
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getItineraryRevisions godoc
+// @Summary      Get the revision history of an itinerary
+// @Description  Retrieves the revisions of an itinerary from the newest, with their author and date. A revision is saved when the itinerary is created and on every update or restore. Use the revision endpoint to get the destinations of a revision. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.GetItineraryRevisionsResponse  "List of revisions"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve itinerary revisions. Try again later."
+// @Router       /itineraries/{itineraryId}/revisions [get]
+func getItineraryRevisions(context *gin.Context) {
+	log.Debug("Retrieving itinerary revisions")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	revisions, err := services.GetItineraryRevisionService().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		log.Errorf("Error retrieving revisions of itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve itinerary revisions. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryRevisionsResponse{Revisions: revisions})
+}
+
+// getItineraryRevision godoc
+// @Summary      Get a revision of an itinerary
+// @Description  Retrieves the content of an itinerary as it was in one of its revisions, with its destinations. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int  true  "Itinerary ID"
+// @Param        revisionNumber  path  int  true  "Revision number"
+// @Success      200  {object}  responses.GetItineraryRevisionResponse  "Revision"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid revision number."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or revision not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve itinerary revision. Try again later."
+// @Router       /itineraries/{itineraryId}/revisions/{revisionNumber} [get]
+func getItineraryRevision(context *gin.Context) {
+	log.Debug("Retrieving itinerary revision")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	number := getPathId(context, "revisionNumber", "revision")
+	if number == nil {
+		return
+	}
+
+	revision, err := services.GetItineraryRevisionService().FindByNumber(itinerary.ID, *number)
+	if err != nil {
+		log.Errorf("Error retrieving revision %d of itinerary %d: %v", *number, itinerary.ID, err)
+		handleItineraryRevisionError(context, err, "Could not retrieve itinerary revision. Try again later.")
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryRevisionResponse{Revision: revision})
+}
+
+// getItineraryRevisionsDiff godoc
+// @Summary      Compare two revisions of an itinerary
+// @Description  Lists the changes made to an itinerary from a revision to another one. Each change has the JSON path of the changed field with its old and new values. Destinations are compared by their position, and added or removed destinations have no old or new value respectively. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path   int  true  "Itinerary ID"
+// @Param        from         query  int  true  "Number of the revision to compare from"
+// @Param        to           query  int  true  "Number of the revision to compare to"
+// @Success      200  {object}  responses.GetItineraryRevisionsDiffResponse  "Changes between the revisions"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid revision numbers."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or revision not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not compare itinerary revisions. Try again later."
+// @Router       /itineraries/{itineraryId}/revisions/diff [get]
+func getItineraryRevisionsDiff(context *gin.Context) {
+	log.Debug("Comparing itinerary revisions")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.GetItineraryRevisionsDiffRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing revisions diff query: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The from and to revision numbers are mandatory."})
+		return
+	}
+
+	changes, err := services.GetItineraryRevisionService().Diff(itinerary.ID, input.From, input.To)
+	if err != nil {
+		log.Errorf("Error comparing revisions %d and %d of itinerary %d: %v", input.From, input.To, itinerary.ID, err)
+		handleItineraryRevisionError(context, err, "Could not compare itinerary revisions. Try again later.")
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryRevisionsDiffResponse{From: input.From, To: input.To, Changes: changes})
+}
+
+// restoreItineraryRevision godoc
+// @Summary      Restore a revision of an itinerary
+// @Description  Brings the title, description, notes and destinations of an itinerary back to the ones of an earlier revision. The restored content is saved as a new revision, so the history is kept. The authenticated user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int  true  "Itinerary ID"
+// @Param        revisionNumber  path  int  true  "Number of the revision to restore"
+// @Success      200  {object}  responses.RestoreItineraryRevisionResponse  "Itinerary restored."
+// @Header       200  {string}  ETag  "New ETag of the itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid revision number."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or revision not found."
+// @Failure      409  {object}  responses.ErrorResponse  "The itinerary was changed since it was retrieved. Get it again and retry."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not restore itinerary revision. Try again later."
+// @Router       /itineraries/{itineraryId}/revisions/{revisionNumber}/restore [post]
+func restoreItineraryRevision(context *gin.Context) {
+	log.Debug("Restoring itinerary revision")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	number := getPathId(context, "revisionNumber", "revision")
+	if number == nil {
+		return
+	}
+
+	err := services.GetItineraryRevisionService().Restore(itinerary, *number, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error restoring revision %d of itinerary %d: %v", *number, itinerary.ID, err)
+		handleItineraryRevisionError(context, err, "Could not restore itinerary revision. Try again later.")
+		return
+	}
+
+	log.Debugf("Itinerary %d restored to revision %d", itinerary.ID, *number)
+	setItineraryETag(context, itinerary)
+	context.JSON(http.StatusOK, &responses.RestoreItineraryRevisionResponse{Message: fmt.Sprintf("Itinerary restored to revision %d.", *number)})
+}
+
+func handleItineraryRevisionError(context *gin.Context, err error, internalErrorMessage string) {
+	switch {
+	case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Revision not found."})
+	case strings.Contains(err.Error(), "invalid revision number"):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid revision number."})
+	case strings.Contains(err.Error(), models.ErrItineraryVersionConflict.Error()):
+		context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: itineraryChangedMessage})
+	default:
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: internalErrorMessage})
+	}
+}
@@ -0,0 +1,182 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockItineraryRevisionService struct {
+	Revisions      []*models.ItineraryRevision
+	Revision       *models.ItineraryRevision
+	Changes        []*models.ItineraryRevisionChange
+	Err            error
+	RestoredNumber int64
+	RestoredBy     int64
+}
+
+func (m *mockItineraryRevisionService) FindByItineraryId(_ int64) ([]*models.ItineraryRevision, error) {
+	return m.Revisions, m.Err
+}
+func (m *mockItineraryRevisionService) FindByNumber(_ int64, _ int64) (*models.ItineraryRevision, error) {
+	return m.Revision, m.Err
+}
+func (m *mockItineraryRevisionService) Diff(_ int64, _ int64, _ int64) ([]*models.ItineraryRevisionChange, error) {
+	return m.Changes, m.Err
+}
+func (m *mockItineraryRevisionService) Restore(_ *models.Itinerary, number int64, actorId int64) error {
+	m.RestoredNumber = number
+	m.RestoredBy = actorId
+	return m.Err
+}
+
+func setMockItineraryRevisionService(mock *mockItineraryRevisionService) func() {
+	orig := services.GetItineraryRevisionService
+	services.GetItineraryRevisionService = func() services.ItineraryRevisionServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryRevisionService = orig }
+}
+
+var itineraryRevisionParams = gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "revisionNumber", Value: "2"}}
+
+// --- Tests ---
+
+func TestGetItineraryRevisions_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Revisions: []*models.ItineraryRevision{
+		{ItineraryID: 1, Number: 2, AuthorID: 2, Title: "Spain"}, {ItineraryID: 1, Number: 1, AuthorID: 2, Title: "Spain trip"},
+	}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryRevisions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"number":2`)
+	assert.Contains(t, w.Body.String(), "Spain trip")
+}
+
+func TestGetItineraryRevisions_Error(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Err: errors.New("db error")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryRevisions(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetItineraryRevision_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Revision: &models.ItineraryRevision{ItineraryID: 1, Number: 2,
+		TravelDestinations: []*models.ItineraryRevisionDestination{{Country: "Spain", City: "Madrid"}}}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryRevisionParams)
+	getItineraryRevision(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Madrid")
+}
+
+func TestGetItineraryRevision_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Err: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryRevisionParams)
+	getItineraryRevision(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetItineraryRevision_InvalidNumber(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "revisionNumber", Value: "latest"}})
+	getItineraryRevision(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetItineraryRevisionsDiff_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Changes: []*models.ItineraryRevisionChange{
+		{Field: "title", From: "Spain trip", To: "Spain"},
+	}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	c.Request.URL.RawQuery = "from=1&to=2"
+	getItineraryRevisionsDiff(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"from":1`)
+	assert.Contains(t, w.Body.String(), `"field":"title"`)
+}
+
+func TestGetItineraryRevisionsDiff_MissingRevision(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	c.Request.URL.RawQuery = "from=1"
+	getItineraryRevisionsDiff(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRestoreItineraryRevision_Editor(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	revisionService := &mockItineraryRevisionService{}
+	defer setMockItineraryRevisionService(revisionService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryRevisionParams)
+	restoreItineraryRevision(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Itinerary restored to revision 2.")
+	assert.Equal(t, int64(2), revisionService.RestoredNumber)
+	assert.Equal(t, int64(1), revisionService.RestoredBy)
+}
+
+func TestRestoreItineraryRevision_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	revisionService := &mockItineraryRevisionService{}
+	defer setMockItineraryRevisionService(revisionService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryRevisionParams)
+	restoreItineraryRevision(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Zero(t, revisionService.RestoredNumber)
+}
+
+func TestRestoreItineraryRevision_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Err: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryRevisionParams)
+	restoreItineraryRevision(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRestoreItineraryRevision_VersionConflict(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryRevisionService(&mockItineraryRevisionService{Err: models.ErrItineraryVersionConflict})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryRevisionParams)
+	restoreItineraryRevision(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// shareItinerary godoc
+// @Summary      Share an itinerary with another user
+// @Description  Grants a registered user the viewer or editor permission on an itinerary. Viewers can read the itinerary and download its files, while editors can also update it and manage its file jobs. Sharing again with the same user changes their permission. Only the owner can share the itinerary.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        share  body  requests.ShareItineraryRequest  true  "User and permission"
+// @Success      200  {object}  responses.ShareItineraryResponse  "Itinerary shared."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or the itinerary cannot be shared with its owner."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or user not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not share itinerary. Try again later."
+// @Router       /itineraries/{itineraryId}/shares [post]
+func shareItinerary(context *gin.Context) {
+	log.Debug("Sharing itinerary")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionOwner)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.ShareItineraryRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The email is mandatory and the permission must be viewer or editor."})
+		return
+	}
+
+	share, err := services.GetItineraryShareService().Share(itinerary, input.Email, input.Permission, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error sharing itinerary %d: %v", itinerary.ID, err)
+		switch {
+		case strings.Contains(err.Error(), "user not found"):
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "User not found."})
+		case strings.Contains(err.Error(), "invalid permission"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The permission must be viewer or editor."})
+		case strings.Contains(err.Error(), "its owner"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "An itinerary cannot be shared with its owner."})
+		default:
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not share itinerary. Try again later."})
+		}
+		return
+	}
+
+	log.Debugf("Itinerary %d shared with user %d as %s", itinerary.ID, share.UserID, share.Permission)
+	context.JSON(http.StatusOK, &responses.ShareItineraryResponse{Message: "Itinerary shared.", Share: share})
+}
+
+// getItineraryShares godoc
+// @Summary      Get the users an itinerary is shared with
+// @Description  Retrieves the users an itinerary is shared with and their permissions. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.GetItinerarySharesResponse  "List of itinerary shares"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve itinerary shares. Try again later."
+// @Router       /itineraries/{itineraryId}/shares [get]
+func getItineraryShares(context *gin.Context) {
+	log.Debug("Retrieving itinerary shares")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	shares, err := services.GetItineraryShareService().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		log.Errorf("Error retrieving shares of itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve itinerary shares. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItinerarySharesResponse{Shares: shares})
+}
+
+// unshareItinerary godoc
+// @Summary      Stop sharing an itinerary with a user
+// @Description  Revokes the access of a user to an itinerary. The owner can revoke the access of anyone, while the other users can only leave an itinerary shared with them.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        userId       path  int  true  "User ID"
+// @Success      200  {object}  responses.UnshareItineraryResponse  "Itinerary unshared."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid user ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or share not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not unshare itinerary. Try again later."
+// @Router       /itineraries/{itineraryId}/shares/{userId} [delete]
+func unshareItinerary(context *gin.Context) {
+	log.Debug("Unsharing itinerary")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	userId := getPathId(context, "userId", "user")
+	if userId == nil {
+		return
+	}
+
+	actorId := context.GetInt64("userId")
+	if *userId != actorId && !checkItineraryPermission(context, itinerary, actorId, models.ItineraryPermissionOwner) {
+		return
+	}
+
+	err := services.GetItineraryShareService().Unshare(itinerary, *userId, actorId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			log.Errorf("Itinerary %d is not shared with user %d", itinerary.ID, *userId)
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Share not found."})
+		} else {
+			log.Errorf("Error unsharing itinerary %d with user %d: %v", itinerary.ID, *userId, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not unshare itinerary. Try again later."})
+		}
+		return
+	}
+
+	log.Debugf("Itinerary %d unshared with user %d", itinerary.ID, *userId)
+	context.JSON(http.StatusOK, &responses.UnshareItineraryResponse{Message: "Itinerary unshared."})
+}
+
+// getSharedItineraries godoc
+// @Summary      Get the itineraries shared with the authenticated user
+// @Description  Retrieves the itineraries other users shared with the authenticated user, together with the granted permission.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetSharedItinerariesResponse  "List of shared itineraries"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve shared itineraries. Try again later."
+// @Router       /itineraries/shared [get]
+func getSharedItineraries(context *gin.Context) {
+	log.Debug("Retrieving shared itineraries")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	shares, err := services.GetItineraryShareService().FindSharedWithUser(*userId)
+	if err != nil {
+		log.Errorf("Error retrieving itineraries shared with user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve shared itineraries. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetSharedItinerariesResponse{Shares: shares})
+}
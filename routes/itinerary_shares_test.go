@@ -0,0 +1,274 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+// mockPermissionService grants the owner permission to the owner of the itinerary and Permission to anyone else
+type mockPermissionService struct {
+	Permission string
+	Err        error
+}
+
+func (m *mockPermissionService) GetItineraryPermission(itinerary *models.Itinerary, userId int64) (string, error) {
+	if m.Err != nil {
+		return "", m.Err
+	}
+	if itinerary.OwnerID == userId {
+		return models.ItineraryPermissionOwner, nil
+	}
+	return m.Permission, nil
+}
+
+func (m *mockPermissionService) CheckItineraryPermission(itinerary *models.Itinerary, userId int64, requiredPermission string) error {
+	permission, err := m.GetItineraryPermission(itinerary, userId)
+	if err != nil {
+		return err
+	}
+	if models.ItineraryPermissionLevel(permission) < models.ItineraryPermissionLevel(requiredPermission) {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+func setMockPermissionService(mock *mockPermissionService) func() {
+	orig := services.GetPermissionService
+	services.GetPermissionService = func() services.PermissionServiceInterface {
+		return mock
+	}
+	return func() { services.GetPermissionService = orig }
+}
+
+type mockItineraryShareService struct {
+	Shares         []*models.ItineraryShare
+	FindErr        error
+	ShareErr       error
+	UnshareErr     error
+	SharedEmail    string
+	SharedRole     string
+	UnsharedUserId int64
+}
+
+func (m *mockItineraryShareService) FindByItineraryId(_ int64) ([]*models.ItineraryShare, error) {
+	return m.Shares, m.FindErr
+}
+func (m *mockItineraryShareService) FindSharedWithUser(_ int64) ([]*models.ItineraryShare, error) {
+	return m.Shares, m.FindErr
+}
+func (m *mockItineraryShareService) Share(itinerary *models.Itinerary, email string, permission string, _ int64) (*models.ItineraryShare, error) {
+	m.SharedEmail = email
+	m.SharedRole = permission
+	if m.ShareErr != nil {
+		return nil, m.ShareErr
+	}
+	return &models.ItineraryShare{ItineraryID: itinerary.ID, UserID: 2, UserEmail: email, Permission: permission}, nil
+}
+func (m *mockItineraryShareService) Unshare(_ *models.Itinerary, userId int64, _ int64) error {
+	m.UnsharedUserId = userId
+	return m.UnshareErr
+}
+
+func setMockItineraryShareService(mock *mockItineraryShareService) func() {
+	orig := services.GetItineraryShareService
+	services.GetItineraryShareService = func() services.ItineraryShareServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryShareService = orig }
+}
+
+func setMockItineraryService(mock *mockItineraryService) func() {
+	orig := services.GetItineraryService
+	services.GetItineraryService = func() services.ItineraryServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryService = orig }
+}
+
+var itineraryIdParams = gin.Params{{Key: "itineraryId", Value: "1"}}
+
+// --- Tests ---
+
+func TestShareItinerary_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	shareService := &mockItineraryShareService{}
+	defer setMockItineraryShareService(shareService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"email":"friend@example.com","permission":"editor"}`, itineraryIdParams)
+	shareItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"permission":"editor"`)
+	assert.Equal(t, "friend@example.com", shareService.SharedEmail)
+}
+
+func TestShareItinerary_InvalidPermission(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryShareService(&mockItineraryShareService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"email":"friend@example.com","permission":"owner"}`, itineraryIdParams)
+	shareItinerary(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestShareItinerary_NotOwner(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"email":"friend@example.com","permission":"viewer"}`, itineraryIdParams)
+	shareItinerary(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestShareItinerary_UserNotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryShareService(&mockItineraryShareService{ShareErr: errors.New("user not found")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"email":"nobody@example.com","permission":"viewer"}`, itineraryIdParams)
+	shareItinerary(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestShareItinerary_WithOwner(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryShareService(&mockItineraryShareService{ShareErr: errors.New("cannot share an itinerary with its owner")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"email":"me@example.com","permission":"viewer"}`, itineraryIdParams)
+	shareItinerary(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetItineraryShares_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	defer setMockItineraryShareService(&mockItineraryShareService{Shares: []*models.ItineraryShare{{UserID: 1, UserEmail: "me@example.com", Permission: "viewer"}}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryShares(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "me@example.com")
+}
+
+func TestGetItineraryShares_Error(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryShareService(&mockItineraryShareService{FindErr: errors.New("db error")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryShares(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestUnshareItinerary_ByOwner(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	shareService := &mockItineraryShareService{}
+	defer setMockItineraryShareService(shareService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "userId", Value: "3"}})
+	unshareItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), shareService.UnsharedUserId)
+}
+
+func TestUnshareItinerary_LeaveSharedItinerary(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	shareService := &mockItineraryShareService{}
+	defer setMockItineraryShareService(shareService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "userId", Value: "1"}})
+	unshareItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), shareService.UnsharedUserId)
+}
+
+func TestUnshareItinerary_EditorCannotUnshareOthers(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	shareService := &mockItineraryShareService{}
+	defer setMockItineraryShareService(shareService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "userId", Value: "3"}})
+	unshareItinerary(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Zero(t, shareService.UnsharedUserId)
+}
+
+func TestUnshareItinerary_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryShareService(&mockItineraryShareService{UnshareErr: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "userId", Value: "3"}})
+	unshareItinerary(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetSharedItineraries_Success(t *testing.T) {
+	defer setMockItineraryShareService(&mockItineraryShareService{Shares: []*models.ItineraryShare{
+		{ItineraryID: 4, UserID: 1, Permission: "editor", Itinerary: &models.Itinerary{ID: 4, Title: "Trip to Spain", OwnerID: 2}},
+	}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getSharedItineraries(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Trip to Spain")
+}
+
+func TestGetItinerary_SharedWithViewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRunItineraryFileJob_ViewerForbidden(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	runItineraryFileJob(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeleteItinerary_EditorForbidden(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryIdParams)
+	deleteItinerary(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetItinerary_PermissionCheckError(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Err: errors.New("failed to check permission")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItinerary(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,337 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+)
+
+// cloneItinerary godoc
+// @Summary      Clone an itinerary
+// @Description  Creates a copy of an itinerary owned by the authenticated user, with its description, notes, destinations and the overrides of its traveller preferences. The dates of every destination can be moved a number of days with shiftDays, or so that the trip starts on startDate. The copy keeps the title of the itinerary unless another one is given. The body is optional. The user must own the itinerary or be one of its viewers or editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        clone  body  requests.CloneItineraryRequest  false  "Options of the copy"
+// @Success      201  {object}  responses.CreateItineraryResponse  "Itinerary cloned."
+// @Header       201  {string}  ETag  "ETag of the new itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not clone itinerary. Try again later."
+// @Router       /itineraries/{itineraryId}/clone [post]
+func cloneItinerary(context *gin.Context) {
+	log.Debug("Cloning itinerary")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	// The body is optional, so a plain copy needs none
+	var input requests.CloneItineraryRequest
+	if err := context.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The title cannot be too large and the shift cannot exceed 3660 days."})
+		return
+	}
+
+	if input.StartDate != nil && input.ShiftDays != 0 {
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Either the shift in days or the start date can be given, not both."})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	itineraryService := services.GetItineraryService()
+
+	clone, err := itineraryService.Clone(itinerary, userId, services.CloneOptions{Title: input.Title, ShiftDays: input.ShiftDays, StartDate: input.StartDate})
+	if err != nil {
+		log.Errorf("Error cloning itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not clone itinerary. Try again later."})
+		return
+	}
+
+	log.Debugf("Itinerary %d cloned as itinerary %d for user %d", itinerary.ID, clone.ID, userId)
+	setItineraryETag(context, clone)
+	context.JSON(http.StatusCreated, &responses.CreateItineraryResponse{Message: "Itinerary cloned.", ItineraryID: clone.ID})
+}
+
+// createTemplateFromItinerary godoc
+// @Summary      Save an itinerary as a template
+// @Description  Saves the route of an itinerary as a template of the authenticated user, without dates: every destination is placed by the whole days since the arrival at the first one. The template takes the title, description and notes of the itinerary, unless another title is given. Shared templates can be used by every user. The user must own the itinerary or be one of its viewers or editors.
+// @Tags         itinerary-templates
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        template  body  requests.CreateTemplateFromItineraryRequest  true  "Template options"
+// @Success      201  {object}  responses.CreateItineraryTemplateResponse  "Itinerary template created."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create itinerary template. Try again later."
+// @Router       /itineraries/{itineraryId}/template [post]
+func createTemplateFromItinerary(context *gin.Context) {
+	log.Debug("Creating itinerary template from itinerary")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.CreateTemplateFromItineraryRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The title cannot be too large."})
+		return
+	}
+
+	template := models.NewItineraryTemplateFromItinerary(itinerary)
+	if input.Title != "" {
+		template.Title = input.Title
+	}
+	template.Shared = input.Shared
+	template.OwnerID = context.GetInt64("userId")
+
+	saveItineraryTemplate(context, template)
+}
+
+// getItineraryTemplates godoc
+// @Summary      List the itinerary templates
+// @Description  Retrieves the itinerary templates of the authenticated user and the ones shared by other users, sorted by title.
+// @Tags         itinerary-templates
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetItineraryTemplatesResponse  "Itinerary templates"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get itinerary templates. Try again later."
+// @Router       /itinerary-templates [get]
+func getItineraryTemplates(context *gin.Context) {
+	log.Debug("Retrieving itinerary templates")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	templates, err := services.GetItineraryTemplateService().FindAvailable(*userId)
+	if err != nil {
+		log.Errorf("Error retrieving itinerary templates of user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get itinerary templates. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryTemplatesResponse{Templates: templates})
+}
+
+// createItineraryTemplate godoc
+// @Summary      Create an itinerary template
+// @Description  Creates an itinerary template of the authenticated user. Templates have no dates: every destination is arrived at on the startDay day of the trip (0 being its start date) and departed from days days later. The destinations follow the same rules as the ones of an itinerary once placed on the calendar. Shared templates can be used by every user.
+// @Tags         itinerary-templates
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        template  body  requests.CreateItineraryTemplateRequest  true  "Itinerary template"
+// @Success      201  {object}  responses.CreateItineraryTemplateResponse  "Itinerary template created."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create itinerary template. Try again later."
+// @Router       /itinerary-templates [post]
+func createItineraryTemplate(context *gin.Context) {
+	log.Debug("Creating itinerary template")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	var input requests.CreateItineraryTemplateRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. One or more mandatory attributes are null/empty or at least one of the expected attributes is too large."})
+		return
+	}
+
+	template := &models.ItineraryTemplate{Title: input.Title, Description: input.Description, Notes: input.Notes, OwnerID: *userId,
+		Shared: input.Shared, Destinations: []*models.ItineraryTemplateDestination{}}
+	for _, destination := range input.Destinations {
+		template.Destinations = append(template.Destinations, &models.ItineraryTemplateDestination{Country: destination.Country,
+			City: destination.City, StartDay: destination.StartDay, Days: destination.Days})
+	}
+
+	saveItineraryTemplate(context, template)
+}
+
+// getItineraryTemplate godoc
+// @Summary      Get an itinerary template
+// @Description  Retrieves an itinerary template of the authenticated user or shared by another user.
+// @Tags         itinerary-templates
+// @Produce      json
+// @Security     Auth
+// @Param        templateId  path  int  true  "Itinerary template ID"
+// @Success      200  {object}  responses.GetItineraryTemplateResponse  "Itinerary template"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid itinerary template ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get itinerary template. Try again later."
+// @Router       /itinerary-templates/{templateId} [get]
+func getItineraryTemplate(context *gin.Context) {
+	log.Debug("Retrieving itinerary template")
+
+	template := getAndValidateItineraryTemplate(context)
+	if template == nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetItineraryTemplateResponse{Template: template})
+}
+
+// deleteItineraryTemplate godoc
+// @Summary      Delete an itinerary template
+// @Description  Deletes an itinerary template of the authenticated user. The itineraries created from it are kept. Shared templates can only be deleted by their owner.
+// @Tags         itinerary-templates
+// @Produce      json
+// @Security     Auth
+// @Param        templateId  path  int  true  "Itinerary template ID"
+// @Success      200  {object}  responses.DeleteItineraryTemplateResponse  "Itinerary template deleted."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid itinerary template ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not delete itinerary template. Try again later."
+// @Router       /itinerary-templates/{templateId} [delete]
+func deleteItineraryTemplate(context *gin.Context) {
+	log.Debug("Deleting itinerary template")
+
+	template := getAndValidateItineraryTemplate(context)
+	if template == nil {
+		return
+	}
+
+	err := services.GetItineraryTemplateService().Delete(template, context.GetInt64("userId"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrItineraryTemplateNotOwned):
+			context.JSON(http.StatusForbidden, &responses.ErrorResponse{Message: "You do not have permission to access this resource."})
+		case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Itinerary template not found."})
+		default:
+			log.Errorf("Error deleting itinerary template %d: %v", template.ID, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete itinerary template. Try again later."})
+		}
+		return
+	}
+
+	log.Debugf("Itinerary template %d deleted", template.ID)
+	context.JSON(http.StatusOK, &responses.DeleteItineraryTemplateResponse{Message: "Itinerary template deleted."})
+}
+
+// instantiateItineraryTemplate godoc
+// @Summary      Create an itinerary from a template
+// @Description  Creates an itinerary of the authenticated user from an itinerary template of theirs or shared by another user, placing its destinations on the calendar from the start date. The itinerary takes the title, description and notes of the template, unless another title is given.
+// @Tags         itinerary-templates
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        templateId  path  int  true  "Itinerary template ID"
+// @Param        instantiation  body  requests.InstantiateItineraryTemplateRequest  true  "Start date and title of the itinerary"
+// @Success      201  {object}  responses.CreateItineraryResponse  "Itinerary created."
+// @Header       201  {string}  ETag  "ETag of the new itinerary"
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid destinations."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create itinerary. Try again later."
+// @Router       /itinerary-templates/{templateId}/instantiate [post]
+func instantiateItineraryTemplate(context *gin.Context) {
+	log.Debug("Creating itinerary from template")
+
+	template := getAndValidateItineraryTemplate(context)
+	if template == nil {
+		return
+	}
+
+	var input requests.InstantiateItineraryTemplateRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The start date is required and the title cannot be too large."})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+	itinerary, err := services.GetItineraryTemplateService().Instantiate(template, input.StartDate, input.Title, userId)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid itinerary template: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: strings.TrimPrefix(err.Error(), "invalid itinerary template: ")})
+			return
+		}
+		log.Errorf("Error creating itinerary from template %d: %v", template.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create itinerary. Try again later."})
+		return
+	}
+
+	log.Debugf("Itinerary %d created from template %d for user %d", itinerary.ID, template.ID, userId)
+	setItineraryETag(context, itinerary)
+	context.JSON(http.StatusCreated, &responses.CreateItineraryResponse{Message: "Itinerary created.", ItineraryID: itinerary.ID})
+}
+
+// getAndValidateItineraryTemplate retrieves the itinerary template of the path, sending an error response and returning nil unless it
+// belongs to the authenticated user or is shared
+func getAndValidateItineraryTemplate(context *gin.Context) *models.ItineraryTemplate {
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return nil
+	}
+
+	templateId := getPathId(context, "templateId", "itinerary template")
+	if templateId == nil {
+		return nil
+	}
+
+	template, err := services.GetItineraryTemplateService().FindById(*templateId, *userId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Itinerary template not found."})
+			return nil
+		}
+		log.Errorf("Error retrieving itinerary template %d: %v", *templateId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get itinerary template. Try again later."})
+		return nil
+	}
+
+	return template
+}
+
+// saveItineraryTemplate creates the template, answering with it or with an error if its destinations are not valid or it cannot be saved
+func saveItineraryTemplate(context *gin.Context, template *models.ItineraryTemplate) {
+	err := services.GetItineraryTemplateService().Create(template, template.OwnerID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid itinerary template: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: strings.TrimPrefix(err.Error(), "invalid itinerary template: ")})
+			return
+		}
+		log.Errorf("Error creating itinerary template: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create itinerary template. Try again later."})
+		return
+	}
+
+	log.Debugf("Itinerary template %d created for user %d", template.ID, template.OwnerID)
+	context.JSON(http.StatusCreated, &responses.CreateItineraryTemplateResponse{Message: "Itinerary template created.", Template: template})
+}
@@ -0,0 +1,191 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockItineraryTemplateService struct {
+	Template  *models.ItineraryTemplate
+	Templates []*models.ItineraryTemplate
+	Err       error
+	DeleteErr error
+	Created   *models.ItineraryTemplate
+	Deleted   *models.ItineraryTemplate
+	Itinerary *models.Itinerary
+	StartDate time.Time
+	Title     string
+	ActorId   int64
+	UserId    int64
+}
+
+func (m *mockItineraryTemplateService) FindById(_ int64, userId int64) (*models.ItineraryTemplate, error) {
+	m.UserId = userId
+	return m.Template, m.Err
+}
+func (m *mockItineraryTemplateService) FindAvailable(userId int64) ([]*models.ItineraryTemplate, error) {
+	m.UserId = userId
+	return m.Templates, m.Err
+}
+func (m *mockItineraryTemplateService) Create(template *models.ItineraryTemplate, actorId int64) error {
+	m.Created = template
+	m.ActorId = actorId
+	template.ID = 5
+	return m.Err
+}
+func (m *mockItineraryTemplateService) Delete(template *models.ItineraryTemplate, actorId int64) error {
+	m.Deleted = template
+	m.ActorId = actorId
+	return m.DeleteErr
+}
+func (m *mockItineraryTemplateService) Instantiate(_ *models.ItineraryTemplate, startDate time.Time, title string, ownerId int64) (*models.Itinerary, error) {
+	m.StartDate = startDate
+	m.Title = title
+	m.ActorId = ownerId
+	return m.Itinerary, m.Err
+}
+
+func setMockItineraryTemplateService(mock *mockItineraryTemplateService) func() {
+	orig := services.GetItineraryTemplateService
+	services.GetItineraryTemplateService = func() services.ItineraryTemplateServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryTemplateService = orig }
+}
+
+var itineraryTemplateIdParams = gin.Params{{Key: "templateId", Value: "5"}}
+
+// --- Tests ---
+
+func TestCloneItinerary_WithoutBody(t *testing.T) {
+	itineraryService := &mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2},
+		Cloned: &models.Itinerary{ID: 9, OwnerID: 1, Version: 1}}
+	defer setMockItineraryService(itineraryService)()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, "", itineraryIdParams)
+	cloneItinerary(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"itineraryId":9`)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, services.CloneOptions{}, itineraryService.CloneOptions)
+}
+
+func TestCloneItinerary_ShiftDays(t *testing.T) {
+	itineraryService := &mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1},
+		Cloned: &models.Itinerary{ID: 9, OwnerID: 1, Version: 1}}
+	defer setMockItineraryService(itineraryService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"title":"Spain again","shiftDays":365}`, itineraryIdParams)
+	cloneItinerary(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "Spain again", itineraryService.CloneOptions.Title)
+	assert.Equal(t, 365, itineraryService.CloneOptions.ShiftDays)
+}
+
+func TestCloneItinerary_ShiftDaysAndStartDate(t *testing.T) {
+	itineraryService := &mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}}
+	defer setMockItineraryService(itineraryService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"shiftDays":7,"startDate":"2026-03-01T00:00:00Z"}`, itineraryIdParams)
+	cloneItinerary(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, itineraryService.Cloned)
+}
+
+func TestCreateItineraryTemplate_Invalid(t *testing.T) {
+	defer setMockItineraryTemplateService(&mockItineraryTemplateService{
+		Err: errors.New("invalid itinerary template: the itinerary cannot span more than 30 days")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost,
+		`{"title":"Offsite","destinations":[{"country":"Portugal","city":"Lisbon","startDay":0,"days":30},{"country":"Portugal","city":"Porto","startDay":30,"days":3}]}`, nil)
+	createItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the itinerary cannot span more than 30 days")
+}
+
+func TestCreateItineraryTemplate_Success(t *testing.T) {
+	templateService := &mockItineraryTemplateService{}
+	defer setMockItineraryTemplateService(templateService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost,
+		`{"title":"Offsite","shared":true,"destinations":[{"country":"Portugal","city":"Lisbon","startDay":0,"days":3}]}`, nil)
+	createItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int64(1), templateService.Created.OwnerID)
+	assert.True(t, templateService.Created.Shared)
+	assert.Len(t, templateService.Created.Destinations, 1)
+	assert.Contains(t, w.Body.String(), `"id":5`)
+}
+
+func TestGetItineraryTemplate_NotFound(t *testing.T) {
+	defer setMockItineraryTemplateService(&mockItineraryTemplateService{Err: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryTemplateIdParams)
+	getItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteItineraryTemplate_NotOwned(t *testing.T) {
+	defer setMockItineraryTemplateService(&mockItineraryTemplateService{Template: &models.ItineraryTemplate{ID: 5, OwnerID: 2, Shared: true},
+		DeleteErr: services.ErrItineraryTemplateNotOwned})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryTemplateIdParams)
+	deleteItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeleteItineraryTemplate_Success(t *testing.T) {
+	templateService := &mockItineraryTemplateService{Template: &models.ItineraryTemplate{ID: 5, OwnerID: 1}}
+	defer setMockItineraryTemplateService(templateService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryTemplateIdParams)
+	deleteItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(5), templateService.Deleted.ID)
+	assert.Equal(t, int64(1), templateService.ActorId)
+}
+
+func TestInstantiateItineraryTemplate_Success(t *testing.T) {
+	templateService := &mockItineraryTemplateService{Template: &models.ItineraryTemplate{ID: 5, OwnerID: 2, Shared: true},
+		Itinerary: &models.Itinerary{ID: 9, OwnerID: 1, Version: 1}}
+	defer setMockItineraryTemplateService(templateService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"startDate":"2026-06-01T00:00:00Z","title":"Offsite 2026"}`, itineraryTemplateIdParams)
+	instantiateItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"itineraryId":9`)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), templateService.StartDate)
+	assert.Equal(t, "Offsite 2026", templateService.Title)
+}
+
+func TestInstantiateItineraryTemplate_MissingStartDate(t *testing.T) {
+	templateService := &mockItineraryTemplateService{Template: &models.ItineraryTemplate{ID: 5, OwnerID: 1}}
+	defer setMockItineraryTemplateService(templateService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"title":"Offsite 2026"}`, itineraryTemplateIdParams)
+	instantiateItineraryTemplate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Zero(t, templateService.ActorId)
+}
@@ -25,12 +25,19 @@ type mockItineraryService struct {
 	CreateErr              error
 	UpdateErr              error
 	DeleteErr              error
+	DeletedVersion         int64
+	UpdatedVersion         int64
 	FindByIdIt             *models.Itinerary
 	FindByIdErr            error
 	FindLightweightByIdIt  *models.Itinerary
 	FindLightweightByIdErr error
 	FindByOwner            []*models.Itinerary
 	FindByOwnerErr         error
+	ItinerariesPage        *services.ItinerariesPage
+	ItinerariesQuery       services.ItinerariesQuery
+	Cloned                 *models.Itinerary
+	CloneOptions           services.CloneOptions
+	CloneErr               error
 }
 
 func (m *mockItineraryService) ValidateItineraryDestinationsDates(_ []*models.ItineraryTravelDestination) error {
@@ -41,6 +48,11 @@ func (m *mockItineraryService) Create(_ *models.Itinerary) error {
 	return m.CreateErr
 }
 
+func (m *mockItineraryService) Clone(_ *models.Itinerary, _ int64, options services.CloneOptions) (*models.Itinerary, error) {
+	m.CloneOptions = options
+	return m.Cloned, m.CloneErr
+}
+
 func (m *mockItineraryService) FindById(_ int64, _ bool) (*models.Itinerary, error) {
 	return m.FindByIdIt, m.FindByIdErr
 }
@@ -53,11 +65,20 @@ func (m *mockItineraryService) FindByOwnerId(_ int64) ([]*models.Itinerary, erro
 	return m.FindByOwner, m.FindByOwnerErr
 }
 
-func (m *mockItineraryService) Update(_ *models.Itinerary) error {
+func (m *mockItineraryService) FindItineraries(query services.ItinerariesQuery) (*services.ItinerariesPage, error) {
+	m.ItinerariesQuery = query
+	return m.ItinerariesPage, m.FindByOwnerErr
+}
+
+func (m *mockItineraryService) Update(itinerary *models.Itinerary, _ int64) error {
+	if m.UpdateErr == nil && m.UpdatedVersion > 0 {
+		itinerary.Version = m.UpdatedVersion
+	}
 	return m.UpdateErr
 }
 
-func (m *mockItineraryService) Delete(_ int64) error {
+func (m *mockItineraryService) Delete(_ int64, version int64, _ int64) error {
+	m.DeletedVersion = version
 	return m.DeleteErr
 }
 
@@ -69,6 +90,15 @@ type mockJobsService struct {
 	GetInProgressJobsOfItineraryCountErr error
 	PrepareJobTask                       *services.ItineraryFileAsyncTaskPayload
 	PrepareJobErr                        error
+	PreparedPromptTemplateName           string
+	PreparedLanguage                     string
+	PreparedSource                       string
+	RegeneratedBaseJob                   *models.ItineraryFileJob
+	RegeneratedScope                     *services.RegenerationScope
+	RegeneratedInstructions              string
+	FindMessagesResult                   []*models.ItineraryJobMessage
+	FindMessagesErr                      error
+	SentMessage                          string
 	StopJobErr                           error
 	AddAsyncTaskIdErr                    error
 	FindByItineraryIdResult              []*models.ItineraryFileJob
@@ -82,6 +112,8 @@ type mockJobsService struct {
 	SoftDeleteErr                        error
 	SoftDeleteByItineraryId              error
 	DeleteErr                            error
+	ForceStopJobErr                      error
+	PurgeJobErr                          error
 }
 
 func (m *mockJobsService) GetInProgressJobsOfUserCount(_ int64) (int, error) {
@@ -90,7 +122,23 @@ func (m *mockJobsService) GetInProgressJobsOfUserCount(_ int64) (int, error) {
 func (m *mockJobsService) GetInProgressJobsOfItineraryCount(_ int64) (int, error) {
 	return m.GetInProgressJobsOfItineraryCountVal, m.GetInProgressJobsOfItineraryCountErr
 }
-func (m *mockJobsService) PrepareJob(_ *models.Itinerary) (*services.ItineraryFileAsyncTaskPayload, error) {
+func (m *mockJobsService) PrepareJob(_ *models.Itinerary, promptTemplateName string, language string, source string, _ int64) (*services.ItineraryFileAsyncTaskPayload, error) {
+	m.PreparedPromptTemplateName = promptTemplateName
+	m.PreparedLanguage = language
+	m.PreparedSource = source
+	return m.PrepareJobTask, m.PrepareJobErr
+}
+func (m *mockJobsService) RegenerateJob(_ *models.Itinerary, baseJob *models.ItineraryFileJob, scope *services.RegenerationScope, instructions string, _ int64) (*services.ItineraryFileAsyncTaskPayload, error) {
+	m.RegeneratedBaseJob = baseJob
+	m.RegeneratedScope = scope
+	m.RegeneratedInstructions = instructions
+	return m.PrepareJobTask, m.PrepareJobErr
+}
+func (m *mockJobsService) FindMessages(_ *models.ItineraryFileJob) ([]*models.ItineraryJobMessage, error) {
+	return m.FindMessagesResult, m.FindMessagesErr
+}
+func (m *mockJobsService) SendMessage(_ *models.Itinerary, _ *models.ItineraryFileJob, content string, _ int64) (*services.ItineraryFileAsyncTaskPayload, error) {
+	m.SentMessage = content
 	return m.PrepareJobTask, m.PrepareJobErr
 }
 func (m *mockJobsService) AddAsyncTaskId(_ string, _ *models.ItineraryFileJob) error {
@@ -109,15 +157,23 @@ func (m *mockJobsService) FindAliveByItineraryId(_ int64) ([]*models.ItineraryFi
 	return m.FindByItineraryIdResult, m.FindByItineraryIdErr
 }
 
-func (m *mockJobsService) StopJob(_ *models.ItineraryFileJob) error {
+func (m *mockJobsService) StopJob(_ *models.ItineraryFileJob, _ int64) error {
 	return m.StopJobErr
 }
 
+func (m *mockJobsService) ForceStopJob(_ *models.ItineraryFileJob, _ int64) error {
+	return m.ForceStopJobErr
+}
+
+func (m *mockJobsService) PurgeJob(_ *models.ItineraryFileJob, _ int64) error {
+	return m.PurgeJobErr
+}
+
 func (m *mockJobsService) FailJob(_ string, _ *models.ItineraryFileJob) error {
 	return nil // unused in routes
 }
 
-func (m *mockJobsService) SoftDeleteJob(_ *models.ItineraryFileJob) error {
+func (m *mockJobsService) SoftDeleteJob(_ *models.ItineraryFileJob, _ int64) error {
 	return m.SoftDeleteErr
 }
 
@@ -133,7 +189,7 @@ func (m *mockJobsService) DeleteDeadJobs(_ int) error {
 	return nil // unused in routes
 }
 
-func (m *mockJobsService) OpenItineraryJobFile(itineraryFileJob *models.ItineraryFileJob) (io.ReadSeekCloser, error) {
+func (m *mockJobsService) OpenItineraryJobFile(itineraryFileJob *models.ItineraryFileJob, _ int64) (io.ReadSeekCloser, error) {
 	return m.OpenItineraryJobFileResult, m.OpenItineraryJobFileErr
 }
 
@@ -143,6 +199,13 @@ type mockAsyncqTaskQueue struct {
 	EnqueueId  string
 }
 
+func (m *mockAsyncqTaskQueue) EnqueueDataExportJob(_ services.DataExportAsyncTaskPayload) (*string, error) {
+	if m.EnqueueErr != nil {
+		return nil, m.EnqueueErr
+	}
+	return &m.EnqueueId, nil
+}
+
 func (m *mockAsyncqTaskQueue) EnqueueItineraryFileJob(_ services.ItineraryFileAsyncTaskPayload) (*string, error) {
 	if m.EnqueueErr != nil {
 		return nil, m.EnqueueErr
@@ -619,30 +682,64 @@ func Test_getOwnersItineraries_Unauthorized(t *testing.T) {
 }
 
 func Test_getOwnersItineraries_Error(t *testing.T) {
-	orig := services.GetItineraryService
-	defer func() { services.GetItineraryService = orig }()
-	services.GetItineraryService = func() services.ItineraryServiceInterface {
-		return &mockItineraryService{FindByOwnerErr: errors.New("find error")}
-	}
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	setUserId(c, 1)
+	defer setMockItineraryService(&mockItineraryService{FindByOwnerErr: errors.New("find error")})()
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
 	getOwnersItineraries(c)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
 func Test_getOwnersItineraries_Success(t *testing.T) {
-	orig := services.GetItineraryService
-	defer func() { services.GetItineraryService = orig }()
-	services.GetItineraryService = func() services.ItineraryServiceInterface {
-		itineraries := []*models.Itinerary{{ID: 1}}
-		return &mockItineraryService{FindByOwner: itineraries}
-	}
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	setUserId(c, 1)
+	mock := &mockItineraryService{ItinerariesPage: &services.ItinerariesPage{Itineraries: []*models.Itinerary{{ID: 1}}, TotalCount: 1}}
+	defer setMockItineraryService(mock)()
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getOwnersItineraries(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"totalCount":1`)
+	assert.NotContains(t, w.Body.String(), "nextCursor")
+	assert.Equal(t, `</>; rel="first"`, w.Header().Get("Link"))
+	assert.Equal(t, int64(1), mock.ItinerariesQuery.OwnerID)
+}
+
+func Test_getOwnersItineraries_NextPage(t *testing.T) {
+	mock := &mockItineraryService{ItinerariesPage: &services.ItinerariesPage{Itineraries: []*models.Itinerary{{ID: 3}, {ID: 2}}, TotalCount: 5, NextCursor: "next"}}
+	defer setMockItineraryService(mock)()
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/itineraries?country=Spain&sort=travelDate&order=asc&limit=2&cursor=current", nil)
 	getOwnersItineraries(c)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"nextCursor":"next"`)
+	assert.Equal(t, `</api/v1/itineraries?country=Spain&limit=2&order=asc&sort=travelDate>; rel="first", `+
+		`</api/v1/itineraries?country=Spain&cursor=next&limit=2&order=asc&sort=travelDate>; rel="next"`, w.Header().Get("Link"))
+	assert.Equal(t, services.ItinerariesQuery{OwnerID: 1, Country: "Spain", Sort: "travelDate", Order: "asc", Cursor: "current", Limit: 2}, mock.ItinerariesQuery)
+}
+
+func Test_getOwnersItineraries_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		err     error
+		message string
+	}{
+		{errors.New("invalid sort field"), "The sort field must be"},
+		{errors.New("invalid sort order"), "The sort order must be"},
+		{errors.New("invalid date range"), "travel date range"},
+		{errors.New("invalid cursor"), "Invalid cursor."},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			defer setMockItineraryService(&mockItineraryService{FindByOwnerErr: test.err})()
+			c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+			getOwnersItineraries(c)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), test.message)
+		})
+	}
+}
+
+func Test_getOwnersItineraries_InvalidLimit(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{})()
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/itineraries?limit=1000", nil)
+	getOwnersItineraries(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
 func Test_getItinerary_Unauthorized(t *testing.T) {
@@ -698,6 +795,7 @@ func Test_getItinerary_UnexpectedError(t *testing.T) {
 }
 
 func Test_getItinerary_Forbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -715,7 +813,7 @@ func Test_getItinerary_Success(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
-		return &mockItineraryService{FindByIdIt: &models.Itinerary{OwnerID: 1}}
+		return &mockItineraryService{FindByIdIt: &models.Itinerary{OwnerID: 1, Version: 3}}
 	}
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -723,6 +821,7 @@ func Test_getItinerary_Success(t *testing.T) {
 	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
 	getItinerary(c)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
 }
 
 func Test_runItineraryFileJob_Unauthorized(t *testing.T) {
@@ -778,6 +877,7 @@ func Test_runItineraryFileJob_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_runItineraryFileJob_ItineraryForbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -851,6 +951,97 @@ func Test_runItineraryFileJob_PrepareJob_Error(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func Test_runItineraryFileJob_PromptTemplateNotFound(t *testing.T) {
+	origIt := services.GetItineraryService
+	defer func() { services.GetItineraryService = origIt }()
+	services.GetItineraryService = func() services.ItineraryServiceInterface {
+		return &mockItineraryService{FindByIdIt: &models.Itinerary{OwnerID: 1}}
+	}
+	jobsService := &mockJobsService{PrepareJobErr: sql.ErrNoRows}
+	origJobs := services.GetItineraryFileJobService
+	defer func() { services.GetItineraryFileJobService = origJobs }()
+	services.GetItineraryFileJobService = func() services.ItineraryFileJobServiceInterface {
+		return jobsService
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/?promptTemplate=kids&language=pt-BR", nil)
+	setUserId(c, 1)
+	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	runItineraryFileJob(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Prompt template not found.")
+	assert.Equal(t, "kids", jobsService.PreparedPromptTemplateName)
+	assert.Equal(t, "pt-BR", jobsService.PreparedLanguage)
+}
+
+func Test_runItineraryFileJob_InvalidLanguage(t *testing.T) {
+	origIt := services.GetItineraryService
+	defer func() { services.GetItineraryService = origIt }()
+	services.GetItineraryService = func() services.ItineraryServiceInterface {
+		return &mockItineraryService{FindByIdIt: &models.Itinerary{OwnerID: 1}}
+	}
+	jobsService := &mockJobsService{}
+	origJobs := services.GetItineraryFileJobService
+	defer func() { services.GetItineraryFileJobService = origJobs }()
+	services.GetItineraryFileJobService = func() services.ItineraryFileJobServiceInterface {
+		return jobsService
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/?language=123", nil)
+	setUserId(c, 1)
+	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	runItineraryFileJob(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, jobsService.PreparedLanguage)
+}
+
+func Test_runItineraryFileJob_InvalidSource(t *testing.T) {
+	origIt := services.GetItineraryService
+	defer func() { services.GetItineraryService = origIt }()
+	services.GetItineraryService = func() services.ItineraryServiceInterface {
+		return &mockItineraryService{FindByIdIt: &models.Itinerary{OwnerID: 1}}
+	}
+	jobsService := &mockJobsService{}
+	origJobs := services.GetItineraryFileJobService
+	defer func() { services.GetItineraryFileJobService = origJobs }()
+	services.GetItineraryFileJobService = func() services.ItineraryFileJobServiceInterface {
+		return jobsService
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/?source=wiki", nil)
+	setUserId(c, 1)
+	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	runItineraryFileJob(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, jobsService.PreparedSource)
+}
+
+func Test_runItineraryFileJob_EmptyPlan(t *testing.T) {
+	origIt := services.GetItineraryService
+	defer func() { services.GetItineraryService = origIt }()
+	services.GetItineraryService = func() services.ItineraryServiceInterface {
+		return &mockItineraryService{FindByIdIt: &models.Itinerary{OwnerID: 1}}
+	}
+	jobsService := &mockJobsService{PrepareJobErr: errors.New("invalid plan: the itinerary has no activities")}
+	origJobs := services.GetItineraryFileJobService
+	defer func() { services.GetItineraryFileJobService = origJobs }()
+	services.GetItineraryFileJobService = func() services.ItineraryFileJobServiceInterface {
+		return jobsService
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/?source=plan", nil)
+	setUserId(c, 1)
+	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	runItineraryFileJob(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the itinerary has no activities")
+	assert.Equal(t, models.ItineraryFileJobSourcePlan, jobsService.PreparedSource)
+}
+
 func Test_runItineraryFileJob_InitAsyncTaskQueueClient_Error(t *testing.T) {
 	origIt := services.GetItineraryService
 	defer func() { services.GetItineraryService = origIt }()
@@ -1025,6 +1216,7 @@ func Test_getAllItineraryFileJobs_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_getAllItineraryFileJobs_ItineraryForbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1175,6 +1367,7 @@ func Test_updateItinerary_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_updateItinerary_Forbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1199,6 +1392,7 @@ func Test_updateItinerary_Forbidden(t *testing.T) {
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
@@ -1208,7 +1402,7 @@ func Test_updateItinerary_ValidateItineraryDestinationsDates_Error(t *testing.T)
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
 		return &mockItineraryService{
-			FindByIdIt:  &models.Itinerary{OwnerID: 1},
+			FindByIdIt:  &models.Itinerary{OwnerID: 1, Version: 2},
 			ValidateErr: errors.New("validation error"),
 		}
 	}
@@ -1231,6 +1425,7 @@ func Test_updateItinerary_ValidateItineraryDestinationsDates_Error(t *testing.T)
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
@@ -1239,7 +1434,7 @@ func Test_updateItinerary_Update_Error(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mock := &mockItineraryService{
-		FindByIdIt: &models.Itinerary{OwnerID: 1},
+		FindByIdIt: &models.Itinerary{OwnerID: 1, Version: 2},
 		UpdateErr:  errors.New("update error"),
 	}
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1264,6 +1459,7 @@ func Test_updateItinerary_Update_Error(t *testing.T) {
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
@@ -1272,7 +1468,7 @@ func Test_updateItinerary_Success(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mock := &mockItineraryService{
-		FindByIdIt: &models.Itinerary{OwnerID: 1},
+		FindByIdIt: &models.Itinerary{OwnerID: 1, Version: 2},
 		UpdateErr:  nil,
 	}
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1298,15 +1494,77 @@ func Test_updateItinerary_Success(t *testing.T) {
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
+	updateItinerary(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+const updateItineraryBody = `{"id":1,"title":"Test","description":"Desc","destinations":[{"country":"Spain","city":"Madrid",` +
+	`"arrivalDate":"2024-07-01T00:00:00Z","departureDate":"2024-07-04T00:00:00Z"}]}`
+
+func Test_updateItinerary_MissingIfMatch(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2}})()
+
+	c, w := newAuthenticatedContext(http.MethodPut, updateItineraryBody, nil)
+	updateItinerary(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+}
+
+func Test_updateItinerary_StaleIfMatch(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 3}})()
+
+	for _, ifMatch := range []string{`"2"`, `W/"3"`, "3"} {
+		c, w := newAuthenticatedContext(http.MethodPut, updateItineraryBody, nil)
+		c.Request.Header.Set("If-Match", ifMatch)
+		updateItinerary(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, ifMatch)
+	}
+}
+
+func Test_updateItinerary_IfMatchList(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 3}})()
+
+	for _, ifMatch := range []string{`"2", "3"`, "*"} {
+		c, w := newAuthenticatedContext(http.MethodPut, updateItineraryBody, nil)
+		c.Request.Header.Set("If-Match", ifMatch)
+		updateItinerary(c)
+
+		assert.Equal(t, http.StatusOK, w.Code, ifMatch)
+	}
+}
+
+func Test_updateItinerary_VersionConflict(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{
+		FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2},
+		UpdateErr:  models.ErrItineraryVersionConflict,
+	})()
+
+	c, w := newAuthenticatedContext(http.MethodPut, updateItineraryBody, nil)
+	c.Request.Header.Set("If-Match", `"2"`)
+	updateItinerary(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func Test_updateItinerary_SetsETag(t *testing.T) {
+	itinerary := &models.Itinerary{ID: 1, OwnerID: 1, Version: 2}
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: itinerary, UpdatedVersion: 3})()
+
+	c, w := newAuthenticatedContext(http.MethodPut, updateItineraryBody, nil)
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
+
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
 }
 
 func Test_updateItinerary_SuccessEmptyDescription(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mock := &mockItineraryService{
-		FindByIdIt: &models.Itinerary{OwnerID: 1},
+		FindByIdIt: &models.Itinerary{OwnerID: 1, Version: 2},
 		UpdateErr:  nil,
 	}
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1331,6 +1589,7 @@ func Test_updateItinerary_SuccessEmptyDescription(t *testing.T) {
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
@@ -1339,7 +1598,7 @@ func Test_updateItinerary_SuccessNullNotes(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mock := &mockItineraryService{
-		FindByIdIt: &models.Itinerary{OwnerID: 1},
+		FindByIdIt: &models.Itinerary{OwnerID: 1, Version: 2},
 		UpdateErr:  nil,
 	}
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1364,6 +1623,7 @@ func Test_updateItinerary_SuccessNullNotes(t *testing.T) {
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
@@ -1372,7 +1632,7 @@ func Test_updateItinerary_SuccessEmptyNotes(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mock := &mockItineraryService{
-		FindByIdIt: &models.Itinerary{OwnerID: 1},
+		FindByIdIt: &models.Itinerary{OwnerID: 1, Version: 2},
 		UpdateErr:  nil,
 	}
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1398,6 +1658,7 @@ func Test_updateItinerary_SuccessEmptyNotes(t *testing.T) {
 	b, _ := json.Marshal(body)
 	c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(b))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
 	updateItinerary(c)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
@@ -1727,6 +1988,7 @@ func Test_deleteItinerary_FindItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_deleteItinerary_Forbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -1744,7 +2006,7 @@ func Test_deleteItinerary_Delete_InProgressItineraryJobsFoundError(t *testing.T)
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mockItineraryService := &mockItineraryService{
-		FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1},
+		FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2},
 		DeleteErr:             errors.New("delete error"),
 	}
 	mockJobsService := &mockJobsService{
@@ -1760,6 +2022,8 @@ func Test_deleteItinerary_Delete_InProgressItineraryJobsFoundError(t *testing.T)
 	c, _ := gin.CreateTestContext(w)
 	setUserId(c, 1)
 	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
 	deleteItinerary(c)
 	assert.Equal(t, http.StatusConflict, w.Code)
 }
@@ -1768,7 +2032,7 @@ func Test_deleteItinerary_Delete_InProgressItineraryJobsSqlError(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	mockItineraryService := &mockItineraryService{
-		FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1},
+		FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2},
 		DeleteErr:             errors.New("delete error"),
 	}
 	mockJobsService := &mockJobsService{
@@ -1784,6 +2048,8 @@ func Test_deleteItinerary_Delete_InProgressItineraryJobsSqlError(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	setUserId(c, 1)
 	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
 	deleteItinerary(c)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
@@ -1792,7 +2058,7 @@ func Test_deleteItinerary_Success(t *testing.T) {
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
-		return &mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}}
+		return &mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2}}
 	}
 	mockJobsService := &mockJobsService{
 		GetInProgressJobsOfItineraryCountVal: 0,
@@ -1804,9 +2070,51 @@ func Test_deleteItinerary_Success(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	setUserId(c, 1)
 	c.Params = gin.Params{{Key: "itineraryId", Value: "1"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
 	deleteItinerary(c)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func Test_deleteItinerary_MissingIfMatch(t *testing.T) {
+	itineraryService := &mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2}}
+	defer setMockItineraryService(itineraryService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryIdParams)
+	deleteItinerary(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Zero(t, itineraryService.DeletedVersion)
+}
+
+func Test_deleteItinerary_StaleIfMatch(t *testing.T) {
+	itineraryService := &mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2}}
+	defer setMockItineraryService(itineraryService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryIdParams)
+	c.Request.Header.Set("If-Match", `"1"`)
+	deleteItinerary(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Zero(t, itineraryService.DeletedVersion)
+}
+
+func Test_deleteItinerary_VersionConflict(t *testing.T) {
+	itineraryService := &mockItineraryService{
+		FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1, Version: 2},
+		DeleteErr:             models.ErrItineraryVersionConflict,
+	}
+	defer setMockItineraryService(itineraryService)()
+	defer setMockJobsService(&mockJobsService{})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryIdParams)
+	c.Request.Header.Set("If-Match", `"2"`)
+	deleteItinerary(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, int64(2), itineraryService.DeletedVersion)
+}
+
 func Test_getItineraryJob_Unauthorized(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -1883,6 +2191,7 @@ func Test_getItineraryJob_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_getItineraryJob_ItineraryForbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -2071,6 +2380,7 @@ func Test_deleteItineraryJob_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_deleteItineraryJob_ItineraryForbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -2333,6 +2643,7 @@ func Test_stopItineraryJob_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_stopItineraryJob_ItineraryForbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -2547,6 +2858,7 @@ func Test_downloadItineraryJobFile_ItineraryUnexpectedError(t *testing.T) {
 }
 
 func Test_downloadItineraryJobFile_ItineraryForbidden(t *testing.T) {
+	defer setMockPermissionService(&mockPermissionService{})()
 	orig := services.GetItineraryService
 	defer func() { services.GetItineraryService = orig }()
 	services.GetItineraryService = func() services.ItineraryServiceInterface {
@@ -2770,7 +3082,94 @@ func Test_downloadItineraryJobFile_FileStat_Error(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func Test_downloadItineraryJobFile_ContentLanguage(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "itinerary-*.txt")
+	assert.NoError(t, err)
+	_, err = file.WriteString("Día 1: Madrid")
+	assert.NoError(t, err)
+
+	origIt := services.GetItineraryService
+	defer func() { services.GetItineraryService = origIt }()
+	services.GetItineraryService = func() services.ItineraryServiceInterface {
+		return &mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}}
+	}
+	origJobs := services.GetItineraryFileJobService
+	defer func() { services.GetItineraryFileJobService = origJobs }()
+	services.GetItineraryFileJobService = func() services.ItineraryFileJobServiceInterface {
+		return &mockJobsService{
+			FindAliveByIdResult:        &models.ItineraryFileJob{ID: 2, ItineraryID: 1, Filepath: file.Name(), Language: "es"},
+			OpenItineraryJobFileResult: file,
+		}
+	}
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "itineraryJobId", Value: "2"}})
+	downloadItineraryJobFile(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "es", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Día 1: Madrid", w.Body.String())
+}
+
 // Note: It is not possible to unit test the success case for downloadItineraryJobFile
 // in a pure unit test, because http.ServeContent writes directly to the http.ResponseWriter
 // and expects an *os.File for Stat(). Mocking *os.File is not feasible in Go.
 // Integration tests with a real file are required for a true success case.
+
+func newPatchItinerary() *models.Itinerary {
+	notes := "Nightlife"
+	return &models.Itinerary{ID: 1, OwnerID: 1, Version: 2, Title: "Spain", Description: "Summer", Notes: &notes,
+		TravelDestinations: []*models.ItineraryTravelDestination{{ID: 10, Country: "Spain", City: "Madrid",
+			ArrivalDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)}}}
+}
+
+func TestPatchItinerary_Success(t *testing.T) {
+	itinerary := newPatchItinerary()
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: itinerary, UpdatedVersion: 3})()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"title":"Spain trip","notes":null}`, itineraryIdParams)
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Request.Header.Set("If-Match", `"2"`)
+	patchItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Equal(t, "Spain trip", itinerary.Title)
+	assert.Equal(t, "Summer", itinerary.Description)
+	assert.Nil(t, itinerary.Notes)
+	assert.Len(t, itinerary.TravelDestinations, 1)
+	assert.Equal(t, "Madrid", itinerary.TravelDestinations[0].City)
+}
+
+func TestPatchItinerary_InvalidPatch(t *testing.T) {
+	for _, patch := range []string{`{"title":null}`, `{"destinations":[]}`, `{"id":2}`, `{"title":`} {
+		restore := setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})
+
+		c, w := newAuthenticatedContext(http.MethodPatch, patch, itineraryIdParams)
+		c.Request.Header.Set("If-Match", `"2"`)
+		patchItinerary(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, patch)
+	}
+}
+
+func TestPatchItinerary_UnsupportedMediaType(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `title=Spain`, itineraryIdParams)
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Request.Header.Set("If-Match", `"2"`)
+	patchItinerary(c)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestPatchItinerary_VersionMismatch(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: newPatchItinerary()})()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"title":"Spain trip"}`, itineraryIdParams)
+	c.Request.Header.Set("If-Match", `"1"`)
+	patchItinerary(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
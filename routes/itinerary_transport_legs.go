@@ -0,0 +1,190 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getItineraryTransportLegs godoc
+// @Summary      Get the transport legs of an itinerary
+// @Description  Gets how the travellers get from each destination of an itinerary to the next one, in travel order. The legs whose cities are no longer consecutive destinations go last, without fromDestinationId and toDestinationId. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.GetTransportLegsResponse  "Transport legs"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get itinerary. Try again later."
+// @Router       /itineraries/{itineraryId}/transport-legs [get]
+func getItineraryTransportLegs(context *gin.Context) {
+	log.Debug("Retrieving itinerary transport legs")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	transportLegs := itinerary.TransportLegs
+	if transportLegs == nil {
+		transportLegs = []*models.ItineraryTransportLeg{}
+	}
+
+	context.JSON(http.StatusOK, &responses.GetTransportLegsResponse{TransportLegs: transportLegs})
+}
+
+// addItineraryTransportLeg godoc
+// @Summary      Add a transport leg to an itinerary
+// @Description  Adds how the travellers get from a destination of an itinerary to the next one. The leg must depart between the arrival in and the departure from the destination it goes from, and arrive at the latest on the departure date from the next one. The leg belongs to the cities it connects, so it is kept when the destinations of the itinerary are replaced. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId   path  int                           true  "Itinerary ID"
+// @Param        transportLeg  body  requests.TransportLegRequest  true  "Transport leg"
+// @Success      201  {object}  responses.CreateTransportLegResponse  "Transport leg added."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid transport leg."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or destination not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not add transport leg. Try again later."
+// @Router       /itineraries/{itineraryId}/transport-legs [post]
+func addItineraryTransportLeg(context *gin.Context) {
+	log.Debug("Adding itinerary transport leg")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.TransportLegRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The destination and a mode among flight, train, bus, car, ferry and other are required."})
+		return
+	}
+
+	leg := newTransportLeg(&input)
+	err := services.GetItineraryTransportLegService().Add(itinerary, leg, input.FromDestinationID, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error adding transport leg to itinerary %d: %v", itinerary.ID, err)
+		handleTransportLegError(context, err, "Destination not found.", "Could not add transport leg. Try again later.")
+		return
+	}
+
+	log.Debugf("Transport leg %d added to itinerary %d", leg.ID, itinerary.ID)
+	context.JSON(http.StatusCreated, &responses.CreateTransportLegResponse{Message: "Transport leg added.", TransportLeg: leg})
+}
+
+// updateItineraryTransportLeg godoc
+// @Summary      Update a transport leg of an itinerary
+// @Description  Replaces a transport leg of an itinerary, which is validated like a new one. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int                           true  "Itinerary ID"
+// @Param        transportLegId  path  int                           true  "Transport leg ID"
+// @Param        transportLeg    body  requests.TransportLegRequest  true  "Transport leg"
+// @Success      200  {object}  responses.UpdateTransportLegResponse  "Transport leg updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid transport leg."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary, transport leg or destination not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update transport leg. Try again later."
+// @Router       /itineraries/{itineraryId}/transport-legs/{transportLegId} [put]
+func updateItineraryTransportLeg(context *gin.Context) {
+	log.Debug("Updating itinerary transport leg")
+
+	itinerary := getAndValidateItinerary(context, true, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	legId := getPathId(context, "transportLegId", "transport leg")
+	if legId == nil {
+		return
+	}
+
+	var input requests.TransportLegRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The destination and a mode among flight, train, bus, car, ferry and other are required."})
+		return
+	}
+
+	leg := newTransportLeg(&input)
+	leg.ID = *legId
+	err := services.GetItineraryTransportLegService().Update(itinerary, leg, input.FromDestinationID, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating transport leg %d of itinerary %d: %v", *legId, itinerary.ID, err)
+		handleTransportLegError(context, err, "Transport leg or destination not found.", "Could not update transport leg. Try again later.")
+		return
+	}
+
+	log.Debugf("Transport leg %d of itinerary %d updated", leg.ID, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.UpdateTransportLegResponse{Message: "Transport leg updated.", TransportLeg: leg})
+}
+
+// deleteItineraryTransportLeg godoc
+// @Summary      Remove a transport leg from an itinerary
+// @Description  Removes a transport leg of an itinerary. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId     path  int  true  "Itinerary ID"
+// @Param        transportLegId  path  int  true  "Transport leg ID"
+// @Success      200  {object}  responses.DeleteTransportLegResponse  "Transport leg removed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or transport leg not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not remove transport leg. Try again later."
+// @Router       /itineraries/{itineraryId}/transport-legs/{transportLegId} [delete]
+func deleteItineraryTransportLeg(context *gin.Context) {
+	log.Debug("Deleting itinerary transport leg")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	legId := getPathId(context, "transportLegId", "transport leg")
+	if legId == nil {
+		return
+	}
+
+	err := services.GetItineraryTransportLegService().Delete(itinerary, *legId, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting transport leg %d of itinerary %d: %v", *legId, itinerary.ID, err)
+		handleTransportLegError(context, err, "Transport leg not found.", "Could not remove transport leg. Try again later.")
+		return
+	}
+
+	log.Debugf("Transport leg %d of itinerary %d removed", *legId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.DeleteTransportLegResponse{Message: "Transport leg removed."})
+}
+
+func newTransportLeg(input *requests.TransportLegRequest) *models.ItineraryTransportLeg {
+	return &models.ItineraryTransportLeg{Mode: input.Mode, Carrier: input.Carrier, BookingReference: input.BookingReference,
+		DepartureTime: input.DepartureTime, ArrivalTime: input.ArrivalTime, Notes: input.Notes}
+}
+
+func handleTransportLegError(context *gin.Context, err error, notFoundMessage string, internalErrorMessage string) {
+	switch {
+	case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: notFoundMessage})
+	case strings.HasPrefix(err.Error(), "invalid transport leg: "):
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+	default:
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: internalErrorMessage})
+	}
+}
@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockItineraryTransportLegService struct {
+	Err               error
+	Leg               *models.ItineraryTransportLeg
+	FromDestinationId int64
+	DeletedId         int64
+}
+
+func (m *mockItineraryTransportLegService) Add(_ *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64, _ int64) error {
+	m.Leg = leg
+	m.FromDestinationId = fromDestinationId
+	return m.Err
+}
+func (m *mockItineraryTransportLegService) Update(_ *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64, _ int64) error {
+	m.Leg = leg
+	m.FromDestinationId = fromDestinationId
+	return m.Err
+}
+func (m *mockItineraryTransportLegService) Delete(_ *models.Itinerary, legId int64, _ int64) error {
+	m.DeletedId = legId
+	return m.Err
+}
+
+func setMockItineraryTransportLegService(mock *mockItineraryTransportLegService) func() {
+	orig := services.GetItineraryTransportLegService
+	services.GetItineraryTransportLegService = func() services.ItineraryTransportLegServiceInterface {
+		return mock
+	}
+	return func() { services.GetItineraryTransportLegService = orig }
+}
+
+var itineraryTransportLegParams = gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "transportLegId", Value: "7"}}
+
+// --- Tests ---
+
+func TestGetItineraryTransportLegs_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2,
+		TransportLegs: []*models.ItineraryTransportLeg{{ID: 7, FromCity: "Madrid", ToCity: "Seville", Mode: models.TransportModeTrain}}}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryTransportLegs(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"mode":"train"`)
+}
+
+func TestGetItineraryTransportLegs_Empty(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryTransportLegs(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"transportLegs":[]}`, w.Body.String())
+}
+
+func TestAddItineraryTransportLeg_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	legService := &mockItineraryTransportLegService{}
+	defer setMockItineraryTransportLegService(legService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost,
+		`{"fromDestinationId":10,"mode":"train","carrier":"Renfe","departureTime":"2024-07-05T09:30:00+02:00"}`, itineraryIdParams)
+	addItineraryTransportLeg(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int64(10), legService.FromDestinationId)
+	assert.Equal(t, "Renfe", legService.Leg.Carrier)
+	assert.Equal(t, 9, legService.Leg.DepartureTime.Hour())
+}
+
+func TestAddItineraryTransportLeg_InvalidBody(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	for _, body := range []string{`{"mode":"train"}`, `{"fromDestinationId":10,"mode":"teleport"}`, `{"fromDestinationId":10}`} {
+		legService := &mockItineraryTransportLegService{}
+		restore := setMockItineraryTransportLegService(legService)
+
+		c, w := newAuthenticatedContext(http.MethodPost, body, itineraryIdParams)
+		addItineraryTransportLeg(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, legService.Leg, body)
+	}
+}
+
+func TestAddItineraryTransportLeg_InvalidDates(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockItineraryTransportLegService(&mockItineraryTransportLegService{
+		Err: errors.New("invalid transport leg: the departure must be before the arrival")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"fromDestinationId":10,"mode":"bus"}`, itineraryIdParams)
+	addItineraryTransportLeg(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "the departure must be before the arrival")
+}
+
+func TestAddItineraryTransportLeg_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	legService := &mockItineraryTransportLegService{}
+	defer setMockItineraryTransportLegService(legService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"fromDestinationId":10,"mode":"bus"}`, itineraryIdParams)
+	addItineraryTransportLeg(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, legService.Leg)
+}
+
+func TestUpdateItineraryTransportLeg_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	legService := &mockItineraryTransportLegService{Err: sql.ErrNoRows}
+	defer setMockItineraryTransportLegService(legService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"fromDestinationId":10,"mode":"ferry"}`, itineraryTransportLegParams)
+	updateItineraryTransportLeg(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, int64(7), legService.Leg.ID)
+}
+
+func TestDeleteItineraryTransportLeg_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	legService := &mockItineraryTransportLegService{}
+	defer setMockItineraryTransportLegService(legService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryTransportLegParams)
+	deleteItineraryTransportLeg(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(7), legService.DeletedId)
+}
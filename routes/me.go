@@ -0,0 +1,275 @@
+package routes
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"example.com/travel-advisor/utils"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getMe godoc
+// @Summary      Get the authenticated user
+// @Description  Retrieves the profile of the authenticated user.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetMeResponse  "User profile"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "User not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get user. Try again later."
+// @Router       /me [get]
+func getMe(context *gin.Context) {
+	log.Debug("Retrieving authenticated user")
+
+	user := getAuthenticatedUser(context)
+	if user == nil {
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetMeResponse{User: user})
+}
+
+// updateMe godoc
+// @Summary      Update the authenticated user
+// @Description  Updates the profile of the authenticated user. Only the attributes present in the request are changed. The email must be valid and not used by another account.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        user  body  requests.UpdateMeRequest  true  "Profile attributes to update"
+// @Success      200  {object}  responses.UpdateMeResponse  "Profile updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid email."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "User not found."
+// @Failure      409  {object}  responses.ErrorResponse  "The email is already in use."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update user. Try again later."
+// @Router       /me [patch]
+func updateMe(context *gin.Context) {
+	log.Debug("Updating authenticated user")
+
+	var input requests.UpdateMeRequest
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	// Bind JSON input to the input struct
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. At least one of the expected attributes is too large."})
+		return
+	}
+
+	if input.Email != nil {
+		err := services.GetUserService().UpdateEmail(*userId, *input.Email)
+		if err != nil {
+			switch {
+			case strings.Contains(err.Error(), "invalid email"):
+				context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The provided email is empty or invalid."})
+			case strings.Contains(err.Error(), "email already in use"):
+				context.JSON(http.StatusConflict, &responses.ErrorResponse{Message: "The email is already in use."})
+			case strings.Contains(err.Error(), sql.ErrNoRows.Error()):
+				context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "User not found."})
+			default:
+				log.Errorf("Error updating email of user %d: %v", *userId, err)
+				context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not update user. Try again later."})
+			}
+			return
+		}
+	}
+
+	user := getAuthenticatedUser(context)
+	if user == nil {
+		return
+	}
+
+	log.Debugf("User %d updated their profile", *userId)
+	context.JSON(http.StatusOK, &responses.UpdateMeResponse{Message: "Profile updated.", User: user})
+}
+
+// changeMyPassword godoc
+// @Summary      Change the password of the authenticated user
+// @Description  Replaces the password of the authenticated user. The current password is required to confirm the change, and wrong attempts count towards the login brute-force protection. The new password must follow the same rules as on sign up.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        passwords  body  requests.ChangePasswordRequest  true  "Current and new password"
+// @Success      200  {object}  responses.ChangePasswordResponse  "Password changed."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or the new password is invalid."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized or wrong current password."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      429  {object}  responses.ErrorResponse  "Too many failed login attempts. Try again later."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not change password. Try again later."
+// @Router       /me/password [post]
+func changeMyPassword(context *gin.Context) {
+	log.Debug("Changing password of authenticated user")
+
+	var input requests.ChangePasswordRequest
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	// Bind JSON input to the input struct
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. One or more mandatory attributes are null/empty or at least one of the expected attributes is too large."})
+		return
+	}
+
+	minPasswordLength, err := getMinPasswordLength()
+	if err != nil {
+		log.Errorf("Unexpected error reading min user password length in environment properties")
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not change password. Try again later."})
+		return
+	}
+
+	if !utils.ValidatePassword(input.NewPassword, minPasswordLength) {
+		log.Errorf("The new password of user %d is invalid", *userId)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: invalidPasswordMessage(minPasswordLength)})
+		return
+	}
+
+	user := getAuthenticatedUser(context)
+	if user == nil {
+		return
+	}
+
+	if !reauthenticateUser(context, user, input.CurrentPassword) {
+		return
+	}
+
+	err = services.GetUserService().UpdatePassword(user.ID, input.NewPassword)
+	if err != nil {
+		log.Errorf("Error changing password of user %d: %v", user.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not change password. Try again later."})
+		return
+	}
+
+	log.Debugf("User %d changed their password", user.ID)
+	context.JSON(http.StatusOK, &responses.ChangePasswordResponse{Message: "Password changed."})
+}
+
+// deleteMe godoc
+// @Summary      Delete the authenticated user
+// @Description  Permanently deletes the account of the authenticated user together with all their itineraries, destinations and API keys. Generated itinerary files are removed shortly after by the dead jobs cleanup. The password is required to confirm the deletion.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        confirmation  body  requests.DeleteMeRequest  true  "Password confirmation"
+// @Success      200  {object}  responses.DeleteMeResponse  "Account deleted."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized or wrong password."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      429  {object}  responses.ErrorResponse  "Too many failed login attempts. Try again later."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not delete account. Try again later."
+// @Router       /me [delete]
+func deleteMe(context *gin.Context) {
+	log.Debug("Deleting authenticated user")
+
+	var input requests.DeleteMeRequest
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	// Bind JSON input to the input struct
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The password is required to delete the account."})
+		return
+	}
+
+	user := getAuthenticatedUser(context)
+	if user == nil {
+		return
+	}
+
+	if !reauthenticateUser(context, user, input.Password) {
+		return
+	}
+
+	err := services.GetUserService().Delete(user.ID)
+	if err != nil {
+		log.Errorf("Error deleting user %d: %v", user.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete account. Try again later."})
+		return
+	}
+
+	log.Infof("User %d deleted their account", user.ID)
+	context.JSON(http.StatusOK, &responses.DeleteMeResponse{Message: "Account deleted."})
+}
+
+func getAuthenticatedUser(context *gin.Context) *models.User {
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return nil
+	}
+
+	user, err := services.GetUserService().FindById(*userId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			log.Errorf("User %d not found", *userId)
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "User not found."})
+		} else {
+			log.Errorf("Error retrieving user %d: %v", *userId, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get user. Try again later."})
+		}
+		return nil
+	}
+
+	return user
+}
+
+// reauthenticateUser checks the password of an already authenticated user before a sensitive change. Wrong passwords are throttled
+// like failed logins, so a stolen token cannot be used to guess the password
+func reauthenticateUser(context *gin.Context, user *models.User, password string) bool {
+	loginThrottleService := services.GetLoginThrottleService()
+
+	retryAfter, err := loginThrottleService.CheckLoginAllowed(user.Email, context.ClientIP())
+	if err != nil {
+		log.Errorf("Error checking login attempts: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Unexpected error. Try again later."})
+		return false
+	}
+	if retryAfter > 0 {
+		log.Warnf("Re-authentication of user %d from %s rejected by brute-force protection", user.ID, context.ClientIP())
+		context.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		context.JSON(http.StatusTooManyRequests, &responses.ErrorResponse{Message: "Too many failed login attempts. Try again later."})
+		return false
+	}
+
+	err = services.GetUserService().ValidateCredentials(user, password)
+	if err != nil {
+		log.Errorf("Error re-authenticating user %d: %v", user.ID, err)
+		err = loginThrottleService.RegisterFailedLogin(user.Email, context.ClientIP())
+		if err != nil {
+			log.Errorf("Error registering failed login attempt: %v", err)
+		}
+		context.JSON(http.StatusUnauthorized, &responses.ErrorResponse{Message: "Wrong password."})
+		return false
+	}
+
+	err = loginThrottleService.ResetFailedLogins(user.Email)
+	if err != nil {
+		log.Errorf("Error resetting failed login attempts: %v", err)
+	}
+
+	return true
+}
@@ -0,0 +1,220 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockMeUserService() *mockUserService {
+	return &mockUserService{
+		findByIdFunc: func(id int64) (*models.User, error) {
+			return &models.User{ID: id, Email: "test@example.com", Role: models.RoleUser}, nil
+		},
+		validateCredentialsFunc: func(user *models.User, password string) error {
+			if password != "Password123-" {
+				return errors.New("invalid user credentials")
+			}
+			return nil
+		},
+	}
+}
+
+func TestGetMe_Success(t *testing.T) {
+	restore := setMockUserService(mockMeUserService())
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "test@example.com")
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestGetMe_NotFound(t *testing.T) {
+	restore := setMockUserService(&mockUserService{
+		findByIdFunc: func(id int64) (*models.User, error) { return nil, sql.ErrNoRows },
+	})
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getMe(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateMe_Success(t *testing.T) {
+	mockSvc := mockMeUserService()
+	var updatedEmail string
+	mockSvc.updateEmailFunc = func(userId int64, email string) error {
+		updatedEmail = email
+		return nil
+	}
+	restore := setMockUserService(mockSvc)
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"email":"new@example.com"}`, nil)
+	updateMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "new@example.com", updatedEmail)
+}
+
+func TestUpdateMe_NoChanges(t *testing.T) {
+	restore := setMockUserService(mockMeUserService())
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{}`, nil)
+	updateMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateMe_InvalidEmail(t *testing.T) {
+	mockSvc := mockMeUserService()
+	mockSvc.updateEmailFunc = func(userId int64, email string) error { return errors.New("invalid email") }
+	restore := setMockUserService(mockSvc)
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"email":"wrong"}`, nil)
+	updateMe(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateMe_EmailInUse(t *testing.T) {
+	mockSvc := mockMeUserService()
+	mockSvc.updateEmailFunc = func(userId int64, email string) error { return errors.New("email already in use") }
+	restore := setMockUserService(mockSvc)
+	defer restore()
+
+	c, w := newAuthenticatedContext(http.MethodPatch, `{"email":"taken@example.com"}`, nil)
+	updateMe(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestChangeMyPassword_Success(t *testing.T) {
+	mockSvc := mockMeUserService()
+	var updatedPassword string
+	mockSvc.updatePasswordFunc = func(userId int64, password string) error {
+		updatedPassword = password
+		return nil
+	}
+	restore := setMockUserService(mockSvc)
+	defer restore()
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"currentPassword":"Password123-","newPassword":"NewPassword123-"}`, nil)
+	changeMyPassword(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "NewPassword123-", updatedPassword)
+}
+
+func TestChangeMyPassword_InvalidNewPassword(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodPost, `{"currentPassword":"Password123-","newPassword":"weak"}`, nil)
+	changeMyPassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestChangeMyPassword_WrongCurrentPassword(t *testing.T) {
+	restore := setMockUserService(mockMeUserService())
+	defer restore()
+	failures := 0
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		registerFailedLoginFunc: func(email string, sourceIp string) error {
+			failures++
+			return nil
+		},
+	})
+	defer restoreThrottle()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"currentPassword":"Wrong123-","newPassword":"NewPassword123-"}`, nil)
+	changeMyPassword(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, 1, failures)
+}
+
+func TestChangeMyPassword_Throttled(t *testing.T) {
+	restore := setMockUserService(mockMeUserService())
+	defer restore()
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		checkLoginAllowedFunc: func(email string, sourceIp string) (time.Duration, error) { return time.Minute, nil },
+	})
+	defer restoreThrottle()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"currentPassword":"Password123-","newPassword":"NewPassword123-"}`, nil)
+	changeMyPassword(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestDeleteMe_Success(t *testing.T) {
+	mockSvc := mockMeUserService()
+	var deletedId int64
+	mockSvc.deleteFunc = func(userId int64) error {
+		deletedId = userId
+		return nil
+	}
+	restore := setMockUserService(mockSvc)
+	defer restore()
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, `{"password":"Password123-"}`, nil)
+	deleteMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), deletedId)
+}
+
+func TestDeleteMe_MissingPassword(t *testing.T) {
+	c, w := newAuthenticatedContext(http.MethodDelete, `{}`, nil)
+	deleteMe(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteMe_WrongPassword(t *testing.T) {
+	mockSvc := mockMeUserService()
+	deleted := false
+	mockSvc.deleteFunc = func(userId int64) error {
+		deleted = true
+		return nil
+	}
+	restore := setMockUserService(mockSvc)
+	defer restore()
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, `{"password":"Wrong123-"}`, nil)
+	deleteMe(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, deleted)
+}
+
+func TestDeleteMe_Error(t *testing.T) {
+	mockSvc := mockMeUserService()
+	mockSvc.deleteFunc = func(userId int64) error { return errors.New("db error") }
+	restore := setMockUserService(mockSvc)
+	defer restore()
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, `{"password":"Password123-"}`, nil)
+	deleteMe(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// suggestPlaces godoc
+// @Summary      Suggest places
+// @Description  Suggests the cities of the offline gazetteer destinations are normalized against, to autocomplete the city and country of a destination. Cities match when their name, an alternate name or a word of them starts with q, ignoring case and diacritics. Cities named as q go first, then the most populous ones. Each place has the normalized city and country names, the ISO 3166-1 alpha-2 code of the country, the coordinates and the IANA time zone.
+// @Tags         places
+// @Produce      json
+// @Security     Auth
+// @Param        q        query  string  true   "Beginning of the name of the city"
+// @Param        country  query  string  false  "Code or name of the country of the cities"
+// @Param        limit    query  int     false  "Maximum number of places (1-50, default 10)"
+// @Success      200  {object}  responses.SuggestPlacesResponse  "Suggested places"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid query or unknown country."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not suggest places. Try again later."
+// @Router       /places/suggest [get]
+func suggestPlaces(context *gin.Context) {
+	log.Debug("Suggesting places")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	var input requests.SuggestPlacesRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing place suggestion query: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The query is required and the limit must be between 1 and 50."})
+		return
+	}
+
+	places, err := services.GetPlaceService().Suggest(input.Q, input.Country, input.Limit)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid place query: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: strings.TrimPrefix(err.Error(), "invalid place query: ")})
+			return
+		}
+		log.Errorf("Error suggesting places for user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not suggest places. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.SuggestPlacesResponse{Places: places})
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockPlaceService struct {
+	Places     []*models.Place
+	SuggestErr error
+	Query      string
+	Country    string
+	Limit      int
+}
+
+func (m *mockPlaceService) Suggest(query string, country string, limit int) ([]*models.Place, error) {
+	m.Query, m.Country, m.Limit = query, country, limit
+	return m.Places, m.SuggestErr
+}
+
+func setMockPlaceService(mock *mockPlaceService) func() {
+	orig := services.GetPlaceService
+	services.GetPlaceService = func() services.PlaceServiceInterface {
+		return mock
+	}
+	return func() { services.GetPlaceService = orig }
+}
+
+// --- Tests ---
+
+func TestSuggestPlaces_Success(t *testing.T) {
+	placeService := &mockPlaceService{Places: []*models.Place{
+		{City: "Madrid", Country: "Spain", CountryCode: "ES", Latitude: 40.4165, Longitude: -3.70256, TimeZone: "Europe/Madrid"},
+	}}
+	defer setMockPlaceService(placeService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/places/suggest?q=mad&country=spain&limit=5", nil)
+	suggestPlaces(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"countryCode":"ES"`)
+	assert.Contains(t, w.Body.String(), `"timeZone":"Europe/Madrid"`)
+	assert.Equal(t, "mad", placeService.Query)
+	assert.Equal(t, "spain", placeService.Country)
+	assert.Equal(t, 5, placeService.Limit)
+}
+
+func TestSuggestPlaces_InvalidQuery(t *testing.T) {
+	for _, url := range []string{"/api/v1/places/suggest", "/api/v1/places/suggest?q=mad&limit=51"} {
+		defer setMockPlaceService(&mockPlaceService{})()
+
+		c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+		c.Request = httptest.NewRequest(http.MethodGet, url, nil)
+		suggestPlaces(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
+func TestSuggestPlaces_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("invalid place query: unknown country Atlantis"), http.StatusBadRequest},
+		{errors.New("failed to suggest places"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			defer setMockPlaceService(&mockPlaceService{SuggestErr: test.err})()
+
+			c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/places/suggest?q=mad&country=atlantis", nil)
+			suggestPlaces(c)
+
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+}
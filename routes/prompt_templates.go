@@ -0,0 +1,301 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+)
+
+// getGlobalPromptTemplates godoc
+// @Summary      List the global prompt templates
+// @Description  Retrieves the latest version of every global prompt template, managed by the administrators. Itineraries are generated with the template named itinerary unless another one is asked for, and with the built-in one if there is no such template.
+// @Tags         prompt-templates
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetPromptTemplatesResponse  "Global prompt templates"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get prompt templates. Try again later."
+// @Router       /prompt-templates [get]
+func getGlobalPromptTemplates(context *gin.Context) {
+	log.Debug("Retrieving global prompt templates")
+	listPromptTemplates(context, nil)
+}
+
+// getPromptTemplate godoc
+// @Summary      Get a version of a prompt template
+// @Description  Retrieves a version of a prompt template, like the one an itinerary file job was generated with, even if the template was deleted since. The template must be global or owned by the authenticated user.
+// @Tags         prompt-templates
+// @Produce      json
+// @Security     Auth
+// @Param        promptTemplateId  path  int  true  "Prompt template version ID"
+// @Success      200  {object}  responses.GetPromptTemplateResponse  "Prompt template version"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid prompt template ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Prompt template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get prompt template. Try again later."
+// @Router       /prompt-templates/{promptTemplateId} [get]
+func getPromptTemplate(context *gin.Context) {
+	log.Debug("Retrieving prompt template version")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	promptTemplateId := getPathId(context, "promptTemplateId", "prompt template")
+	if promptTemplateId == nil {
+		return
+	}
+
+	promptTemplate, err := services.GetPromptTemplateService().FindById(*promptTemplateId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Prompt template not found."})
+			return
+		}
+		log.Errorf("Error retrieving prompt template %d: %v", *promptTemplateId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get prompt template. Try again later."})
+		return
+	}
+
+	// The templates of other users are not disclosed
+	if promptTemplate.OwnerID != nil && *promptTemplate.OwnerID != *userId {
+		context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Prompt template not found."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetPromptTemplateResponse{PromptTemplate: promptTemplate})
+}
+
+// getMyPromptTemplates godoc
+// @Summary      List the prompt templates of the authenticated user
+// @Description  Retrieves the latest version of every prompt template of the authenticated user. A template of the user is used instead of the global one with the same name.
+// @Tags         prompt-templates
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetPromptTemplatesResponse  "Prompt templates of the user"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get prompt templates. Try again later."
+// @Router       /me/prompt-templates [get]
+func getMyPromptTemplates(context *gin.Context) {
+	log.Debug("Retrieving prompt templates of authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	listPromptTemplates(context, userId)
+}
+
+// createMyPromptTemplate godoc
+// @Summary      Save a prompt template of the authenticated user
+// @Description  Saves a new version of a prompt template of the authenticated user, the first one if there is no template with the name. The template is a Go template which can only use the variables title, description, notes, ownerId, travelDestinations (with country, city, arrivalDate, departureDate, and localArrivalDate and localDepartureDate formatted for the locale of the language), interests, travellerProfile, language and languageName, and the built-in functions of Go templates.
+// @Tags         prompt-templates
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        promptTemplate  body  requests.PromptTemplateRequest  true  "Prompt template"
+// @Success      201  {object}  responses.CreatePromptTemplateResponse  "Prompt template saved."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid template."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not save prompt template. Try again later."
+// @Router       /me/prompt-templates [post]
+func createMyPromptTemplate(context *gin.Context) {
+	log.Debug("Saving prompt template of authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	savePromptTemplate(context, userId, *userId)
+}
+
+// getMyPromptTemplateVersions godoc
+// @Summary      List the versions of a prompt template of the authenticated user
+// @Description  Retrieves the versions of a prompt template of the authenticated user, from the newest.
+// @Tags         prompt-templates
+// @Produce      json
+// @Security     Auth
+// @Param        name  path  string  true  "Prompt template name"
+// @Success      200  {object}  responses.GetPromptTemplatesResponse  "Versions of the prompt template"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Prompt template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get prompt template. Try again later."
+// @Router       /me/prompt-templates/{name} [get]
+func getMyPromptTemplateVersions(context *gin.Context) {
+	log.Debug("Retrieving prompt template versions of authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	listPromptTemplateVersions(context, userId)
+}
+
+// deleteMyPromptTemplate godoc
+// @Summary      Delete a prompt template of the authenticated user
+// @Description  Deletes all the versions of a prompt template of the authenticated user, so the global template with the same name, if any, is used again. The versions stay available for the jobs generated with them.
+// @Tags         prompt-templates
+// @Produce      json
+// @Security     Auth
+// @Param        name  path  string  true  "Prompt template name"
+// @Success      200  {object}  responses.DeletePromptTemplateResponse  "Prompt template deleted."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Prompt template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not delete prompt template. Try again later."
+// @Router       /me/prompt-templates/{name} [delete]
+func deleteMyPromptTemplate(context *gin.Context) {
+	log.Debug("Deleting prompt template of authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	removePromptTemplate(context, userId, *userId)
+}
+
+// createGlobalPromptTemplate godoc
+// @Summary      Save a global prompt template
+// @Description  Saves a new version of a global prompt template, the first one if there is no template with the name. Saving the template named itinerary changes the default prompt of the users without their own one. The template is a Go template which can only use the variables title, description, notes, ownerId, travelDestinations (with country, city, arrivalDate, departureDate, and localArrivalDate and localDepartureDate formatted for the locale of the language), interests, travellerProfile, language and languageName, and the built-in functions of Go templates. Only available for administrators.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        promptTemplate  body  requests.PromptTemplateRequest  true  "Prompt template"
+// @Success      201  {object}  responses.CreatePromptTemplateResponse  "Prompt template saved."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data or invalid template."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not save prompt template. Try again later."
+// @Router       /admin/prompt-templates [post]
+func createGlobalPromptTemplate(context *gin.Context) {
+	log.Debug("Saving global prompt template")
+	savePromptTemplate(context, nil, context.GetInt64("userId"))
+}
+
+// getGlobalPromptTemplateVersions godoc
+// @Summary      List the versions of a global prompt template
+// @Description  Retrieves the versions of a global prompt template, from the newest. Only available for support staff and administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        name  path  string  true  "Prompt template name"
+// @Success      200  {object}  responses.GetPromptTemplatesResponse  "Versions of the prompt template"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Prompt template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get prompt template. Try again later."
+// @Router       /admin/prompt-templates/{name} [get]
+func getGlobalPromptTemplateVersions(context *gin.Context) {
+	log.Debug("Retrieving global prompt template versions")
+	listPromptTemplateVersions(context, nil)
+}
+
+// deleteGlobalPromptTemplate godoc
+// @Summary      Delete a global prompt template
+// @Description  Deletes all the versions of a global prompt template. Deleting the template named itinerary restores the built-in default prompt. The versions stay available for the jobs generated with them. Only available for administrators.
+// @Tags         admin
+// @Produce      json
+// @Security     Auth
+// @Param        name  path  string  true  "Prompt template name"
+// @Success      200  {object}  responses.DeletePromptTemplateResponse  "Prompt template deleted."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Prompt template not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not delete prompt template. Try again later."
+// @Router       /admin/prompt-templates/{name} [delete]
+func deleteGlobalPromptTemplate(context *gin.Context) {
+	log.Debug("Deleting global prompt template")
+	removePromptTemplate(context, nil, context.GetInt64("userId"))
+}
+
+// listPromptTemplates sends the latest version of every prompt template of the owner, or of every global template if the owner is nil
+func listPromptTemplates(context *gin.Context, ownerId *int64) {
+	promptTemplates, err := services.GetPromptTemplateService().FindAllLatest(ownerId)
+	if err != nil {
+		log.Errorf("Error retrieving prompt templates: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get prompt templates. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetPromptTemplatesResponse{PromptTemplates: promptTemplates})
+}
+
+// listPromptTemplateVersions sends the versions of the prompt template of the path of the owner, or of the global one if the owner is nil
+func listPromptTemplateVersions(context *gin.Context, ownerId *int64) {
+	name := context.Param("name")
+	promptTemplates, err := services.GetPromptTemplateService().FindVersions(ownerId, name)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Prompt template not found."})
+			return
+		}
+		log.Errorf("Error retrieving versions of prompt template %s: %v", name, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get prompt template. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetPromptTemplatesResponse{PromptTemplates: promptTemplates})
+}
+
+// savePromptTemplate saves the prompt template of the request body as a new version of a template of the owner, or of a global one if
+// the owner is nil
+func savePromptTemplate(context *gin.Context, ownerId *int64, actorId int64) {
+	var input requests.PromptTemplateRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The name, system message and template are required, the name cannot contain slashes and none of them can be too large."})
+		return
+	}
+
+	promptTemplate := &models.PromptTemplate{Name: input.Name, OwnerID: ownerId, SystemMessage: input.SystemMessage, Template: input.Template}
+	err := services.GetPromptTemplateService().Create(promptTemplate, actorId)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid prompt template: ") {
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid template: " + strings.TrimPrefix(err.Error(), "invalid prompt template: ")})
+			return
+		}
+		log.Errorf("Error saving prompt template %s: %v", input.Name, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not save prompt template. Try again later."})
+		return
+	}
+
+	log.Debugf("Version %d of prompt template %s saved", promptTemplate.Version, promptTemplate.Name)
+	context.JSON(http.StatusCreated, &responses.CreatePromptTemplateResponse{Message: "Prompt template saved.", PromptTemplate: promptTemplate})
+}
+
+// removePromptTemplate deletes the prompt template of the path of the owner, or the global one if the owner is nil
+func removePromptTemplate(context *gin.Context, ownerId *int64, actorId int64) {
+	name := context.Param("name")
+	err := services.GetPromptTemplateService().Delete(ownerId, name, actorId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Prompt template not found."})
+			return
+		}
+		log.Errorf("Error deleting prompt template %s: %v", name, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not delete prompt template. Try again later."})
+		return
+	}
+
+	log.Debugf("Prompt template %s deleted", name)
+	context.JSON(http.StatusOK, &responses.DeletePromptTemplateResponse{Message: "Prompt template deleted."})
+}
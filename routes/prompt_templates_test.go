@@ -0,0 +1,182 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockPromptTemplateService struct {
+	PromptTemplate  *models.PromptTemplate
+	PromptTemplates []*models.PromptTemplate
+	Err             error
+	OwnerId         *int64
+	Name            string
+	Created         *models.PromptTemplate
+	ActorId         int64
+}
+
+func (m *mockPromptTemplateService) FindById(_ int64) (*models.PromptTemplate, error) {
+	return m.PromptTemplate, m.Err
+}
+func (m *mockPromptTemplateService) FindAllLatest(ownerId *int64) ([]*models.PromptTemplate, error) {
+	m.OwnerId = ownerId
+	return m.PromptTemplates, m.Err
+}
+func (m *mockPromptTemplateService) FindVersions(ownerId *int64, name string) ([]*models.PromptTemplate, error) {
+	m.OwnerId = ownerId
+	m.Name = name
+	return m.PromptTemplates, m.Err
+}
+func (m *mockPromptTemplateService) Create(promptTemplate *models.PromptTemplate, actorId int64) error {
+	m.Created = promptTemplate
+	m.ActorId = actorId
+	promptTemplate.Version = 1
+	return m.Err
+}
+func (m *mockPromptTemplateService) Delete(ownerId *int64, name string, actorId int64) error {
+	m.OwnerId = ownerId
+	m.Name = name
+	m.ActorId = actorId
+	return m.Err
+}
+func (m *mockPromptTemplateService) Resolve(_ string, _ int64) (*models.PromptTemplate, error) {
+	return m.PromptTemplate, m.Err
+}
+
+func setMockPromptTemplateService(mock *mockPromptTemplateService) func() {
+	orig := services.GetPromptTemplateService
+	services.GetPromptTemplateService = func() services.PromptTemplateServiceInterface {
+		return mock
+	}
+	return func() { services.GetPromptTemplateService = orig }
+}
+
+var promptTemplateNameParams = gin.Params{{Key: "name", Value: "kids"}}
+
+// --- Tests ---
+
+func TestGetMyPromptTemplates_Success(t *testing.T) {
+	promptTemplateService := &mockPromptTemplateService{PromptTemplates: []*models.PromptTemplate{{ID: 3, Name: "kids", Version: 2}}}
+	defer setMockPromptTemplateService(promptTemplateService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getMyPromptTemplates(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), *promptTemplateService.OwnerId)
+	assert.Contains(t, w.Body.String(), `"name":"kids"`)
+}
+
+func TestGetGlobalPromptTemplates_Success(t *testing.T) {
+	promptTemplateService := &mockPromptTemplateService{PromptTemplates: []*models.PromptTemplate{}}
+	defer setMockPromptTemplateService(promptTemplateService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getGlobalPromptTemplates(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, promptTemplateService.OwnerId)
+	assert.JSONEq(t, `{"promptTemplates":[]}`, w.Body.String())
+}
+
+func TestGetPromptTemplate_OtherUser(t *testing.T) {
+	otherUserId := int64(2)
+	defer setMockPromptTemplateService(&mockPromptTemplateService{PromptTemplate: &models.PromptTemplate{ID: 3, OwnerID: &otherUserId}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "promptTemplateId", Value: "3"}})
+	getPromptTemplate(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetPromptTemplate_Global(t *testing.T) {
+	defer setMockPromptTemplateService(&mockPromptTemplateService{PromptTemplate: &models.PromptTemplate{ID: 3, Template: "Plan {{.title}}"}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", gin.Params{{Key: "promptTemplateId", Value: "3"}})
+	getPromptTemplate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"template":"Plan {{.title}}"`)
+}
+
+func TestCreateMyPromptTemplate_Success(t *testing.T) {
+	promptTemplateService := &mockPromptTemplateService{}
+	defer setMockPromptTemplateService(promptTemplateService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"name":"kids","systemMessage":"You are a guide.","template":"Plan {{.title}}"}`, nil)
+	createMyPromptTemplate(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int64(1), *promptTemplateService.Created.OwnerID)
+	assert.Equal(t, "Plan {{.title}}", promptTemplateService.Created.Template)
+	assert.Equal(t, int64(1), promptTemplateService.ActorId)
+	assert.Contains(t, w.Body.String(), `"version":1`)
+}
+
+func TestCreateMyPromptTemplate_BadRequest(t *testing.T) {
+	for _, body := range []string{`{"name":"kids","systemMessage":"You are a guide."}`, `{"name":"a/b","systemMessage":"You are a guide.","template":"Plan"}`} {
+		promptTemplateService := &mockPromptTemplateService{}
+		restore := setMockPromptTemplateService(promptTemplateService)
+
+		c, w := newAuthenticatedContext(http.MethodPost, body, nil)
+		createMyPromptTemplate(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, promptTemplateService.Created)
+	}
+}
+
+func TestCreateGlobalPromptTemplate_InvalidTemplate(t *testing.T) {
+	promptTemplateService := &mockPromptTemplateService{Err: errors.New(`invalid prompt template: map has no entry for key "budget"`)}
+	defer setMockPromptTemplateService(promptTemplateService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"name":"itinerary","systemMessage":"You are a guide.","template":"{{.budget}}"}`, nil)
+	createGlobalPromptTemplate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, promptTemplateService.Created.OwnerID)
+	assert.Contains(t, w.Body.String(), `Invalid template: map has no entry for key \"budget\"`)
+}
+
+func TestGetMyPromptTemplateVersions_NotFound(t *testing.T) {
+	promptTemplateService := &mockPromptTemplateService{Err: sql.ErrNoRows}
+	defer setMockPromptTemplateService(promptTemplateService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", promptTemplateNameParams)
+	getMyPromptTemplateVersions(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "kids", promptTemplateService.Name)
+}
+
+func TestDeleteGlobalPromptTemplate_Success(t *testing.T) {
+	promptTemplateService := &mockPromptTemplateService{}
+	defer setMockPromptTemplateService(promptTemplateService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", promptTemplateNameParams)
+	deleteGlobalPromptTemplate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, promptTemplateService.OwnerId)
+	assert.Equal(t, "kids", promptTemplateService.Name)
+	assert.Equal(t, int64(1), promptTemplateService.ActorId)
+}
+
+func TestDeleteMyPromptTemplate_Error(t *testing.T) {
+	defer setMockPromptTemplateService(&mockPromptTemplateService{Err: errors.New("failed to delete prompt template")})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", promptTemplateNameParams)
+	deleteMyPromptTemplate(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
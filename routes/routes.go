@@ -1,32 +1,146 @@
 package routes
 
 import (
+	"os"
+	"strings"
+
 	"example.com/travel-advisor/middlewares"
+	"example.com/travel-advisor/models"
 	"github.com/gin-gonic/gin"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 )
 
+// SetTrustedProxies trusts the X-Forwarded-For and X-Real-IP headers only from the proxies of the comma-separated IPs and CIDR ranges
+// of the TRUSTED_PROXIES environment variable. Without it the client IP is the address of the connection, since any client could
+// otherwise send those headers and get around the login brute-force protection, which is keyed on the client IP
+func SetTrustedProxies(server *gin.Engine) error {
+	trustedProxies := []string{}
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if len(trustedProxies) == 0 {
+		return server.SetTrustedProxies(nil)
+	}
+	return server.SetTrustedProxies(trustedProxies)
+}
+
 func RegisterRoutes(server *gin.Engine) {
 	api := server.Group("/api/v1")
 
 	api.POST("/signup", signUp)
 	api.POST("/login", login)
+	api.GET("/public/share-links/:token", getPublicItinerary)
+	api.GET("/public/share-links/:token/file", downloadPublicItineraryFile)
 
 	authenticated := api.Group("/")
 	authenticated.Use(middlewares.Authenticate)
-	authenticated.POST("/itineraries", createItinerary)
-	authenticated.PUT("/itineraries", updateItinerary)
-	authenticated.GET("/itineraries", getOwnersItineraries)
-	authenticated.GET("/itineraries/:itineraryId", getItinerary)
-	authenticated.DELETE("/itineraries/:itineraryId", deleteItinerary)
-	authenticated.POST("/itineraries/:itineraryId/jobs", runItineraryFileJob)
-	authenticated.GET("/itineraries/:itineraryId/jobs", getAllItineraryFileJobs)
-	authenticated.GET("/itineraries/:itineraryId/jobs/:itineraryJobId", getItineraryJob)
-	authenticated.GET("/itineraries/:itineraryId/jobs/:itineraryJobId/file", downloadItineraryJobFile)
-	authenticated.PUT("/itineraries/:itineraryId/jobs/:itineraryJobId/stop", stopItineraryJob)
-	authenticated.DELETE("/itineraries/:itineraryId/jobs/:itineraryJobId", deleteItineraryJob)
+	authenticated.POST("/itineraries", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), createItinerary)
+	authenticated.PUT("/itineraries", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateItinerary)
+	authenticated.GET("/itineraries", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getOwnersItineraries)
+	authenticated.GET("/itineraries/shared", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getSharedItineraries)
+	authenticated.GET("/itineraries/search", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), searchItineraries)
+	authenticated.POST("/itineraries/import", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), importItinerary)
+	authenticated.GET("/itineraries/:itineraryId", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItinerary)
+	authenticated.PATCH("/itineraries/:itineraryId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), patchItinerary)
+	authenticated.DELETE("/itineraries/:itineraryId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItinerary)
+	authenticated.POST("/itineraries/:itineraryId/destinations", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), addItineraryDestination)
+	authenticated.PATCH("/itineraries/:itineraryId/destinations/:destinationId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), patchItineraryDestination)
+	authenticated.DELETE("/itineraries/:itineraryId/destinations/:destinationId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItineraryDestination)
+	authenticated.POST("/itineraries/:itineraryId/clone", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), cloneItinerary)
+	authenticated.POST("/itineraries/:itineraryId/template", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), createTemplateFromItinerary)
+	authenticated.GET("/itineraries/:itineraryId/revisions", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryRevisions)
+	authenticated.GET("/itineraries/:itineraryId/revisions/diff", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryRevisionsDiff)
+	authenticated.GET("/itineraries/:itineraryId/revisions/:revisionNumber", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryRevision)
+	authenticated.POST("/itineraries/:itineraryId/revisions/:revisionNumber/restore", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), restoreItineraryRevision)
+	authenticated.GET("/itineraries/:itineraryId/preferences", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryTravellerPreferences)
+	authenticated.PUT("/itineraries/:itineraryId/preferences", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateItineraryTravellerPreferences)
+	authenticated.DELETE("/itineraries/:itineraryId/preferences", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItineraryTravellerPreferences)
+	authenticated.GET("/itineraries/:itineraryId/budget", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryBudget)
+	authenticated.PUT("/itineraries/:itineraryId/budget", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateItineraryBudget)
+	authenticated.DELETE("/itineraries/:itineraryId/budget", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItineraryBudget)
+	authenticated.POST("/itineraries/:itineraryId/budget/estimates", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), generateCostEstimates)
+	authenticated.PUT("/itineraries/:itineraryId/destinations/:destinationId/cost-estimate", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateDestinationCostEstimate)
+	authenticated.DELETE("/itineraries/:itineraryId/destinations/:destinationId/cost-estimate", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteDestinationCostEstimate)
+	authenticated.GET("/itineraries/:itineraryId/destinations/:destinationId/accommodation", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getDestinationAccommodation)
+	authenticated.PUT("/itineraries/:itineraryId/destinations/:destinationId/accommodation", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateDestinationAccommodation)
+	authenticated.DELETE("/itineraries/:itineraryId/destinations/:destinationId/accommodation", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteDestinationAccommodation)
+	authenticated.GET("/itineraries/:itineraryId/transport-legs", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryTransportLegs)
+	authenticated.POST("/itineraries/:itineraryId/transport-legs", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), addItineraryTransportLeg)
+	authenticated.PUT("/itineraries/:itineraryId/transport-legs/:transportLegId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateItineraryTransportLeg)
+	authenticated.DELETE("/itineraries/:itineraryId/transport-legs/:transportLegId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItineraryTransportLeg)
+	authenticated.GET("/itineraries/:itineraryId/plan", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryPlan)
+	authenticated.POST("/itineraries/:itineraryId/plan/activities", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), addItineraryActivity)
+	authenticated.PUT("/itineraries/:itineraryId/plan/activities/:activityId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), updateItineraryActivity)
+	authenticated.DELETE("/itineraries/:itineraryId/plan/activities/:activityId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItineraryActivity)
+	authenticated.PUT("/itineraries/:itineraryId/plan/days/:date/order", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), reorderItineraryDay)
+	authenticated.POST("/itineraries/:itineraryId/plan/generate", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), generateItineraryPlan)
+	authenticated.POST("/itineraries/:itineraryId/shares", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), shareItinerary)
+	authenticated.GET("/itineraries/:itineraryId/shares", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryShares)
+	authenticated.DELETE("/itineraries/:itineraryId/shares/:userId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), unshareItinerary)
+	authenticated.POST("/itineraries/:itineraryId/share-links", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), createShareLink)
+	authenticated.GET("/itineraries/:itineraryId/share-links", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getShareLinks)
+	authenticated.DELETE("/itineraries/:itineraryId/share-links/:shareLinkId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), revokeShareLink)
+	authenticated.POST("/itineraries/:itineraryId/jobs", middlewares.RequireScope(models.ApiKeyScopeJobsWrite), runItineraryFileJob)
+	authenticated.GET("/itineraries/:itineraryId/jobs", middlewares.RequireScope(models.ApiKeyScopeJobsRead), getAllItineraryFileJobs)
+	authenticated.GET("/itineraries/:itineraryId/jobs/:itineraryJobId", middlewares.RequireScope(models.ApiKeyScopeJobsRead), getItineraryJob)
+	authenticated.GET("/itineraries/:itineraryId/jobs/:itineraryJobId/file", middlewares.RequireScope(models.ApiKeyScopeJobsRead), downloadItineraryJobFile)
+	authenticated.POST("/itineraries/:itineraryId/jobs/:itineraryJobId/regenerate", middlewares.RequireScope(models.ApiKeyScopeJobsWrite), regenerateItineraryJob)
+	authenticated.GET("/itineraries/:itineraryId/jobs/:itineraryJobId/messages", middlewares.RequireScope(models.ApiKeyScopeJobsRead), getItineraryJobMessages)
+	authenticated.POST("/itineraries/:itineraryId/jobs/:itineraryJobId/messages", middlewares.RequireScope(models.ApiKeyScopeJobsWrite), sendItineraryJobMessage)
+	authenticated.PUT("/itineraries/:itineraryId/jobs/:itineraryJobId/stop", middlewares.RequireScope(models.ApiKeyScopeJobsWrite), stopItineraryJob)
+	authenticated.DELETE("/itineraries/:itineraryId/jobs/:itineraryJobId", middlewares.RequireScope(models.ApiKeyScopeJobsWrite), deleteItineraryJob)
+	authenticated.GET("/places/suggest", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), suggestPlaces)
+	authenticated.GET("/itinerary-templates", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryTemplates)
+	authenticated.POST("/itinerary-templates", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), createItineraryTemplate)
+	authenticated.GET("/itinerary-templates/:templateId", middlewares.RequireScope(models.ApiKeyScopeItinerariesRead), getItineraryTemplate)
+	authenticated.DELETE("/itinerary-templates/:templateId", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), deleteItineraryTemplate)
+	authenticated.POST("/itinerary-templates/:templateId/instantiate", middlewares.RequireScope(models.ApiKeyScopeItinerariesWrite), instantiateItineraryTemplate)
+	authenticated.GET("/prompt-templates", middlewares.RequireScope(models.ApiKeyScopeJobsRead), getGlobalPromptTemplates)
+	authenticated.GET("/prompt-templates/:promptTemplateId", middlewares.RequireScope(models.ApiKeyScopeJobsRead), getPromptTemplate)
+
+	me := authenticated.Group("/me")
+	me.Use(middlewares.RequireLoginSession)
+	me.GET("", getMe)
+	me.PATCH("", updateMe)
+	me.POST("/password", changeMyPassword)
+	me.DELETE("", deleteMe)
+	me.POST("/export", requestDataExport)
+	me.GET("/exports", getDataExports)
+	me.GET("/exports/:exportJobId", getDataExport)
+	me.GET("/exports/:exportJobId/file", downloadDataExportFile)
+	me.DELETE("/exports/:exportJobId", deleteDataExport)
+	me.GET("/audit-events", getMyAuditEvents)
+	me.GET("/preferences", getMyTravellerPreferences)
+	me.PUT("/preferences", updateMyTravellerPreferences)
+	me.GET("/prompt-templates", getMyPromptTemplates)
+	me.POST("/prompt-templates", createMyPromptTemplate)
+	me.GET("/prompt-templates/:name", getMyPromptTemplateVersions)
+	me.DELETE("/prompt-templates/:name", deleteMyPromptTemplate)
+
+	apiKeys := authenticated.Group("/api-keys")
+	apiKeys.Use(middlewares.RequireLoginSession)
+	apiKeys.POST("", createApiKey)
+	apiKeys.GET("", getApiKeys)
+	apiKeys.DELETE("/:apiKeyId", revokeApiKey)
+
+	admin := authenticated.Group("/admin")
+	admin.Use(middlewares.RequireLoginSession, middlewares.RequireRole(models.RoleSupport, models.RoleAdmin))
+	admin.GET("/users", getUsers)
+	admin.POST("/users/unlock", unlockUserAccount)
+	admin.PUT("/users/:userId/role", middlewares.RequireRole(models.RoleAdmin), updateUserRole)
+	admin.PUT("/users/:userId/disable", middlewares.RequireRole(models.RoleAdmin), disableUser)
+	admin.PUT("/users/:userId/enable", middlewares.RequireRole(models.RoleAdmin), enableUser)
+	admin.GET("/jobs/:itineraryJobId", getAnyItineraryJob)
+	admin.PUT("/jobs/:itineraryJobId/stop", middlewares.RequireRole(models.RoleAdmin), forceStopItineraryJob)
+	admin.DELETE("/jobs/:itineraryJobId", middlewares.RequireRole(models.RoleAdmin), purgeItineraryJob)
+	admin.GET("/audit-events", middlewares.RequireRole(models.RoleAdmin), getAllAuditEvents)
+	admin.POST("/prompt-templates", middlewares.RequireRole(models.RoleAdmin), createGlobalPromptTemplate)
+	admin.GET("/prompt-templates/:name", getGlobalPromptTemplateVersions)
+	admin.DELETE("/prompt-templates/:name", middlewares.RequireRole(models.RoleAdmin), deleteGlobalPromptTemplate)
 
 	api.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
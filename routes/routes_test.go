@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientIP returns the client IP seen by a server with the trusted proxies of the environment for a request from remoteAddr
+func clientIP(t *testing.T, remoteAddr string, forwardedFor string) string {
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	assert.NoError(t, SetTrustedProxies(server))
+	server.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w.Body.String()
+}
+
+func TestSetTrustedProxies_None(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+
+	assert.Equal(t, "203.0.113.7", clientIP(t, "203.0.113.7:1234", "198.51.100.1"))
+}
+
+func TestSetTrustedProxies_List(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+
+	assert.Equal(t, "198.51.100.1", clientIP(t, "10.1.2.3:1234", "198.51.100.1"))
+	assert.Equal(t, "198.51.100.1", clientIP(t, "192.168.1.1:1234", "198.51.100.1"))
+	assert.Equal(t, "203.0.113.7", clientIP(t, "203.0.113.7:1234", "198.51.100.1"))
+}
+
+func TestSetTrustedProxies_Invalid(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "not-an-ip")
+
+	assert.Error(t, SetTrustedProxies(gin.New()))
+}
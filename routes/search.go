@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// searchItineraries godoc
+// @Summary      Search itineraries
+// @Description  Searches the words of q in the title, description, notes, destinations and latest generated document of the itineraries owned by or shared with the authenticated user. Every word of at least 2 characters must match, as a whole word or as the beginning of one, ignoring case and diacritics. Results are ranked from the best match, weighting matches in the title and destinations the most, and include the title and a snippet of the best matching text with the matched words between <mark> and </mark> and the rest of the text HTML-escaped. Use the nextCursor of a page, with the same q, to get the next one.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        q       query  string  true   "Words to search (up to 10)"
+// @Param        cursor  query  string  false  "Cursor of the page to get"
+// @Param        limit   query  int     false  "Maximum number of results per page (1-50, default 20)"
+// @Success      200  {object}  responses.SearchItinerariesResponse  "Page of search results"
+// @Header       200  {string}  Link  "Links to the first and next pages"
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid search text or cursor."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not search itineraries. Try again later."
+// @Router       /itineraries/search [get]
+func searchItineraries(context *gin.Context) {
+	log.Debug("Searching itineraries")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	var input requests.SearchItinerariesRequest
+	if err := context.ShouldBindQuery(&input); err != nil {
+		log.Errorf("Error parsing itinerary search query: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data."})
+		return
+	}
+
+	page, err := services.GetSearchService().SearchItineraries(services.ItinerarySearchQuery{
+		UserID: *userId,
+		Text:   input.Q,
+		Cursor: input.Cursor,
+		Limit:  input.Limit,
+	})
+	if err != nil {
+		log.Errorf("Error searching itineraries for user %d: %v", *userId, err)
+		switch {
+		case strings.Contains(err.Error(), "invalid search text"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "The search text must have between 1 and 10 words of at least 2 characters."})
+		case strings.Contains(err.Error(), "invalid cursor"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Invalid cursor."})
+		default:
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not search itineraries. Try again later."})
+		}
+		return
+	}
+
+	setPaginationLinkHeader(context, page.NextCursor)
+
+	log.Debugf("Found %d itineraries for user %d", len(page.Results), *userId)
+	context.JSON(http.StatusOK, &responses.SearchItinerariesResponse{Results: page.Results, NextCursor: page.NextCursor})
+}
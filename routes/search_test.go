@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockSearchService struct {
+	Page      *services.ItinerarySearchPage
+	SearchErr error
+	Query     services.ItinerarySearchQuery
+}
+
+func (m *mockSearchService) SearchItineraries(query services.ItinerarySearchQuery) (*services.ItinerarySearchPage, error) {
+	m.Query = query
+	return m.Page, m.SearchErr
+}
+func (m *mockSearchService) IndexItinerary(_ int64) error { return nil }
+
+func setMockSearchService(mock *mockSearchService) func() {
+	orig := services.GetSearchService
+	services.GetSearchService = func() services.SearchServiceInterface {
+		return mock
+	}
+	return func() { services.GetSearchService = orig }
+}
+
+// --- Tests ---
+
+func TestSearchItineraries_Success(t *testing.T) {
+	searchService := &mockSearchService{Page: &services.ItinerarySearchPage{
+		Results:    []*models.ItinerarySearchResult{{ItineraryID: 2, Title: "Kyoto", TitleHighlight: "<mark>Kyoto</mark>"}},
+		NextCursor: "next",
+	}}
+	defer setMockSearchService(searchService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/itineraries/search?q=kyoto+food&limit=1", nil)
+	searchItineraries(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"itineraryId":2`)
+	assert.Contains(t, w.Body.String(), `"nextCursor":"next"`)
+	assert.Contains(t, w.Header().Get("Link"), `cursor=next&limit=1&q=kyoto+food>; rel="next"`)
+	assert.Equal(t, services.ItinerarySearchQuery{UserID: 1, Text: "kyoto food", Limit: 1}, searchService.Query)
+}
+
+func TestSearchItineraries_MissingText(t *testing.T) {
+	defer setMockSearchService(&mockSearchService{})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/itineraries/search", nil)
+	searchItineraries(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchItineraries_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("invalid search text"), http.StatusBadRequest},
+		{errors.New("invalid cursor"), http.StatusBadRequest},
+		{errors.New("failed to search itineraries"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			defer setMockSearchService(&mockSearchService{SearchErr: test.err})()
+
+			c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/itineraries/search?q=a", nil)
+			searchItineraries(c)
+
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+}
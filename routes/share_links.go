@@ -0,0 +1,247 @@
+package routes
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// shareLinkPasswordHeader carries the password of the password-protected share links
+const shareLinkPasswordHeader = "X-Share-Password"
+
+// createShareLink godoc
+// @Summary      Create a public share link for an itinerary
+// @Description  Creates a read-only link that gives access to the itinerary and its latest generated document to anyone knowing it, without an account. The link can target a specific completed job, expire at a given date and require a password. The token is only returned once. Only the owner can create share links.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        shareLink  body  requests.CreateShareLinkRequest  true  "Share link data"
+// @Success      201  {object}  responses.CreateShareLinkResponse  "Share link created."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data, invalid expiration date or the job is not completed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or itinerary job not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not create share link. Try again later."
+// @Router       /itineraries/{itineraryId}/share-links [post]
+func createShareLink(context *gin.Context) {
+	log.Debug("Creating share link")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionOwner)
+	if itinerary == nil {
+		return
+	}
+
+	var input requests.CreateShareLinkRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. At least one of the expected attributes is invalid or too large."})
+		return
+	}
+
+	shareLink := models.NewShareLink(context.GetInt64("userId"), itinerary.ID, input.ItineraryJobID, input.ExpirationDate)
+
+	token, err := services.GetShareLinkService().Create(shareLink, input.Password)
+	if err != nil {
+		log.Errorf("Error creating share link for itinerary %d: %v", itinerary.ID, err)
+		switch {
+		case strings.Contains(err.Error(), "itinerary job not found"):
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Itinerary job not found."})
+		case strings.Contains(err.Error(), "not completed"), strings.Contains(err.Error(), "expiration date"):
+			context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: err.Error()})
+		default:
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create share link. Try again later."})
+		}
+		return
+	}
+
+	log.Debugf("Share link %d created for itinerary %d", shareLink.ID, itinerary.ID)
+	context.JSON(http.StatusCreated, &responses.CreateShareLinkResponse{Message: "Share link created. Store it safely, it will not be shown again.", Token: token, ShareLink: shareLink})
+}
+
+// getShareLinks godoc
+// @Summary      Get the public share links of an itinerary
+// @Description  Retrieves all the share links of an itinerary, including revoked and expired ones, with their access count. The tokens themselves are never returned. Only the owner can list share links.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.GetShareLinksResponse  "List of share links"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve share links. Try again later."
+// @Router       /itineraries/{itineraryId}/share-links [get]
+func getShareLinks(context *gin.Context) {
+	log.Debug("Retrieving share links")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionOwner)
+	if itinerary == nil {
+		return
+	}
+
+	shareLinks, err := services.GetShareLinkService().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		log.Errorf("Error retrieving share links of itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve share links. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetShareLinksResponse{ShareLinks: shareLinks})
+}
+
+// revokeShareLink godoc
+// @Summary      Revoke a public share link
+// @Description  Revokes a share link of an itinerary. Revoked links can no longer be used to access the itinerary. Only the owner can revoke share links.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        shareLinkId  path  int  true  "Share link ID"
+// @Success      200  {object}  responses.RevokeShareLinkResponse  "Share link revoked."
+// @Failure      400  {object}  responses.ErrorResponse  "Invalid share link ID."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary or share link not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not revoke share link. Try again later."
+// @Router       /itineraries/{itineraryId}/share-links/{shareLinkId} [delete]
+func revokeShareLink(context *gin.Context) {
+	log.Debug("Revoking share link")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionOwner)
+	if itinerary == nil {
+		return
+	}
+
+	shareLinkId := getPathId(context, "shareLinkId", "share link")
+	if shareLinkId == nil {
+		return
+	}
+
+	err := services.GetShareLinkService().Revoke(itinerary.ID, *shareLinkId)
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			log.Warnf("Share link %d not found for itinerary %d", *shareLinkId, itinerary.ID)
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Share link not found."})
+		} else {
+			log.Errorf("Error revoking share link %d: %v", *shareLinkId, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not revoke share link. Try again later."})
+		}
+		return
+	}
+
+	log.Debugf("Share link %d of itinerary %d revoked", *shareLinkId, itinerary.ID)
+	context.JSON(http.StatusOK, &responses.RevokeShareLinkResponse{Message: "Share link revoked."})
+}
+
+// getPublicItinerary godoc
+// @Summary      Get an itinerary through a public share link
+// @Description  Retrieves the shared itinerary, with its destinations, and the content of its latest generated document (or of the job targeted by the link). No account is needed. Password-protected links require the password in the X-Share-Password header, and repeated wrong passwords from the same IP are delayed and then locked out like failed logins, and too many wrong passwords from any IP lock out the link. Every access is counted and audited.
+// @Tags         public
+// @Produce      json
+// @Param        token  path  string  true  "Share link token"
+// @Param        X-Share-Password  header  string  false  "Password of the share link"
+// @Success      200  {object}  responses.GetPublicItineraryResponse  "Shared itinerary and document"
+// @Failure      401  {object}  responses.ErrorResponse  "Password required or invalid."
+// @Failure      404  {object}  responses.ErrorResponse  "Share link not found, revoked or expired."
+// @Failure      429  {object}  responses.ErrorResponse  "Too many wrong share link passwords. Try again later."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not retrieve shared itinerary. Try again later."
+// @Router       /public/share-links/{token} [get]
+func getPublicItinerary(context *gin.Context) {
+	log.Debug("Retrieving itinerary through share link")
+
+	shareLink := resolveShareLink(context)
+	if shareLink == nil {
+		return
+	}
+
+	itinerary, job, document, err := services.GetShareLinkService().GetSharedItinerary(shareLink, context.ClientIP())
+	if err != nil {
+		log.Errorf("Error retrieving itinerary of share link %d: %v", shareLink.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not retrieve shared itinerary. Try again later."})
+		return
+	}
+
+	if job != nil {
+		// Storage details are not exposed to anonymous users
+		job.Filepath = ""
+		job.FileManager = ""
+		job.AsyncTaskID = ""
+	}
+
+	context.JSON(http.StatusOK, &responses.GetPublicItineraryResponse{Itinerary: itinerary, Job: job, Document: document})
+}
+
+// downloadPublicItineraryFile godoc
+// @Summary      Download the document of an itinerary through a public share link
+// @Description  Downloads the latest generated document of the shared itinerary, or the document of the job targeted by the link. No account is needed. Password-protected links require the password in the X-Share-Password header, and repeated wrong passwords from the same IP are delayed and then locked out like failed logins, and too many wrong passwords from any IP lock out the link. Every access is counted and audited.
+// @Tags         public
+// @Produce      octet-stream
+// @Param        token  path  string  true  "Share link token"
+// @Param        X-Share-Password  header  string  false  "Password of the share link"
+// @Success      200  {file}  file  "Itinerary document"
+// @Failure      401  {object}  responses.ErrorResponse  "Password required or invalid."
+// @Failure      404  {object}  responses.ErrorResponse  "Share link not found, revoked or expired, or no document available."
+// @Failure      429  {object}  responses.ErrorResponse  "Too many wrong share link passwords. Try again later."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not download shared document. Try again later."
+// @Router       /public/share-links/{token}/file [get]
+func downloadPublicItineraryFile(context *gin.Context) {
+	log.Debug("Downloading itinerary document through share link")
+
+	shareLink := resolveShareLink(context)
+	if shareLink == nil {
+		return
+	}
+
+	job, file, err := services.GetShareLinkService().OpenSharedFile(shareLink, context.ClientIP())
+	if err != nil {
+		if strings.Contains(err.Error(), "no document available") {
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "No document available for this itinerary."})
+		} else {
+			log.Errorf("Error opening document of share link %d: %v", shareLink.ID, err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not download shared document. Try again later."})
+		}
+		return
+	}
+	defer file.Close()
+
+	filename := serveFile(context, file, job.Language)
+	if filename != "" {
+		log.Debugf("File %s of job %d served through share link %d", filename, job.ID, shareLink.ID)
+	}
+}
+
+// resolveShareLink returns the active share link of the token path parameter, or nil after writing the error response
+func resolveShareLink(context *gin.Context) *models.ShareLink {
+	shareLink, retryAfter, err := services.GetShareLinkService().Resolve(context.Param("token"), context.GetHeader(shareLinkPasswordHeader),
+		context.ClientIP())
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "too many password attempts"):
+			context.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			context.JSON(http.StatusTooManyRequests, &responses.ErrorResponse{Message: "Too many wrong share link passwords. Try again later."})
+		case strings.Contains(err.Error(), "invalid share link"), strings.Contains(err.Error(), "revoked or expired"):
+			context.JSON(http.StatusNotFound, &responses.ErrorResponse{Message: "Share link not found, revoked or expired."})
+		case strings.Contains(err.Error(), "password required"):
+			context.JSON(http.StatusUnauthorized, &responses.ErrorResponse{Message: "This share link requires a password."})
+		case strings.Contains(err.Error(), "invalid password"):
+			context.JSON(http.StatusUnauthorized, &responses.ErrorResponse{Message: "Invalid share link password."})
+		default:
+			log.Errorf("Error resolving share link: %v", err)
+			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not resolve share link. Try again later."})
+		}
+		return nil
+	}
+
+	return shareLink
+}
@@ -0,0 +1,245 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockShareLinkService struct {
+	ShareLinks      []*models.ShareLink
+	ShareLink       *models.ShareLink
+	Itinerary       *models.Itinerary
+	Job             *models.ItineraryFileJob
+	Document        *string
+	File            io.ReadSeekCloser
+	CreateErr       error
+	FindErr         error
+	RevokeErr       error
+	ResolveErr      error
+	RetryAfter      time.Duration
+	GetErr          error
+	OpenErr         error
+	CreatedPassword string
+	ResolvedToken   string
+	ResolvedPass    string
+}
+
+func (m *mockShareLinkService) Validate(_ *models.ShareLink) error { return nil }
+func (m *mockShareLinkService) Create(shareLink *models.ShareLink, password string) (string, error) {
+	m.CreatedPassword = password
+	if m.CreateErr != nil {
+		return "", m.CreateErr
+	}
+	shareLink.ID = 7
+	shareLink.TokenPrefix = "tas_01234567"
+	return "tas_0123456789", nil
+}
+func (m *mockShareLinkService) FindByItineraryId(_ int64) ([]*models.ShareLink, error) {
+	return m.ShareLinks, m.FindErr
+}
+func (m *mockShareLinkService) Revoke(_ int64, _ int64) error { return m.RevokeErr }
+func (m *mockShareLinkService) Resolve(token string, password string, _ string) (*models.ShareLink, time.Duration, error) {
+	m.ResolvedToken = token
+	m.ResolvedPass = password
+	return m.ShareLink, m.RetryAfter, m.ResolveErr
+}
+func (m *mockShareLinkService) GetSharedItinerary(_ *models.ShareLink, _ string) (*models.Itinerary, *models.ItineraryFileJob, *string, error) {
+	return m.Itinerary, m.Job, m.Document, m.GetErr
+}
+func (m *mockShareLinkService) OpenSharedFile(_ *models.ShareLink, _ string) (*models.ItineraryFileJob, io.ReadSeekCloser, error) {
+	return m.Job, m.File, m.OpenErr
+}
+
+func setMockShareLinkService(mock *mockShareLinkService) func() {
+	orig := services.GetShareLinkService
+	services.GetShareLinkService = func() services.ShareLinkServiceInterface {
+		return mock
+	}
+	return func() { services.GetShareLinkService = orig }
+}
+
+var shareTokenParams = gin.Params{{Key: "token", Value: "tas_0123456789"}}
+
+// --- Tests ---
+
+func TestCreateShareLink_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	shareLinkService := &mockShareLinkService{}
+	defer setMockShareLinkService(shareLinkService)()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"password":"s3cr3t"}`, itineraryIdParams)
+	createShareLink(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"token":"tas_0123456789"`)
+	assert.NotContains(t, w.Body.String(), "s3cr3t")
+	assert.Equal(t, "s3cr3t", shareLinkService.CreatedPassword)
+}
+
+func TestCreateShareLink_Forbidden(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	defer setMockShareLinkService(&mockShareLinkService{})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{}`, itineraryIdParams)
+	createShareLink(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateShareLink_JobNotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockShareLinkService(&mockShareLinkService{CreateErr: errors.New("itinerary job not found")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"itineraryJobId":3}`, itineraryIdParams)
+	createShareLink(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateShareLink_InvalidExpiration(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockShareLinkService(&mockShareLinkService{CreateErr: errors.New("share link expiration date must be in the future")})()
+
+	c, w := newAuthenticatedContext(http.MethodPost, `{"expirationDate":"2020-01-01T00:00:00Z"}`, itineraryIdParams)
+	createShareLink(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "must be in the future")
+}
+
+func TestGetShareLinks_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockShareLinkService(&mockShareLinkService{ShareLinks: []*models.ShareLink{{ID: 7, ItineraryID: 1, TokenHash: "secret-hash", AccessCount: 4}}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getShareLinks(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"accessCount":4`)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestRevokeShareLink_NotFound(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockShareLinkService(&mockShareLinkService{RevokeErr: sql.ErrNoRows})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "shareLinkId", Value: "7"}})
+	revokeShareLink(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRevokeShareLink_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockShareLinkService(&mockShareLinkService{})()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", gin.Params{{Key: "itineraryId", Value: "1"}, {Key: "shareLinkId", Value: "7"}})
+	revokeShareLink(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetPublicItinerary_Success(t *testing.T) {
+	document := "Day 1: Madrid"
+	shareLinkService := &mockShareLinkService{
+		ShareLink: &models.ShareLink{ID: 7, ItineraryID: 1},
+		Itinerary: &models.Itinerary{ID: 1, Title: "Trip to Spain"},
+		Job:       &models.ItineraryFileJob{ID: 3, Status: "completed", Filepath: "files/users/1/plan.txt", FileManager: "local"},
+		Document:  &document,
+	}
+	defer setMockShareLinkService(shareLinkService)()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", shareTokenParams)
+	c.Request.Header.Set("X-Share-Password", "s3cr3t")
+	getPublicItinerary(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"document":"Day 1: Madrid"`)
+	assert.NotContains(t, w.Body.String(), "files/users/1/plan.txt")
+	assert.Equal(t, "tas_0123456789", shareLinkService.ResolvedToken)
+	assert.Equal(t, "s3cr3t", shareLinkService.ResolvedPass)
+}
+
+func TestGetPublicItinerary_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("invalid share link"), http.StatusNotFound},
+		{errors.New("share link is revoked or expired"), http.StatusNotFound},
+		{errors.New("password required"), http.StatusUnauthorized},
+		{errors.New("invalid password"), http.StatusUnauthorized},
+		{errors.New("failed to resolve share link"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			defer setMockShareLinkService(&mockShareLinkService{ResolveErr: test.err})()
+
+			c, w := newAuthenticatedContext(http.MethodGet, "", shareTokenParams)
+			getPublicItinerary(c)
+
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+}
+
+func TestGetPublicItinerary_TooManyPasswordAttempts(t *testing.T) {
+	defer setMockShareLinkService(&mockShareLinkService{
+		ResolveErr: errors.New("too many password attempts"),
+		RetryAfter: 90*time.Second + time.Millisecond,
+	})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", shareTokenParams)
+	c.Request.Header.Set("X-Share-Password", "guess")
+	getPublicItinerary(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "91", w.Header().Get("Retry-After"))
+}
+
+func TestDownloadPublicItineraryFile_Success(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("Day 1: Madrid"), 0644))
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+
+	defer setMockShareLinkService(&mockShareLinkService{
+		ShareLink: &models.ShareLink{ID: 7, ItineraryID: 1},
+		Job:       &models.ItineraryFileJob{ID: 3},
+		File:      file,
+	})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", shareTokenParams)
+	downloadPublicItineraryFile(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Day 1: Madrid", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "plan.txt")
+}
+
+func TestDownloadPublicItineraryFile_NoDocument(t *testing.T) {
+	defer setMockShareLinkService(&mockShareLinkService{
+		ShareLink: &models.ShareLink{ID: 7, ItineraryID: 1},
+		OpenErr:   errors.New("no document available"),
+	})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", shareTokenParams)
+	downloadPublicItineraryFile(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
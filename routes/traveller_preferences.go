@@ -0,0 +1,202 @@
+package routes
+
+import (
+	"net/http"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/requests"
+	"example.com/travel-advisor/responses"
+	"example.com/travel-advisor/services"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// getMyTravellerPreferences godoc
+// @Summary      Get the traveller preferences of the authenticated user
+// @Description  Retrieves the interests, pace, budget level, dietary restrictions, mobility needs, travelling party and preferred language the itineraries of the authenticated user are generated for. Unset preferences are omitted.
+// @Tags         users
+// @Produce      json
+// @Security     Auth
+// @Success      200  {object}  responses.GetTravellerPreferencesResponse  "Traveller preferences"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get traveller preferences. Try again later."
+// @Router       /me/preferences [get]
+func getMyTravellerPreferences(context *gin.Context) {
+	log.Debug("Retrieving traveller preferences of authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	preferences, err := services.GetTravellerPreferencesService().FindByUserId(*userId)
+	if err != nil {
+		log.Errorf("Error retrieving traveller preferences of user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get traveller preferences. Try again later."})
+		return
+	}
+
+	context.JSON(http.StatusOK, &responses.GetTravellerPreferencesResponse{Preferences: preferences})
+}
+
+// updateMyTravellerPreferences godoc
+// @Summary      Update the traveller preferences of the authenticated user
+// @Description  Replaces the traveller preferences of the authenticated user, which personalise the itineraries generated from then on. Omitted attributes are unset. The language is a BCP 47 tag like es or pt-BR.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        preferences  body  requests.TravellerPreferencesRequest  true  "Traveller preferences"
+// @Success      200  {object}  responses.UpdateTravellerPreferencesResponse  "Traveller preferences updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update traveller preferences. Try again later."
+// @Router       /me/preferences [put]
+func updateMyTravellerPreferences(context *gin.Context) {
+	log.Debug("Updating traveller preferences of authenticated user")
+
+	userId := validateAuthenticatedUser(context)
+	if userId == nil {
+		return
+	}
+
+	preferences := bindTravellerPreferences(context)
+	if preferences == nil {
+		return
+	}
+
+	err := services.GetTravellerPreferencesService().SaveForUser(*userId, preferences)
+	if err != nil {
+		log.Errorf("Error updating traveller preferences of user %d: %v", *userId, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not update traveller preferences. Try again later."})
+		return
+	}
+
+	log.Debugf("Traveller preferences of user %d updated", *userId)
+	context.JSON(http.StatusOK, &responses.UpdateTravellerPreferencesResponse{Message: "Traveller preferences updated.", Preferences: preferences})
+}
+
+// getItineraryTravellerPreferences godoc
+// @Summary      Get the traveller preferences of an itinerary
+// @Description  Retrieves the overrides of the traveller preferences set for an itinerary, together with the effective preferences it is generated with: the ones of its owner with the overrides applied. The itinerary must be owned by or shared with the authenticated user.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.GetItineraryTravellerPreferencesResponse  "Traveller preferences"
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not get traveller preferences. Try again later."
+// @Router       /itineraries/{itineraryId}/preferences [get]
+func getItineraryTravellerPreferences(context *gin.Context) {
+	log.Debug("Retrieving itinerary traveller preferences")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionViewer)
+	if itinerary == nil {
+		return
+	}
+
+	preferencesService := services.GetTravellerPreferencesService()
+	overrides, err := preferencesService.FindByItineraryId(itinerary.ID)
+	if err == nil {
+		var effective *models.TravellerPreferences
+		effective, err = preferencesService.FindEffective(itinerary)
+		if err == nil {
+			context.JSON(http.StatusOK, &responses.GetItineraryTravellerPreferencesResponse{Overrides: overrides, Effective: effective})
+			return
+		}
+	}
+
+	log.Errorf("Error retrieving traveller preferences of itinerary %d: %v", itinerary.ID, err)
+	context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not get traveller preferences. Try again later."})
+}
+
+// updateItineraryTravellerPreferences godoc
+// @Summary      Override the traveller preferences for an itinerary
+// @Description  Replaces the overrides of the traveller preferences of the owner for an itinerary, e.g. to generate it for a trip with kids. Only the set attributes override the preferences of the owner. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Param        preferences  body  requests.TravellerPreferencesRequest  true  "Overrides of the traveller preferences"
+// @Success      200  {object}  responses.UpdateTravellerPreferencesResponse  "Traveller preferences updated."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not update traveller preferences. Try again later."
+// @Router       /itineraries/{itineraryId}/preferences [put]
+func updateItineraryTravellerPreferences(context *gin.Context) {
+	log.Debug("Updating itinerary traveller preferences")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	preferences := bindTravellerPreferences(context)
+	if preferences == nil {
+		return
+	}
+
+	err := services.GetTravellerPreferencesService().SaveForItinerary(itinerary, preferences, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error updating traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not update traveller preferences. Try again later."})
+		return
+	}
+
+	log.Debugf("Traveller preferences of itinerary %d updated", itinerary.ID)
+	context.JSON(http.StatusOK, &responses.UpdateTravellerPreferencesResponse{Message: "Traveller preferences updated.", Preferences: preferences})
+}
+
+// deleteItineraryTravellerPreferences godoc
+// @Summary      Remove the traveller preferences of an itinerary
+// @Description  Removes the overrides of the traveller preferences for an itinerary, so it is generated with the preferences of its owner again. The user must own the itinerary or be one of its editors.
+// @Tags         itineraries
+// @Produce      json
+// @Security     Auth
+// @Param        itineraryId  path  int  true  "Itinerary ID"
+// @Success      200  {object}  responses.DeleteItineraryTravellerPreferencesResponse  "Traveller preferences of the itinerary removed."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      404  {object}  responses.ErrorResponse  "Itinerary not found."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not remove traveller preferences. Try again later."
+// @Router       /itineraries/{itineraryId}/preferences [delete]
+func deleteItineraryTravellerPreferences(context *gin.Context) {
+	log.Debug("Deleting itinerary traveller preferences")
+
+	itinerary := getAndValidateItinerary(context, false, models.ItineraryPermissionEditor)
+	if itinerary == nil {
+		return
+	}
+
+	err := services.GetTravellerPreferencesService().DeleteForItinerary(itinerary, context.GetInt64("userId"))
+	if err != nil {
+		log.Errorf("Error deleting traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not remove traveller preferences. Try again later."})
+		return
+	}
+
+	log.Debugf("Traveller preferences of itinerary %d removed", itinerary.ID)
+	context.JSON(http.StatusOK, &responses.DeleteItineraryTravellerPreferencesResponse{Message: "Traveller preferences of the itinerary removed."})
+}
+
+// bindTravellerPreferences binds the traveller preferences of the request body. Sends an error response and returns nil if they are not
+// valid
+func bindTravellerPreferences(context *gin.Context) *models.TravellerPreferences {
+	var input requests.TravellerPreferencesRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. The pace must be relaxed, moderate or intense, the budget level budget, moderate or luxury and the language a BCP 47 tag like es, and none of the attributes can be too large."})
+		return nil
+	}
+
+	return &models.TravellerPreferences{Interests: input.Interests, Pace: input.Pace, BudgetLevel: input.BudgetLevel,
+		DietaryRestrictions: input.DietaryRestrictions, MobilityNeeds: input.MobilityNeeds, Adults: input.Adults, Children: input.Children,
+		Seniors: input.Seniors, Language: input.Language}
+}
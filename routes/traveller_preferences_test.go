@@ -0,0 +1,159 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+type mockTravellerPreferencesService struct {
+	Preferences *models.TravellerPreferences
+	Effective   *models.TravellerPreferences
+	Err         error
+	Saved       *models.TravellerPreferences
+	SavedUserId int64
+	Deleted     bool
+}
+
+func (m *mockTravellerPreferencesService) FindByUserId(_ int64) (*models.TravellerPreferences, error) {
+	return m.Preferences, m.Err
+}
+func (m *mockTravellerPreferencesService) SaveForUser(userId int64, preferences *models.TravellerPreferences) error {
+	m.Saved = preferences
+	m.SavedUserId = userId
+	return m.Err
+}
+func (m *mockTravellerPreferencesService) FindByItineraryId(_ int64) (*models.TravellerPreferences, error) {
+	return m.Preferences, m.Err
+}
+func (m *mockTravellerPreferencesService) SaveForItinerary(_ *models.Itinerary, preferences *models.TravellerPreferences, _ int64) error {
+	m.Saved = preferences
+	return m.Err
+}
+func (m *mockTravellerPreferencesService) DeleteForItinerary(_ *models.Itinerary, _ int64) error {
+	m.Deleted = true
+	return m.Err
+}
+func (m *mockTravellerPreferencesService) FindEffective(_ *models.Itinerary) (*models.TravellerPreferences, error) {
+	return m.Effective, m.Err
+}
+
+func setMockTravellerPreferencesService(mock *mockTravellerPreferencesService) func() {
+	orig := services.GetTravellerPreferencesService
+	services.GetTravellerPreferencesService = func() services.TravellerPreferencesServiceInterface {
+		return mock
+	}
+	return func() { services.GetTravellerPreferencesService = orig }
+}
+
+// --- Tests ---
+
+func TestGetMyTravellerPreferences_Success(t *testing.T) {
+	defer setMockTravellerPreferencesService(&mockTravellerPreferencesService{Preferences: &models.TravellerPreferences{Pace: "relaxed"}})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", nil)
+	getMyTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"pace":"relaxed"`)
+}
+
+func TestUpdateMyTravellerPreferences_Success(t *testing.T) {
+	preferencesService := &mockTravellerPreferencesService{}
+	defer setMockTravellerPreferencesService(preferencesService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut,
+		`{"interests":["museums"],"pace":"relaxed","budgetLevel":"luxury","dietaryRestrictions":["vegan"],"children":0,"language":"pt-BR"}`, nil)
+	updateMyTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), preferencesService.SavedUserId)
+	assert.Equal(t, []string{"museums"}, preferencesService.Saved.Interests)
+	assert.Equal(t, 0, *preferencesService.Saved.Children)
+	assert.Nil(t, preferencesService.Saved.Adults)
+	assert.Equal(t, "pt-BR", preferencesService.Saved.Language)
+}
+
+func TestUpdateMyTravellerPreferences_Invalid(t *testing.T) {
+	for _, body := range []string{`{"pace":"fast"}`, `{"budgetLevel":"cheap"}`, `{"language":"not a language"}`, `{"adults":-1}`, `{"interests":[""]}`} {
+		preferencesService := &mockTravellerPreferencesService{}
+		restore := setMockTravellerPreferencesService(preferencesService)
+
+		c, w := newAuthenticatedContext(http.MethodPut, body, nil)
+		updateMyTravellerPreferences(c)
+		restore()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Nil(t, preferencesService.Saved, body)
+	}
+}
+
+func TestGetItineraryTravellerPreferences_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	defer setMockTravellerPreferencesService(&mockTravellerPreferencesService{
+		Preferences: &models.TravellerPreferences{Pace: "relaxed"},
+		Effective:   &models.TravellerPreferences{Pace: "relaxed", Language: "es"},
+	})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"overrides":{"pace":"relaxed"}`)
+	assert.Contains(t, w.Body.String(), `"language":"es"`)
+}
+
+func TestGetItineraryTravellerPreferences_Error(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	defer setMockTravellerPreferencesService(&mockTravellerPreferencesService{Err: errors.New("db error")})()
+
+	c, w := newAuthenticatedContext(http.MethodGet, "", itineraryIdParams)
+	getItineraryTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestUpdateItineraryTravellerPreferences_Viewer(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionViewer})()
+	preferencesService := &mockTravellerPreferencesService{}
+	defer setMockTravellerPreferencesService(preferencesService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"children":2}`, itineraryIdParams)
+	updateItineraryTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, preferencesService.Saved)
+}
+
+func TestUpdateItineraryTravellerPreferences_Editor(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 2}})()
+	defer setMockPermissionService(&mockPermissionService{Permission: models.ItineraryPermissionEditor})()
+	preferencesService := &mockTravellerPreferencesService{}
+	defer setMockTravellerPreferencesService(preferencesService)()
+
+	c, w := newAuthenticatedContext(http.MethodPut, `{"children":2}`, itineraryIdParams)
+	updateItineraryTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, *preferencesService.Saved.Children)
+}
+
+func TestDeleteItineraryTravellerPreferences_Success(t *testing.T) {
+	defer setMockItineraryService(&mockItineraryService{FindLightweightByIdIt: &models.Itinerary{ID: 1, OwnerID: 1}})()
+	preferencesService := &mockTravellerPreferencesService{}
+	defer setMockTravellerPreferencesService(preferencesService)()
+
+	c, w := newAuthenticatedContext(http.MethodDelete, "", itineraryIdParams)
+	deleteItineraryTravellerPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, preferencesService.Deleted)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -45,22 +46,17 @@ func signUp(context *gin.Context) {
 		return
 	}
 
-	minUserPasswordLenghtStr := os.Getenv("MIN_USER_PASSWORD_LENGTH")
-	minPasswordLength := 8 // Default min password length
-	if minUserPasswordLenghtStr != "" {
-		minPasswordLength, err = strconv.Atoi(minUserPasswordLenghtStr)
-		if err != nil {
-			log.Errorf("Unexpected error reading min user password length in environment properties")
-			context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create user. Try again later."})
-			return
-		}
+	minPasswordLength, err := getMinPasswordLength()
+	if err != nil {
+		log.Errorf("Unexpected error reading min user password length in environment properties")
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not create user. Try again later."})
+		return
 	}
 
 	isPasswordValid := utils.ValidatePassword(input.Password, minPasswordLength)
 	if !isPasswordValid {
 		log.Errorf("The provided user password is invalid. It must contain at least 1 number, 1 upper case letter and 1 special character")
-		errorMsg := fmt.Sprintf("The provided user password is invalid. It must be at least %d characters long, contain at least 1 number, 1 upper case letter and 1 special character (a punctuation sign or a symbol like @,#,*,etc)", minPasswordLength)
-		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: errorMsg})
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: invalidPasswordMessage(minPasswordLength)})
 		return
 	}
 
@@ -98,6 +94,9 @@ func signUp(context *gin.Context) {
 // @Success      200  {object}  responses.LoginResponse  "Login successful."
 // @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
 // @Failure      401  {object}  responses.ErrorResponse  "Wrong user credentials."
+// @Failure      403  {object}  responses.ErrorResponse  "This account is disabled."
+// @Failure      429  {object}  responses.ErrorResponse  "Too many failed login attempts. Try again later."
+// @Failure      500  {object}  responses.ErrorResponse  "Unexpected error. Try again later."
 // @Router       /login [post]
 func login(context *gin.Context) {
 	log.Debug("Login endpoint called")
@@ -111,19 +110,50 @@ func login(context *gin.Context) {
 		return
 	}
 
+	loginThrottleService := services.GetLoginThrottleService()
+
+	// Reject the attempt early if the account or the source IP is delayed/locked out after repeated failures
+	retryAfter, err := loginThrottleService.CheckLoginAllowed(input.Email, context.ClientIP())
+	if err != nil {
+		log.Errorf("Error checking login attempts: %v", err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Unexpected error. Try again later."})
+		return
+	}
+	if retryAfter > 0 {
+		log.Warnf("Login attempt for %s from %s rejected by brute-force protection", input.Email, context.ClientIP())
+		context.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		context.JSON(http.StatusTooManyRequests, &responses.ErrorResponse{Message: "Too many failed login attempts. Try again later."})
+		return
+	}
+
 	// Create a new User instance using the constructor
 	userService := services.GetUserService()
 
 	user := models.InitUser()
 	user.Email = input.Email
 
-	err := userService.ValidateCredentials(user, input.Password)
+	err = userService.ValidateCredentials(user, input.Password)
 	if err != nil {
 		log.Errorf("Error validating user credentials: %v", err)
+		err = loginThrottleService.RegisterFailedLogin(input.Email, context.ClientIP())
+		if err != nil {
+			log.Errorf("Error registering failed login attempt: %v", err)
+		}
 		context.JSON(http.StatusUnauthorized, &responses.ErrorResponse{Message: "Wrong user credentials."})
 		return
 	}
 
+	if user.DisabledDate != nil {
+		log.Warnf("Login attempt for disabled user %s", user.Email)
+		context.JSON(http.StatusForbidden, &responses.ErrorResponse{Message: "This account is disabled."})
+		return
+	}
+
+	err = loginThrottleService.ResetFailedLogins(input.Email)
+	if err != nil {
+		log.Errorf("Error resetting failed login attempts: %v", err)
+	}
+
 	token, err := userService.GenerateLoginToken(user)
 	if err != nil {
 		log.Errorf("Error generating token: %v", err)
@@ -134,3 +164,54 @@ func login(context *gin.Context) {
 	log.Debugf("User %s logged in successfully", user.Email)
 	context.JSON(http.StatusOK, &responses.LoginResponse{Message: "Login successful!", Token: token})
 }
+
+// unlockUserAccount godoc
+// @Summary      Unlock a user account
+// @Description  Removes the login delay or temporary lockout applied to an account after repeated failed logins. Only available for support staff and administrators.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     Auth
+// @Param        user  body  requests.UnlockUserRequest  true  "Account to unlock"
+// @Success      200  {object}  responses.UnlockUserResponse  "User account unlocked."
+// @Failure      400  {object}  responses.ErrorResponse  "Could not parse request data."
+// @Failure      401  {object}  responses.ErrorResponse  "Not authorized."
+// @Failure      403  {object}  responses.ErrorResponse  "You do not have permission to access this resource."
+// @Failure      500  {object}  responses.ErrorResponse  "Could not unlock user account. Try again later."
+// @Router       /admin/users/unlock [post]
+func unlockUserAccount(context *gin.Context) {
+	log.Debug("Unlock user account endpoint called")
+
+	var input requests.UnlockUserRequest
+
+	// Bind JSON input to the input struct
+	if err := context.ShouldBindJSON(&input); err != nil {
+		log.Errorf("Error parsing JSON: %v", err)
+		context.JSON(http.StatusBadRequest, &responses.ErrorResponse{Message: "Could not parse request data. One or more mandatory attributes are null/empty or at least one of the expected attributes is too large."})
+		return
+	}
+
+	loginThrottleService := services.GetLoginThrottleService()
+
+	err := loginThrottleService.UnlockAccount(input.Email)
+	if err != nil {
+		log.Errorf("Error unlocking user account %s: %v", input.Email, err)
+		context.JSON(http.StatusInternalServerError, &responses.ErrorResponse{Message: "Could not unlock user account. Try again later."})
+		return
+	}
+
+	log.Infof("User account %s unlocked", input.Email)
+	context.JSON(http.StatusOK, &responses.UnlockUserResponse{Message: "User account unlocked."})
+}
+
+func getMinPasswordLength() (int, error) {
+	minUserPasswordLenghtStr := os.Getenv("MIN_USER_PASSWORD_LENGTH")
+	if minUserPasswordLenghtStr == "" {
+		return 8, nil // Default min password length
+	}
+	return strconv.Atoi(minUserPasswordLenghtStr)
+}
+
+func invalidPasswordMessage(minPasswordLength int) string {
+	return fmt.Sprintf("The provided user password is invalid. It must be at least %d characters long, contain at least 1 number, 1 upper case letter and 1 special character (a punctuation sign or a symbol like @,#,*,etc)", minPasswordLength)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"example.com/travel-advisor/models"
 	"example.com/travel-advisor/services"
@@ -16,10 +17,42 @@ import (
 // --- Mocks ---
 
 type mockUserService struct {
+	findByIdFunc            func(id int64) (*models.User, error)
 	findByEmailFunc         func(email string) (*models.User, error)
+	findAllFunc             func() ([]*models.User, error)
 	createFunc              func(user *models.User) error
 	validateCredentialsFunc func(user *models.User, password string) error
 	generateLoginTokenFunc  func(user *models.User) (string, error)
+	updateRoleFunc          func(userId int64, role string, actorId int64) error
+	updateDisabledFunc      func(userId int64, disabled bool, actorId int64) error
+	updateEmailFunc         func(userId int64, email string) error
+	updatePasswordFunc      func(userId int64, password string) error
+	deleteFunc              func(userId int64) error
+}
+
+func (m *mockUserService) FindById(id int64) (*models.User, error) {
+	return m.findByIdFunc(id)
+}
+func (m *mockUserService) FindAll() ([]*models.User, error) {
+	return m.findAllFunc()
+}
+func (m *mockUserService) UpdateRole(userId int64, role string, actorId int64) error {
+	return m.updateRoleFunc(userId, role, actorId)
+}
+func (m *mockUserService) UpdateDisabled(userId int64, disabled bool, actorId int64) error {
+	return m.updateDisabledFunc(userId, disabled, actorId)
+}
+func (m *mockUserService) EnsureAdmin(email string) error {
+	return nil // unused in routes
+}
+func (m *mockUserService) UpdateEmail(userId int64, email string) error {
+	return m.updateEmailFunc(userId, email)
+}
+func (m *mockUserService) UpdatePassword(userId int64, password string) error {
+	return m.updatePasswordFunc(userId, password)
+}
+func (m *mockUserService) Delete(userId int64) error {
+	return m.deleteFunc(userId)
 }
 
 func (m *mockUserService) FindByEmail(email string) (*models.User, error) {
@@ -35,6 +68,63 @@ func (m *mockUserService) GenerateLoginToken(user *models.User) (string, error)
 	return m.generateLoginTokenFunc(user)
 }
 
+type mockLoginThrottleService struct {
+	checkLoginAllowedFunc   func(email string, sourceIp string) (time.Duration, error)
+	registerFailedLoginFunc func(email string, sourceIp string) error
+	resetFailedLoginsFunc   func(email string) error
+	unlockAccountFunc       func(email string) error
+	deleteExpiredFunc       func() error
+}
+
+func (m *mockLoginThrottleService) CheckLoginAllowed(email string, sourceIp string) (time.Duration, error) {
+	if m.checkLoginAllowedFunc == nil {
+		return 0, nil
+	}
+	return m.checkLoginAllowedFunc(email, sourceIp)
+}
+func (m *mockLoginThrottleService) RegisterFailedLogin(email string, sourceIp string) error {
+	if m.registerFailedLoginFunc == nil {
+		return nil
+	}
+	return m.registerFailedLoginFunc(email, sourceIp)
+}
+func (m *mockLoginThrottleService) ResetFailedLogins(email string) error {
+	if m.resetFailedLoginsFunc == nil {
+		return nil
+	}
+	return m.resetFailedLoginsFunc(email)
+}
+func (m *mockLoginThrottleService) UnlockAccount(email string) error {
+	if m.unlockAccountFunc == nil {
+		return nil
+	}
+	return m.unlockAccountFunc(email)
+}
+func (m *mockLoginThrottleService) CheckSharePasswordAllowed(shareLinkId int64, sourceIp string) (time.Duration, error) {
+	return 0, nil
+}
+func (m *mockLoginThrottleService) RegisterFailedSharePassword(shareLinkId int64, sourceIp string) error {
+	return nil
+}
+func (m *mockLoginThrottleService) ResetFailedSharePasswords(shareLinkId int64, sourceIp string) error {
+	return nil
+}
+func (m *mockLoginThrottleService) DeleteExpiredAttempts() error {
+	if m.deleteExpiredFunc == nil {
+		return nil
+	}
+	return m.deleteExpiredFunc()
+}
+
+// Patch services.GetLoginThrottleService to return our mock
+func setMockLoginThrottleService(mock services.LoginThrottleServiceInterface) func() {
+	orig := services.GetLoginThrottleService
+	services.GetLoginThrottleService = func() services.LoginThrottleServiceInterface {
+		return mock
+	}
+	return func() { services.GetLoginThrottleService = orig }
+}
+
 // Patch services.GetUserService to return our mock
 func setMockUserService(mock services.UserServiceInterface) func() {
 	orig := services.GetUserService
@@ -310,6 +400,9 @@ func TestLogin_Success(t *testing.T) {
 	restoreSvc := setMockUserService(mockSvc)
 	defer restoreSvc()
 
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
 	body := []byte(`{"email":"test@example.com","password":"Password123-"}`)
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -324,6 +417,36 @@ func TestLogin_Success(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "mocktoken")
 }
 
+func TestLogin_DisabledAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := &mockUserService{
+		validateCredentialsFunc: func(user *models.User, password string) error {
+			disabledDate := time.Now()
+			user.DisabledDate = &disabledDate
+			return nil
+		},
+		generateLoginTokenFunc: func(user *models.User) (string, error) { return "mocktoken", nil },
+	}
+	restoreSvc := setMockUserService(mockSvc)
+	defer restoreSvc()
+
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
+	body := []byte(`{"email":"test@example.com","password":"Password123-"}`)
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	login(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "This account is disabled.")
+	assert.NotContains(t, w.Body.String(), "mocktoken")
+}
+
 func TestLogin_BadRequest(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer([]byte(`{bad json`)))
@@ -346,6 +469,9 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 	restore := setMockUserService(mockSvc)
 	defer restore()
 
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
 	body := []byte(`{"email":"test@example.com","password":"wrongpass"}`)
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -368,6 +494,9 @@ func TestLogin_TokenError(t *testing.T) {
 	restoreSvc := setMockUserService(mockSvc)
 	defer restoreSvc()
 
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{})
+	defer restoreThrottle()
+
 	body := []byte(`{"email":"test@example.com","password":"Password123-"}`)
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -380,3 +509,140 @@ func TestLogin_TokenError(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	assert.Contains(t, w.Body.String(), "Unexpected error.")
 }
+
+func TestLogin_TooManyFailedAttempts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validateCalled := false
+	mockSvc := &mockUserService{
+		validateCredentialsFunc: func(user *models.User, password string) error {
+			validateCalled = true
+			return nil
+		},
+	}
+	restore := setMockUserService(mockSvc)
+	defer restore()
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		checkLoginAllowedFunc: func(email string, sourceIp string) (time.Duration, error) { return 90 * time.Second, nil },
+	})
+	defer restoreThrottle()
+
+	body := []byte(`{"email":"test@example.com","password":"Password123-"}`)
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	login(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Too many failed login attempts")
+	assert.False(t, validateCalled, "credentials should not be checked while locked out")
+}
+
+func TestLogin_CheckLoginAllowedError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		checkLoginAllowedFunc: func(email string, sourceIp string) (time.Duration, error) { return 0, errors.New("db error") },
+	})
+	defer restoreThrottle()
+
+	body := []byte(`{"email":"test@example.com","password":"Password123-"}`)
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	login(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestLogin_InvalidCredentialsRegistersFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := &mockUserService{
+		validateCredentialsFunc: func(user *models.User, password string) error { return errors.New("invalid") },
+	}
+	restore := setMockUserService(mockSvc)
+	defer restore()
+
+	registeredEmail := ""
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		registerFailedLoginFunc: func(email string, sourceIp string) error {
+			registeredEmail = email
+			return nil
+		},
+	})
+	defer restoreThrottle()
+
+	body := []byte(`{"email":"test@example.com","password":"wrongpass"}`)
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	login(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "test@example.com", registeredEmail)
+}
+
+func TestUnlockUserAccount_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	unlockedEmail := ""
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		unlockAccountFunc: func(email string) error {
+			unlockedEmail = email
+			return nil
+		},
+	})
+	defer restoreThrottle()
+
+	body := []byte(`{"email":"test@example.com"}`)
+	req, _ := http.NewRequest("POST", "/admin/users/unlock", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	unlockUserAccount(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "test@example.com", unlockedEmail)
+}
+
+func TestUnlockUserAccount_BadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req, _ := http.NewRequest("POST", "/admin/users/unlock", bytes.NewBuffer([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	unlockUserAccount(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUnlockUserAccount_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	restoreThrottle := setMockLoginThrottleService(&mockLoginThrottleService{
+		unlockAccountFunc: func(email string) error { return errors.New("db error") },
+	})
+	defer restoreThrottle()
+
+	body := []byte(`{"email":"test@example.com"}`)
+	req, _ := http.NewRequest("POST", "/admin/users/unlock", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	unlockUserAccount(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Could not unlock user account")
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type ApiKeyServiceInterface interface {
+	Validate(apiKey *models.ApiKey) error
+	Create(apiKey *models.ApiKey) (string, error)
+	FindByUserId(userId int64) ([]*models.ApiKey, error)
+	Revoke(userId int64, apiKeyId int64) error
+	Authenticate(rawApiKey string) (*models.ApiKey, error)
+}
+
+type ApiKeyService struct{}
+
+// singleton instance
+var apiKeyServiceInstance = &ApiKeyService{}
+
+// GetApiKeyService returns the singleton instance of ApiKeyService
+var GetApiKeyService = func() ApiKeyServiceInterface {
+	return apiKeyServiceInstance
+}
+
+// Validate checks the name, scopes and expiration date of a new API key
+func (aks *ApiKeyService) Validate(apiKey *models.ApiKey) error {
+	if apiKey == nil {
+		log.Error("API key instance is nil")
+		return errors.New("API key instance is nil")
+	}
+
+	if strings.TrimSpace(apiKey.Name) == "" {
+		log.Error("API key name is empty")
+		return errors.New("API key name cannot be empty")
+	}
+
+	if len(apiKey.Scopes) == 0 {
+		log.Error("API key has no scopes")
+		return errors.New("at least one API key scope is required")
+	}
+
+	for _, scope := range apiKey.Scopes {
+		if !slices.Contains(models.ApiKeyScopes, scope) {
+			log.Errorf("Unknown API key scope %s", scope)
+			return errors.New("unknown API key scope: " + scope)
+		}
+	}
+
+	if apiKey.ExpirationDate != nil && !apiKey.ExpirationDate.After(time.Now()) {
+		log.Error("API key expiration date is not in the future")
+		return errors.New("API key expiration date must be in the future")
+	}
+
+	return nil
+}
+
+// Create validates and stores a new API key. The generated key is returned in clear only once, since just its hash is persisted.
+func (aks *ApiKeyService) Create(apiKey *models.ApiKey) (string, error) {
+	err := aks.Validate(apiKey)
+	if err != nil {
+		return "", err
+	}
+
+	slices.Sort(apiKey.Scopes)
+	apiKey.Scopes = slices.Compact(apiKey.Scopes)
+
+	rawApiKey, err := utils.GenerateApiKey()
+	if err != nil {
+		log.Errorf("Error generating API key: %v", err)
+		return "", errors.New("error generating API key")
+	}
+
+	apiKey.KeyPrefix = rawApiKey[:utils.ApiKeyDisplayPrefixLength]
+	apiKey.KeyHash = utils.HashApiKey(rawApiKey)
+
+	err = apiKey.Create()
+	if err != nil {
+		return "", err
+	}
+
+	return rawApiKey, nil
+}
+
+// FindByUserId retrieves all the API keys of a user, including the revoked and expired ones
+func (aks *ApiKeyService) FindByUserId(userId int64) ([]*models.ApiKey, error) {
+	if userId <= 0 {
+		log.Error("Invalid user ID provided")
+		return nil, errors.New("invalid user ID")
+	}
+	return models.InitApiKey().FindByUserId(userId)
+}
+
+// Revoke revokes an API key of the given user. Keys owned by other users are reported as not found.
+func (aks *ApiKeyService) Revoke(userId int64, apiKeyId int64) error {
+	if apiKeyId <= 0 {
+		log.Error("Invalid API key ID provided")
+		return errors.New("invalid API key ID")
+	}
+
+	apiKey, err := models.InitApiKey().FindById(apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	if apiKey.UserID != userId {
+		log.Errorf("API key %d does not belong to user %d", apiKeyId, userId)
+		return sql.ErrNoRows
+	}
+
+	if apiKey.RevocationDate != nil {
+		log.Warnf("API key %d is already revoked", apiKeyId)
+		return nil
+	}
+
+	apiKey = models.InitApiKeyFunctions(apiKey)
+	return apiKey.Revoke()
+}
+
+// Authenticate returns the active API key matching the given clear key and records when it was used
+func (aks *ApiKeyService) Authenticate(rawApiKey string) (*models.ApiKey, error) {
+	if !utils.IsApiKey(rawApiKey) {
+		return nil, errors.New("invalid API key")
+	}
+
+	apiKey, err := models.InitApiKey().FindByHash(utils.HashApiKey(rawApiKey))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invalid API key")
+		}
+		log.Errorf("Error retrieving API key: %v", err)
+		return nil, errors.New("error retrieving API key")
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		log.Warnf("API key %d is revoked or expired", apiKey.ID)
+		return nil, errors.New("API key is revoked or expired")
+	}
+
+	apiKey = models.InitApiKeyFunctions(apiKey)
+	err = apiKey.UpdateLastUsedDate(now)
+	if err != nil {
+		// Not being able to record the usage must not block the request
+		log.Errorf("Error updating last used date of API key %d: %v", apiKey.ID, err)
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyService_Validate(t *testing.T) {
+	svc := GetApiKeyService()
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	assert.EqualError(t, svc.Validate(nil), "API key instance is nil")
+	assert.EqualError(t, svc.Validate(&models.ApiKey{Name: " ", Scopes: []string{models.ApiKeyScopeJobsRead}}), "API key name cannot be empty")
+	assert.EqualError(t, svc.Validate(&models.ApiKey{Name: "CI"}), "at least one API key scope is required")
+	assert.EqualError(t, svc.Validate(&models.ApiKey{Name: "CI", Scopes: []string{"users:write"}}), "unknown API key scope: users:write")
+	assert.EqualError(t, svc.Validate(&models.ApiKey{Name: "CI", Scopes: []string{models.ApiKeyScopeJobsRead}, ExpirationDate: &past}), "API key expiration date must be in the future")
+	assert.NoError(t, svc.Validate(&models.ApiKey{Name: "CI", Scopes: []string{models.ApiKeyScopeJobsRead}, ExpirationDate: &future}))
+}
+
+func TestApiKeyService_Create_Success(t *testing.T) {
+	var created *models.ApiKey
+	apiKey := &models.ApiKey{UserID: 1, Name: "CI", Scopes: []string{models.ApiKeyScopeJobsWrite, models.ApiKeyScopeItinerariesRead, models.ApiKeyScopeJobsWrite}}
+	apiKey.Create = func() error {
+		created = apiKey
+		return nil
+	}
+
+	rawApiKey, err := GetApiKeyService().Create(apiKey)
+	assert.NoError(t, err)
+	assert.True(t, utils.IsApiKey(rawApiKey))
+	assert.Same(t, apiKey, created)
+	assert.Equal(t, rawApiKey[:utils.ApiKeyDisplayPrefixLength], apiKey.KeyPrefix)
+	assert.Equal(t, utils.HashApiKey(rawApiKey), apiKey.KeyHash)
+	assert.Equal(t, []string{models.ApiKeyScopeItinerariesRead, models.ApiKeyScopeJobsWrite}, apiKey.Scopes)
+}
+
+func TestApiKeyService_Create_GenerateError(t *testing.T) {
+	origGenerate := utils.GenerateApiKey
+	defer func() { utils.GenerateApiKey = origGenerate }()
+	utils.GenerateApiKey = func() (string, error) { return "", errors.New("entropy error") }
+
+	_, err := GetApiKeyService().Create(&models.ApiKey{Name: "CI", Scopes: []string{models.ApiKeyScopeJobsRead}})
+	assert.EqualError(t, err, "error generating API key")
+}
+
+func TestApiKeyService_FindByUserId_InvalidId(t *testing.T) {
+	apiKeys, err := GetApiKeyService().FindByUserId(0)
+	assert.Nil(t, apiKeys)
+	assert.EqualError(t, err, "invalid user ID")
+}
+
+func mockInitApiKey(found *models.ApiKey, findErr error) func() {
+	origInit := models.InitApiKey
+	origInitFunctions := models.InitApiKeyFunctions
+
+	models.InitApiKey = func() *models.ApiKey {
+		return &models.ApiKey{
+			FindById:   func(id int64) (*models.ApiKey, error) { return found, findErr },
+			FindByHash: func(keyHash string) (*models.ApiKey, error) { return found, findErr },
+		}
+	}
+
+	return func() {
+		models.InitApiKey = origInit
+		models.InitApiKeyFunctions = origInitFunctions
+	}
+}
+
+func TestApiKeyService_Revoke_Success(t *testing.T) {
+	restore := mockInitApiKey(&models.ApiKey{ID: 3, UserID: 1}, nil)
+	defer restore()
+
+	revoked := false
+	models.InitApiKeyFunctions = func(apiKey *models.ApiKey) *models.ApiKey {
+		apiKey.Revoke = func() error {
+			revoked = true
+			return nil
+		}
+		return apiKey
+	}
+
+	err := GetApiKeyService().Revoke(1, 3)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestApiKeyService_Revoke_OtherUser(t *testing.T) {
+	restore := mockInitApiKey(&models.ApiKey{ID: 3, UserID: 2}, nil)
+	defer restore()
+
+	err := GetApiKeyService().Revoke(1, 3)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestApiKeyService_Revoke_AlreadyRevoked(t *testing.T) {
+	revocationDate := time.Now()
+	restore := mockInitApiKey(&models.ApiKey{ID: 3, UserID: 1, RevocationDate: &revocationDate}, nil)
+	defer restore()
+
+	models.InitApiKeyFunctions = func(apiKey *models.ApiKey) *models.ApiKey {
+		t.Fatal("an already revoked key must not be revoked again")
+		return apiKey
+	}
+
+	assert.NoError(t, GetApiKeyService().Revoke(1, 3))
+}
+
+func TestApiKeyService_Authenticate_Success(t *testing.T) {
+	restore := mockInitApiKey(&models.ApiKey{ID: 3, UserID: 1, Scopes: []string{models.ApiKeyScopeJobsRead}}, nil)
+	defer restore()
+
+	var lastUsed time.Time
+	models.InitApiKeyFunctions = func(apiKey *models.ApiKey) *models.ApiKey {
+		apiKey.UpdateLastUsedDate = func(lastUsedDate time.Time) error {
+			lastUsed = lastUsedDate
+			return nil
+		}
+		return apiKey
+	}
+
+	apiKey, err := GetApiKeyService().Authenticate("tak_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), apiKey.UserID)
+	assert.False(t, lastUsed.IsZero())
+}
+
+func TestApiKeyService_Authenticate_LastUsedErrorIsIgnored(t *testing.T) {
+	restore := mockInitApiKey(&models.ApiKey{ID: 3, UserID: 1}, nil)
+	defer restore()
+
+	models.InitApiKeyFunctions = func(apiKey *models.ApiKey) *models.ApiKey {
+		apiKey.UpdateLastUsedDate = func(lastUsedDate time.Time) error { return errors.New("db error") }
+		return apiKey
+	}
+
+	apiKey, err := GetApiKeyService().Authenticate("tak_secret")
+	assert.NoError(t, err)
+	assert.NotNil(t, apiKey)
+}
+
+func TestApiKeyService_Authenticate_Expired(t *testing.T) {
+	expiration := time.Now().Add(-time.Minute)
+	restore := mockInitApiKey(&models.ApiKey{ID: 3, UserID: 1, ExpirationDate: &expiration}, nil)
+	defer restore()
+
+	apiKey, err := GetApiKeyService().Authenticate("tak_secret")
+	assert.Nil(t, apiKey)
+	assert.EqualError(t, err, "API key is revoked or expired")
+}
+
+func TestApiKeyService_Authenticate_Unknown(t *testing.T) {
+	restore := mockInitApiKey(nil, sql.ErrNoRows)
+	defer restore()
+
+	apiKey, err := GetApiKeyService().Authenticate("tak_unknown")
+	assert.Nil(t, apiKey)
+	assert.EqualError(t, err, "invalid API key")
+}
+
+func TestApiKeyService_Authenticate_NotAnApiKey(t *testing.T) {
+	apiKey, err := GetApiKeyService().Authenticate("jwt-token")
+	assert.Nil(t, apiKey)
+	assert.EqualError(t, err, "invalid API key")
+}
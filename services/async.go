@@ -15,6 +15,7 @@ import (
 type AsyncTaskQueueInterface interface {
 	Close()
 	EnqueueItineraryFileJob(itineraryTaskPayload ItineraryFileAsyncTaskPayload) (*string, error)
+	EnqueueDataExportJob(dataExportTaskPayload DataExportAsyncTaskPayload) (*string, error)
 }
 
 type AsyncQueueClientInteface interface {
@@ -67,6 +68,24 @@ func (q *AsyncqTaskQueue) EnqueueItineraryFileJob(itineraryTaskPayload Itinerary
 		return nil, err
 	}
 
+	return q.enqueue(TypeItineraryFileGeneration, asyncTaskPayloadJson)
+}
+
+func (q *AsyncqTaskQueue) EnqueueDataExportJob(dataExportTaskPayload DataExportAsyncTaskPayload) (*string, error) {
+
+	asyncTaskPayloadJson, err := json.Marshal(dataExportTaskPayload)
+	if err != nil {
+		log.Errorf("could not marshal data export job payload: %v", err)
+		return nil, err
+	}
+
+	return q.enqueue(TypeDataExport, asyncTaskPayloadJson)
+}
+
+// enqueue sends a task with the given type and payload to the queue, applying the configured async task timeout and no retries
+func (q *AsyncqTaskQueue) enqueue(taskType string, asyncTaskPayloadJson []byte) (*string, error) {
+	var err error
+
 	asyncTaskTimeoutStr := os.Getenv("ASYNC_TASK_TIMEOUT_MINUTES")
 	var asyncTaskTimeoutMinutes int
 	if asyncTaskTimeoutStr != "" {
@@ -79,14 +98,14 @@ func (q *AsyncqTaskQueue) EnqueueItineraryFileJob(itineraryTaskPayload Itinerary
 		asyncTaskTimeoutMinutes = 10 // default timeout in minutes if not set
 	}
 
-	asyncTask := asynq.NewTask(TypeItineraryFileGeneration, asyncTaskPayloadJson, asynq.MaxRetry(0), asynq.Timeout(time.Duration(asyncTaskTimeoutMinutes)*time.Minute))
+	asyncTask := asynq.NewTask(taskType, asyncTaskPayloadJson, asynq.MaxRetry(0), asynq.Timeout(time.Duration(asyncTaskTimeoutMinutes)*time.Minute))
 
 	info, err := q.Client.Enqueue(asyncTask)
 	if err != nil {
-		log.Errorf("could not enqueue itinerary file job task: %v", err)
+		log.Errorf("could not enqueue %s task: %v", taskType, err)
 		return nil, err
 	}
-	log.Debugf("enqueued itinerary file job task: id=%s queue=%s", info.ID, info.Queue)
+	log.Debugf("enqueued %s task: id=%s queue=%s", taskType, info.ID, info.Queue)
 
 	return &info.ID, nil
 
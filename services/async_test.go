@@ -76,7 +76,7 @@ func TestAsyncqTaskQueue_Close_Error(t *testing.T) {
 
 func TestAsyncqTaskQueue_EnqueueItineraryFileJob_Success(t *testing.T) {
 	mockClient := new(MockAsynqClient)
-	payload := ItineraryFileAsyncTaskPayload{&models.Itinerary{}, &models.ItineraryFileJob{}}
+	payload := ItineraryFileAsyncTaskPayload{Itinerary: &models.Itinerary{}, ItineraryFileJob: &models.ItineraryFileJob{}}
 	os.Setenv("ASYNC_TASK_TIMEOUT_MINUTES", "1")
 	defer os.Unsetenv("ASYNC_TASK_TIMEOUT_MINUTES")
 
@@ -91,7 +91,7 @@ func TestAsyncqTaskQueue_EnqueueItineraryFileJob_Success(t *testing.T) {
 
 func TestAsyncqTaskQueue_EnqueueItineraryFileJob_TimeoutParseError(t *testing.T) {
 	mockClient := new(MockAsynqClient)
-	payload := ItineraryFileAsyncTaskPayload{&models.Itinerary{}, &models.ItineraryFileJob{}}
+	payload := ItineraryFileAsyncTaskPayload{Itinerary: &models.Itinerary{}, ItineraryFileJob: &models.ItineraryFileJob{}}
 	os.Setenv("ASYNC_TASK_TIMEOUT_MINUTES", "notanint")
 	defer os.Unsetenv("ASYNC_TASK_TIMEOUT_MINUTES")
 
@@ -102,7 +102,7 @@ func TestAsyncqTaskQueue_EnqueueItineraryFileJob_TimeoutParseError(t *testing.T)
 
 func TestAsyncqTaskQueue_EnqueueItineraryFileJob_EnqueueError(t *testing.T) {
 	mockClient := new(MockAsynqClient)
-	payload := ItineraryFileAsyncTaskPayload{&models.Itinerary{}, &models.ItineraryFileJob{}}
+	payload := ItineraryFileAsyncTaskPayload{Itinerary: &models.Itinerary{}, ItineraryFileJob: &models.ItineraryFileJob{}}
 	os.Unsetenv("ASYNC_TASK_TIMEOUT_MINUTES")
 
 	mockClient.On("Enqueue", mock.AnythingOfType("*asynq.Task"), mock.Anything).Return(nil, errors.New("enqueue error"))
@@ -114,3 +114,28 @@ func TestAsyncqTaskQueue_EnqueueItineraryFileJob_EnqueueError(t *testing.T) {
 
 //Note: it is not possible to unit test the json.Marshall error case for EnquqeItineraryFileJob.
 // The json.Marshal function will not return an error for the given ItineraryFileAsyncTaskPayload struct, so this case is not testable.
+
+func TestAsyncqTaskQueue_EnqueueDataExportJob_Success(t *testing.T) {
+	mockClient := new(MockAsynqClient)
+	payload := DataExportAsyncTaskPayload{&models.DataExportJob{ID: 1}, 2}
+	os.Unsetenv("ASYNC_TASK_TIMEOUT_MINUTES")
+
+	mockClient.On("Enqueue", mock.MatchedBy(func(task *asynq.Task) bool { return task.Type() == TypeDataExport }), mock.Anything).Return(&asynq.TaskInfo{ID: "taskid", Queue: "default"}, nil)
+
+	queue := newAsyncqTaskQueueWithMock(mockClient)
+	id, err := queue.EnqueueDataExportJob(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "taskid", *id)
+}
+
+func TestAsyncqTaskQueue_EnqueueDataExportJob_EnqueueError(t *testing.T) {
+	mockClient := new(MockAsynqClient)
+	payload := DataExportAsyncTaskPayload{&models.DataExportJob{ID: 1}, 2}
+	os.Unsetenv("ASYNC_TASK_TIMEOUT_MINUTES")
+
+	mockClient.On("Enqueue", mock.AnythingOfType("*asynq.Task"), mock.Anything).Return(nil, errors.New("enqueue error"))
+
+	queue := newAsyncqTaskQueueWithMock(mockClient)
+	_, err := queue.EnqueueDataExportJob(payload)
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/travel-advisor/db"
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAuditEventsPageSize = 50
+	maxAuditEventsPageSize     = 200
+)
+
+type AuditServiceInterface interface {
+	FindEvents(query AuditEventsQuery) (*AuditEventsPage, error)
+}
+
+type AuditService struct{}
+
+// singleton instance
+var auditServiceInstance = &AuditService{}
+
+// GetAuditService returns the singleton instance of AuditService
+var GetAuditService = func() AuditServiceInterface {
+	return auditServiceInstance
+}
+
+// AuditEventsQuery holds the filters of an audit events search. UserID 0 searches the events of all users
+type AuditEventsQuery struct {
+	UserID    int64
+	EventType string
+	From      *time.Time
+	To        *time.Time
+	Cursor    string
+	Limit     int
+}
+
+// AuditEventsPage is a page of audit events from the newest to the oldest. NextCursor is empty on the last page
+type AuditEventsPage struct {
+	Events     []*models.AuditEvent
+	NextCursor string
+}
+
+type auditEventsCursor struct {
+	ID int64 `json:"id"`
+}
+
+// saveAuditEvent stores an audit event in its own transaction, for actions whose changes are not done inside one
+var saveAuditEvent = func(userId int64, eventType string, eventDescription string, details map[string]any) (err error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		log.Errorf("Error starting transaction for auditing: %v", err)
+		return errors.New("unexpected error starting transaction")
+	}
+
+	defer db.HandleTransaction(tx, &err)
+
+	auditEvent := models.NewAuditEvent(userId, eventType, eventDescription, details)
+	err = auditEvent.CreateAuditEvent(tx)
+	if err != nil {
+		log.Errorf("Error saving audit event: %v", err)
+		return errors.New("error saving audit event")
+	}
+
+	return nil
+}
+
+// FindEvents retrieves a page of the audit events matching the query, from the newest to the oldest
+func (as *AuditService) FindEvents(query AuditEventsQuery) (*AuditEventsPage, error) {
+	if query.EventType != "" && !models.IsValidAuditEventType(query.EventType) {
+		log.Errorf("invalid audit event type %s", query.EventType)
+		return nil, errors.New("invalid event type")
+	}
+
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		log.Error("the start of the date range is after its end")
+		return nil, errors.New("invalid date range")
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultAuditEventsPageSize
+	}
+	if limit > maxAuditEventsPageSize {
+		limit = maxAuditEventsPageSize
+	}
+
+	filter := models.AuditEventFilter{
+		UserID:    query.UserID,
+		EventType: query.EventType,
+		From:      query.From,
+		To:        query.To,
+		// One more event than requested is fetched to know if there is a next page
+		Limit: limit + 1,
+	}
+
+	if query.Cursor != "" {
+		var cursor auditEventsCursor
+		err := utils.DecodeCursor(query.Cursor, &cursor)
+		if err != nil || cursor.ID <= 0 {
+			log.Errorf("invalid audit events cursor %s", query.Cursor)
+			return nil, errors.New("invalid cursor")
+		}
+		filter.BeforeID = cursor.ID
+	}
+
+	events, err := models.InitAuditEvent().Find(filter)
+	if err != nil {
+		log.Errorf("failed to find audit events: %v", err)
+		return nil, errors.New("failed to find audit events")
+	}
+
+	page := &AuditEventsPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.NextCursor, err = utils.EncodeCursor(auditEventsCursor{ID: page.Events[limit-1].ID})
+		if err != nil {
+			log.Errorf("failed to encode audit events cursor: %v", err)
+			return nil, errors.New("failed to encode cursor")
+		}
+	}
+
+	return page, nil
+}
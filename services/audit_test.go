@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFindAuditEvents(t *testing.T, events []*models.AuditEvent, err error) *models.AuditEventFilter {
+	orig := models.InitAuditEvent
+	t.Cleanup(func() { models.InitAuditEvent = orig })
+
+	filter := &models.AuditEventFilter{}
+	models.InitAuditEvent = func() *models.AuditEvent {
+		return &models.AuditEvent{
+			Find: func(f models.AuditEventFilter) ([]*models.AuditEvent, error) {
+				*filter = f
+				return events, err
+			},
+		}
+	}
+	return filter
+}
+
+func TestFindEvents_LastPage(t *testing.T) {
+	filter := mockFindAuditEvents(t, []*models.AuditEvent{{ID: 2}, {ID: 1}}, nil)
+
+	page, err := (&AuditService{}).FindEvents(AuditEventsQuery{UserID: 3, EventType: models.AuditEventJobStarted})
+	assert.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, int64(3), filter.UserID)
+	assert.Equal(t, models.AuditEventJobStarted, filter.EventType)
+	assert.Equal(t, defaultAuditEventsPageSize+1, filter.Limit)
+}
+
+func TestFindEvents_NextPage(t *testing.T) {
+	mockFindAuditEvents(t, []*models.AuditEvent{{ID: 9}, {ID: 8}, {ID: 7}}, nil)
+
+	page, err := (&AuditService{}).FindEvents(AuditEventsQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	filter := mockFindAuditEvents(t, []*models.AuditEvent{{ID: 7}}, nil)
+	page, err = (&AuditService{}).FindEvents(AuditEventsQuery{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Events, 1)
+	assert.Equal(t, int64(8), filter.BeforeID)
+}
+
+func TestFindEvents_LimitClamped(t *testing.T) {
+	filter := mockFindAuditEvents(t, []*models.AuditEvent{}, nil)
+
+	_, err := (&AuditService{}).FindEvents(AuditEventsQuery{Limit: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, maxAuditEventsPageSize+1, filter.Limit)
+}
+
+func TestFindEvents_InvalidEventType(t *testing.T) {
+	page, err := (&AuditService{}).FindEvents(AuditEventsQuery{EventType: "user.unknown"})
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "invalid event type")
+}
+
+func TestFindEvents_InvalidDateRange(t *testing.T) {
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	page, err := (&AuditService{}).FindEvents(AuditEventsQuery{From: &from, To: &to})
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "invalid date range")
+}
+
+func TestFindEvents_InvalidCursor(t *testing.T) {
+	page, err := (&AuditService{}).FindEvents(AuditEventsQuery{Cursor: "not a cursor"})
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "invalid cursor")
+
+	cursor, _ := utils.EncodeCursor(auditEventsCursor{ID: 0})
+	page, err = (&AuditService{}).FindEvents(AuditEventsQuery{Cursor: cursor})
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "invalid cursor")
+}
+
+func TestFindEvents_Error(t *testing.T) {
+	mockFindAuditEvents(t, nil, errors.New("db error"))
+
+	page, err := (&AuditService{}).FindEvents(AuditEventsQuery{})
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "failed to find audit events")
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+)
+
+// DefaultBudgetCurrency is the currency of the budget summaries of the itineraries without a budget
+const DefaultBudgetCurrency = "EUR"
+
+type BudgetServiceInterface interface {
+	GetSummary(itinerary *models.Itinerary, currency string) (*models.BudgetSummary, error)
+	SaveBudget(itinerary *models.Itinerary, budget *models.ItineraryBudget, actorId int64) error
+	DeleteBudget(itinerary *models.Itinerary, actorId int64) error
+	SaveEstimate(itinerary *models.Itinerary, destinationId int64, estimate *models.DestinationCostEstimate, actorId int64) error
+	DeleteEstimate(itinerary *models.Itinerary, destinationId int64, actorId int64) error
+	GenerateEstimates(ctx context.Context, itinerary *models.Itinerary, currency string, actorId int64) ([]*models.DestinationCostEstimate, error)
+}
+
+type BudgetService struct{}
+
+// singleton instance
+var budgetServiceInstance = &BudgetService{}
+
+// GetBudgetService returns the singleton instance of BudgetService
+var GetBudgetService = func() BudgetServiceInterface {
+	return budgetServiceInstance
+}
+
+// costEstimatesSystemMessage is the system message of the prompt the cost estimates are generated with
+const costEstimatesSystemMessage = "You are an expert in the cost of travelling around the world. You only answer with JSON."
+
+// costEstimatesPromptTemplate is the prompt the cost estimates are generated with
+const costEstimatesPromptTemplate = `Estimate the typical daily spending of the travellers in each of the following destinations, in {{.currency}}:
+{{range .destinations}}
+- Country: {{.country}}, City: {{.city}}
+{{end}}
+{{if .travellerProfile}}
+Traveller profile:
+{{range .travellerProfile}}- {{.}}
+{{end}}{{end}}
+Split the spending of a day into lodging (a night of accommodation), food, local transport and activities (tickets, tours and
+entertainment), for all the travellers together. Answer only with a JSON object like {"estimates": [{"country": "Spain", "city": "Madrid",
+"lodging": 120, "food": 60, "transport": 15, "activities": 40}]}, with one element for each destination and the amounts as numbers in
+{{.currency}}.`
+
+// generatedCostEstimates is the answer the cost estimates are generated with
+type generatedCostEstimates struct {
+	Estimates []struct {
+		Country    string        `json:"country"`
+		City       string        `json:"city"`
+		Lodging    models.Amount `json:"lodging"`
+		Food       models.Amount `json:"food"`
+		Transport  models.Amount `json:"transport"`
+		Activities models.Amount `json:"activities"`
+	} `json:"estimates"`
+}
+
+// GetSummary compares the budget of an itinerary retrieved with its destinations with the estimated cost of the stay in them, converted
+// to the currency, which is the one of the budget if empty
+func (bs *BudgetService) GetSummary(itinerary *models.Itinerary, currency string) (*models.BudgetSummary, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+
+	budget, err := models.InitItineraryBudget().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Error retrieving budget of itinerary %d: %v", itinerary.ID, err)
+			return nil, errors.New("failed to retrieve budget")
+		}
+		budget = nil
+	}
+
+	currency, err = resolveBudgetCurrency(currency, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	estimates, err := models.InitDestinationCostEstimate().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		log.Errorf("Error retrieving cost estimates of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to retrieve cost estimates")
+	}
+
+	summary := &models.BudgetSummary{ItineraryID: itinerary.ID, Currency: currency, Budget: budget, Destinations: []*models.DestinationBudget{}}
+	for _, destination := range itinerary.TravelDestinations {
+		destinationBudget := &models.DestinationBudget{DestinationID: destination.ID, Country: destination.Country, City: destination.City,
+			Days: stayDays(destination), Estimate: findCostEstimate(estimates, destination.Country, destination.City)}
+		summary.Destinations = append(summary.Destinations, destinationBudget)
+		if destinationBudget.Estimate == nil {
+			summary.DestinationsWithoutEstimate++
+			continue
+		}
+
+		destinationBudget.EstimatedCost, err = estimateStayCost(destinationBudget.Estimate, destinationBudget.Days, currency)
+		if err != nil {
+			log.Errorf("Error converting cost estimate of %s in itinerary %d: %v", destination.City, itinerary.ID, err)
+			return nil, errors.New("failed to convert cost estimates")
+		}
+		summary.EstimatedCost.Lodging += destinationBudget.EstimatedCost.Lodging
+		summary.EstimatedCost.Food += destinationBudget.EstimatedCost.Food
+		summary.EstimatedCost.Transport += destinationBudget.EstimatedCost.Transport
+		summary.EstimatedCost.Activities += destinationBudget.EstimatedCost.Activities
+		summary.EstimatedCost.Total += destinationBudget.EstimatedCost.Total
+	}
+
+	if budget != nil {
+		budgetAmount, err := convertAmount(budget.Amount, budget.Currency, currency)
+		if err != nil {
+			log.Errorf("Error converting budget of itinerary %d: %v", itinerary.ID, err)
+			return nil, errors.New("failed to convert budget")
+		}
+		remaining := budgetAmount - summary.EstimatedCost.Total
+		summary.BudgetAmount = &budgetAmount
+		summary.Remaining = &remaining
+		summary.OverBudget = remaining < 0
+	}
+
+	return summary, nil
+}
+
+// SaveBudget replaces the budget of an itinerary, recording the change in the audit log. The currency must be supported by the exchange
+// rate provider
+func (bs *BudgetService) SaveBudget(itinerary *models.Itinerary, budget *models.ItineraryBudget, actorId int64) error {
+	if itinerary == nil || budget == nil {
+		log.Error("Itinerary or budget instance is nil")
+		return errors.New("itinerary or budget instance is nil")
+	}
+
+	if budget.Amount <= 0 {
+		return errors.New("invalid budget: the amount must be positive")
+	}
+	currency, err := validateCurrency(budget.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid budget: %w", err)
+	}
+
+	budget = models.InitItineraryBudgetFunctions(budget)
+	budget.ItineraryID = itinerary.ID
+	budget.Currency = currency
+	err = budget.Save()
+	if err != nil {
+		log.Errorf("Error saving budget of itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to save budget")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Budget of itinerary %d updated.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "budget": "updated"})
+}
+
+// DeleteBudget removes the budget of an itinerary, recording the change in the audit log. The cost estimates are kept. Returns
+// sql.ErrNoRows if the itinerary has no budget
+func (bs *BudgetService) DeleteBudget(itinerary *models.Itinerary, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	budget := models.InitItineraryBudget()
+	budget.ItineraryID = itinerary.ID
+	err := budget.Delete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting budget of itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to delete budget")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Budget of itinerary %d removed.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "budget": "removed"})
+}
+
+// SaveEstimate replaces the daily cost estimate of the city of a destination of an itinerary retrieved with its destinations, recording
+// the change in the audit log. Returns sql.ErrNoRows if the itinerary has no such destination
+func (bs *BudgetService) SaveEstimate(itinerary *models.Itinerary, destinationId int64, estimate *models.DestinationCostEstimate, actorId int64) error {
+	if itinerary == nil || estimate == nil {
+		log.Error("Itinerary or cost estimate instance is nil")
+		return errors.New("itinerary or cost estimate instance is nil")
+	}
+
+	index := findDestinationIndex(itinerary, destinationId)
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+
+	if estimate.Lodging < 0 || estimate.Food < 0 || estimate.Transport < 0 || estimate.Activities < 0 {
+		return errors.New("invalid cost estimate: the amounts cannot be negative")
+	}
+	currency, err := validateCurrency(estimate.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid cost estimate: %w", err)
+	}
+
+	destination := itinerary.TravelDestinations[index]
+	estimate = models.InitDestinationCostEstimateFunctions(estimate)
+	estimate.ItineraryID = itinerary.ID
+	estimate.Country = destination.Country
+	estimate.City = destination.City
+	estimate.Currency = currency
+	estimate.Source = models.CostEstimateSourceManual
+	err = estimate.Save()
+	if err != nil {
+		log.Errorf("Error saving cost estimate of destination %d of itinerary %d: %v", destinationId, itinerary.ID, err)
+		return errors.New("failed to save cost estimate")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated,
+		fmt.Sprintf("Cost estimate of destination %d of itinerary %d updated.", destinationId, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "destinationId": destinationId, "costEstimate": "updated"})
+}
+
+// DeleteEstimate removes the daily cost estimate of the city of a destination of an itinerary retrieved with its destinations,
+// recording the change in the audit log. Returns sql.ErrNoRows if the itinerary has no such destination or the city no estimate
+func (bs *BudgetService) DeleteEstimate(itinerary *models.Itinerary, destinationId int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	index := findDestinationIndex(itinerary, destinationId)
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+
+	estimate := models.InitDestinationCostEstimate()
+	estimate.ItineraryID = itinerary.ID
+	estimate.Country = itinerary.TravelDestinations[index].Country
+	estimate.City = itinerary.TravelDestinations[index].City
+	err := estimate.Delete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting cost estimate of destination %d of itinerary %d: %v", destinationId, itinerary.ID, err)
+		return errors.New("failed to delete cost estimate")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated,
+		fmt.Sprintf("Cost estimate of destination %d of itinerary %d removed.", destinationId, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "destinationId": destinationId, "costEstimate": "removed"})
+}
+
+// GenerateEstimates asks the LLM for the daily cost estimates of the cities of the destinations of an itinerary retrieved with them,
+// for the travellers of its effective preferences and in the currency (the one of the budget if empty). The generated estimates replace
+// the previous ones of the cities and the change is recorded in the audit log. The LLM call is cancelled with the context and after
+// LLM_REQUEST_TIMEOUT_SECONDS
+func (bs *BudgetService) GenerateEstimates(ctx context.Context, itinerary *models.Itinerary, currency string, actorId int64) ([]*models.DestinationCostEstimate, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+	if len(itinerary.TravelDestinations) == 0 {
+		return nil, errors.New("invalid itinerary: it has no destinations")
+	}
+
+	budget, err := models.InitItineraryBudget().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Error retrieving budget of itinerary %d: %v", itinerary.ID, err)
+			return nil, errors.New("failed to retrieve budget")
+		}
+		budget = nil
+	}
+	currency, err = resolveBudgetCurrency(currency, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := GetTravellerPreferencesService().FindEffective(itinerary)
+	if err != nil {
+		log.Errorf("Error retrieving traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to generate cost estimates")
+	}
+
+	// Stays in the same city share its estimate
+	destinations := []map[string]any{}
+	for _, destination := range itinerary.TravelDestinations {
+		if !containsDestinationCity(destinations, destination) {
+			destinations = append(destinations, map[string]any{"country": destination.Country, "city": destination.City})
+		}
+	}
+
+	prompt, err := prompts.NewPromptTemplate(costEstimatesPromptTemplate, []string{"currency", "destinations", "travellerProfile"}).
+		Format(map[string]any{"currency": currency, "destinations": destinations, "travellerProfile": describeTravellerProfile(preferences)})
+	if err != nil {
+		log.Errorf("Error formatting cost estimates prompt: %v", err)
+		return nil, errors.New("failed to generate cost estimates")
+	}
+
+	timeoutSeconds, err := getIntEnvOrDefault("LLM_REQUEST_TIMEOUT_SECONDS", 60)
+	if err != nil {
+		return nil, errors.New("failed to generate cost estimates")
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	response, err := apis.CallLlm(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, costEstimatesSystemMessage),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Errorf("Timed out generating cost estimates of itinerary %d after %d seconds", itinerary.ID, timeoutSeconds)
+		return nil, errors.New("cost estimates generation timed out")
+	}
+	if err != nil || response == nil {
+		log.Errorf("Error generating cost estimates of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to generate cost estimates")
+	}
+
+	generated := &generatedCostEstimates{}
+	err = parseLlmJsonObject(*response, generated)
+	if err != nil {
+		log.Errorf("Error parsing cost estimates of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to generate cost estimates")
+	}
+
+	estimates := []*models.DestinationCostEstimate{}
+	for _, destination := range destinations {
+		for _, candidate := range generated.Estimates {
+			if !utils.SamePlace(candidate.Country, candidate.City, destination["country"].(string), destination["city"].(string)) {
+				continue
+			}
+			if candidate.Lodging < 0 || candidate.Food < 0 || candidate.Transport < 0 || candidate.Activities < 0 {
+				log.Warnf("Ignoring negative cost estimate of %s generated for itinerary %d", candidate.City, itinerary.ID)
+				break
+			}
+
+			estimate := models.InitDestinationCostEstimate()
+			estimate.ItineraryID = itinerary.ID
+			estimate.Country = destination["country"].(string)
+			estimate.City = destination["city"].(string)
+			estimate.Currency = currency
+			estimate.Lodging = candidate.Lodging
+			estimate.Food = candidate.Food
+			estimate.Transport = candidate.Transport
+			estimate.Activities = candidate.Activities
+			estimate.Source = models.CostEstimateSourceGenerated
+			err = estimate.Save()
+			if err != nil {
+				log.Errorf("Error saving cost estimate of %s for itinerary %d: %v", estimate.City, itinerary.ID, err)
+				return nil, errors.New("failed to save cost estimates")
+			}
+			estimates = append(estimates, estimate)
+			break
+		}
+	}
+	if len(estimates) == 0 {
+		log.Errorf("No cost estimate generated for the destinations of itinerary %d", itinerary.ID)
+		return nil, errors.New("failed to generate cost estimates")
+	}
+
+	err = saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Cost estimates of itinerary %d generated.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "costEstimate": "generated", "currency": currency, "destinations": len(estimates)})
+	if err != nil {
+		return nil, err
+	}
+
+	return estimates, nil
+}
+
+// resolveBudgetCurrency returns the currency, or else the one of the budget or the default one, after checking the exchange rate
+// provider supports it
+func resolveBudgetCurrency(currency string, budget *models.ItineraryBudget) (string, error) {
+	if currency == "" {
+		currency = DefaultBudgetCurrency
+		if budget != nil {
+			currency = budget.Currency
+		}
+	}
+
+	currency, err := validateCurrency(currency)
+	if err != nil {
+		return "", fmt.Errorf("invalid currency: %w", err)
+	}
+	return currency, nil
+}
+
+// validateCurrency returns the currency in upper case if the exchange rate provider supports it
+func validateCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(currency)
+	_, err := GetExchangeRateProvider().Rate(currency, currency)
+	if err != nil {
+		return "", err
+	}
+	return currency, nil
+}
+
+// stayDays returns the number of days of the stay in a destination, counting a part of a day as a whole one and at least one day
+func stayDays(destination *models.ItineraryTravelDestination) int {
+	days := int(math.Ceil(destination.DepartureDate.Sub(destination.ArrivalDate).Hours() / 24))
+	return max(days, 1)
+}
+
+// estimateStayCost returns the cost of the days of a stay with the daily estimate, converted to the currency
+func estimateStayCost(estimate *models.DestinationCostEstimate, days int, currency string) (*models.CostBreakdown, error) {
+	cost := &models.CostBreakdown{}
+	for _, category := range []struct {
+		daily models.Amount
+		total *models.Amount
+	}{{estimate.Lodging, &cost.Lodging}, {estimate.Food, &cost.Food}, {estimate.Transport, &cost.Transport}, {estimate.Activities, &cost.Activities}} {
+		converted, err := convertAmount(category.daily*models.Amount(days), estimate.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+		*category.total = converted
+		cost.Total += converted
+	}
+
+	return cost, nil
+}
+
+func findCostEstimate(estimates []*models.DestinationCostEstimate, country string, city string) *models.DestinationCostEstimate {
+	for _, estimate := range estimates {
+		if utils.SamePlace(estimate.Country, estimate.City, country, city) {
+			return estimate
+		}
+	}
+	return nil
+}
+
+func containsDestinationCity(destinations []map[string]any, destination *models.ItineraryTravelDestination) bool {
+	for _, candidate := range destinations {
+		if utils.SamePlace(candidate["country"].(string), candidate["city"].(string), destination.Country, destination.City) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLlmJsonObject reads the JSON object of the answer of the LLM into the target. The object can be surrounded by other text like
+// code fences
+func parseLlmJsonObject(response string, target any) error {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return errors.New("no JSON object in the answer")
+	}
+
+	return json.Unmarshal([]byte(response[start:end+1]), target)
+}
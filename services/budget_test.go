@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// mockExchangeRates converts currencies with the rates per euro
+func mockExchangeRates(t *testing.T, ratesPerEuro map[string]float64) {
+	orig := GetExchangeRateProvider
+	GetExchangeRateProvider = func() ExchangeRateProviderInterface {
+		return &StaticExchangeRateProvider{RatesPerEuro: ratesPerEuro}
+	}
+	t.Cleanup(func() { GetExchangeRateProvider = orig })
+}
+
+// mockStoredBudget makes the budget the one of the itineraries, none if nil, and returns the saved ones
+func mockStoredBudget(t *testing.T, budget *models.ItineraryBudget) *[]*models.ItineraryBudget {
+	saved := []*models.ItineraryBudget{}
+	orig := models.InitItineraryBudget
+	origFunctions := models.InitItineraryBudgetFunctions
+	initFunctions := func(b *models.ItineraryBudget) *models.ItineraryBudget {
+		b.FindByItineraryId = func(_ int64) (*models.ItineraryBudget, error) {
+			if budget == nil {
+				return nil, sql.ErrNoRows
+			}
+			return budget, nil
+		}
+		b.Save = func() error {
+			saved = append(saved, b)
+			return nil
+		}
+		b.Delete = func() error {
+			if budget == nil {
+				return sql.ErrNoRows
+			}
+			return nil
+		}
+		return b
+	}
+	models.InitItineraryBudgetFunctions = initFunctions
+	models.InitItineraryBudget = func() *models.ItineraryBudget { return initFunctions(&models.ItineraryBudget{}) }
+	t.Cleanup(func() {
+		models.InitItineraryBudget = orig
+		models.InitItineraryBudgetFunctions = origFunctions
+	})
+	return &saved
+}
+
+// mockStoredCostEstimates makes the estimates the ones of the itineraries and returns the saved ones
+func mockStoredCostEstimates(t *testing.T, estimates []*models.DestinationCostEstimate) *[]*models.DestinationCostEstimate {
+	saved := []*models.DestinationCostEstimate{}
+	orig := models.InitDestinationCostEstimate
+	origFunctions := models.InitDestinationCostEstimateFunctions
+	initFunctions := func(e *models.DestinationCostEstimate) *models.DestinationCostEstimate {
+		e.FindByItineraryId = func(_ int64) ([]*models.DestinationCostEstimate, error) { return estimates, nil }
+		e.Save = func() error {
+			saved = append(saved, e)
+			return nil
+		}
+		e.Delete = func() error { return sql.ErrNoRows }
+		return e
+	}
+	models.InitDestinationCostEstimateFunctions = initFunctions
+	models.InitDestinationCostEstimate = func() *models.DestinationCostEstimate {
+		return initFunctions(&models.DestinationCostEstimate{})
+	}
+	t.Cleanup(func() {
+		models.InitDestinationCostEstimate = orig
+		models.InitDestinationCostEstimateFunctions = origFunctions
+	})
+	return &saved
+}
+
+func budgetItinerary() *models.Itinerary {
+	arrival := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	return &models.Itinerary{ID: 1, OwnerID: 2, TravelDestinations: []*models.ItineraryTravelDestination{
+		{ID: 10, Country: "Spain", City: "Madrid", ArrivalDate: arrival, DepartureDate: arrival.Add(3 * 24 * time.Hour)},
+		{ID: 11, Country: "United Kingdom", City: "London", ArrivalDate: arrival.Add(3 * 24 * time.Hour), DepartureDate: arrival.Add(5 * 24 * time.Hour)},
+		{ID: 12, Country: "France", City: "Paris", ArrivalDate: arrival.Add(5 * 24 * time.Hour), DepartureDate: arrival.Add(5*24*time.Hour + time.Hour)},
+	}}
+}
+
+func TestBudgetService_GetSummary(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1, "GBP": 0.5, "USD": 2})
+	mockStoredBudget(t, &models.ItineraryBudget{ItineraryID: 1, Amount: models.AmountOf(1000), Currency: "EUR"})
+	mockStoredCostEstimates(t, []*models.DestinationCostEstimate{
+		{ItineraryID: 1, Country: "spain", City: "madrid", Currency: "EUR", Lodging: models.AmountOf(100), Food: models.AmountOf(50),
+			Transport: models.AmountOf(10), Activities: models.AmountOf(40)},
+		{ItineraryID: 1, Country: "UK", City: "london", Currency: "GBP", Lodging: models.AmountOf(100), Food: models.AmountOf(25),
+			Transport: models.AmountOf(5), Activities: models.AmountOf(20)},
+	})
+
+	summary, err := GetBudgetService().GetSummary(budgetItinerary(), "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", summary.Currency)
+	assert.Len(t, summary.Destinations, 3)
+	assert.Equal(t, 3, summary.Destinations[0].Days)
+	assert.Equal(t, models.AmountOf(600), summary.Destinations[0].EstimatedCost.Total)
+	assert.Equal(t, 2, summary.Destinations[1].Days)
+	assert.Equal(t, models.AmountOf(400), summary.Destinations[1].EstimatedCost.Lodging)
+	assert.Equal(t, models.AmountOf(600), summary.Destinations[1].EstimatedCost.Total)
+	assert.Equal(t, 1, summary.Destinations[2].Days)
+	assert.Nil(t, summary.Destinations[2].EstimatedCost)
+	assert.Equal(t, 1, summary.DestinationsWithoutEstimate)
+	assert.Equal(t, models.CostBreakdown{Lodging: models.AmountOf(700), Food: models.AmountOf(250), Transport: models.AmountOf(50),
+		Activities: models.AmountOf(200), Total: models.AmountOf(1200)}, summary.EstimatedCost)
+	assert.Equal(t, models.AmountOf(1000), *summary.BudgetAmount)
+	assert.Equal(t, models.AmountOf(-200), *summary.Remaining)
+	assert.True(t, summary.OverBudget)
+}
+
+func TestBudgetService_GetSummary_OtherCurrency(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1, "USD": 2})
+	mockStoredBudget(t, &models.ItineraryBudget{ItineraryID: 1, Amount: models.AmountOf(1000), Currency: "EUR"})
+	mockStoredCostEstimates(t, []*models.DestinationCostEstimate{
+		{ItineraryID: 1, Country: "Spain", City: "Madrid", Currency: "EUR", Lodging: models.AmountOf(100)},
+	})
+
+	summary, err := GetBudgetService().GetSummary(budgetItinerary(), "usd")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", summary.Currency)
+	assert.Equal(t, models.AmountOf(2000), *summary.BudgetAmount)
+	assert.Equal(t, models.AmountOf(600), summary.EstimatedCost.Total)
+	assert.Equal(t, models.AmountOf(1400), *summary.Remaining)
+	assert.False(t, summary.OverBudget)
+}
+
+func TestBudgetService_GetSummary_NoBudget(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	mockStoredBudget(t, nil)
+	mockStoredCostEstimates(t, []*models.DestinationCostEstimate{})
+
+	summary, err := GetBudgetService().GetSummary(budgetItinerary(), "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultBudgetCurrency, summary.Currency)
+	assert.Nil(t, summary.Budget)
+	assert.Nil(t, summary.Remaining)
+	assert.Equal(t, 3, summary.DestinationsWithoutEstimate)
+}
+
+func TestBudgetService_GetSummary_InvalidCurrency(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	mockStoredBudget(t, nil)
+
+	_, err := GetBudgetService().GetSummary(budgetItinerary(), "XXX")
+
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid currency: "))
+}
+
+func TestBudgetService_SaveBudget(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1, "USD": 2})
+	saved := mockStoredBudget(t, nil)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	err := GetBudgetService().SaveBudget(budgetItinerary(), &models.ItineraryBudget{Amount: models.AmountOf(1500), Currency: "usd"}, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, *saved, 1)
+	assert.Equal(t, int64(1), (*saved)[0].ItineraryID)
+	assert.Equal(t, "USD", (*saved)[0].Currency)
+	assert.Equal(t, []string{"Budget of itinerary 1 updated."}, *descriptions)
+}
+
+func TestBudgetService_SaveBudget_Invalid(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	saved := mockStoredBudget(t, nil)
+
+	err := GetBudgetService().SaveBudget(budgetItinerary(), &models.ItineraryBudget{Amount: 0, Currency: "EUR"}, 2)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid budget: "))
+
+	err = GetBudgetService().SaveBudget(budgetItinerary(), &models.ItineraryBudget{Amount: models.AmountOf(100), Currency: "XXX"}, 2)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid budget: "))
+	assert.Empty(t, *saved)
+}
+
+func TestBudgetService_DeleteBudget_NotFound(t *testing.T) {
+	mockStoredBudget(t, nil)
+
+	err := GetBudgetService().DeleteBudget(budgetItinerary(), 2)
+
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func TestBudgetService_SaveEstimate(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	saved := mockStoredCostEstimates(t, nil)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	err := GetBudgetService().SaveEstimate(budgetItinerary(), 11, &models.DestinationCostEstimate{Currency: "eur", Lodging: models.AmountOf(90)}, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, *saved, 1)
+	assert.Equal(t, "London", (*saved)[0].City)
+	assert.Equal(t, "United Kingdom", (*saved)[0].Country)
+	assert.Equal(t, "EUR", (*saved)[0].Currency)
+	assert.Equal(t, models.CostEstimateSourceManual, (*saved)[0].Source)
+	assert.Equal(t, []string{"Cost estimate of destination 11 of itinerary 1 updated."}, *descriptions)
+}
+
+func TestBudgetService_SaveEstimate_DestinationNotFound(t *testing.T) {
+	saved := mockStoredCostEstimates(t, nil)
+
+	err := GetBudgetService().SaveEstimate(budgetItinerary(), 99, &models.DestinationCostEstimate{Currency: "EUR"}, 2)
+
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+	assert.Empty(t, *saved)
+}
+
+func TestBudgetService_SaveEstimate_Negative(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+
+	err := GetBudgetService().SaveEstimate(budgetItinerary(), 10, &models.DestinationCostEstimate{Currency: "EUR", Food: models.AmountOf(-1)}, 2)
+
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid cost estimate: "))
+}
+
+func TestBudgetService_DeleteEstimate_NotFound(t *testing.T) {
+	mockStoredCostEstimates(t, nil)
+
+	err := GetBudgetService().DeleteEstimate(budgetItinerary(), 10, 2)
+
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func TestBudgetService_GenerateEstimates(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1, "GBP": 0.85})
+	mockStoredBudget(t, &models.ItineraryBudget{ItineraryID: 1, Amount: models.AmountOf(1000), Currency: "GBP"})
+	saved := mockStoredCostEstimates(t, nil)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	var prompt string
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		prompt = msgs[1].Parts[0].(llms.TextContent).Text
+		response := "```json\n" + `{"estimates": [{"country": "España", "city": "madrid", "lodging": 100.123, "food": 50, "transport": 10,
+		"activities": 30}, {"country": "United Kingdom", "city": "London", "lodging": -5, "food": 40, "transport": 10, "activities": 30},
+		{"country": "Italy", "city": "Rome", "lodging": 80, "food": 40, "transport": 10, "activities": 30}]}` + "\n```"
+		return &response, nil
+	}
+
+	estimates, err := GetBudgetService().GenerateEstimates(context.Background(), budgetItinerary(), "", 2)
+
+	assert.NoError(t, err)
+	assert.Contains(t, prompt, "in GBP")
+	assert.Contains(t, prompt, "Country: France, City: Paris")
+	assert.Len(t, estimates, 1)
+	assert.Equal(t, *saved, estimates)
+	assert.Equal(t, "Madrid", estimates[0].City)
+	assert.Equal(t, "GBP", estimates[0].Currency)
+	assert.Equal(t, models.AmountOf(100.12), estimates[0].Lodging)
+	assert.Equal(t, models.CostEstimateSourceGenerated, estimates[0].Source)
+	assert.Equal(t, []string{"Cost estimates of itinerary 1 generated."}, *descriptions)
+}
+
+func TestBudgetService_GenerateEstimates_InvalidAnswer(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	mockStoredBudget(t, nil)
+	saved := mockStoredCostEstimates(t, nil)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		response := "I cannot estimate that"
+		return &response, nil
+	}
+
+	_, err := GetBudgetService().GenerateEstimates(context.Background(), budgetItinerary(), "", 2)
+
+	assert.EqualError(t, err, "failed to generate cost estimates")
+	assert.Empty(t, *saved)
+}
+
+func TestBudgetService_GenerateEstimates_TimedOut(t *testing.T) {
+	t.Setenv("LLM_REQUEST_TIMEOUT_SECONDS", "1")
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	mockStoredBudget(t, nil)
+	saved := mockStoredCostEstimates(t, nil)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(ctx context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	_, err := GetBudgetService().GenerateEstimates(context.Background(), budgetItinerary(), "", 2)
+
+	assert.EqualError(t, err, "cost estimates generation timed out")
+	assert.Empty(t, *saved)
+}
+
+func TestBudgetService_GetSummary_ExactAmounts(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	mockStoredBudget(t, &models.ItineraryBudget{ItineraryID: 1, Amount: models.AmountOf(0.9), Currency: "EUR"})
+	mockStoredCostEstimates(t, []*models.DestinationCostEstimate{
+		{ItineraryID: 1, Country: "Spain", City: "Madrid", Currency: "EUR", Food: models.AmountOf(0.1), Transport: models.AmountOf(0.2)},
+	})
+
+	summary, err := GetBudgetService().GetSummary(budgetItinerary(), "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.AmountOf(0.9), summary.EstimatedCost.Total)
+	assert.Equal(t, models.Amount(0), *summary.Remaining)
+	assert.False(t, summary.OverBudget)
+}
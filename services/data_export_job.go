@@ -0,0 +1,422 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"example.com/travel-advisor/models"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	log "github.com/sirupsen/logrus"
+)
+
+type DataExportJobServiceInterface interface {
+	FindAliveById(id int64) (*models.DataExportJob, error)
+	FindAliveByUserId(userId int64) ([]*models.DataExportJob, error)
+	GetInProgressJobsOfUserCount(userId int64) (int, error)
+	PrepareJob(userId int64) (*DataExportAsyncTaskPayload, error)
+	AddAsyncTaskId(asyncTaskId string, dataExportJob *models.DataExportJob) error
+	FailJob(errorDescription string, dataExportJob *models.DataExportJob) error
+	OpenDataExportJobFile(dataExportJob *models.DataExportJob) (io.ReadSeekCloser, error)
+	SoftDeleteJob(dataExportJob *models.DataExportJob) error
+	DeleteDeadJobs(fetchLimit int) error
+}
+
+type DataExportJobService struct{}
+
+// singleton instance
+var dataExportJobServiceInstance = &DataExportJobService{}
+
+// GetDataExportJobService returns the singleton instance of DataExportJobService
+var GetDataExportJobService = func() DataExportJobServiceInterface {
+	return dataExportJobServiceInstance
+}
+
+const (
+	TypeDataExport = "data_export"
+)
+
+type DataExportAsyncTaskPayload struct {
+	DataExportJob *models.DataExportJob `json:"dataExportJob"`
+	UserID        int64                 `json:"userId"`
+}
+
+// FindAliveById retrieves the data export job by its ID
+func (dejs *DataExportJobService) FindAliveById(id int64) (*models.DataExportJob, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid data export job ID")
+	}
+	job := models.InitDataExportJob()
+	return job.FindAliveById(id)
+}
+
+// FindAliveByUserId retrieves the data export jobs of a user
+func (dejs *DataExportJobService) FindAliveByUserId(userId int64) ([]*models.DataExportJob, error) {
+	if userId <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+	job := models.InitDataExportJob()
+	return job.FindAliveByUserId(userId)
+}
+
+// GetInProgressJobsOfUserCount retrieves the count of running/pending data export jobs of a user
+func (dejs *DataExportJobService) GetInProgressJobsOfUserCount(userId int64) (int, error) {
+	if userId <= 0 {
+		log.Error("invalid user ID")
+		return 0, errors.New("invalid user ID")
+	}
+	job := models.InitDataExportJob()
+	return job.GetInProgressJobsOfUserCount(userId)
+}
+
+// PrepareJob stores a new pending data export job for the user and records the request in the audit log
+func (dejs *DataExportJobService) PrepareJob(userId int64) (*DataExportAsyncTaskPayload, error) {
+	if userId <= 0 {
+		log.Error("invalid user ID")
+		return nil, errors.New("invalid user ID")
+	}
+
+	job := models.NewDataExportJob(userId)
+	err := job.PrepareJob()
+	if err != nil {
+		log.Errorf("failed to prepare data export job: %v", err)
+		return nil, errors.New("failed to prepare data export job")
+	}
+
+	err = saveAuditEvent(userId, models.AuditEventDataExportRequested, fmt.Sprintf("Data export %d requested.", job.ID),
+		map[string]any{"exportJobId": job.ID})
+	if err != nil {
+		job.FailJob("Could not record the request of the data export")
+		return nil, err
+	}
+
+	return &DataExportAsyncTaskPayload{DataExportJob: job, UserID: userId}, nil
+}
+
+// AddAsyncTaskId adds an async task ID to the data export job
+func (dejs *DataExportJobService) AddAsyncTaskId(asyncTaskId string, dataExportJob *models.DataExportJob) error {
+	if asyncTaskId == "" {
+		log.Error("async task ID cannot be empty")
+		return errors.New("async task ID cannot be empty")
+	}
+	if dataExportJob == nil {
+		log.Error("data export job instance is nil")
+		return errors.New("data export job instance is nil")
+	}
+
+	err := dataExportJob.AddAsyncTaskId(asyncTaskId)
+	if err != nil {
+		log.Errorf("failed to add async task ID: %v", err)
+		return errors.New("failed to add async task ID")
+	}
+
+	return nil
+}
+
+// FailJob marks the data export job as failed with a description
+func (dejs *DataExportJobService) FailJob(errorDescription string, dataExportJob *models.DataExportJob) error {
+	if dataExportJob == nil {
+		log.Error("data export job instance is nil")
+		return errors.New("data export job instance is nil")
+	}
+	if errorDescription == "" {
+		log.Error("error description cannot be empty")
+		return errors.New("error description cannot be empty")
+	}
+
+	err := dataExportJob.FailJob(errorDescription)
+	if err != nil {
+		log.Errorf("failed to fail data export job: %v", err)
+		return errors.New("failed to fail data export job")
+	}
+
+	return nil
+}
+
+// OpenDataExportJobFile opens the ZIP file generated by a completed data export job, recording the download in the audit log
+func (dejs *DataExportJobService) OpenDataExportJobFile(dataExportJob *models.DataExportJob) (io.ReadSeekCloser, error) {
+	if dataExportJob == nil {
+		log.Error("data export job instance is nil")
+		return nil, errors.New("data export job instance is nil")
+	}
+	if dataExportJob.Filepath == "" {
+		log.Error("data export job filepath is empty")
+		return nil, errors.New("data export job filepath is empty")
+	}
+
+	fileManager := GetFileManager(dataExportJob.FileManager)
+	file, err := fileManager.OpenFile(dataExportJob.Filepath)
+	if err != nil {
+		log.Errorf("failed to open data export job file: %v", err)
+		return nil, errors.New("failed to open data export job file")
+	}
+
+	err = saveAuditEvent(dataExportJob.UserID, models.AuditEventDataExportDownloaded, fmt.Sprintf("Data export %d downloaded.", dataExportJob.ID),
+		map[string]any{"exportJobId": dataExportJob.ID})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// SoftDeleteJob marks the data export job as deleted, so the dead jobs cleanup removes it with its file later on
+func (dejs *DataExportJobService) SoftDeleteJob(dataExportJob *models.DataExportJob) error {
+	if dataExportJob == nil {
+		log.Error("data export job instance is nil")
+		return errors.New("data export job instance is nil")
+	}
+
+	err := dataExportJob.SoftDeleteJob()
+	if err != nil {
+		log.Errorf("failed to soft delete data export job: %v", err)
+		return errors.New("failed to soft delete data export job")
+	}
+	return nil
+}
+
+// DeleteDeadJobs fully deletes (file + DB row) the first 'n' data export jobs in 'deleted' status, 10 by default if fetchLimit is
+// equal or less than 0
+func (dejs *DataExportJobService) DeleteDeadJobs(fetchLimit int) error {
+	finalFetchLimit := 10 // Default fetch limit
+	if fetchLimit > 0 {
+		finalFetchLimit = fetchLimit
+	}
+
+	job := models.InitDataExportJob()
+
+	deadJobs, err := job.FindDead(finalFetchLimit)
+	if err != nil {
+		log.Error("failed to find dead data export jobs", err)
+		return errors.New("failed to find dead data export jobs")
+	}
+	if len(deadJobs) == 0 {
+		log.Info("No dead data export jobs found to delete")
+		return nil
+	}
+	for _, deadJob := range deadJobs {
+		if deadJob.Filepath != "" {
+			fileManager := GetFileManager(deadJob.FileManager)
+			err = fileManager.DeleteFile(deadJob.Filepath)
+			if err != nil {
+				log.Warnf("Error deleting file for dead data export job %v: %v", deadJob.ID, err)
+			}
+		}
+
+		// Delete the job from the database
+		job.ID = deadJob.ID
+		err = job.DeleteJob()
+		if err != nil {
+			log.Errorf("Error deleting dead data export job %v from database: %v", deadJob.ID, err)
+		} else {
+			log.Debugf("Data export job with ID %d deleted successfully", deadJob.ID)
+		}
+	}
+
+	return nil
+}
+
+func HandleDataExportJob(ctx context.Context, t *asynq.Task) error {
+	var dataExportTask DataExportAsyncTaskPayload
+	if err := json.Unmarshal(t.Payload(), &dataExportTask); err != nil {
+		log.Errorf("could not unmarshal task payload: %v", err)
+		return errors.New("could not unmarshal task payload")
+	}
+
+	// We regenerate the job from the user ID to have access to the entity methods
+	job := models.NewDataExportJob(dataExportTask.UserID)
+	job.ID = dataExportTask.DataExportJob.ID
+	job.Status = dataExportTask.DataExportJob.Status
+	job.CreationDate = dataExportTask.DataExportJob.CreationDate
+	job.FileManager = dataExportTask.DataExportJob.FileManager
+
+	err := job.StartJob()
+	if err != nil {
+		log.Errorf("failed to start data export job: %v", err)
+		job.FailJob("Failed to start job: " + err.Error())
+		return err
+	}
+
+	archive, err := buildDataExportArchive(job.UserID)
+	if err != nil {
+		log.Errorf("failed to build data export archive: %v", err)
+		job.FailJob("Failed to collect user data: " + err.Error())
+		return err
+	}
+
+	fileManager := GetFileManager(job.FileManager)
+
+	job.Filepath = "files/users/" + fmt.Sprintf("%d", job.UserID) + "/exports/" + uuid.New().String() + ".zip"
+
+	// The file manager works with string contents, which can hold the binary ZIP data as is
+	content := string(archive)
+	err = fileManager.SaveContentInFile(job.Filepath, &content)
+	if err != nil {
+		log.Errorf("failed to write data export to file: %v", err)
+		job.FailJob("Failed to write data export to file: " + err.Error())
+		return err
+	}
+
+	defer func(finalError *error, filepath string, fileManager FileManagerInterface) {
+		if *finalError != nil {
+			deleteFileError := fileManager.DeleteFile(filepath)
+			if deleteFileError != nil {
+				log.Warnf("Error deleting file %v after unexpected failure processing data export job: %v", filepath, deleteFileError)
+			}
+		}
+	}(&err, job.Filepath, fileManager)
+
+	err = job.CompleteJob()
+	if err != nil {
+		log.Errorf("failed to complete data export job: %v", err)
+		job.FailJob("Failed to complete job: " + err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// buildDataExportArchive collects the profile, itineraries with their destinations, transport legs and accommodations, the activities
+// of their plans, file job metadata and the conversations about their files, audit events, traveller preferences, prompt templates,
+// itinerary templates and generated itinerary files of a user into a ZIP archive
+var buildDataExportArchive = func(userId int64) ([]byte, error) {
+	user, err := models.InitUser().FindById(userId)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve user: %w", err)
+	}
+
+	itineraries, err := models.InitItinerary().FindByOwnerId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve itineraries: %w", err)
+	}
+	if itineraries == nil {
+		itineraries = []*models.Itinerary{}
+	}
+
+	itineraryFileJobs := []*models.ItineraryFileJob{}
+	itineraryActivities := []*models.ItineraryActivity{}
+	itineraryJobMessages := []*models.ItineraryJobMessage{}
+	for _, itinerary := range itineraries {
+		jobs, err := models.InitItineraryFileJob().FindAliveByItineraryId(itinerary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve jobs of itinerary %d: %w", itinerary.ID, err)
+		}
+		itineraryFileJobs = append(itineraryFileJobs, jobs...)
+
+		activities, err := models.InitItineraryActivity().FindByItineraryId(itinerary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve plan of itinerary %d: %w", itinerary.ID, err)
+		}
+		itineraryActivities = append(itineraryActivities, activities...)
+
+		messages, err := models.InitItineraryJobMessage().FindByItineraryId(itinerary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve job messages of itinerary %d: %w", itinerary.ID, err)
+		}
+		itineraryJobMessages = append(itineraryJobMessages, messages...)
+	}
+
+	auditEvents, err := models.InitAuditEvent().FindByUserId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve audit events: %w", err)
+	}
+
+	travellerPreferences, err := GetTravellerPreferencesService().FindByUserId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve traveller preferences: %w", err)
+	}
+
+	promptTemplates, err := GetPromptTemplateService().FindAllLatest(&userId)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve prompt templates: %w", err)
+	}
+
+	itineraryTemplates, err := models.InitItineraryTemplate().FindByOwnerId(userId)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve itinerary templates: %w", err)
+	}
+
+	buffer := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buffer)
+
+	jsonFiles := []struct {
+		name    string
+		content any
+	}{
+		{"profile.json", user},
+		{"itineraries.json", itineraries},
+		{"itinerary_activities.json", itineraryActivities},
+		{"itinerary_file_jobs.json", itineraryFileJobs},
+		{"itinerary_job_messages.json", itineraryJobMessages},
+		{"audit_events.json", auditEvents},
+		{"traveller_preferences.json", travellerPreferences},
+		{"prompt_templates.json", promptTemplates},
+		{"itinerary_templates.json", itineraryTemplates},
+	}
+	for _, jsonFile := range jsonFiles {
+		err = writeJsonToZip(zipWriter, jsonFile.name, jsonFile.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, job := range itineraryFileJobs {
+		if job.Filepath == "" {
+			continue
+		}
+
+		err = copyJobFileToZip(zipWriter, fmt.Sprintf("files/itineraries/%d/%d.txt", job.ItineraryID, job.ID), job)
+		if err != nil {
+			// A missing generated file must not prevent the user from getting the rest of their data
+			log.Warnf("Could not add file of itinerary job %d to data export: %v", job.ID, err)
+		}
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not close ZIP archive: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeJsonToZip(zipWriter *zip.Writer, name string, content any) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal %s: %w", name, err)
+	}
+
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not add %s to ZIP archive: %w", name, err)
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("could not write %s to ZIP archive: %w", name, err)
+	}
+
+	return nil
+}
+
+func copyJobFileToZip(zipWriter *zip.Writer, name string, job *models.ItineraryFileJob) error {
+	file, err := GetFileManager(job.FileManager).OpenFile(job.Filepath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, file)
+	return err
+}
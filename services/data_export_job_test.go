@@ -0,0 +1,427 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+func mockDataExportJob() *models.DataExportJob {
+	dej := models.NewDataExportJob(3)
+	dej.ID = 1
+	dej.FindAliveById = func(id int64) (*models.DataExportJob, error) { return &models.DataExportJob{ID: id}, nil }
+	dej.FindAliveByUserId = func(userId int64) ([]*models.DataExportJob, error) {
+		return []*models.DataExportJob{dej}, nil
+	}
+	dej.GetInProgressJobsOfUserCount = func(userId int64) (int, error) { return 0, nil }
+	dej.PrepareJob = func() error { return nil }
+	dej.AddAsyncTaskId = func(id string) error { return nil }
+	dej.StartJob = func() error { return nil }
+	dej.FailJob = func(desc string) error { return nil }
+	dej.CompleteJob = func() error { return nil }
+	dej.DeleteJob = func() error { return nil }
+	dej.SoftDeleteJob = func() error { return nil }
+	return dej
+}
+
+func setMockNewDataExportJob(t *testing.T, job *models.DataExportJob) {
+	origNew := models.NewDataExportJob
+	origInit := models.InitDataExportJob
+	models.NewDataExportJob = func(userId int64) *models.DataExportJob { return job }
+	models.InitDataExportJob = func() *models.DataExportJob { return job }
+	t.Cleanup(func() {
+		models.NewDataExportJob = origNew
+		models.InitDataExportJob = origInit
+	})
+}
+
+type recordingFileManager struct {
+	mockFileManager
+	savedPath    string
+	savedContent string
+	saveErr      error
+}
+
+func (m *recordingFileManager) SaveContentInFile(path string, content *string) error {
+	m.savedPath = path
+	m.savedContent = *content
+	return m.saveErr
+}
+
+// inMemoryFileManager serves the files of a map, as the local file system helpers are patched for the whole package
+type inMemoryFileManager struct {
+	mockFileManager
+	files map[string]string
+}
+
+type inMemoryFile struct {
+	*bytes.Reader
+}
+
+func (f *inMemoryFile) Close() error { return nil }
+
+func (m *inMemoryFileManager) OpenFile(path string) (io.ReadSeekCloser, error) {
+	content, ok := m.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return &inMemoryFile{bytes.NewReader([]byte(content))}, nil
+}
+
+func (m *inMemoryFileManager) SaveContentInFile(path string, content *string) error {
+	m.files[path] = *content
+	return nil
+}
+
+func setMockFileManager(t *testing.T, fileManager FileManagerInterface) {
+	orig := GetFileManager
+	GetFileManager = func(name string) FileManagerInterface { return fileManager }
+	t.Cleanup(func() { GetFileManager = orig })
+}
+
+func setMockBuildDataExportArchive(t *testing.T, archive []byte, err error) {
+	orig := buildDataExportArchive
+	buildDataExportArchive = func(userId int64) ([]byte, error) { return archive, err }
+	t.Cleanup(func() { buildDataExportArchive = orig })
+}
+
+func newDataExportTask(job *models.DataExportJob) *asynq.Task {
+	payloadBytes, _ := json.Marshal(DataExportAsyncTaskPayload{DataExportJob: job, UserID: job.UserID})
+	return asynq.NewTask(TypeDataExport, payloadBytes)
+}
+
+// --- Tests ---
+
+func TestDataExportJobService_FindAliveById_InvalidID(t *testing.T) {
+	job, err := (&DataExportJobService{}).FindAliveById(0)
+	assert.Nil(t, job)
+	assert.Error(t, err)
+}
+
+func TestDataExportJobService_FindAliveByUserId_Success(t *testing.T) {
+	setMockNewDataExportJob(t, mockDataExportJob())
+
+	jobs, err := (&DataExportJobService{}).FindAliveByUserId(3)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestDataExportJobService_GetInProgressJobsOfUserCount_InvalidUser(t *testing.T) {
+	_, err := (&DataExportJobService{}).GetInProgressJobsOfUserCount(0)
+	assert.Error(t, err)
+}
+
+func TestDataExportJobService_PrepareJob_InvalidUser(t *testing.T) {
+	payload, err := (&DataExportJobService{}).PrepareJob(0)
+	assert.Nil(t, payload)
+	assert.Error(t, err)
+}
+
+func TestDataExportJobService_PrepareJob_Fail(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	dej := mockDataExportJob()
+	dej.PrepareJob = func() error { return errors.New("insert failed") }
+	setMockNewDataExportJob(t, dej)
+
+	payload, err := (&DataExportJobService{}).PrepareJob(3)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "failed to prepare data export job")
+	assert.Empty(t, *descriptions)
+}
+
+func TestDataExportJobService_PrepareJob_AuditFails(t *testing.T) {
+	mockSaveAuditEvent(t, errors.New("error saving audit event"))
+	dej := mockDataExportJob()
+	failed := false
+	dej.FailJob = func(desc string) error {
+		failed = true
+		return nil
+	}
+	setMockNewDataExportJob(t, dej)
+
+	payload, err := (&DataExportJobService{}).PrepareJob(3)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "error saving audit event")
+	assert.True(t, failed)
+}
+
+func TestDataExportJobService_PrepareJob_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	dej := mockDataExportJob()
+	setMockNewDataExportJob(t, dej)
+
+	payload, err := (&DataExportJobService{}).PrepareJob(3)
+	assert.NoError(t, err)
+	assert.Equal(t, dej, payload.DataExportJob)
+	assert.Equal(t, int64(3), payload.UserID)
+	assert.Equal(t, []string{"Data export 1 requested."}, *descriptions)
+}
+
+func TestDataExportJobService_AddAsyncTaskId_EmptyTaskId(t *testing.T) {
+	err := (&DataExportJobService{}).AddAsyncTaskId("", mockDataExportJob())
+	assert.Error(t, err)
+}
+
+func TestDataExportJobService_AddAsyncTaskId_Fail(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.AddAsyncTaskId = func(id string) error { return errors.New("update failed") }
+
+	err := (&DataExportJobService{}).AddAsyncTaskId("taskid", dej)
+	assert.EqualError(t, err, "failed to add async task ID")
+}
+
+func TestDataExportJobService_FailJob_EmptyDesc(t *testing.T) {
+	err := (&DataExportJobService{}).FailJob("", mockDataExportJob())
+	assert.Error(t, err)
+}
+
+func TestDataExportJobService_FailJob_Success(t *testing.T) {
+	err := (&DataExportJobService{}).FailJob("error", mockDataExportJob())
+	assert.NoError(t, err)
+}
+
+func TestDataExportJobService_OpenDataExportJobFile_EmptyPath(t *testing.T) {
+	_, err := (&DataExportJobService{}).OpenDataExportJobFile(mockDataExportJob())
+	assert.EqualError(t, err, "data export job filepath is empty")
+}
+
+func TestDataExportJobService_OpenDataExportJobFile_Fail(t *testing.T) {
+	setMockFileManager(t, &mockFileManager{openFileErr: errors.New("not found")})
+	dej := mockDataExportJob()
+	dej.Filepath = "export.zip"
+
+	_, err := (&DataExportJobService{}).OpenDataExportJobFile(dej)
+	assert.EqualError(t, err, "failed to open data export job file")
+}
+
+func TestDataExportJobService_SoftDeleteJob_Fail(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.SoftDeleteJob = func() error { return errors.New("update failed") }
+
+	err := (&DataExportJobService{}).SoftDeleteJob(dej)
+	assert.EqualError(t, err, "failed to soft delete data export job")
+}
+
+func TestDataExportJobService_DeleteDeadJobs_FindDeadFails(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.FindDead = func(limit int) ([]*models.DataExportJob, error) { return nil, errors.New("find dead fail") }
+	setMockNewDataExportJob(t, dej)
+
+	err := (&DataExportJobService{}).DeleteDeadJobs(5)
+	assert.EqualError(t, err, "failed to find dead data export jobs")
+}
+
+func TestDataExportJobService_DeleteDeadJobs_Success(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.FindDead = func(limit int) ([]*models.DataExportJob, error) {
+		assert.Equal(t, 10, limit)
+		return []*models.DataExportJob{{ID: 5, Status: "deleted", Filepath: "export.zip", FileManager: "local"}}, nil
+	}
+	setMockNewDataExportJob(t, dej)
+	mgr := &mockFileManager{}
+	setMockFileManager(t, mgr)
+
+	deletedId := int64(0)
+	dej.DeleteJob = func() error {
+		deletedId = dej.ID
+		return nil
+	}
+
+	err := (&DataExportJobService{}).DeleteDeadJobs(0)
+	assert.NoError(t, err)
+	assert.True(t, mgr.deleteFileCalled)
+	assert.Equal(t, int64(5), deletedId)
+}
+
+func TestHandleDataExportJob_UnmarshalError(t *testing.T) {
+	task := asynq.NewTask(TypeDataExport, []byte("{invalid-json}"))
+
+	err := HandleDataExportJob(context.TODO(), task)
+	assert.EqualError(t, err, "could not unmarshal task payload")
+}
+
+func TestHandleDataExportJob_StartJobFails(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.StartJob = func() error { return errors.New("start job fail") }
+	failDescription := ""
+	dej.FailJob = func(desc string) error {
+		failDescription = desc
+		return nil
+	}
+	setMockNewDataExportJob(t, dej)
+
+	err := HandleDataExportJob(context.TODO(), newDataExportTask(dej))
+	assert.EqualError(t, err, "start job fail")
+	assert.Contains(t, failDescription, "Failed to start job")
+}
+
+func TestHandleDataExportJob_BuildArchiveFails(t *testing.T) {
+	dej := mockDataExportJob()
+	failDescription := ""
+	dej.FailJob = func(desc string) error {
+		failDescription = desc
+		return nil
+	}
+	setMockNewDataExportJob(t, dej)
+	setMockBuildDataExportArchive(t, nil, errors.New("db error"))
+
+	err := HandleDataExportJob(context.TODO(), newDataExportTask(dej))
+	assert.Error(t, err)
+	assert.Contains(t, failDescription, "Failed to collect user data")
+}
+
+func TestHandleDataExportJob_WriteFileFails(t *testing.T) {
+	dej := mockDataExportJob()
+	failDescription := ""
+	dej.FailJob = func(desc string) error {
+		failDescription = desc
+		return nil
+	}
+	setMockNewDataExportJob(t, dej)
+	setMockBuildDataExportArchive(t, []byte("zip"), nil)
+	setMockFileManager(t, &recordingFileManager{saveErr: errors.New("disk full")})
+
+	err := HandleDataExportJob(context.TODO(), newDataExportTask(dej))
+	assert.Error(t, err)
+	assert.Contains(t, failDescription, "Failed to write data export to file")
+}
+
+func TestHandleDataExportJob_CompleteJobFails(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.CompleteJob = func() error { return errors.New("complete fail") }
+	setMockNewDataExportJob(t, dej)
+	setMockBuildDataExportArchive(t, []byte("zip"), nil)
+	mgr := &recordingFileManager{}
+	setMockFileManager(t, mgr)
+
+	err := HandleDataExportJob(context.TODO(), newDataExportTask(dej))
+	assert.EqualError(t, err, "complete fail")
+	assert.True(t, mgr.deleteFileCalled)
+}
+
+func TestHandleDataExportJob_Success(t *testing.T) {
+	dej := mockDataExportJob()
+	dej.FailJob = func(desc string) error {
+		t.Errorf("FailJob should not be called on success")
+		return nil
+	}
+	setMockNewDataExportJob(t, dej)
+	setMockBuildDataExportArchive(t, []byte("zip"), nil)
+	mgr := &recordingFileManager{}
+	setMockFileManager(t, mgr)
+
+	err := HandleDataExportJob(context.TODO(), newDataExportTask(dej))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^files/users/3/exports/[0-9a-f-]+\.zip$`, mgr.savedPath)
+	assert.Equal(t, "zip", mgr.savedContent)
+	assert.Equal(t, mgr.savedPath, dej.Filepath)
+	assert.False(t, mgr.deleteFileCalled)
+}
+
+func TestBuildDataExportArchive_Success(t *testing.T) {
+	origInitUser := models.InitUser
+	origInitItinerary := models.InitItinerary
+	origInitItineraryFileJob := models.InitItineraryFileJob
+	origInitAuditEvent := models.InitAuditEvent
+	t.Cleanup(func() {
+		models.InitUser = origInitUser
+		models.InitItinerary = origInitItinerary
+		models.InitItineraryFileJob = origInitItineraryFileJob
+		models.InitAuditEvent = origInitAuditEvent
+	})
+
+	mockStoredTravellerPreferences(t, map[int64]*models.TravellerPreferences{3: {DietaryRestrictions: []string{"vegan"}}}, nil)
+	userId := int64(3)
+	mockStoredPromptTemplates(t, &[]*models.PromptTemplate{{ID: 7, Name: "kids", Version: 1, OwnerID: &userId, Template: "Plan for kids"}})
+	mockStoredActivities(t, newPlanActivities(), false)
+	mockStoredJobMessages(t, []*models.ItineraryJobMessage{{ID: 1, JobID: 4, ItineraryID: 2, Role: models.JobMessageRoleUser,
+		Content: "Add vegetarian restaurants"}})
+	mockStoredItineraryTemplates(t, &[]*models.ItineraryTemplate{newOffsiteTemplate(3, false)})
+	jobFilePath := "files/itineraries/2/4.txt"
+	setMockFileManager(t, &inMemoryFileManager{files: map[string]string{jobFilePath: "generated itinerary"}})
+
+	models.InitUser = func() *models.User {
+		user := &models.User{}
+		user.FindById = func(id int64) (*models.User, error) {
+			return &models.User{ID: id, Email: "test@example.com", Password: "hash"}, nil
+		}
+		return user
+	}
+	models.InitItinerary = func() *models.Itinerary {
+		itinerary := &models.Itinerary{}
+		itinerary.FindByOwnerId = func(ownerId int64) ([]*models.Itinerary, error) {
+			return []*models.Itinerary{{ID: 2, OwnerID: ownerId, Title: "Trip"}}, nil
+		}
+		return itinerary
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		job := &models.ItineraryFileJob{}
+		job.FindAliveByItineraryId = func(itineraryId int64) ([]*models.ItineraryFileJob, error) {
+			return []*models.ItineraryFileJob{
+				{ID: 4, ItineraryID: itineraryId, Status: "completed", Filepath: jobFilePath, FileManager: "local"},
+				{ID: 5, ItineraryID: itineraryId, Status: "completed", Filepath: "missing.txt", FileManager: "local"},
+			}, nil
+		}
+		return job
+	}
+	models.InitAuditEvent = func() *models.AuditEvent {
+		event := &models.AuditEvent{}
+		event.FindByUserId = func(userId int64) ([]*models.AuditEvent, error) {
+			return []*models.AuditEvent{{ID: 6, UserID: userId, EventDescription: "User 3 logged in."}}, nil
+		}
+		return event
+	}
+
+	archive, err := buildDataExportArchive(3)
+	assert.NoError(t, err)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+
+	contents := map[string]string{}
+	for _, file := range zipReader.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		contents[file.Name] = string(data)
+	}
+
+	assert.Len(t, contents, 10)
+	assert.Contains(t, contents["profile.json"], "test@example.com")
+	assert.NotContains(t, contents["profile.json"], "hash")
+	assert.Contains(t, contents["itineraries.json"], "Trip")
+	assert.Contains(t, contents["itinerary_activities.json"], "Visit the Prado Museum")
+	assert.Contains(t, contents["itinerary_file_jobs.json"], `"id": 5`)
+	assert.Contains(t, contents["itinerary_job_messages.json"], "Add vegetarian restaurants")
+	assert.Contains(t, contents["audit_events.json"], "User 3 logged in.")
+	assert.Contains(t, contents["traveller_preferences.json"], "vegan")
+	assert.Contains(t, contents["prompt_templates.json"], "Plan for kids")
+	assert.Contains(t, contents["itinerary_templates.json"], "Lisbon")
+	assert.Equal(t, "generated itinerary", contents["files/itineraries/2/4.txt"])
+}
+
+func TestBuildDataExportArchive_FindUserFails(t *testing.T) {
+	origInitUser := models.InitUser
+	t.Cleanup(func() { models.InitUser = origInitUser })
+	models.InitUser = func() *models.User {
+		user := &models.User{}
+		user.FindById = func(id int64) (*models.User, error) { return nil, errors.New("db error") }
+		return user
+	}
+
+	archive, err := buildDataExportArchive(3)
+	assert.Nil(t, archive)
+	assert.ErrorContains(t, err, "could not retrieve user")
+}
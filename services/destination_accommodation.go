@@ -0,0 +1,143 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type DestinationAccommodationServiceInterface interface {
+	Save(itinerary *models.Itinerary, destinationId int64, accommodation *models.DestinationAccommodation, actorId int64) error
+	Delete(itinerary *models.Itinerary, destinationId int64, actorId int64) error
+}
+
+type DestinationAccommodationService struct{}
+
+// singleton instance
+var destinationAccommodationServiceInstance = &DestinationAccommodationService{}
+
+// GetDestinationAccommodationService returns the singleton instance of DestinationAccommodationService
+var GetDestinationAccommodationService = func() DestinationAccommodationServiceInterface {
+	return destinationAccommodationServiceInstance
+}
+
+// Save adds or replaces the accommodation of a destination of an itinerary retrieved with its destinations and accommodations,
+// recording the change in the audit log. The check-in and check-out must be during the stay in the destination. Returns sql.ErrNoRows
+// if the itinerary has no such destination
+func (das *DestinationAccommodationService) Save(itinerary *models.Itinerary, destinationId int64, accommodation *models.DestinationAccommodation, actorId int64) error {
+	if itinerary == nil || accommodation == nil {
+		log.Error("Itinerary or accommodation instance is nil")
+		return errors.New("itinerary or accommodation instance is nil")
+	}
+
+	index := findDestinationIndex(itinerary, destinationId)
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+	destination := itinerary.TravelDestinations[index]
+
+	err := validateAccommodation(destination, accommodation)
+	if err != nil {
+		return err
+	}
+
+	accommodation = models.InitDestinationAccommodationFunctions(accommodation)
+	accommodation.ItineraryID = itinerary.ID
+	accommodation.Country = destination.Country
+	accommodation.City = destination.City
+	accommodation.DestinationID = &destination.ID
+
+	action := "added"
+	existing := findDestinationAccommodation(itinerary, destinationId)
+	if existing != nil {
+		action = "updated"
+		accommodation.ID = existing.ID
+		accommodation.CreationDate = existing.CreationDate
+		err = accommodation.Update()
+	} else {
+		err = accommodation.Create()
+	}
+	if err != nil {
+		log.Errorf("Error saving accommodation of destination %d of itinerary %d: %v", destinationId, itinerary.ID, err)
+		return errors.New("failed to save accommodation")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated,
+		fmt.Sprintf("Accommodation of destination %d of itinerary %d %s.", destinationId, itinerary.ID, action),
+		map[string]any{"itineraryId": itinerary.ID, "destinationId": destinationId, "accommodation": action})
+}
+
+// Delete removes the accommodation of a destination of an itinerary retrieved with its destinations and accommodations, recording the
+// change in the audit log. Returns sql.ErrNoRows if the itinerary has no such destination or the destination no accommodation
+func (das *DestinationAccommodationService) Delete(itinerary *models.Itinerary, destinationId int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	existing := findDestinationAccommodation(itinerary, destinationId)
+	if existing == nil {
+		return sql.ErrNoRows
+	}
+
+	accommodation := models.InitDestinationAccommodation()
+	accommodation.ID = existing.ID
+	accommodation.ItineraryID = itinerary.ID
+	err := accommodation.Delete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting accommodation of destination %d of itinerary %d: %v", destinationId, itinerary.ID, err)
+		return errors.New("failed to delete accommodation")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated,
+		fmt.Sprintf("Accommodation of destination %d of itinerary %d removed.", destinationId, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "destinationId": destinationId, "accommodation": "removed"})
+}
+
+// validateAccommodation checks that the accommodation is for the stay in the destination, with the dates of the check-in and check-out
+// taken in their own time zones, and that its cost is in a supported currency
+func validateAccommodation(destination *models.ItineraryTravelDestination, accommodation *models.DestinationAccommodation) error {
+	if accommodation.Name == "" {
+		return errors.New("invalid accommodation: the name is required")
+	}
+	if !accommodation.CheckIn.Before(accommodation.CheckOut) {
+		return errors.New("invalid accommodation: the check-in must be before the check-out")
+	}
+	if calendarDate(accommodation.CheckIn) < calendarDate(destination.ArrivalDate.UTC()) ||
+		calendarDate(accommodation.CheckOut) > calendarDate(destination.DepartureDate.UTC()) {
+		return fmt.Errorf("invalid accommodation: the check-in and check-out must be between the arrival in and the departure from %s",
+			destination.City)
+	}
+
+	if accommodation.Cost == nil {
+		accommodation.Currency = ""
+		return nil
+	}
+	if *accommodation.Cost < 0 {
+		return errors.New("invalid accommodation: the cost cannot be negative")
+	}
+	currency, err := validateCurrency(accommodation.Currency)
+	if err != nil {
+		return fmt.Errorf("invalid accommodation: %w", err)
+	}
+	accommodation.Currency = currency
+	return nil
+}
+
+// findDestinationAccommodation returns the accommodation of a destination of the itinerary, or nil if it has none
+func findDestinationAccommodation(itinerary *models.Itinerary, destinationId int64) *models.DestinationAccommodation {
+	index := slices.IndexFunc(itinerary.Accommodations, func(accommodation *models.DestinationAccommodation) bool {
+		return accommodation.DestinationID != nil && *accommodation.DestinationID == destinationId
+	})
+	if index < 0 {
+		return nil
+	}
+	return itinerary.Accommodations[index]
+}
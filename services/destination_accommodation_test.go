@@ -0,0 +1,120 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockStoredAccommodations makes the changes of the accommodations succeed and returns the created and updated ones
+func mockStoredAccommodations(t *testing.T) (*[]*models.DestinationAccommodation, *[]*models.DestinationAccommodation) {
+	created := []*models.DestinationAccommodation{}
+	updated := []*models.DestinationAccommodation{}
+	orig := models.InitDestinationAccommodation
+	origFunctions := models.InitDestinationAccommodationFunctions
+	initFunctions := func(a *models.DestinationAccommodation) *models.DestinationAccommodation {
+		a.Create = func() error {
+			a.ID = 4
+			created = append(created, a)
+			return nil
+		}
+		a.Update = func() error {
+			updated = append(updated, a)
+			return nil
+		}
+		a.Delete = func() error { return nil }
+		return a
+	}
+	models.InitDestinationAccommodationFunctions = initFunctions
+	models.InitDestinationAccommodation = func() *models.DestinationAccommodation {
+		return initFunctions(&models.DestinationAccommodation{})
+	}
+	t.Cleanup(func() {
+		models.InitDestinationAccommodation = orig
+		models.InitDestinationAccommodationFunctions = origFunctions
+	})
+	return &created, &updated
+}
+
+func newAccommodation(checkInDay int, checkOutDay int) *models.DestinationAccommodation {
+	return &models.DestinationAccommodation{Name: "Hotel Gran Vía", CheckIn: time.Date(2024, 7, checkInDay, 15, 0, 0, 0, time.UTC),
+		CheckOut: time.Date(2024, 7, checkOutDay, 11, 0, 0, 0, time.UTC)}
+}
+
+func TestDestinationAccommodationService_Save_Create(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	created, updated := mockStoredAccommodations(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	accommodation := newAccommodation(1, 5)
+	cost := 480.0
+	accommodation.Cost, accommodation.Currency = &cost, "eur"
+
+	err := GetDestinationAccommodationService().Save(newDestinationsItinerary(), 10, accommodation, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.DestinationAccommodation{accommodation}, *created)
+	assert.Empty(t, *updated)
+	assert.Equal(t, "Madrid", accommodation.City)
+	assert.Equal(t, "EUR", accommodation.Currency)
+	assert.Equal(t, int64(10), *accommodation.DestinationID)
+	assert.Equal(t, []string{"Accommodation of destination 10 of itinerary 1 added."}, *descriptions)
+}
+
+func TestDestinationAccommodationService_Save_Replace(t *testing.T) {
+	created, updated := mockStoredAccommodations(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	itinerary := newDestinationsItinerary()
+	destinationId := int64(11)
+	itinerary.Accommodations = []*models.DestinationAccommodation{{ID: 3, DestinationID: &destinationId}}
+	accommodation := newAccommodation(5, 8)
+	accommodation.Currency = "EUR"
+
+	err := GetDestinationAccommodationService().Save(itinerary, 11, accommodation, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, *created)
+	assert.Len(t, *updated, 1)
+	assert.Equal(t, int64(3), (*updated)[0].ID)
+	assert.Empty(t, (*updated)[0].Currency)
+	assert.Equal(t, []string{"Accommodation of destination 11 of itinerary 1 updated."}, *descriptions)
+}
+
+func TestDestinationAccommodationService_Save_Invalid(t *testing.T) {
+	mockExchangeRates(t, map[string]float64{"EUR": 1})
+	created, _ := mockStoredAccommodations(t)
+	negative, positive := -1.0, 100.0
+	for _, test := range []struct {
+		accommodation *models.DestinationAccommodation
+		err           string
+	}{
+		{&models.DestinationAccommodation{CheckIn: time.Now(), CheckOut: time.Now().Add(time.Hour)}, "invalid accommodation: the name is required"},
+		{newAccommodation(4, 2), "invalid accommodation: the check-in must be before the check-out"},
+		{newAccommodation(1, 6), "invalid accommodation: the check-in and check-out must be between the arrival in and the departure from Madrid"},
+		{&models.DestinationAccommodation{Name: "Hotel", CheckIn: newAccommodation(1, 5).CheckIn, CheckOut: newAccommodation(1, 5).CheckOut,
+			Cost: &negative, Currency: "EUR"}, "invalid accommodation: the cost cannot be negative"},
+		{&models.DestinationAccommodation{Name: "Hotel", CheckIn: newAccommodation(1, 5).CheckIn, CheckOut: newAccommodation(1, 5).CheckOut,
+			Cost: &positive, Currency: "XXX"}, "invalid accommodation: unsupported currency: XXX"},
+	} {
+		err := GetDestinationAccommodationService().Save(newDestinationsItinerary(), 10, test.accommodation, 2)
+		assert.EqualError(t, err, test.err)
+	}
+	assert.Empty(t, *created)
+}
+
+func TestDestinationAccommodationService_Save_DestinationNotFound(t *testing.T) {
+	created, _ := mockStoredAccommodations(t)
+
+	err := GetDestinationAccommodationService().Save(newDestinationsItinerary(), 99, newAccommodation(1, 5), 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *created)
+}
+
+func TestDestinationAccommodationService_Delete_NotFound(t *testing.T) {
+	mockStoredAccommodations(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	err := GetDestinationAccommodationService().Delete(newDestinationsItinerary(), 10, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *descriptions)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"example.com/travel-advisor/models"
+)
+
+// ErrUnsupportedCurrency is returned by the exchange rate providers for the currencies they have no rates of
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+type ExchangeRateProviderInterface interface {
+	// Rate returns how many units of the currency to one unit of the currency from is worth. Currencies are ISO 4217 codes
+	Rate(from string, to string) (float64, error)
+}
+
+// StaticExchangeRateProvider converts currencies with a fixed table of rates, so it works offline. The rates are approximate
+type StaticExchangeRateProvider struct {
+	// RatesPerEuro are the units of each currency one euro is worth
+	RatesPerEuro map[string]float64
+}
+
+// staticRatesPerEuro are the approximate rates of the static exchange rate provider
+var staticRatesPerEuro = map[string]float64{
+	"EUR": 1,
+	"USD": 1.08,
+	"GBP": 0.85,
+	"JPY": 162,
+	"CHF": 0.95,
+	"CAD": 1.47,
+	"AUD": 1.63,
+	"NZD": 1.78,
+	"CNY": 7.8,
+	"HKD": 8.4,
+	"SGD": 1.45,
+	"KRW": 1470,
+	"INR": 90,
+	"THB": 39,
+	"IDR": 17300,
+	"MYR": 5,
+	"PHP": 62,
+	"VND": 27000,
+	"SEK": 11.4,
+	"NOK": 11.6,
+	"DKK": 7.46,
+	"PLN": 4.3,
+	"CZK": 25,
+	"HUF": 390,
+	"RON": 4.97,
+	"TRY": 35,
+	"ILS": 4,
+	"AED": 3.97,
+	"ZAR": 20,
+	"EGP": 52,
+	"MAD": 10.8,
+	"MXN": 18.5,
+	"BRL": 5.9,
+	"ARS": 1000,
+	"CLP": 1010,
+	"COP": 4300,
+	"PEN": 4.05,
+}
+
+func (serp *StaticExchangeRateProvider) Rate(from string, to string) (float64, error) {
+	fromRate, ok := serp.RatesPerEuro[strings.ToUpper(from)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, from)
+	}
+	toRate, ok := serp.RatesPerEuro[strings.ToUpper(to)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// GetExchangeRateProvider returns the exchange rate provider set in the EXCHANGE_RATE_PROVIDER environment variable
+var GetExchangeRateProvider = func() ExchangeRateProviderInterface {
+	//TODO Support for online exchange rate providers
+	switch os.Getenv("EXCHANGE_RATE_PROVIDER") {
+	case "static":
+		return &StaticExchangeRateProvider{RatesPerEuro: staticRatesPerEuro}
+	default:
+		return &StaticExchangeRateProvider{RatesPerEuro: staticRatesPerEuro}
+	}
+}
+
+// convertAmount converts the amount between the currencies with the exchange rate provider, rounded to hundredths
+func convertAmount(amount models.Amount, from string, to string) (models.Amount, error) {
+	rate, err := GetExchangeRateProvider().Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Convert(rate), nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticExchangeRateProvider_Rate(t *testing.T) {
+	provider := &StaticExchangeRateProvider{RatesPerEuro: map[string]float64{"EUR": 1, "USD": 1.25, "GBP": 0.8}}
+
+	rate, err := provider.Rate("eur", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.25, rate)
+
+	rate, err = provider.Rate("USD", "GBP")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.64, rate)
+
+	_, err = provider.Rate("XXX", "EUR")
+	assert.True(t, errors.Is(err, ErrUnsupportedCurrency))
+}
+
+func TestConvertAmount(t *testing.T) {
+	orig := GetExchangeRateProvider
+	GetExchangeRateProvider = func() ExchangeRateProviderInterface {
+		return &StaticExchangeRateProvider{RatesPerEuro: map[string]float64{"EUR": 1, "USD": 1.5}}
+	}
+	t.Cleanup(func() { GetExchangeRateProvider = orig })
+
+	amount, err := convertAmount(1001, "EUR", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Amount(1502), amount)
+
+	_, err = convertAmount(1000, "EUR", "JPY")
+	assert.Error(t, err)
+}
@@ -3,9 +3,11 @@ package services
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -13,15 +15,66 @@ type ItineraryServiceInterface interface {
 	FindById(id int64, includeDestinations bool) (*models.Itinerary, error)
 	FindLightweightById(id int64) (*models.Itinerary, error)
 	FindByOwnerId(ownerId int64) ([]*models.Itinerary, error)
+	FindItineraries(query ItinerariesQuery) (*ItinerariesPage, error)
 	Create(itinerary *models.Itinerary) error
-	Update(itinerary *models.Itinerary) error
-	Delete(id int64) error
+	Clone(source *models.Itinerary, ownerId int64, options CloneOptions) (*models.Itinerary, error)
+	Update(itinerary *models.Itinerary, actorId int64) error
+	Delete(id int64, version int64, actorId int64) error
 	ValidateItineraryDestinationsDates(destinations []*models.ItineraryTravelDestination) error
 }
 
 type ItineraryService struct {
 }
 
+const (
+	defaultItinerariesPageSize = 20
+	maxItinerariesPageSize     = 100
+)
+
+// Sort orders of the itinerary listings
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// ItinerariesQuery holds the filters, sorting and page of an itinerary search. Sort defaults to the creation date and Order to
+// descending
+type ItinerariesQuery struct {
+	OwnerID    int64
+	Country    string
+	City       string
+	TravelFrom *time.Time
+	TravelTo   *time.Time
+	Title      string
+	Sort       string
+	Order      string
+	Cursor     string
+	Limit      int
+}
+
+// ItinerariesPage is a page of itineraries. TotalCount is the number of itineraries matching the filters in all the pages, and
+// NextCursor is empty on the last page
+type ItinerariesPage struct {
+	Itineraries []*models.Itinerary
+	TotalCount  int64
+	NextCursor  string
+}
+
+// CloneOptions customise the copy of an itinerary. The copy keeps the title of the source unless another one is given, and its dates
+// are moved ShiftDays days, or to start on StartDate if it is set
+type CloneOptions struct {
+	Title     string
+	ShiftDays int
+	StartDate *time.Time
+}
+
+// itinerariesCursor keeps the sorting of the page it was generated for, since it is only valid with the same one
+type itinerariesCursor struct {
+	ID    int64  `json:"id"`
+	Sort  string `json:"sort"`
+	Order string `json:"order"`
+}
+
 // singleton instance
 var itineraryServiceInstance = &ItineraryService{}
 
@@ -60,33 +113,198 @@ func (is *ItineraryService) FindByOwnerId(ownerId int64) ([]*models.Itinerary, e
 	return itinerary.FindByOwnerId(ownerId)
 }
 
-// Create creates a new itinerary
+// FindItineraries retrieves a page of the itineraries matching the query, together with the total count of matching itineraries
+func (is *ItineraryService) FindItineraries(query ItinerariesQuery) (*ItinerariesPage, error) {
+	if query.OwnerID <= 0 {
+		log.Error("Invalid owner ID provided")
+		return nil, errors.New("invalid owner ID")
+	}
+
+	sort := query.Sort
+	if sort == "" {
+		sort = models.ItinerarySortCreationDate
+	}
+	if !slices.Contains(models.ItinerarySortFields, sort) {
+		log.Errorf("invalid itineraries sort field %s", sort)
+		return nil, errors.New("invalid sort field")
+	}
+
+	order := query.Order
+	if order == "" {
+		order = SortOrderDesc
+	}
+	if order != SortOrderAsc && order != SortOrderDesc {
+		log.Errorf("invalid itineraries sort order %s", order)
+		return nil, errors.New("invalid sort order")
+	}
+
+	if query.TravelFrom != nil && query.TravelTo != nil && query.TravelFrom.After(*query.TravelTo) {
+		log.Error("the start of the travel date range is after its end")
+		return nil, errors.New("invalid date range")
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultItinerariesPageSize
+	}
+	if limit > maxItinerariesPageSize {
+		limit = maxItinerariesPageSize
+	}
+
+	filter := models.ItineraryFilter{
+		OwnerID:    query.OwnerID,
+		Country:    query.Country,
+		City:       query.City,
+		TravelFrom: query.TravelFrom,
+		TravelTo:   query.TravelTo,
+		Title:      query.Title,
+		SortBy:     sort,
+		Ascending:  order == SortOrderAsc,
+		// One more itinerary than requested is fetched to know if there is a next page
+		Limit: limit + 1,
+	}
+
+	if query.Cursor != "" {
+		var cursor itinerariesCursor
+		err := utils.DecodeCursor(query.Cursor, &cursor)
+		if err != nil || cursor.ID <= 0 || cursor.Sort != sort || cursor.Order != order {
+			log.Errorf("invalid itineraries cursor %s", query.Cursor)
+			return nil, errors.New("invalid cursor")
+		}
+		filter.AfterID = cursor.ID
+	}
+
+	itinerary := models.InitItinerary()
+
+	itineraries, err := itinerary.Find(filter)
+	if err != nil {
+		log.Errorf("failed to find itineraries: %v", err)
+		return nil, errors.New("failed to find itineraries")
+	}
+
+	totalCount, err := itinerary.Count(filter)
+	if err != nil {
+		log.Errorf("failed to count itineraries: %v", err)
+		return nil, errors.New("failed to count itineraries")
+	}
+
+	page := &ItinerariesPage{Itineraries: itineraries, TotalCount: totalCount}
+	if len(itineraries) > limit {
+		page.Itineraries = itineraries[:limit]
+		page.NextCursor, err = utils.EncodeCursor(itinerariesCursor{ID: page.Itineraries[limit-1].ID, Sort: sort, Order: order})
+		if err != nil {
+			log.Errorf("failed to encode itineraries cursor: %v", err)
+			return nil, errors.New("failed to encode cursor")
+		}
+	}
+
+	return page, nil
+}
+
+// Create creates a new itinerary on behalf of its owner
 func (is *ItineraryService) Create(itinerary *models.Itinerary) error {
 	if itinerary == nil {
 		log.Error("Itinerary instance is nil")
 		return errors.New("itinerary instance is nil")
 	}
-	return itinerary.Create()
+	err := itinerary.Create()
+	if err != nil {
+		return err
+	}
+	indexItinerary(itinerary.ID)
+	return saveAuditEvent(itinerary.OwnerID, models.AuditEventItineraryCreated, fmt.Sprintf("Itinerary %d created.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "title": itinerary.Title})
+}
+
+// Clone creates a copy of the itinerary owned by the user, with its notes and the overrides of its traveller preferences, moving the
+// arrival and departure of every destination by the same number of days. The source needs its destinations
+func (is *ItineraryService) Clone(source *models.Itinerary, ownerId int64, options CloneOptions) (*models.Itinerary, error) {
+	if source == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+	if len(source.TravelDestinations) == 0 {
+		log.Errorf("Itinerary %d has no destinations to clone", source.ID)
+		return nil, errors.New("at least one destination is required")
+	}
+
+	shiftDays := options.ShiftDays
+	if options.StartDate != nil {
+		start := source.TravelDestinations[0].ArrivalDate
+		for _, destination := range source.TravelDestinations {
+			if destination.ArrivalDate.Before(start) {
+				start = destination.ArrivalDate
+			}
+		}
+		shiftDays = daysBetween(start, *options.StartDate)
+	}
+
+	title := options.Title
+	if title == "" {
+		title = source.Title
+	}
+
+	destinations := []*models.ItineraryTravelDestination{}
+	for _, destination := range source.TravelDestinations {
+		destinations = append(destinations, models.NewItineraryTravelDestination(destination.Country, destination.City,
+			destination.ArrivalDate.AddDate(0, 0, shiftDays), destination.DepartureDate.AddDate(0, 0, shiftDays)))
+	}
+
+	itinerary := models.NewItinerary(title, source.Description, source.Notes, destinations)
+	itinerary.OwnerID = ownerId
+	err := itinerary.CreateClone(source.ID)
+	if err != nil {
+		log.Errorf("Error cloning itinerary %d: %v", source.ID, err)
+		return nil, errors.New("failed to clone itinerary")
+	}
+
+	indexItinerary(itinerary.ID)
+	err = saveAuditEvent(ownerId, models.AuditEventItineraryCreated, fmt.Sprintf("Itinerary %d created as a copy of itinerary %d.", itinerary.ID, source.ID),
+		map[string]any{"itineraryId": itinerary.ID, "title": itinerary.Title, "clonedFrom": source.ID, "shiftDays": shiftDays})
+	if err != nil {
+		return nil, err
+	}
+
+	return itinerary, nil
 }
 
-// Update updates the itinerary
-func (is *ItineraryService) Update(itinerary *models.Itinerary) error {
+// daysBetween counts the calendar days from the date of one time to the date of another, ignoring the time of the day
+func daysBetween(from time.Time, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// Update updates the itinerary if it is still in its version, recording the user who changed it in the audit log
+func (is *ItineraryService) Update(itinerary *models.Itinerary, actorId int64) error {
 	if itinerary == nil {
 		log.Error("Itinerary instance is nil")
 		return errors.New("itinerary instance is nil")
 	}
-	return itinerary.Update()
+	err := itinerary.Update(actorId)
+	if err != nil {
+		return err
+	}
+	indexItinerary(itinerary.ID)
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Itinerary %d updated.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "title": itinerary.Title})
 }
 
-// Delete deletes the itinerary
-func (is *ItineraryService) Delete(id int64) error {
+// Delete deletes the itinerary if it is still in the given version, recording the user who deleted it in the audit log
+func (is *ItineraryService) Delete(id int64, version int64, actorId int64) error {
 	if id <= 0 {
 		log.Error("Invalid itinerary ID provided")
 		return errors.New("invalid itinerary ID")
 	}
 	itinerary := models.InitItinerary() // Create a new Itinerary instance
 	itinerary.ID = id                   // Set the ID for the itinerary instance
-	return itinerary.Delete()
+	itinerary.Version = version
+	err := itinerary.Delete()
+	if err != nil {
+		return err
+	}
+	return saveAuditEvent(actorId, models.AuditEventItineraryDeleted, fmt.Sprintf("Itinerary %d deleted.", id),
+		map[string]any{"itineraryId": id})
 }
 func (is *ItineraryService) ValidateItineraryDestinationsDates(destinations []*models.ItineraryTravelDestination) error {
 	if len(destinations) == 0 {
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type ItineraryDestinationServiceInterface interface {
+	Add(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error
+	Update(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error
+	Delete(itinerary *models.Itinerary, destinationId int64, actorId int64) error
+}
+
+type ItineraryDestinationService struct{}
+
+// singleton instance
+var itineraryDestinationServiceInstance = &ItineraryDestinationService{}
+
+// GetItineraryDestinationService returns the singleton instance of ItineraryDestinationService
+var GetItineraryDestinationService = func() ItineraryDestinationServiceInterface {
+	return itineraryDestinationServiceInstance
+}
+
+// Add adds a destination to an itinerary retrieved with its destinations, if the itinerary is still in its version and its
+// destinations stay valid
+func (ids *ItineraryDestinationService) Add(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error {
+	if itinerary == nil || destination == nil {
+		log.Error("Itinerary or destination instance is nil")
+		return errors.New("itinerary or destination instance is nil")
+	}
+
+	itinerary = models.InitItineraryFunctions(itinerary)
+	destinations := append(slices.Clone(itinerary.TravelDestinations), destination)
+	return ids.change(itinerary, destinations, actorId, "added", destination, itinerary.AddDestination)
+}
+
+// Update replaces a destination of an itinerary retrieved with its destinations, if the itinerary is still in its version and its
+// destinations stay valid
+func (ids *ItineraryDestinationService) Update(itinerary *models.Itinerary, destination *models.ItineraryTravelDestination, actorId int64) error {
+	if itinerary == nil || destination == nil {
+		log.Error("Itinerary or destination instance is nil")
+		return errors.New("itinerary or destination instance is nil")
+	}
+
+	index := findDestinationIndex(itinerary, destination.ID)
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+
+	itinerary = models.InitItineraryFunctions(itinerary)
+	destinations := slices.Clone(itinerary.TravelDestinations)
+	destinations[index] = destination
+	return ids.change(itinerary, destinations, actorId, "updated", destination, itinerary.UpdateDestination)
+}
+
+// Delete removes a destination of an itinerary retrieved with its destinations, if the itinerary is still in its version and keeps
+// at least one destination
+func (ids *ItineraryDestinationService) Delete(itinerary *models.Itinerary, destinationId int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	index := findDestinationIndex(itinerary, destinationId)
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+
+	itinerary = models.InitItineraryFunctions(itinerary)
+	destination := models.InitItineraryTravelDestination()
+	destination.ID = destinationId
+	destinations := slices.Delete(slices.Clone(itinerary.TravelDestinations), index, index+1)
+	return ids.change(itinerary, destinations, actorId, "removed", destination, itinerary.DeleteDestination)
+}
+
+// change validates the destinations the itinerary would have after the change, which is saved with them as a new revision
+func (ids *ItineraryDestinationService) change(itinerary *models.Itinerary, destinations []*models.ItineraryTravelDestination, actorId int64,
+	action string, destination *models.ItineraryTravelDestination, save func(*models.ItineraryTravelDestination, int64) error) error {
+	err := GetItineraryService().ValidateItineraryDestinationsDates(destinations)
+	if err != nil {
+		return fmt.Errorf("invalid destinations: %w", err)
+	}
+
+	// Keep the order the destinations are retrieved in, which the revisions are compared by
+	slices.SortStableFunc(destinations, func(a, b *models.ItineraryTravelDestination) int {
+		return a.ArrivalDate.Compare(b.ArrivalDate)
+	})
+
+	previousDestinations := itinerary.TravelDestinations
+	itinerary.TravelDestinations = destinations
+	err = save(destination, actorId)
+	if err != nil {
+		itinerary.TravelDestinations = previousDestinations
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, models.ErrItineraryVersionConflict) {
+			return err
+		}
+		log.Errorf("Error saving destination of itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to save itinerary destination")
+	}
+
+	indexItinerary(itinerary.ID)
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Destination %d of itinerary %d %s.", destination.ID, itinerary.ID, action),
+		map[string]any{"itineraryId": itinerary.ID, "destinationId": destination.ID, "destination": action})
+}
+
+func findDestinationIndex(itinerary *models.Itinerary, destinationId int64) int {
+	return slices.IndexFunc(itinerary.TravelDestinations, func(destination *models.ItineraryTravelDestination) bool {
+		return destination.ID == destinationId
+	})
+}
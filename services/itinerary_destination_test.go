@@ -0,0 +1,119 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSaveDestination makes the destination changes of the itineraries succeed or fail with err, returning the saved destinations
+func mockSaveDestination(t *testing.T, err error) *[]*models.ItineraryTravelDestination {
+	saved := []*models.ItineraryTravelDestination{}
+	save := func(destination *models.ItineraryTravelDestination, authorId int64) error {
+		if err != nil {
+			return err
+		}
+		saved = append(saved, destination)
+		return nil
+	}
+
+	orig := models.InitItineraryFunctions
+	models.InitItineraryFunctions = func(itinerary *models.Itinerary) *models.Itinerary {
+		itinerary.AddDestination = save
+		itinerary.UpdateDestination = save
+		itinerary.DeleteDestination = save
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItineraryFunctions = orig })
+	return &saved
+}
+
+func newDestinationsItinerary() *models.Itinerary {
+	return &models.Itinerary{ID: 1, TravelDestinations: []*models.ItineraryTravelDestination{
+		{ID: 10, Country: "Spain", City: "Madrid", ArrivalDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: 11, Country: "Spain", City: "Seville", ArrivalDate: time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)},
+	}}
+}
+
+func TestItineraryDestinationService_Add_Success(t *testing.T) {
+	saved := mockSaveDestination(t, nil)
+	indexed := mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	itinerary := newDestinationsItinerary()
+	destination := &models.ItineraryTravelDestination{ID: 12, Country: "Spain", City: "Toledo",
+		ArrivalDate: time.Date(2024, 6, 29, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}
+
+	err := GetItineraryDestinationService().Add(itinerary, destination, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ItineraryTravelDestination{destination}, *saved)
+	assert.Equal(t, []string{"Toledo", "Madrid", "Seville"}, destinationCities(itinerary))
+	assert.Equal(t, []int64{1}, *indexed)
+	assert.Equal(t, []string{"Destination 12 of itinerary 1 added."}, *descriptions)
+}
+
+func TestItineraryDestinationService_Add_InvalidDates(t *testing.T) {
+	saved := mockSaveDestination(t, nil)
+	itinerary := newDestinationsItinerary()
+	destination := &models.ItineraryTravelDestination{Country: "Portugal", City: "Lisbon",
+		ArrivalDate: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 8, 5, 0, 0, 0, 0, time.UTC)}
+
+	err := GetItineraryDestinationService().Add(itinerary, destination, 2)
+	assert.EqualError(t, err, "invalid destinations: the itinerary cannot span more than 30 days")
+	assert.Empty(t, *saved)
+	assert.Len(t, itinerary.TravelDestinations, 2)
+}
+
+func TestItineraryDestinationService_Update_NotFound(t *testing.T) {
+	saved := mockSaveDestination(t, nil)
+
+	err := GetItineraryDestinationService().Update(newDestinationsItinerary(), &models.ItineraryTravelDestination{ID: 99}, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *saved)
+}
+
+func TestItineraryDestinationService_Update_VersionConflict(t *testing.T) {
+	mockSaveDestination(t, models.ErrItineraryVersionConflict)
+	itinerary := newDestinationsItinerary()
+	destination := &models.ItineraryTravelDestination{ID: 11, Country: "Spain", City: "Granada",
+		ArrivalDate: time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)}
+
+	err := GetItineraryDestinationService().Update(itinerary, destination, 2)
+	assert.ErrorIs(t, err, models.ErrItineraryVersionConflict)
+	assert.Equal(t, []string{"Madrid", "Seville"}, destinationCities(itinerary))
+}
+
+func TestItineraryDestinationService_Delete(t *testing.T) {
+	saved := mockSaveDestination(t, nil)
+	mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	itinerary := newDestinationsItinerary()
+	svc := GetItineraryDestinationService()
+
+	assert.NoError(t, svc.Delete(itinerary, 10, 2))
+	assert.Equal(t, int64(10), (*saved)[0].ID)
+	assert.Equal(t, []string{"Seville"}, destinationCities(itinerary))
+	assert.Equal(t, []string{"Destination 10 of itinerary 1 removed."}, *descriptions)
+
+	err := svc.Delete(itinerary, 11, 2)
+	assert.EqualError(t, err, "invalid destinations: at least one destination is required")
+	assert.ErrorIs(t, svc.Delete(itinerary, 10, 2), sql.ErrNoRows)
+}
+
+func TestItineraryDestinationService_Delete_SaveError(t *testing.T) {
+	mockSaveDestination(t, errors.New("db error"))
+
+	err := GetItineraryDestinationService().Delete(newDestinationsItinerary(), 10, 2)
+	assert.EqualError(t, err, "failed to save itinerary destination")
+}
+
+func destinationCities(itinerary *models.Itinerary) []string {
+	cities := []string{}
+	for _, destination := range itinerary.TravelDestinations {
+		cities = append(cities, destination.City)
+	}
+	return cities
+}
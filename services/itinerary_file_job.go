@@ -9,10 +9,12 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"example.com/travel-advisor/apis"
 	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	log "github.com/sirupsen/logrus"
@@ -24,16 +26,21 @@ type ItineraryFileJobServiceInterface interface {
 	FindAliveById(id int64) (*models.ItineraryFileJob, error)
 	FindAliveLightweightById(id int64) (*models.ItineraryFileJob, error)
 	FindAliveByItineraryId(itineraryId int64) ([]*models.ItineraryFileJob, error)
-	OpenItineraryJobFile(itineraryFileJob *models.ItineraryFileJob) (io.ReadSeekCloser, error)
+	OpenItineraryJobFile(itineraryFileJob *models.ItineraryFileJob, actorId int64) (io.ReadSeekCloser, error)
 	GetInProgressJobsOfUserCount(userId int64) (int, error)
 	GetInProgressJobsOfItineraryCount(itineraryId int64) (int, error)
-	PrepareJob(itinerary *models.Itinerary) (*ItineraryFileAsyncTaskPayload, error)
+	PrepareJob(itinerary *models.Itinerary, promptTemplateName string, language string, source string, actorId int64) (*ItineraryFileAsyncTaskPayload, error)
+	RegenerateJob(itinerary *models.Itinerary, baseJob *models.ItineraryFileJob, scope *RegenerationScope, instructions string, actorId int64) (*ItineraryFileAsyncTaskPayload, error)
+	FindMessages(job *models.ItineraryFileJob) ([]*models.ItineraryJobMessage, error)
+	SendMessage(itinerary *models.Itinerary, job *models.ItineraryFileJob, content string, actorId int64) (*ItineraryFileAsyncTaskPayload, error)
 	AddAsyncTaskId(asyncTaskId string, itineraryFileJob *models.ItineraryFileJob) error
 	FailJob(errorDescription string, itineraryFileJob *models.ItineraryFileJob) error
-	StopJob(itineraryFileJob *models.ItineraryFileJob) error
-	SoftDeleteJob(itineraryFileJob *models.ItineraryFileJob) error
+	StopJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error
+	ForceStopJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error
+	SoftDeleteJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error
 	SoftDeleteJobsByItineraryId(itineraryId int64, tx *sql.Tx) error
 	DeleteJob(itineraryFileJob *models.ItineraryFileJob) error
+	PurgeJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error
 	DeleteDeadJobs(fetchLimit int) error
 }
 
@@ -52,10 +59,23 @@ const (
 )
 
 type ItineraryFileAsyncTaskPayload struct {
-	Itinerary        *models.Itinerary        `json:"itinerary"`
-	ItineraryFileJob *models.ItineraryFileJob `json:"itineraryFileJob"`
+	Itinerary            *models.Itinerary             `json:"itinerary"`
+	ItineraryFileJob     *models.ItineraryFileJob      `json:"itineraryFileJob"`
+	TravellerPreferences *models.TravellerPreferences  `json:"travellerPreferences,omitempty"`
+	PromptTemplate       *models.PromptTemplate        `json:"promptTemplate,omitempty"`
+	Plan                 []*models.ItineraryDay        `json:"plan,omitempty"`
+	BaseJob              *models.ItineraryFileJob      `json:"baseJob,omitempty"`
+	Instructions         string                        `json:"instructions,omitempty"`
+	Conversation         []*models.ItineraryJobMessage `json:"conversation,omitempty"`
+	// ActorID is the user who started the job, recorded in the audit log with the plans it generates
+	ActorID int64 `json:"actorId,omitempty"`
 }
 
+// itinerarySystemMessage is the system message of the built-in prompt template
+const itinerarySystemMessage = "You are a helpful expert and guide of international travel."
+
+// itineraryPromptTemplate is the built-in prompt template, used when neither the user nor the administrators saved one with the default
+// name
 const itineraryPromptTemplate = `Create a detailed travel itinerary based on the following information:
 Title: {{.title}}
 Description: {{.description}}
@@ -63,10 +83,37 @@ Description: {{.description}}
 {{end}}
 Destinations:
 {{range .travelDestinations}}
-- Country: {{.country}}, City: {{.city}}, Arrival: {{.arrivalDate}}, Departure: {{.departureDate}}
+- Country: {{.country}}, City: {{.city}}, Arrival: {{.localArrivalDate}}, Departure: {{.localDepartureDate}}
 {{end}}
-
-Please provide a day-by-day plan, including recommendations for activities, local attractions, and travel tips for each destination. The plan should provide a schedule for each day, including morning, afternoon, and evening activities. The itinerary should be suitable for a traveler who enjoys cultural experiences, local cuisine, and sightseeing.`
+{{if .transportLegs}}
+Transport between destinations:
+{{range .transportLegs}}- From {{.fromCity}} to {{.toCity}} by {{.mode}}{{if .carrier}} with {{.carrier}}{{end}}{{if .localDepartureTime}}, departing {{.localDepartureTime}}{{end}}{{if .localArrivalTime}}, arriving {{.localArrivalTime}}{{end}}{{if .notes}} ({{.notes}}){{end}}
+{{end}}{{end}}{{if .accommodations}}
+Accommodation:
+{{range .accommodations}}- In {{.city}}: {{.name}}{{if .address}}, {{.address}}{{end}}, check-in {{.localCheckIn}}, check-out {{.localCheckOut}}
+{{end}}{{end}}
+{{if .travellerProfile}}
+Traveller profile:
+{{range .travellerProfile}}- {{.}}
+{{end}}{{end}}
+Please provide a day-by-day plan, including recommendations for activities, local attractions, and travel tips for each destination. The plan should provide a schedule for each day, including morning, afternoon, and evening activities.{{if .transportLegs}} Plan the travel days around the transport between destinations.{{end}}{{if .accommodations}} Start and end each day at the accommodation and favour activities and restaurants near it.{{end}} The itinerary should be suitable for a traveler who enjoys {{.interests}}.{{if .travellerProfile}} Take every point of the traveller profile into account in the activities, restaurants and accommodation you recommend.{{end}}{{if .language}} Write the whole itinerary in {{.languageName}} (language code {{.language}}), with dates, times, numbers and prices written as usual in that locale.{{end}}`
+
+// defaultTravellerInterests are the interests of the travellers who did not set theirs
+const defaultTravellerInterests = "cultural experiences, local cuisine, and sightseeing"
+
+// Descriptions of the paces and budget levels for the prompt
+var (
+	travellerPaceDescriptions = map[string]string{
+		models.TravellerPaceRelaxed:  "relaxed, with few activities a day and plenty of free time",
+		models.TravellerPaceModerate: "moderate, with a balance of activities and free time",
+		models.TravellerPaceIntense:  "intense, making the most of every day",
+	}
+	travellerBudgetDescriptions = map[string]string{
+		models.TravellerBudgetLow:      "budget, favouring free and cheap options",
+		models.TravellerBudgetModerate: "moderate, mid-range options",
+		models.TravellerBudgetLuxury:   "luxury, premium options",
+	}
+)
 
 // FindAliveById retrieves the job by its ID
 func (ifjs *ItineraryFileJobService) FindAliveById(id int64) (*models.ItineraryFileJob, error) {
@@ -97,7 +144,8 @@ func (ifjs *ItineraryFileJobService) FindAliveByItineraryId(itineraryId int64) (
 	return job.FindAliveByItineraryId(itineraryId)
 }
 
-func (itineraryFileJobService *ItineraryFileJobService) OpenItineraryJobFile(itineraryFileJob *models.ItineraryFileJob) (io.ReadSeekCloser, error) {
+// OpenItineraryJobFile opens the file generated by the job, recording the download of the user in the audit log
+func (itineraryFileJobService *ItineraryFileJobService) OpenItineraryJobFile(itineraryFileJob *models.ItineraryFileJob, actorId int64) (io.ReadSeekCloser, error) {
 	if itineraryFileJob == nil {
 		log.Error("itinerary file job instance is nil")
 		return nil, errors.New("itinerary file job instance is nil")
@@ -114,9 +162,53 @@ func (itineraryFileJobService *ItineraryFileJobService) OpenItineraryJobFile(iti
 		return nil, errors.New("failed to open itinerary job file")
 	}
 
+	err = saveAuditEvent(actorId, models.AuditEventJobFileDownloaded, fmt.Sprintf("File of itinerary file job %d downloaded.", itineraryFileJob.ID),
+		map[string]any{"itineraryId": itineraryFileJob.ItineraryID, "itineraryJobId": itineraryFileJob.ID})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
 	return file, nil
 }
 
+// findLatestCompletedJob returns the most recently completed job of the itinerary, or nil if it has none
+func findLatestCompletedJob(itineraryId int64) (*models.ItineraryFileJob, error) {
+	jobs, err := models.InitItineraryFileJob().FindAliveByItineraryId(itineraryId)
+	if err != nil {
+		log.Errorf("Error retrieving itinerary file jobs of itinerary %d: %v", itineraryId, err)
+		return nil, errors.New("failed to find itinerary job")
+	}
+
+	var latestJob *models.ItineraryFileJob
+	for _, job := range jobs {
+		if job.Status == "completed" && (latestJob == nil || job.EndDate.After(latestJob.EndDate)) {
+			latestJob = job
+		}
+	}
+
+	return latestJob, nil
+}
+
+// readJobFile returns the whole content of the file generated by the job
+func readJobFile(job *models.ItineraryFileJob) (*string, error) {
+	file, err := GetFileManager(job.FileManager).OpenFile(job.Filepath)
+	if err != nil {
+		log.Errorf("Error opening file of itinerary file job %d: %v", job.ID, err)
+		return nil, errors.New("failed to open itinerary job file")
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		log.Errorf("Error reading file of itinerary file job %d: %v", job.ID, err)
+		return nil, errors.New("failed to read itinerary job file")
+	}
+
+	document := string(content)
+	return &document, nil
+}
+
 // GetInProgressJobsOfUserCount retrieves the count of running/pending jobs for a user
 func (ifjs *ItineraryFileJobService) GetInProgressJobsOfUserCount(userId int64) (int, error) {
 	if userId <= 0 {
@@ -137,23 +229,86 @@ func (ifjs *ItineraryFileJobService) GetInProgressJobsOfItineraryCount(itinerary
 	return job.GetInProgressJobsOfItineraryCount(itineraryId)
 }
 
-// PrepareJob prepares the job for execution
-func (ifjs *ItineraryFileJobService) PrepareJob(itinerary *models.Itinerary) (*ItineraryFileAsyncTaskPayload, error) {
+// PrepareJob prepares the job for execution with the latest version of the prompt template with the name (the default one if empty) the
+// user who started it can use, recording the user in the audit log. The file is written in the language (a BCP 47 tag), or in the one of
+// the traveller preferences if empty. The file is generated by the LLM, or rendered without any prompt template from the day-by-day plan of
+// the itinerary if the source is "plan", or from the plan the job generates with the LLM if it is "llm_plan". Returns sql.ErrNoRows if
+// there is no such template
+func (ifjs *ItineraryFileJobService) PrepareJob(itinerary *models.Itinerary, promptTemplateName string, language string, source string, actorId int64) (*ItineraryFileAsyncTaskPayload, error) {
 	if itinerary == nil {
 		log.Error("itinerary instance is nil")
 		return nil, errors.New("itinerary instance is nil")
 	}
 
+	var promptTemplate *models.PromptTemplate
+	var plan []*models.ItineraryDay
+	var err error
+	switch source {
+	case "", models.ItineraryFileJobSourceLlm:
+		source = models.ItineraryFileJobSourceLlm
+		promptTemplate, err = GetPromptTemplateService().Resolve(promptTemplateName, actorId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			log.Errorf("failed to resolve prompt template: %v", err)
+			return nil, errors.New("failed to prepare job")
+		}
+	case models.ItineraryFileJobSourcePlan:
+		// The plan is read now, so the file has the activities the itinerary had when asking for it
+		plan, err = GetItineraryPlanService().FindDays(itinerary)
+		if err != nil {
+			log.Errorf("failed to retrieve plan: %v", err)
+			return nil, errors.New("failed to prepare job")
+		}
+		if len(plan) == 0 {
+			return nil, errors.New("invalid plan: the itinerary has no activities")
+		}
+	case models.ItineraryFileJobSourceLlmPlan:
+		if len(itinerary.TravelDestinations) == 0 {
+			return nil, errors.New("invalid plan: the itinerary has no destinations")
+		}
+	default:
+		return nil, fmt.Errorf("invalid source: %s", source)
+	}
+
+	// The preferences are resolved now, so the file is generated with the ones the user had when asking for it
+	preferences, err := GetTravellerPreferencesService().FindEffective(itinerary)
+	if err != nil {
+		log.Errorf("failed to retrieve traveller preferences: %v", err)
+		return nil, errors.New("failed to prepare job")
+	}
+	if language != "" {
+		preferences = preferences.Merge(&models.TravellerPreferences{Language: language})
+	}
+
 	job := models.InitItineraryFileJob()
-	err := job.PrepareJob(itinerary)
+	job.Language = preferences.Language
+	job.Source = source
+	// The built-in template has no ID
+	if promptTemplate != nil && promptTemplate.ID != 0 {
+		job.PromptTemplateID = &promptTemplate.ID
+	}
+	err = job.PrepareJob(itinerary)
 	if err != nil {
 		log.Errorf("failed to prepare job: %v", err)
 		return nil, errors.New("failed to prepare job")
 	}
 
+	err = saveAuditEvent(actorId, models.AuditEventJobStarted, fmt.Sprintf("Itinerary file job %d started.", job.ID),
+		map[string]any{"itineraryId": itinerary.ID, "itineraryJobId": job.ID})
+	if err != nil {
+		job.FailJob("Could not record the start of the job")
+		return nil, err
+	}
+
 	payload := &ItineraryFileAsyncTaskPayload{
-		Itinerary:        itinerary,
-		ItineraryFileJob: job,
+		Itinerary:            itinerary,
+		ItineraryFileJob:     job,
+		TravellerPreferences: preferences,
+		PromptTemplate:       promptTemplate,
+		Plan:                 plan,
+		ActorID:              actorId,
 	}
 
 	return payload, nil
@@ -200,8 +355,8 @@ func (ifjs *ItineraryFileJobService) FailJob(errorDescription string, itineraryF
 	return nil
 }
 
-// StopJob stops the job
-func (ifjs *ItineraryFileJobService) StopJob(itineraryFileJob *models.ItineraryFileJob) error {
+// StopJob stops the job, recording the user who stopped it in the audit log
+func (ifjs *ItineraryFileJobService) StopJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error {
 	if itineraryFileJob == nil {
 		log.Error("itinerary file job instance is nil")
 		return errors.New("itinerary file job instance is nil")
@@ -234,11 +389,46 @@ func (ifjs *ItineraryFileJobService) StopJob(itineraryFileJob *models.ItineraryF
 		return errors.New("failed to stop job")
 	}
 
-	return nil
+	return saveAuditEvent(actorId, models.AuditEventJobStopped, fmt.Sprintf("Itinerary file job %d stopped.", itineraryFileJob.ID),
+		map[string]any{"itineraryId": itineraryFileJob.ItineraryID, "itineraryJobId": itineraryFileJob.ID})
 }
 
-// SoftDeleteJob marks the job as deleted without removing it from the database
-func (ifjs *ItineraryFileJobService) SoftDeleteJob(itineraryFileJob *models.ItineraryFileJob) error {
+// ForceStopJob stops a pending or running job right away, without waiting for the async task timeout like StopJob does.
+// It is meant for administrators, who are the actors recorded in the audit event.
+func (ifjs *ItineraryFileJobService) ForceStopJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error {
+	if itineraryFileJob == nil {
+		log.Error("itinerary file job instance is nil")
+		return errors.New("itinerary file job instance is nil")
+	}
+
+	if itineraryFileJob.Status != "pending" && itineraryFileJob.Status != "running" {
+		log.Errorf("itinerary file job %d is not pending or running. It cannot be stopped", itineraryFileJob.ID)
+		return errors.New("only pending or running itinerary file jobs can be stopped")
+	}
+
+	err := itineraryFileJob.StopJob()
+	if err != nil {
+		log.Errorf("failed to force stop job: %v", err)
+		return errors.New("failed to stop job")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventJobForceStopped, fmt.Sprintf("Itinerary file job %d force-stopped.", itineraryFileJob.ID),
+		map[string]any{"itineraryId": itineraryFileJob.ItineraryID, "itineraryJobId": itineraryFileJob.ID})
+}
+
+// SoftDeleteJob marks the job as deleted without removing it from the database, recording the user who deleted it in the audit log
+func (ifjs *ItineraryFileJobService) SoftDeleteJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error {
+	err := ifjs.softDeleteJob(itineraryFileJob)
+	if err != nil {
+		return err
+	}
+	// The document of a previous job may become the latest one
+	indexItinerary(itineraryFileJob.ItineraryID)
+	return saveAuditEvent(actorId, models.AuditEventJobDeleted, fmt.Sprintf("Itinerary file job %d deleted.", itineraryFileJob.ID),
+		map[string]any{"itineraryId": itineraryFileJob.ItineraryID, "itineraryJobId": itineraryFileJob.ID})
+}
+
+func (ifjs *ItineraryFileJobService) softDeleteJob(itineraryFileJob *models.ItineraryFileJob) error {
 	if itineraryFileJob == nil {
 		log.Error("itinerary file job instance is nil")
 		return errors.New("itinerary file job instance is nil")
@@ -301,6 +491,36 @@ func (ifjs *ItineraryFileJobService) DeleteJob(itineraryFileJob *models.Itinerar
 	return nil
 }
 
+// PurgeJob fully deletes a job and its file right away instead of waiting for the dead jobs cleanup. It is meant for
+// administrators, who are the actors recorded in the audit event.
+func (ifjs *ItineraryFileJobService) PurgeJob(itineraryFileJob *models.ItineraryFileJob, actorId int64) error {
+	if itineraryFileJob == nil {
+		log.Error("itinerary file job instance is nil")
+		return errors.New("itinerary file job instance is nil")
+	}
+
+	if itineraryFileJob.Status == "pending" || itineraryFileJob.Status == "running" {
+		log.Errorf("itinerary file job %d is still pending or running. It cannot be purged", itineraryFileJob.ID)
+		return errors.New("pending or running itinerary file jobs must be stopped before being purged")
+	}
+
+	// Mark the job as deleted first, so the dead jobs cleanup finishes the purge if the full deletion fails
+	err := ifjs.softDeleteJob(itineraryFileJob)
+	if err != nil {
+		return err
+	}
+	itineraryFileJob.Status = "deleted"
+
+	err = ifjs.DeleteJob(itineraryFileJob)
+	if err != nil {
+		return err
+	}
+	indexItinerary(itineraryFileJob.ItineraryID)
+
+	return saveAuditEvent(actorId, models.AuditEventJobPurged, fmt.Sprintf("Itinerary file job %d purged.", itineraryFileJob.ID),
+		map[string]any{"itineraryId": itineraryFileJob.ItineraryID, "itineraryJobId": itineraryFileJob.ID})
+}
+
 // Fully deletes (job file + DB jobs table row removal) a "dead" (in 'deleted' status) jobs from the system.
 // It only deletes the first 'n' jobs it finds on the DB based on the fetchLimit argument value (10 by default if the value is equal or less than 0)
 func (ifjs *ItineraryFileJobService) DeleteDeadJobs(fetchLimit int) error {
@@ -372,35 +592,36 @@ func HandleItineraryFileJob(ctx context.Context, t *asynq.Task) error {
 		return err
 	}
 
-	// Generate the LLM messages for the itinerary
-	prompt, err := buildItineraryLlmPrompt(itinerary)
-	if err != nil {
-		log.Errorf("failed to build itinerary prompt: %v", err)
-		job.FailJob("Failed to build itinerary prompt: " + err.Error())
-		return err
-	}
-
-	// Call the LLM to generate the itinerary
-	messages := []llms.MessageContent{
-		{
-			Role: llms.ChatMessageTypeSystem,
-			Parts: []llms.ContentPart{
-				llms.TextContent{Text: "You are a helpful expert and guide of international travel."},
-			},
-		},
-		{
-			Role: llms.ChatMessageTypeHuman,
-			Parts: []llms.ContentPart{
-				llms.TextContent{Text: *prompt},
-			},
-		},
-	}
-
-	response, err := apis.CallLlm(messages)
-	if err != nil {
-		log.Errorf("failed to call LLM: %v", err)
-		job.FailJob("Failed to generate itinerary: " + err.Error())
-		return err
+	// Render the file from the plan the job was prepared with or from the one it generates, answer the conversation about the file of
+	// the base job with a new version of it, rewrite a part of it, or generate the file with the LLM
+	var response, reply *string
+	statusDescription := "Itinerary generated successfully"
+	if itineraryFileJobTask.ItineraryFileJob.Source == models.ItineraryFileJobSourcePlan {
+		response = renderItineraryPlan(itinerary, itineraryFileJobTask.Plan, itineraryFileJobTask.ItineraryFileJob.Language)
+		statusDescription = "Itinerary rendered from the plan"
+	} else if itineraryFileJobTask.ItineraryFileJob.Source == models.ItineraryFileJobSourceLlmPlan {
+		response, err = generateItineraryPlanFile(ctx, &itineraryFileJobTask, job)
+		if err != nil {
+			return err
+		}
+		statusDescription = "Plan generated and itinerary rendered from it"
+	} else if len(itineraryFileJobTask.Conversation) > 0 {
+		response, reply, err = refineItineraryFile(ctx, &itineraryFileJobTask, job)
+		if err != nil {
+			return err
+		}
+		statusDescription = fmt.Sprintf("Itinerary refined from job %d", itineraryFileJobTask.BaseJob.ID)
+	} else if itineraryFileJobTask.BaseJob != nil {
+		response, err = regenerateItineraryFile(ctx, &itineraryFileJobTask, job)
+		if err != nil {
+			return err
+		}
+		statusDescription = fmt.Sprintf("Itinerary regenerated from job %d", itineraryFileJobTask.BaseJob.ID)
+	} else {
+		response, err = generateItineraryFile(ctx, &itineraryFileJobTask, job)
+		if err != nil {
+			return err
+		}
 	}
 
 	//Get file manager to save the file specified in the configuration settings
@@ -430,7 +651,7 @@ func HandleItineraryFileJob(ctx context.Context, t *asynq.Task) error {
 	}(&err, job.Filepath, fileManager)
 
 	job.Status = "completed"
-	job.StatusDescription = "Itinerary generated successfully"
+	job.StatusDescription = statusDescription
 	job.EndDate = time.Now()
 
 	// Update the job in the database
@@ -441,45 +662,238 @@ func HandleItineraryFileJob(ctx context.Context, t *asynq.Task) error {
 		return err
 	}
 
+	if reply != nil {
+		saveConversationReply(&itineraryFileJobTask, job, *reply)
+	}
+
+	indexItinerary(itinerary.ID)
+
 	return nil
 }
 
-var buildItineraryLlmPrompt = func(itinerary *models.Itinerary) (*string, error) {
+// generateItineraryFile generates the file of the itinerary of the task with the LLM, failing the job on errors
+func generateItineraryFile(ctx context.Context, task *ItineraryFileAsyncTaskPayload, job *models.ItineraryFileJob) (*string, error) {
+	itinerary := task.Itinerary
+
+	// Generate the LLM messages for the itinerary with the prompt template of the job, or the built-in one for the tasks queued before
+	// the templates were available
+	promptTemplate := task.PromptTemplate
+	if promptTemplate == nil {
+		promptTemplate = builtInPromptTemplate()
+	}
+	prompt, err := buildItineraryLlmPrompt(itinerary, task.TravellerPreferences, promptTemplate.Template)
+	if err != nil {
+		log.Errorf("failed to build itinerary prompt: %v", err)
+		job.FailJob("Failed to build itinerary prompt: " + err.Error())
+		return nil, err
+	}
+
+	// Call the LLM to generate the itinerary
+	messages := []llms.MessageContent{
+		{
+			Role: llms.ChatMessageTypeSystem,
+			Parts: []llms.ContentPart{
+				llms.TextContent{Text: promptTemplate.SystemMessage},
+			},
+		},
+		{
+			Role: llms.ChatMessageTypeHuman,
+			Parts: []llms.ContentPart{
+				llms.TextContent{Text: *prompt},
+			},
+		},
+	}
+
+	response, err := apis.CallLlm(ctx, messages)
+	if err != nil {
+		log.Errorf("failed to call LLM: %v", err)
+		job.FailJob("Failed to generate itinerary: " + err.Error())
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// generateItineraryPlanFile replaces the plan of the itinerary of the task with one generated by the LLM and renders the file from it,
+// failing the job on errors
+func generateItineraryPlanFile(ctx context.Context, task *ItineraryFileAsyncTaskPayload, job *models.ItineraryFileJob) (*string, error) {
+	plan, err := GetItineraryPlanService().Generate(ctx, task.Itinerary, task.ActorID)
+	if err != nil {
+		log.Errorf("failed to generate plan: %v", err)
+		job.FailJob("Failed to generate plan: " + err.Error())
+		return nil, err
+	}
+
+	return renderItineraryPlan(task.Itinerary, plan, task.ItineraryFileJob.Language), nil
+}
+
+// renderItineraryPlan writes the day-by-day plan of the itinerary as text, with the dates formatted for the locale of the language
+func renderItineraryPlan(itinerary *models.Itinerary, plan []*models.ItineraryDay, language string) *string {
+	var builder strings.Builder
+	builder.WriteString(itinerary.Title + "\n")
+	if itinerary.Description != "" {
+		builder.WriteString(itinerary.Description + "\n")
+	}
+	for _, day := range plan {
+		builder.WriteString("\n" + utils.FormatLocalDate(day.Date, language) + "\n")
+		for _, activity := range day.Activities {
+			line := fmt.Sprintf("- %s%s: %s", strings.ToUpper(activity.TimeSlot[:1]), activity.TimeSlot[1:], activity.Title)
+			if activity.Location != "" {
+				line += " (" + activity.Location + ")"
+			}
+			builder.WriteString(line + "\n")
+			if activity.Notes != "" {
+				builder.WriteString("  " + activity.Notes + "\n")
+			}
+		}
+	}
+
+	content := builder.String()
+	return &content
+}
+
+// buildItineraryLlmPrompt builds the prompt to generate the itinerary for travellers with the preferences, which can be nil for the
+// tasks queued before the preferences were available, from the prompt template
+var buildItineraryLlmPrompt = func(itinerary *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
 	prompt := prompts.NewChatPromptTemplate([]prompts.MessageFormatter{
 		prompts.NewHumanMessagePromptTemplate(
-			itineraryPromptTemplate,
-			[]string{"title", "description", "notes", "travelStartDate", "travelEndDate", "ownerId", "travelDestinations"},
+			promptTemplate,
+			promptTemplateVariables,
 		),
 	})
 
-	// Prepare travelDestinations for the template
+	message, err := prompt.Format(itineraryPromptInput(itinerary, preferences))
+	if err != nil {
+		log.Errorf("failed to format itinerary prompt: %v", err)
+		return nil, errors.New("failed to format itinerary prompt")
+	}
+
+	log.Debugf("Generated itinerary prompt: %s", message)
+
+	return &message, nil
+
+}
+
+// itineraryPromptInput returns the values of the variables of the prompt templates for the itinerary and the preferences
+func itineraryPromptInput(itinerary *models.Itinerary, preferences *models.TravellerPreferences) map[string]any {
+	if preferences == nil {
+		preferences = &models.TravellerPreferences{}
+	}
+
+	interests := defaultTravellerInterests
+	if len(preferences.Interests) > 0 {
+		interests = strings.Join(preferences.Interests, ", ")
+	}
+
+	// Prepare travelDestinations for the template, with the dates also formatted for the locale of the language
 	var travelDestinations []map[string]any
 	for _, dest := range itinerary.TravelDestinations {
 		travelDestinations = append(travelDestinations, map[string]any{
-			"country":       dest.Country,
-			"city":          dest.City,
-			"arrivalDate":   dest.ArrivalDate,
-			"departureDate": dest.DepartureDate,
+			"country":            dest.Country,
+			"city":               dest.City,
+			"arrivalDate":        dest.ArrivalDate,
+			"departureDate":      dest.DepartureDate,
+			"localArrivalDate":   utils.FormatLocalDate(dest.ArrivalDate, preferences.Language),
+			"localDepartureDate": utils.FormatLocalDate(dest.DepartureDate, preferences.Language),
+		})
+	}
+
+	// Prepare the transport legs still connecting consecutive destinations, with their times formatted in their own time zones
+	var transportLegs []map[string]any
+	for _, leg := range itinerary.TransportLegs {
+		if leg.FromDestinationID == nil {
+			continue
+		}
+		transportLegs = append(transportLegs, map[string]any{
+			"fromCity":           leg.FromCity,
+			"toCity":             leg.ToCity,
+			"mode":               leg.Mode,
+			"carrier":            leg.Carrier,
+			"bookingReference":   leg.BookingReference,
+			"notes":              leg.Notes,
+			"localDepartureTime": formatLocalTime(leg.DepartureTime, preferences.Language),
+			"localArrivalTime":   formatLocalTime(leg.ArrivalTime, preferences.Language),
+		})
+	}
+
+	// Prepare the accommodations of the destinations, where the plan of each day is anchored
+	var accommodations []map[string]any
+	for _, accommodation := range itinerary.Accommodations {
+		if accommodation.DestinationID == nil {
+			continue
+		}
+		accommodations = append(accommodations, map[string]any{
+			"city":          accommodation.City,
+			"name":          accommodation.Name,
+			"address":       accommodation.Address,
+			"localCheckIn":  formatLocalTime(&accommodation.CheckIn, preferences.Language),
+			"localCheckOut": formatLocalTime(&accommodation.CheckOut, preferences.Language),
 		})
 	}
 
-	// Build the input map
-	inputMap := map[string]any{
+	languageName := ""
+	if preferences.Language != "" {
+		languageName = utils.LanguageName(preferences.Language)
+	}
+
+	return map[string]any{
 		"title":              itinerary.Title,
 		"description":        itinerary.Description,
 		"notes":              itinerary.Notes,
 		"ownerId":            itinerary.OwnerID,
 		"travelDestinations": travelDestinations,
+		"transportLegs":      transportLegs,
+		"accommodations":     accommodations,
+		"interests":          interests,
+		"travellerProfile":   describeTravellerProfile(preferences),
+		"language":           preferences.Language,
+		"languageName":       languageName,
 	}
+}
 
-	message, err := prompt.Format(inputMap)
-	if err != nil {
-		log.Errorf("failed to format itinerary prompt: %v", err)
-		return nil, errors.New("failed to format itinerary prompt")
+// formatLocalTime formats a time of a transport leg or accommodation for the locale of the language, or returns an empty string if the
+// time is not set
+func formatLocalTime(t *time.Time, language string) string {
+	if t == nil {
+		return ""
 	}
+	return utils.FormatLocalDate(*t, language) + " " + t.Format("15:04")
+}
 
-	log.Debugf("Generated itinerary prompt: %s", message)
+// describeTravellerProfile lists the preferences of the travellers other than their interests and language as lines of the prompt
+func describeTravellerProfile(preferences *models.TravellerPreferences) []string {
+	profile := []string{}
+	if preferences.Pace != "" {
+		profile = append(profile, "Pace: "+travellerPaceDescriptions[preferences.Pace])
+	}
+	if preferences.BudgetLevel != "" {
+		profile = append(profile, "Budget: "+travellerBudgetDescriptions[preferences.BudgetLevel])
+	}
+	if len(preferences.DietaryRestrictions) > 0 {
+		profile = append(profile, "Dietary restrictions: "+strings.Join(preferences.DietaryRestrictions, ", "))
+	}
+	if preferences.MobilityNeeds != "" {
+		profile = append(profile, "Mobility needs: "+preferences.MobilityNeeds)
+	}
 
-	return &message, nil
+	party := []string{}
+	for _, group := range []struct {
+		count    *int
+		singular string
+		plural   string
+	}{{preferences.Adults, "adult", "adults"}, {preferences.Children, "child", "children"}, {preferences.Seniors, "senior", "seniors"}} {
+		if group.count == nil || *group.count <= 0 {
+			continue
+		}
+		if *group.count == 1 {
+			party = append(party, "1 "+group.singular)
+		} else {
+			party = append(party, utils.FormatLocalNumber(*group.count, preferences.Language)+" "+group.plural)
+		}
+	}
+	if len(party) > 0 {
+		profile = append(profile, "Travelling party: "+strings.Join(party, ", "))
+	}
 
+	return profile
 }
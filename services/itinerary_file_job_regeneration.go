@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+)
+
+// RegenerationScope is the part of the trip a regeneration job rewrites: the days from StartDate to EndDate, or the stay in the
+// destination with DestinationID
+type RegenerationScope struct {
+	StartDate     *time.Time
+	EndDate       *time.Time
+	DestinationID *int64
+}
+
+// regenerationSystemMessage is the system message of the prompt the parts of the itineraries are regenerated with
+const regenerationSystemMessage = "You are a helpful expert and guide of international travel. You only answer with JSON."
+
+// regenerationPromptTemplate is the prompt the parts of the itineraries are regenerated with. The lines of the itinerary are numbered,
+// so the answer says which ones it replaces without repeating them
+const regenerationPromptTemplate = `This is a travel itinerary, with its lines numbered:
+{{.itinerary}}
+
+Rewrite only the part of the itinerary about {{.scope}}, keeping it consistent with the days before and after it{{if .languageName}} and
+writing it in {{.languageName}}{{end}}.
+{{if .travellerProfile}}
+Traveller profile:
+{{range .travellerProfile}}- {{.}}
+{{end}}{{end}}{{if .instructions}}
+Instructions of the travellers: {{.instructions}}
+{{end}}
+Answer only with a JSON object like {"startLine": 12, "endLine": 30, "text": "..."}, with the numbers of the first and last lines of the
+part you rewrite and its new text, without line numbers.`
+
+// generatedRegeneration is the answer the parts of the itineraries are regenerated with
+type generatedRegeneration struct {
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Text      string `json:"text"`
+}
+
+// RegenerateJob prepares a job that rewrites the part of the file of a completed job of an itinerary retrieved with its destinations
+// about the days or destination of the scope, recording the user who started it in the audit log. The rest of the file is kept, and the
+// base job stays available for comparison. The new part is written in the language of the base job and follows the instructions, if
+// any. Returns sql.ErrNoRows if the itinerary has no such destination
+func (ifjs *ItineraryFileJobService) RegenerateJob(itinerary *models.Itinerary, baseJob *models.ItineraryFileJob, scope *RegenerationScope, instructions string, actorId int64) (*ItineraryFileAsyncTaskPayload, error) {
+	if itinerary == nil || baseJob == nil || scope == nil {
+		log.Error("itinerary, base job or scope instance is nil")
+		return nil, errors.New("itinerary, base job or scope instance is nil")
+	}
+	if baseJob.Status != "completed" || baseJob.Filepath == "" {
+		return nil, errors.New("invalid base job: only the files of completed jobs can be regenerated")
+	}
+
+	startDate, endDate, err := resolveRegenerationScope(itinerary, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := GetTravellerPreferencesService().FindEffective(itinerary)
+	if err != nil {
+		log.Errorf("failed to retrieve traveller preferences: %v", err)
+		return nil, errors.New("failed to prepare job")
+	}
+	// The new part is written in the language of the file of the base job
+	preferences = preferences.Merge(nil)
+	preferences.Language = baseJob.Language
+
+	job := models.InitItineraryFileJob()
+	job.Language = baseJob.Language
+	job.Source = models.ItineraryFileJobSourceLlm
+	job.BaseJobID = &baseJob.ID
+	job.ScopeStartDate = &startDate
+	job.ScopeEndDate = &endDate
+	err = job.PrepareJob(itinerary)
+	if err != nil {
+		log.Errorf("failed to prepare job: %v", err)
+		return nil, errors.New("failed to prepare job")
+	}
+
+	err = saveAuditEvent(actorId, models.AuditEventJobStarted,
+		fmt.Sprintf("Itinerary file job %d started to regenerate job %d.", job.ID, baseJob.ID),
+		map[string]any{"itineraryId": itinerary.ID, "itineraryJobId": job.ID, "baseJobId": baseJob.ID,
+			"scopeStartDate": calendarDate(startDate), "scopeEndDate": calendarDate(endDate)})
+	if err != nil {
+		job.FailJob("Could not record the start of the job")
+		return nil, err
+	}
+
+	return &ItineraryFileAsyncTaskPayload{
+		Itinerary:            itinerary,
+		ItineraryFileJob:     job,
+		TravellerPreferences: preferences,
+		BaseJob:              baseJob,
+		Instructions:         instructions,
+	}, nil
+}
+
+// resolveRegenerationScope returns the first and last days of the scope, at midnight UTC, which must be during the trip
+func resolveRegenerationScope(itinerary *models.Itinerary, scope *RegenerationScope) (time.Time, time.Time, error) {
+	if len(itinerary.TravelDestinations) == 0 {
+		return time.Time{}, time.Time{}, errors.New("invalid scope: the itinerary has no destinations")
+	}
+
+	if scope.DestinationID != nil {
+		if scope.StartDate != nil || scope.EndDate != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid scope: give either a date range or a destination")
+		}
+		index := findDestinationIndex(itinerary, *scope.DestinationID)
+		if index < 0 {
+			return time.Time{}, time.Time{}, sql.ErrNoRows
+		}
+		destination := itinerary.TravelDestinations[index]
+		return planDate(destination.ArrivalDate.UTC()), planDate(destination.DepartureDate.UTC()), nil
+	}
+
+	if scope.StartDate == nil || scope.EndDate == nil {
+		return time.Time{}, time.Time{}, errors.New("invalid scope: give either a date range or a destination")
+	}
+	startDate, endDate := planDate(*scope.StartDate), planDate(*scope.EndDate)
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, errors.New("invalid scope: the start date cannot be after the end date")
+	}
+	tripStartDate, tripEndDate := tripDates(itinerary)
+	if startDate.Before(tripStartDate) || endDate.After(tripEndDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid scope: the dates must be between %s and %s", calendarDate(tripStartDate),
+			calendarDate(tripEndDate))
+	}
+	return startDate, endDate, nil
+}
+
+// regenerateItineraryFile rewrites the part of the file of the base job of the task about its scope with the LLM, failing the job on
+// errors
+func regenerateItineraryFile(ctx context.Context, task *ItineraryFileAsyncTaskPayload, job *models.ItineraryFileJob) (*string, error) {
+	base, err := readJobFile(task.BaseJob)
+	if err != nil {
+		job.FailJob("Failed to read the file of the base job: " + err.Error())
+		return nil, err
+	}
+
+	lines := strings.Split(*base, "\n")
+	numberedLines := make([]string, len(lines))
+	for i, line := range lines {
+		numberedLines[i] = fmt.Sprintf("%d: %s", i+1, line)
+	}
+
+	preferences := task.TravellerPreferences
+	if preferences == nil {
+		preferences = &models.TravellerPreferences{}
+	}
+	languageName := ""
+	if preferences.Language != "" {
+		languageName = utils.LanguageName(preferences.Language)
+	}
+
+	prompt, err := prompts.NewPromptTemplate(regenerationPromptTemplate,
+		[]string{"itinerary", "scope", "languageName", "travellerProfile", "instructions"}).
+		Format(map[string]any{"itinerary": strings.Join(numberedLines, "\n"),
+			"scope":            describeRegenerationScope(task.Itinerary, task.ItineraryFileJob, preferences.Language),
+			"languageName":     languageName,
+			"travellerProfile": describeTravellerProfile(preferences),
+			"instructions":     task.Instructions})
+	if err != nil {
+		log.Errorf("failed to format regeneration prompt: %v", err)
+		job.FailJob("Failed to build regeneration prompt: " + err.Error())
+		return nil, err
+	}
+
+	response, err := apis.CallLlm(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, regenerationSystemMessage),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	})
+	if err != nil || response == nil {
+		if err == nil {
+			err = errors.New("empty answer")
+		}
+		log.Errorf("failed to call LLM: %v", err)
+		job.FailJob("Failed to regenerate itinerary: " + err.Error())
+		return nil, err
+	}
+
+	generated := &generatedRegeneration{}
+	err = parseLlmJsonObject(*response, generated)
+	if err == nil {
+		err = spliceLines(&lines, generated.StartLine, generated.EndLine, generated.Text)
+	}
+	if err != nil {
+		log.Errorf("failed to splice regenerated part of job %d: %v", task.BaseJob.ID, err)
+		job.FailJob("Failed to regenerate itinerary: " + err.Error())
+		return nil, err
+	}
+
+	content := strings.Join(lines, "\n")
+	return &content, nil
+}
+
+// spliceLines replaces the lines from startLine to endLine, numbered from 1, with the ones of the text
+func spliceLines(lines *[]string, startLine int, endLine int, text string) error {
+	if startLine < 1 || endLine < startLine || endLine > len(*lines) {
+		return fmt.Errorf("invalid lines %d to %d of %d", startLine, endLine, len(*lines))
+	}
+
+	spliced := append([]string{}, (*lines)[:startLine-1]...)
+	spliced = append(spliced, strings.Split(strings.TrimRight(text, "\n"), "\n")...)
+	*lines = append(spliced, (*lines)[endLine:]...)
+	return nil
+}
+
+// describeRegenerationScope describes the days the job regenerates, with the dates formatted for the locale of the language like in
+// the file, and the cities the travellers are in during them
+func describeRegenerationScope(itinerary *models.Itinerary, job *models.ItineraryFileJob, language string) string {
+	if job.ScopeStartDate == nil || job.ScopeEndDate == nil {
+		return "the whole trip"
+	}
+
+	startDate, endDate := *job.ScopeStartDate, *job.ScopeEndDate
+	description := "the day " + utils.FormatLocalDate(startDate, language)
+	if !startDate.Equal(endDate) {
+		description = fmt.Sprintf("the days from %s to %s", utils.FormatLocalDate(startDate, language), utils.FormatLocalDate(endDate, language))
+	}
+
+	cities := []string{}
+	for _, destination := range itinerary.TravelDestinations {
+		if planDate(destination.ArrivalDate.UTC()).After(endDate) || planDate(destination.DepartureDate.UTC()).Before(startDate) {
+			continue
+		}
+		cities = append(cities, destination.City)
+	}
+	if len(cities) > 0 {
+		description += " in " + strings.Join(cities, " and ")
+	}
+	return description
+}
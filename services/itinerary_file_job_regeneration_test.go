@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func newBaseJob() *models.ItineraryFileJob {
+	return &models.ItineraryFileJob{ID: 4, ItineraryID: 1, Status: "completed", Filepath: "files/users/2/itineraries/1/base.txt",
+		FileManager: "local", Language: "es"}
+}
+
+func TestItineraryFileJobRegenerateJob_Destination(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	preferences := &models.TravellerPreferences{Pace: models.TravellerPaceRelaxed, Language: "pt-BR"}
+	mockEffectiveTravellerPreferences(t, preferences, nil)
+	origInitItineraryFileJob := models.InitItineraryFileJob
+	t.Cleanup(func() { models.InitItineraryFileJob = origInitItineraryFileJob })
+	ifj := mockItineraryFileJob()
+	ifj.ID = 5
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+	destinationId := int64(11)
+
+	payload, err := (&ItineraryFileJobService{}).RegenerateJob(newDestinationsItinerary(), newBaseJob(),
+		&RegenerationScope{DestinationID: &destinationId}, "More tapas", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), *payload.ItineraryFileJob.BaseJobID)
+	assert.Equal(t, planDay(5), *payload.ItineraryFileJob.ScopeStartDate)
+	assert.Equal(t, planDay(8), *payload.ItineraryFileJob.ScopeEndDate)
+	assert.Equal(t, "es", payload.ItineraryFileJob.Language)
+	assert.Equal(t, "es", payload.TravellerPreferences.Language)
+	assert.Equal(t, models.TravellerPaceRelaxed, payload.TravellerPreferences.Pace)
+	assert.Equal(t, "pt-BR", preferences.Language)
+	assert.Equal(t, "More tapas", payload.Instructions)
+	assert.Equal(t, []string{"Itinerary file job 5 started to regenerate job 4."}, *descriptions)
+}
+
+func TestItineraryFileJobRegenerateJob_AuditFails(t *testing.T) {
+	mockSaveAuditEvent(t, errors.New("error saving audit event"))
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	origInitItineraryFileJob := models.InitItineraryFileJob
+	t.Cleanup(func() { models.InitItineraryFileJob = origInitItineraryFileJob })
+	ifj := mockItineraryFileJob()
+	failed := false
+	ifj.FailJob = func(desc string) error {
+		failed = true
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+	destinationId := int64(11)
+
+	payload, err := (&ItineraryFileJobService{}).RegenerateJob(newDestinationsItinerary(), newBaseJob(),
+		&RegenerationScope{DestinationID: &destinationId}, "", 2)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "error saving audit event")
+	assert.True(t, failed)
+}
+
+func TestItineraryFileJobRegenerateJob_Invalid(t *testing.T) {
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	origInitItineraryFileJob := models.InitItineraryFileJob
+	t.Cleanup(func() { models.InitItineraryFileJob = origInitItineraryFileJob })
+	ifj := mockItineraryFileJob()
+	prepared := false
+	ifj.PrepareJob = func(it *models.Itinerary) error {
+		prepared = true
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+	day := func(d int) *time.Time {
+		date := planDay(d)
+		return &date
+	}
+	destinationId, missingDestinationId := int64(10), int64(99)
+
+	for _, test := range []struct {
+		itinerary *models.Itinerary
+		baseJob   *models.ItineraryFileJob
+		scope     *RegenerationScope
+		err       string
+	}{
+		{newDestinationsItinerary(), &models.ItineraryFileJob{ID: 4, Status: "running"}, &RegenerationScope{DestinationID: &destinationId},
+			"invalid base job: only the files of completed jobs can be regenerated"},
+		{&models.Itinerary{ID: 1}, newBaseJob(), &RegenerationScope{DestinationID: &destinationId},
+			"invalid scope: the itinerary has no destinations"},
+		{newDestinationsItinerary(), newBaseJob(), &RegenerationScope{}, "invalid scope: give either a date range or a destination"},
+		{newDestinationsItinerary(), newBaseJob(), &RegenerationScope{StartDate: day(2), EndDate: day(3), DestinationID: &destinationId},
+			"invalid scope: give either a date range or a destination"},
+		{newDestinationsItinerary(), newBaseJob(), &RegenerationScope{StartDate: day(3), EndDate: day(2)},
+			"invalid scope: the start date cannot be after the end date"},
+		{newDestinationsItinerary(), newBaseJob(), &RegenerationScope{StartDate: day(7), EndDate: day(9)},
+			"invalid scope: the dates must be between 2024-07-01 and 2024-07-08"},
+		{newDestinationsItinerary(), newBaseJob(), &RegenerationScope{DestinationID: &missingDestinationId}, sql.ErrNoRows.Error()},
+	} {
+		payload, err := (&ItineraryFileJobService{}).RegenerateJob(test.itinerary, test.baseJob, test.scope, "", 2)
+		assert.Nil(t, payload)
+		assert.EqualError(t, err, test.err)
+	}
+	assert.False(t, prepared)
+}
+
+func TestHandleItineraryFileJob_Regeneration(t *testing.T) {
+	mockIndexItinerary(t)
+	baseJob := newBaseJob()
+	fileManager := &inMemoryFileManager{files: map[string]string{baseJob.Filepath: "Summer in Spain\n\n01/07/2024\nMuseums\n" +
+		"\n02/07/2024\nMore museums\n\n03/07/2024\nLeave Madrid"}}
+	setMockFileManager(t, fileManager)
+	job := mockItineraryFileJob()
+	job.FailJob = func(desc string) error {
+		t.Errorf("FailJob should not be called on success: %s", desc)
+		return nil
+	}
+	var statusDescription string
+	job.CompleteJob = func() error {
+		statusDescription = job.StatusDescription
+		return nil
+	}
+	origNewItineraryFileJob := models.NewItineraryFileJob
+	t.Cleanup(func() { models.NewItineraryFileJob = origNewItineraryFileJob })
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	var prompt string
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		prompt = msgs[1].Parts[0].(llms.TextContent).Text
+		response := "```json\n" + `{"startLine": 6, "endLine": 7, "text": "02/07/2024\nWalk in El Retiro\n"}` + "\n```"
+		return &response, nil
+	}
+
+	startDate, endDate := planDay(2), planDay(2)
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary: newDestinationsItinerary(),
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 5, ItineraryID: 1, Language: "es", Source: models.ItineraryFileJobSourceLlm,
+			BaseJobID: &baseJob.ID, ScopeStartDate: &startDate, ScopeEndDate: &endDate},
+		TravellerPreferences: &models.TravellerPreferences{Language: "es"},
+		BaseJob:              baseJob,
+		Instructions:         "Less museums",
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.NoError(t, err)
+	assert.Equal(t, "Itinerary regenerated from job 4", statusDescription)
+	assert.Contains(t, prompt, "6: 02/07/2024\n7: More museums")
+	assert.Contains(t, prompt, "the day 02/07/2024 in Madrid")
+	assert.Contains(t, prompt, "Instructions of the travellers: Less museums")
+	assert.Equal(t, "Summer in Spain\n\n01/07/2024\nMuseums\n\n02/07/2024\nWalk in El Retiro\n\n03/07/2024\nLeave Madrid",
+		fileManager.files[job.Filepath])
+}
+
+func TestHandleItineraryFileJob_RegenerationInvalidLines(t *testing.T) {
+	baseJob := newBaseJob()
+	setMockFileManager(t, &inMemoryFileManager{files: map[string]string{baseJob.Filepath: "Summer in Spain\n\n01/07/2024\nMuseums"}})
+	job := mockItineraryFileJob()
+	var failure string
+	job.FailJob = func(desc string) error {
+		failure = desc
+		return nil
+	}
+	job.CompleteJob = func() error {
+		t.Errorf("CompleteJob should not be called on failure")
+		return nil
+	}
+	origNewItineraryFileJob := models.NewItineraryFileJob
+	t.Cleanup(func() { models.NewItineraryFileJob = origNewItineraryFileJob })
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		response := `{"startLine": 3, "endLine": 9, "text": "01/07/2024\nWalk in El Retiro"}`
+		return &response, nil
+	}
+
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:        newDestinationsItinerary(),
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 5, ItineraryID: 1, BaseJobID: &baseJob.ID},
+		BaseJob:          baseJob,
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.EqualError(t, err, "invalid lines 3 to 9 of 4")
+	assert.Equal(t, "Failed to regenerate itinerary: invalid lines 3 to 9 of 4", failure)
+}
+
+func TestSpliceLines(t *testing.T) {
+	lines := []string{"a", "b", "c", "d"}
+	assert.NoError(t, spliceLines(&lines, 2, 3, "x\ny\nz\n"))
+	assert.Equal(t, []string{"a", "x", "y", "z", "d"}, lines)
+
+	assert.NoError(t, spliceLines(&lines, 5, 5, "e"))
+	assert.Equal(t, []string{"a", "x", "y", "z", "e"}, lines)
+
+	assert.Error(t, spliceLines(&lines, 0, 1, "x"))
+	assert.Error(t, spliceLines(&lines, 3, 2, "x"))
+	assert.Error(t, spliceLines(&lines, 1, 6, "x"))
+}
@@ -228,12 +228,14 @@ func TestItineraryFileJobService_GetInProgressJobsOfItineraryCount_Success(t *te
 
 func TestItineraryFileJobPrepareJob_NilItinerary(t *testing.T) {
 	svc := &ItineraryFileJobService{}
-	payload, err := svc.PrepareJob(nil)
+	payload, err := svc.PrepareJob(nil, "", "", "", 2)
 	assert.Nil(t, payload)
 	assert.Error(t, err)
 }
 
 func TestItineraryFileJobPrepareJob_PrepareJobFails(t *testing.T) {
+	mockResolvePromptTemplate(t, builtInPromptTemplate(), nil)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
 	ifj := mockItineraryFileJob()
 	ifj.PrepareJob = func(it *models.Itinerary) error {
 		return errors.New("prepare job failed")
@@ -244,12 +246,17 @@ func TestItineraryFileJobPrepareJob_PrepareJobFails(t *testing.T) {
 
 	svc := &ItineraryFileJobService{}
 	it := &models.Itinerary{ID: 1}
-	payload, err := svc.PrepareJob(it)
+	payload, err := svc.PrepareJob(it, "", "", "", 2)
 	assert.Nil(t, payload)
 	assert.Error(t, err)
 }
 
 func TestItineraryFileJobPrepareJob_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	preferences := &models.TravellerPreferences{Pace: models.TravellerPaceRelaxed}
+	mockEffectiveTravellerPreferences(t, preferences, nil)
+	promptTemplate := &models.PromptTemplate{ID: 5, Name: "kids", Version: 2, Template: "Plan {{.title}}"}
+	mockResolvePromptTemplate(t, promptTemplate, nil)
 	ifj := mockItineraryFileJob()
 	ifj.PrepareJob = func(it *models.Itinerary) error {
 		return nil // Simulate successful preparation
@@ -260,10 +267,153 @@ func TestItineraryFileJobPrepareJob_Success(t *testing.T) {
 
 	svc := &ItineraryFileJobService{}
 	it := &models.Itinerary{ID: 2}
-	payload, err := svc.PrepareJob(it)
+	payload, err := svc.PrepareJob(it, "", "", "", 2)
 	assert.NoError(t, err)
 	assert.NotNil(t, payload)
 	assert.Equal(t, it, payload.Itinerary)
+	assert.Same(t, preferences, payload.TravellerPreferences)
+	assert.Same(t, promptTemplate, payload.PromptTemplate)
+	assert.Equal(t, int64(5), *payload.ItineraryFileJob.PromptTemplateID)
+	assert.Equal(t, []string{"Itinerary file job 1 started."}, *descriptions)
+}
+
+func TestItineraryFileJobPrepareJob_Language(t *testing.T) {
+	mockSaveAuditEvent(t, nil)
+	mockResolvePromptTemplate(t, builtInPromptTemplate(), nil)
+	preferences := &models.TravellerPreferences{Pace: models.TravellerPaceRelaxed, Language: "es"}
+	mockEffectiveTravellerPreferences(t, preferences, nil)
+	ifj := mockItineraryFileJob()
+	ifj.PrepareJob = func(it *models.Itinerary) error {
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 2}, "", "pt-BR", "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "pt-BR", payload.ItineraryFileJob.Language)
+	assert.Equal(t, "pt-BR", payload.TravellerPreferences.Language)
+	assert.Equal(t, models.TravellerPaceRelaxed, payload.TravellerPreferences.Pace)
+	assert.Equal(t, "es", preferences.Language)
+
+	payload, err = (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 2}, "", "", "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "es", payload.ItineraryFileJob.Language)
+}
+
+func TestItineraryFileJobPrepareJob_Plan(t *testing.T) {
+	mockSaveAuditEvent(t, nil)
+	mockResolvePromptTemplate(t, nil, errors.New("the plan needs no prompt template"))
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	mockStoredActivities(t, newPlanActivities(), false)
+	ifj := mockItineraryFileJob()
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 1}, "", "", models.ItineraryFileJobSourcePlan, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ItineraryFileJobSourcePlan, payload.ItineraryFileJob.Source)
+	assert.Nil(t, payload.ItineraryFileJob.PromptTemplateID)
+	assert.Nil(t, payload.PromptTemplate)
+	assert.Len(t, payload.Plan, 2)
+}
+
+func TestItineraryFileJobPrepareJob_EmptyPlan(t *testing.T) {
+	mockStoredActivities(t, []*models.ItineraryActivity{}, false)
+	ifj := mockItineraryFileJob()
+	prepared := false
+	ifj.PrepareJob = func(it *models.Itinerary) error {
+		prepared = true
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 1}, "", "", models.ItineraryFileJobSourcePlan, 2)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "invalid plan: the itinerary has no activities")
+	assert.False(t, prepared)
+}
+
+func TestItineraryFileJobPrepareJob_LlmPlan(t *testing.T) {
+	mockSaveAuditEvent(t, nil)
+	mockResolvePromptTemplate(t, nil, errors.New("the plan needs no prompt template"))
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	ifj := mockItineraryFileJob()
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 1}, "", "", models.ItineraryFileJobSourceLlmPlan, 2)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "invalid plan: the itinerary has no destinations")
+
+	payload, err = (&ItineraryFileJobService{}).PrepareJob(newDestinationsItinerary(), "", "", models.ItineraryFileJobSourceLlmPlan, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ItineraryFileJobSourceLlmPlan, payload.ItineraryFileJob.Source)
+	assert.Nil(t, payload.PromptTemplate)
+	assert.Nil(t, payload.Plan)
+	assert.Equal(t, int64(2), payload.ActorID)
+}
+
+func TestItineraryFileJobPrepareJob_PromptTemplateNotFound(t *testing.T) {
+	mockResolvePromptTemplate(t, nil, sql.ErrNoRows)
+	ifj := mockItineraryFileJob()
+	prepared := false
+	ifj.PrepareJob = func(it *models.Itinerary) error {
+		prepared = true
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 2}, "kids", "", "", 2)
+	assert.Nil(t, payload)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.False(t, prepared)
+}
+
+func TestItineraryFileJobPrepareJob_PreferencesFail(t *testing.T) {
+	mockResolvePromptTemplate(t, builtInPromptTemplate(), nil)
+	mockEffectiveTravellerPreferences(t, nil, errors.New("failed to retrieve traveller preferences"))
+	ifj := mockItineraryFileJob()
+	prepared := false
+	ifj.PrepareJob = func(it *models.Itinerary) error {
+		prepared = true
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 2}, "", "", "", 2)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "failed to prepare job")
+	assert.False(t, prepared)
+}
+
+func TestItineraryFileJobPrepareJob_AuditFails(t *testing.T) {
+	mockResolvePromptTemplate(t, builtInPromptTemplate(), nil)
+	mockSaveAuditEvent(t, errors.New("error saving audit event"))
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	ifj := mockItineraryFileJob()
+	failed := false
+	ifj.FailJob = func(desc string) error {
+		failed = true
+		return nil
+	}
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		return ifj
+	}
+
+	payload, err := (&ItineraryFileJobService{}).PrepareJob(&models.Itinerary{ID: 2}, "", "", "", 2)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "error saving audit event")
+	assert.True(t, failed)
 }
 
 func TestItineraryFileJobAddAsyncTaskId_EmptyTaskId(t *testing.T) {
@@ -337,7 +487,7 @@ func TestItineraryFileJobFailJob_Success(t *testing.T) {
 
 func TestItineraryFileJobStopJob_NilJob(t *testing.T) {
 	svc := &ItineraryFileJobService{}
-	err := svc.StopJob(nil)
+	err := svc.StopJob(nil, 2)
 	assert.Error(t, err)
 }
 
@@ -348,7 +498,7 @@ func TestItineraryFileJobStopJob_JobCompleted(t *testing.T) {
 		ifj.Status = ""
 	}()
 
-	err := (&ItineraryFileJobService{}).StopJob(ifj)
+	err := (&ItineraryFileJobService{}).StopJob(ifj, 2)
 	assert.Error(t, err)
 }
 
@@ -359,7 +509,7 @@ func TestItineraryFileJobStopJob_TimeoutNotReached(t *testing.T) {
 		ifj.CreationDate = time.Now()
 	}()
 
-	err := (&ItineraryFileJobService{}).StopJob(ifj)
+	err := (&ItineraryFileJobService{}).StopJob(ifj, 2)
 	assert.Error(t, err)
 }
 
@@ -369,11 +519,12 @@ func TestItineraryFileJobStopJob_Fail(t *testing.T) {
 		return errors.New("fail")
 	}
 
-	err := (&ItineraryFileJobService{}).StopJob(ifj)
+	err := (&ItineraryFileJobService{}).StopJob(ifj, 2)
 	assert.Error(t, err)
 }
 
 func TestItineraryFileJobStopJob_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
 	ifj := mockItineraryFileJob()
 	ifj.CreationDate = time.Now().Add(11 * -time.Minute) // Simulate job started 11 minutes ago (above default timeout of 10 minutes)
 	defer func() {
@@ -384,11 +535,13 @@ func TestItineraryFileJobStopJob_Success(t *testing.T) {
 		return nil // Simulate successful stopping of job
 	}
 
-	err := (&ItineraryFileJobService{}).StopJob(ifj)
+	err := (&ItineraryFileJobService{}).StopJob(ifj, 2)
 	assert.NoError(t, err)
+	assert.Equal(t, []string{"Itinerary file job 1 stopped."}, *descriptions)
 }
 
 func TestItineraryFileJobStopJob_Success_CustomTimeout(t *testing.T) {
+	mockSaveAuditEvent(t, nil)
 	os.Setenv("ASYNC_TASK_TIMEOUT_MINUTES", "2")
 
 	ifj := mockItineraryFileJob()
@@ -402,7 +555,7 @@ func TestItineraryFileJobStopJob_Success_CustomTimeout(t *testing.T) {
 		return nil // Simulate successful stopping of job
 	}
 
-	err := (&ItineraryFileJobService{}).StopJob(ifj)
+	err := (&ItineraryFileJobService{}).StopJob(ifj, 2)
 	assert.NoError(t, err)
 }
 
@@ -457,7 +610,7 @@ func TestHandleItineraryFileJob_BuildPromptFails(t *testing.T) {
 
 	origBuildPrompt := buildItineraryLlmPrompt
 	defer func() { buildItineraryLlmPrompt = origBuildPrompt }()
-	buildItineraryLlmPrompt = func(it *models.Itinerary) (*string, error) {
+	buildItineraryLlmPrompt = func(it *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
 		return nil, errors.New("prompt fail")
 	}
 
@@ -489,13 +642,13 @@ func TestHandleItineraryFileJob_LlmCallFails(t *testing.T) {
 	origBuildPrompt := buildItineraryLlmPrompt
 	defer func() { buildItineraryLlmPrompt = origBuildPrompt }()
 	prompt := "prompt"
-	buildItineraryLlmPrompt = func(it *models.Itinerary) (*string, error) {
+	buildItineraryLlmPrompt = func(it *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
 		return &prompt, nil
 	}
 
 	origCallLlm := apis.CallLlm
 	defer func() { apis.CallLlm = origCallLlm }()
-	apis.CallLlm = func(msgs []llms.MessageContent) (*string, error) {
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
 		return nil, errors.New("llm fail")
 	}
 
@@ -511,6 +664,45 @@ func TestHandleItineraryFileJob_LlmCallFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "llm fail")
 }
 
+func TestHandleItineraryFileJob_PromptTemplate(t *testing.T) {
+	it := &models.Itinerary{ID: 1, OwnerID: 2}
+	job := mockItineraryFileJob()
+	job.StartJob = func() error { return nil }
+	job.FailJob = func(desc string) error { return nil }
+
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	origBuildPrompt := buildItineraryLlmPrompt
+	defer func() { buildItineraryLlmPrompt = origBuildPrompt }()
+	var usedTemplate string
+	buildItineraryLlmPrompt = func(it *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
+		usedTemplate = promptTemplate
+		return &promptTemplate, nil
+	}
+
+	origCallLlm := apis.CallLlm
+	defer func() { apis.CallLlm = origCallLlm }()
+	var messages []llms.MessageContent
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		messages = msgs
+		return nil, errors.New("llm fail")
+	}
+
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:        it,
+		ItineraryFileJob: job,
+		PromptTemplate:   &models.PromptTemplate{ID: 5, SystemMessage: "You are a family travel agent.", Template: "Plan {{.title}}"},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.Error(t, err)
+	assert.Equal(t, "Plan {{.title}}", usedTemplate)
+	assert.Equal(t, llms.TextContent{Text: "You are a family travel agent."}, messages[0].Parts[0])
+}
+
 func TestHandleItineraryFileJob_WriteFileFails(t *testing.T) {
 	it := &models.Itinerary{ID: 1, OwnerID: 2}
 	job := mockItineraryFileJob()
@@ -527,14 +719,14 @@ func TestHandleItineraryFileJob_WriteFileFails(t *testing.T) {
 	origBuildPrompt := buildItineraryLlmPrompt
 	defer func() { buildItineraryLlmPrompt = origBuildPrompt }()
 	prompt := "prompt"
-	buildItineraryLlmPrompt = func(it *models.Itinerary) (*string, error) {
+	buildItineraryLlmPrompt = func(it *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
 		return &prompt, nil
 	}
 
 	origCallLlm := apis.CallLlm
 	defer func() { apis.CallLlm = origCallLlm }()
 	resp := "llm response"
-	apis.CallLlm = func(msgs []llms.MessageContent) (*string, error) {
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
 		return &resp, nil
 	}
 
@@ -573,14 +765,14 @@ func TestHandleItineraryFileJob_CompleteJobFails(t *testing.T) {
 	origBuildPrompt := buildItineraryLlmPrompt
 	defer func() { buildItineraryLlmPrompt = origBuildPrompt }()
 	prompt := "prompt"
-	buildItineraryLlmPrompt = func(it *models.Itinerary) (*string, error) {
+	buildItineraryLlmPrompt = func(it *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
 		return &prompt, nil
 	}
 
 	origCallLlm := apis.CallLlm
 	defer func() { apis.CallLlm = origCallLlm }()
 	resp := "llm response"
-	apis.CallLlm = func(msgs []llms.MessageContent) (*string, error) {
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
 		return &resp, nil
 	}
 
@@ -606,6 +798,7 @@ func TestHandleItineraryFileJob_CompleteJobFails(t *testing.T) {
 }
 
 func TestHandleItineraryFileJob_Success(t *testing.T) {
+	indexed := mockIndexItinerary(t)
 	it := &models.Itinerary{ID: 1, OwnerID: 2}
 	job := mockItineraryFileJob()
 	job.StartJob = func() error { return nil }
@@ -622,14 +815,14 @@ func TestHandleItineraryFileJob_Success(t *testing.T) {
 	origBuildPrompt := buildItineraryLlmPrompt
 	defer func() { buildItineraryLlmPrompt = origBuildPrompt }()
 	prompt := "prompt"
-	buildItineraryLlmPrompt = func(it *models.Itinerary) (*string, error) {
+	buildItineraryLlmPrompt = func(it *models.Itinerary, preferences *models.TravellerPreferences, promptTemplate string) (*string, error) {
 		return &prompt, nil
 	}
 
 	origCallLlm := apis.CallLlm
 	defer func() { apis.CallLlm = origCallLlm }()
 	resp := "llm response"
-	apis.CallLlm = func(msgs []llms.MessageContent) (*string, error) {
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
 		return &resp, nil
 	}
 
@@ -648,10 +841,144 @@ func TestHandleItineraryFileJob_Success(t *testing.T) {
 
 	err := HandleItineraryFileJob(context.TODO(), task)
 	assert.NoError(t, err)
+	assert.Equal(t, []int64{it.ID}, *indexed)
+}
+func TestHandleItineraryFileJob_Plan(t *testing.T) {
+	mockIndexItinerary(t)
+	it := &models.Itinerary{ID: 1, OwnerID: 2, Title: "Summer in Spain"}
+	job := mockItineraryFileJob()
+	job.FailJob = func(desc string) error {
+		t.Errorf("FailJob should not be called on success")
+		return nil
+	}
+	var statusDescription string
+	job.CompleteJob = func() error {
+		statusDescription = job.StatusDescription
+		return nil
+	}
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	origCallLlm := apis.CallLlm
+	defer func() { apis.CallLlm = origCallLlm }()
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		t.Errorf("The LLM should not be called for the plan")
+		return nil, errors.New("llm called")
+	}
+
+	origWriteLocalFile := utils.WriteLocalFile
+	defer func() { utils.WriteLocalFile = origWriteLocalFile }()
+	var content string
+	utils.WriteLocalFile = func(path string, data []byte, perm os.FileMode) error {
+		content = string(data)
+		return nil
+	}
+
+	activities := newPlanActivities()
+	activities[0].Location = "Calle de Ruiz de Alarcón 23"
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:        it,
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 1, ItineraryID: 1, Language: "es", Source: models.ItineraryFileJobSourcePlan},
+		Plan:             models.GroupActivitiesByDay(activities),
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.NoError(t, err)
+	assert.Equal(t, "Itinerary rendered from the plan", statusDescription)
+	assert.Equal(t, "Summer in Spain\n\n01/07/2024\n- Morning: Visit the Prado Museum (Calle de Ruiz de Alarcón 23)\n- Evening: Dinner at Botín\n"+
+		"\n02/07/2024\n- Morning: Walk in El Retiro\n", content)
+}
+
+func TestHandleItineraryFileJob_LlmPlan(t *testing.T) {
+	mockIndexItinerary(t)
+	stored := mockStoredActivities(t, []*models.ItineraryActivity{}, false)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	descriptions := mockSaveAuditEvent(t, nil)
+	it := newDestinationsItinerary()
+	it.Title = "Summer in Spain"
+	job := mockItineraryFileJob()
+	job.FailJob = func(desc string) error {
+		t.Errorf("FailJob should not be called on success")
+		return nil
+	}
+	var statusDescription string
+	job.CompleteJob = func() error {
+		statusDescription = job.StatusDescription
+		return nil
+	}
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	type ctxKey struct{}
+	origCallLlm := apis.CallLlm
+	defer func() { apis.CallLlm = origCallLlm }()
+	apis.CallLlm = func(ctx context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		assert.Equal(t, "job", ctx.Value(ctxKey{}))
+		response := `{"activities": [{"date": "2024-07-01", "timeSlot": "morning", "title": "Visit the Prado Museum"}]}`
+		return &response, nil
+	}
+
+	origWriteLocalFile := utils.WriteLocalFile
+	defer func() { utils.WriteLocalFile = origWriteLocalFile }()
+	var content string
+	utils.WriteLocalFile = func(path string, data []byte, perm os.FileMode) error {
+		content = string(data)
+		return nil
+	}
+
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:        it,
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 1, ItineraryID: 1, Language: "es", Source: models.ItineraryFileJobSourceLlmPlan},
+		ActorID:          2,
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.WithValue(context.TODO(), ctxKey{}, "job"), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.NoError(t, err)
+	assert.Len(t, stored.Replaced, 1)
+	assert.Equal(t, []string{"Plan of itinerary 1 generated."}, *descriptions)
+	assert.Equal(t, "Plan generated and itinerary rendered from it", statusDescription)
+	assert.Equal(t, "Summer in Spain\n\n01/07/2024\n- Morning: Visit the Prado Museum\n", content)
 }
+
+func TestHandleItineraryFileJob_LlmPlan_Fails(t *testing.T) {
+	stored := mockStoredActivities(t, []*models.ItineraryActivity{}, false)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	job := mockItineraryFileJob()
+	var failure string
+	job.FailJob = func(desc string) error {
+		failure = desc
+		return nil
+	}
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	origCallLlm := apis.CallLlm
+	defer func() { apis.CallLlm = origCallLlm }()
+	apis.CallLlm = func(ctx context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		return nil, context.DeadlineExceeded
+	}
+
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:        newDestinationsItinerary(),
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 1, ItineraryID: 1, Source: models.ItineraryFileJobSourceLlmPlan},
+		ActorID:          2,
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.EqualError(t, err, "failed to generate plan")
+	assert.Equal(t, "Failed to generate plan: failed to generate plan", failure)
+	assert.Nil(t, stored.Replaced)
+}
+
 func TestItineraryFileJobService_SoftDeleteJob_NilJob(t *testing.T) {
 	svc := &ItineraryFileJobService{}
-	err := svc.SoftDeleteJob(nil)
+	err := svc.SoftDeleteJob(nil, 2)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "itinerary file job instance is nil")
 }
@@ -660,17 +987,20 @@ func TestItineraryFileJobService_SoftDeleteJob_Fail(t *testing.T) {
 	ifj := mockItineraryFileJob()
 	ifj.SoftDeleteJob = func() error { return errors.New("fail soft delete") }
 
-	err := (&ItineraryFileJobService{}).SoftDeleteJob(ifj)
+	err := (&ItineraryFileJobService{}).SoftDeleteJob(ifj, 2)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to soft delete job")
 }
 
 func TestItineraryFileJobService_SoftDeleteJob_Success(t *testing.T) {
+	mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
 	ifj := mockItineraryFileJob()
 	ifj.SoftDeleteJob = func() error { return nil }
 
-	err := (&ItineraryFileJobService{}).SoftDeleteJob(ifj)
+	err := (&ItineraryFileJobService{}).SoftDeleteJob(ifj, 2)
 	assert.NoError(t, err)
+	assert.Equal(t, []string{"Itinerary file job 1 deleted."}, *descriptions)
 }
 
 func TestItineraryFileJobService_SoftDeleteJobsByItineraryId_InvalidID(t *testing.T) {
@@ -913,7 +1243,7 @@ func TestItineraryFileJobService_DeleteDeadJobs_AbsentFileSuccess(t *testing.T)
 }
 func TestItineraryFileJobService_OpenItineraryJobFile_NilJob(t *testing.T) {
 	svc := &ItineraryFileJobService{}
-	file, err := svc.OpenItineraryJobFile(nil)
+	file, err := svc.OpenItineraryJobFile(nil, 2)
 	assert.Nil(t, file)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "itinerary file job instance is nil")
@@ -922,7 +1252,7 @@ func TestItineraryFileJobService_OpenItineraryJobFile_NilJob(t *testing.T) {
 func TestItineraryFileJobService_OpenItineraryJobFile_EmptyFilepath(t *testing.T) {
 	svc := &ItineraryFileJobService{}
 	job := &models.ItineraryFileJob{Filepath: ""}
-	file, err := svc.OpenItineraryJobFile(job)
+	file, err := svc.OpenItineraryJobFile(job, 2)
 	assert.Nil(t, file)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "itinerary file job filepath is empty")
@@ -940,20 +1270,174 @@ func TestItineraryFileJobService_OpenItineraryJobFile_OpenFileFails(t *testing.T
 	mgr := &mockFileManager{openFileErr: errors.New("fail open")}
 	GetFileManager = func(name string) FileManagerInterface { return mgr }
 
-	file, err := svc.OpenItineraryJobFile(job)
+	file, err := svc.OpenItineraryJobFile(job, 2)
 	assert.Nil(t, file)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to open itinerary job file")
 }
 
 func TestItineraryFileJobService_OpenItineraryJobFile_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
 	svc := &ItineraryFileJobService{}
-	job := &models.ItineraryFileJob{Filepath: "some/path", FileManager: "mock"}
+	job := &models.ItineraryFileJob{ID: 3, Filepath: "some/path", FileManager: "mock"}
 	reader := &mockReadSeeker{}
 	mgr := &mockFileManager{returnReader: reader}
 	GetFileManager = func(name string) FileManagerInterface { return mgr }
 
-	file, err := svc.OpenItineraryJobFile(job)
+	file, err := svc.OpenItineraryJobFile(job, 2)
 	assert.NoError(t, err)
 	assert.Equal(t, reader, file)
+	assert.Equal(t, []string{"File of itinerary file job 3 downloaded."}, *descriptions)
+}
+
+func mockSaveAuditEvent(t *testing.T, err error) *[]string {
+	descriptions := []string{}
+	original := saveAuditEvent
+	saveAuditEvent = func(userId int64, eventType string, eventDescription string, details map[string]any) error {
+		descriptions = append(descriptions, eventDescription)
+		return err
+	}
+	t.Cleanup(func() { saveAuditEvent = original })
+	return &descriptions
+}
+
+func TestItineraryFileJobService_ForceStopJob_NilJob(t *testing.T) {
+	err := (&ItineraryFileJobService{}).ForceStopJob(nil, 1)
+	assert.Error(t, err)
+}
+
+func TestItineraryFileJobService_ForceStopJob_NotInProgress(t *testing.T) {
+	ifj := mockItineraryFileJob()
+	ifj.Status = "completed"
+
+	err := (&ItineraryFileJobService{}).ForceStopJob(ifj, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "only pending or running")
+}
+
+func TestItineraryFileJobService_ForceStopJob_StopFails(t *testing.T) {
+	ifj := mockItineraryFileJob()
+	ifj.Status = "running"
+	ifj.StopJob = func() error { return errors.New("fail") }
+
+	err := (&ItineraryFileJobService{}).ForceStopJob(ifj, 1)
+	assert.EqualError(t, err, "failed to stop job")
+}
+
+func TestItineraryFileJobService_ForceStopJob_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	ifj := mockItineraryFileJob()
+	ifj.Status = "running"
+	// No timeout check applies when an administrator stops the job
+	ifj.CreationDate = time.Now()
+
+	err := (&ItineraryFileJobService{}).ForceStopJob(ifj, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Itinerary file job 1 force-stopped."}, *descriptions)
+}
+
+func TestItineraryFileJobService_PurgeJob_InProgress(t *testing.T) {
+	ifj := mockItineraryFileJob()
+	ifj.Status = "pending"
+
+	err := (&ItineraryFileJobService{}).PurgeJob(ifj, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be stopped")
+}
+
+func TestItineraryFileJobService_PurgeJob_SoftDeleteFails(t *testing.T) {
+	ifj := mockItineraryFileJob()
+	ifj.Status = "completed"
+	ifj.SoftDeleteJob = func() error { return errors.New("fail") }
+
+	err := (&ItineraryFileJobService{}).PurgeJob(ifj, 1)
+	assert.Error(t, err)
+	assert.Equal(t, "completed", ifj.Status)
+}
+
+func TestItineraryFileJobService_PurgeJob_Success(t *testing.T) {
+	mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	ifj := mockItineraryFileJob()
+	ifj.Status = "failed"
+	ifj.SoftDeleteJob = func() error { return nil }
+	deleted := false
+	ifj.DeleteJob = func() error {
+		deleted = true
+		return nil
+	}
+	GetFileManager = func(name string) FileManagerInterface { return &mockFileManager{} }
+
+	err := (&ItineraryFileJobService{}).PurgeJob(ifj, 1)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, []string{"Itinerary file job 1 purged."}, *descriptions)
+}
+
+func TestBuildItineraryLlmPrompt_DefaultPreferences(t *testing.T) {
+	it := &models.Itinerary{ID: 1, Title: "Spain", TravelDestinations: []*models.ItineraryTravelDestination{{Country: "Spain", City: "Madrid"}}}
+
+	prompt, err := buildItineraryLlmPrompt(it, nil, itineraryPromptTemplate)
+	assert.NoError(t, err)
+	assert.Contains(t, *prompt, "City: Madrid")
+	assert.Contains(t, *prompt, "suitable for a traveler who enjoys cultural experiences, local cuisine, and sightseeing.")
+	assert.NotContains(t, *prompt, "Traveller profile")
+	assert.NotContains(t, *prompt, "Transport between destinations")
+	assert.NotContains(t, *prompt, "Accommodation:")
+	assert.NotContains(t, *prompt, "language")
+}
+
+func TestBuildItineraryLlmPrompt_WithPreferences(t *testing.T) {
+	adults, children, seniors := 2, 1, 0
+	preferences := &models.TravellerPreferences{Interests: []string{"museums", "street food"}, Pace: models.TravellerPaceRelaxed,
+		BudgetLevel: models.TravellerBudgetLow, DietaryRestrictions: []string{"vegetarian", "nut allergy"}, MobilityNeeds: "Wheelchair user",
+		Adults: &adults, Children: &children, Seniors: &seniors, Language: "es"}
+
+	it := &models.Itinerary{ID: 1, Title: "Spain", TravelDestinations: []*models.ItineraryTravelDestination{{Country: "Spain", City: "Madrid",
+		ArrivalDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), DepartureDate: time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)}}}
+
+	prompt, err := buildItineraryLlmPrompt(it, preferences, itineraryPromptTemplate)
+	assert.NoError(t, err)
+	assert.Contains(t, *prompt, "suitable for a traveler who enjoys museums, street food.")
+	assert.Contains(t, *prompt, "- Pace: relaxed, with few activities a day and plenty of free time\n")
+	assert.Contains(t, *prompt, "- Budget: budget, favouring free and cheap options\n")
+	assert.Contains(t, *prompt, "- Dietary restrictions: vegetarian, nut allergy\n")
+	assert.Contains(t, *prompt, "- Mobility needs: Wheelchair user\n")
+	assert.Contains(t, *prompt, "- Travelling party: 2 adults, 1 child\n")
+	assert.Contains(t, *prompt, "Arrival: 01/07/2024, Departure: 05/07/2024")
+	assert.Contains(t, *prompt, "Write the whole itinerary in Spanish (language code es), with dates, times, numbers and prices written as usual in that locale.")
+}
+
+func TestBuildItineraryLlmPrompt_TransportLegs(t *testing.T) {
+	departure := time.Date(2024, 7, 5, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	fromId, toId := int64(10), int64(11)
+	it := &models.Itinerary{ID: 1, Title: "Spain", TravelDestinations: []*models.ItineraryTravelDestination{
+		{ID: 10, Country: "Spain", City: "Madrid"}, {ID: 11, Country: "Spain", City: "Seville"}},
+		TransportLegs: []*models.ItineraryTransportLeg{
+			{FromDestinationID: &fromId, ToDestinationID: &toId, FromCity: "Madrid", ToCity: "Seville", Mode: models.TransportModeTrain,
+				Carrier: "Renfe", DepartureTime: &departure, Notes: "Seats 4A and 4B"},
+			{FromCity: "Seville", ToCity: "Lisbon", Mode: models.TransportModeBus},
+		}}
+
+	prompt, err := buildItineraryLlmPrompt(it, &models.TravellerPreferences{Language: "es"}, itineraryPromptTemplate)
+	assert.NoError(t, err)
+	assert.Contains(t, *prompt, "- From Madrid to Seville by train with Renfe, departing 05/07/2024 09:30 (Seats 4A and 4B)\n")
+	assert.NotContains(t, *prompt, "Lisbon")
+	assert.Contains(t, *prompt, "Plan the travel days around the transport between destinations.")
+}
+
+func TestBuildItineraryLlmPrompt_Accommodations(t *testing.T) {
+	destinationId := int64(10)
+	it := &models.Itinerary{ID: 1, Title: "Spain", TravelDestinations: []*models.ItineraryTravelDestination{{ID: 10, Country: "Spain", City: "Madrid"}},
+		Accommodations: []*models.DestinationAccommodation{
+			{DestinationID: &destinationId, City: "Madrid", Name: "Hotel Gran Vía", Address: "Gran Vía 25",
+				CheckIn: time.Date(2024, 7, 1, 15, 0, 0, 0, time.UTC), CheckOut: time.Date(2024, 7, 5, 11, 0, 0, 0, time.UTC)},
+			{City: "Madrid", Name: "Old Hostel"},
+		}}
+
+	prompt, err := buildItineraryLlmPrompt(it, nil, itineraryPromptTemplate)
+	assert.NoError(t, err)
+	assert.Contains(t, *prompt, "- In Madrid: Hotel Gran Vía, Gran Vía 25, check-in 2024-07-01 15:00, check-out 2024-07-05 11:00\n")
+	assert.NotContains(t, *prompt, "Old Hostel")
+	assert.Contains(t, *prompt, "Start and end each day at the accommodation and favour activities and restaurants near it.")
 }
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type ItineraryImportServiceInterface interface {
+	Preview(format string, data []byte, title string) (*models.ItineraryImport, error)
+	Create(itineraryImport *models.ItineraryImport, ownerId int64) (*models.Itinerary, error)
+}
+
+type ItineraryImportService struct{}
+
+// defaultImportTitle is the title of the imported itineraries whose file has none, like a CSV file
+const defaultImportTitle = "Imported itinerary"
+
+// singleton instance
+var itineraryImportServiceInstance = &ItineraryImportService{}
+
+// GetItineraryImportService returns the singleton instance of ItineraryImportService
+var GetItineraryImportService = func() ItineraryImportServiceInterface {
+	return itineraryImportServiceInstance
+}
+
+// Preview reads the destinations of an iCalendar, CSV or JSON interchange file and validates them like the ones of a new itinerary,
+// without creating it. The title takes precedence over the one of the file. Errors of single destinations are reported in their rows;
+// an error is only returned if the file cannot be read at all
+func (iis *ItineraryImportService) Preview(format string, data []byte, title string) (*models.ItineraryImport, error) {
+	itineraryImport := &models.ItineraryImport{Format: format, Rows: []*models.ItineraryImportRow{}, Errors: []string{}}
+
+	var err error
+	switch format {
+	case models.ItineraryImportFormatICS:
+		err = parseICSImport(data, itineraryImport)
+	case models.ItineraryImportFormatCSV:
+		err = parseCSVImport(data, itineraryImport)
+	case models.ItineraryImportFormatJSON:
+		err = parseJSONImport(data, itineraryImport)
+	default:
+		return nil, fmt.Errorf("invalid import: unsupported format %s", format)
+	}
+	if err != nil {
+		log.Errorf("Error reading %s import: %v", format, err)
+		return nil, fmt.Errorf("invalid import: %w", err)
+	}
+
+	if len(itineraryImport.Rows) == 0 {
+		return nil, errors.New("invalid import: the file has no destinations")
+	}
+
+	if title != "" {
+		itineraryImport.Title = title
+	}
+	if itineraryImport.Title == "" {
+		itineraryImport.Title = defaultImportTitle
+	}
+
+	validateItineraryImport(itineraryImport)
+	return itineraryImport, nil
+}
+
+// Create creates the itinerary of a valid import for its owner, recording it in the audit log
+func (iis *ItineraryImportService) Create(itineraryImport *models.ItineraryImport, ownerId int64) (*models.Itinerary, error) {
+	if itineraryImport == nil {
+		log.Error("Itinerary import instance is nil")
+		return nil, errors.New("itinerary import instance is nil")
+	}
+	if !itineraryImport.Valid {
+		return nil, errors.New("invalid import: the file has errors")
+	}
+
+	itinerary := models.NewItinerary(itineraryImport.Title, itineraryImport.Description, itineraryImport.Notes,
+		itineraryImport.TravelDestinations())
+	itinerary.OwnerID = ownerId
+
+	err := itinerary.Create()
+	if err != nil {
+		log.Errorf("Error creating itinerary from %s import: %v", itineraryImport.Format, err)
+		return nil, errors.New("failed to create itinerary")
+	}
+
+	indexItinerary(itinerary.ID)
+	err = saveAuditEvent(ownerId, models.AuditEventItineraryCreated,
+		fmt.Sprintf("Itinerary %d imported from a %s file.", itinerary.ID, itineraryImport.Format),
+		map[string]any{"itineraryId": itinerary.ID, "title": itinerary.Title, "importFormat": itineraryImport.Format})
+	if err != nil {
+		return nil, err
+	}
+
+	return itinerary, nil
+}
+
+// validateItineraryImport checks every row and then the destinations as a whole with the rules of the itinerary service, so an import
+// is only valid when the itinerary could be created by hand
+func validateItineraryImport(itineraryImport *models.ItineraryImport) {
+	if utf8.RuneCountInString(itineraryImport.Title) > 128 {
+		itineraryImport.Errors = append(itineraryImport.Errors, "the title cannot be longer than 128 characters")
+	}
+	if utf8.RuneCountInString(itineraryImport.Description) > 512 {
+		itineraryImport.Errors = append(itineraryImport.Errors, "the description cannot be longer than 512 characters")
+	}
+	if itineraryImport.Notes != nil && utf8.RuneCountInString(*itineraryImport.Notes) > 512 {
+		itineraryImport.Errors = append(itineraryImport.Errors, "the notes cannot be longer than 512 characters")
+	}
+
+	rowsValid := true
+	for _, row := range itineraryImport.Rows {
+		row.Errors = append(row.Errors, validateItineraryImportRow(row)...)
+		if len(row.Errors) > 0 {
+			rowsValid = false
+		}
+	}
+
+	// The dates of the whole trip can only be checked once every destination has its own
+	if rowsValid {
+		err := GetItineraryService().ValidateItineraryDestinationsDates(itineraryImport.TravelDestinations())
+		if err != nil {
+			itineraryImport.Errors = append(itineraryImport.Errors, err.Error())
+		}
+	}
+
+	itineraryImport.Valid = rowsValid && len(itineraryImport.Errors) == 0
+}
+
+func validateItineraryImportRow(row *models.ItineraryImportRow) []string {
+	rowErrors := []string{}
+	for _, field := range []struct{ name, value string }{{"city", row.City}, {"country", row.Country}} {
+		if field.value == "" {
+			rowErrors = append(rowErrors, fmt.Sprintf("the %s is required", field.name))
+		} else if utf8.RuneCountInString(field.value) > 128 {
+			rowErrors = append(rowErrors, fmt.Sprintf("the %s cannot be longer than 128 characters", field.name))
+		}
+	}
+
+	if row.ArrivalDate != nil && row.DepartureDate != nil && row.DepartureDate.Before(*row.ArrivalDate) {
+		rowErrors = append(rowErrors, "the departure date is older than the arrival date")
+	}
+
+	return rowErrors
+}
+
+// importDateLayouts are the layouts the dates of CSV and JSON files can have. Dates without an offset are taken as UTC
+var importDateLayouts = []string{time.RFC3339, time.DateOnly, "2006-01-02T15:04:05", time.DateTime, "2006-01-02 15:04"}
+
+// parseImportDate reads a date of a row, adding an error to the row if it is missing or cannot be read
+func parseImportDate(row *models.ItineraryImportRow, name string, value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		row.Errors = append(row.Errors, fmt.Sprintf("the %s date is required", name))
+		return nil
+	}
+
+	for _, layout := range importDateLayouts {
+		date, err := time.Parse(layout, value)
+		if err == nil {
+			return &date
+		}
+	}
+
+	row.Errors = append(row.Errors, fmt.Sprintf("the %s date %q is not a date like 2024-07-01 or 2024-07-01T10:00:00Z", name, value))
+	return nil
+}
+
+// csvImportColumns maps the accepted names of the columns of a CSV file, without case, to the attribute they have
+var csvImportColumns = map[string]string{
+	"city": "city", "country": "country",
+	"arrival": "arrival", "arrivaldate": "arrival", "arrival_date": "arrival", "arrival date": "arrival",
+	"departure": "departure", "departuredate": "departure", "departure_date": "departure", "departure date": "departure",
+}
+
+// parseCSVImport reads a CSV file with a header row naming the city, country, arrival and departure columns, in any order. Other
+// columns are ignored
+func parseCSVImport(data []byte, itineraryImport *models.ItineraryImport) error {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("the file has no destinations")
+		}
+		return fmt.Errorf("could not read the CSV file: %w", err)
+	}
+
+	columns := map[string]int{}
+	for idx, name := range header {
+		if attribute, ok := csvImportColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[attribute] = idx
+		}
+	}
+	for _, attribute := range []string{"city", "country", "arrival", "departure"} {
+		if _, ok := columns[attribute]; !ok {
+			return errors.New("the CSV file must have a header row with the city, country, arrival and departure columns")
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("could not read the CSV file: %w", err)
+		}
+
+		value := func(attribute string) string {
+			if columns[attribute] < len(record) {
+				return strings.TrimSpace(record[columns[attribute]])
+			}
+			return ""
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &models.ItineraryImportRow{Row: line, City: value("city"), Country: value("country"), Errors: []string{}}
+		row.ArrivalDate = parseImportDate(row, "arrival", value("arrival"))
+		row.DepartureDate = parseImportDate(row, "departure", value("departure"))
+		itineraryImport.Rows = append(itineraryImport.Rows, row)
+	}
+
+	return nil
+}
+
+// parseJSONImport reads a file of the JSON interchange format. Unknown attributes are ignored
+func parseJSONImport(data []byte, itineraryImport *models.ItineraryImport) error {
+	var document models.ItineraryInterchangeDocument
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return errors.New("the file is not a JSON document of the interchange format")
+	}
+
+	itineraryImport.Title = strings.TrimSpace(document.Trip.DisplayName)
+	itineraryImport.Description = strings.TrimSpace(document.Trip.Description)
+	itineraryImport.Notes = document.Trip.Notes
+
+	for idx, destination := range document.Destinations {
+		row := &models.ItineraryImportRow{Row: idx + 1, Errors: []string{}}
+		if destination == nil {
+			row.Errors = append(row.Errors, "the destination is empty")
+			itineraryImport.Rows = append(itineraryImport.Rows, row)
+			continue
+		}
+
+		row.City = strings.TrimSpace(destination.City)
+		row.Country = strings.TrimSpace(destination.Country)
+		row.ArrivalDate = parseImportDate(row, "arrival", destination.StartDate)
+		row.DepartureDate = parseImportDate(row, "departure", destination.EndDate)
+		itineraryImport.Rows = append(itineraryImport.Rows, row)
+	}
+
+	return nil
+}
+
+// icsProperty is a content line of an iCalendar file, like DTSTART;TZID=Europe/Madrid:20240701T100000
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICSImport reads the events of an iCalendar file (RFC 5545) as destinations. The location of each event, or its summary if it has
+// none, names the destination as "City, Country"; only the last two parts are used, so full addresses work too. The start and end of
+// the event are the arrival and departure dates. The name and description of the calendar are taken as the ones of the itinerary
+func parseICSImport(data []byte, itineraryImport *models.ItineraryImport) error {
+	properties := unfoldICSLines(string(data))
+	if len(properties) == 0 || properties[0].name != "BEGIN" || !strings.EqualFold(properties[0].value, "VCALENDAR") {
+		return errors.New("the file is not an iCalendar file")
+	}
+
+	components := []string{}
+	var event map[string]*icsProperty
+	for _, property := range properties {
+		switch property.name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(property.value))
+			if len(components) == 2 && components[1] == "VEVENT" {
+				event = map[string]*icsProperty{}
+			}
+			continue
+		case "END":
+			if len(components) == 2 && components[1] == "VEVENT" {
+				itineraryImport.Rows = append(itineraryImport.Rows, icsEventRow(event, len(itineraryImport.Rows)+1))
+				event = nil
+			}
+			if len(components) > 0 {
+				components = components[:len(components)-1]
+			}
+			continue
+		}
+
+		// Properties of nested components, like the alarms of an event, are ignored
+		if len(components) == 1 {
+			switch property.name {
+			case "X-WR-CALNAME":
+				itineraryImport.Title = strings.TrimSpace(unescapeICSText(property.value))
+			case "X-WR-CALDESC":
+				itineraryImport.Description = strings.TrimSpace(unescapeICSText(property.value))
+			}
+		} else if len(components) == 2 && event != nil {
+			event[property.name] = property
+		}
+	}
+
+	return nil
+}
+
+// unfoldICSLines joins the folded content lines of an iCalendar file, which go on in the next lines starting with a space or a tab,
+// and splits them into their name, parameters and value
+func unfoldICSLines(content string) []*icsProperty {
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimPrefix(line, "\ufeff"))
+		}
+	}
+
+	properties := []*icsProperty{}
+	for _, line := range lines {
+		nameAndParams, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		parts := strings.Split(nameAndParams, ";")
+		property := &icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: value}
+		for _, param := range parts[1:] {
+			key, paramValue, _ := strings.Cut(param, "=")
+			property.params[strings.ToUpper(key)] = strings.Trim(paramValue, `"`)
+		}
+		properties = append(properties, property)
+	}
+
+	return properties
+}
+
+func icsEventRow(event map[string]*icsProperty, number int) *models.ItineraryImportRow {
+	row := &models.ItineraryImportRow{Row: number, Errors: []string{}}
+
+	location := event["LOCATION"]
+	if location == nil || strings.TrimSpace(location.value) == "" {
+		location = event["SUMMARY"]
+	}
+	if location != nil {
+		parts := strings.Split(unescapeICSText(location.value), ",")
+		row.Country = strings.TrimSpace(parts[len(parts)-1])
+		if len(parts) > 1 {
+			row.City = strings.TrimSpace(parts[len(parts)-2])
+		}
+	}
+
+	if event["DTSTART"] == nil {
+		row.Errors = append(row.Errors, "the event has no start")
+		return row
+	}
+	start, allDay, err := parseICSDate(event["DTSTART"])
+	if err != nil {
+		row.Errors = append(row.Errors, fmt.Sprintf("the start of the event %q is not a date", event["DTSTART"].value))
+		return row
+	}
+	row.ArrivalDate = &start
+
+	// An event without an end lasts its whole day if it has no time, and has no duration otherwise
+	end := start
+	if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if event["DTEND"] != nil {
+		end, _, err = parseICSDate(event["DTEND"])
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("the end of the event %q is not a date", event["DTEND"].value))
+			return row
+		}
+	}
+	row.DepartureDate = &end
+
+	return row
+}
+
+// parseICSDate reads a DATE or DATE-TIME value, in UTC, in the time zone of its TZID parameter or else as UTC. Returns whether it is a
+// date without a time
+func parseICSDate(property *icsProperty) (time.Time, bool, error) {
+	value := strings.TrimSpace(property.value)
+	if property.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		date, err := time.Parse("20060102", value)
+		return date, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		date, err := time.Parse("20060102T150405Z", value)
+		return date, false, err
+	}
+
+	location := time.UTC
+	if tzid := property.params["TZID"]; tzid != "" {
+		if tzLocation, err := time.LoadLocation(tzid); err == nil {
+			location = tzLocation
+		} else {
+			log.Warnf("Unknown time zone %s of imported event, using UTC", tzid)
+		}
+	}
+	date, err := time.ParseInLocation("20060102T150405", value, location)
+	return date, false, err
+}
+
+// unescapeICSText undoes the escaping of the TEXT values of an iCalendar file
+func unescapeICSText(value string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(value)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const icsImport = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-CALNAME:Summer in Spain\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Madrid\r\n" +
+	"LOCATION:Hotel Central\\, Gran Via 1\\, Madrid\\, Spain\r\n" +
+	"DTSTART;VALUE=DATE:20240701\r\n" +
+	"DTEND;VALUE=DATE:20240705\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"LOCATION:Nowhere\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Barcelona\\, \r\n" +
+	" Spain\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20240705T100000\r\n" +
+	"DTEND:20240709T080000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestItineraryImportService_Preview_ICS(t *testing.T) {
+	itineraryImport, err := GetItineraryImportService().Preview(models.ItineraryImportFormatICS, []byte(icsImport), "")
+	assert.NoError(t, err)
+	assert.True(t, itineraryImport.Valid)
+	assert.Equal(t, "Summer in Spain", itineraryImport.Title)
+	assert.Len(t, itineraryImport.Rows, 2)
+
+	madrid := itineraryImport.Rows[0]
+	assert.Equal(t, 1, madrid.Row)
+	assert.Equal(t, "Madrid", madrid.City)
+	assert.Equal(t, "Spain", madrid.Country)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), *madrid.ArrivalDate)
+	assert.Equal(t, time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC), *madrid.DepartureDate)
+
+	barcelona := itineraryImport.Rows[1]
+	assert.Equal(t, "Barcelona", barcelona.City)
+	assert.Equal(t, time.Date(2024, 7, 5, 8, 0, 0, 0, time.UTC), barcelona.ArrivalDate.UTC())
+	assert.Empty(t, barcelona.Errors)
+}
+
+func TestItineraryImportService_Preview_ICSNotACalendar(t *testing.T) {
+	_, err := GetItineraryImportService().Preview(models.ItineraryImportFormatICS, []byte("city,country"), "")
+	assert.EqualError(t, err, "invalid import: the file is not an iCalendar file")
+}
+
+func TestItineraryImportService_Preview_CSVRowErrors(t *testing.T) {
+	csvImport := "\ufeffCountry,City,Arrival Date,Departure Date,Notes\n" +
+		"Spain,Madrid,2024-07-01,2024-07-05,First stop\n" +
+		"\n" +
+		"Spain,,2024-07-05,2024-07-03\n" +
+		"Spain,Seville,July 9,2024-07-12T10:00:00Z\n"
+
+	itineraryImport, err := GetItineraryImportService().Preview(models.ItineraryImportFormatCSV, []byte(csvImport), "Spain")
+	assert.NoError(t, err)
+	assert.False(t, itineraryImport.Valid)
+	assert.Equal(t, "Spain", itineraryImport.Title)
+	assert.Empty(t, itineraryImport.Errors)
+	assert.Len(t, itineraryImport.Rows, 3)
+
+	assert.Equal(t, 2, itineraryImport.Rows[0].Row)
+	assert.Empty(t, itineraryImport.Rows[0].Errors)
+
+	assert.Equal(t, 4, itineraryImport.Rows[1].Row)
+	assert.Equal(t, []string{"the city is required", "the departure date is older than the arrival date"}, itineraryImport.Rows[1].Errors)
+
+	assert.Equal(t, 5, itineraryImport.Rows[2].Row)
+	assert.Nil(t, itineraryImport.Rows[2].ArrivalDate)
+	assert.Len(t, itineraryImport.Rows[2].Errors, 1)
+	assert.Contains(t, itineraryImport.Rows[2].Errors[0], `the arrival date "July 9" is not a date`)
+}
+
+func TestItineraryImportService_Preview_CSVMissingColumns(t *testing.T) {
+	_, err := GetItineraryImportService().Preview(models.ItineraryImportFormatCSV, []byte("city,country,arrival\nMadrid,Spain,2024-07-01\n"), "")
+	assert.ErrorContains(t, err, "invalid import: the CSV file must have a header row")
+
+	_, err = GetItineraryImportService().Preview(models.ItineraryImportFormatCSV, []byte("city,country,arrival,departure\n"), "")
+	assert.EqualError(t, err, "invalid import: the file has no destinations")
+}
+
+func TestItineraryImportService_Preview_JSONTripTooLong(t *testing.T) {
+	jsonImport := `{"trip":{"display_name":"Around the world","notes":"Pack light"},"destinations":[
+		{"city":"Tokyo","country":"Japan","start_date":"2024-07-01","end_date":"2024-07-20"},
+		{"city":"Lima","country":"Peru","start_date":"2024-07-20","end_date":"2024-08-10"}]}`
+
+	itineraryImport, err := GetItineraryImportService().Preview(models.ItineraryImportFormatJSON, []byte(jsonImport), "")
+	assert.NoError(t, err)
+	assert.False(t, itineraryImport.Valid)
+	assert.Equal(t, "Around the world", itineraryImport.Title)
+	assert.Equal(t, "Pack light", *itineraryImport.Notes)
+	assert.Empty(t, itineraryImport.Rows[0].Errors)
+	assert.Empty(t, itineraryImport.Rows[1].Errors)
+	assert.Equal(t, []string{"the itinerary cannot span more than 30 days"}, itineraryImport.Errors)
+}
+
+func TestItineraryImportService_Preview_InvalidJSON(t *testing.T) {
+	_, err := GetItineraryImportService().Preview(models.ItineraryImportFormatJSON, []byte(`{"trip":`), "")
+	assert.EqualError(t, err, "invalid import: the file is not a JSON document of the interchange format")
+}
+
+func TestItineraryImportService_Preview_TitleTooLong(t *testing.T) {
+	csvImport := "city,country,arrival,departure\nMadrid,Spain,2024-07-01,2024-07-05\n"
+	itineraryImport, err := GetItineraryImportService().Preview(models.ItineraryImportFormatCSV, []byte(csvImport), strings.Repeat("a", 129))
+	assert.NoError(t, err)
+	assert.False(t, itineraryImport.Valid)
+	assert.Equal(t, []string{"the title cannot be longer than 128 characters"}, itineraryImport.Errors)
+}
+
+func TestItineraryImportService_Create_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	indexed := mockIndexItinerary(t)
+	created, _ := mockCreatedItineraries(t)
+	service := GetItineraryImportService()
+
+	csvImport := "city,country,arrival,departure\nMadrid,Spain,2024-07-01,2024-07-05\n"
+	itineraryImport, err := service.Preview(models.ItineraryImportFormatCSV, []byte(csvImport), "")
+	assert.NoError(t, err)
+
+	itinerary, err := service.Create(itineraryImport, 3)
+	assert.NoError(t, err)
+	assert.Len(t, *created, 1)
+	assert.Equal(t, int64(3), itinerary.OwnerID)
+	assert.Equal(t, "Imported itinerary", itinerary.Title)
+	assert.Len(t, itinerary.TravelDestinations, 1)
+	assert.Equal(t, "Madrid", itinerary.TravelDestinations[0].City)
+	assert.Len(t, *indexed, 1)
+	assert.Equal(t, []string{"Itinerary 10 imported from a csv file."}, *descriptions)
+}
+
+func TestItineraryImportService_Create_Invalid(t *testing.T) {
+	created, _ := mockCreatedItineraries(t)
+
+	_, err := GetItineraryImportService().Create(&models.ItineraryImport{Format: models.ItineraryImportFormatCSV}, 3)
+	assert.EqualError(t, err, "invalid import: the file has errors")
+	assert.Empty(t, *created)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+)
+
+// conversationSystemMessage is the system message of the prompt the itineraries are refined with in the conversations about them
+const conversationSystemMessage = "You are a helpful expert and guide of international travel. You only answer with JSON."
+
+// conversationPromptTemplate is the prompt the itineraries are refined with. It has the current version of the itinerary and the
+// conversation so far, whose last message is the one to answer
+const conversationPromptTemplate = `This is the current version of a travel itinerary:
+{{.itinerary}}
+
+This is the conversation of the travellers with you about it:
+{{range .conversation}}{{.}}
+{{end}}
+Change the itinerary as the travellers ask in their last message, keeping the rest of it as it is{{if .languageName}} and writing it
+in {{.languageName}}{{end}}.
+{{if .travellerProfile}}
+Traveller profile:
+{{range .travellerProfile}}- {{.}}
+{{end}}{{end}}
+Answer only with a JSON object like {"reply": "...", "itinerary": "..."}, with a short reply to the travellers about what you changed
+and the whole new version of the itinerary.`
+
+// generatedConversationAnswer is the answer the itineraries are refined with
+type generatedConversationAnswer struct {
+	Reply     string `json:"reply"`
+	Itinerary string `json:"itinerary"`
+}
+
+// FindMessages retrieves the conversation about the file of a job, oldest message first
+func (ifjs *ItineraryFileJobService) FindMessages(job *models.ItineraryFileJob) ([]*models.ItineraryJobMessage, error) {
+	if job == nil {
+		log.Error("itinerary file job instance is nil")
+		return nil, errors.New("itinerary file job instance is nil")
+	}
+	return models.InitItineraryJobMessage().FindByJobId(job.ID)
+}
+
+// SendMessage adds a message of the user to the conversation about the file of a completed job of an itinerary retrieved with its
+// destinations, and prepares the job that answers it with a new version of the file, recording the user who sent it in the audit log.
+// The new version is made from the latest completed one of the conversation, or the file of the job if there is none yet, in its
+// language. The previous message must have been answered before sending a new one
+func (ifjs *ItineraryFileJobService) SendMessage(itinerary *models.Itinerary, job *models.ItineraryFileJob, content string, actorId int64) (*ItineraryFileAsyncTaskPayload, error) {
+	if itinerary == nil || job == nil {
+		log.Error("itinerary or itinerary file job instance is nil")
+		return nil, errors.New("itinerary or itinerary file job instance is nil")
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("invalid message: the message cannot be empty")
+	}
+	if job.Status != "completed" || job.Filepath == "" {
+		return nil, errors.New("invalid base job: only the files of completed jobs can be refined")
+	}
+
+	messages, err := models.InitItineraryJobMessage().FindByJobId(job.ID)
+	if err != nil {
+		log.Errorf("failed to retrieve messages of job %d: %v", job.ID, err)
+		return nil, errors.New("failed to prepare job")
+	}
+
+	baseJob, err := findLatestConversationVersion(job, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := GetTravellerPreferencesService().FindEffective(itinerary)
+	if err != nil {
+		log.Errorf("failed to retrieve traveller preferences: %v", err)
+		return nil, errors.New("failed to prepare job")
+	}
+	// The new version is written in the language of the version it is made from
+	preferences = preferences.Merge(nil)
+	preferences.Language = baseJob.Language
+
+	outputJob := models.InitItineraryFileJob()
+	outputJob.Language = baseJob.Language
+	outputJob.Source = models.ItineraryFileJobSourceLlm
+	outputJob.BaseJobID = &baseJob.ID
+	err = outputJob.PrepareJob(itinerary)
+	if err != nil {
+		log.Errorf("failed to prepare job: %v", err)
+		return nil, errors.New("failed to prepare job")
+	}
+
+	message := models.InitItineraryJobMessage()
+	message.JobID = job.ID
+	message.ItineraryID = itinerary.ID
+	message.Role = models.JobMessageRoleUser
+	message.Content = content
+	message.OutputJobID = &outputJob.ID
+	err = message.Create()
+	if err != nil {
+		log.Errorf("failed to save message about job %d: %v", job.ID, err)
+		outputJob.FailJob("Could not save the message it answers")
+		return nil, errors.New("failed to save message")
+	}
+
+	err = saveAuditEvent(actorId, models.AuditEventJobStarted,
+		fmt.Sprintf("Itinerary file job %d started to answer message %d about job %d.", outputJob.ID, message.ID, job.ID),
+		map[string]any{"itineraryId": itinerary.ID, "itineraryJobId": outputJob.ID, "baseJobId": baseJob.ID, "conversationJobId": job.ID,
+			"messageId": message.ID})
+	if err != nil {
+		outputJob.FailJob("Could not record the start of the job")
+		return nil, err
+	}
+
+	return &ItineraryFileAsyncTaskPayload{
+		Itinerary:            itinerary,
+		ItineraryFileJob:     outputJob,
+		TravellerPreferences: preferences,
+		BaseJob:              baseJob,
+		Conversation:         append(messages, message),
+	}, nil
+}
+
+// findLatestConversationVersion returns the job with the latest completed version of the file in the conversation, which is the job of
+// the conversation if none of the messages has been answered. Failed, stopped and deleted answers are skipped
+func findLatestConversationVersion(job *models.ItineraryFileJob, messages []*models.ItineraryJobMessage) (*models.ItineraryFileJob, error) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		message := messages[i]
+		if message.Role != models.JobMessageRoleUser || message.OutputJobID == nil {
+			continue
+		}
+
+		outputJob, err := models.InitItineraryFileJob().FindAliveById(*message.OutputJobID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			log.Errorf("failed to retrieve job %d of the conversation about job %d: %v", *message.OutputJobID, job.ID, err)
+			return nil, errors.New("failed to prepare job")
+		}
+		switch outputJob.Status {
+		case "completed":
+			return outputJob, nil
+		case "pending", "running":
+			return nil, errors.New("invalid message: the previous message is still being answered")
+		}
+	}
+	return job, nil
+}
+
+// refineItineraryFile writes the new version of the file of the base job of the task that answers the last message of its conversation
+// with the LLM, and returns it with the reply to the user, failing the job on errors
+func refineItineraryFile(ctx context.Context, task *ItineraryFileAsyncTaskPayload, job *models.ItineraryFileJob) (*string, *string, error) {
+	base, err := readJobFile(task.BaseJob)
+	if err != nil {
+		job.FailJob("Failed to read the file of the base job: " + err.Error())
+		return nil, nil, err
+	}
+
+	conversation := make([]string, len(task.Conversation))
+	for i, message := range task.Conversation {
+		speaker := "Travellers"
+		if message.Role == models.JobMessageRoleAssistant {
+			speaker = "You"
+		}
+		conversation[i] = speaker + ": " + message.Content
+	}
+
+	preferences := task.TravellerPreferences
+	if preferences == nil {
+		preferences = &models.TravellerPreferences{}
+	}
+	languageName := ""
+	if preferences.Language != "" {
+		languageName = utils.LanguageName(preferences.Language)
+	}
+
+	prompt, err := prompts.NewPromptTemplate(conversationPromptTemplate,
+		[]string{"itinerary", "conversation", "languageName", "travellerProfile"}).
+		Format(map[string]any{"itinerary": *base,
+			"conversation":     conversation,
+			"languageName":     languageName,
+			"travellerProfile": describeTravellerProfile(preferences)})
+	if err != nil {
+		log.Errorf("failed to format conversation prompt: %v", err)
+		job.FailJob("Failed to build conversation prompt: " + err.Error())
+		return nil, nil, err
+	}
+
+	response, err := apis.CallLlm(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, conversationSystemMessage),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	})
+	if err != nil || response == nil {
+		if err == nil {
+			err = errors.New("empty answer")
+		}
+		log.Errorf("failed to call LLM: %v", err)
+		job.FailJob("Failed to refine itinerary: " + err.Error())
+		return nil, nil, err
+	}
+
+	answer := &generatedConversationAnswer{}
+	err = parseLlmJsonObject(*response, answer)
+	if err == nil && strings.TrimSpace(answer.Itinerary) == "" {
+		err = errors.New("the answer has no itinerary")
+	}
+	if err != nil {
+		log.Errorf("failed to parse answer to the conversation about job %d: %v", task.BaseJob.ID, err)
+		job.FailJob("Failed to refine itinerary: " + err.Error())
+		return nil, nil, err
+	}
+
+	return &answer.Itinerary, &answer.Reply, nil
+}
+
+// saveConversationReply adds the reply of the assistant with the new version of the file of the job to the conversation of the task. The
+// version is kept even if the reply cannot be saved
+func saveConversationReply(task *ItineraryFileAsyncTaskPayload, job *models.ItineraryFileJob, reply string) {
+	userMessage := task.Conversation[len(task.Conversation)-1]
+
+	message := models.InitItineraryJobMessage()
+	message.JobID = userMessage.JobID
+	message.ItineraryID = userMessage.ItineraryID
+	message.Role = models.JobMessageRoleAssistant
+	message.Content = reply
+	message.OutputJobID = &job.ID
+	err := message.Create()
+	if err != nil {
+		log.Warnf("Could not save the reply of job %d to the conversation about job %d: %v", job.ID, userMessage.JobID, err)
+	}
+}
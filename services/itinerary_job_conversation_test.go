@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// mockStoredJobMessages makes the conversation about every job the messages, and returns the messages created through the mocked
+// functions
+func mockStoredJobMessages(t *testing.T, messages []*models.ItineraryJobMessage) *[]*models.ItineraryJobMessage {
+	created := &[]*models.ItineraryJobMessage{}
+	orig := models.InitItineraryJobMessage
+	models.InitItineraryJobMessage = func() *models.ItineraryJobMessage {
+		message := &models.ItineraryJobMessage{}
+		message.FindByJobId = func(jobId int64) ([]*models.ItineraryJobMessage, error) { return messages, nil }
+		message.FindByItineraryId = func(itineraryId int64) ([]*models.ItineraryJobMessage, error) { return messages, nil }
+		message.Create = func() error {
+			message.ID = int64(len(messages) + len(*created) + 1)
+			*created = append(*created, message)
+			return nil
+		}
+		return message
+	}
+	t.Cleanup(func() { models.InitItineraryJobMessage = orig })
+	return created
+}
+
+// mockConversationJobs makes the jobs the versions of the conversation, by ID, and the new job prepared for the answer have ID 9
+func mockConversationJobs(t *testing.T, jobs map[int64]*models.ItineraryFileJob) {
+	orig := models.InitItineraryFileJob
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		job := mockItineraryFileJob()
+		job.ID = 9
+		job.FindAliveById = func(id int64) (*models.ItineraryFileJob, error) {
+			if found, ok := jobs[id]; ok {
+				return found, nil
+			}
+			return nil, sql.ErrNoRows
+		}
+		return job
+	}
+	t.Cleanup(func() { models.InitItineraryFileJob = orig })
+}
+
+func int64Pointer(value int64) *int64 {
+	return &value
+}
+
+func TestItineraryFileJobSendMessage_FirstMessage(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{Language: "pt-BR"}, nil)
+	mockConversationJobs(t, map[int64]*models.ItineraryFileJob{})
+	created := mockStoredJobMessages(t, []*models.ItineraryJobMessage{})
+
+	payload, err := (&ItineraryFileJobService{}).SendMessage(newDestinationsItinerary(), newBaseJob(), " Make the third day more relaxed ", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), payload.BaseJob.ID)
+	assert.Equal(t, int64(4), *payload.ItineraryFileJob.BaseJobID)
+	assert.Equal(t, "es", payload.ItineraryFileJob.Language)
+	assert.Equal(t, "es", payload.TravellerPreferences.Language)
+	assert.Len(t, *created, 1)
+	message := (*created)[0]
+	assert.Equal(t, int64(4), message.JobID)
+	assert.Equal(t, int64(1), message.ItineraryID)
+	assert.Equal(t, models.JobMessageRoleUser, message.Role)
+	assert.Equal(t, "Make the third day more relaxed", message.Content)
+	assert.Equal(t, int64(9), *message.OutputJobID)
+	assert.Equal(t, []*models.ItineraryJobMessage{message}, payload.Conversation)
+	assert.Equal(t, []string{"Itinerary file job 9 started to answer message 1 about job 4."}, *descriptions)
+}
+
+func TestItineraryFileJobSendMessage_LatestVersion(t *testing.T) {
+	mockSaveAuditEvent(t, nil)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	mockConversationJobs(t, map[int64]*models.ItineraryFileJob{
+		6: {ID: 6, ItineraryID: 1, Status: "completed", Filepath: "6.txt", Language: "es"},
+		7: {ID: 7, ItineraryID: 1, Status: "failed"},
+	})
+	mockStoredJobMessages(t, []*models.ItineraryJobMessage{
+		{ID: 1, JobID: 4, Role: models.JobMessageRoleUser, Content: "Make the third day more relaxed", OutputJobID: int64Pointer(6)},
+		{ID: 2, JobID: 4, Role: models.JobMessageRoleAssistant, Content: "The third day now starts later.", OutputJobID: int64Pointer(6)},
+		{ID: 3, JobID: 4, Role: models.JobMessageRoleUser, Content: "Add vegetarian restaurants", OutputJobID: int64Pointer(7)},
+		{ID: 4, JobID: 4, Role: models.JobMessageRoleUser, Content: "Add a museum", OutputJobID: int64Pointer(8)},
+	})
+
+	payload, err := (&ItineraryFileJobService{}).SendMessage(newDestinationsItinerary(), newBaseJob(), "Add vegetarian restaurants", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), payload.BaseJob.ID)
+	assert.Equal(t, int64(6), *payload.ItineraryFileJob.BaseJobID)
+	assert.Len(t, payload.Conversation, 5)
+}
+
+func TestItineraryFileJobSendMessage_AuditFails(t *testing.T) {
+	mockSaveAuditEvent(t, errors.New("error saving audit event"))
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	mockConversationJobs(t, map[int64]*models.ItineraryFileJob{})
+	initConversationJob := models.InitItineraryFileJob
+	failed := false
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		job := initConversationJob()
+		job.FailJob = func(desc string) error {
+			failed = true
+			return nil
+		}
+		return job
+	}
+	mockStoredJobMessages(t, []*models.ItineraryJobMessage{})
+
+	payload, err := (&ItineraryFileJobService{}).SendMessage(newDestinationsItinerary(), newBaseJob(), "Add a museum", 2)
+	assert.Nil(t, payload)
+	assert.EqualError(t, err, "error saving audit event")
+	assert.True(t, failed)
+}
+
+func TestItineraryFileJobSendMessage_Invalid(t *testing.T) {
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+	mockConversationJobs(t, map[int64]*models.ItineraryFileJob{6: {ID: 6, ItineraryID: 1, Status: "running"}})
+	created := mockStoredJobMessages(t, []*models.ItineraryJobMessage{
+		{ID: 1, JobID: 4, Role: models.JobMessageRoleUser, Content: "Make the third day more relaxed", OutputJobID: int64Pointer(6)},
+	})
+
+	for _, test := range []struct {
+		job     *models.ItineraryFileJob
+		content string
+		err     string
+	}{
+		{newBaseJob(), "  ", "invalid message: the message cannot be empty"},
+		{&models.ItineraryFileJob{ID: 4, Status: "failed"}, "Add a museum", "invalid base job: only the files of completed jobs can be refined"},
+		{newBaseJob(), "Add a museum", "invalid message: the previous message is still being answered"},
+	} {
+		payload, err := (&ItineraryFileJobService{}).SendMessage(newDestinationsItinerary(), test.job, test.content, 2)
+		assert.Nil(t, payload)
+		assert.EqualError(t, err, test.err)
+	}
+	assert.Empty(t, *created)
+}
+
+func TestHandleItineraryFileJob_Conversation(t *testing.T) {
+	mockIndexItinerary(t)
+	baseJob := &models.ItineraryFileJob{ID: 6, ItineraryID: 1, Status: "completed", Filepath: "6.txt", FileManager: "local", Language: "es"}
+	fileManager := &inMemoryFileManager{files: map[string]string{baseJob.Filepath: "Summer in Spain\n\n01/07/2024\nMuseums"}}
+	setMockFileManager(t, fileManager)
+	created := mockStoredJobMessages(t, []*models.ItineraryJobMessage{})
+	job := mockItineraryFileJob()
+	job.ID = 9
+	job.FailJob = func(desc string) error {
+		t.Errorf("FailJob should not be called on success: %s", desc)
+		return nil
+	}
+	var statusDescription string
+	job.CompleteJob = func() error {
+		statusDescription = job.StatusDescription
+		return nil
+	}
+	origNewItineraryFileJob := models.NewItineraryFileJob
+	t.Cleanup(func() { models.NewItineraryFileJob = origNewItineraryFileJob })
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	var prompt string
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		prompt = msgs[1].Parts[0].(llms.TextContent).Text
+		response := `{"reply": "I added a vegetarian restaurant.", "itinerary": "Summer in Spain\n\n01/07/2024\nMuseums\nLunch at Artemisa"}`
+		return &response, nil
+	}
+
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:            newDestinationsItinerary(),
+		ItineraryFileJob:     &models.ItineraryFileJob{ID: 9, ItineraryID: 1, Language: "es", BaseJobID: &baseJob.ID},
+		TravellerPreferences: &models.TravellerPreferences{Language: "es"},
+		BaseJob:              baseJob,
+		Conversation: []*models.ItineraryJobMessage{
+			{ID: 1, JobID: 4, ItineraryID: 1, Role: models.JobMessageRoleUser, Content: "Make the first day more relaxed"},
+			{ID: 2, JobID: 4, ItineraryID: 1, Role: models.JobMessageRoleAssistant, Content: "The first day now starts later."},
+			{ID: 3, JobID: 4, ItineraryID: 1, Role: models.JobMessageRoleUser, Content: "Add vegetarian restaurants"},
+		},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.NoError(t, err)
+	assert.Equal(t, "Itinerary refined from job 6", statusDescription)
+	assert.Contains(t, prompt, "Summer in Spain\n\n01/07/2024\nMuseums\n")
+	assert.Contains(t, prompt, "Travellers: Make the first day more relaxed\nYou: The first day now starts later.\n"+
+		"Travellers: Add vegetarian restaurants\n")
+	assert.Contains(t, prompt, "writing it\nin Spanish")
+	assert.Equal(t, "Summer in Spain\n\n01/07/2024\nMuseums\nLunch at Artemisa", fileManager.files[job.Filepath])
+	assert.Len(t, *created, 1)
+	reply := (*created)[0]
+	assert.Equal(t, int64(4), reply.JobID)
+	assert.Equal(t, models.JobMessageRoleAssistant, reply.Role)
+	assert.Equal(t, "I added a vegetarian restaurant.", reply.Content)
+	assert.Equal(t, int64(9), *reply.OutputJobID)
+}
+
+func TestHandleItineraryFileJob_ConversationWithoutItinerary(t *testing.T) {
+	baseJob := &models.ItineraryFileJob{ID: 6, ItineraryID: 1, Status: "completed", Filepath: "6.txt", FileManager: "local"}
+	setMockFileManager(t, &inMemoryFileManager{files: map[string]string{baseJob.Filepath: "Summer in Spain"}})
+	created := mockStoredJobMessages(t, []*models.ItineraryJobMessage{})
+	job := mockItineraryFileJob()
+	var failure string
+	job.FailJob = func(desc string) error {
+		failure = desc
+		return nil
+	}
+	origNewItineraryFileJob := models.NewItineraryFileJob
+	t.Cleanup(func() { models.NewItineraryFileJob = origNewItineraryFileJob })
+	models.NewItineraryFileJob = func(itineraryId int64) *models.ItineraryFileJob {
+		return job
+	}
+
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		response := `{"reply": "Sure!"}`
+		return &response, nil
+	}
+
+	payload := ItineraryFileAsyncTaskPayload{
+		Itinerary:        newDestinationsItinerary(),
+		ItineraryFileJob: &models.ItineraryFileJob{ID: 9, ItineraryID: 1, BaseJobID: &baseJob.ID},
+		BaseJob:          baseJob,
+		Conversation:     []*models.ItineraryJobMessage{{ID: 1, JobID: 4, Role: models.JobMessageRoleUser, Content: "Add a museum"}},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+
+	err := HandleItineraryFileJob(context.TODO(), asynq.NewTask("ItineraryFileJob", payloadBytes))
+	assert.EqualError(t, err, "the answer has no itinerary")
+	assert.Equal(t, "Failed to refine itinerary: the answer has no itinerary", failure)
+	assert.Empty(t, *created)
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+)
+
+type ItineraryPlanServiceInterface interface {
+	FindDays(itinerary *models.Itinerary) ([]*models.ItineraryDay, error)
+	AddActivity(itinerary *models.Itinerary, activity *models.ItineraryActivity, actorId int64) error
+	UpdateActivity(itinerary *models.Itinerary, activity *models.ItineraryActivity, actorId int64) error
+	DeleteActivity(itinerary *models.Itinerary, activityId int64, actorId int64) error
+	ReorderDay(itinerary *models.Itinerary, date time.Time, activityIds []int64, actorId int64) error
+	Generate(ctx context.Context, itinerary *models.Itinerary, actorId int64) ([]*models.ItineraryDay, error)
+}
+
+type ItineraryPlanService struct{}
+
+// singleton instance
+var itineraryPlanServiceInstance = &ItineraryPlanService{}
+
+// GetItineraryPlanService returns the singleton instance of ItineraryPlanService
+var GetItineraryPlanService = func() ItineraryPlanServiceInterface {
+	return itineraryPlanServiceInstance
+}
+
+// planSystemMessage is the system message of the prompt the plans are generated with
+const planSystemMessage = "You are an expert travel planner. You only answer with JSON."
+
+// planPromptTemplate is the prompt the plans are generated with
+const planPromptTemplate = `Plan the activities of each day of a trip to the following destinations:
+{{range .destinations}}
+- Country: {{.country}}, City: {{.city}}, from {{.arrivalDate}} to {{.departureDate}}
+{{end}}
+{{if .travellerProfile}}
+Traveller profile:
+{{range .travellerProfile}}- {{.}}
+{{end}}{{end}}
+Plan every day from {{.startDate}} to {{.endDate}} in the city the travellers are in that day, splitting it into morning, afternoon and
+evening. Answer only with a JSON object like {"activities": [{"date": "2024-07-01", "timeSlot": "morning", "title": "Visit the Prado
+Museum", "location": "Calle de Ruiz de Alarcón 23, Madrid", "notes": "Book the tickets in advance"}]}, with the dates as YYYY-MM-DD, the
+time slot being one of morning, afternoon or evening, and the activities of each day in the order they are done.`
+
+// planBaseTokens is the number of tokens of the answer with a plan that do not depend on its days
+const planBaseTokens = 200
+
+// generatedPlan is the answer the plans are generated with
+type generatedPlan struct {
+	Activities []struct {
+		Date     string `json:"date"`
+		TimeSlot string `json:"timeSlot"`
+		Title    string `json:"title"`
+		Location string `json:"location"`
+		Notes    string `json:"notes"`
+	} `json:"activities"`
+}
+
+// FindDays returns the days of the plan of an itinerary that have activities, in order
+func (ips *ItineraryPlanService) FindDays(itinerary *models.Itinerary) ([]*models.ItineraryDay, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+
+	activities, err := findActivities(itinerary)
+	if err != nil {
+		return nil, err
+	}
+	return models.GroupActivitiesByDay(activities), nil
+}
+
+// AddActivity adds an activity at the end of its day in the plan of an itinerary retrieved with its destinations, recording the change
+// in the audit log
+func (ips *ItineraryPlanService) AddActivity(itinerary *models.Itinerary, activity *models.ItineraryActivity, actorId int64) error {
+	if itinerary == nil || activity == nil {
+		log.Error("Itinerary or activity instance is nil")
+		return errors.New("itinerary or activity instance is nil")
+	}
+
+	err := validateActivity(itinerary, activity)
+	if err != nil {
+		return err
+	}
+
+	activities, err := findActivities(itinerary)
+	if err != nil {
+		return err
+	}
+
+	activity = models.InitItineraryActivityFunctions(activity)
+	activity.ItineraryID = itinerary.ID
+	activity.Position = nextDayPosition(activities, activity.Date)
+	err = activity.Create()
+	if err != nil {
+		log.Errorf("Error adding activity to itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to save activity")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Activity %d of itinerary %d added.", activity.ID, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "activityId": activity.ID, "activity": "added"})
+}
+
+// UpdateActivity replaces an activity of the plan of an itinerary retrieved with its destinations, recording the change in the audit
+// log. The activity keeps its position unless it is moved to another day, where it goes at the end. Returns sql.ErrNoRows if the
+// itinerary has no such activity
+func (ips *ItineraryPlanService) UpdateActivity(itinerary *models.Itinerary, activity *models.ItineraryActivity, actorId int64) error {
+	if itinerary == nil || activity == nil {
+		log.Error("Itinerary or activity instance is nil")
+		return errors.New("itinerary or activity instance is nil")
+	}
+
+	activities, err := findActivities(itinerary)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(activities, func(candidate *models.ItineraryActivity) bool {
+		return candidate.ID == activity.ID
+	})
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+	existing := activities[index]
+
+	err = validateActivity(itinerary, activity)
+	if err != nil {
+		return err
+	}
+
+	activity = models.InitItineraryActivityFunctions(activity)
+	activity.ItineraryID = itinerary.ID
+	activity.CreationDate = existing.CreationDate
+	if activity.Date.Equal(existing.Date) {
+		activity.Position = existing.Position
+	} else {
+		activity.Position = nextDayPosition(activities, activity.Date)
+	}
+	err = activity.Update()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error updating activity %d of itinerary %d: %v", activity.ID, itinerary.ID, err)
+		return errors.New("failed to save activity")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Activity %d of itinerary %d updated.", activity.ID, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "activityId": activity.ID, "activity": "updated"})
+}
+
+// DeleteActivity removes an activity from the plan of an itinerary, recording the change in the audit log. Returns sql.ErrNoRows if the
+// itinerary has no such activity
+func (ips *ItineraryPlanService) DeleteActivity(itinerary *models.Itinerary, activityId int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	activity := models.InitItineraryActivity()
+	activity.ID = activityId
+	activity.ItineraryID = itinerary.ID
+	err := activity.Delete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting activity %d of itinerary %d: %v", activityId, itinerary.ID, err)
+		return errors.New("failed to delete activity")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Activity %d of itinerary %d removed.", activityId, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "activityId": activityId, "activity": "removed"})
+}
+
+// ReorderDay sets the order of the activities of a day of the plan of an itinerary, recording the change in the audit log. The IDs
+// must be the ones of all the activities of the day, each of them once
+func (ips *ItineraryPlanService) ReorderDay(itinerary *models.Itinerary, date time.Time, activityIds []int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	activities, err := findActivities(itinerary)
+	if err != nil {
+		return err
+	}
+
+	date = planDate(date)
+	dayActivityIds := []int64{}
+	for _, activity := range activities {
+		if activity.Date.Equal(date) {
+			dayActivityIds = append(dayActivityIds, activity.ID)
+		}
+	}
+	if len(dayActivityIds) == 0 {
+		return sql.ErrNoRows
+	}
+	sortedIds := slices.Clone(activityIds)
+	slices.Sort(sortedIds)
+	slices.Sort(dayActivityIds)
+	if !slices.Equal(sortedIds, dayActivityIds) {
+		return fmt.Errorf("invalid plan: the order must have the IDs of all the activities of %s, each of them once", calendarDate(date))
+	}
+
+	err = models.InitItineraryActivity().UpdatePositions(itinerary.ID, activityIds)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error reordering the activities of %s in itinerary %d: %v", calendarDate(date), itinerary.ID, err)
+		return errors.New("failed to reorder activities")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated,
+		fmt.Sprintf("Activities of %s in itinerary %d reordered.", calendarDate(date), itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "date": calendarDate(date), "plan": "reordered"})
+}
+
+// Generate replaces the plan of an itinerary retrieved with its destinations with one generated by the LLM for the destinations and the
+// effective traveller preferences of the itinerary, recording the change in the audit log. Generated activities outside the trip or
+// with an unknown time slot are ignored. It runs in the itinerary file jobs, which cancel the LLM call with the context
+func (ips *ItineraryPlanService) Generate(ctx context.Context, itinerary *models.Itinerary, actorId int64) ([]*models.ItineraryDay, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+	if len(itinerary.TravelDestinations) == 0 {
+		return nil, errors.New("invalid plan: the itinerary has no destinations")
+	}
+
+	preferences, err := GetTravellerPreferencesService().FindEffective(itinerary)
+	if err != nil {
+		log.Errorf("Error retrieving traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to generate plan")
+	}
+
+	destinations := []map[string]any{}
+	for _, destination := range itinerary.TravelDestinations {
+		destinations = append(destinations, map[string]any{"country": destination.Country, "city": destination.City,
+			"arrivalDate": calendarDate(destination.ArrivalDate.UTC()), "departureDate": calendarDate(destination.DepartureDate.UTC())})
+	}
+	startDate, endDate := tripDates(itinerary)
+
+	prompt, err := prompts.NewPromptTemplate(planPromptTemplate, []string{"destinations", "travellerProfile", "startDate", "endDate"}).
+		Format(map[string]any{"destinations": destinations, "travellerProfile": describeTravellerProfile(preferences),
+			"startDate": calendarDate(startDate), "endDate": calendarDate(endDate)})
+	if err != nil {
+		log.Errorf("Error formatting plan prompt: %v", err)
+		return nil, errors.New("failed to generate plan")
+	}
+
+	// The answer has a few activities per day, so it is limited by the length of the trip rather than LLM_MAX_RESPONSE_LENGTH
+	maxTokens, err := planMaxTokens(startDate, endDate)
+	if err != nil {
+		return nil, errors.New("failed to generate plan")
+	}
+	response, err := apis.CallLlm(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, planSystemMessage),
+		llms.TextParts(llms.ChatMessageTypeHuman, prompt),
+	}, llms.WithMaxTokens(maxTokens), llms.WithMaxLength(maxTokens))
+	if err != nil || response == nil {
+		log.Errorf("Error generating plan of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to generate plan")
+	}
+
+	generated := &generatedPlan{}
+	err = parseLlmJsonObject(*response, generated)
+	if err != nil {
+		log.Errorf("Error parsing plan of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to generate plan")
+	}
+
+	activities := []*models.ItineraryActivity{}
+	for _, candidate := range generated.Activities {
+		date, err := time.Parse(time.DateOnly, candidate.Date)
+		if err != nil {
+			log.Warnf("Ignoring activity with invalid date %q generated for itinerary %d", candidate.Date, itinerary.ID)
+			continue
+		}
+		activity := &models.ItineraryActivity{Date: date, TimeSlot: strings.ToLower(candidate.TimeSlot), Title: candidate.Title,
+			Location: candidate.Location, Notes: candidate.Notes}
+		err = validateActivity(itinerary, activity)
+		if err != nil {
+			log.Warnf("Ignoring activity generated for itinerary %d: %v", itinerary.ID, err)
+			continue
+		}
+		activities = append(activities, activity)
+	}
+	if len(activities) == 0 {
+		log.Errorf("No valid activity generated for itinerary %d", itinerary.ID)
+		return nil, errors.New("failed to generate plan")
+	}
+
+	// Keep the order of the LLM within each time slot of a day
+	sort.SliceStable(activities, func(i, j int) bool {
+		if !activities[i].Date.Equal(activities[j].Date) {
+			return activities[i].Date.Before(activities[j].Date)
+		}
+		return slices.Index(models.TimeSlots, activities[i].TimeSlot) < slices.Index(models.TimeSlots, activities[j].TimeSlot)
+	})
+	for i, activity := range activities {
+		activity.Position = 1
+		if i > 0 && activities[i-1].Date.Equal(activity.Date) {
+			activity.Position = activities[i-1].Position + 1
+		}
+	}
+
+	err = models.InitItineraryActivity().ReplaceByItineraryId(itinerary.ID, activities)
+	if err != nil {
+		log.Errorf("Error saving generated plan of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to save plan")
+	}
+
+	err = saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Plan of itinerary %d generated.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "plan": "generated", "activities": len(activities)})
+	if err != nil {
+		return nil, err
+	}
+
+	return models.GroupActivitiesByDay(activities), nil
+}
+
+// planMaxTokens returns the maximum number of tokens of the answer with the plan of the days from the start to the end date, which is
+// LLM_PLAN_MAX_TOKENS_PER_DAY for each day on top of the ones of the JSON object
+func planMaxTokens(startDate time.Time, endDate time.Time) (int, error) {
+	tokensPerDay, err := getIntEnvOrDefault("LLM_PLAN_MAX_TOKENS_PER_DAY", 500)
+	if err != nil {
+		return 0, err
+	}
+	days := int(endDate.Sub(startDate).Hours()/24) + 1
+	return planBaseTokens + days*tokensPerDay, nil
+}
+
+// findActivities returns the activities of the plan of the itinerary sorted by date and position
+func findActivities(itinerary *models.Itinerary) ([]*models.ItineraryActivity, error) {
+	activities, err := models.InitItineraryActivity().FindByItineraryId(itinerary.ID)
+	if err != nil {
+		log.Errorf("Error retrieving plan of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to retrieve plan")
+	}
+	return activities, nil
+}
+
+// validateActivity checks that the activity has a title and a known time slot and that it is during the trip, setting its date to
+// midnight UTC of its day
+func validateActivity(itinerary *models.Itinerary, activity *models.ItineraryActivity) error {
+	if strings.TrimSpace(activity.Title) == "" {
+		return errors.New("invalid activity: the title is required")
+	}
+	if !slices.Contains(models.TimeSlots, activity.TimeSlot) {
+		return fmt.Errorf("invalid activity: the time slot must be one of %s", strings.Join(models.TimeSlots, ", "))
+	}
+	if len(itinerary.TravelDestinations) == 0 {
+		return errors.New("invalid activity: the itinerary has no destinations")
+	}
+
+	activity.Date = planDate(activity.Date)
+	startDate, endDate := tripDates(itinerary)
+	if activity.Date.Before(startDate) || activity.Date.After(endDate) {
+		return fmt.Errorf("invalid activity: the date must be between %s and %s", calendarDate(startDate), calendarDate(endDate))
+	}
+	return nil
+}
+
+// tripDates returns the days of the first arrival and the last departure of the destinations of the itinerary, at midnight UTC
+func tripDates(itinerary *models.Itinerary) (time.Time, time.Time) {
+	var startDate, endDate time.Time
+	for i, destination := range itinerary.TravelDestinations {
+		arrival, departure := planDate(destination.ArrivalDate.UTC()), planDate(destination.DepartureDate.UTC())
+		if i == 0 || arrival.Before(startDate) {
+			startDate = arrival
+		}
+		if i == 0 || departure.After(endDate) {
+			endDate = departure
+		}
+	}
+	return startDate, endDate
+}
+
+// planDate returns midnight UTC of the calendar day of the time, which is how the days of the plans are stored
+func planDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nextDayPosition returns the position after the last activity of the day, as the positions of a day can have gaps left by the removed
+// activities
+func nextDayPosition(activities []*models.ItineraryActivity, date time.Time) int {
+	position := 0
+	for _, activity := range activities {
+		if activity.Date.Equal(date) && activity.Position > position {
+			position = activity.Position
+		}
+	}
+	return position + 1
+}
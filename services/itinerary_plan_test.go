@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/apis"
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// storedActivities records the changes of the plan made through the mocked activity functions
+type storedActivities struct {
+	Saved      []*models.ItineraryActivity
+	Reordered  []int64
+	Replaced   []*models.ItineraryActivity
+	DeletedIds []int64
+}
+
+// mockStoredActivities makes the plan of every itinerary the activities, and the changes of the activities succeed, or fail with
+// sql.ErrNoRows if notFound
+func mockStoredActivities(t *testing.T, activities []*models.ItineraryActivity, notFound bool) *storedActivities {
+	stored := &storedActivities{}
+	orig := models.InitItineraryActivity
+	origFunctions := models.InitItineraryActivityFunctions
+	initFunctions := func(a *models.ItineraryActivity) *models.ItineraryActivity {
+		result := func() error {
+			if notFound {
+				return sql.ErrNoRows
+			}
+			return nil
+		}
+		a.FindByItineraryId = func(itineraryId int64) ([]*models.ItineraryActivity, error) { return activities, nil }
+		a.Create = func() error {
+			a.ID = 5
+			stored.Saved = append(stored.Saved, a)
+			return result()
+		}
+		a.Update = func() error {
+			stored.Saved = append(stored.Saved, a)
+			return result()
+		}
+		a.Delete = func() error {
+			stored.DeletedIds = append(stored.DeletedIds, a.ID)
+			return result()
+		}
+		a.UpdatePositions = func(itineraryId int64, activityIds []int64) error {
+			stored.Reordered = activityIds
+			return result()
+		}
+		a.ReplaceByItineraryId = func(itineraryId int64, replaced []*models.ItineraryActivity) error {
+			stored.Replaced = replaced
+			return result()
+		}
+		return a
+	}
+	models.InitItineraryActivityFunctions = initFunctions
+	models.InitItineraryActivity = func() *models.ItineraryActivity {
+		return initFunctions(&models.ItineraryActivity{})
+	}
+	t.Cleanup(func() {
+		models.InitItineraryActivity = orig
+		models.InitItineraryActivityFunctions = origFunctions
+	})
+	return stored
+}
+
+func planDay(day int) time.Time {
+	return time.Date(2024, time.July, day, 0, 0, 0, 0, time.UTC)
+}
+
+func newPlanActivities() []*models.ItineraryActivity {
+	return []*models.ItineraryActivity{
+		{ID: 1, ItineraryID: 1, Date: planDay(1), TimeSlot: models.TimeSlotMorning, Title: "Visit the Prado Museum", Position: 1},
+		{ID: 2, ItineraryID: 1, Date: planDay(1), TimeSlot: models.TimeSlotEvening, Title: "Dinner at Botín", Position: 3},
+		{ID: 3, ItineraryID: 1, Date: planDay(2), TimeSlot: models.TimeSlotMorning, Title: "Walk in El Retiro", Position: 1},
+	}
+}
+
+func TestItineraryPlanService_FindDays(t *testing.T) {
+	mockStoredActivities(t, newPlanActivities(), false)
+
+	days, err := GetItineraryPlanService().FindDays(newDestinationsItinerary())
+	assert.NoError(t, err)
+	assert.Len(t, days, 2)
+	assert.Len(t, days[0].Activities, 2)
+	assert.Equal(t, planDay(2), days[1].Date)
+}
+
+func TestItineraryPlanService_AddActivity(t *testing.T) {
+	stored := mockStoredActivities(t, newPlanActivities(), false)
+	descriptions := mockSaveAuditEvent(t, nil)
+	activity := &models.ItineraryActivity{Date: time.Date(2024, time.July, 1, 18, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+		TimeSlot: models.TimeSlotAfternoon, Title: "Tapas in La Latina"}
+
+	err := GetItineraryPlanService().AddActivity(newDestinationsItinerary(), activity, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ItineraryActivity{activity}, stored.Saved)
+	assert.Equal(t, int64(1), activity.ItineraryID)
+	assert.Equal(t, planDay(1), activity.Date)
+	assert.Equal(t, 4, activity.Position)
+	assert.Equal(t, []string{"Activity 5 of itinerary 1 added."}, *descriptions)
+}
+
+func TestItineraryPlanService_AddActivity_Invalid(t *testing.T) {
+	stored := mockStoredActivities(t, newPlanActivities(), false)
+	for _, test := range []struct {
+		activity *models.ItineraryActivity
+		err      string
+	}{
+		{&models.ItineraryActivity{Date: planDay(1), TimeSlot: models.TimeSlotMorning, Title: " "}, "invalid activity: the title is required"},
+		{&models.ItineraryActivity{Date: planDay(1), TimeSlot: "night", Title: "Flamenco show"},
+			"invalid activity: the time slot must be one of morning, afternoon, evening"},
+		{&models.ItineraryActivity{Date: planDay(9), TimeSlot: models.TimeSlotMorning, Title: "Flamenco show"},
+			"invalid activity: the date must be between 2024-07-01 and 2024-07-08"},
+	} {
+		err := GetItineraryPlanService().AddActivity(newDestinationsItinerary(), test.activity, 2)
+		assert.EqualError(t, err, test.err)
+	}
+	assert.Empty(t, stored.Saved)
+}
+
+func TestItineraryPlanService_UpdateActivity_MovedToAnotherDay(t *testing.T) {
+	stored := mockStoredActivities(t, newPlanActivities(), false)
+	descriptions := mockSaveAuditEvent(t, nil)
+	activity := &models.ItineraryActivity{ID: 1, Date: planDay(2), TimeSlot: models.TimeSlotAfternoon, Title: "Visit the Prado Museum"}
+
+	err := GetItineraryPlanService().UpdateActivity(newDestinationsItinerary(), activity, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ItineraryActivity{activity}, stored.Saved)
+	assert.Equal(t, 2, activity.Position)
+	assert.Equal(t, []string{"Activity 1 of itinerary 1 updated."}, *descriptions)
+}
+
+func TestItineraryPlanService_UpdateActivity_NotFound(t *testing.T) {
+	stored := mockStoredActivities(t, newPlanActivities(), false)
+
+	err := GetItineraryPlanService().UpdateActivity(newDestinationsItinerary(),
+		&models.ItineraryActivity{ID: 99, Date: planDay(2), TimeSlot: models.TimeSlotMorning, Title: "Walk"}, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, stored.Saved)
+}
+
+func TestItineraryPlanService_DeleteActivity_NotFound(t *testing.T) {
+	mockStoredActivities(t, newPlanActivities(), true)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	err := GetItineraryPlanService().DeleteActivity(newDestinationsItinerary(), 99, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *descriptions)
+}
+
+func TestItineraryPlanService_ReorderDay(t *testing.T) {
+	stored := mockStoredActivities(t, newPlanActivities(), false)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	err := GetItineraryPlanService().ReorderDay(newDestinationsItinerary(), planDay(1), []int64{2, 1}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, stored.Reordered)
+	assert.Equal(t, []string{"Activities of 2024-07-01 in itinerary 1 reordered."}, *descriptions)
+}
+
+func TestItineraryPlanService_ReorderDay_Invalid(t *testing.T) {
+	stored := mockStoredActivities(t, newPlanActivities(), false)
+
+	for _, activityIds := range [][]int64{{2}, {2, 1, 3}, {2, 2}} {
+		err := GetItineraryPlanService().ReorderDay(newDestinationsItinerary(), planDay(1), activityIds, 2)
+		assert.EqualError(t, err, "invalid plan: the order must have the IDs of all the activities of 2024-07-01, each of them once")
+	}
+	err := GetItineraryPlanService().ReorderDay(newDestinationsItinerary(), planDay(6), []int64{1}, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Nil(t, stored.Reordered)
+}
+
+func TestItineraryPlanService_Generate(t *testing.T) {
+	stored := mockStoredActivities(t, []*models.ItineraryActivity{}, false)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{Pace: models.TravellerPaceRelaxed}, nil)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	var prompt string
+	callOptions := llms.CallOptions{}
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, options ...llms.CallOption) (*string, error) {
+		prompt = msgs[1].Parts[0].(llms.TextContent).Text
+		for _, option := range options {
+			option(&callOptions)
+		}
+		response := "```json\n" + `{"activities": [{"date": "2024-07-01", "timeSlot": "evening", "title": "Dinner at Botín"},
+		{"date": "2024-07-01", "timeSlot": "Morning", "title": "Visit the Prado Museum", "location": "Calle de Ruiz de Alarcón 23"},
+		{"date": "2024-07-02", "timeSlot": "morning", "title": "Walk in El Retiro"},
+		{"date": "2024-07-01", "timeSlot": "afternoon", "title": "Tapas in La Latina"},
+		{"date": "2024-07-12", "timeSlot": "morning", "title": "Too late"},
+		{"date": "tomorrow", "timeSlot": "morning", "title": "Invalid date"},
+		{"date": "2024-07-03", "timeSlot": "night", "title": "Invalid time slot"}]}` + "\n```"
+		return &response, nil
+	}
+
+	days, err := GetItineraryPlanService().Generate(context.Background(), newDestinationsItinerary(), 2)
+	assert.NoError(t, err)
+	// 8 days of trip with the default tokens per day
+	assert.Equal(t, 4200, callOptions.MaxTokens)
+	assert.Equal(t, 4200, callOptions.MaxLength)
+	assert.Contains(t, prompt, "Country: Spain, City: Seville, from 2024-07-05 to 2024-07-08")
+	assert.Contains(t, prompt, "from 2024-07-01 to 2024-07-08")
+	assert.Contains(t, prompt, "Traveller profile:")
+	assert.Len(t, stored.Replaced, 4)
+	assert.Len(t, days, 2)
+	titles := []string{}
+	for _, activity := range days[0].Activities {
+		titles = append(titles, activity.Title)
+	}
+	assert.Equal(t, []string{"Visit the Prado Museum", "Tapas in La Latina", "Dinner at Botín"}, titles)
+	assert.Equal(t, 3, days[0].Activities[2].Position)
+	assert.Equal(t, 1, days[1].Activities[0].Position)
+	assert.Equal(t, []string{"Plan of itinerary 1 generated."}, *descriptions)
+}
+
+func TestItineraryPlanService_Generate_InvalidAnswer(t *testing.T) {
+	stored := mockStoredActivities(t, []*models.ItineraryActivity{}, false)
+	mockEffectiveTravellerPreferences(t, &models.TravellerPreferences{}, nil)
+
+	origCallLlm := apis.CallLlm
+	t.Cleanup(func() { apis.CallLlm = origCallLlm })
+	apis.CallLlm = func(_ context.Context, msgs []llms.MessageContent, _ ...llms.CallOption) (*string, error) {
+		response := `{"activities": [{"date": "2024-08-01", "timeSlot": "morning", "title": "Outside the trip"}]}`
+		return &response, nil
+	}
+
+	_, err := GetItineraryPlanService().Generate(context.Background(), newDestinationsItinerary(), 2)
+	assert.EqualError(t, err, "failed to generate plan")
+	assert.Nil(t, stored.Replaced)
+
+	_, err = GetItineraryPlanService().Generate(context.Background(), &models.Itinerary{ID: 1}, 2)
+	assert.EqualError(t, err, "invalid plan: the itinerary has no destinations")
+}
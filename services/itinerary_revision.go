@@ -0,0 +1,91 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type ItineraryRevisionServiceInterface interface {
+	FindByItineraryId(itineraryId int64) ([]*models.ItineraryRevision, error)
+	FindByNumber(itineraryId int64, number int64) (*models.ItineraryRevision, error)
+	Diff(itineraryId int64, from int64, to int64) ([]*models.ItineraryRevisionChange, error)
+	Restore(itinerary *models.Itinerary, number int64, actorId int64) error
+}
+
+type ItineraryRevisionService struct{}
+
+// singleton instance
+var itineraryRevisionServiceInstance = &ItineraryRevisionService{}
+
+// GetItineraryRevisionService returns the singleton instance of ItineraryRevisionService
+var GetItineraryRevisionService = func() ItineraryRevisionServiceInterface {
+	return itineraryRevisionServiceInstance
+}
+
+// FindByItineraryId retrieves the revisions of an itinerary from the newest, without their destinations
+func (irs *ItineraryRevisionService) FindByItineraryId(itineraryId int64) ([]*models.ItineraryRevision, error) {
+	if itineraryId <= 0 {
+		log.Error("Invalid itinerary ID provided")
+		return nil, errors.New("invalid itinerary ID")
+	}
+	return models.InitItineraryRevision().FindByItineraryId(itineraryId)
+}
+
+// FindByNumber retrieves a revision of an itinerary with its destinations
+func (irs *ItineraryRevisionService) FindByNumber(itineraryId int64, number int64) (*models.ItineraryRevision, error) {
+	if number <= 0 {
+		log.Error("Invalid revision number provided")
+		return nil, errors.New("invalid revision number")
+	}
+	return models.InitItineraryRevision().FindByNumber(itineraryId, number)
+}
+
+// Diff lists the changes made to an itinerary from a revision to another one. The revisions can be given in any order, which reverses
+// the changes
+func (irs *ItineraryRevisionService) Diff(itineraryId int64, from int64, to int64) ([]*models.ItineraryRevisionChange, error) {
+	fromRevision, err := irs.FindByNumber(itineraryId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := irs.FindByNumber(itineraryId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromRevision.Changes(toRevision), nil
+}
+
+// Restore brings the itinerary back to the content of one of its revisions, which is saved as a new revision authored by the actor
+func (irs *ItineraryRevisionService) Restore(itinerary *models.Itinerary, number int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	revision, err := irs.FindByNumber(itinerary.ID, number)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Revision %d of itinerary %d not found", number, itinerary.ID)
+		}
+		return err
+	}
+
+	itinerary = models.InitItineraryFunctions(itinerary)
+	err = itinerary.Restore(revision, actorId)
+	if err != nil {
+		log.Errorf("Error restoring revision %d of itinerary %d: %v", number, itinerary.ID, err)
+		if errors.Is(err, models.ErrItineraryVersionConflict) {
+			return err
+		}
+		return errors.New("failed to restore itinerary revision")
+	}
+
+	indexItinerary(itinerary.ID)
+	return saveAuditEvent(actorId, models.AuditEventItineraryRestored, fmt.Sprintf("Itinerary %d restored to revision %d.", itinerary.ID, number),
+		map[string]any{"itineraryId": itinerary.ID, "revision": number})
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFindItineraryRevisions(t *testing.T, revisions map[int64]*models.ItineraryRevision) {
+	orig := models.InitItineraryRevision
+	models.InitItineraryRevision = func() *models.ItineraryRevision {
+		return &models.ItineraryRevision{
+			FindByNumber: func(itineraryId int64, number int64) (*models.ItineraryRevision, error) {
+				revision, ok := revisions[number]
+				if !ok || revision.ItineraryID != itineraryId {
+					return nil, sql.ErrNoRows
+				}
+				return revision, nil
+			},
+		}
+	}
+	t.Cleanup(func() { models.InitItineraryRevision = orig })
+}
+
+func TestItineraryRevisionService_FindByNumber_InvalidNumber(t *testing.T) {
+	_, err := GetItineraryRevisionService().FindByNumber(1, 0)
+	assert.EqualError(t, err, "invalid revision number")
+}
+
+func TestItineraryRevisionService_Diff(t *testing.T) {
+	mockFindItineraryRevisions(t, map[int64]*models.ItineraryRevision{
+		1: {ItineraryID: 1, Number: 1, Title: "Spain", TravelDestinations: []*models.ItineraryRevisionDestination{{Country: "Spain", City: "Madrid"}}},
+		2: {ItineraryID: 1, Number: 2, Title: "Spain trip", TravelDestinations: []*models.ItineraryRevisionDestination{{Country: "Spain", City: "Seville"}}},
+	})
+
+	changes, err := GetItineraryRevisionService().Diff(1, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ItineraryRevisionChange{
+		{Field: "title", From: "Spain", To: "Spain trip"},
+		{Field: "travelDestinations[0].city", From: "Madrid", To: "Seville"},
+	}, changes)
+
+	_, err = GetItineraryRevisionService().Diff(1, 1, 3)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestItineraryRevisionService_Restore_Success(t *testing.T) {
+	indexed := mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	revision := &models.ItineraryRevision{ItineraryID: 1, Number: 2, Title: "Spain"}
+	mockFindItineraryRevisions(t, map[int64]*models.ItineraryRevision{2: revision})
+
+	var restored *models.ItineraryRevision
+	origInitFunctions := models.InitItineraryFunctions
+	models.InitItineraryFunctions = func(itinerary *models.Itinerary) *models.Itinerary {
+		itinerary.Restore = func(revision *models.ItineraryRevision, authorId int64) error {
+			restored = revision
+			return nil
+		}
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItineraryFunctions = origInitFunctions })
+
+	err := GetItineraryRevisionService().Restore(&models.Itinerary{ID: 1, OwnerID: 3}, 2, 4)
+	assert.NoError(t, err)
+	assert.Same(t, revision, restored)
+	assert.Equal(t, []int64{1}, *indexed)
+	assert.Equal(t, []string{"Itinerary 1 restored to revision 2."}, *descriptions)
+}
+
+func TestItineraryRevisionService_Restore_Errors(t *testing.T) {
+	mockFindItineraryRevisions(t, map[int64]*models.ItineraryRevision{2: {ItineraryID: 1, Number: 2}})
+	svc := GetItineraryRevisionService()
+
+	assert.EqualError(t, svc.Restore(nil, 2, 4), "itinerary instance is nil")
+	assert.ErrorIs(t, svc.Restore(&models.Itinerary{ID: 1}, 5, 4), sql.ErrNoRows)
+
+	origInitFunctions := models.InitItineraryFunctions
+	models.InitItineraryFunctions = func(itinerary *models.Itinerary) *models.Itinerary {
+		itinerary.Restore = func(revision *models.ItineraryRevision, authorId int64) error { return errors.New("db error") }
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItineraryFunctions = origInitFunctions })
+
+	assert.EqualError(t, svc.Restore(&models.Itinerary{ID: 1}, 2, 4), "failed to restore itinerary revision")
+
+	models.InitItineraryFunctions = func(itinerary *models.Itinerary) *models.Itinerary {
+		itinerary.Restore = func(revision *models.ItineraryRevision, authorId int64) error {
+			return models.ErrItineraryVersionConflict
+		}
+		return itinerary
+	}
+	assert.ErrorIs(t, svc.Restore(&models.Itinerary{ID: 1}, 2, 4), models.ErrItineraryVersionConflict)
+}
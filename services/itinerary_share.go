@@ -0,0 +1,115 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type ItineraryShareServiceInterface interface {
+	FindByItineraryId(itineraryId int64) ([]*models.ItineraryShare, error)
+	FindSharedWithUser(userId int64) ([]*models.ItineraryShare, error)
+	Share(itinerary *models.Itinerary, email string, permission string, actorId int64) (*models.ItineraryShare, error)
+	Unshare(itinerary *models.Itinerary, userId int64, actorId int64) error
+}
+
+type ItineraryShareService struct{}
+
+// singleton instance
+var itineraryShareServiceInstance = &ItineraryShareService{}
+
+// GetItineraryShareService returns the singleton instance of ItineraryShareService
+var GetItineraryShareService = func() ItineraryShareServiceInterface {
+	return itineraryShareServiceInstance
+}
+
+// FindByItineraryId retrieves the users an itinerary is shared with and their permissions
+func (iss *ItineraryShareService) FindByItineraryId(itineraryId int64) ([]*models.ItineraryShare, error) {
+	if itineraryId <= 0 {
+		log.Error("Invalid itinerary ID provided")
+		return nil, errors.New("invalid itinerary ID")
+	}
+	return models.InitItineraryShare().FindByItineraryId(itineraryId)
+}
+
+// FindSharedWithUser retrieves the itineraries other users shared with the user
+func (iss *ItineraryShareService) FindSharedWithUser(userId int64) ([]*models.ItineraryShare, error) {
+	if userId <= 0 {
+		log.Error("Invalid user ID provided")
+		return nil, errors.New("invalid user ID")
+	}
+	return models.InitItineraryShare().FindByUserId(userId)
+}
+
+// Share grants the registered user with the given email a viewer or editor permission on the itinerary. Sharing again with the
+// same user changes their permission
+func (iss *ItineraryShareService) Share(itinerary *models.Itinerary, email string, permission string, actorId int64) (*models.ItineraryShare, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+
+	if !slices.Contains(models.ItineraryShareRoles, permission) {
+		log.Errorf("Invalid itinerary share permission %s", permission)
+		return nil, errors.New("invalid permission")
+	}
+
+	user, err := models.InitUser().FindByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("No user registered with email %s", email)
+			return nil, errors.New("user not found")
+		}
+		log.Errorf("Error retrieving user by email: %v", err)
+		return nil, errors.New("failed to find user")
+	}
+
+	if user.ID == itinerary.OwnerID {
+		log.Errorf("Itinerary %d cannot be shared with its owner", itinerary.ID)
+		return nil, errors.New("cannot share an itinerary with its owner")
+	}
+
+	share := models.NewItineraryShare(itinerary.ID, user.ID, permission)
+	err = share.Save()
+	if err != nil {
+		log.Errorf("Error sharing itinerary %d with user %d: %v", itinerary.ID, user.ID, err)
+		return nil, errors.New("failed to share itinerary")
+	}
+	share.UserEmail = user.Email
+
+	err = saveAuditEvent(actorId, models.AuditEventItineraryShared, fmt.Sprintf("Itinerary %d shared with user %d as %s.", itinerary.ID, user.ID, permission),
+		map[string]any{"itineraryId": itinerary.ID, "userId": user.ID, "permission": permission})
+	if err != nil {
+		return nil, err
+	}
+
+	return share, nil
+}
+
+// Unshare revokes the access of the user to the itinerary. Users without access are reported as not found
+func (iss *ItineraryShareService) Unshare(itinerary *models.Itinerary, userId int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	share, err := models.InitItineraryShare().FindByItineraryAndUserId(itinerary.ID, userId)
+	if err != nil {
+		return err
+	}
+
+	share = models.InitItineraryShareFunctions(share)
+	err = share.Delete()
+	if err != nil {
+		log.Errorf("Error unsharing itinerary %d with user %d: %v", itinerary.ID, userId, err)
+		return errors.New("failed to unshare itinerary")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUnshared, fmt.Sprintf("Itinerary %d unshared with user %d.", itinerary.ID, userId),
+		map[string]any{"itineraryId": itinerary.ID, "userId": userId})
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFindUserByEmail(t *testing.T, user *models.User, err error) {
+	orig := models.InitUser
+	t.Cleanup(func() { models.InitUser = orig })
+
+	models.InitUser = func() *models.User {
+		return &models.User{
+			FindByEmail: func(email string) (*models.User, error) {
+				return user, err
+			},
+		}
+	}
+}
+
+func mockNewItineraryShare(t *testing.T, saveErr error) **models.ItineraryShare {
+	orig := models.NewItineraryShare
+	t.Cleanup(func() { models.NewItineraryShare = orig })
+
+	var saved *models.ItineraryShare
+	models.NewItineraryShare = func(itineraryId int64, userId int64, permission string) *models.ItineraryShare {
+		saved = &models.ItineraryShare{ItineraryID: itineraryId, UserID: userId, Permission: permission}
+		saved.Save = func() error { return saveErr }
+		return saved
+	}
+	return &saved
+}
+
+func TestItineraryShareService_Share_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	mockFindUserByEmail(t, &models.User{ID: 3, Email: "friend@example.com"}, nil)
+	saved := mockNewItineraryShare(t, nil)
+
+	share, err := (&ItineraryShareService{}).Share(&models.Itinerary{ID: 1, OwnerID: 2}, "friend@example.com", models.ItineraryPermissionEditor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), share.UserID)
+	assert.Equal(t, "friend@example.com", share.UserEmail)
+	assert.Equal(t, models.ItineraryPermissionEditor, (*saved).Permission)
+	assert.Equal(t, []string{"Itinerary 1 shared with user 3 as editor."}, *descriptions)
+}
+
+func TestItineraryShareService_Share_InvalidPermission(t *testing.T) {
+	_, err := (&ItineraryShareService{}).Share(&models.Itinerary{ID: 1, OwnerID: 2}, "friend@example.com", models.ItineraryPermissionOwner, 2)
+	assert.EqualError(t, err, "invalid permission")
+}
+
+func TestItineraryShareService_Share_UserNotFound(t *testing.T) {
+	mockFindUserByEmail(t, nil, sql.ErrNoRows)
+
+	_, err := (&ItineraryShareService{}).Share(&models.Itinerary{ID: 1, OwnerID: 2}, "nobody@example.com", models.ItineraryPermissionViewer, 2)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestItineraryShareService_Share_WithOwner(t *testing.T) {
+	mockFindUserByEmail(t, &models.User{ID: 2}, nil)
+
+	_, err := (&ItineraryShareService{}).Share(&models.Itinerary{ID: 1, OwnerID: 2}, "me@example.com", models.ItineraryPermissionViewer, 2)
+	assert.EqualError(t, err, "cannot share an itinerary with its owner")
+}
+
+func TestItineraryShareService_Share_SaveError(t *testing.T) {
+	mockFindUserByEmail(t, &models.User{ID: 3}, nil)
+	mockNewItineraryShare(t, errors.New("db error"))
+
+	_, err := (&ItineraryShareService{}).Share(&models.Itinerary{ID: 1, OwnerID: 2}, "friend@example.com", models.ItineraryPermissionViewer, 2)
+	assert.EqualError(t, err, "failed to share itinerary")
+}
+
+func TestItineraryShareService_Unshare_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	deleted := false
+	mockFindItineraryShare(t, &models.ItineraryShare{ItineraryID: 1, UserID: 3}, nil)
+	origFunctions := models.InitItineraryShareFunctions
+	t.Cleanup(func() { models.InitItineraryShareFunctions = origFunctions })
+	models.InitItineraryShareFunctions = func(share *models.ItineraryShare) *models.ItineraryShare {
+		share.Delete = func() error {
+			deleted = true
+			return nil
+		}
+		return share
+	}
+
+	err := (&ItineraryShareService{}).Unshare(&models.Itinerary{ID: 1, OwnerID: 2}, 3, 2)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, []string{"Itinerary 1 unshared with user 3."}, *descriptions)
+}
+
+func TestItineraryShareService_Unshare_NotShared(t *testing.T) {
+	mockFindItineraryShare(t, nil, sql.ErrNoRows)
+
+	err := (&ItineraryShareService{}).Unshare(&models.Itinerary{ID: 1, OwnerID: 2}, 3, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
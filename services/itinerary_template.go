@@ -0,0 +1,150 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type ItineraryTemplateServiceInterface interface {
+	FindById(id int64, userId int64) (*models.ItineraryTemplate, error)
+	FindAvailable(userId int64) ([]*models.ItineraryTemplate, error)
+	Create(template *models.ItineraryTemplate, actorId int64) error
+	Delete(template *models.ItineraryTemplate, actorId int64) error
+	Instantiate(template *models.ItineraryTemplate, startDate time.Time, title string, ownerId int64) (*models.Itinerary, error)
+}
+
+type ItineraryTemplateService struct{}
+
+// ErrItineraryTemplateNotOwned is returned when a user tries to change a template shared by another user
+var ErrItineraryTemplateNotOwned = errors.New("itinerary template not owned by the user")
+
+// singleton instance
+var itineraryTemplateServiceInstance = &ItineraryTemplateService{}
+
+// GetItineraryTemplateService returns the singleton instance of ItineraryTemplateService
+var GetItineraryTemplateService = func() ItineraryTemplateServiceInterface {
+	return itineraryTemplateServiceInstance
+}
+
+// FindById retrieves a template of the user or shared by another user. Returns sql.ErrNoRows if there is no such template or it is a
+// private template of another user, so its existence is not disclosed
+func (its *ItineraryTemplateService) FindById(id int64, userId int64) (*models.ItineraryTemplate, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid itinerary template ID")
+	}
+
+	template, err := models.InitItineraryTemplate().FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerID != userId && !template.Shared {
+		log.Warnf("User %d cannot see private itinerary template %d", userId, id)
+		return nil, sql.ErrNoRows
+	}
+
+	return models.InitItineraryTemplateFunctions(template), nil
+}
+
+// FindAvailable retrieves the templates of the user and the ones shared by other users
+func (its *ItineraryTemplateService) FindAvailable(userId int64) ([]*models.ItineraryTemplate, error) {
+	return models.InitItineraryTemplate().FindAvailable(userId)
+}
+
+// Create saves the template for its owner, recording it in the audit log. The template is validated by placing it on the calendar, so
+// it follows the same rules as the destinations of an itinerary
+func (its *ItineraryTemplateService) Create(template *models.ItineraryTemplate, actorId int64) error {
+	if template == nil {
+		log.Error("Itinerary template instance is nil")
+		return errors.New("itinerary template instance is nil")
+	}
+
+	for _, destination := range template.Destinations {
+		if destination.StartDay < 0 || destination.Days < 0 {
+			return errors.New("invalid itinerary template: the days of the destinations cannot be negative")
+		}
+	}
+
+	err := GetItineraryService().ValidateItineraryDestinationsDates(template.TravelDestinations(time.Now()))
+	if err != nil {
+		return fmt.Errorf("invalid itinerary template: %w", err)
+	}
+
+	template = models.InitItineraryTemplateFunctions(template)
+	err = template.Create()
+	if err != nil {
+		log.Errorf("Error saving itinerary template %s: %v", template.Title, err)
+		return errors.New("failed to save itinerary template")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryTemplateCreated, fmt.Sprintf("Itinerary template %d created.", template.ID),
+		map[string]any{"templateId": template.ID, "title": template.Title, "shared": template.Shared})
+}
+
+// Delete deletes a template of the user, recording it in the audit log. The itineraries created from it are kept. Returns
+// ErrItineraryTemplateNotOwned if the template was shared by another user
+func (its *ItineraryTemplateService) Delete(template *models.ItineraryTemplate, actorId int64) error {
+	if template == nil {
+		log.Error("Itinerary template instance is nil")
+		return errors.New("itinerary template instance is nil")
+	}
+	if template.OwnerID != actorId {
+		log.Errorf("User %d cannot delete itinerary template %d of user %d", actorId, template.ID, template.OwnerID)
+		return ErrItineraryTemplateNotOwned
+	}
+
+	template = models.InitItineraryTemplateFunctions(template)
+	err := template.Delete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting itinerary template %d: %v", template.ID, err)
+		return errors.New("failed to delete itinerary template")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryTemplateDeleted, fmt.Sprintf("Itinerary template %d deleted.", template.ID),
+		map[string]any{"templateId": template.ID})
+}
+
+// Instantiate creates an itinerary of the user from the template, starting on the start date. The itinerary takes the title of the
+// template unless another one is given
+func (its *ItineraryTemplateService) Instantiate(template *models.ItineraryTemplate, startDate time.Time, title string, ownerId int64) (*models.Itinerary, error) {
+	if template == nil {
+		log.Error("Itinerary template instance is nil")
+		return nil, errors.New("itinerary template instance is nil")
+	}
+
+	if title == "" {
+		title = template.Title
+	}
+
+	itinerary := models.NewItinerary(title, template.Description, template.Notes, template.TravelDestinations(startDate))
+	itinerary.OwnerID = ownerId
+
+	itineraryService := GetItineraryService()
+	err := itineraryService.ValidateItineraryDestinationsDates(itinerary.TravelDestinations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid itinerary template: %w", err)
+	}
+
+	err = itinerary.Create()
+	if err != nil {
+		log.Errorf("Error creating itinerary from template %d: %v", template.ID, err)
+		return nil, errors.New("failed to create itinerary")
+	}
+
+	indexItinerary(itinerary.ID)
+	err = saveAuditEvent(ownerId, models.AuditEventItineraryCreated,
+		fmt.Sprintf("Itinerary %d created from itinerary template %d.", itinerary.ID, template.ID),
+		map[string]any{"itineraryId": itinerary.ID, "title": itinerary.Title, "templateId": template.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return itinerary, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockStoredItineraryTemplates stores the itinerary templates in memory, numbering the created ones like the database does
+func mockStoredItineraryTemplates(t *testing.T, stored *[]*models.ItineraryTemplate) {
+	orig := models.InitItineraryTemplate
+	origFunctions := models.InitItineraryTemplateFunctions
+	models.InitItineraryTemplateFunctions = func(template *models.ItineraryTemplate) *models.ItineraryTemplate {
+		template.FindById = func(id int64) (*models.ItineraryTemplate, error) {
+			for _, candidate := range *stored {
+				if candidate.ID == id {
+					return candidate, nil
+				}
+			}
+			return nil, sql.ErrNoRows
+		}
+		template.FindByOwnerId = func(ownerId int64) ([]*models.ItineraryTemplate, error) {
+			templates := []*models.ItineraryTemplate{}
+			for _, candidate := range *stored {
+				if candidate.OwnerID == ownerId {
+					templates = append(templates, candidate)
+				}
+			}
+			return templates, nil
+		}
+		template.Create = func() error {
+			template.ID = int64(len(*stored) + 1)
+			*stored = append(*stored, template)
+			return nil
+		}
+		template.Delete = func() error {
+			for idx, candidate := range *stored {
+				if candidate.ID == template.ID {
+					*stored = append((*stored)[:idx], (*stored)[idx+1:]...)
+					return nil
+				}
+			}
+			return sql.ErrNoRows
+		}
+		return template
+	}
+	models.InitItineraryTemplate = func() *models.ItineraryTemplate {
+		return models.InitItineraryTemplateFunctions(&models.ItineraryTemplate{})
+	}
+	t.Cleanup(func() {
+		models.InitItineraryTemplate = orig
+		models.InitItineraryTemplateFunctions = origFunctions
+	})
+}
+
+// mockCreatedItineraries records the itineraries created instead of saving them, numbering them from 10, and the itinerary each clone
+// was copied from
+func mockCreatedItineraries(t *testing.T) (*[]*models.Itinerary, map[int64]int64) {
+	created := []*models.Itinerary{}
+	clonedFrom := map[int64]int64{}
+	orig := models.InitItineraryFunctions
+	models.InitItineraryFunctions = func(itinerary *models.Itinerary) *models.Itinerary {
+		itinerary.Create = func() error {
+			itinerary.ID = int64(10 + len(created))
+			itinerary.Version = 1
+			created = append(created, itinerary)
+			return nil
+		}
+		itinerary.CreateClone = func(sourceId int64) error {
+			clonedFrom[int64(10+len(created))] = sourceId
+			return itinerary.Create()
+		}
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItineraryFunctions = orig })
+	return &created, clonedFrom
+}
+
+func newOffsiteTemplate(ownerId int64, shared bool) *models.ItineraryTemplate {
+	return &models.ItineraryTemplate{ID: 1, Title: "Offsite", OwnerID: ownerId, Shared: shared,
+		Destinations: []*models.ItineraryTemplateDestination{
+			{Country: "Portugal", City: "Lisbon", StartDay: 0, Days: 3},
+			{Country: "Portugal", City: "Porto", StartDay: 3, Days: 2},
+		}}
+}
+
+func TestItineraryTemplateService_FindById_PrivateOfOtherUser(t *testing.T) {
+	stored := []*models.ItineraryTemplate{newOffsiteTemplate(3, false)}
+	mockStoredItineraryTemplates(t, &stored)
+	service := GetItineraryTemplateService()
+
+	template, err := service.FindById(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Offsite", template.Title)
+
+	_, err = service.FindById(1, 4)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	stored[0].Shared = true
+	template, err = service.FindById(1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), template.OwnerID)
+}
+
+func TestItineraryTemplateService_Create_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	stored := []*models.ItineraryTemplate{}
+	mockStoredItineraryTemplates(t, &stored)
+
+	template := newOffsiteTemplate(3, true)
+	template.ID = 0
+	err := GetItineraryTemplateService().Create(template, 3)
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+	assert.Equal(t, int64(1), template.ID)
+	assert.Equal(t, []string{"Itinerary template 1 created."}, *descriptions)
+}
+
+func TestItineraryTemplateService_Create_Invalid(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	stored := []*models.ItineraryTemplate{}
+	mockStoredItineraryTemplates(t, &stored)
+	service := GetItineraryTemplateService()
+
+	tooLong := newOffsiteTemplate(3, false)
+	tooLong.Destinations[1].Days = 40
+	assert.EqualError(t, service.Create(tooLong, 3), "invalid itinerary template: the itinerary cannot span more than 30 days")
+
+	negative := newOffsiteTemplate(3, false)
+	negative.Destinations[0].StartDay = -1
+	assert.ErrorContains(t, service.Create(negative, 3), "invalid itinerary template: ")
+
+	assert.Empty(t, stored)
+	assert.Empty(t, *descriptions)
+}
+
+func TestItineraryTemplateService_Delete_NotOwned(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	stored := []*models.ItineraryTemplate{newOffsiteTemplate(3, true)}
+	mockStoredItineraryTemplates(t, &stored)
+	service := GetItineraryTemplateService()
+
+	err := service.Delete(stored[0], 4)
+	assert.ErrorIs(t, err, ErrItineraryTemplateNotOwned)
+	assert.Len(t, stored, 1)
+
+	err = service.Delete(stored[0], 3)
+	assert.NoError(t, err)
+	assert.Empty(t, stored)
+	assert.Equal(t, []string{"Itinerary template 1 deleted."}, *descriptions)
+}
+
+func TestItineraryTemplateService_Instantiate_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	mockIndexItinerary(t)
+	created, _ := mockCreatedItineraries(t)
+
+	startDate := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	itinerary, err := GetItineraryTemplateService().Instantiate(newOffsiteTemplate(3, true), startDate, "", 4)
+	assert.NoError(t, err)
+	assert.Len(t, *created, 1)
+	assert.Equal(t, int64(4), itinerary.OwnerID)
+	assert.Equal(t, "Offsite", itinerary.Title)
+	assert.Len(t, itinerary.TravelDestinations, 2)
+	assert.Equal(t, startDate, itinerary.TravelDestinations[0].ArrivalDate)
+	assert.Equal(t, time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC), itinerary.TravelDestinations[1].ArrivalDate)
+	assert.Equal(t, time.Date(2025, 6, 7, 0, 0, 0, 0, time.UTC), itinerary.TravelDestinations[1].DepartureDate)
+	assert.Equal(t, []string{"Itinerary 10 created from itinerary template 1."}, *descriptions)
+}
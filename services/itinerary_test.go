@@ -22,7 +22,7 @@ func mockItinerary() *models.Itinerary {
 		return arr, nil
 	}
 	it.Create = func() error { return nil }
-	it.Update = func() error { return nil }
+	it.Update = func(authorId int64) error { return nil }
 	it.Delete = func() error { return nil }
 	return it
 }
@@ -138,7 +138,105 @@ func TestFindByOwnerId_ErrorFromModel(t *testing.T) {
 	}
 }
 
+// mockFindItineraries makes the model return the given itineraries and total count, recording the filters it receives
+func mockFindItineraries(itineraries []*models.Itinerary, totalCount int64, filters *[]models.ItineraryFilter) {
+	it := mockItinerary()
+	it.Find = func(filter models.ItineraryFilter) ([]*models.Itinerary, error) {
+		*filters = append(*filters, filter)
+		return itineraries, nil
+	}
+	it.Count = func(filter models.ItineraryFilter) (int64, error) { return totalCount, nil }
+	models.InitItinerary = func() *models.Itinerary {
+		return it
+	}
+}
+
+func TestFindItineraries_FirstPage(t *testing.T) {
+	svc := &ItineraryService{}
+	filters := []models.ItineraryFilter{}
+	mockFindItineraries([]*models.Itinerary{{ID: 5}, {ID: 4}, {ID: 3}}, 7, &filters)
+
+	page, err := svc.FindItineraries(ItinerariesQuery{OwnerID: 2, Country: "Spain", Limit: 2})
+	if err != nil {
+		t.Fatalf("expected success, got err=%v", err)
+	}
+	if len(page.Itineraries) != 2 || page.TotalCount != 7 || page.NextCursor == "" {
+		t.Errorf("expected 2 itineraries of 7 and a next cursor, got %d of %d and cursor %q", len(page.Itineraries), page.TotalCount, page.NextCursor)
+	}
+	filter := filters[0]
+	if filter.OwnerID != 2 || filter.Country != "Spain" || filter.Limit != 3 || filter.SortBy != models.ItinerarySortCreationDate || filter.Ascending || filter.AfterID != 0 {
+		t.Errorf("unexpected filter %+v", filter)
+	}
+
+	// The cursor continues after the last itinerary of the page
+	_, err = svc.FindItineraries(ItinerariesQuery{OwnerID: 2, Country: "Spain", Limit: 2, Cursor: page.NextCursor})
+	if err != nil || filters[1].AfterID != 4 {
+		t.Errorf("expected the next page after itinerary 4, got err=%v, filter=%+v", err, filters[1])
+	}
+}
+
+func TestFindItineraries_LastPage(t *testing.T) {
+	svc := &ItineraryService{}
+	filters := []models.ItineraryFilter{}
+	mockFindItineraries([]*models.Itinerary{{ID: 5}}, 1, &filters)
+
+	page, err := svc.FindItineraries(ItinerariesQuery{OwnerID: 2, Sort: models.ItinerarySortTravelDate, Order: SortOrderAsc, Limit: 500})
+	if err != nil || page.NextCursor != "" || len(page.Itineraries) != 1 {
+		t.Errorf("expected a last page, got err=%v, page=%+v", err, page)
+	}
+	if filters[0].Limit != maxItinerariesPageSize+1 || filters[0].SortBy != models.ItinerarySortTravelDate || !filters[0].Ascending {
+		t.Errorf("unexpected filter %+v", filters[0])
+	}
+}
+
+func TestFindItineraries_InvalidQuery(t *testing.T) {
+	svc := &ItineraryService{}
+	filters := []models.ItineraryFilter{}
+	mockFindItineraries([]*models.Itinerary{{ID: 5}, {ID: 4}}, 2, &filters)
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	page, err := svc.FindItineraries(ItinerariesQuery{OwnerID: 2, Limit: 1})
+	if err != nil {
+		t.Fatalf("expected success, got err=%v", err)
+	}
+
+	tests := map[string]ItinerariesQuery{
+		"invalid owner ID":   {},
+		"invalid sort field": {OwnerID: 2, Sort: "title"},
+		"invalid sort order": {OwnerID: 2, Order: "up"},
+		"invalid date range": {OwnerID: 2, TravelFrom: &from, TravelTo: &to},
+		"invalid cursor":     {OwnerID: 2, Cursor: "not-a-cursor"},
+	}
+	for expected, query := range tests {
+		if _, err := svc.FindItineraries(query); err == nil || err.Error() != expected {
+			t.Errorf("expected %q, got %v", expected, err)
+		}
+	}
+
+	// A cursor is only valid with the sorting it was generated for
+	_, err = svc.FindItineraries(ItinerariesQuery{OwnerID: 2, Order: SortOrderAsc, Cursor: page.NextCursor})
+	if err == nil || err.Error() != "invalid cursor" {
+		t.Errorf("expected invalid cursor, got %v", err)
+	}
+}
+
+func TestFindItineraries_ErrorFromModel(t *testing.T) {
+	svc := &ItineraryService{}
+	it := mockItinerary()
+	it.Find = func(filter models.ItineraryFilter) ([]*models.Itinerary, error) { return nil, errors.New("fail") }
+	models.InitItinerary = func() *models.Itinerary {
+		return it
+	}
+	page, err := svc.FindItineraries(ItinerariesQuery{OwnerID: 2})
+	if err == nil || page != nil {
+		t.Errorf("expected error from model")
+	}
+}
+
 func TestCreate_Success(t *testing.T) {
+	indexed := mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
 	svc := &ItineraryService{}
 	it := mockItinerary()
 	it.Create = func() error { return nil }
@@ -146,6 +244,12 @@ func TestCreate_Success(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected success, got err=%v", err)
 	}
+	if len(*descriptions) != 1 {
+		t.Errorf("expected the creation to be audited")
+	}
+	if len(*indexed) != 1 {
+		t.Errorf("expected the itinerary to be indexed")
+	}
 }
 
 func TestCreate_NilItinerary(t *testing.T) {
@@ -166,19 +270,98 @@ func TestCreate_ErrorFromModel(t *testing.T) {
 	}
 }
 
+func newCloneSource() *models.Itinerary {
+	notes := "Bring the passports"
+	return &models.Itinerary{ID: 4, Title: "Spain", Description: "Summer trip", Notes: &notes, OwnerID: 1,
+		TravelDestinations: []*models.ItineraryTravelDestination{
+			models.NewItineraryTravelDestination("Spain", "Barcelona", time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 7, 7, 0, 0, 0, 0, time.UTC)),
+			models.NewItineraryTravelDestination("Spain", "Madrid", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC)),
+		}}
+}
+
+func TestClone_ShiftDays(t *testing.T) {
+	indexed := mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
+	_, clonedFrom := mockCreatedItineraries(t)
+	svc := &ItineraryService{}
+
+	clone, err := svc.Clone(newCloneSource(), 2, CloneOptions{ShiftDays: 7})
+	if err != nil {
+		t.Fatalf("expected success, got err=%v", err)
+	}
+	if clone.OwnerID != 2 || clone.Title != "Spain" || clone.Notes == nil || *clone.Notes != "Bring the passports" {
+		t.Errorf("expected a copy owned by the user, got %+v", clone)
+	}
+	if !clone.TravelDestinations[0].ArrivalDate.Equal(time.Date(2025, 7, 11, 0, 0, 0, 0, time.UTC)) ||
+		!clone.TravelDestinations[1].DepartureDate.Equal(time.Date(2025, 7, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the destinations to be shifted by 7 days")
+	}
+	if clonedFrom[clone.ID] != 4 {
+		t.Errorf("expected the preferences to be copied from itinerary 4, got %d", clonedFrom[clone.ID])
+	}
+	if len(*indexed) != 1 {
+		t.Errorf("expected the clone to be indexed")
+	}
+	if len(*descriptions) != 1 || (*descriptions)[0] != "Itinerary 10 created as a copy of itinerary 4." {
+		t.Errorf("expected the clone to be audited, got %v", *descriptions)
+	}
+}
+
+func TestClone_StartDate(t *testing.T) {
+	mockIndexItinerary(t)
+	mockSaveAuditEvent(t, nil)
+	mockCreatedItineraries(t)
+	svc := &ItineraryService{}
+
+	startDate := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	clone, err := svc.Clone(newCloneSource(), 2, CloneOptions{Title: "Spain again", StartDate: &startDate})
+	if err != nil {
+		t.Fatalf("expected success, got err=%v", err)
+	}
+	if clone.Title != "Spain again" {
+		t.Errorf("expected the given title, got %s", clone.Title)
+	}
+	if !clone.TravelDestinations[1].ArrivalDate.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) ||
+		!clone.TravelDestinations[0].ArrivalDate.Equal(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the earliest destination to start on the start date")
+	}
+}
+
+func TestClone_ErrorFromModel(t *testing.T) {
+	orig := models.InitItineraryFunctions
+	t.Cleanup(func() { models.InitItineraryFunctions = orig })
+	models.InitItineraryFunctions = func(itinerary *models.Itinerary) *models.Itinerary {
+		itinerary.CreateClone = func(sourceId int64) error { return errors.New("fail") }
+		return itinerary
+	}
+	svc := &ItineraryService{}
+
+	_, err := svc.Clone(newCloneSource(), 2, CloneOptions{})
+	if err == nil || err.Error() != "failed to clone itinerary" {
+		t.Errorf("expected error from model, got %v", err)
+	}
+}
+
 func TestUpdate_Success(t *testing.T) {
+	mockIndexItinerary(t)
+	descriptions := mockSaveAuditEvent(t, nil)
 	svc := &ItineraryService{}
 	it := mockItinerary()
-	it.Update = func() error { return nil }
-	err := svc.Update(it)
+	it.Update = func(authorId int64) error { return nil }
+	err := svc.Update(it, 2)
 	if err != nil {
 		t.Errorf("expected success, got err=%v", err)
 	}
+	if len(*descriptions) != 1 {
+		t.Errorf("expected the update to be audited")
+	}
 }
 
 func TestUpdate_NilItinerary(t *testing.T) {
 	svc := &ItineraryService{}
-	err := svc.Update(nil)
+	err := svc.Update(nil, 2)
 	if err == nil {
 		t.Errorf("expected error for nil itinerary")
 	}
@@ -187,26 +370,50 @@ func TestUpdate_NilItinerary(t *testing.T) {
 func TestUpdate_ErrorFromModel(t *testing.T) {
 	svc := &ItineraryService{}
 	it := mockItinerary()
-	it.Update = func() error { return errors.New("fail") }
-	err := svc.Update(it)
+	it.Update = func(authorId int64) error { return errors.New("fail") }
+	err := svc.Update(it, 2)
 	if err == nil {
 		t.Errorf("expected error from model")
 	}
 }
 
 func TestDelete_Success(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
 	svc := &ItineraryService{}
 	it := mockItinerary()
+	models.InitItinerary = func() *models.Itinerary {
+		return it
+	}
 	it.Delete = func() error { return nil }
-	err := svc.Delete(1)
+	err := svc.Delete(1, 3, 2)
 	if err != nil {
 		t.Errorf("expected success, got err=%v", err)
 	}
+	if it.ID != 1 || it.Version != 3 {
+		t.Errorf("expected itinerary 1 to be deleted in version 3, got %d in version %d", it.ID, it.Version)
+	}
+	if len(*descriptions) != 1 || (*descriptions)[0] != "Itinerary 1 deleted." {
+		t.Errorf("expected the deletion to be audited, got %v", *descriptions)
+	}
+}
+
+func TestDelete_AuditFails(t *testing.T) {
+	mockSaveAuditEvent(t, errors.New("error saving audit event"))
+	svc := &ItineraryService{}
+	it := mockItinerary()
+	models.InitItinerary = func() *models.Itinerary {
+		return it
+	}
+	it.Delete = func() error { return nil }
+	err := svc.Delete(1, 1, 2)
+	if err == nil {
+		t.Errorf("expected error from audit")
+	}
 }
 
 func TestDelete_InvalidItineraryId(t *testing.T) {
 	svc := &ItineraryService{}
-	err := svc.Delete(-1)
+	err := svc.Delete(-1, 1, 2)
 	if err == nil {
 		t.Errorf("expected error for nil itinerary")
 	}
@@ -219,7 +426,7 @@ func TestDelete_ErrorFromModel(t *testing.T) {
 		return it
 	}
 	it.Delete = func() error { return errors.New("fail") }
-	err := svc.Delete(1)
+	err := svc.Delete(1, 1, 2)
 	if err == nil {
 		t.Errorf("expected error from model")
 	}
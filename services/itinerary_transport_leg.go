@@ -0,0 +1,158 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type ItineraryTransportLegServiceInterface interface {
+	Add(itinerary *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64, actorId int64) error
+	Update(itinerary *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64, actorId int64) error
+	Delete(itinerary *models.Itinerary, legId int64, actorId int64) error
+}
+
+type ItineraryTransportLegService struct{}
+
+// singleton instance
+var itineraryTransportLegServiceInstance = &ItineraryTransportLegService{}
+
+// GetItineraryTransportLegService returns the singleton instance of ItineraryTransportLegService
+var GetItineraryTransportLegService = func() ItineraryTransportLegServiceInterface {
+	return itineraryTransportLegServiceInstance
+}
+
+// Add adds a transport leg from a destination of an itinerary retrieved with its destinations to the next one, recording the change in
+// the audit log. Returns sql.ErrNoRows if the itinerary has no such destination
+func (itls *ItineraryTransportLegService) Add(itinerary *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64, actorId int64) error {
+	if itinerary == nil || leg == nil {
+		log.Error("Itinerary or transport leg instance is nil")
+		return errors.New("itinerary or transport leg instance is nil")
+	}
+
+	err := connectTransportLeg(itinerary, leg, fromDestinationId)
+	if err != nil {
+		return err
+	}
+
+	leg = models.InitItineraryTransportLegFunctions(leg)
+	leg.ItineraryID = itinerary.ID
+	err = leg.Create()
+	if err != nil {
+		log.Errorf("Error adding transport leg to itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to save transport leg")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Transport leg %d of itinerary %d added.", leg.ID, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "transportLegId": leg.ID, "transportLeg": "added"})
+}
+
+// Update replaces a transport leg of an itinerary retrieved with its destinations and transport legs, which goes from a destination
+// to the next one, recording the change in the audit log. Returns sql.ErrNoRows if the itinerary has no such leg or destination
+func (itls *ItineraryTransportLegService) Update(itinerary *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64, actorId int64) error {
+	if itinerary == nil || leg == nil {
+		log.Error("Itinerary or transport leg instance is nil")
+		return errors.New("itinerary or transport leg instance is nil")
+	}
+
+	if findTransportLegIndex(itinerary, leg.ID) < 0 {
+		return sql.ErrNoRows
+	}
+
+	err := connectTransportLeg(itinerary, leg, fromDestinationId)
+	if err != nil {
+		return err
+	}
+
+	leg = models.InitItineraryTransportLegFunctions(leg)
+	leg.ItineraryID = itinerary.ID
+	err = leg.Update()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error updating transport leg %d of itinerary %d: %v", leg.ID, itinerary.ID, err)
+		return errors.New("failed to save transport leg")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Transport leg %d of itinerary %d updated.", leg.ID, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "transportLegId": leg.ID, "transportLeg": "updated"})
+}
+
+// Delete removes a transport leg of an itinerary, recording the change in the audit log. Returns sql.ErrNoRows if the itinerary has
+// no such leg
+func (itls *ItineraryTransportLegService) Delete(itinerary *models.Itinerary, legId int64, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	leg := models.InitItineraryTransportLeg()
+	leg.ID = legId
+	leg.ItineraryID = itinerary.ID
+	err := leg.Delete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting transport leg %d of itinerary %d: %v", legId, itinerary.ID, err)
+		return errors.New("failed to delete transport leg")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Transport leg %d of itinerary %d removed.", legId, itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "transportLegId": legId, "transportLeg": "removed"})
+}
+
+// connectTransportLeg validates the leg as the one from a destination of the itinerary to the next one, setting the countries and
+// cities it connects. The leg must depart during the stay in the destination it goes from and arrive at the latest on the departure
+// date from the one it goes to, with the dates of the times taken in their own time zones
+func connectTransportLeg(itinerary *models.Itinerary, leg *models.ItineraryTransportLeg, fromDestinationId int64) error {
+	index := findDestinationIndex(itinerary, fromDestinationId)
+	if index < 0 {
+		return sql.ErrNoRows
+	}
+	if index == len(itinerary.TravelDestinations)-1 {
+		return fmt.Errorf("invalid transport leg: destination %d is the last one of the itinerary", fromDestinationId)
+	}
+	from := itinerary.TravelDestinations[index]
+	to := itinerary.TravelDestinations[index+1]
+
+	if !slices.Contains(models.TransportModes, leg.Mode) {
+		return fmt.Errorf("invalid transport leg: unknown mode %s", leg.Mode)
+	}
+	if leg.DepartureTime != nil && leg.ArrivalTime != nil && !leg.DepartureTime.Before(*leg.ArrivalTime) {
+		return errors.New("invalid transport leg: the departure must be before the arrival")
+	}
+	if leg.DepartureTime != nil &&
+		(calendarDate(*leg.DepartureTime) < calendarDate(from.ArrivalDate.UTC()) || calendarDate(*leg.DepartureTime) > calendarDate(from.DepartureDate.UTC())) {
+		return fmt.Errorf("invalid transport leg: the departure must be between the arrival in and the departure from %s", from.City)
+	}
+	if leg.ArrivalTime != nil &&
+		(calendarDate(*leg.ArrivalTime) < calendarDate(from.ArrivalDate.UTC()) || calendarDate(*leg.ArrivalTime) > calendarDate(to.DepartureDate.UTC())) {
+		return fmt.Errorf("invalid transport leg: the arrival must be between the arrival in %s and the departure from %s", from.City, to.City)
+	}
+
+	leg.FromCountry = from.Country
+	leg.FromCity = from.City
+	leg.ToCountry = to.Country
+	leg.ToCity = to.City
+	leg.FromDestinationID = &from.ID
+	leg.ToDestinationID = &to.ID
+	return nil
+}
+
+// calendarDate returns the date of a time in its time zone, in a format sorted like the dates
+func calendarDate(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func findTransportLegIndex(itinerary *models.Itinerary, legId int64) int {
+	return slices.IndexFunc(itinerary.TransportLegs, func(leg *models.ItineraryTransportLeg) bool {
+		return leg.ID == legId
+	})
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockStoredTransportLegs makes the changes of the transport legs succeed, or fail with sql.ErrNoRows if notFound, and returns the
+// saved legs
+func mockStoredTransportLegs(t *testing.T, notFound bool) *[]*models.ItineraryTransportLeg {
+	saved := []*models.ItineraryTransportLeg{}
+	orig := models.InitItineraryTransportLeg
+	origFunctions := models.InitItineraryTransportLegFunctions
+	initFunctions := func(l *models.ItineraryTransportLeg) *models.ItineraryTransportLeg {
+		save := func() error {
+			if notFound {
+				return sql.ErrNoRows
+			}
+			saved = append(saved, l)
+			return nil
+		}
+		l.Create = func() error {
+			l.ID = 7
+			return save()
+		}
+		l.Update = save
+		l.Delete = save
+		return l
+	}
+	models.InitItineraryTransportLegFunctions = initFunctions
+	models.InitItineraryTransportLeg = func() *models.ItineraryTransportLeg {
+		return initFunctions(&models.ItineraryTransportLeg{})
+	}
+	t.Cleanup(func() {
+		models.InitItineraryTransportLeg = orig
+		models.InitItineraryTransportLegFunctions = origFunctions
+	})
+	return &saved
+}
+
+func transportLegTime(day int, hour int) *time.Time {
+	t := time.Date(2024, time.July, day, hour, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	return &t
+}
+
+func TestItineraryTransportLegService_Add(t *testing.T) {
+	saved := mockStoredTransportLegs(t, false)
+	descriptions := mockSaveAuditEvent(t, nil)
+	leg := &models.ItineraryTransportLeg{Mode: models.TransportModeTrain, Carrier: "Renfe", DepartureTime: transportLegTime(5, 9),
+		ArrivalTime: transportLegTime(5, 12)}
+
+	err := GetItineraryTransportLegService().Add(newDestinationsItinerary(), leg, 10, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.ItineraryTransportLeg{leg}, *saved)
+	assert.Equal(t, int64(1), leg.ItineraryID)
+	assert.Equal(t, "Madrid", leg.FromCity)
+	assert.Equal(t, "Seville", leg.ToCity)
+	assert.Equal(t, int64(10), *leg.FromDestinationID)
+	assert.Equal(t, int64(11), *leg.ToDestinationID)
+	assert.Equal(t, []string{"Transport leg 7 of itinerary 1 added."}, *descriptions)
+}
+
+func TestItineraryTransportLegService_Add_Invalid(t *testing.T) {
+	saved := mockStoredTransportLegs(t, false)
+	for _, test := range []struct {
+		fromDestinationId int64
+		leg               *models.ItineraryTransportLeg
+		err               string
+	}{
+		{11, &models.ItineraryTransportLeg{Mode: models.TransportModeTrain},
+			"invalid transport leg: destination 11 is the last one of the itinerary"},
+		{10, &models.ItineraryTransportLeg{Mode: "teleport"}, "invalid transport leg: unknown mode teleport"},
+		{10, &models.ItineraryTransportLeg{Mode: models.TransportModeBus, DepartureTime: transportLegTime(5, 12), ArrivalTime: transportLegTime(5, 9)},
+			"invalid transport leg: the departure must be before the arrival"},
+		{10, &models.ItineraryTransportLeg{Mode: models.TransportModeBus, DepartureTime: transportLegTime(6, 9)},
+			"invalid transport leg: the departure must be between the arrival in and the departure from Madrid"},
+		{10, &models.ItineraryTransportLeg{Mode: models.TransportModeBus, ArrivalTime: transportLegTime(9, 9)},
+			"invalid transport leg: the arrival must be between the arrival in Madrid and the departure from Seville"},
+	} {
+		err := GetItineraryTransportLegService().Add(newDestinationsItinerary(), test.leg, test.fromDestinationId, 2)
+		assert.EqualError(t, err, test.err)
+	}
+	assert.Empty(t, *saved)
+}
+
+func TestItineraryTransportLegService_Add_DestinationNotFound(t *testing.T) {
+	saved := mockStoredTransportLegs(t, false)
+
+	err := GetItineraryTransportLegService().Add(newDestinationsItinerary(), &models.ItineraryTransportLeg{Mode: models.TransportModeCar}, 99, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *saved)
+}
+
+func TestItineraryTransportLegService_Update(t *testing.T) {
+	saved := mockStoredTransportLegs(t, false)
+	descriptions := mockSaveAuditEvent(t, nil)
+	itinerary := newDestinationsItinerary()
+	itinerary.TransportLegs = []*models.ItineraryTransportLeg{{ID: 3, Mode: models.TransportModeBus}}
+
+	err := GetItineraryTransportLegService().Update(itinerary, &models.ItineraryTransportLeg{ID: 3, Mode: models.TransportModeFlight}, 10, 2)
+	assert.NoError(t, err)
+	assert.Len(t, *saved, 1)
+	assert.Equal(t, models.TransportModeFlight, (*saved)[0].Mode)
+	assert.Equal(t, []string{"Transport leg 3 of itinerary 1 updated."}, *descriptions)
+}
+
+func TestItineraryTransportLegService_Update_NotFound(t *testing.T) {
+	saved := mockStoredTransportLegs(t, false)
+
+	err := GetItineraryTransportLegService().Update(newDestinationsItinerary(), &models.ItineraryTransportLeg{ID: 3, Mode: models.TransportModeBus}, 10, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *saved)
+}
+
+func TestItineraryTransportLegService_Delete_NotFound(t *testing.T) {
+	mockStoredTransportLegs(t, true)
+	descriptions := mockSaveAuditEvent(t, nil)
+
+	err := GetItineraryTransportLegService().Delete(newDestinationsItinerary(), 3, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *descriptions)
+}
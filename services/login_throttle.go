@@ -0,0 +1,305 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type LoginThrottleServiceInterface interface {
+	CheckLoginAllowed(email string, sourceIp string) (time.Duration, error)
+	RegisterFailedLogin(email string, sourceIp string) error
+	ResetFailedLogins(email string) error
+	UnlockAccount(email string) error
+	CheckSharePasswordAllowed(shareLinkId int64, sourceIp string) (time.Duration, error)
+	RegisterFailedSharePassword(shareLinkId int64, sourceIp string) error
+	ResetFailedSharePasswords(shareLinkId int64, sourceIp string) error
+	DeleteExpiredAttempts() error
+}
+
+type LoginThrottleService struct{}
+
+// singleton instance
+var loginThrottleServiceInstance = &LoginThrottleService{}
+
+// GetLoginThrottleService returns the singleton instance of LoginThrottleService
+var GetLoginThrottleService = func() LoginThrottleServiceInterface {
+	return loginThrottleServiceInstance
+}
+
+const (
+	accountAttemptKeyPrefix        = "account:"
+	ipAttemptKeyPrefix             = "ip:"
+	shareLinkAttemptKeyPrefix      = "share_link:"
+	shareLinkAnyIpAttemptKeyPrefix = "share_link_any_ip:"
+)
+
+type loginThrottleConfig struct {
+	attemptsBeforeDelay  int
+	baseDelay            time.Duration
+	maxAccountAttempts   int
+	maxIpAttempts        int
+	maxShareLinkAttempts int
+	lockoutDuration      time.Duration
+}
+
+// CheckLoginAllowed returns how long the caller must wait before trying to log in again with the given email from the given
+// source IP. A zero duration means the login attempt can go on. Accounts are tracked by email whether they exist or not, so the
+// result never reveals if an account is registered.
+func (lts *LoginThrottleService) CheckLoginAllowed(email string, sourceIp string) (time.Duration, error) {
+	return checkAttemptsAllowed(buildLoginAttemptKeys(email, sourceIp))
+}
+
+// RegisterFailedLogin increases the failed login counters of the account and the source IP. Once a counter goes over the
+// configured free attempts, the next attempt is delayed exponentially, and once it reaches the maximum allowed attempts the key is
+// locked out for the configured lockout period.
+func (lts *LoginThrottleService) RegisterFailedLogin(email string, sourceIp string) error {
+	return registerFailedAttempts(buildLoginAttemptKeys(email, sourceIp))
+}
+
+// ResetFailedLogins clears the failed login counter of an account after a successful login. The source IP counter is kept on
+// purpose, since a single valid login from a shared IP must not clear the attempts made against other accounts.
+func (lts *LoginThrottleService) ResetFailedLogins(email string) error {
+	if email == "" {
+		log.Error("Email cannot be empty")
+		return errors.New("email cannot be empty")
+	}
+
+	err := models.InitLoginAttempt().DeleteByKey(buildAccountAttemptKey(email))
+	if err != nil {
+		log.Errorf("Error resetting failed logins: %v", err)
+		return errors.New("error resetting failed logins")
+	}
+
+	return nil
+}
+
+// UnlockAccount removes any delay or lockout applied to an account
+func (lts *LoginThrottleService) UnlockAccount(email string) error {
+	if email == "" {
+		log.Error("Email cannot be empty")
+		return errors.New("email cannot be empty")
+	}
+
+	err := models.InitLoginAttempt().DeleteByKey(buildAccountAttemptKey(email))
+	if err != nil {
+		log.Errorf("Error unlocking account: %v", err)
+		return errors.New("error unlocking account")
+	}
+
+	return nil
+}
+
+// CheckSharePasswordAllowed returns how long the caller must wait before trying again the password of a share link from the given
+// source IP, the same way as CheckLoginAllowed. The wrong passwords of the link are also counted from every IP, so a link can be
+// locked out even for clients that never failed. A zero duration means the password can be checked.
+func (lts *LoginThrottleService) CheckSharePasswordAllowed(shareLinkId int64, sourceIp string) (time.Duration, error) {
+	return checkAttemptsAllowed(buildShareLinkAttemptKeys(shareLinkId, sourceIp))
+}
+
+// RegisterFailedSharePassword increases the failed password counters of a share link, for the source IP and for every IP. Both are
+// delayed and locked out like the failed logins of an account, the counter of every IP with the maximum allowed attempts per link.
+func (lts *LoginThrottleService) RegisterFailedSharePassword(shareLinkId int64, sourceIp string) error {
+	return registerFailedAttempts(buildShareLinkAttemptKeys(shareLinkId, sourceIp))
+}
+
+// ResetFailedSharePasswords clears the failed password counter of a share link and source IP after a valid password. The counter of
+// every IP is kept on purpose, since the password is known by every viewer of the link and their valid passwords must not clear the
+// attempts made from other IPs.
+func (lts *LoginThrottleService) ResetFailedSharePasswords(shareLinkId int64, sourceIp string) error {
+	err := models.InitLoginAttempt().DeleteByKey(buildShareLinkAttemptKey(shareLinkId, sourceIp))
+	if err != nil {
+		log.Errorf("Error resetting failed share link passwords: %v", err)
+		return errors.New("error resetting failed share link passwords")
+	}
+
+	return nil
+}
+
+// DeleteExpiredAttempts deletes the failed login counters that are no longer locked and that would be forgotten anyway on the next
+// failure, since a whole lockout period has passed since their last failure
+func (lts *LoginThrottleService) DeleteExpiredAttempts() error {
+	config, err := getLoginThrottleConfig()
+	if err != nil {
+		log.Errorf("Error reading login throttle configuration: %v", err)
+		return errors.New("error reading login throttle configuration")
+	}
+
+	deleted, err := models.InitLoginAttempt().DeleteExpired(time.Now().Add(-config.lockoutDuration))
+	if err != nil {
+		log.Errorf("Error deleting expired login attempts: %v", err)
+		return errors.New("error deleting expired login attempts")
+	}
+
+	log.Debugf("Deleted %d expired login attempts", deleted)
+	return nil
+}
+
+// checkAttemptsAllowed returns the longest delay or lockout of the given keys, zero if none of them is delayed
+func checkAttemptsAllowed(attemptKeys []string) (time.Duration, error) {
+	var retryAfter time.Duration
+
+	for _, attemptKey := range attemptKeys {
+		loginAttempt, err := models.InitLoginAttempt().FindByKey(attemptKey)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			log.Errorf("Error retrieving login attempts of key %s: %v", attemptKey, err)
+			return 0, errors.New("error retrieving login attempts")
+		}
+
+		if loginAttempt.LockedUntil != nil {
+			wait := time.Until(*loginAttempt.LockedUntil)
+			if wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// registerFailedAttempts increases the failed counters of the given keys, delaying or locking them out once they go over the limits
+func registerFailedAttempts(attemptKeys []string) error {
+	config, err := getLoginThrottleConfig()
+	if err != nil {
+		log.Errorf("Error reading login throttle configuration: %v", err)
+		return errors.New("error reading login throttle configuration")
+	}
+
+	now := time.Now()
+
+	for _, attemptKey := range attemptKeys {
+		// The counter is increased atomically, and forgotten once a whole lockout period has passed since the last failure
+		failedCount, err := models.InitLoginAttempt().RegisterFailure(attemptKey, now, now.Add(-config.lockoutDuration))
+		if err != nil {
+			log.Errorf("Error saving login attempts of key %s: %v", attemptKey, err)
+			return errors.New("error saving login attempts")
+		}
+
+		maxAttempts := config.maxAccountAttempts
+		if strings.HasPrefix(attemptKey, ipAttemptKeyPrefix) {
+			maxAttempts = config.maxIpAttempts
+		} else if strings.HasPrefix(attemptKey, shareLinkAnyIpAttemptKeyPrefix) {
+			maxAttempts = config.maxShareLinkAttempts
+		}
+
+		lockedUntil := computeLoginLockedUntil(now, failedCount, maxAttempts, config)
+		if lockedUntil == nil {
+			continue
+		}
+
+		if failedCount == maxAttempts {
+			log.Warnf("Login attempts of key %s locked until %v after %d failed attempts", attemptKey, lockedUntil, failedCount)
+		}
+
+		err = models.InitLoginAttempt().SetLockedUntil(attemptKey, failedCount, lockedUntil)
+		if err != nil {
+			log.Errorf("Error saving login attempts of key %s: %v", attemptKey, err)
+			return errors.New("error saving login attempts")
+		}
+	}
+
+	return nil
+}
+
+func buildAccountAttemptKey(email string) string {
+	return accountAttemptKeyPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+func buildLoginAttemptKeys(email string, sourceIp string) []string {
+	attemptKeys := []string{buildAccountAttemptKey(email)}
+	if sourceIp != "" {
+		attemptKeys = append(attemptKeys, ipAttemptKeyPrefix+sourceIp)
+	}
+	return attemptKeys
+}
+
+func buildShareLinkAttemptKey(shareLinkId int64, sourceIp string) string {
+	return shareLinkAttemptKeyPrefix + strconv.FormatInt(shareLinkId, 10) + ":" + sourceIp
+}
+
+func buildShareLinkAttemptKeys(shareLinkId int64, sourceIp string) []string {
+	return []string{buildShareLinkAttemptKey(shareLinkId, sourceIp), shareLinkAnyIpAttemptKeyPrefix + strconv.FormatInt(shareLinkId, 10)}
+}
+
+func computeLoginLockedUntil(now time.Time, failedCount int, maxAttempts int, config *loginThrottleConfig) *time.Time {
+	if failedCount >= maxAttempts {
+		lockedUntil := now.Add(config.lockoutDuration)
+		return &lockedUntil
+	}
+
+	if failedCount <= config.attemptsBeforeDelay {
+		return nil
+	}
+
+	// The delay doubles with every attempt over the free ones and never goes over the lockout period
+	delay := config.baseDelay
+	for i := config.attemptsBeforeDelay + 1; i < failedCount && delay < config.lockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > config.lockoutDuration {
+		delay = config.lockoutDuration
+	}
+
+	lockedUntil := now.Add(delay)
+	return &lockedUntil
+}
+
+func getLoginThrottleConfig() (*loginThrottleConfig, error) {
+	attemptsBeforeDelay, err := getIntEnvOrDefault("LOGIN_FAILED_ATTEMPTS_BEFORE_DELAY", 3)
+	if err != nil {
+		return nil, err
+	}
+	baseDelaySeconds, err := getIntEnvOrDefault("LOGIN_FAILED_ATTEMPTS_BASE_DELAY_SECONDS", 2)
+	if err != nil {
+		return nil, err
+	}
+	maxAccountAttempts, err := getIntEnvOrDefault("LOGIN_MAX_FAILED_ATTEMPTS_PER_ACCOUNT", 10)
+	if err != nil {
+		return nil, err
+	}
+	maxIpAttempts, err := getIntEnvOrDefault("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 50)
+	if err != nil {
+		return nil, err
+	}
+	maxShareLinkAttempts, err := getIntEnvOrDefault("SHARE_LINK_MAX_FAILED_ATTEMPTS_PER_LINK", 50)
+	if err != nil {
+		return nil, err
+	}
+	lockoutMinutes, err := getIntEnvOrDefault("LOGIN_LOCKOUT_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loginThrottleConfig{
+		attemptsBeforeDelay:  attemptsBeforeDelay,
+		baseDelay:            time.Duration(baseDelaySeconds) * time.Second,
+		maxAccountAttempts:   maxAccountAttempts,
+		maxIpAttempts:        maxIpAttempts,
+		maxShareLinkAttempts: maxShareLinkAttempts,
+		lockoutDuration:      time.Duration(lockoutMinutes) * time.Minute,
+	}, nil
+}
+
+func getIntEnvOrDefault(envName string, defaultValue int) (int, error) {
+	valueStr := os.Getenv(envName)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		log.Errorf("The format of %s environment property is incorrect: %v", envName, err)
+		return 0, err
+	}
+
+	return value, nil
+}
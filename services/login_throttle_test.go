@@ -0,0 +1,244 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- Mocks ---
+
+// mockLoginAttemptStore returns login attempt entities backed by an in-memory map
+func mockLoginAttemptStore(store map[string]*models.LoginAttempt) func() *models.LoginAttempt {
+	return func() *models.LoginAttempt {
+		loginAttempt := &models.LoginAttempt{}
+		loginAttempt.FindByKey = func(attemptKey string) (*models.LoginAttempt, error) {
+			stored, ok := store[attemptKey]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			found := *stored
+			return &found, nil
+		}
+		loginAttempt.DeleteByKey = func(attemptKey string) error {
+			delete(store, attemptKey)
+			return nil
+		}
+		loginAttempt.RegisterFailure = func(attemptKey string, failureDate time.Time, resetBefore time.Time) (int, error) {
+			stored, ok := store[attemptKey]
+			if !ok {
+				stored = &models.LoginAttempt{AttemptKey: attemptKey}
+				store[attemptKey] = stored
+			}
+			if stored.LastFailureDate != nil && stored.LastFailureDate.Before(resetBefore) {
+				stored.FailedCount = 0
+			}
+			stored.FailedCount++
+			stored.LastFailureDate = &failureDate
+			return stored.FailedCount, nil
+		}
+		loginAttempt.SetLockedUntil = func(attemptKey string, failedCount int, lockedUntil *time.Time) error {
+			if stored, ok := store[attemptKey]; ok && stored.FailedCount == failedCount {
+				stored.LockedUntil = lockedUntil
+			}
+			return nil
+		}
+		loginAttempt.DeleteExpired = func(before time.Time) (int64, error) {
+			var deleted int64
+			for attemptKey, stored := range store {
+				if stored.LastFailureDate.Before(before) && (stored.LockedUntil == nil || stored.LockedUntil.Before(before)) {
+					delete(store, attemptKey)
+					deleted++
+				}
+			}
+			return deleted, nil
+		}
+		return loginAttempt
+	}
+}
+
+func setMockLoginAttemptStore(store map[string]*models.LoginAttempt) func() {
+	origInit := models.InitLoginAttempt
+	models.InitLoginAttempt = mockLoginAttemptStore(store)
+
+	return func() {
+		models.InitLoginAttempt = origInit
+	}
+}
+
+// --- Tests ---
+
+func TestLoginThrottleService_CheckLoginAllowed_NoAttempts(t *testing.T) {
+	restore := setMockLoginAttemptStore(map[string]*models.LoginAttempt{})
+	defer restore()
+
+	retryAfter, err := GetLoginThrottleService().CheckLoginAllowed("test@example.com", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+}
+
+func TestLoginThrottleService_CheckLoginAllowed_LockedIp(t *testing.T) {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	store := map[string]*models.LoginAttempt{
+		"ip:127.0.0.1": {AttemptKey: "ip:127.0.0.1", FailedCount: 50, LockedUntil: &lockedUntil},
+	}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	retryAfter, err := GetLoginThrottleService().CheckLoginAllowed("other@example.com", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Greater(t, retryAfter, 9*time.Minute)
+}
+
+func TestLoginThrottleService_CheckLoginAllowed_ExpiredLock(t *testing.T) {
+	lockedUntil := time.Now().Add(-1 * time.Minute)
+	store := map[string]*models.LoginAttempt{
+		"account:test@example.com": {AttemptKey: "account:test@example.com", FailedCount: 10, LockedUntil: &lockedUntil},
+	}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	retryAfter, err := GetLoginThrottleService().CheckLoginAllowed("Test@Example.com ", "")
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, retryAfter, time.Duration(0))
+}
+
+func TestLoginThrottleService_CheckLoginAllowed_FindError(t *testing.T) {
+	origInit := models.InitLoginAttempt
+	defer func() { models.InitLoginAttempt = origInit }()
+	models.InitLoginAttempt = func() *models.LoginAttempt {
+		return &models.LoginAttempt{
+			FindByKey: func(attemptKey string) (*models.LoginAttempt, error) { return nil, errors.New("db error") },
+		}
+	}
+
+	_, err := GetLoginThrottleService().CheckLoginAllowed("test@example.com", "127.0.0.1")
+	assert.Error(t, err)
+	assert.Equal(t, "error retrieving login attempts", err.Error())
+}
+
+func TestLoginThrottleService_RegisterFailedLogin_ProgressiveDelayAndLockout(t *testing.T) {
+	os.Setenv("LOGIN_FAILED_ATTEMPTS_BEFORE_DELAY", "2")
+	os.Setenv("LOGIN_MAX_FAILED_ATTEMPTS_PER_ACCOUNT", "5")
+	defer os.Unsetenv("LOGIN_FAILED_ATTEMPTS_BEFORE_DELAY")
+	defer os.Unsetenv("LOGIN_MAX_FAILED_ATTEMPTS_PER_ACCOUNT")
+
+	store := map[string]*models.LoginAttempt{}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	svc := GetLoginThrottleService()
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, svc.RegisterFailedLogin("test@example.com", "127.0.0.1"))
+	}
+	assert.Equal(t, 2, store["account:test@example.com"].FailedCount)
+	assert.Equal(t, 2, store["ip:127.0.0.1"].FailedCount)
+	assert.Nil(t, store["account:test@example.com"].LockedUntil)
+
+	assert.NoError(t, svc.RegisterFailedLogin("test@example.com", "127.0.0.1"))
+	firstDelay := time.Until(*store["account:test@example.com"].LockedUntil)
+	assert.Greater(t, firstDelay, time.Duration(0))
+
+	assert.NoError(t, svc.RegisterFailedLogin("test@example.com", "127.0.0.1"))
+	secondDelay := time.Until(*store["account:test@example.com"].LockedUntil)
+	assert.Greater(t, secondDelay, firstDelay)
+
+	assert.NoError(t, svc.RegisterFailedLogin("test@example.com", "127.0.0.1"))
+	assert.Greater(t, time.Until(*store["account:test@example.com"].LockedUntil), 14*time.Minute)
+	// The IP limit is higher, so the IP is only delayed at this point
+	assert.Less(t, time.Until(*store["ip:127.0.0.1"].LockedUntil), 14*time.Minute)
+}
+
+func TestLoginThrottleService_RegisterFailedLogin_OldCounterIsForgotten(t *testing.T) {
+	lastFailure := time.Now().Add(-2 * time.Hour)
+	store := map[string]*models.LoginAttempt{
+		"account:test@example.com": {AttemptKey: "account:test@example.com", FailedCount: 9, LastFailureDate: &lastFailure},
+	}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	err := GetLoginThrottleService().RegisterFailedLogin("test@example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, store["account:test@example.com"].FailedCount)
+	assert.Nil(t, store["account:test@example.com"].LockedUntil)
+}
+
+func TestLoginThrottleService_RegisterFailedLogin_InvalidConfig(t *testing.T) {
+	os.Setenv("LOGIN_LOCKOUT_MINUTES", "abc")
+	defer os.Unsetenv("LOGIN_LOCKOUT_MINUTES")
+
+	err := GetLoginThrottleService().RegisterFailedLogin("test@example.com", "127.0.0.1")
+	assert.Error(t, err)
+	assert.Equal(t, "error reading login throttle configuration", err.Error())
+}
+
+func TestLoginThrottleService_ResetFailedLogins(t *testing.T) {
+	store := map[string]*models.LoginAttempt{
+		"account:test@example.com": {AttemptKey: "account:test@example.com", FailedCount: 3},
+		"ip:127.0.0.1":             {AttemptKey: "ip:127.0.0.1", FailedCount: 3},
+	}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	err := GetLoginThrottleService().ResetFailedLogins("TEST@example.com")
+	assert.NoError(t, err)
+	assert.NotContains(t, store, "account:test@example.com")
+	assert.Contains(t, store, "ip:127.0.0.1")
+}
+
+func TestLoginThrottleService_DeleteExpiredAttempts(t *testing.T) {
+	oldFailure := time.Now().Add(-2 * time.Hour)
+	recentFailure := time.Now().Add(-1 * time.Minute)
+	lockedUntil := time.Now().Add(-90 * time.Minute)
+	store := map[string]*models.LoginAttempt{
+		"account:old@example.com":    {AttemptKey: "account:old@example.com", FailedCount: 10, LastFailureDate: &oldFailure, LockedUntil: &lockedUntil},
+		"account:recent@example.com": {AttemptKey: "account:recent@example.com", FailedCount: 2, LastFailureDate: &recentFailure},
+	}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	err := GetLoginThrottleService().DeleteExpiredAttempts()
+	assert.NoError(t, err)
+	assert.NotContains(t, store, "account:old@example.com")
+	assert.Contains(t, store, "account:recent@example.com")
+}
+
+func TestLoginThrottleService_DeleteExpiredAttempts_DeleteError(t *testing.T) {
+	origInit := models.InitLoginAttempt
+	defer func() { models.InitLoginAttempt = origInit }()
+	models.InitLoginAttempt = func() *models.LoginAttempt {
+		return &models.LoginAttempt{
+			DeleteExpired: func(before time.Time) (int64, error) { return 0, errors.New("db error") },
+		}
+	}
+
+	err := GetLoginThrottleService().DeleteExpiredAttempts()
+	assert.Error(t, err)
+	assert.Equal(t, "error deleting expired login attempts", err.Error())
+}
+
+func TestLoginThrottleService_UnlockAccount_EmptyEmail(t *testing.T) {
+	err := GetLoginThrottleService().UnlockAccount("")
+	assert.Error(t, err)
+	assert.Equal(t, "email cannot be empty", err.Error())
+}
+
+func TestLoginThrottleService_UnlockAccount_DeleteError(t *testing.T) {
+	origInit := models.InitLoginAttempt
+	defer func() { models.InitLoginAttempt = origInit }()
+	models.InitLoginAttempt = func() *models.LoginAttempt {
+		return &models.LoginAttempt{
+			DeleteByKey: func(attemptKey string) error { return errors.New("db error") },
+		}
+	}
+
+	err := GetLoginThrottleService().UnlockAccount("test@example.com")
+	assert.Error(t, err)
+	assert.Equal(t, "error unlocking account", err.Error())
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type PermissionServiceInterface interface {
+	GetItineraryPermission(itinerary *models.Itinerary, userId int64) (string, error)
+	CheckItineraryPermission(itinerary *models.Itinerary, userId int64, requiredPermission string) error
+}
+
+type PermissionService struct{}
+
+// singleton instance
+var permissionServiceInstance = &PermissionService{}
+
+// GetPermissionService returns the singleton instance of PermissionService
+var GetPermissionService = func() PermissionServiceInterface {
+	return permissionServiceInstance
+}
+
+// GetItineraryPermission returns the permission of the user on the itinerary: owner, editor or viewer. An empty permission means the
+// user has no access to it
+func (ps *PermissionService) GetItineraryPermission(itinerary *models.Itinerary, userId int64) (string, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return "", errors.New("itinerary instance is nil")
+	}
+
+	if itinerary.OwnerID == userId {
+		return models.ItineraryPermissionOwner, nil
+	}
+
+	share, err := models.InitItineraryShare().FindByItineraryAndUserId(itinerary.ID, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		log.Errorf("Error retrieving the share of itinerary %d with user %d: %v", itinerary.ID, userId, err)
+		return "", errors.New("failed to check permission")
+	}
+
+	return share.Permission, nil
+}
+
+// CheckItineraryPermission fails with a "permission denied" error unless the user has at least the required permission on the itinerary
+func (ps *PermissionService) CheckItineraryPermission(itinerary *models.Itinerary, userId int64, requiredPermission string) error {
+	permission, err := ps.GetItineraryPermission(itinerary, userId)
+	if err != nil {
+		return err
+	}
+
+	if models.ItineraryPermissionLevel(permission) < models.ItineraryPermissionLevel(requiredPermission) {
+		log.Errorf("User %d has permission '%s' on itinerary %d but '%s' is required", userId, permission, itinerary.ID, requiredPermission)
+		return errors.New("permission denied")
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockFindItineraryShare(t *testing.T, share *models.ItineraryShare, err error) {
+	orig := models.InitItineraryShare
+	t.Cleanup(func() { models.InitItineraryShare = orig })
+
+	models.InitItineraryShare = func() *models.ItineraryShare {
+		return &models.ItineraryShare{
+			FindByItineraryAndUserId: func(itineraryId int64, userId int64) (*models.ItineraryShare, error) {
+				return share, err
+			},
+		}
+	}
+}
+
+func TestGetItineraryPermission_Owner(t *testing.T) {
+	permission, err := (&PermissionService{}).GetItineraryPermission(&models.Itinerary{ID: 1, OwnerID: 2}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ItineraryPermissionOwner, permission)
+}
+
+func TestGetItineraryPermission_Shared(t *testing.T) {
+	mockFindItineraryShare(t, &models.ItineraryShare{Permission: models.ItineraryPermissionEditor}, nil)
+
+	permission, err := (&PermissionService{}).GetItineraryPermission(&models.Itinerary{ID: 1, OwnerID: 2}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ItineraryPermissionEditor, permission)
+}
+
+func TestGetItineraryPermission_NotShared(t *testing.T) {
+	mockFindItineraryShare(t, nil, sql.ErrNoRows)
+
+	permission, err := (&PermissionService{}).GetItineraryPermission(&models.Itinerary{ID: 1, OwnerID: 2}, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, permission)
+}
+
+func TestGetItineraryPermission_Error(t *testing.T) {
+	mockFindItineraryShare(t, nil, errors.New("db error"))
+
+	_, err := (&PermissionService{}).GetItineraryPermission(&models.Itinerary{ID: 1, OwnerID: 2}, 3)
+	assert.EqualError(t, err, "failed to check permission")
+}
+
+func TestCheckItineraryPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission string
+		required   string
+		allowed    bool
+	}{
+		{"viewer reads", models.ItineraryPermissionViewer, models.ItineraryPermissionViewer, true},
+		{"viewer edits", models.ItineraryPermissionViewer, models.ItineraryPermissionEditor, false},
+		{"editor edits", models.ItineraryPermissionEditor, models.ItineraryPermissionEditor, true},
+		{"editor deletes", models.ItineraryPermissionEditor, models.ItineraryPermissionOwner, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFindItineraryShare(t, &models.ItineraryShare{Permission: tt.permission}, nil)
+
+			err := (&PermissionService{}).CheckItineraryPermission(&models.Itinerary{ID: 1, OwnerID: 2}, 3, tt.required)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, "permission denied")
+			}
+		})
+	}
+}
+
+func TestCheckItineraryPermission_NoAccess(t *testing.T) {
+	mockFindItineraryShare(t, nil, sql.ErrNoRows)
+
+	err := (&PermissionService{}).CheckItineraryPermission(&models.Itinerary{ID: 1, OwnerID: 2}, 3, models.ItineraryPermissionViewer)
+	assert.EqualError(t, err, "permission denied")
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPlaceSuggestions = 10
+	maxPlaceSuggestions     = 50
+)
+
+type PlaceServiceInterface interface {
+	Suggest(query string, country string, limit int) ([]*models.Place, error)
+}
+
+// PlaceService suggests the cities of the embedded gazetteer destinations are normalized against, so it works offline
+type PlaceService struct{}
+
+// singleton instance
+var placeServiceInstance = &PlaceService{}
+
+// GetPlaceService returns the singleton instance of PlaceService
+var GetPlaceService = func() PlaceServiceInterface {
+	return placeServiceInstance
+}
+
+// Suggest returns the cities whose name, or a word of it, starts with the query, ignoring case and diacritics, the ones named as the
+// query and the most populous first. The country, if any, is the code or any name of the country the cities must be in. Returns at
+// most limit cities, 10 if it is not positive
+func (ps *PlaceService) Suggest(query string, country string, limit int) ([]*models.Place, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("invalid place query: the query cannot be blank")
+	}
+	if limit <= 0 {
+		limit = defaultPlaceSuggestions
+	}
+	limit = min(limit, maxPlaceSuggestions)
+
+	countryCode := ""
+	if strings.TrimSpace(country) != "" {
+		gazetteerCountry := utils.FindCountry(country)
+		if gazetteerCountry == nil {
+			return nil, fmt.Errorf("invalid place query: unknown country %s", country)
+		}
+		countryCode = gazetteerCountry.Code
+	}
+
+	places := []*models.Place{}
+	for _, city := range utils.SuggestCities(query, countryCode, limit) {
+		places = append(places, &models.Place{
+			City:        city.Name,
+			Country:     utils.FindCountry(city.CountryCode).Name,
+			CountryCode: city.CountryCode,
+			Latitude:    city.Latitude,
+			Longitude:   city.Longitude,
+			TimeZone:    city.TimeZone,
+			Population:  city.Population,
+		})
+	}
+
+	log.Debugf("Suggested %d places for %q", len(places), query)
+	return places, nil
+}
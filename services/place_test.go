@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceService_Suggest(t *testing.T) {
+	places, err := GetPlaceService().Suggest("barc", "", 0)
+
+	assert.NoError(t, err)
+	if assert.Len(t, places, 1) {
+		assert.Equal(t, "Barcelona", places[0].City)
+		assert.Equal(t, "Spain", places[0].Country)
+		assert.Equal(t, "ES", places[0].CountryCode)
+		assert.Equal(t, "Europe/Madrid", places[0].TimeZone)
+		assert.InDelta(t, 41.38879, places[0].Latitude, 0.00001)
+	}
+}
+
+func TestPlaceService_Suggest_Country(t *testing.T) {
+	places, err := GetPlaceService().Suggest("cordoba", "Argentina", 5)
+
+	assert.NoError(t, err)
+	if assert.Len(t, places, 1) {
+		assert.Equal(t, "Córdoba", places[0].City)
+		assert.Equal(t, "AR", places[0].CountryCode)
+	}
+
+	places, err = GetPlaceService().Suggest("cordoba", "", 5)
+	assert.NoError(t, err)
+	assert.Len(t, places, 2)
+}
+
+func TestPlaceService_Suggest_Limit(t *testing.T) {
+	places, err := GetPlaceService().Suggest("s", "", 3)
+	assert.NoError(t, err)
+	assert.Len(t, places, 3)
+
+	places, err = GetPlaceService().Suggest("s", "", 1000)
+	assert.NoError(t, err)
+	assert.Len(t, places, maxPlaceSuggestions)
+}
+
+func TestPlaceService_Suggest_NoMatches(t *testing.T) {
+	places, err := GetPlaceService().Suggest("xyzzy", "", 10)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, places)
+	assert.Empty(t, places)
+}
+
+func TestPlaceService_Suggest_Invalid(t *testing.T) {
+	_, err := GetPlaceService().Suggest(" ", "", 10)
+	assert.ErrorContains(t, err, "invalid place query")
+
+	_, err = GetPlaceService().Suggest("mad", "Atlantis", 10)
+	assert.EqualError(t, err, "invalid place query: unknown country Atlantis")
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+	"time"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultPromptTemplateName is the name of the prompt template the itineraries are generated with unless another one is asked for
+const DefaultPromptTemplateName = "itinerary"
+
+// promptTemplateVariables are the variables the prompt templates can use
+var promptTemplateVariables = []string{"title", "description", "notes", "ownerId", "travelDestinations", "transportLegs", "accommodations",
+	"interests", "travellerProfile", "language", "languageName"}
+
+type PromptTemplateServiceInterface interface {
+	FindById(id int64) (*models.PromptTemplate, error)
+	FindAllLatest(ownerId *int64) ([]*models.PromptTemplate, error)
+	FindVersions(ownerId *int64, name string) ([]*models.PromptTemplate, error)
+	Create(promptTemplate *models.PromptTemplate, actorId int64) error
+	Delete(ownerId *int64, name string, actorId int64) error
+	Resolve(name string, userId int64) (*models.PromptTemplate, error)
+}
+
+type PromptTemplateService struct{}
+
+// singleton instance
+var promptTemplateServiceInstance = &PromptTemplateService{}
+
+// GetPromptTemplateService returns the singleton instance of PromptTemplateService
+var GetPromptTemplateService = func() PromptTemplateServiceInterface {
+	return promptTemplateServiceInstance
+}
+
+// FindById retrieves a version of a prompt template, even if the template was deleted
+func (pts *PromptTemplateService) FindById(id int64) (*models.PromptTemplate, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid prompt template ID")
+	}
+	return models.InitPromptTemplate().FindById(id)
+}
+
+// FindAllLatest retrieves the latest version of every prompt template of the owner, or of every global template if the owner is nil
+func (pts *PromptTemplateService) FindAllLatest(ownerId *int64) ([]*models.PromptTemplate, error) {
+	return models.InitPromptTemplate().FindAllLatest(ownerId)
+}
+
+// FindVersions retrieves the versions of a prompt template of the owner, or of a global template if the owner is nil, from the newest.
+// Returns sql.ErrNoRows if there is no such template
+func (pts *PromptTemplateService) FindVersions(ownerId *int64, name string) ([]*models.PromptTemplate, error) {
+	promptTemplates, err := models.InitPromptTemplate().FindVersions(ownerId, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(promptTemplates) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return promptTemplates, nil
+}
+
+// Create saves the prompt template as the next version of its name and owner, recording the change in the audit log. The template is
+// validated first, so it can only use the variables in promptTemplateVariables and the built-in functions of Go templates
+func (pts *PromptTemplateService) Create(promptTemplate *models.PromptTemplate, actorId int64) error {
+	if promptTemplate == nil {
+		log.Error("Prompt template instance is nil")
+		return errors.New("prompt template instance is nil")
+	}
+
+	err := validatePromptTemplate(promptTemplate.Template)
+	if err != nil {
+		return fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	promptTemplate = models.InitPromptTemplateFunctions(promptTemplate)
+	promptTemplate.AuthorID = actorId
+	err = promptTemplate.Create()
+	if err != nil {
+		log.Errorf("Error saving prompt template %s: %v", promptTemplate.Name, err)
+		return errors.New("failed to save prompt template")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventPromptTemplateSaved,
+		fmt.Sprintf("Version %d of prompt template %s saved.", promptTemplate.Version, promptTemplate.Name),
+		map[string]any{"promptTemplateId": promptTemplate.ID, "name": promptTemplate.Name, "version": promptTemplate.Version,
+			"global": promptTemplate.OwnerID == nil})
+}
+
+// Delete hides all the versions of a prompt template of the owner, or of a global template if the owner is nil, recording the deletion
+// in the audit log. The versions are kept for the jobs generated with them. Returns sql.ErrNoRows if there is no such template
+func (pts *PromptTemplateService) Delete(ownerId *int64, name string, actorId int64) error {
+	promptTemplate := models.InitPromptTemplate()
+	promptTemplate.OwnerID = ownerId
+	promptTemplate.Name = name
+	err := promptTemplate.SoftDelete()
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		log.Errorf("Error deleting prompt template %s: %v", name, err)
+		return errors.New("failed to delete prompt template")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventPromptTemplateDeleted,
+		fmt.Sprintf("Prompt template %s deleted.", name),
+		map[string]any{"name": name, "global": ownerId == nil})
+}
+
+// Resolve retrieves the latest version of the prompt template with the name the user generates itineraries with: their own one, or the
+// global one if they have none. Without a name, the default template is resolved, which is the built-in one if neither the user nor the
+// administrators saved one. Returns sql.ErrNoRows if there is no template with the name
+func (pts *PromptTemplateService) Resolve(name string, userId int64) (*models.PromptTemplate, error) {
+	if name == "" {
+		name = DefaultPromptTemplateName
+	}
+
+	for _, ownerId := range []*int64{&userId, nil} {
+		promptTemplate, err := models.InitPromptTemplate().FindLatest(ownerId, name)
+		if err == nil {
+			return promptTemplate, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Errorf("Error retrieving prompt template %s: %v", name, err)
+			return nil, errors.New("failed to retrieve prompt template")
+		}
+	}
+
+	if name == DefaultPromptTemplateName {
+		return builtInPromptTemplate(), nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// builtInPromptTemplate returns the template the itineraries are generated with when no default one was saved. It has no ID nor version
+func builtInPromptTemplate() *models.PromptTemplate {
+	return &models.PromptTemplate{Name: DefaultPromptTemplateName, SystemMessage: itinerarySystemMessage, Template: itineraryPromptTemplate}
+}
+
+// validatePromptTemplate renders the template for a sample itinerary with and without the optional values. Only the built-in functions
+// of Go templates are available, so the templates cannot use functions like env to leak the environment of the server into the prompts
+func validatePromptTemplate(text string) error {
+	parsed, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+
+	notes := "I want to enjoy the nightlife"
+	two := 2
+	itinerary := &models.Itinerary{ID: 1, Title: "Trip to Spain", Description: "Summer vacation in Spain", Notes: &notes, OwnerID: 1,
+		TravelDestinations: []*models.ItineraryTravelDestination{{Country: "Spain", City: "Madrid", ArrivalDate: time.Now(),
+			DepartureDate: time.Now().Add(72 * time.Hour)}}}
+	departure := time.Now().Add(72 * time.Hour)
+	itinerary.TransportLegs = []*models.ItineraryTransportLeg{{FromDestinationID: &itinerary.ID, FromCity: "Madrid", ToCity: "Barcelona",
+		Mode: models.TransportModeTrain, Carrier: "Renfe", DepartureTime: &departure, Notes: "Seats 4A and 4B"}}
+	itinerary.Accommodations = []*models.DestinationAccommodation{{DestinationID: &itinerary.ID, City: "Madrid", Name: "Hotel Gran Vía",
+		Address: "Gran Vía 25", CheckIn: time.Now(), CheckOut: departure}}
+	preferences := &models.TravellerPreferences{Interests: []string{"museums"}, Pace: models.TravellerPaceRelaxed, Adults: &two,
+		Language: "es"}
+
+	for _, input := range []map[string]any{itineraryPromptInput(itinerary, preferences), itineraryPromptInput(&models.Itinerary{}, nil)} {
+		err = parsed.Execute(io.Discard, input)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPromptTemplateService struct {
+	PromptTemplateService
+	Resolved   *models.PromptTemplate
+	ResolveErr error
+}
+
+func (m *mockPromptTemplateService) Resolve(_ string, _ int64) (*models.PromptTemplate, error) {
+	return m.Resolved, m.ResolveErr
+}
+
+// mockResolvePromptTemplate makes the itineraries be generated with the prompt template, or fail to find it with err
+func mockResolvePromptTemplate(t *testing.T, promptTemplate *models.PromptTemplate, err error) {
+	orig := GetPromptTemplateService
+	GetPromptTemplateService = func() PromptTemplateServiceInterface {
+		return &mockPromptTemplateService{Resolved: promptTemplate, ResolveErr: err}
+	}
+	t.Cleanup(func() { GetPromptTemplateService = orig })
+}
+
+// mockStoredPromptTemplates stores the versions of the prompt templates in memory, numbering the created ones like the database does
+func mockStoredPromptTemplates(t *testing.T, stored *[]*models.PromptTemplate) {
+	sameOwner := func(a *int64, b *int64) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+
+	orig := models.InitPromptTemplate
+	origFunctions := models.InitPromptTemplateFunctions
+	models.InitPromptTemplateFunctions = func(promptTemplate *models.PromptTemplate) *models.PromptTemplate {
+		promptTemplate.FindLatest = func(ownerId *int64, name string) (*models.PromptTemplate, error) {
+			var latest *models.PromptTemplate
+			for _, candidate := range *stored {
+				if sameOwner(candidate.OwnerID, ownerId) && candidate.Name == name && (latest == nil || candidate.Version > latest.Version) {
+					latest = candidate
+				}
+			}
+			if latest == nil {
+				return nil, sql.ErrNoRows
+			}
+			return latest, nil
+		}
+		promptTemplate.FindAllLatest = func(ownerId *int64) ([]*models.PromptTemplate, error) {
+			latest := []*models.PromptTemplate{}
+			for _, candidate := range *stored {
+				found, err := promptTemplate.FindLatest(ownerId, candidate.Name)
+				if err == nil && found == candidate {
+					latest = append(latest, candidate)
+				}
+			}
+			return latest, nil
+		}
+		promptTemplate.Create = func() error {
+			promptTemplate.ID = int64(len(*stored) + 1)
+			promptTemplate.Version = 1
+			for _, candidate := range *stored {
+				if sameOwner(candidate.OwnerID, promptTemplate.OwnerID) && candidate.Name == promptTemplate.Name {
+					promptTemplate.Version = max(promptTemplate.Version, candidate.Version+1)
+				}
+			}
+			*stored = append(*stored, promptTemplate)
+			return nil
+		}
+		promptTemplate.SoftDelete = func() error {
+			return sql.ErrNoRows
+		}
+		return promptTemplate
+	}
+	models.InitPromptTemplate = func() *models.PromptTemplate {
+		return models.InitPromptTemplateFunctions(&models.PromptTemplate{})
+	}
+	t.Cleanup(func() {
+		models.InitPromptTemplate = orig
+		models.InitPromptTemplateFunctions = origFunctions
+	})
+}
+
+func TestPromptTemplateService_Resolve(t *testing.T) {
+	userId, otherUserId := int64(3), int64(4)
+	stored := []*models.PromptTemplate{
+		{ID: 1, Name: DefaultPromptTemplateName, Version: 1},
+		{ID: 2, Name: DefaultPromptTemplateName, Version: 2},
+		{ID: 3, Name: "kids", Version: 1, OwnerID: &userId},
+		{ID: 4, Name: DefaultPromptTemplateName, Version: 1, OwnerID: &userId},
+	}
+	mockStoredPromptTemplates(t, &stored)
+	service := GetPromptTemplateService()
+
+	promptTemplate, err := service.Resolve("", userId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), promptTemplate.ID)
+
+	promptTemplate, err = service.Resolve("", otherUserId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), promptTemplate.ID)
+
+	promptTemplate, err = service.Resolve("kids", userId)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), promptTemplate.ID)
+
+	_, err = service.Resolve("kids", otherUserId)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPromptTemplateService_Resolve_BuiltIn(t *testing.T) {
+	stored := []*models.PromptTemplate{}
+	mockStoredPromptTemplates(t, &stored)
+
+	promptTemplate, err := GetPromptTemplateService().Resolve("", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), promptTemplate.ID)
+	assert.Equal(t, itineraryPromptTemplate, promptTemplate.Template)
+	assert.Equal(t, itinerarySystemMessage, promptTemplate.SystemMessage)
+}
+
+func TestPromptTemplateService_Create_Versions(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	stored := []*models.PromptTemplate{}
+	mockStoredPromptTemplates(t, &stored)
+	userId := int64(3)
+	service := GetPromptTemplateService()
+
+	for _, text := range []string{"Plan {{.title}}", "Plan {{.title}}{{range .travelDestinations}} in {{.city}}{{end}} in {{.language}}"} {
+		err := service.Create(&models.PromptTemplate{Name: "kids", OwnerID: &userId, SystemMessage: "You are a guide.", Template: text}, userId)
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, stored, 2)
+	assert.Equal(t, int64(2), stored[1].Version)
+	assert.Equal(t, userId, stored[1].AuthorID)
+	assert.Equal(t, []string{"Version 1 of prompt template kids saved.", "Version 2 of prompt template kids saved."}, *descriptions)
+}
+
+func TestPromptTemplateService_Create_Invalid(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	stored := []*models.PromptTemplate{}
+	mockStoredPromptTemplates(t, &stored)
+
+	for _, text := range []string{"Plan {{.title}} on {{.budget}}", "Plan {{.title", "Plan {{env \"JWT_SECRET\"}}",
+		"{{range .travelDestinations}}{{.hotel}}{{end}}"} {
+		err := GetPromptTemplateService().Create(&models.PromptTemplate{Name: "itinerary", Template: text}, 1)
+		assert.ErrorContains(t, err, "invalid prompt template: ", text)
+	}
+
+	assert.Empty(t, stored)
+	assert.Empty(t, *descriptions)
+}
+
+func TestPromptTemplateService_Delete_NotFound(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	stored := []*models.PromptTemplate{}
+	mockStoredPromptTemplates(t, &stored)
+
+	err := GetPromptTemplateService().Delete(nil, "itinerary", 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Empty(t, *descriptions)
+}
+
+func TestBuiltInPromptTemplate_Valid(t *testing.T) {
+	assert.NoError(t, validatePromptTemplate(itineraryPromptTemplate))
+}
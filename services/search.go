@@ -0,0 +1,173 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 50
+	maxSearchTerms        = 10
+	minSearchTermLength   = 2
+)
+
+type SearchServiceInterface interface {
+	SearchItineraries(query ItinerarySearchQuery) (*ItinerarySearchPage, error)
+	IndexItinerary(itineraryId int64) error
+}
+
+type SearchService struct{}
+
+// singleton instance
+var searchServiceInstance = &SearchService{}
+
+// GetSearchService returns the singleton instance of SearchService
+var GetSearchService = func() SearchServiceInterface {
+	return searchServiceInstance
+}
+
+// ItinerarySearchQuery holds the text searched among the itineraries owned by or shared with the user
+type ItinerarySearchQuery struct {
+	UserID int64
+	Text   string
+	Cursor string
+	Limit  int
+}
+
+// ItinerarySearchPage is a page of search results from the best to the worst match. NextCursor is empty on the last page
+type ItinerarySearchPage struct {
+	Results    []*models.ItinerarySearchResult
+	NextCursor string
+}
+
+// searchCursor is the offset of the next page. Ranked results have no stable key to continue from, unlike the other listings
+type searchCursor struct {
+	Offset int `json:"offset"`
+}
+
+// SearchItineraries searches the words of the text in the title, description, notes, destinations and latest generated document of the
+// itineraries the user can access. Every word must match, as a whole word or as the beginning of one
+func (ss *SearchService) SearchItineraries(query ItinerarySearchQuery) (*ItinerarySearchPage, error) {
+	if query.UserID <= 0 {
+		log.Error("Invalid user ID provided")
+		return nil, errors.New("invalid user ID")
+	}
+
+	terms := []string{}
+	for _, term := range strings.Fields(query.Text) {
+		if utf8.RuneCountInString(term) >= minSearchTermLength {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 || len(terms) > maxSearchTerms {
+		log.Errorf("invalid search text %q", query.Text)
+		return nil, errors.New("invalid search text")
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchPageSize
+	}
+	if limit > maxSearchPageSize {
+		limit = maxSearchPageSize
+	}
+
+	filter := models.ItinerarySearchFilter{
+		UserID: query.UserID,
+		Terms:  terms,
+		// One more result than requested is fetched to know if there is a next page
+		Limit: limit + 1,
+	}
+
+	if query.Cursor != "" {
+		var cursor searchCursor
+		err := utils.DecodeCursor(query.Cursor, &cursor)
+		if err != nil || cursor.Offset <= 0 {
+			log.Errorf("invalid search cursor %s", query.Cursor)
+			return nil, errors.New("invalid cursor")
+		}
+		filter.Offset = cursor.Offset
+	}
+
+	results, err := models.InitItinerarySearchDocument().Search(filter)
+	if err != nil {
+		log.Errorf("failed to search itineraries: %v", err)
+		return nil, errors.New("failed to search itineraries")
+	}
+
+	page := &ItinerarySearchPage{Results: results}
+	if len(results) > limit {
+		page.Results = results[:limit]
+		page.NextCursor, err = utils.EncodeCursor(searchCursor{Offset: filter.Offset + limit})
+		if err != nil {
+			log.Errorf("failed to encode search cursor: %v", err)
+			return nil, errors.New("failed to encode cursor")
+		}
+	}
+
+	return page, nil
+}
+
+// IndexItinerary updates the search document of the itinerary with its current content and the text of its latest completed job
+func (ss *SearchService) IndexItinerary(itineraryId int64) error {
+	itinerary, err := models.InitItinerary().FindById(itineraryId, true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted itineraries are removed from the index together with them
+			return nil
+		}
+		log.Errorf("Error retrieving itinerary %d to index it: %v", itineraryId, err)
+		return errors.New("failed to index itinerary")
+	}
+
+	document := models.InitItinerarySearchDocument()
+	document.ItineraryID = itinerary.ID
+	document.OwnerID = itinerary.OwnerID
+	document.Title = itinerary.Title
+	document.Description = itinerary.Description
+	if itinerary.Notes != nil {
+		document.Notes = *itinerary.Notes
+	}
+
+	destinations := []string{}
+	for _, destination := range itinerary.TravelDestinations {
+		destinations = append(destinations, destination.City+", "+destination.Country)
+	}
+	document.Destinations = strings.Join(destinations, "; ")
+
+	job, err := findLatestCompletedJob(itinerary.ID)
+	if err != nil {
+		return errors.New("failed to index itinerary")
+	}
+	if job != nil {
+		content, err := readJobFile(job)
+		if err != nil {
+			return errors.New("failed to index itinerary")
+		}
+		document.Content = *content
+	}
+
+	err = document.Save()
+	if err != nil {
+		log.Errorf("Error saving search document of itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to index itinerary")
+	}
+
+	return nil
+}
+
+// indexItinerary refreshes the search document of an itinerary after a change. The index is derived data that is rebuilt on the next
+// change, so failures are logged instead of failing the change itself
+var indexItinerary = func(itineraryId int64) {
+	err := GetSearchService().IndexItinerary(itineraryId)
+	if err != nil {
+		log.Warnf("Itinerary %d could not be indexed for search: %v", itineraryId, err)
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIndexItinerary records the itineraries indexed after a change instead of indexing them
+func mockIndexItinerary(t *testing.T) *[]int64 {
+	indexed := []int64{}
+	orig := indexItinerary
+	indexItinerary = func(itineraryId int64) { indexed = append(indexed, itineraryId) }
+	t.Cleanup(func() { indexItinerary = orig })
+	return &indexed
+}
+
+func mockSearchItineraries(t *testing.T, results []*models.ItinerarySearchResult, err error) *[]models.ItinerarySearchFilter {
+	filters := []models.ItinerarySearchFilter{}
+	orig := models.InitItinerarySearchDocument
+	models.InitItinerarySearchDocument = func() *models.ItinerarySearchDocument {
+		return &models.ItinerarySearchDocument{
+			Search: func(filter models.ItinerarySearchFilter) ([]*models.ItinerarySearchResult, error) {
+				filters = append(filters, filter)
+				return results, err
+			},
+		}
+	}
+	t.Cleanup(func() { models.InitItinerarySearchDocument = orig })
+	return &filters
+}
+
+func TestSearchService_SearchItineraries_Pages(t *testing.T) {
+	filters := mockSearchItineraries(t, []*models.ItinerarySearchResult{{ItineraryID: 3}, {ItineraryID: 1}, {ItineraryID: 2}}, nil)
+
+	page, err := GetSearchService().SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: "  kyoto a  food tour ", Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.Equal(t, models.ItinerarySearchFilter{UserID: 1, Terms: []string{"kyoto", "food", "tour"}, Limit: 3}, (*filters)[0])
+
+	_, err = GetSearchService().SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: "kyoto", Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, (*filters)[1].Offset)
+}
+
+func TestSearchService_SearchItineraries_LastPage(t *testing.T) {
+	filters := mockSearchItineraries(t, []*models.ItinerarySearchResult{{ItineraryID: 3}}, nil)
+
+	page, err := GetSearchService().SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: "kyoto", Limit: 1000})
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, maxSearchPageSize+1, (*filters)[0].Limit)
+}
+
+func TestSearchService_SearchItineraries_InvalidQuery(t *testing.T) {
+	mockSearchItineraries(t, nil, nil)
+	svc := GetSearchService()
+
+	_, err := svc.SearchItineraries(ItinerarySearchQuery{Text: "kyoto"})
+	assert.EqualError(t, err, "invalid user ID")
+	_, err = svc.SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: " a  b "})
+	assert.EqualError(t, err, "invalid search text")
+	_, err = svc.SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: "aa bb cc dd ee ff gg hh ii jj kk"})
+	assert.EqualError(t, err, "invalid search text")
+	_, err = svc.SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: "kyoto", Cursor: "not-a-cursor"})
+	assert.EqualError(t, err, "invalid cursor")
+}
+
+func TestSearchService_SearchItineraries_ModelError(t *testing.T) {
+	mockSearchItineraries(t, nil, errors.New("fts error"))
+
+	page, err := GetSearchService().SearchItineraries(ItinerarySearchQuery{UserID: 1, Text: "kyoto"})
+	assert.Nil(t, page)
+	assert.EqualError(t, err, "failed to search itineraries")
+}
+
+func TestSearchService_IndexItinerary_WithLatestDocument(t *testing.T) {
+	notes := "Street food"
+	origInitItinerary := models.InitItinerary
+	models.InitItinerary = func() *models.Itinerary {
+		itinerary := &models.Itinerary{}
+		itinerary.FindById = func(id int64, includeDestinations bool) (*models.Itinerary, error) {
+			return &models.Itinerary{ID: id, OwnerID: 1, Title: "Japan", Description: "Spring trip", Notes: &notes,
+				TravelDestinations: []*models.ItineraryTravelDestination{{City: "Kyoto", Country: "Japan"}, {City: "Osaka", Country: "Japan"}}}, nil
+		}
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItinerary = origInitItinerary })
+
+	var saved *models.ItinerarySearchDocument
+	origInitDocument := models.InitItinerarySearchDocument
+	models.InitItinerarySearchDocument = func() *models.ItinerarySearchDocument {
+		document := &models.ItinerarySearchDocument{}
+		document.Save = func() error {
+			saved = document
+			return nil
+		}
+		return document
+	}
+	t.Cleanup(func() { models.InitItinerarySearchDocument = origInitDocument })
+
+	mockFindItineraryFileJobs(t, nil, []*models.ItineraryFileJob{{ID: 4, ItineraryID: 2, Status: "completed", Filepath: "plan.txt"}}, nil)
+	setMockFileManager(t, &inMemoryFileManager{files: map[string]string{"plan.txt": "Kyoto food tour"}})
+
+	err := GetSearchService().IndexItinerary(2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), saved.ItineraryID)
+	assert.Equal(t, int64(1), saved.OwnerID)
+	assert.Equal(t, "Street food", saved.Notes)
+	assert.Equal(t, "Kyoto, Japan; Osaka, Japan", saved.Destinations)
+	assert.Equal(t, "Kyoto food tour", saved.Content)
+}
+
+func TestSearchService_IndexItinerary_Deleted(t *testing.T) {
+	origInitItinerary := models.InitItinerary
+	models.InitItinerary = func() *models.Itinerary {
+		itinerary := &models.Itinerary{}
+		itinerary.FindById = func(id int64, includeDestinations bool) (*models.Itinerary, error) { return nil, sql.ErrNoRows }
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItinerary = origInitItinerary })
+
+	assert.NoError(t, GetSearchService().IndexItinerary(2))
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type ShareLinkServiceInterface interface {
+	Validate(shareLink *models.ShareLink) error
+	Create(shareLink *models.ShareLink, password string) (string, error)
+	FindByItineraryId(itineraryId int64) ([]*models.ShareLink, error)
+	Revoke(itineraryId int64, shareLinkId int64) error
+	Resolve(token string, password string, clientIp string) (*models.ShareLink, time.Duration, error)
+	GetSharedItinerary(shareLink *models.ShareLink, clientIp string) (*models.Itinerary, *models.ItineraryFileJob, *string, error)
+	OpenSharedFile(shareLink *models.ShareLink, clientIp string) (*models.ItineraryFileJob, io.ReadSeekCloser, error)
+}
+
+type ShareLinkService struct{}
+
+// singleton instance
+var shareLinkServiceInstance = &ShareLinkService{}
+
+// GetShareLinkService returns the singleton instance of ShareLinkService
+var GetShareLinkService = func() ShareLinkServiceInterface {
+	return shareLinkServiceInstance
+}
+
+// Validate checks the expiration date of a new share link and, when the link targets a specific job, that the job is a completed
+// job of the shared itinerary
+func (sls *ShareLinkService) Validate(shareLink *models.ShareLink) error {
+	if shareLink == nil {
+		log.Error("Share link instance is nil")
+		return errors.New("share link instance is nil")
+	}
+
+	if shareLink.ExpirationDate != nil && !shareLink.ExpirationDate.After(time.Now()) {
+		log.Error("Share link expiration date is not in the future")
+		return errors.New("share link expiration date must be in the future")
+	}
+
+	if shareLink.ItineraryFileJobID != nil {
+		job, err := models.InitItineraryFileJob().FindAliveById(*shareLink.ItineraryFileJobID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Errorf("Itinerary file job %d not found", *shareLink.ItineraryFileJobID)
+				return errors.New("itinerary job not found")
+			}
+			log.Errorf("Error retrieving itinerary file job %d: %v", *shareLink.ItineraryFileJobID, err)
+			return errors.New("failed to find itinerary job")
+		}
+
+		if job.ItineraryID != shareLink.ItineraryID {
+			log.Errorf("Itinerary file job %d does not belong to itinerary %d", job.ID, shareLink.ItineraryID)
+			return errors.New("itinerary job not found")
+		}
+
+		if job.Status != "completed" {
+			log.Errorf("Itinerary file job %d is not completed", job.ID)
+			return errors.New("itinerary job is not completed")
+		}
+	}
+
+	return nil
+}
+
+// Create validates and stores a new share link. The generated token is returned in clear only once, since just its hash is persisted.
+// An empty password creates a link that does not require one
+func (sls *ShareLinkService) Create(shareLink *models.ShareLink, password string) (string, error) {
+	err := sls.Validate(shareLink)
+	if err != nil {
+		return "", err
+	}
+
+	if password != "" {
+		passwordHash, err := utils.HashPassword(password)
+		if err != nil {
+			log.Errorf("Error hashing share link password: %v", err)
+			return "", errors.New("error hashing share link password")
+		}
+		shareLink.PasswordHash = &passwordHash
+	}
+
+	token, err := utils.GenerateShareToken()
+	if err != nil {
+		log.Errorf("Error generating share token: %v", err)
+		return "", errors.New("error generating share token")
+	}
+
+	shareLink.TokenPrefix = token[:utils.ShareTokenDisplayPrefixLength]
+	shareLink.TokenHash = utils.HashShareToken(token)
+
+	err = shareLink.Create()
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// FindByItineraryId retrieves all the share links of an itinerary, including the revoked and expired ones
+func (sls *ShareLinkService) FindByItineraryId(itineraryId int64) ([]*models.ShareLink, error) {
+	if itineraryId <= 0 {
+		log.Error("Invalid itinerary ID provided")
+		return nil, errors.New("invalid itinerary ID")
+	}
+	return models.InitShareLink().FindByItineraryId(itineraryId)
+}
+
+// Revoke revokes a share link of the given itinerary. Links of other itineraries are reported as not found.
+func (sls *ShareLinkService) Revoke(itineraryId int64, shareLinkId int64) error {
+	if shareLinkId <= 0 {
+		log.Error("Invalid share link ID provided")
+		return errors.New("invalid share link ID")
+	}
+
+	shareLink, err := models.InitShareLink().FindById(shareLinkId)
+	if err != nil {
+		return err
+	}
+
+	if shareLink.ItineraryID != itineraryId {
+		log.Errorf("Share link %d does not belong to itinerary %d", shareLinkId, itineraryId)
+		return sql.ErrNoRows
+	}
+
+	if shareLink.RevocationDate != nil {
+		log.Warnf("Share link %d is already revoked", shareLinkId)
+		return nil
+	}
+
+	shareLink = models.InitShareLinkFunctions(shareLink)
+	return shareLink.Revoke()
+}
+
+// Resolve returns the active share link matching the given clear token, checking its password when it has one. Wrong passwords are
+// throttled per link and client IP like failed logins, and per link from every IP: while the client must wait, the password is not
+// checked and the wait is returned along with the error
+func (sls *ShareLinkService) Resolve(token string, password string, clientIp string) (*models.ShareLink, time.Duration, error) {
+	if !utils.IsShareToken(token) {
+		return nil, 0, errors.New("invalid share link")
+	}
+
+	shareLink, err := models.InitShareLink().FindByTokenHash(utils.HashShareToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warn("Share link not found")
+			return nil, 0, errors.New("invalid share link")
+		}
+		log.Errorf("Error retrieving share link: %v", err)
+		return nil, 0, errors.New("failed to resolve share link")
+	}
+
+	if !shareLink.IsActive(time.Now()) {
+		log.Warnf("Share link %d is revoked or expired", shareLink.ID)
+		return nil, 0, errors.New("share link is revoked or expired")
+	}
+
+	if shareLink.PasswordHash != nil {
+		if password == "" {
+			log.Warnf("Share link %d requires a password", shareLink.ID)
+			return nil, 0, errors.New("password required")
+		}
+
+		// Checked before the costly password hash comparison, so it cannot be used to brute-force the password either
+		loginThrottleService := GetLoginThrottleService()
+		retryAfter, err := loginThrottleService.CheckSharePasswordAllowed(shareLink.ID, clientIp)
+		if err != nil {
+			log.Errorf("Error checking password attempts of share link %d: %v", shareLink.ID, err)
+			return nil, 0, errors.New("failed to resolve share link")
+		}
+		if retryAfter > 0 {
+			log.Warnf("Password attempt for share link %d from %s rejected by brute-force protection", shareLink.ID, clientIp)
+			return nil, retryAfter, errors.New("too many password attempts")
+		}
+
+		if !utils.CheckPasswordHash(*shareLink.PasswordHash, password) {
+			log.Warnf("Invalid password for share link %d", shareLink.ID)
+			err = loginThrottleService.RegisterFailedSharePassword(shareLink.ID, clientIp)
+			if err != nil {
+				log.Errorf("Error registering failed password attempt of share link %d: %v", shareLink.ID, err)
+			}
+			return nil, 0, errors.New("invalid password")
+		}
+
+		err = loginThrottleService.ResetFailedSharePasswords(shareLink.ID, clientIp)
+		if err != nil {
+			log.Errorf("Error resetting failed password attempts of share link %d: %v", shareLink.ID, err)
+		}
+	}
+
+	return models.InitShareLinkFunctions(shareLink), 0, nil
+}
+
+// GetSharedItinerary returns the shared itinerary together with its document, if any, and the job that generated it. The access is
+// counted and recorded in the audit log of the user who created the link
+func (sls *ShareLinkService) GetSharedItinerary(shareLink *models.ShareLink, clientIp string) (*models.Itinerary, *models.ItineraryFileJob, *string, error) {
+	if shareLink == nil {
+		log.Error("Share link instance is nil")
+		return nil, nil, nil, errors.New("share link instance is nil")
+	}
+
+	itinerary, err := models.InitItinerary().FindById(shareLink.ItineraryID, true)
+	if err != nil {
+		log.Errorf("Error retrieving itinerary %d of share link %d: %v", shareLink.ItineraryID, shareLink.ID, err)
+		return nil, nil, nil, err
+	}
+
+	job, err := findSharedJob(shareLink)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var document *string
+	if job != nil {
+		document, err = readJobFile(job)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	err = shareLink.RegisterAccess(models.ShareLinkResourceItinerary, clientIp)
+	if err != nil {
+		log.Errorf("Error registering access to share link %d: %v", shareLink.ID, err)
+		return nil, nil, nil, errors.New("failed to register share link access")
+	}
+
+	return itinerary, job, document, nil
+}
+
+// OpenSharedFile opens the document of the shared itinerary. The access is counted and recorded in the audit log of the user who
+// created the link
+func (sls *ShareLinkService) OpenSharedFile(shareLink *models.ShareLink, clientIp string) (*models.ItineraryFileJob, io.ReadSeekCloser, error) {
+	if shareLink == nil {
+		log.Error("Share link instance is nil")
+		return nil, nil, errors.New("share link instance is nil")
+	}
+
+	job, err := findSharedJob(shareLink)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job == nil {
+		log.Errorf("No document available for share link %d", shareLink.ID)
+		return nil, nil, errors.New("no document available")
+	}
+
+	file, err := GetFileManager(job.FileManager).OpenFile(job.Filepath)
+	if err != nil {
+		log.Errorf("Error opening file of itinerary file job %d: %v", job.ID, err)
+		return nil, nil, errors.New("failed to open itinerary job file")
+	}
+
+	err = shareLink.RegisterAccess(models.ShareLinkResourceFile, clientIp)
+	if err != nil {
+		file.Close()
+		log.Errorf("Error registering access to share link %d: %v", shareLink.ID, err)
+		return nil, nil, errors.New("failed to register share link access")
+	}
+
+	return job, file, nil
+}
+
+// findSharedJob returns the job whose document is shared by the link: the job of the link when it targets one, otherwise the most
+// recently completed job of the itinerary. A nil job means there is no document to share
+func findSharedJob(shareLink *models.ShareLink) (*models.ItineraryFileJob, error) {
+	if shareLink.ItineraryFileJobID != nil {
+		job, err := models.InitItineraryFileJob().FindAliveById(*shareLink.ItineraryFileJobID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			log.Errorf("Error retrieving itinerary file job %d of share link %d: %v", *shareLink.ItineraryFileJobID, shareLink.ID, err)
+			return nil, errors.New("failed to find itinerary job")
+		}
+		if job.ItineraryID != shareLink.ItineraryID || job.Status != "completed" {
+			return nil, nil
+		}
+		return job, nil
+	}
+
+	return findLatestCompletedJob(shareLink.ItineraryID)
+}
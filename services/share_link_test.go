@@ -0,0 +1,271 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"example.com/travel-advisor/models"
+	"example.com/travel-advisor/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func mockFindItineraryFileJobs(t *testing.T, job *models.ItineraryFileJob, jobs []*models.ItineraryFileJob, err error) {
+	orig := models.InitItineraryFileJob
+	models.InitItineraryFileJob = func() *models.ItineraryFileJob {
+		ifj := &models.ItineraryFileJob{}
+		ifj.FindAliveById = func(id int64) (*models.ItineraryFileJob, error) { return job, err }
+		ifj.FindAliveByItineraryId = func(itineraryId int64) ([]*models.ItineraryFileJob, error) { return jobs, err }
+		return ifj
+	}
+	t.Cleanup(func() { models.InitItineraryFileJob = orig })
+}
+
+func mockFindShareLink(t *testing.T, shareLink *models.ShareLink, err error) {
+	orig := models.InitShareLink
+	models.InitShareLink = func() *models.ShareLink {
+		return &models.ShareLink{
+			FindById:        func(id int64) (*models.ShareLink, error) { return shareLink, err },
+			FindByTokenHash: func(tokenHash string) (*models.ShareLink, error) { return shareLink, err },
+		}
+	}
+	t.Cleanup(func() { models.InitShareLink = orig })
+}
+
+func newSharedLink(accesses *[]string) *models.ShareLink {
+	shareLink := &models.ShareLink{ID: 7, ItineraryID: 2, UserID: 1}
+	shareLink.RegisterAccess = func(resource string, clientIp string) error {
+		*accesses = append(*accesses, resource)
+		return nil
+	}
+	return shareLink
+}
+
+func TestShareLinkService_Validate(t *testing.T) {
+	svc := GetShareLinkService()
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	jobId := int64(3)
+
+	assert.EqualError(t, svc.Validate(nil), "share link instance is nil")
+	assert.EqualError(t, svc.Validate(&models.ShareLink{ItineraryID: 2, ExpirationDate: &past}), "share link expiration date must be in the future")
+	assert.NoError(t, svc.Validate(&models.ShareLink{ItineraryID: 2, ExpirationDate: &future}))
+
+	mockFindItineraryFileJobs(t, &models.ItineraryFileJob{ID: 3, ItineraryID: 9, Status: "completed"}, nil, nil)
+	assert.EqualError(t, svc.Validate(&models.ShareLink{ItineraryID: 2, ItineraryFileJobID: &jobId}), "itinerary job not found")
+
+	mockFindItineraryFileJobs(t, &models.ItineraryFileJob{ID: 3, ItineraryID: 2, Status: "running"}, nil, nil)
+	assert.EqualError(t, svc.Validate(&models.ShareLink{ItineraryID: 2, ItineraryFileJobID: &jobId}), "itinerary job is not completed")
+
+	mockFindItineraryFileJobs(t, nil, nil, sql.ErrNoRows)
+	assert.EqualError(t, svc.Validate(&models.ShareLink{ItineraryID: 2, ItineraryFileJobID: &jobId}), "itinerary job not found")
+
+	mockFindItineraryFileJobs(t, &models.ItineraryFileJob{ID: 3, ItineraryID: 2, Status: "completed"}, nil, nil)
+	assert.NoError(t, svc.Validate(&models.ShareLink{ItineraryID: 2, ItineraryFileJobID: &jobId}))
+}
+
+func TestShareLinkService_Create_Success(t *testing.T) {
+	var created *models.ShareLink
+	shareLink := &models.ShareLink{UserID: 1, ItineraryID: 2}
+	shareLink.Create = func() error {
+		created = shareLink
+		return nil
+	}
+
+	token, err := GetShareLinkService().Create(shareLink, "")
+	assert.NoError(t, err)
+	assert.True(t, utils.IsShareToken(token))
+	assert.Same(t, shareLink, created)
+	assert.Equal(t, token[:utils.ShareTokenDisplayPrefixLength], shareLink.TokenPrefix)
+	assert.Equal(t, utils.HashShareToken(token), shareLink.TokenHash)
+	assert.Nil(t, shareLink.PasswordHash)
+}
+
+func TestShareLinkService_Create_GenerateError(t *testing.T) {
+	origGenerate := utils.GenerateShareToken
+	defer func() { utils.GenerateShareToken = origGenerate }()
+	utils.GenerateShareToken = func() (string, error) { return "", errors.New("entropy error") }
+
+	_, err := GetShareLinkService().Create(&models.ShareLink{ItineraryID: 2}, "")
+	assert.EqualError(t, err, "error generating share token")
+}
+
+func TestShareLinkService_Revoke_Success(t *testing.T) {
+	revoked := false
+	mockFindShareLink(t, &models.ShareLink{ID: 7, ItineraryID: 2}, nil)
+	origInitFunctions := models.InitShareLinkFunctions
+	models.InitShareLinkFunctions = func(shareLink *models.ShareLink) *models.ShareLink {
+		shareLink.Revoke = func() error {
+			revoked = true
+			return nil
+		}
+		return shareLink
+	}
+	t.Cleanup(func() { models.InitShareLinkFunctions = origInitFunctions })
+
+	err := GetShareLinkService().Revoke(2, 7)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestShareLinkService_Revoke_OtherItinerary(t *testing.T) {
+	mockFindShareLink(t, &models.ShareLink{ID: 7, ItineraryID: 9}, nil)
+
+	err := GetShareLinkService().Revoke(2, 7)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestShareLinkService_Resolve_Success(t *testing.T) {
+	mockFindShareLink(t, &models.ShareLink{ID: 7, ItineraryID: 2}, nil)
+
+	shareLink, _, err := GetShareLinkService().Resolve("tas_token", "", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), shareLink.ID)
+	assert.NotNil(t, shareLink.RegisterAccess)
+}
+
+func TestShareLinkService_Resolve_Invalid(t *testing.T) {
+	mockFindShareLink(t, nil, sql.ErrNoRows)
+
+	_, _, err := GetShareLinkService().Resolve("tas_unknown", "", "127.0.0.1")
+	assert.EqualError(t, err, "invalid share link")
+
+	_, _, err = GetShareLinkService().Resolve("not-a-share-token", "", "127.0.0.1")
+	assert.EqualError(t, err, "invalid share link")
+}
+
+func TestShareLinkService_Resolve_RevokedOrExpired(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	mockFindShareLink(t, &models.ShareLink{ID: 7, RevocationDate: &past}, nil)
+	_, _, err := GetShareLinkService().Resolve("tas_token", "", "127.0.0.1")
+	assert.EqualError(t, err, "share link is revoked or expired")
+
+	mockFindShareLink(t, &models.ShareLink{ID: 7, ExpirationDate: &past}, nil)
+	_, _, err = GetShareLinkService().Resolve("tas_token", "", "127.0.0.1")
+	assert.EqualError(t, err, "share link is revoked or expired")
+}
+
+func TestShareLinkService_Resolve_Password(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cr3t"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	passwordHash := string(hash)
+	mockFindShareLink(t, &models.ShareLink{ID: 7, PasswordHash: &passwordHash}, nil)
+	store := map[string]*models.LoginAttempt{}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	_, _, err = GetShareLinkService().Resolve("tas_token", "", "127.0.0.1")
+	assert.EqualError(t, err, "password required")
+
+	_, _, err = GetShareLinkService().Resolve("tas_token", "wrong", "127.0.0.1")
+	assert.EqualError(t, err, "invalid password")
+	assert.Equal(t, 1, store["share_link:7:127.0.0.1"].FailedCount)
+	assert.Equal(t, 1, store["share_link_any_ip:7"].FailedCount)
+
+	shareLink, _, err := GetShareLinkService().Resolve("tas_token", "s3cr3t", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), shareLink.ID)
+	assert.NotContains(t, store, "share_link:7:127.0.0.1")
+	assert.Equal(t, 1, store["share_link_any_ip:7"].FailedCount)
+}
+
+func TestShareLinkService_Resolve_TooManyPasswordAttempts(t *testing.T) {
+	passwordHash := "not-checked"
+	mockFindShareLink(t, &models.ShareLink{ID: 7, PasswordHash: &passwordHash}, nil)
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	store := map[string]*models.LoginAttempt{
+		"share_link:7:127.0.0.1": {AttemptKey: "share_link:7:127.0.0.1", FailedCount: 10, LockedUntil: &lockedUntil},
+	}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	_, retryAfter, err := GetShareLinkService().Resolve("tas_token", "s3cr3t", "127.0.0.1")
+	assert.EqualError(t, err, "too many password attempts")
+	assert.Greater(t, retryAfter, 9*time.Minute)
+
+	// Other clients of the same link are not locked out
+	_, _, err = GetShareLinkService().Resolve("tas_token", "s3cr3t", "10.0.0.1")
+	assert.EqualError(t, err, "invalid password")
+}
+
+func TestShareLinkService_Resolve_TooManyPasswordAttemptsOfLink(t *testing.T) {
+	t.Setenv("SHARE_LINK_MAX_FAILED_ATTEMPTS_PER_LINK", "3")
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cr3t"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	passwordHash := string(hash)
+	mockFindShareLink(t, &models.ShareLink{ID: 7, PasswordHash: &passwordHash}, nil)
+	store := map[string]*models.LoginAttempt{}
+	restore := setMockLoginAttemptStore(store)
+	defer restore()
+
+	for _, clientIp := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		_, _, err = GetShareLinkService().Resolve("tas_token", "wrong", clientIp)
+		assert.EqualError(t, err, "invalid password")
+	}
+
+	// Clients that never failed are locked out too, even with the right password
+	_, retryAfter, err := GetShareLinkService().Resolve("tas_token", "s3cr3t", "10.0.0.4")
+	assert.EqualError(t, err, "too many password attempts")
+	assert.Greater(t, retryAfter, 14*time.Minute)
+}
+
+func TestShareLinkService_GetSharedItinerary_LatestDocument(t *testing.T) {
+	origInitItinerary := models.InitItinerary
+	models.InitItinerary = func() *models.Itinerary {
+		itinerary := &models.Itinerary{}
+		itinerary.FindById = func(id int64, includeDestinations bool) (*models.Itinerary, error) {
+			return &models.Itinerary{ID: id, Title: "Trip"}, nil
+		}
+		return itinerary
+	}
+	t.Cleanup(func() { models.InitItinerary = origInitItinerary })
+
+	now := time.Now()
+	mockFindItineraryFileJobs(t, nil, []*models.ItineraryFileJob{
+		{ID: 3, ItineraryID: 2, Status: "completed", EndDate: now.Add(-time.Hour), Filepath: "old.txt"},
+		{ID: 4, ItineraryID: 2, Status: "completed", EndDate: now, Filepath: "latest.txt"},
+		{ID: 5, ItineraryID: 2, Status: "running", Filepath: "running.txt"},
+	}, nil)
+	setMockFileManager(t, &inMemoryFileManager{files: map[string]string{"old.txt": "old plan", "latest.txt": "latest plan"}})
+
+	accesses := []string{}
+	itinerary, job, document, err := GetShareLinkService().GetSharedItinerary(newSharedLink(&accesses), "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Trip", itinerary.Title)
+	assert.Equal(t, int64(4), job.ID)
+	assert.Equal(t, "latest plan", *document)
+	assert.Equal(t, []string{models.ShareLinkResourceItinerary}, accesses)
+}
+
+func TestShareLinkService_OpenSharedFile_LinkedJob(t *testing.T) {
+	jobId := int64(3)
+	mockFindItineraryFileJobs(t, &models.ItineraryFileJob{ID: 3, ItineraryID: 2, Status: "completed", Filepath: "old.txt"}, nil, nil)
+	setMockFileManager(t, &inMemoryFileManager{files: map[string]string{"old.txt": "old plan"}})
+
+	accesses := []string{}
+	shareLink := newSharedLink(&accesses)
+	shareLink.ItineraryFileJobID = &jobId
+
+	job, file, err := GetShareLinkService().OpenSharedFile(shareLink, "10.0.0.1")
+	assert.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), job.ID)
+	assert.Equal(t, "old plan", string(content))
+	assert.Equal(t, []string{models.ShareLinkResourceFile}, accesses)
+}
+
+func TestShareLinkService_OpenSharedFile_NoDocument(t *testing.T) {
+	mockFindItineraryFileJobs(t, nil, []*models.ItineraryFileJob{{ID: 5, ItineraryID: 2, Status: "failed"}}, nil)
+
+	accesses := []string{}
+	_, file, err := GetShareLinkService().OpenSharedFile(newSharedLink(&accesses), "10.0.0.1")
+	assert.Nil(t, file)
+	assert.EqualError(t, err, "no document available")
+	assert.Empty(t, accesses)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"example.com/travel-advisor/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type TravellerPreferencesServiceInterface interface {
+	FindByUserId(userId int64) (*models.TravellerPreferences, error)
+	SaveForUser(userId int64, preferences *models.TravellerPreferences) error
+	FindByItineraryId(itineraryId int64) (*models.TravellerPreferences, error)
+	SaveForItinerary(itinerary *models.Itinerary, preferences *models.TravellerPreferences, actorId int64) error
+	DeleteForItinerary(itinerary *models.Itinerary, actorId int64) error
+	FindEffective(itinerary *models.Itinerary) (*models.TravellerPreferences, error)
+}
+
+type TravellerPreferencesService struct{}
+
+// singleton instance
+var travellerPreferencesServiceInstance = &TravellerPreferencesService{}
+
+// GetTravellerPreferencesService returns the singleton instance of TravellerPreferencesService
+var GetTravellerPreferencesService = func() TravellerPreferencesServiceInterface {
+	return travellerPreferencesServiceInstance
+}
+
+// FindByUserId retrieves the traveller preferences of a user, which are empty if the user has not set them
+func (tps *TravellerPreferencesService) FindByUserId(userId int64) (*models.TravellerPreferences, error) {
+	preferences, err := models.InitTravellerPreferences().FindByUserId(userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.TravellerPreferences{UserID: &userId}, nil
+	}
+	return preferences, err
+}
+
+// SaveForUser replaces the traveller preferences of a user
+func (tps *TravellerPreferencesService) SaveForUser(userId int64, preferences *models.TravellerPreferences) error {
+	if preferences == nil {
+		log.Error("Traveller preferences instance is nil")
+		return errors.New("traveller preferences instance is nil")
+	}
+
+	preferences = models.InitTravellerPreferencesFunctions(preferences)
+	preferences.UserID = &userId
+	preferences.ItineraryID = nil
+	err := preferences.Save()
+	if err != nil {
+		log.Errorf("Error saving traveller preferences of user %d: %v", userId, err)
+		return errors.New("failed to save traveller preferences")
+	}
+
+	return nil
+}
+
+// FindByItineraryId retrieves the overrides of the traveller preferences for an itinerary, which are empty if none were set
+func (tps *TravellerPreferencesService) FindByItineraryId(itineraryId int64) (*models.TravellerPreferences, error) {
+	preferences, err := models.InitTravellerPreferences().FindByItineraryId(itineraryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.TravellerPreferences{ItineraryID: &itineraryId}, nil
+	}
+	return preferences, err
+}
+
+// SaveForItinerary replaces the overrides of the traveller preferences of the owner of the itinerary, recording the change in the audit
+// log
+func (tps *TravellerPreferencesService) SaveForItinerary(itinerary *models.Itinerary, preferences *models.TravellerPreferences, actorId int64) error {
+	if itinerary == nil || preferences == nil {
+		log.Error("Itinerary or traveller preferences instance is nil")
+		return errors.New("itinerary or traveller preferences instance is nil")
+	}
+
+	preferences = models.InitTravellerPreferencesFunctions(preferences)
+	preferences.UserID = nil
+	preferences.ItineraryID = &itinerary.ID
+	err := preferences.Save()
+	if err != nil {
+		log.Errorf("Error saving traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to save traveller preferences")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Traveller preferences of itinerary %d updated.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "travellerPreferences": "updated"})
+}
+
+// DeleteForItinerary removes the overrides of the traveller preferences for an itinerary, so the ones of its owner apply again
+func (tps *TravellerPreferencesService) DeleteForItinerary(itinerary *models.Itinerary, actorId int64) error {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return errors.New("itinerary instance is nil")
+	}
+
+	preferences := models.InitTravellerPreferences()
+	preferences.ItineraryID = &itinerary.ID
+	err := preferences.Delete()
+	if err != nil {
+		log.Errorf("Error deleting traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		return errors.New("failed to delete traveller preferences")
+	}
+
+	return saveAuditEvent(actorId, models.AuditEventItineraryUpdated, fmt.Sprintf("Traveller preferences of itinerary %d removed.", itinerary.ID),
+		map[string]any{"itineraryId": itinerary.ID, "travellerPreferences": "removed"})
+}
+
+// FindEffective retrieves the traveller preferences an itinerary is generated with: the ones of its owner with the overrides of the
+// itinerary applied
+func (tps *TravellerPreferencesService) FindEffective(itinerary *models.Itinerary) (*models.TravellerPreferences, error) {
+	if itinerary == nil {
+		log.Error("Itinerary instance is nil")
+		return nil, errors.New("itinerary instance is nil")
+	}
+
+	profile, err := tps.FindByUserId(itinerary.OwnerID)
+	if err != nil {
+		log.Errorf("Error retrieving traveller preferences of user %d: %v", itinerary.OwnerID, err)
+		return nil, errors.New("failed to retrieve traveller preferences")
+	}
+
+	overrides, err := tps.FindByItineraryId(itinerary.ID)
+	if err != nil {
+		log.Errorf("Error retrieving traveller preferences of itinerary %d: %v", itinerary.ID, err)
+		return nil, errors.New("failed to retrieve traveller preferences")
+	}
+
+	return profile.Merge(overrides), nil
+}
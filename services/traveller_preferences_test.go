@@ -0,0 +1,128 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"example.com/travel-advisor/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTravellerPreferencesService struct {
+	TravellerPreferencesService
+	Effective    *models.TravellerPreferences
+	EffectiveErr error
+}
+
+func (m *mockTravellerPreferencesService) FindEffective(_ *models.Itinerary) (*models.TravellerPreferences, error) {
+	return m.Effective, m.EffectiveErr
+}
+
+// mockEffectiveTravellerPreferences makes the itineraries be generated with the preferences, or fail to find them with err
+func mockEffectiveTravellerPreferences(t *testing.T, preferences *models.TravellerPreferences, err error) {
+	orig := GetTravellerPreferencesService
+	GetTravellerPreferencesService = func() TravellerPreferencesServiceInterface {
+		return &mockTravellerPreferencesService{Effective: preferences, EffectiveErr: err}
+	}
+	t.Cleanup(func() { GetTravellerPreferencesService = orig })
+}
+
+// mockStoredTravellerPreferences stores the preferences of the users and itineraries in memory, by user ID and itinerary ID respectively
+func mockStoredTravellerPreferences(t *testing.T, users map[int64]*models.TravellerPreferences, itineraries map[int64]*models.TravellerPreferences) {
+	orig := models.InitTravellerPreferences
+	models.InitTravellerPreferences = func() *models.TravellerPreferences {
+		preferences := &models.TravellerPreferences{}
+		preferences.FindByUserId = func(userId int64) (*models.TravellerPreferences, error) {
+			if found, ok := users[userId]; ok {
+				return found, nil
+			}
+			return nil, sql.ErrNoRows
+		}
+		preferences.FindByItineraryId = func(itineraryId int64) (*models.TravellerPreferences, error) {
+			if found, ok := itineraries[itineraryId]; ok {
+				return found, nil
+			}
+			return nil, sql.ErrNoRows
+		}
+		preferences.Delete = func() error {
+			delete(itineraries, *preferences.ItineraryID)
+			return nil
+		}
+		return preferences
+	}
+	t.Cleanup(func() { models.InitTravellerPreferences = orig })
+}
+
+func TestTravellerPreferencesService_FindByUserId_NotSet(t *testing.T) {
+	mockStoredTravellerPreferences(t, nil, nil)
+
+	preferences, err := GetTravellerPreferencesService().FindByUserId(3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), *preferences.UserID)
+	assert.True(t, preferences.IsEmpty())
+}
+
+func TestTravellerPreferencesService_FindEffective(t *testing.T) {
+	mockStoredTravellerPreferences(t,
+		map[int64]*models.TravellerPreferences{3: {Interests: []string{"museums"}, Pace: models.TravellerPaceIntense, Language: "es"}},
+		map[int64]*models.TravellerPreferences{1: {Pace: models.TravellerPaceRelaxed}})
+
+	preferences, err := GetTravellerPreferencesService().FindEffective(&models.Itinerary{ID: 1, OwnerID: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"museums"}, preferences.Interests)
+	assert.Equal(t, models.TravellerPaceRelaxed, preferences.Pace)
+	assert.Equal(t, "es", preferences.Language)
+
+	preferences, err = GetTravellerPreferencesService().FindEffective(&models.Itinerary{ID: 2, OwnerID: 4})
+	assert.NoError(t, err)
+	assert.True(t, preferences.IsEmpty())
+
+	_, err = GetTravellerPreferencesService().FindEffective(nil)
+	assert.Error(t, err)
+}
+
+func TestTravellerPreferencesService_SaveForItinerary(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	var saved *models.TravellerPreferences
+	orig := models.InitTravellerPreferencesFunctions
+	models.InitTravellerPreferencesFunctions = func(preferences *models.TravellerPreferences) *models.TravellerPreferences {
+		preferences.Save = func() error {
+			saved = preferences
+			return nil
+		}
+		return preferences
+	}
+	t.Cleanup(func() { models.InitTravellerPreferencesFunctions = orig })
+
+	userId := int64(3)
+	err := GetTravellerPreferencesService().SaveForItinerary(&models.Itinerary{ID: 1}, &models.TravellerPreferences{UserID: &userId, Pace: "relaxed"}, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, saved.UserID)
+	assert.Equal(t, int64(1), *saved.ItineraryID)
+	assert.Equal(t, []string{"Traveller preferences of itinerary 1 updated."}, *descriptions)
+}
+
+func TestTravellerPreferencesService_SaveForUser_Error(t *testing.T) {
+	orig := models.InitTravellerPreferencesFunctions
+	models.InitTravellerPreferencesFunctions = func(preferences *models.TravellerPreferences) *models.TravellerPreferences {
+		preferences.Save = func() error { return errors.New("db error") }
+		return preferences
+	}
+	t.Cleanup(func() { models.InitTravellerPreferencesFunctions = orig })
+
+	err := GetTravellerPreferencesService().SaveForUser(3, &models.TravellerPreferences{})
+	assert.EqualError(t, err, "failed to save traveller preferences")
+	assert.Error(t, GetTravellerPreferencesService().SaveForUser(3, nil))
+}
+
+func TestTravellerPreferencesService_DeleteForItinerary(t *testing.T) {
+	descriptions := mockSaveAuditEvent(t, nil)
+	itineraries := map[int64]*models.TravellerPreferences{1: {Pace: models.TravellerPaceRelaxed}}
+	mockStoredTravellerPreferences(t, nil, itineraries)
+
+	err := GetTravellerPreferencesService().DeleteForItinerary(&models.Itinerary{ID: 1}, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, itineraries)
+	assert.Equal(t, []string{"Traveller preferences of itinerary 1 removed."}, *descriptions)
+}
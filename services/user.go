@@ -1,7 +1,9 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
+	"slices"
 
 	"example.com/travel-advisor/db"
 	"example.com/travel-advisor/models" // Replace with the actual path to the User struct
@@ -10,10 +12,18 @@ import (
 )
 
 type UserServiceInterface interface {
+	FindById(id int64) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
+	FindAll() ([]*models.User, error)
 	Create(user *models.User) error
 	ValidateCredentials(user *models.User, password string) error
 	GenerateLoginToken(user *models.User) (string, error)
+	UpdateRole(userId int64, role string, actorId int64) error
+	UpdateDisabled(userId int64, disabled bool, actorId int64) error
+	EnsureAdmin(email string) error
+	UpdateEmail(userId int64, email string) error
+	UpdatePassword(userId int64, password string) error
+	Delete(userId int64) error
 }
 
 type UserService struct{}
@@ -26,6 +36,22 @@ var GetUserService = func() UserServiceInterface {
 	return userServiceInstance
 }
 
+// FindById retrieves the user by their ID
+func (us *UserService) FindById(id int64) (*models.User, error) {
+	if id <= 0 {
+		log.Error("Invalid user ID provided")
+		return nil, errors.New("invalid user ID")
+	}
+	user := models.InitUser()
+	return user.FindById(id)
+}
+
+// FindAll retrieves all the users
+func (us *UserService) FindAll() ([]*models.User, error) {
+	user := models.InitUser()
+	return user.FindAll()
+}
+
 // FindByEmail retrieves the user by their email
 func (us *UserService) FindByEmail(email string) (*models.User, error) {
 	if email == "" {
@@ -68,7 +94,7 @@ func (us *UserService) ValidateCredentials(user *models.User, password string) e
 
 		defer db.HandleTransaction(tx, &err)
 
-		auditEvent := models.NewAuditEvent(user.ID, "Failed user login due to invalid credentials")
+		auditEvent := models.NewAuditEvent(user.ID, models.AuditEventLoginFailed, "Failed user login due to invalid credentials", nil)
 		err = auditEvent.CreateAuditEvent(tx)
 		if err != nil {
 			log.Errorf("Error saving login event: %v", err)
@@ -87,7 +113,7 @@ func (us *UserService) GenerateLoginToken(user *models.User) (string, error) {
 		return "", errors.New("user instance is nil")
 	}
 
-	token, err := utils.GenerateToken(user.Email, user.ID)
+	token, err := utils.GenerateToken(user.Email, user.ID, user.Role)
 	if err != nil {
 		log.Errorf("Error generating token: %v", err)
 		return "", errors.New("error generating token")
@@ -108,7 +134,7 @@ func (us *UserService) GenerateLoginToken(user *models.User) (string, error) {
 		return "", errors.New("error updating last login date")
 	}
 
-	auditEvent := models.NewAuditEvent(user.ID, "Successful user login")
+	auditEvent := models.NewAuditEvent(user.ID, models.AuditEventLoginSucceeded, "Successful user login", nil)
 	err = auditEvent.CreateAuditEvent(tx)
 	if err != nil {
 		log.Errorf("Error saving login event: %v", err)
@@ -117,3 +143,127 @@ func (us *UserService) GenerateLoginToken(user *models.User) (string, error) {
 
 	return token, nil
 }
+
+// UpdateRole changes the role of a user. The actor is the administrator performing the change, who cannot change their own role
+func (us *UserService) UpdateRole(userId int64, role string, actorId int64) error {
+	if !slices.Contains(models.Roles, role) {
+		log.Errorf("Unknown role %s", role)
+		return errors.New("unknown role: " + role)
+	}
+
+	if userId == actorId {
+		log.Errorf("User %d tried to change their own role", actorId)
+		return errors.New("users cannot change their own role")
+	}
+
+	user, err := us.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	user = models.InitUserFunctions(user)
+	return user.UpdateRole(role, actorId)
+}
+
+// UpdateDisabled disables or re-enables a user account. The actor is the administrator performing the change, who cannot disable
+// their own account
+func (us *UserService) UpdateDisabled(userId int64, disabled bool, actorId int64) error {
+	if disabled && userId == actorId {
+		log.Errorf("User %d tried to disable their own account", actorId)
+		return errors.New("users cannot disable their own account")
+	}
+
+	user, err := us.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	if (user.DisabledDate != nil) == disabled {
+		log.Warnf("User %d already has the requested disabled status", userId)
+		return nil
+	}
+
+	user = models.InitUserFunctions(user)
+	return user.UpdateDisabled(disabled, actorId)
+}
+
+// EnsureAdmin grants the admin role to the user with the given email if it exists. It is used to bootstrap the first administrator
+func (us *UserService) EnsureAdmin(email string) error {
+	user, err := us.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Warnf("Initial administrator %s is not registered yet", email)
+			return nil
+		}
+		return err
+	}
+
+	user, err = us.FindById(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	log.Infof("Granting the admin role to the initial administrator %s", email)
+	user = models.InitUserFunctions(user)
+	return user.UpdateRole(models.RoleAdmin, user.ID)
+}
+
+// UpdateEmail changes the email of a user, which must be valid and not used by another account
+func (us *UserService) UpdateEmail(userId int64, email string) error {
+	err := utils.ValidateEmail(email)
+	if err != nil {
+		log.Errorf("Invalid email provided for user %d", userId)
+		return errors.New("invalid email")
+	}
+
+	existingUser, err := us.FindByEmail(email)
+	if err == nil {
+		if existingUser.ID == userId {
+			return nil
+		}
+		log.Errorf("Email %s is already used by another user", email)
+		return errors.New("email already in use")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	user, err := us.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	user = models.InitUserFunctions(user)
+	return user.UpdateEmail(email)
+}
+
+// UpdatePassword replaces the password of a user. The caller is in charge of validating the new password and re-authenticating the user
+func (us *UserService) UpdatePassword(userId int64, password string) error {
+	if password == "" {
+		log.Error("Password cannot be empty")
+		return errors.New("password cannot be empty")
+	}
+
+	user, err := us.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	user = models.InitUserFunctions(user)
+	return user.UpdatePassword(password)
+}
+
+// Delete removes a user account with all its itineraries and API keys. The files of its jobs are removed later by the dead jobs cleanup
+func (us *UserService) Delete(userId int64) error {
+	user, err := us.FindById(userId)
+	if err != nil {
+		return err
+	}
+
+	user = models.InitUserFunctions(user)
+	return user.Delete()
+}
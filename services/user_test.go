@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"example.com/travel-advisor/db"
 	"example.com/travel-advisor/models"
@@ -138,7 +139,7 @@ func TestUserService_GenerateLoginToken_Success(t *testing.T) {
 		utils.GenerateToken = origGenerateToken
 	}()
 
-	utils.GenerateToken = func(email string, id int64) (string, error) {
+	utils.GenerateToken = func(email string, id int64, role string) (string, error) {
 		return "mocktoken", nil
 	}
 
@@ -160,7 +161,7 @@ func TestUserService_GenerateLoginToken_Success(t *testing.T) {
 		calledCreateAudit = true
 		return nil
 	}
-	models.NewAuditEvent = func(userID int64, event string) *models.AuditEvent {
+	models.NewAuditEvent = func(userID int64, eventType string, event string, details map[string]any) *models.AuditEvent {
 		return mockAuditEvent
 	}
 
@@ -184,7 +185,7 @@ func TestUserService_GenerateLoginToken_NullUserError(t *testing.T) {
 	origGenerateToken := utils.GenerateToken
 	defer func() { utils.GenerateToken = origGenerateToken }()
 
-	utils.GenerateToken = func(email string, id int64) (string, error) {
+	utils.GenerateToken = func(email string, id int64, role string) (string, error) {
 		return "", errors.New("token error")
 	}
 
@@ -225,7 +226,7 @@ func TestUserService_GenerateLoginToken_ErrorUpdateLastLoginDate(t *testing.T) {
 	assert.NoError(t, err)
 	defer dbMock.Close()
 	defer func() { utils.GenerateToken = origGenerateToken }()
-	utils.GenerateToken = func(email string, id int64) (string, error) {
+	utils.GenerateToken = func(email string, id int64, role string) (string, error) {
 		return "mocktoken", nil
 	}
 
@@ -252,7 +253,7 @@ func TestUserService_GenerateLoginToken_ErrorCreateAuditEvent(t *testing.T) {
 	assert.NoError(t, err)
 	defer dbMock.Close()
 	defer func() { utils.GenerateToken = origGenerateToken }()
-	utils.GenerateToken = func(email string, id int64) (string, error) {
+	utils.GenerateToken = func(email string, id int64, role string) (string, error) {
 		return "mocktoken", nil
 	}
 
@@ -272,7 +273,7 @@ func TestUserService_GenerateLoginToken_ErrorCreateAuditEvent(t *testing.T) {
 	mockAuditEvent.CreateAuditEvent = func(tx *sql.Tx) error {
 		return errors.New("audit error")
 	}
-	models.NewAuditEvent = func(userID int64, event string) *models.AuditEvent {
+	models.NewAuditEvent = func(userID int64, eventType string, event string, details map[string]any) *models.AuditEvent {
 		return mockAuditEvent
 	}
 
@@ -335,7 +336,7 @@ func TestUserService_ValidateCredentials_InvalidCredentials_AuditSuccess(t *test
 		calledCreateAudit = true
 		return nil
 	}
-	models.NewAuditEvent = func(userID int64, event string) *models.AuditEvent {
+	models.NewAuditEvent = func(userID int64, eventType string, event string, details map[string]any) *models.AuditEvent {
 		return mockAuditEvent
 	}
 
@@ -366,7 +367,7 @@ func TestUserService_ValidateCredentials_InvalidCredentials_AuditError(t *testin
 	mockAuditEvent.CreateAuditEvent = func(tx *sql.Tx) error {
 		return errors.New("audit error")
 	}
-	models.NewAuditEvent = func(userID int64, event string) *models.AuditEvent {
+	models.NewAuditEvent = func(userID int64, eventType string, event string, details map[string]any) *models.AuditEvent {
 		return mockAuditEvent
 	}
 
@@ -392,3 +393,184 @@ func TestUserService_ValidateCredentials_InvalidCredentials_BeginTxError(t *test
 	assert.Error(t, err)
 	assert.Equal(t, "unexpected error starting transaction", err.Error())
 }
+
+// mockUserStore makes InitUser return users backed by the given entity, recording the role and disabled status updates
+func mockUserStore(stored *models.User, findErr error) func() {
+	origInitUser := models.InitUser
+	origInitUserFunctions := models.InitUserFunctions
+
+	models.InitUser = func() *models.User {
+		return &models.User{
+			FindById: func(id int64) (*models.User, error) {
+				if findErr != nil {
+					return nil, findErr
+				}
+				found := *stored
+				return &found, nil
+			},
+			FindByEmail: func(email string) (*models.User, error) {
+				if findErr != nil {
+					return nil, findErr
+				}
+				if stored.Email != email {
+					return nil, sql.ErrNoRows
+				}
+				return &models.User{ID: stored.ID, Email: email}, nil
+			},
+		}
+	}
+	models.InitUserFunctions = func(user *models.User) *models.User {
+		user.UpdateRole = func(role string, actorId int64) error {
+			stored.Role = role
+			return nil
+		}
+		user.UpdateDisabled = func(disabled bool, actorId int64) error {
+			stored.DisabledDate = nil
+			if disabled {
+				now := time.Now()
+				stored.DisabledDate = &now
+			}
+			return nil
+		}
+		user.UpdateEmail = func(email string) error {
+			stored.Email = email
+			return nil
+		}
+		user.UpdatePassword = func(password string) error {
+			stored.Password = password
+			return nil
+		}
+		user.Delete = func() error {
+			stored.ID = 0
+			return nil
+		}
+		return user
+	}
+
+	return func() {
+		models.InitUser = origInitUser
+		models.InitUserFunctions = origInitUserFunctions
+	}
+}
+
+func TestUserService_UpdateRole_Success(t *testing.T) {
+	stored := &models.User{ID: 2, Role: models.RoleUser}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().UpdateRole(2, models.RoleSupport, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleSupport, stored.Role)
+}
+
+func TestUserService_UpdateRole_UnknownRole(t *testing.T) {
+	err := GetUserService().UpdateRole(2, "superuser", 1)
+	assert.EqualError(t, err, "unknown role: superuser")
+}
+
+func TestUserService_UpdateRole_OwnRole(t *testing.T) {
+	err := GetUserService().UpdateRole(1, models.RoleUser, 1)
+	assert.EqualError(t, err, "users cannot change their own role")
+}
+
+func TestUserService_UpdateRole_NotFound(t *testing.T) {
+	restore := mockUserStore(nil, sql.ErrNoRows)
+	defer restore()
+
+	err := GetUserService().UpdateRole(2, models.RoleAdmin, 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUserService_UpdateDisabled_Success(t *testing.T) {
+	stored := &models.User{ID: 2}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().UpdateDisabled(2, true, 1)
+	assert.NoError(t, err)
+	assert.NotNil(t, stored.DisabledDate)
+
+	err = GetUserService().UpdateDisabled(2, false, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.DisabledDate)
+}
+
+func TestUserService_UpdateDisabled_OwnAccount(t *testing.T) {
+	err := GetUserService().UpdateDisabled(1, true, 1)
+	assert.EqualError(t, err, "users cannot disable their own account")
+}
+
+func TestUserService_EnsureAdmin_PromotesUser(t *testing.T) {
+	stored := &models.User{ID: 3, Email: "admin@example.com", Role: models.RoleUser}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().EnsureAdmin("admin@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, stored.Role)
+}
+
+func TestUserService_EnsureAdmin_UnknownEmail(t *testing.T) {
+	restore := mockUserStore(nil, sql.ErrNoRows)
+	defer restore()
+
+	err := GetUserService().EnsureAdmin("admin@example.com")
+	assert.NoError(t, err)
+}
+
+func TestUserService_UpdateEmail_Success(t *testing.T) {
+	stored := &models.User{ID: 2, Email: "old@example.com"}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().UpdateEmail(2, "new@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", stored.Email)
+}
+
+func TestUserService_UpdateEmail_InvalidEmail(t *testing.T) {
+	err := GetUserService().UpdateEmail(2, "not-an-email")
+	assert.EqualError(t, err, "invalid email")
+}
+
+func TestUserService_UpdateEmail_AlreadyInUse(t *testing.T) {
+	stored := &models.User{ID: 3, Email: "taken@example.com"}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().UpdateEmail(2, "taken@example.com")
+	assert.EqualError(t, err, "email already in use")
+}
+
+func TestUserService_UpdatePassword_Success(t *testing.T) {
+	stored := &models.User{ID: 2}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().UpdatePassword(2, "NewPassword123-")
+	assert.NoError(t, err)
+	assert.Equal(t, "NewPassword123-", stored.Password)
+}
+
+func TestUserService_UpdatePassword_Empty(t *testing.T) {
+	err := GetUserService().UpdatePassword(2, "")
+	assert.EqualError(t, err, "password cannot be empty")
+}
+
+func TestUserService_Delete_Success(t *testing.T) {
+	stored := &models.User{ID: 2}
+	restore := mockUserStore(stored, nil)
+	defer restore()
+
+	err := GetUserService().Delete(2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stored.ID)
+}
+
+func TestUserService_Delete_NotFound(t *testing.T) {
+	restore := mockUserStore(nil, sql.ErrNoRows)
+	defer restore()
+
+	err := GetUserService().Delete(2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
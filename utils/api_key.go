@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ApiKeyPrefix marks a personal API key so it can be told apart from a JWT in the Authorization header
+const ApiKeyPrefix = "tak_"
+
+// ApiKeyDisplayPrefixLength is the number of leading characters of a key that are stored in clear to help users identify it
+const ApiKeyDisplayPrefixLength = 12
+
+var GenerateApiKey = func() (string, error) {
+	randomBytes := make([]byte, 32)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		log.Errorf("Error generating random bytes for API key: %v", err)
+		return "", err
+	}
+
+	return ApiKeyPrefix + hex.EncodeToString(randomBytes), nil
+}
+
+// HashApiKey returns the SHA-256 hex digest of an API key. API keys are long random values, so a fast hash is enough and
+// keeps the per-request authentication cheap (unlike passwords, which need bcrypt).
+func HashApiKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, ApiKeyPrefix)
+}
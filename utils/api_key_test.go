@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateApiKey(t *testing.T) {
+	apiKey, err := GenerateApiKey()
+	assert.NoError(t, err)
+	assert.True(t, IsApiKey(apiKey))
+	assert.Len(t, apiKey, len(ApiKeyPrefix)+64)
+
+	otherApiKey, err := GenerateApiKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, apiKey, otherApiKey, "generated API keys should be unique")
+}
+
+func TestHashApiKey(t *testing.T) {
+	hash := HashApiKey("tak_test")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashApiKey("tak_test"), "hashing should be deterministic")
+	assert.NotEqual(t, hash, HashApiKey("tak_other"))
+}
+
+func TestIsApiKey(t *testing.T) {
+	assert.True(t, IsApiKey("tak_0123456789abcdef"))
+	assert.False(t, IsApiKey("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.payload.signature"))
+	assert.False(t, IsApiKey(""))
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// EncodeCursor serializes the position of the last element of a page into an opaque string that clients send back to get
+// the next page
+func EncodeCursor(position any) (string, error) {
+	positionJson, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(positionJson), nil
+}
+
+// DecodeCursor reads a cursor generated by EncodeCursor into the given position
+func DecodeCursor(cursor string, position any) error {
+	positionJson, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errors.New("invalid cursor")
+	}
+	err = json.Unmarshal(positionJson, position)
+	if err != nil {
+		return errors.New("invalid cursor")
+	}
+	return nil
+}
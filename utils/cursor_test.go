@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCursorPosition struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	cursor, err := EncodeCursor(testCursorPosition{ID: 42, Title: "Trip to Spain"})
+	assert.NoError(t, err)
+	assert.NotContains(t, cursor, "Trip", "The cursor should be opaque")
+
+	var position testCursorPosition
+	err = DecodeCursor(cursor, &position)
+	assert.NoError(t, err)
+	assert.Equal(t, testCursorPosition{ID: 42, Title: "Trip to Spain"}, position)
+}
+
+func TestDecodeCursor_InvalidBase64(t *testing.T) {
+	var position testCursorPosition
+	err := DecodeCursor("not a cursor!", &position)
+	assert.EqualError(t, err, "invalid cursor")
+}
+
+func TestDecodeCursor_InvalidJson(t *testing.T) {
+	var position testCursorPosition
+	err := DecodeCursor("bm90LWpzb24", &position)
+	assert.EqualError(t, err, "invalid cursor")
+}
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The embedded gazetteer of countries and major cities, so destinations can be normalized without calling an external geocoding
+// service. Both files are tab separated, with lines starting with # as comments
+var (
+	//go:embed gazetteer/countries.tsv
+	gazetteerCountriesData string
+	//go:embed gazetteer/cities.tsv
+	gazetteerCitiesData string
+)
+
+// GazetteerCountry is a country of the gazetteer, with its ISO 3166-1 alpha-2 and alpha-3 codes
+type GazetteerCountry struct {
+	Code  string
+	Code3 string
+	Name  string
+}
+
+// GazetteerCity is a city of the gazetteer, with its WGS 84 coordinates and IANA time zone
+type GazetteerCity struct {
+	Name        string
+	CountryCode string
+	Latitude    float64
+	Longitude   float64
+	Population  int64
+	TimeZone    string
+
+	// names are the folded name and alternate names of the city
+	names []string
+}
+
+type gazetteer struct {
+	// countries are the countries by their folded codes, name and alternate names
+	countries map[string]*GazetteerCountry
+	// cities are sorted by population, the largest first, so the first match of a name is the most likely one
+	cities []*GazetteerCity
+}
+
+var loadGazetteer = sync.OnceValue(func() *gazetteer {
+	g := &gazetteer{countries: map[string]*GazetteerCountry{}}
+
+	for _, fields := range gazetteerRecords(gazetteerCountriesData, 4) {
+		country := &GazetteerCountry{Code: fields[0], Code3: fields[1], Name: fields[2]}
+		for _, name := range append([]string{fields[0], fields[1], fields[2]}, strings.Split(fields[3], ",")...) {
+			if folded := FoldPlaceName(name); folded != "" {
+				g.countries[folded] = country
+			}
+		}
+	}
+
+	for _, fields := range gazetteerRecords(gazetteerCitiesData, 7) {
+		latitude, errLatitude := strconv.ParseFloat(fields[2], 64)
+		longitude, errLongitude := strconv.ParseFloat(fields[3], 64)
+		population, errPopulation := strconv.ParseInt(fields[5], 10, 64)
+		if errLatitude != nil || errLongitude != nil || errPopulation != nil || g.countries[FoldPlaceName(fields[4])] == nil {
+			panic(fmt.Sprintf("invalid gazetteer city %q", fields[0]))
+		}
+
+		city := &GazetteerCity{Name: fields[0], CountryCode: fields[4], Latitude: latitude, Longitude: longitude,
+			Population: population, TimeZone: fields[6]}
+		for _, name := range append([]string{fields[0]}, strings.Split(fields[1], ",")...) {
+			if folded := FoldPlaceName(name); folded != "" {
+				city.names = append(city.names, folded)
+			}
+		}
+		g.cities = append(g.cities, city)
+	}
+
+	sort.SliceStable(g.cities, func(a int, b int) bool {
+		return g.cities[a].Population > g.cities[b].Population
+	})
+
+	return g
+})
+
+// gazetteerRecords splits the embedded data into records of the given number of fields, skipping comments and blank lines. The
+// data is part of the binary, so a malformed record is a bug
+func gazetteerRecords(data string, fieldCount int) [][]string {
+	records := [][]string{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != fieldCount {
+			panic(fmt.Sprintf("invalid gazetteer record %q", line))
+		}
+		records = append(records, fields)
+	}
+	return records
+}
+
+// FoldPlaceName returns the name of a place as compared with the gazetteer: lowercase, without diacritics or periods, with hyphens
+// as spaces and without repeated spaces, so "Zürich", "ZURICH" and "zurich" are the same place, as are "St. Petersburg" and
+// "st petersburg"
+func FoldPlaceName(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+	folded = strings.NewReplacer(".", "", "-", " ").Replace(strings.ToLower(folded))
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// FindCountry returns the country of the gazetteer with the code (alpha-2 or alpha-3), name or alternate name, ignoring case and
+// diacritics, like US for "USA", "United States" or "us". Returns nil if there is none
+func FindCountry(name string) *GazetteerCountry {
+	return loadGazetteer().countries[FoldPlaceName(name)]
+}
+
+// FindCity returns the most populous city of the gazetteer with the name or alternate name in the country with the ISO 3166-1
+// alpha-2 code, ignoring case and diacritics. Returns nil if there is none
+func FindCity(countryCode string, name string) *GazetteerCity {
+	folded := FoldPlaceName(name)
+	if folded == "" {
+		return nil
+	}
+	for _, city := range loadGazetteer().cities {
+		if strings.EqualFold(city.CountryCode, countryCode) && slices.Contains(city.names, folded) {
+			return city
+		}
+	}
+	return nil
+}
+
+// NormalizePlace returns the country and city with their names in the gazetteer, so "USA, NYC" becomes "United States, New York",
+// along with the gazetteer country and city. Places missing from the gazetteer are returned trimmed but otherwise as entered, with
+// a nil country or city
+func NormalizePlace(countryName string, cityName string) (string, string, *GazetteerCountry, *GazetteerCity) {
+	countryName = strings.TrimSpace(countryName)
+	cityName = strings.TrimSpace(cityName)
+
+	country := FindCountry(countryName)
+	if country == nil {
+		return countryName, cityName, nil, nil
+	}
+	city := FindCity(country.Code, cityName)
+	if city == nil {
+		return country.Name, cityName, country, nil
+	}
+	return country.Name, city.Name, country, city
+}
+
+// SuggestCities returns at most limit cities whose name or alternate name, or a word of them, starts with the query, ignoring case
+// and diacritics. Only the cities of the country with the ISO 3166-1 alpha-2 code are returned if it is not empty. Cities named as
+// the query go first, then the ones whose name starts with it, the most populous first
+func SuggestCities(query string, countryCode string, limit int) []*GazetteerCity {
+	folded := FoldPlaceName(query)
+	if folded == "" || limit <= 0 {
+		return []*GazetteerCity{}
+	}
+
+	const (
+		exactMatch = iota
+		nameMatch
+		wordMatch
+		noMatch
+	)
+	ranks := map[*GazetteerCity]int{}
+	matches := []*GazetteerCity{}
+	for _, city := range loadGazetteer().cities {
+		if countryCode != "" && !strings.EqualFold(city.CountryCode, countryCode) {
+			continue
+		}
+		rank := noMatch
+		for _, name := range city.names {
+			switch {
+			case name == folded:
+				rank = min(rank, exactMatch)
+			case strings.HasPrefix(name, folded):
+				rank = min(rank, nameMatch)
+			case strings.Contains(name, " "+folded):
+				rank = min(rank, wordMatch)
+			}
+		}
+		if rank != noMatch {
+			ranks[city] = rank
+			matches = append(matches, city)
+		}
+	}
+
+	sort.SliceStable(matches, func(a int, b int) bool {
+		return ranks[matches[a]] < ranks[matches[b]]
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// SamePlace returns whether two countries and cities are the same place, either because their names only differ in case and
+// diacritics or because the gazetteer knows them by both names, like "USA, NYC" and "United States, New York"
+func SamePlace(countryA string, cityA string, countryB string, cityB string) bool {
+	sameCountry := FoldPlaceName(countryA) == FoldPlaceName(countryB)
+	code := ""
+	if a, b := FindCountry(countryA), FindCountry(countryB); a != nil && a == b {
+		sameCountry = true
+		code = a.Code
+	}
+	if !sameCountry {
+		return false
+	}
+
+	if FoldPlaceName(cityA) == FoldPlaceName(cityB) {
+		return true
+	}
+	if code == "" {
+		return false
+	}
+	a, b := FindCity(code, cityA), FindCity(code, cityB)
+	return a != nil && a == b
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldPlaceName(t *testing.T) {
+	assert.Equal(t, "zurich", FoldPlaceName(" Zürich "))
+	assert.Equal(t, "st petersburg", FoldPlaceName("St.  Petersburg"))
+	assert.Equal(t, "cluj napoca", FoldPlaceName("Cluj-Napoca"))
+	assert.Equal(t, "", FoldPlaceName("  "))
+}
+
+func TestFindCountry(t *testing.T) {
+	for _, name := range []string{"USA", "United States", "us", "united states of america", " U.S.A. "} {
+		country := FindCountry(name)
+		if assert.NotNil(t, country, name) {
+			assert.Equal(t, "US", country.Code, name)
+			assert.Equal(t, "United States", country.Name, name)
+		}
+	}
+	assert.Equal(t, "ES", FindCountry("españa").Code)
+	assert.Equal(t, "GBR", FindCountry("UK").Code3)
+	assert.Nil(t, FindCountry("Atlantis"))
+	assert.Nil(t, FindCountry(""))
+}
+
+func TestFindCity(t *testing.T) {
+	city := FindCity("US", "nyc")
+	if assert.NotNil(t, city) {
+		assert.Equal(t, "New York", city.Name)
+		assert.Equal(t, "America/New_York", city.TimeZone)
+		assert.InDelta(t, 40.71, city.Latitude, 0.01)
+		assert.InDelta(t, -74.01, city.Longitude, 0.01)
+	}
+	assert.Equal(t, "Córdoba", FindCity("AR", "cordoba").Name)
+	assert.Equal(t, "America/Argentina/Cordoba", FindCity("AR", "cordoba").TimeZone)
+	assert.Equal(t, "Europe/Madrid", FindCity("es", "CORDOBA").TimeZone)
+	assert.Nil(t, FindCity("FR", "Madrid"))
+	assert.Nil(t, FindCity("ES", ""))
+}
+
+func TestNormalizePlace(t *testing.T) {
+	country, city, gazetteerCountry, gazetteerCity := NormalizePlace(" usa ", "nyc")
+	assert.Equal(t, "United States", country)
+	assert.Equal(t, "New York", city)
+	assert.Equal(t, "US", gazetteerCountry.Code)
+	assert.Equal(t, "America/New_York", gazetteerCity.TimeZone)
+
+	country, city, gazetteerCountry, gazetteerCity = NormalizePlace("españa", " Villarriba ")
+	assert.Equal(t, "Spain", country)
+	assert.Equal(t, "Villarriba", city)
+	assert.Equal(t, "ES", gazetteerCountry.Code)
+	assert.Nil(t, gazetteerCity)
+
+	country, city, gazetteerCountry, gazetteerCity = NormalizePlace(" Atlantis", "Poseidonia ")
+	assert.Equal(t, "Atlantis", country)
+	assert.Equal(t, "Poseidonia", city)
+	assert.Nil(t, gazetteerCountry)
+	assert.Nil(t, gazetteerCity)
+}
+
+func TestSuggestCities(t *testing.T) {
+	suggestions := SuggestCities("san", "CL", 3)
+	if assert.Len(t, suggestions, 2) {
+		assert.Equal(t, "Santiago", suggestions[0].Name)
+		assert.Equal(t, "San Pedro de Atacama", suggestions[1].Name)
+	}
+
+	suggestions = SuggestCities("york", "", 10)
+	if assert.Len(t, suggestions, 2) {
+		assert.Equal(t, "York", suggestions[0].Name)
+		assert.Equal(t, "New York", suggestions[1].Name)
+	}
+
+	suggestions = SuggestCities("sao", "", 1)
+	if assert.Len(t, suggestions, 1) {
+		assert.Equal(t, "São Paulo", suggestions[0].Name)
+	}
+
+	suggestions = SuggestCities("granada", "ni", 10)
+	if assert.Len(t, suggestions, 1) {
+		assert.Equal(t, "NI", suggestions[0].CountryCode)
+	}
+
+	suggestions = SuggestCities("vic", "", 10)
+	assert.Equal(t, "Victoria", suggestions[0].Name)
+
+	assert.Empty(t, SuggestCities("", "", 10))
+	assert.Empty(t, SuggestCities("madrid", "", 0))
+	assert.Empty(t, SuggestCities("zzz", "", 10))
+}
+
+func TestSamePlace(t *testing.T) {
+	assert.True(t, SamePlace("USA", "NYC", "United States", "New York"))
+	assert.True(t, SamePlace("spain", "sevilla", "Spain", "Seville"))
+	assert.True(t, SamePlace("Atlantis", "Poseidonia", "atlantis", "POSEIDONIA"))
+	assert.False(t, SamePlace("Spain", "Madrid", "Spain", "Barcelona"))
+	assert.False(t, SamePlace("Spain", "Granada", "Nicaragua", "Granada"))
+	assert.False(t, SamePlace("Atlantis", "Poseidonia", "Atlantis", "Other"))
+}
+
+func TestGazetteer_TimeZones(t *testing.T) {
+	for _, city := range loadGazetteer().cities {
+		_, err := time.LoadLocation(city.TimeZone)
+		assert.NoError(t, err, city.Name)
+	}
+}
@@ -30,17 +30,22 @@ func InitJwtSecretKey() error {
 	return err
 }
 
-var GenerateToken = func(email string, userId int64) (string, error) {
+// GenerateToken returns a token for the user that expires in two hours. The role claim is informational only: it is the role the
+// user had when logging in, so authorization always reads the current role of the user instead.
+var GenerateToken = func(email string, userId int64, role string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"email":  email,
 		"userId": userId,
+		"role":   role,
 		"exp":    time.Now().Add(time.Hour * 2).Unix(),
 	})
 
 	return token.SignedString([]byte(secretKey))
 }
 
-var VerifyToken = func(token string) (int64, error) {
+// VerifyToken returns the user ID and role carried by a valid token. The role is empty for tokens issued before roles existed, and it
+// must not be used for authorization, since it is not updated when the role of the user changes.
+var VerifyToken = func(token string) (int64, string, error) {
 	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
@@ -52,25 +57,26 @@ var VerifyToken = func(token string) (int64, error) {
 
 	if err != nil {
 		log.Error("Error parsing token: ", err)
-		return 0, errors.New("could not parse token")
+		return 0, "", errors.New("could not parse token")
 	}
 
 	tokenIsValid := parsedToken.Valid
 
 	if !tokenIsValid {
 		log.Error("Invalid token!")
-		return 0, errors.New("invalid token")
+		return 0, "", errors.New("invalid token")
 	}
 
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 
 	if !ok {
 		log.Error("Invalid token claims!")
-		return 0, errors.New("invalid token claims")
+		return 0, "", errors.New("invalid token claims")
 	}
 
 	// email := claims["email"].(string)
 	userId := int64(claims["userId"].(float64))
+	role, _ := claims["role"].(string)
 
-	return userId, nil
+	return userId, role, nil
 }
@@ -43,15 +43,16 @@ func TestGenerateAndVerifyToken(t *testing.T) {
 	email := "test@example.com"
 	userId := int64(12345)
 
-	token, err := GenerateToken(email, userId)
+	token, err := GenerateToken(email, userId, "admin")
 
 	assert.NoError(t, err, "GenerateToken failed: %v", err)
 	assert.NotEmpty(t, token, "expected token to be non-empty")
 
-	gotUserId, err := VerifyToken(token)
+	gotUserId, gotRole, err := VerifyToken(token)
 
 	assert.NoError(t, err, "VerifyToken failed: %v", err)
 	assert.Equal(t, userId, gotUserId, "expected userId to match")
+	assert.Equal(t, "admin", gotRole, "expected role to match")
 }
 
 func TestVerifyToken_InvalidToken(t *testing.T) {
@@ -64,6 +65,6 @@ func TestVerifyToken_InvalidToken(t *testing.T) {
 	assert.NoError(t, err, "InitJwtSecretKey failed: %v", err)
 
 	invalidToken := "invalid.token.value"
-	_, err = VerifyToken(invalidToken)
+	_, _, err = VerifyToken(invalidToken)
 	assert.Error(t, err, "expected error for invalid token, got nil")
 }
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+	"golang.org/x/text/message"
+)
+
+// isoDateLayout is the layout of the dates without a locale
+const isoDateLayout = "2006-01-02"
+
+// defaultLocalDateLayout is the layout of the dates of the locales without a specific one, the day first as in most of the world
+const defaultLocalDateLayout = "02/01/2006"
+
+// Numeric date layouts of the locales whose dates are not written day first with slashes. Locales with a region take precedence over
+// their language
+var (
+	localDateLayoutsByLocale = map[string]string{
+		"en-US": "01/02/2006",
+		"en-CA": isoDateLayout,
+		"fr-CA": isoDateLayout,
+	}
+	localDateLayoutsByLanguage = map[string]string{
+		"cs": "02.01.2006",
+		"da": "02.01.2006",
+		"de": "02.01.2006",
+		"fi": "02.01.2006",
+		"hu": "2006. 01. 02.",
+		"ja": "2006/01/02",
+		"ko": "2006. 01. 02.",
+		"lt": isoDateLayout,
+		"nb": "02.01.2006",
+		"nl": "02-01-2006",
+		"pl": "02.01.2006",
+		"ro": "02.01.2006",
+		"ru": "02.01.2006",
+		"sk": "02.01.2006",
+		"sv": isoDateLayout,
+		"tr": "02.01.2006",
+		"uk": "02.01.2006",
+		"zh": "2006/01/02",
+	}
+)
+
+// ValidateLanguageTag checks whether the tag is a well-formed BCP 47 language tag, like es or pt-BR
+func ValidateLanguageTag(languageTag string) error {
+	_, err := language.Parse(languageTag)
+	return err
+}
+
+// FormatLocalDate formats the date as usual in the locale of the BCP 47 language tag, like 31/12/2024 for es or 12/31/2024 for en-US.
+// The dates of an empty or invalid tag are formatted as ISO 8601
+func FormatLocalDate(date time.Time, languageTag string) string {
+	tag, err := language.Parse(languageTag)
+	if err != nil {
+		return date.Format(isoDateLayout)
+	}
+
+	// The region of a tag without one is the most likely one, like US for en
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	if layout, ok := localDateLayoutsByLocale[base.String()+"-"+region.String()]; ok {
+		return date.Format(layout)
+	}
+	if layout, ok := localDateLayoutsByLanguage[base.String()]; ok {
+		return date.Format(layout)
+	}
+	return date.Format(defaultLocalDateLayout)
+}
+
+// FormatLocalNumber formats the number with the digit grouping and decimal separators of the locale of the BCP 47 language tag, like
+// 1.234,5 for es. The numbers of an empty or invalid tag are formatted with the ones of English
+func FormatLocalNumber(number any, languageTag string) string {
+	tag, err := language.Parse(languageTag)
+	if err != nil {
+		tag = language.English
+	}
+	return message.NewPrinter(tag).Sprint(number)
+}
+
+// LanguageName returns the English name of the language of the BCP 47 language tag, like Spanish for es or Brazilian Portuguese for
+// pt-BR, or the tag itself if the language is unknown
+func LanguageName(languageTag string) string {
+	tag, err := language.Parse(languageTag)
+	if err != nil {
+		return languageTag
+	}
+
+	name := display.English.Tags().Name(tag)
+	if name == "" {
+		return languageTag
+	}
+	return name
+}
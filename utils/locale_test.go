@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLanguageTag(t *testing.T) {
+	assert.NoError(t, ValidateLanguageTag("es"))
+	assert.NoError(t, ValidateLanguageTag("pt-BR"))
+	assert.Error(t, ValidateLanguageTag("123"))
+	assert.Error(t, ValidateLanguageTag(""))
+}
+
+func TestFormatLocalDate(t *testing.T) {
+	date := time.Date(2024, time.December, 31, 10, 0, 0, 0, time.UTC)
+
+	for languageTag, expected := range map[string]string{
+		"":      "2024-12-31",
+		"not a": "2024-12-31",
+		"en":    "12/31/2024",
+		"en-US": "12/31/2024",
+		"en-GB": "31/12/2024",
+		"es":    "31/12/2024",
+		"pt-BR": "31/12/2024",
+		"de-AT": "31.12.2024",
+		"ja":    "2024/12/31",
+		"fr-CA": "2024-12-31",
+	} {
+		assert.Equal(t, expected, FormatLocalDate(date, languageTag), languageTag)
+	}
+}
+
+func TestFormatLocalNumber(t *testing.T) {
+	assert.Equal(t, "1,234.5", FormatLocalNumber(1234.5, ""))
+	assert.Equal(t, "1.234,5", FormatLocalNumber(1234.5, "es"))
+	assert.Equal(t, "2", FormatLocalNumber(2, "de"))
+}
+
+func TestLanguageName(t *testing.T) {
+	assert.Equal(t, "Spanish", LanguageName("es"))
+	assert.Equal(t, "Brazilian Portuguese", LanguageName("pt-BR"))
+	assert.Equal(t, "not a", LanguageName("not a"))
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/json"
+)
+
+// ApplyMergePatch applies a JSON merge patch (RFC 7396) to a JSON document. Members of the patch replace the ones of the document,
+// objects are merged recursively and null members remove the ones of the document. Arrays are replaced as a whole
+func ApplyMergePatch(document []byte, patch []byte) ([]byte, error) {
+	var target any
+	err := json.Unmarshal(document, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	var patchValue any
+	err = json.Unmarshal(patch, &patchValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(target, patchValue))
+}
+
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+
+	return targetObject
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ShareTokenPrefix marks the tokens of public share links
+const ShareTokenPrefix = "tas_"
+
+// ShareTokenDisplayPrefixLength is the number of leading characters of a share token that are stored in clear to help owners identify it
+const ShareTokenDisplayPrefixLength = 12
+
+// GenerateShareToken returns an unguessable, URL-safe token for a public share link
+var GenerateShareToken = func() (string, error) {
+	randomBytes := make([]byte, 32)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		log.Errorf("Error generating random bytes for share token: %v", err)
+		return "", err
+	}
+
+	return ShareTokenPrefix + base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+// HashShareToken returns the SHA-256 hex digest of a share token. Like API keys, share tokens are long random values, so a fast
+// hash is enough
+func HashShareToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func IsShareToken(token string) bool {
+	return strings.HasPrefix(token, ShareTokenPrefix)
+}
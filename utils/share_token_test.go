@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateShareToken(t *testing.T) {
+	token, err := GenerateShareToken()
+	assert.NoError(t, err)
+	assert.True(t, IsShareToken(token))
+	assert.Equal(t, token, url.PathEscape(token), "share tokens should be URL safe")
+
+	otherToken, err := GenerateShareToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, otherToken, "generated share tokens should be unique")
+}
+
+func TestHashShareToken(t *testing.T) {
+	hash := HashShareToken("tas_test")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashShareToken("tas_test"), "hashing should be deterministic")
+	assert.NotEqual(t, hash, HashShareToken("tas_other"))
+}
+
+func TestIsShareToken(t *testing.T) {
+	assert.True(t, IsShareToken("tas_abc"))
+	assert.False(t, IsShareToken("tak_abc"))
+	assert.False(t, IsShareToken(""))
+}